@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"exercise-tracker/internal/blob"
+	"exercise-tracker/internal/catalognorm"
+	"exercise-tracker/internal/freeexercisedb"
+	"exercise-tracker/internal/store"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type freeExerciseDBReport struct {
+	File       string               `json:"file"`
+	DryRun     bool                 `json:"dryRun"`
+	Parsed     int                  `json:"parsed"`
+	Upserted   int                  `json:"upserted"`
+	Skipped    int                  `json:"skipped"`
+	Failures   []rowFailure         `json:"failures"`
+	Normalized []catalognorm.Change `json:"normalized"`
+	// Images is nil when images weren't downloaded.
+	Images     *freeexercisedb.ImageResult `json:"images,omitempty"`
+	DurationMs int64                       `json:"durationMs"`
+}
+
+type freeExerciseDBOptions struct {
+	path       string
+	dbURL      string
+	dryRun     bool
+	images     bool
+	imageBase  string
+	reportPath string
+	norm       *catalognorm.Normalizer
+}
+
+// importFreeExerciseDB imports a yuhonas/free-exercise-db exercises.json
+// through the catalog store, the same way the admin import endpoint does,
+// then downloads the images of entries that have none into the blob store
+// configured by BLOB_BACKEND and S3_*.
+func importFreeExerciseDB(opts freeExerciseDBOptions) {
+	start := time.Now()
+	rep := freeExerciseDBReport{File: opts.path, DryRun: opts.dryRun, Failures: []rowFailure{}}
+	emitReport := func() {
+		if opts.reportPath == "" {
+			return
+		}
+		rep.DurationMs = time.Since(start).Milliseconds()
+		if err := writeReport(opts.reportPath, rep); err != nil {
+			log.Printf("write report: %v", err)
+		}
+	}
+
+	f, err := os.Open(opts.path)
+	if err != nil {
+		log.Fatalf("open json: %v", err)
+	}
+	exercises, err := freeexercisedb.Parse(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("parse json: %v", err)
+	}
+
+	normRep := catalognorm.NewReport()
+	entries := make([]store.CatalogEntry, 0, len(exercises))
+	accepted := make([]freeexercisedb.Exercise, 0, len(exercises))
+	for i, e := range exercises {
+		row := i + 1
+		entry, err := e.Entry()
+		if err != nil {
+			rep.Skipped++
+			rep.Failures = append(rep.Failures, rowFailure{Row: row, Reason: err.Error()})
+			continue
+		}
+		rejected := len(normRep.Rejected)
+		if !opts.norm.Entry(normRep, row, &entry.Type, &entry.BodyPart, &entry.Equipment, &entry.Level) {
+			rep.Skipped++
+			for _, rj := range normRep.Rejected[rejected:] {
+				rep.Failures = append(rep.Failures, rowFailure{Row: row, Reason: fmt.Sprintf("%s %q is not allowed", rj.Field, rj.Value)})
+			}
+			continue
+		}
+		entries = append(entries, entry)
+		accepted = append(accepted, e)
+	}
+	rep.Parsed = len(entries)
+	rep.Normalized = normRep.Normalized
+	log.Printf("parsed %d exercises", len(entries))
+	if opts.dryRun {
+		for i := 0; i < len(entries) && i < 10; i++ {
+			log.Printf("%3d: %s [%s] %s | %s | %s", i+1, entries[i].Name, entries[i].Level, entries[i].Type, entries[i].BodyPart, entries[i].Equipment)
+		}
+		emitReport()
+		return
+	}
+	if opts.dbURL == "" {
+		log.Fatalf("DATABASE_URL or --db is required unless --dry-run")
+	}
+
+	db, err := sqlx.Open("pgx", opts.dbURL)
+	if err != nil {
+		log.Fatalf("db open: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		log.Fatalf("db ping: %v", err)
+	}
+	blobs, err := blobStoreFromEnv(db)
+	if err != nil {
+		log.Fatalf("blob store: %v", err)
+	}
+	catalog := store.NewCatalog(db, blobs)
+
+	n, err := catalog.Upsert(ctx, entries)
+	if err != nil {
+		rep.Failures = append(rep.Failures, rowFailure{Reason: err.Error()})
+		log.Printf("upsert failed: %v", err)
+		emitReport()
+		os.Exit(1)
+	}
+	rep.Upserted = n
+	log.Printf("upserted %d exercises", n)
+
+	if opts.images {
+		d := &freeexercisedb.Downloader{Store: catalog, Base: opts.imageBase}
+		res, err := d.Images(ctx, accepted)
+		if err != nil {
+			log.Printf("download images: %v", err)
+		}
+		for _, f := range res.Failures {
+			log.Printf("image %q (%s): %s", f.Name, f.URL, f.Reason)
+		}
+		rep.Images = &res
+		log.Printf("images: %d downloaded, %d skipped, %d failed", res.Downloaded, res.Skipped, len(res.Failures))
+	}
+	log.Printf("done in %s: %d upserted, %d skipped, %d failures",
+		time.Since(start).Truncate(time.Millisecond), rep.Upserted, rep.Skipped, len(rep.Failures))
+	emitReport()
+}
+
+// blobStoreFromEnv picks the image store like the server does from
+// BLOB_BACKEND and the S3_* variables.
+func blobStoreFromEnv(db *sqlx.DB) (blob.Store, error) {
+	switch backend := os.Getenv("BLOB_BACKEND"); backend {
+	case "", "postgres":
+		return blob.NewPostgres(db), nil
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return blob.NewS3(blob.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          region,
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") != "false",
+		})
+	default:
+		return nil, fmt.Errorf("unknown BLOB_BACKEND %q", backend)
+	}
+}
@@ -3,13 +3,21 @@ package main
 import (
 	"context"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"exercise-tracker/internal/catalognorm"
+	"exercise-tracker/internal/freeexercisedb"
+
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 )
@@ -23,6 +31,7 @@ func slugify(name string) string {
 }
 
 type Row struct {
+	Line      int // 1-based data row number (header excluded)
 	Title     string
 	Desc      string
 	Type      string
@@ -32,18 +41,112 @@ type Row struct {
 	Primary   []string
 }
 
+// checkpoint is persisted after every committed batch so an interrupted
+// import can continue with --resume.
+type checkpoint struct {
+	CSV       string    `json:"csv"`
+	LastRow   int       `json:"lastRow"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type rowFailure struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+type report struct {
+	CSV         string       `json:"csv"`
+	DryRun      bool         `json:"dryRun"`
+	ResumedFrom int          `json:"resumedFrom,omitempty"`
+	Parsed      int          `json:"parsed"`
+	Inserted    int          `json:"inserted"`
+	Updated     int          `json:"updated"`
+	Skipped     int          `json:"skipped"`
+	Failures    []rowFailure `json:"failures"`
+	// Facet values rewritten by the normalization rules.
+	Normalized []catalognorm.Change `json:"normalized"`
+	DurationMs int64                `json:"durationMs"`
+}
+
 func main() {
 	var (
-		dbURL   string
-		csvPath string
-		dryRun  bool
-		batch   int
+		dbURL      string
+		csvPath    string
+		dryRun     bool
+		batch      int
+		statePath  string
+		resume     bool
+		reportPath string
+		normPath   string
+		freeDBPath string
+		images     bool
+		imageBase  string
 	)
 	flag.StringVar(&dbURL, "db", os.Getenv("DATABASE_URL"), "Postgres connection URL (or env DATABASE_URL)")
 	flag.StringVar(&csvPath, "csv", "megaGymDataset.csv", "Path to megaGymDataset.csv")
 	flag.BoolVar(&dryRun, "dry-run", false, "Parse only; do not write to DB")
 	flag.IntVar(&batch, "batch", 500, "Batch size for DB upserts")
+	flag.StringVar(&statePath, "state", "", "Checkpoint file (default: <csv>.state.json)")
+	flag.BoolVar(&resume, "resume", false, "Continue after the last row recorded in the checkpoint file")
+	flag.StringVar(&reportPath, "report", "", "Write a JSON summary to this path (\"-\" for stdout)")
+	flag.StringVar(&normPath, "normalize", "", "JSON facet normalization rules layered over the defaults (see internal/catalognorm)")
+	flag.StringVar(&freeDBPath, "free-exercise-db", "", "Import this yuhonas/free-exercise-db exercises.json instead of the CSV")
+	flag.BoolVar(&images, "images", true, "With --free-exercise-db, download images for entries that have none")
+	flag.StringVar(&imageBase, "image-base", freeexercisedb.DefaultImageBase, "With --free-exercise-db, URL the dataset's image paths are relative to")
 	flag.Parse()
+	if statePath == "" {
+		statePath = csvPath + ".state.json"
+	}
+	if batch <= 0 {
+		batch = 500
+	}
+
+	rules, err := catalognorm.Load(normPath)
+	if err != nil {
+		log.Fatalf("normalization rules: %v", err)
+	}
+	norm := catalognorm.New(rules, nil)
+	if freeDBPath != "" {
+		importFreeExerciseDB(freeExerciseDBOptions{
+			path:       freeDBPath,
+			dbURL:      dbURL,
+			dryRun:     dryRun,
+			images:     images,
+			imageBase:  imageBase,
+			reportPath: reportPath,
+			norm:       norm,
+		})
+		return
+	}
+	normRep := catalognorm.NewReport()
+	rep := report{CSV: csvPath, DryRun: dryRun, Failures: []rowFailure{}}
+	start := time.Now()
+	emitReport := func() {
+		if reportPath == "" {
+			return
+		}
+		rep.DurationMs = time.Since(start).Milliseconds()
+		if err := writeReport(reportPath, rep); err != nil {
+			log.Printf("write report: %v", err)
+		}
+	}
+
+	resumeAfter := 0
+	if resume {
+		cp, err := readCheckpoint(statePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Printf("no checkpoint at %s; starting from the beginning", statePath)
+		case err != nil:
+			log.Fatalf("read checkpoint: %v", err)
+		case cp.CSV != "" && filepath.Clean(cp.CSV) != filepath.Clean(csvPath):
+			log.Fatalf("checkpoint %s belongs to %s, not %s", statePath, cp.CSV, csvPath)
+		default:
+			resumeAfter = cp.LastRow
+			rep.ResumedFrom = cp.LastRow
+			log.Printf("resuming after row %d", resumeAfter)
+		}
+	}
 
 	f, err := os.Open(csvPath)
 	if err != nil {
@@ -80,26 +183,40 @@ func main() {
 	}
 
 	var rows []Row
+	line := 0
 	for {
 		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
 		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
-			if strings.Contains(err.Error(), "EOF") {
-				break
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				if line > resumeAfter {
+					rep.Failures = append(rep.Failures, rowFailure{Row: line, Reason: perr.Err.Error()})
+				}
+				continue
 			}
 			log.Fatalf("read row: %v", err)
 		}
+		if line <= resumeAfter {
+			continue
+		}
 		// Skip empty/short rows
 		if len(rec) <= iTitle {
+			rep.Skipped++
+			rep.Failures = append(rep.Failures, rowFailure{Row: line, Reason: "missing Title column"})
 			continue
 		}
 		title := strings.TrimSpace(rec[iTitle])
 		if title == "" {
+			rep.Skipped++
+			rep.Failures = append(rep.Failures, rowFailure{Row: line, Reason: "empty Title"})
 			continue
 		}
 		row := Row{
+			Line:      line,
 			Title:     title,
 			Desc:      pick(rec, iDesc),
 			Type:      pick(rec, iType),
@@ -108,13 +225,24 @@ func main() {
 			Level:     pick(rec, iLevel),
 			Primary:   splitList(pick(rec, iPrimary)),
 		}
+		rejected := len(normRep.Rejected)
+		if !norm.Entry(normRep, line, &row.Type, &row.BodyPart, &row.Equipment, &row.Level) {
+			rep.Skipped++
+			for _, rj := range normRep.Rejected[rejected:] {
+				rep.Failures = append(rep.Failures, rowFailure{Row: line, Reason: fmt.Sprintf("%s %q is not allowed", rj.Field, rj.Value)})
+			}
+			continue
+		}
 		rows = append(rows, row)
 	}
+	rep.Parsed = len(rows)
+	rep.Normalized = normRep.Normalized
 	log.Printf("parsed %d rows", len(rows))
 	if dryRun {
 		for i := 0; i < len(rows) && i < 10; i++ {
-			log.Printf("%3d: %s [%s] %s | %s", i+1, rows[i].Title, rows[i].Level, rows[i].Type, rows[i].BodyPart)
+			log.Printf("%3d: %s [%s] %s | %s", rows[i].Line, rows[i].Title, rows[i].Level, rows[i].Type, rows[i].BodyPart)
 		}
+		emitReport()
 		return
 	}
 	if dbURL == "" {
@@ -131,26 +259,83 @@ func main() {
 		log.Fatalf("db ping: %v", err)
 	}
 
-	start := time.Now()
 	for i := 0; i < len(rows); i += batch {
 		end := i + batch
 		if end > len(rows) {
 			end = len(rows)
 		}
+		var inserted, updated int
 		err := transact(ctx, db, func(tx *sqlx.Tx) error {
+			inserted, updated = 0, 0
 			for _, row := range rows[i:end] {
-				if err := upsertCatalog(ctx, tx, row); err != nil {
-					return err
+				isNew, err := upsertCatalog(ctx, tx, row)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", row.Line, err)
+				}
+				if isNew {
+					inserted++
+				} else {
+					updated++
 				}
 			}
 			return nil
 		})
 		if err != nil {
-			log.Fatalf("batch upsert failed at %d-%d: %v", i, end, err)
+			rep.Failures = append(rep.Failures, rowFailure{Row: rows[i].Line, Reason: err.Error()})
+			log.Printf("batch upsert failed at rows %d-%d: %v (rerun with --resume to continue)", rows[i].Line, rows[end-1].Line, err)
+			emitReport()
+			os.Exit(1)
+		}
+		rep.Inserted += inserted
+		rep.Updated += updated
+		if err := writeCheckpoint(statePath, checkpoint{CSV: csvPath, LastRow: rows[end-1].Line, UpdatedAt: time.Now().UTC()}); err != nil {
+			log.Printf("write checkpoint: %v", err)
 		}
-		log.Printf("upserted %d/%d", end, len(rows))
+		log.Printf("upserted %d/%d (row %d)", end, len(rows), rows[end-1].Line)
+	}
+	if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("remove checkpoint: %v", err)
 	}
-	log.Printf("done in %s", time.Since(start).Truncate(time.Millisecond))
+	log.Printf("done in %s: %d inserted, %d updated, %d skipped, %d failures",
+		time.Since(start).Truncate(time.Millisecond), rep.Inserted, rep.Updated, rep.Skipped, len(rep.Failures))
+	emitReport()
+}
+
+func readCheckpoint(path string) (checkpoint, error) {
+	var cp checkpoint
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cp, err
+	}
+	err = json.Unmarshal(b, &cp)
+	return cp, err
+}
+
+// writeCheckpoint replaces the state file atomically so a crash mid-write
+// never leaves a truncated checkpoint behind.
+func writeCheckpoint(path string, cp checkpoint) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func writeReport(path string, rep any) error {
+	b, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
 }
 
 func pick(rec []string, i int) string {
@@ -222,7 +407,8 @@ func transact(ctx context.Context, db *sqlx.DB, fn func(*sqlx.Tx) error) error {
 	return tx.Commit()
 }
 
-func upsertCatalog(ctx context.Context, tx *sqlx.Tx, r Row) error {
+// upsertCatalog writes one row and reports whether it was newly inserted.
+func upsertCatalog(ctx context.Context, tx *sqlx.Tx, r Row) (bool, error) {
 	slug := slugify(r.Title)
 	// Ensure reference values exist (FKs)
 	const unspecified = "unspecified"
@@ -244,12 +430,13 @@ func upsertCatalog(ctx context.Context, tx *sqlx.Tx, r Row) error {
 		{level, `insert into levels(name) values ($1) on conflict do nothing`},
 	} {
 		if _, err := tx.ExecContext(ctx, ref.sql, ref.value); err != nil {
-			return err
+			return false, err
 		}
 	}
+	// Alternate spellings resolve to their canonical muscle (see muscle_aliases).
 	for _, muscle := range primaryList {
-		if _, err := tx.ExecContext(ctx, `insert into muscle_types(name) values ($1) on conflict do nothing`, muscle); err != nil {
-			return err
+		if _, err := tx.ExecContext(ctx, `insert into muscle_types(name) values (canonical_muscle($1)) on conflict do nothing`, muscle); err != nil {
+			return false, err
 		}
 	}
 	const q = `
@@ -262,22 +449,24 @@ set name = excluded.name,
     body_part = excluded.body_part,
     equipment = excluded.equipment,
     level = excluded.level
+returning (xmax = 0) as inserted
 `
-	if _, err := tx.ExecContext(ctx, q, r.Title, slug, r.Desc, typeVal, bodyPart, equipment, level); err != nil {
-		return err
+	var inserted bool
+	if err := tx.QueryRowxContext(ctx, q, r.Title, slug, r.Desc, typeVal, bodyPart, equipment, level).Scan(&inserted); err != nil {
+		return false, err
 	}
 	if _, err := tx.ExecContext(ctx, `delete from exercise_catalog_primary_muscles where catalog_id = (select id from exercise_catalog where slug = $1)`, slug); err != nil {
-		return err
+		return false, err
 	}
 	for _, muscle := range primaryList {
 		if _, err := tx.ExecContext(ctx, `
 			insert into exercise_catalog_primary_muscles (catalog_id, muscle)
-			select id, $2 from exercise_catalog where slug = $1
+			select id, canonical_muscle($2) from exercise_catalog where slug = $1
 			on conflict do nothing`, slug, muscle); err != nil {
-	return err
+			return false, err
 		}
 	}
-	return nil
+	return inserted, nil
 }
 
 func defaultString(value, fallback string) string {
@@ -0,0 +1,126 @@
+// Command migrate applies, rolls back and inspects the embedded database
+// migrations.
+//
+//	DATABASE_URL=postgres://... go run ./cmd/migrate status
+//	go run ./cmd/migrate up [--to 017]
+//	go run ./cmd/migrate down [--to 017]   (one step without --to)
+//	go run ./cmd/migrate force 017
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"exercise-tracker/internal/db"
+)
+
+const usage = `usage: migrate [--db URL] <command> [flags]
+
+commands:
+  up [--to VERSION]    apply pending migrations (up to and including VERSION)
+  down [--to VERSION]  roll back the latest migration, or everything after VERSION
+  status               list migrations and whether they are applied
+  force VERSION        mark migrations through VERSION as applied and later ones
+                       as not applied, without running SQL
+
+VERSION is a numeric prefix (017) or a file name (017_add_bodyweight.sql).
+`
+
+func main() {
+	var dbURL string
+	flag.StringVar(&dbURL, "db", os.Getenv("DATABASE_URL"), "Postgres connection URL (or env DATABASE_URL)")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if dbURL == "" {
+		log.Fatalf("DATABASE_URL or --db is required")
+	}
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	sub := flag.NewFlagSet(cmd, flag.ExitOnError)
+	to := sub.String("to", "", "target migration version")
+	_ = sub.Parse(args)
+
+	ctx := context.Background()
+	database, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	defer database.Close()
+
+	switch cmd {
+	case "up":
+		done, err := database.MigrateUp(ctx, *to)
+		report("applied", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		done, err := database.MigrateDown(ctx, *to)
+		report("rolled back", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		if err := status(ctx, database); err != nil {
+			log.Fatal(err)
+		}
+	case "force":
+		if sub.NArg() != 1 {
+			log.Fatalf("force needs exactly one VERSION")
+		}
+		if err := database.ForceMigration(ctx, sub.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("forced schema to %s\n", sub.Arg(0))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func report(verb string, names []string) {
+	if len(names) == 0 {
+		fmt.Printf("nothing %s\n", verb)
+		return
+	}
+	for _, n := range names {
+		fmt.Printf("%s %s\n", verb, n)
+	}
+}
+
+func status(ctx context.Context, database *db.DB) error {
+	states, err := database.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIGRATION\tAPPLIED\tDOWN\tNOTE")
+	for _, s := range states {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		down := "no"
+		if s.Reversible {
+			down = "yes"
+		}
+		note := ""
+		switch {
+		case s.Missing:
+			note = "applied but not in this build"
+		case s.Modified:
+			note = "checksum mismatch"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, applied, down, note)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,284 @@
+// Command seed fills a database with a demo user and a few weeks of realistic
+// training history so analytics and sync endpoints have data to work with.
+//
+//	DATABASE_URL=postgres://... go run ./cmd/seed --weeks 6
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/db"
+	"exercise-tracker/internal/store"
+)
+
+// lift is one exercise in the demo program with its week-one working weight
+// and weekly progression.
+type lift struct {
+	entry     store.CatalogEntry
+	sets      int
+	reps      int
+	startKg   float64
+	perWeekKg float64
+	restSec   int
+}
+
+func catalogEntry(name, bodyPart, equipment string, primary, secondary []string) store.CatalogEntry {
+	return store.CatalogEntry{
+		Name:             name,
+		Type:             "Strength",
+		BodyPart:         bodyPart,
+		Equipment:        equipment,
+		Level:            "Intermediate",
+		PrimaryMuscles:   primary,
+		SecondaryMuscles: secondary,
+	}
+}
+
+// split is a push/pull/legs rotation trained Monday, Wednesday and Friday,
+// with an optional Saturday session.
+var split = [][]lift{
+	{ // push
+		{catalogEntry("Barbell Bench Press", "Chest", "Barbell", []string{"Chest"}, []string{"Triceps", "Shoulders"}), 4, 6, 70, 2.5, 180},
+		{catalogEntry("Overhead Press", "Shoulders", "Barbell", []string{"Shoulders"}, []string{"Triceps"}), 3, 8, 40, 1.25, 150},
+		{catalogEntry("Dumbbell Lateral Raise", "Shoulders", "Dumbbell", []string{"Shoulders"}, nil), 3, 12, 8, 0.5, 60},
+	},
+	{ // pull
+		{catalogEntry("Barbell Deadlift", "Back", "Barbell", []string{"Lower Back"}, []string{"Hamstrings", "Glutes"}), 3, 5, 120, 5, 240},
+		{catalogEntry("Barbell Bent Over Row", "Back", "Barbell", []string{"Middle Back"}, []string{"Lats", "Biceps"}), 4, 8, 60, 2.5, 120},
+		{catalogEntry("Pull Up", "Back", "Body Only", []string{"Lats"}, []string{"Biceps"}), 3, 8, 0, 0, 120},
+	},
+	{ // legs
+		{catalogEntry("Barbell Back Squat", "Legs", "Barbell", []string{"Quadriceps"}, []string{"Glutes", "Hamstrings"}), 4, 5, 100, 2.5, 210},
+		{catalogEntry("Romanian Deadlift", "Legs", "Barbell", []string{"Hamstrings"}, []string{"Glutes", "Lower Back"}), 3, 8, 80, 2.5, 150},
+		{catalogEntry("Standing Calf Raise", "Legs", "Machine", []string{"Calves"}, nil), 3, 12, 60, 2.5, 60},
+	},
+}
+
+func main() {
+	var (
+		dbURL    string
+		email    string
+		password string
+		weeks    int
+		reset    bool
+		seed     int64
+	)
+	flag.StringVar(&dbURL, "db", os.Getenv("DATABASE_URL"), "Postgres connection URL (or env DATABASE_URL)")
+	flag.StringVar(&email, "email", "demo@example.com", "Demo user email")
+	flag.StringVar(&password, "password", "demo-password", "Demo user password")
+	flag.IntVar(&weeks, "weeks", 6, "Weeks of history to generate, ending yesterday")
+	flag.BoolVar(&reset, "reset", false, "Delete the demo user's existing days in the range before seeding")
+	flag.Int64Var(&seed, "seed", 1, "Random seed for reproducible data")
+	flag.Parse()
+	if dbURL == "" {
+		log.Fatalf("DATABASE_URL or --db is required")
+	}
+	if weeks <= 0 {
+		log.Fatalf("--weeks must be positive")
+	}
+
+	ctx := context.Background()
+	database, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	defer database.Close()
+
+	users := store.NewUsers(database.DB)
+	catalog := store.NewCatalog(database.DB, nil)
+	days := store.NewDays(database.DB)
+	exercises := store.NewExercises(database.DB)
+	sets := store.NewSets(database.DB)
+
+	u, err := users.ByEmail(ctx, email)
+	if err != nil {
+		log.Fatalf("lookup user: %v", err)
+	}
+	if u == nil {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			log.Fatalf("hash password: %v", err)
+		}
+		if u, err = users.Create(ctx, email, hash); err != nil {
+			log.Fatalf("create user: %v", err)
+		}
+		log.Printf("created user %s (password %q)", email, password)
+	} else {
+		log.Printf("using existing user %s", email)
+	}
+
+	catalogIDs, err := ensureCatalog(ctx, catalog)
+	if err != nil {
+		log.Fatalf("catalog: %v", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -weeks*7)
+	if reset {
+		res, err := database.ExecContext(ctx, `delete from workout_days where user_id = $1 and workout_date >= $2 and workout_date < $3`, u.ID, from, today)
+		if err != nil {
+			log.Fatalf("reset days: %v", err)
+		}
+		n, _ := res.RowsAffected()
+		log.Printf("removed %d existing days", n)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	var nDays, nSets int
+	session := 0
+	for d := from; d.Before(today); d = d.AddDate(0, 0, 1) {
+		wd := d.Weekday()
+		trains := wd == time.Monday || wd == time.Wednesday || wd == time.Friday ||
+			(wd == time.Saturday && rng.Intn(3) == 0)
+		if !trains {
+			continue
+		}
+		existing, err := days.GetByUserAndDate(ctx, u.ID, d)
+		if err != nil {
+			log.Fatalf("lookup day %s: %v", d.Format("2006-01-02"), err)
+		}
+		if existing != nil {
+			continue
+		}
+		day, err := days.Create(ctx, u.ID, d)
+		if err != nil {
+			log.Fatalf("create day: %v", err)
+		}
+		week := int(d.Sub(from).Hours() / (24 * 7))
+		start := d.Add(17*time.Hour + time.Duration(rng.Intn(120))*time.Minute)
+		for pos, l := range split[session%len(split)] {
+			ex, err := exercises.Create(ctx, u.ID, day.ID, catalogIDs[l.entry.Name], pos+1, nil)
+			if err != nil {
+				log.Fatalf("create exercise: %v", err)
+			}
+			n, err := seedSets(ctx, sets, rng, u.ID, ex.ID, l, week, &start)
+			if err != nil {
+				log.Fatalf("create sets: %v", err)
+			}
+			nSets += n
+		}
+		session++
+		nDays++
+	}
+
+	if _, err := database.ExecContext(ctx, `refresh materialized view set_facts`); err != nil {
+		log.Printf("refresh set_facts: %v", err)
+	}
+	log.Printf("seeded %d days and %d sets for %s (%s to %s)", nDays, nSets, email,
+		from.Format("2006-01-02"), today.AddDate(0, 0, -1).Format("2006-01-02"))
+}
+
+// ensureCatalog makes sure every lift exists in the catalog without touching
+// entries that are already there, and returns their ids by name.
+func ensureCatalog(ctx context.Context, catalog *store.Catalog) (map[string]string, error) {
+	ids := make(map[string]string)
+	for _, day := range split {
+		for _, l := range day {
+			if _, ok := ids[l.entry.Name]; ok {
+				continue
+			}
+			rec, err := catalog.GetCatalogEntryBySlug(ctx, slugify(l.entry.Name))
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if rec == nil {
+				if _, err := catalog.Upsert(ctx, []store.CatalogEntry{l.entry}); err != nil {
+					return nil, err
+				}
+				if rec, err = catalog.GetCatalogEntryBySlug(ctx, slugify(l.entry.Name)); err != nil {
+					return nil, err
+				}
+			}
+			ids[l.entry.Name] = rec.ID
+		}
+	}
+	return ids, nil
+}
+
+// seedSets logs two warm-ups (for loaded lifts) and the working sets, with a
+// rest between each set. Weights follow the weekly progression with the odd
+// missed rep so the history isn't perfectly linear.
+func seedSets(ctx context.Context, sets *store.Sets, rng *rand.Rand, userID, exerciseID string, l lift, week int, clock *time.Time) (int, error) {
+	working := l.startKg + float64(week)*l.perWeekKg
+	type plan struct {
+		reps   int
+		kg     float64
+		warmup bool
+	}
+	var plans []plan
+	if working >= 40 {
+		plans = append(plans,
+			plan{reps: 8, kg: roundKg(working * 0.5), warmup: true},
+			plan{reps: 5, kg: roundKg(working * 0.75), warmup: true},
+		)
+	}
+	for i := 0; i < l.sets; i++ {
+		reps := l.reps
+		if i == l.sets-1 && rng.Intn(4) == 0 {
+			reps--
+		}
+		plans = append(plans, plan{reps: reps, kg: roundKg(working)})
+	}
+
+	for i, p := range plans {
+		performed := *clock
+		var rpe *float64
+		if !p.warmup {
+			v := 7 + float64(rng.Intn(5))*0.5
+			rpe = &v
+		}
+		if _, err := sets.Create(ctx, store.CreateSetParams{
+			ExerciseID:  exerciseID,
+			UserID:      userID,
+			Position:    i + 1,
+			Reps:        p.reps,
+			WeightKg:    p.kg,
+			RPE:         rpe,
+			IsWarmup:    p.warmup,
+			PerformedAt: &performed,
+		}); err != nil {
+			return 0, err
+		}
+		rest := l.restSec
+		if p.warmup {
+			rest = 60
+		}
+		rest += rng.Intn(31) - 15
+		if i < len(plans)-1 {
+			if _, err := sets.CreateRest(ctx, store.CreateRestParams{
+				ExerciseID:      exerciseID,
+				UserID:          userID,
+				Position:        i + 1,
+				DurationSeconds: rest,
+			}); err != nil {
+				return 0, err
+			}
+		}
+		*clock = clock.Add(time.Duration(rest+40) * time.Second)
+	}
+	return len(plans), nil
+}
+
+func roundKg(kg float64) float64 {
+	return math.Round(kg/2.5) * 2.5
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify matches the catalog store's slug rules.
+func slugify(name string) string {
+	s := strings.ToLower(name)
+	s = nonAlnum.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,51 +13,206 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
 
+	"exercise-tracker/internal/achievements"
+	"exercise-tracker/internal/assist"
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/blob"
+	"exercise-tracker/internal/catalognorm"
 	"exercise-tracker/internal/config"
 	"exercise-tracker/internal/db"
+	"exercise-tracker/internal/deadlinks"
+	"exercise-tracker/internal/freeexercisedb"
+	"exercise-tracker/internal/grpcapi"
 	apphttp "exercise-tracker/internal/http"
 	"exercise-tracker/internal/http/handlers"
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/jobs"
+	"exercise-tracker/internal/linkmeta"
+	"exercise-tracker/internal/logging"
+	"exercise-tracker/internal/notify"
+	"exercise-tracker/internal/realtime"
+	"exercise-tracker/internal/recap"
+	"exercise-tracker/internal/reminders"
 	"exercise-tracker/internal/store"
 )
 
 func main() {
 	cfg := config.MustLoad()
+	logLevel, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	logging.SetLevel(logLevel)
+	logging.SetRedact(cfg.LogRedact)
 
 	ctx := context.Background()
-	database, err := db.Connect(ctx, cfg.DatabaseURL)
+	database, err := db.ConnectPool(ctx, cfg.DatabaseURL, db.PoolConfig{
+		MaxOpenConns:     cfg.DBMaxOpenConns,
+		MaxIdleConns:     cfg.DBMaxIdleConns,
+		ConnMaxLifetime:  cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.DBConnMaxIdleTime,
+		StatementTimeout: cfg.DBStatementTimeout,
+	})
 	if err != nil {
 		log.Fatalf("db connect: %v", err)
 	}
 	defer database.Close()
+	if cfg.DatabaseReadURL != "" {
+		if err := database.ConnectReader(ctx, cfg.DatabaseReadURL); err != nil {
+			log.Fatalf("db connect: %v", err)
+		}
+	}
 
-	if err := database.Migrate(ctx); err != nil {
-		log.Fatalf("db migrate: %v", err)
+	if cfg.AutoMigrate {
+		if err := database.Migrate(ctx); err != nil {
+			log.Fatalf("db migrate: %v", err)
+		}
+	} else {
+		log.Println("AUTO_MIGRATE=false: skipping migrations")
 	}
 
 	usersStore := store.NewUsers(database.DB)
 	daysStore := store.NewDays(database.DB)
 	exercisesStore := store.NewExercises(database.DB)
 	setsStore := store.NewSets(database.DB)
-	catalogStore := store.NewCatalog(database.DB)
+	var blobStore blob.Store
+	switch cfg.BlobBackend {
+	case "s3":
+		blobStore, err = blob.NewS3(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+		if err != nil {
+			log.Fatalf("blob store: %v", err)
+		}
+	case "postgres", "":
+		blobStore = blob.NewPostgres(database.DB)
+	default:
+		log.Fatalf("unknown BLOB_BACKEND %q", cfg.BlobBackend)
+	}
+
+	catalogStore := store.NewCatalog(database.DB, blobStore)
+	catalogStore.EnableCache(cfg.CatalogCacheTTL)
+	catalogStore.UseReader(database.Reader())
+	var linkFetcher *linkmeta.Fetcher
+	if cfg.LinkMetadataTimeout > 0 {
+		linkFetcher = linkmeta.New(catalogStore, cfg.LinkMetadataTimeout)
+	}
 	saveStore := store.NewSave(database.DB)
+	saveStore.SetStrict(cfg.SaveStrictOps)
+	auditStore := store.NewAudit(database.DB)
+	programsStore := store.NewPrograms(database.DB)
+	analyticsStore := store.NewAnalytics(database.Reader())
+
+	apiKeysStore := store.NewAPIKeys(database.DB)
+	sessionsStore := store.NewSessions(database.DB)
+	settingsStore := store.NewSettings(database.DB)
+	bodyweightStore := store.NewBodyweight(database.DB)
+	gymsStore := store.NewGyms(database.DB)
+	remindersStore := store.NewReminders(database.DB)
+	usageStore := store.NewUsage(database.DB)
+
+	webPush, err := notify.NewWebPush(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+	if err != nil {
+		log.Fatalf("web push: %v", err)
+	}
+	jobQueue := jobs.New(database.DB)
+	reminderScheduler := &reminders.Scheduler{
+		Reminders: remindersStore,
+		Users:     usersStore,
+		Queue:     jobQueue,
+		Mail:      notify.NewMailer(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword),
+		Push:      webPush,
+	}
+	reminderScheduler.Register()
+	hub := realtime.NewHub()
+	achievementRules, err := achievements.Load(cfg.AchievementsFile)
+	if err != nil {
+		log.Fatalf("config: ACHIEVEMENTS_FILE: %v", err)
+	}
+	achievementsStore := store.NewAchievements(database.DB)
+	achievementsEngine := &achievements.Engine{
+		Rules:        achievementRules,
+		Achievements: achievementsStore,
+		Reminders:    remindersStore,
+		Queue:        jobQueue,
+		Push:         webPush,
+		Hub:          hub,
+	}
+	achievementsEngine.Register()
+	recapsStore := store.NewRecaps(database.DB)
+	biometricsStore := store.NewBiometrics(database.DB)
+	nutritionStore := store.NewNutrition(database.DB)
+	checkinsStore := store.NewCheckins(database.DB)
+	goalsStore := store.NewGoals(database.DB)
+	recapScheduler := &recap.Scheduler{Recaps: recapsStore, Queue: jobQueue}
+	recapScheduler.Register()
+	var linkChecker *deadlinks.Checker
+	if cfg.LinkCheckInterval > 0 {
+		linkChecker = &deadlinks.Checker{Store: catalogStore, Queue: jobQueue, Interval: cfg.LinkCheckInterval}
+		linkChecker.Register()
+	}
+	freeExerciseImages := &freeexercisedb.Downloader{Store: catalogStore, Queue: jobQueue}
+	freeExerciseImages.Register()
 
 	authCfg := middleware.AuthConfig{
 		JWTSecret:    cfg.JWTSecret,
 		CookieDomain: cfg.CookieDomain,
+		APIKeys:      apiKeysStore,
+		Sessions:     sessionsStore,
 	}
 
 	authHandler := &handlers.AuthHandler{
-		Users:        usersStore,
-		JWTSecret:    cfg.JWTSecret,
-		CookieDomain: cfg.CookieDomain,
+		Users:         usersStore,
+		JWTSecret:     cfg.JWTSecret,
+		CookieDomain:  cfg.CookieDomain,
+		Audit:         auditStore,
+		Sessions:      sessionsStore,
+		TwoFactor:     store.NewTwoFactor(database.DB),
+		GuestMode:     cfg.GuestMode,
+		DeletionGrace: cfg.AccountDeletionGrace,
+		Throttle: auth.NewLoginThrottle(auth.ThrottleConfig{
+			MaxFailures:      cfg.LoginMaxFailures,
+			MaxFailuresPerIP: cfg.LoginMaxFailuresPerIP,
+			Window:           cfg.LoginFailureWindow,
+			Lockout:          cfg.LoginLockout,
+		}),
+	}
+	var summarizer assist.Summarizer = assist.Rules{}
+	if cfg.AssistLLMURL != "" {
+		summarizer = &assist.LLM{
+			URL:    cfg.AssistLLMURL,
+			APIKey: cfg.AssistLLMAPIKey,
+			Model:  cfg.AssistLLMModel,
+			Client: &http.Client{Timeout: cfg.AssistLLMTimeout},
+		}
+	}
+	daysHandler := &handlers.DaysHandler{Days: daysStore, Achievements: achievementsEngine, Assist: summarizer}
+	shareHandler := &handlers.ShareHandler{Days: daysStore, JWTSecret: cfg.JWTSecret}
+	exercisesHandler := &handlers.ExercisesHandler{Exercises: exercisesStore, Catalog: catalogStore, Sets: setsStore, Settings: settingsStore}
+	setsHandler := &handlers.SetsHandler{Sets: setsStore, Hub: hub, Achievements: achievementsEngine}
+	catalogHandler := &handlers.CatalogHandler{Catalog: catalogStore, Audit: auditStore, Gyms: gymsStore, Links: linkFetcher}
+	saveHandler := &handlers.SaveHandler{
+		Service:      saveStore,
+		Sets:         setsStore,
+		Hub:          hub,
+		Achievements: achievementsEngine,
+		Days:         daysStore,
+		Catalog:      catalogStore,
+		Limits: store.SaveLimits{
+			MaxOps:       cfg.SaveMaxOps,
+			MaxBodyBytes: cfg.SaveMaxBodyBytes,
+			MaxStringLen: cfg.SaveMaxStringLen,
+		},
 	}
-	daysHandler := &handlers.DaysHandler{Days: daysStore}
-	exercisesHandler := &handlers.ExercisesHandler{Exercises: exercisesStore}
-	setsHandler := &handlers.SetsHandler{Sets: setsStore}
-	catalogHandler := &handlers.CatalogHandler{Catalog: catalogStore}
-	saveHandler := &handlers.SaveHandler{Service: saveStore}
+	programsHandler := &handlers.ProgramsHandler{Programs: programsStore}
+	analyticsHandler := &handlers.AnalyticsHandler{Analytics: analyticsStore}
 	// Admin emails set
 	adminSet := map[string]struct{}{}
 	if cfg.AdminEmails != "" {
@@ -67,55 +223,341 @@ func main() {
 			}
 		}
 	}
+	coachingStore := store.NewCoaching(database.DB)
+	coachingHandler := &handlers.CoachingHandler{Coaching: coachingStore, Users: usersStore}
+	apiKeysHandler := &handlers.APIKeysHandler{Keys: apiKeysStore}
+	settingsHandler := &handlers.SettingsHandler{Settings: settingsStore}
+	toolsHandler := &handlers.ToolsHandler{Settings: settingsStore}
+	bodyweightHandler := &handlers.BodyweightHandler{Bodyweight: bodyweightStore}
+	gymsHandler := &handlers.GymsHandler{Gyms: gymsStore}
+	remindersHandler := &handlers.RemindersHandler{Reminders: remindersStore, Push: webPush}
+	usageHandler := &handlers.UsageHandler{Usage: usageStore}
+	commentsHandler := &handlers.CommentsHandler{Comments: store.NewComments(database.DB), Hub: hub}
+	journalStore := store.NewJournal(database.DB)
+	journalHandler := &handlers.JournalHandler{Journal: journalStore}
+	templatesHandler := &handlers.TemplatesHandler{Templates: store.NewTemplates(database.DB)}
+	leaderboardsHandler := &handlers.LeaderboardsHandler{Leaderboards: store.NewLeaderboards(database.DB)}
+	friendsHandler := &handlers.FriendsHandler{Friends: store.NewFriends(database.DB), Feed: store.NewFeed(database.DB), Users: usersStore}
+	challengesHandler := &handlers.ChallengesHandler{Challenges: store.NewChallenges(database.DB)}
+	achievementsHandler := &handlers.AchievementsHandler{Achievements: achievementsStore, Rules: achievementRules}
+	recapHandler := &handlers.RecapHandler{Recaps: recapsStore}
+	biometricsHandler := &handlers.BiometricsHandler{Biometrics: biometricsStore}
+	nutritionHandler := &handlers.NutritionHandler{Nutrition: nutritionStore}
+	checkinsHandler := &handlers.CheckinsHandler{Checkins: checkinsStore}
+	goalsHandler := &handlers.GoalsHandler{Goals: goalsStore}
+	maintenance := middleware.NewMaintenance(cfg.MaintenanceMode, cfg.MaintenanceReason)
+	normalizeRules, err := catalognorm.Load(cfg.CatalogNormalizeFile)
+	if err != nil {
+		log.Fatalf("config: CATALOG_NORMALIZE_FILE: %v", err)
+	}
 	adminHandler := &handlers.AdminHandler{
 		Users:       usersStore,
 		Catalog:     catalogStore,
+		Audit:       auditStore,
 		AdminEmails: adminSet,
+		Links:       linkFetcher,
+		Maintenance: maintenance,
+		Usage:       usageStore,
+		Analytics:   store.NewAnalytics(database.DB),
+		Normalize:   normalizeRules,
+		Images:      freeExerciseImages,
+	}
+
+	origins, err := apphttp.ParseOrigins(cfg.FrontendOrigin)
+	if err != nil {
+		log.Fatalf("config: FRONTEND_ORIGIN: %v", err)
 	}
+	realtimeHandler := &handlers.RealtimeHandler{Hub: hub, Origins: origins}
+	// Conditional PATCHes lock the row they check, so they need a
+	// transaction even when TX_PER_REQUEST is off.
+	lockTx := middleware.Transaction(database.DB)
+	router := apphttp.NewRouter(origins, authCfg.Middleware, func(r chi.Router) {
+		// Read-only shared workouts (no auth; the token is the credential)
+		r.Get("/public/workouts/{token}", shareHandler.Get)
+
+		// Read-only catalog for logged-out views, rate limited per IP
+		if cfg.PublicCatalog {
+			r.Route("/public/catalog", func(r chi.Router) {
+				if cfg.CompressResponses {
+					r.Use(middleware.Compress)
+				}
+				r.Use(middleware.NewRateLimiter(cfg.PublicCatalogRate, cfg.PublicCatalogRate).Middleware)
+				r.Get("/", catalogHandler.PublicSearch) // same query as /api/catalog, without ?gym
+				r.Get("/facets", catalogHandler.PublicFacets)
+				r.Get("/entries/{id}", catalogHandler.PublicGetEntry)
+			})
+		}
 
-	router := apphttp.NewRouter(cfg.FrontendOrigin, authCfg.Middleware, func(r chi.Router) {
 		r.Route("/api", func(r chi.Router) {
+			if cfg.CompressResponses {
+				r.Use(middleware.Compress)
+			}
+			r.Use(maintenance.Middleware("/api/auth/login", "/api/auth/logout", "/api/admin/maintenance"))
+			if cfg.TxPerRequest {
+				r.Use(middleware.Transaction(database.DB))
+			}
+
 			// Public auth routes
 			r.Route("/auth", func(r chi.Router) {
 				r.Post("/register", authHandler.Register)
 				r.Post("/login", authHandler.Login)
 				r.Post("/logout", authHandler.Logout)
+				r.With(middleware.NewRateLimiter(cfg.GuestRate, cfg.GuestRate).Middleware).Post("/guest", authHandler.Guest)
+				r.Post("/claim", authCfg.Middleware(http.HandlerFunc(authHandler.Claim)).ServeHTTP) // body {email, password}
 				r.Get("/me", authCfg.Middleware(http.HandlerFunc(authHandler.Me)).ServeHTTP)
+				r.Delete("/me", authCfg.Middleware(http.HandlerFunc(authHandler.DeleteMe)).ServeHTTP) // body {password, soft}
+				r.Get("/sessions", authCfg.Middleware(http.HandlerFunc(authHandler.ListSessions)).ServeHTTP)
+				r.Delete("/sessions/{id}", authCfg.Middleware(http.HandlerFunc(authHandler.RevokeSession)).ServeHTTP)
+				r.Route("/2fa", func(r chi.Router) {
+					r.Use(authCfg.Middleware)
+					r.Get("/", authHandler.TwoFactorStatus)
+					r.Post("/enroll", authHandler.TwoFactorEnroll)
+					r.Post("/enable", authHandler.TwoFactorEnable)                // body {code}
+					r.Post("/disable", authHandler.TwoFactorDisable)              // body {password, code | recoveryCode}
+					r.Post("/recovery-codes", authHandler.TwoFactorRecoveryCodes) // body {code}
+				})
 			})
 
 			// Authenticated routes
-				r.Group(func(r chi.Router) {
-					r.Use(authCfg.Middleware)
-				r.Get("/days", daysHandler.GetByDate)        // /api/days?date=YYYY-MM-DD&ensure=true
-				r.Post("/days", daysHandler.Create)          // body {date}
-				r.Patch("/days/{dayId}", daysHandler.Update) // body {isRestDay}
+			r.Group(func(r chi.Router) {
+				r.Use(authCfg.Middleware)
+				r.Get("/days", daysHandler.GetByDate)                     // /api/days?date=YYYY-MM-DD&ensure=true
+				r.Post("/days", daysHandler.Create)                       // body {date}
+				r.Post("/days/batch", daysHandler.Batch)                  // body {ids?, dates?}
+				r.Get("/days/week", daysHandler.Week)                     // ?start=YYYY-MM-DD
+				r.Get("/search", daysHandler.Search)                      // ?q=
+				r.With(lockTx).Patch("/days/{dayId}", daysHandler.Update) // body {isRestDay?, notes?, visibility?}
+				r.Get("/days/{dayId}/adherence", analyticsHandler.DayAdherence)
+				r.Get("/days/{dayId}/timeline", daysHandler.SessionTimeline)
+				r.Get("/days/{dayId}/history", journalHandler.DayHistory) // ?limit=
+				r.Post("/undo", journalHandler.Undo)
+				r.Post("/days/{dayId}/start", daysHandler.StartSession)   // body {at?}
+				r.Post("/days/{dayId}/finish", daysHandler.FinishSession) // body {at?}
+				r.Post("/days/{dayId}/share", shareHandler.Create)        // body {expiresInHours?}
+				r.With(middleware.NewRateLimiter(cfg.AssistLLMRate, cfg.AssistLLMRate).Middleware).
+					Post("/days/{dayId}/summarize", daysHandler.Summarize)
+				r.With(middleware.DecompressRequest).Post("/days/{dayId}/biometrics", biometricsHandler.Put) // body {heartRate: [{at, bpm}], profile?}
+				r.Get("/days/{dayId}/biometrics", biometricsHandler.Get)
+				r.Delete("/days/{dayId}/biometrics", biometricsHandler.Delete)
+				r.Get("/days/{dayId}/comments", commentsHandler.List)
+				r.Post("/days/{dayId}/comments", commentsHandler.Create) // body {exerciseId?, body}
+				r.Post("/days/{dayId}/comments/read", commentsHandler.MarkRead)
+				r.Delete("/comments/{id}", commentsHandler.Delete)
+				r.Get("/comments/unread", commentsHandler.Unread)
 				r.Post("/days/{dayId}/exercises", exercisesHandler.Create)
-				r.Patch("/exercises/{id}", exercisesHandler.Update)
+				r.Patch("/days/{dayId}/exercises/order", exercisesHandler.Reorder) // body {orderedIds}
+				r.With(lockTx).Patch("/exercises/{id}", exercisesHandler.Update)
 				r.Delete("/exercises/{id}", exercisesHandler.Delete)
+				r.Post("/exercises/{id}/move", exercisesHandler.Move) // body {dayId, position?}
+				r.Get("/exercises/{id}/timeline", daysHandler.ExerciseTimeline)
+				r.Post("/exercises/{id}/generate-warmups", exercisesHandler.GenerateWarmups) // ?workingWeight=&create=true
+				r.Get("/exercises/{id}/suggestion", exercisesHandler.Suggestion)             // {id} is a catalog id
 				r.Post("/exercises/{id}/sets", setsHandler.Create)
-				r.Patch("/sets/{id}", setsHandler.Update)
+				r.Patch("/exercises/{id}/sets/order", setsHandler.Reorder) // body {orderedIds}
+				r.With(lockTx).Patch("/sets/{id}", setsHandler.Update)
 				r.Delete("/sets/{id}", setsHandler.Delete)
 				r.Post("/exercises/{id}/rests", setsHandler.CreateRest)
 				r.Patch("/rests/{id}", setsHandler.UpdateRest)
 				r.Delete("/rests/{id}", setsHandler.DeleteRest)
 
 				// Catalog search
-				r.Get("/catalog", catalogHandler.Search)
+				r.Get("/catalog", catalogHandler.Search) // ?gym=<profileId> limits to that profile's equipment
 				r.Get("/catalog/facets", catalogHandler.Facets)
+				r.Get("/catalog/submissions", catalogHandler.Submissions) // caller's own, with review state
+				r.Post("/catalog/submissions", catalogHandler.Submit)     // queued as pending for admin review
 				r.Get("/catalog/entries/{id}", catalogHandler.GetEntry)
 				r.Put("/catalog/entries/{id}", catalogHandler.UpdateEntry)
 				r.Delete("/catalog/entries/{id}", catalogHandler.DeleteEntry)
 				r.Get("/catalog/entries/{id}/stats", catalogHandler.GetExerciseStats)
+				r.Get("/catalog/entries/{id}/muscle-map", catalogHandler.MuscleMap)
+				r.Get("/catalog/entries/{id}/progress", catalogHandler.GetProgress) // ?metric=e1rm|topset|volume&range=6m&points=100
 				// Catalog images
 				r.Get("/catalog/entries/{id}/image", catalogHandler.GetImage)
 
 				// Admin-only routes
 				r.Post("/catalog/admin/import", adminHandler.UpsertCatalogJSON)
 				r.Post("/catalog/admin/import/csv", adminHandler.UpsertCatalogCSV)
+				r.Post("/catalog/admin/import/free-exercise-db", adminHandler.ImportFreeExerciseDB) // body exercises.json; ?images=false skips image downloads
+				r.Get("/catalog/admin/audit", adminHandler.ListAudit)
+				r.Get("/catalog/admin/cache", adminHandler.CacheStats)
+				r.Get("/catalog/admin/export", adminHandler.ExportCatalog)
+				r.Post("/catalog/admin/merge", adminHandler.MergeCatalog)          // body {sourceId, targetId}
+				r.Get("/catalog/admin/duplicates", adminHandler.CatalogDuplicates) // ?minSimilarity=0.6&limit=50
+				r.Get("/catalog/admin/deadlinks", adminHandler.DeadLinks)          // ?limit=100
+				r.Get("/catalog/admin/submissions", adminHandler.ListSubmissions)  // ?status=pending|approved|rejected|all
+				r.Post("/catalog/admin/submissions/{id}/approve", adminHandler.ApproveSubmission)
+				r.Post("/catalog/admin/submissions/{id}/reject", adminHandler.RejectSubmission) // body {feedback}
+				r.Get("/catalog/admin/entries/{id}/translations", adminHandler.ListTranslations)
+				r.Put("/catalog/admin/entries/{id}/translations/{locale}", adminHandler.PutTranslation) // body {name, description?}
+				r.Delete("/catalog/admin/entries/{id}/translations/{locale}", adminHandler.DeleteTranslation)
+				r.Get("/catalog/admin/muscles", adminHandler.ListMuscles)
+				r.Post("/catalog/admin/muscles/merge", adminHandler.MergeMuscles) // body {source, target}
+
+				// Maintenance mode
+				r.Get("/admin/maintenance", adminHandler.GetMaintenance)
+				r.Put("/admin/maintenance", adminHandler.SetMaintenance) // body {enabled, reason?}
+
+				// Storage quotas
+				r.Get("/admin/quotas", adminHandler.GetQuotaDefaults)
+				r.Put("/admin/quotas", adminHandler.SetQuotaDefaults) // body {maxWorkoutDays, maxSets}; null = unlimited
+				r.Get("/admin/users/{id}/usage", adminHandler.GetUserUsage)
+				r.Put("/admin/users/{id}/quota", adminHandler.SetUserQuota) // body {maxWorkoutDays, maxSets}; null = default
+				r.Delete("/admin/users/{id}/quota", adminHandler.DeleteUserQuota)
+
+				// Analytics rollups
+				r.Post("/admin/stats/recompute", adminHandler.RecomputeStats) // body {userId?}; all users without
+
+				// Programs
+				r.Get("/programs", programsHandler.List)
+				r.Post("/programs", programsHandler.Create)
+				r.Get("/programs/templates", programsHandler.Templates)
+				r.Post("/programs/templates/{templateId}", programsHandler.FromTemplate) // body {trainingMaxes, weeks?, name?}
+				r.Get("/programs/{id}", programsHandler.Get)
+				r.Put("/programs/{id}", programsHandler.Update)
+				r.Delete("/programs/{id}", programsHandler.Delete)
+				r.Post("/programs/{id}/schedule", programsHandler.Schedule) // body {startDate}
+
+				// Templates shared by code
+				r.Post("/templates/share", templatesHandler.Publish) // body {kind: day|program, id}
+				r.Get("/templates/shares", templatesHandler.List)
+				r.Delete("/templates/shares/{code}", templatesHandler.Delete)
+				r.Get("/templates/{code}", templatesHandler.Get)
+				r.Post("/templates/import/{code}", templatesHandler.Import) // body {date?}
+
+				// Stats
+				r.Get("/stats/muscle-split", analyticsHandler.MuscleSplit)          // ?weeks=8&secondaryFactor=0.5
+				r.Get("/stats/session-duration", analyticsHandler.SessionDurations) // ?weeks=8
+				r.Get("/stats/load", analyticsHandler.TrainingLoad)                 // ?weeks=12
+				r.Get("/stats/cardio", analyticsHandler.Cardio)                     // ?weeks=8
+				r.Get("/stats/sides", analyticsHandler.SideBalance)                 // ?weeks=8
+				r.Get("/stats/recovery", analyticsHandler.Recovery)                 // ?weeks=12
+				r.Get("/stats/recap", recapHandler.Get)                             // ?year=2025 (default last year)
+
+				// Leaderboards (opt-in, ranked by e1RM / bodyweight)
+				r.Get("/leaderboards/{catalogId}", leaderboardsHandler.Get) // ?formula=epley&limit=50
+				r.Get("/settings/leaderboard", leaderboardsHandler.Profile)
+				r.Patch("/settings/leaderboard", leaderboardsHandler.UpdateProfile) // body {optedIn?, hideIdentity?, displayName?}
+
+				// Friends and activity feed (days shared with friends only)
+				r.Get("/friends", friendsHandler.List)
+				r.Post("/friends", friendsHandler.Request) // body {email}; accepts their request if they sent one
+				r.Post("/friends/{id}/accept", friendsHandler.Accept)
+				r.Delete("/friends/{id}", friendsHandler.Remove) // unfriend, cancel or decline
+				r.Get("/feed", friendsHandler.ListFeed)          // ?before=YYYY-MM-DD&limit=20
+
+				// Challenges
+				r.Get("/challenges", challengesHandler.List)     // ?status=active|upcoming|past|all
+				r.Post("/challenges", challengesHandler.Create)  // body {name, metric, catalogId?, startsOn, endsOn, public?}
+				r.Get("/challenges/{id}", challengesHandler.Get) // with standings
+				r.Delete("/challenges/{id}", challengesHandler.Delete)
+				r.Post("/challenges/{id}/join", challengesHandler.Join)
+				r.Delete("/challenges/{id}/join", challengesHandler.Leave)
+
+				// Achievements and the unlock webhook
+				r.Get("/achievements", achievementsHandler.List)
+				r.Get("/achievements/webhook", achievementsHandler.Webhook)
+				r.Put("/achievements/webhook", achievementsHandler.SetWebhook) // body {url}; returns the signing secret
+				r.Delete("/achievements/webhook", achievementsHandler.DeleteWebhook)
 
 				// Batch save
-				r.Post("/save", saveHandler.Handle)
+				r.With(middleware.DecompressRequest).Post("/save", saveHandler.Handle) // Content-Encoding: gzip accepted
 				r.Get("/save/epoch", saveHandler.Epoch)
+				r.Post("/quicklog", saveHandler.QuickLog) // body {text, date?, catalogIds?, dryRun?}
+				r.Post("/voicelog", saveHandler.VoiceLog) // body {transcript, dayId, exerciseId?, dryRun?}
+
+				// Realtime push (rest timers, epoch bumps, PRs)
+				r.Get("/ws", realtimeHandler.Connect)
+
+				// Bodyweight
+				r.Get("/bodyweight", bodyweightHandler.List) // ?from=&to=
+				r.Post("/bodyweight", bodyweightHandler.Log) // body {date?, weightKg}
+				r.Delete("/bodyweight/{id}", bodyweightHandler.Delete)
+				r.Get("/nutrition", nutritionHandler.List)                // ?from=&to=
+				r.Post("/nutrition", nutritionHandler.Log)                // body {date?, calories, proteinG, carbsG?, fatG?, note?}
+				r.Get("/nutrition/dashboard", nutritionHandler.Dashboard) // ?from=&to= (default last 30 days)
+				r.Delete("/nutrition/{id}", nutritionHandler.Delete)
+				r.Get("/checkins", checkinsHandler.List)       // ?from=&to=
+				r.Put("/checkins/{date}", checkinsHandler.Put) // body {sleepHours?, soreness?, motivation?, note?}
+				r.Delete("/checkins/{date}", checkinsHandler.Delete)
+				r.Get("/goals", goalsHandler.List)
+				r.Post("/goals", goalsHandler.Create) // body {catalogId, metric: e1rm|weight|reps, target, atWeightKg?, formula?, targetDate?, note?}
+				r.Get("/goals/{id}", goalsHandler.Get)
+				r.Put("/goals/{id}", goalsHandler.Update)
+				r.Delete("/goals/{id}", goalsHandler.Delete)
+
+				// Gym profiles (equipment available per location)
+				r.Get("/gyms", gymsHandler.List)
+				r.Post("/gyms", gymsHandler.Create) // body {name, kind: home|commercial, equipment}
+				r.Get("/gyms/{id}", gymsHandler.Get)
+				r.Put("/gyms/{id}", gymsHandler.Update)
+				r.Delete("/gyms/{id}", gymsHandler.Delete)
+
+				// Settings
+				r.Get("/settings", settingsHandler.Get)
+				r.Patch("/settings", settingsHandler.Update) // body {barWeightKg?, plateIncrementKg?, units?, plates?, barWeights?, defaultRestSeconds?}
+				r.Get("/settings/usage", usageHandler.Get)
+				r.Get("/settings/rest", settingsHandler.ExerciseRests)
+				r.Put("/settings/rest/{catalogId}", settingsHandler.SetExerciseRest) // body {restSeconds}
+				r.Delete("/settings/rest/{catalogId}", settingsHandler.DeleteExerciseRest)
+				r.Get("/tools/plates", toolsHandler.Plates) // ?target=102.5&bar=20
+
+				// API keys (cookie sessions only)
+				r.Get("/settings/api-keys", apiKeysHandler.List)
+				r.Post("/settings/api-keys", apiKeysHandler.Create) // body {name, scope: read|write}
+				r.Delete("/settings/api-keys/{id}", apiKeysHandler.Revoke)
+
+				// Workout reminders and web push subscriptions
+				r.Get("/settings/reminders", remindersHandler.List)
+				r.Post("/settings/reminders", remindersHandler.Create) // body {daysOfWeek, time: HH:MM, timezone, channels, enabled?}
+				r.Put("/settings/reminders/{id}", remindersHandler.Update)
+				r.Delete("/settings/reminders/{id}", remindersHandler.Delete)
+				r.Get("/settings/push/key", remindersHandler.PushKey)
+				r.Post("/settings/push/subscriptions", remindersHandler.Subscribe)     // body PushSubscription.toJSON()
+				r.Delete("/settings/push/subscriptions", remindersHandler.Unsubscribe) // body {endpoint}
+
+				// Coaching
+				r.Get("/coaches", coachingHandler.ListCoaches)
+				r.Post("/coaches", coachingHandler.Invite) // body {email, canWrite}
+				r.Delete("/coaches/{id}", coachingHandler.Remove)
+				r.Get("/clients", coachingHandler.ListClients) // active clients + pending invites
+				r.Post("/clients/invites/{id}/accept", coachingHandler.Accept)
+
+				// Delegated access: the same handlers, run as the client
+				r.Route("/clients/{userId}", func(r chi.Router) {
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.Delegated(coachingStore))
+						r.Get("/days", daysHandler.GetByDate)
+						r.Get("/days/week", daysHandler.Week)
+						r.Post("/days", daysHandler.Create)
+						r.With(lockTx).Patch("/days/{dayId}", daysHandler.Update)
+						r.Get("/days/{dayId}/adherence", analyticsHandler.DayAdherence)
+						r.Get("/days/{dayId}/timeline", daysHandler.SessionTimeline)
+						r.Post("/days/{dayId}/exercises", exercisesHandler.Create)
+						r.Patch("/days/{dayId}/exercises/order", exercisesHandler.Reorder)
+						r.With(lockTx).Patch("/exercises/{id}", exercisesHandler.Update)
+						r.Delete("/exercises/{id}", exercisesHandler.Delete)
+						r.Get("/exercises/{id}/timeline", daysHandler.ExerciseTimeline)
+						r.Post("/exercises/{id}/sets", setsHandler.Create)
+						r.Patch("/exercises/{id}/sets/order", setsHandler.Reorder)
+						r.With(lockTx).Patch("/sets/{id}", setsHandler.Update)
+						r.Delete("/sets/{id}", setsHandler.Delete)
+						r.Post("/exercises/{id}/rests", setsHandler.CreateRest)
+						r.Patch("/rests/{id}", setsHandler.UpdateRest)
+						r.Delete("/rests/{id}", setsHandler.DeleteRest)
+						r.Get("/stats/muscle-split", analyticsHandler.MuscleSplit)
+						r.Get("/stats/session-duration", analyticsHandler.SessionDurations)
+						r.Get("/stats/load", analyticsHandler.TrainingLoad)
+						r.Get("/stats/cardio", analyticsHandler.Cardio)
+						r.Get("/stats/sides", analyticsHandler.SideBalance)
+						r.Get("/stats/recovery", analyticsHandler.Recovery)
+					})
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.DelegatedFeedback(coachingStore))
+						r.Get("/days/{dayId}/comments", commentsHandler.List)
+						r.Post("/days/{dayId}/comments", commentsHandler.Create)
+						r.Delete("/comments/{id}", commentsHandler.Delete)
+					})
+				})
 			})
 		})
 	})
@@ -129,6 +571,56 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go purgeDeletedAccounts(purgeCtx, usersStore, auditStore, saveStore, journalStore, cfg.GuestTTL)
+
+	if linkFetcher != nil {
+		linksCtx, stopLinks := context.WithCancel(context.Background())
+		defer stopLinks()
+		go linkFetcher.Run(linksCtx)
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go jobQueue.Run(jobsCtx)
+	go reminderScheduler.Run(jobsCtx)
+	go recapScheduler.Run(jobsCtx)
+	if linkChecker != nil {
+		go linkChecker.Run(jobsCtx)
+	}
+
+	var grpcServer *grpc.Server
+	if cfg.GRPCPort > 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			log.Fatalf("grpc listen: %v", err)
+		}
+		grpcServer = (&grpcapi.Server{
+			Catalog: catalogStore,
+			Days:    daysStore,
+			Save:    saveStore,
+			SaveLimits: store.SaveLimits{
+				MaxOps:       cfg.SaveMaxOps,
+				MaxStringLen: cfg.SaveMaxStringLen,
+			},
+			Sets:         setsStore,
+			Hub:          hub,
+			Achievements: achievementsEngine,
+			JWTSecret:    cfg.JWTSecret,
+			APIKeys:      apiKeysStore,
+			Sessions:     sessionsStore,
+
+			Maintenance: maintenance,
+		}).NewGRPCServer()
+		go func() {
+			log.Printf("grpc listening on :%d", cfg.GRPCPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("grpc server error: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		log.Printf("listening on :%d", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -143,4 +635,51 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(ctx)
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+}
+
+// purgeDeletedAccounts hard-deletes soft-deleted accounts whose grace period
+// has expired, and unclaimed guests idle for guestTTL, checking hourly. Sync
+// tombstones and change journal entries older than 30 days go too.
+func purgeDeletedAccounts(ctx context.Context, users *store.Users, audit *store.Audit, save *store.Save, journal *store.Journal, guestTTL time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		ids, err := users.PurgeDue(ctx, time.Now().UTC())
+		if err != nil {
+			log.Printf("account purge error: %v", err)
+		}
+		if guestTTL > 0 {
+			guests, err := users.PurgeIdleGuests(ctx, time.Now().UTC().Add(-guestTTL))
+			if err != nil {
+				log.Printf("guest purge error: %v", err)
+			}
+			ids = append(ids, guests...)
+		}
+		for _, id := range ids {
+			if err := audit.Record(ctx, store.AuditRecordParams{
+				Action:     store.AuditAccountPurge,
+				EntityType: "user",
+				EntityID:   store.AnonymizedUserID(id),
+			}); err != nil {
+				log.Printf("audit record error: %v", err)
+			}
+		}
+		if len(ids) > 0 {
+			log.Printf("purged %d deleted and idle guest accounts", len(ids))
+		}
+		if _, err := save.PruneTombstones(ctx, time.Now().UTC().AddDate(0, 0, -30)); err != nil {
+			log.Printf("tombstone prune error: %v", err)
+		}
+		if _, err := journal.Prune(ctx, time.Now().UTC().AddDate(0, 0, -30)); err != nil {
+			log.Printf("journal prune error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,235 @@
+package achievements
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"exercise-tracker/internal/jobs"
+	"exercise-tracker/internal/linkmeta"
+	"exercise-tracker/internal/notify"
+	"exercise-tracker/internal/realtime"
+	"exercise-tracker/internal/store"
+)
+
+// Job kinds.
+const (
+	JobEvaluate = "achievements.evaluate"
+	JobPush     = "achievements.push"
+	JobWebhook  = "achievements.webhook"
+)
+
+const (
+	// evaluateEvery batches a burst of set writes into one evaluation per
+	// user.
+	evaluateEvery  = time.Minute
+	webhookTimeout = 10 * time.Second
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook's secret.
+const (
+	HeaderTimestamp = "X-FitLog-Timestamp"
+	HeaderSignature = "X-FitLog-Signature"
+)
+
+// Sign returns the signature header value for a webhook body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext:           (&net.Dialer{Timeout: webhookTimeout, Control: linkmeta.PublicOnly}).DialContext,
+		TLSHandshakeTimeout:   webhookTimeout,
+		ResponseHeaderTimeout: webhookTimeout,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+type evaluatePayload struct {
+	UserID string `json:"userId"`
+}
+
+type unlockPayload struct {
+	UserID      string    `json:"userId"`
+	Achievement string    `json:"achievement"`
+	Value       float64   `json:"value"`
+	UnlockedAt  time.Time `json:"unlockedAt"`
+}
+
+// Engine evaluates rules in jobs and sends the unlock notifications. Push
+// and Hub may be nil when that channel isn't available.
+type Engine struct {
+	Rules        []Rule
+	Achievements *store.Achievements
+	// Reminders holds the web push subscriptions.
+	Reminders *store.Reminders
+	Queue     *jobs.Queue
+	Push      *notify.WebPush
+	Hub       *realtime.Hub
+}
+
+// Register adds the job handlers to the queue. Call before the queue runs.
+func (e *Engine) Register() {
+	e.Queue.Handle(JobEvaluate, e.evaluate)
+	e.Queue.Handle(JobPush, e.push)
+	e.Queue.Handle(JobWebhook, e.webhook)
+}
+
+// Enqueue schedules an evaluation of the user's achievements after their
+// sets or days changed. Calls within the same minute share one job. Errors
+// are logged; a nil Engine ignores the call.
+func (e *Engine) Enqueue(ctx context.Context, userID string) {
+	if e == nil {
+		return
+	}
+	at := time.Now().UTC().Truncate(evaluateEvery).Add(evaluateEvery)
+	_, err := e.Queue.Enqueue(ctx, JobEvaluate, evaluatePayload{UserID: userID}, jobs.EnqueueOptions{
+		RunAt:       at,
+		DedupeKey:   fmt.Sprintf("achievements:%s:%d", userID, at.Unix()),
+		MaxAttempts: 3,
+	})
+	if err != nil {
+		log.Printf("achievements enqueue error: %v", err)
+	}
+}
+
+func (e *Engine) rule(id string) Rule {
+	if i := index(e.Rules, id); i >= 0 {
+		return e.Rules[i]
+	}
+	return Rule{ID: id, Title: id}
+}
+
+func (e *Engine) evaluate(ctx context.Context, job jobs.Job) error {
+	var p evaluatePayload
+	if err := job.Decode(&p); err != nil {
+		return fmt.Errorf("achievements payload: %w", err)
+	}
+	progress, err := e.Achievements.Progress(ctx, p.UserID, Slugs(e.Rules))
+	if err != nil {
+		return err
+	}
+	for _, r := range e.Rules {
+		value := r.Value(progress)
+		if value < r.Threshold {
+			continue
+		}
+		// Unlock is idempotent, so a retry after a partial run only
+		// notifies for what it newly unlocks.
+		created, err := e.Achievements.Unlock(ctx, p.UserID, r.ID, value)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+		u := unlockPayload{UserID: p.UserID, Achievement: r.ID, Value: value, UnlockedAt: time.Now().UTC()}
+		if e.Hub != nil {
+			e.Hub.Publish(p.UserID, realtime.Event{Type: realtime.EventAchievement, Data: e.message(u)})
+		}
+		for _, kind := range []string{JobPush, JobWebhook} {
+			if _, err := e.Queue.Enqueue(ctx, kind, u, jobs.EnqueueOptions{
+				DedupeKey:   fmt.Sprintf("%s:%s:%s", kind, p.UserID, r.ID),
+				MaxAttempts: 5,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// message is the unlock as sent to clients and webhooks.
+func (e *Engine) message(u unlockPayload) map[string]any {
+	r := e.rule(u.Achievement)
+	return map[string]any{
+		"id":          r.ID,
+		"title":       r.Title,
+		"description": r.Description,
+		"value":       u.Value,
+		"unlockedAt":  u.UnlockedAt,
+	}
+}
+
+func (e *Engine) push(ctx context.Context, job jobs.Job) error {
+	var u unlockPayload
+	if err := job.Decode(&u); err != nil {
+		return fmt.Errorf("achievements payload: %w", err)
+	}
+	if e.Push == nil {
+		return nil
+	}
+	subs, err := e.Reminders.PushSubscriptions(ctx, u.UserID)
+	if err != nil {
+		return err
+	}
+	r := e.rule(u.Achievement)
+	payload, err := json.Marshal(map[string]string{"title": "Achievement unlocked: " + r.Title, "body": r.Description, "tag": "achievement-" + r.ID})
+	if err != nil {
+		return err
+	}
+	// A retry goes to every subscription again; a duplicate beats a missed
+	// notification.
+	var errs []error
+	for _, sub := range subs {
+		err := e.Push.Send(ctx, notify.PushSubscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, payload, 24*time.Hour)
+		if errors.Is(err, notify.ErrSubscriptionGone) {
+			if err := e.Reminders.DropPushEndpoint(ctx, sub.Endpoint); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, notify.ErrNotConfigured) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Engine) webhook(ctx context.Context, job jobs.Job) error {
+	var u unlockPayload
+	if err := job.Decode(&u); err != nil {
+		return fmt.Errorf("achievements payload: %w", err)
+	}
+	hook, err := e.Achievements.Webhook(ctx, u.UserID)
+	if err != nil || hook == nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{"type": "achievement.unlocked", "achievement": e.message(u)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, ts, body))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package achievements evaluates achievement rules against a user's stat
+// rollups after they log sets, and notifies them of unlocks by web push, the
+// realtime hub and an optional webhook.
+package achievements
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"exercise-tracker/internal/store"
+)
+
+// Metrics a rule can test, all counted from working sets.
+const (
+	MetricWorkouts = "workouts" // days trained
+	MetricStreak   = "streak"   // longest run of consecutive training days
+	MetricVolume   = "volume"   // lifetime tonnage, kg
+	MetricTopSet   = "topSet"   // heaviest set of one catalog exercise, kg
+)
+
+// Rule unlocks an achievement once its metric reaches Threshold.
+type Rule struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Metric      string `json:"metric"`
+	// Catalog is the exercise slug a topSet rule is about.
+	Catalog   string  `json:"catalog,omitempty"`
+	Threshold float64 `json:"threshold"`
+	// Disabled, in a rules file, removes the default rule with this ID.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Default is the built-in rule set.
+var Default = []Rule{
+	{ID: "first-workout", Title: "First workout", Description: "Log your first working set.", Metric: MetricWorkouts, Threshold: 1},
+	{ID: "workouts-100", Title: "Centurion", Description: "Train on 100 days.", Metric: MetricWorkouts, Threshold: 100},
+	{ID: "streak-7", Title: "Full week", Description: "Train 7 days in a row.", Metric: MetricStreak, Threshold: 7},
+	{ID: "streak-30", Title: "30-day streak", Description: "Train 30 days in a row.", Metric: MetricStreak, Threshold: 30},
+	{ID: "volume-100k", Title: "100 tonnes", Description: "Lift 100,000 kg in total.", Metric: MetricVolume, Threshold: 100_000},
+	{ID: "volume-1m", Title: "Million-kilo club", Description: "Lift 1,000,000 kg in total.", Metric: MetricVolume, Threshold: 1_000_000},
+	{ID: "squat-100", Title: "100 kg squat", Description: "Squat 100 kg for a working set.", Metric: MetricTopSet, Catalog: "barbell-back-squat", Threshold: 100},
+	{ID: "bench-100", Title: "100 kg bench", Description: "Bench press 100 kg for a working set.", Metric: MetricTopSet, Catalog: "barbell-bench-press", Threshold: 100},
+	{ID: "deadlift-140", Title: "140 kg deadlift", Description: "Deadlift 140 kg for a working set.", Metric: MetricTopSet, Catalog: "barbell-deadlift", Threshold: 140},
+}
+
+// Load reads a JSON array of rules from path and layers it over Default: a
+// rule replaces the default with the same ID, or removes it when disabled,
+// and other rules are added. An empty path returns Default.
+func Load(path string) ([]Rule, error) {
+	rules := append([]Rule(nil), Default...)
+	if path == "" {
+		return rules, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file []Rule
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, fr := range file {
+		if fr.ID == "" {
+			return nil, fmt.Errorf("%s: rule without id", path)
+		}
+		if !fr.Disabled {
+			if err := fr.validate(); err != nil {
+				return nil, fmt.Errorf("%s: rule %q: %w", path, fr.ID, err)
+			}
+		}
+		i := index(rules, fr.ID)
+		switch {
+		case fr.Disabled && i >= 0:
+			rules = append(rules[:i], rules[i+1:]...)
+		case fr.Disabled:
+		case i >= 0:
+			rules[i] = fr
+		default:
+			rules = append(rules, fr)
+		}
+	}
+	return rules, nil
+}
+
+func (r Rule) validate() error {
+	switch r.Metric {
+	case MetricWorkouts, MetricStreak, MetricVolume:
+	case MetricTopSet:
+		if r.Catalog == "" {
+			return fmt.Errorf("topSet needs a catalog slug")
+		}
+	default:
+		return fmt.Errorf("unknown metric %q", r.Metric)
+	}
+	if r.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if r.Threshold <= 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	return nil
+}
+
+func index(rules []Rule, id string) int {
+	for i, r := range rules {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value is the user's current value for the rule's metric.
+func (r Rule) Value(p store.AchievementProgress) float64 {
+	switch r.Metric {
+	case MetricWorkouts:
+		return float64(p.Workouts)
+	case MetricStreak:
+		return float64(p.LongestStreak)
+	case MetricVolume:
+		return p.LifetimeVolumeKg
+	case MetricTopSet:
+		return p.TopSetKg[r.Catalog]
+	}
+	return 0
+}
+
+// Slugs returns the catalog slugs the topSet rules need progress for.
+func Slugs(rules []Rule) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if r.Metric == MetricTopSet && !seen[r.Catalog] {
+			seen[r.Catalog] = true
+			out = append(out, r.Catalog)
+		}
+	}
+	return out
+}
+
+// Status is a rule with the user's progress towards it.
+type Status struct {
+	Rule
+	Value      float64    `json:"value"`
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlockedAt"`
+}
+
+// Statuses pairs every rule with the user's progress. An unlocked
+// achievement reports the value it was unlocked with if the current one has
+// since dropped (e.g. sets were deleted).
+func Statuses(rules []Rule, p store.AchievementProgress, unlocked []store.UnlockedAchievement) []Status {
+	byID := map[string]store.UnlockedAchievement{}
+	for _, u := range unlocked {
+		byID[u.ID] = u
+	}
+	out := make([]Status, 0, len(rules))
+	for _, r := range rules {
+		s := Status{Rule: r, Value: r.Value(p)}
+		if u, ok := byID[r.ID]; ok {
+			s.Unlocked = true
+			s.UnlockedAt = &u.UnlockedAt
+			if s.Value < u.Value {
+				s.Value = u.Value
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package achievements_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/achievements"
+	"exercise-tracker/internal/store"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`[
+		{"id": "squat-100", "title": "Triple-digit squat", "metric": "topSet", "catalog": "barbell-back-squat", "threshold": 100},
+		{"id": "workouts-100", "disabled": true},
+		{"id": "ohp-60", "title": "60 kg press", "metric": "topSet", "catalog": "barbell-overhead-press", "threshold": 60}
+	]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := achievements.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != len(achievements.Default) {
+		t.Fatalf("got %d rules", len(rules))
+	}
+	byID := map[string]achievements.Rule{}
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+	if byID["squat-100"].Title != "Triple-digit squat" {
+		t.Fatalf("squat-100 not replaced: %+v", byID["squat-100"])
+	}
+	if _, ok := byID["workouts-100"]; ok {
+		t.Fatal("workouts-100 not disabled")
+	}
+	if _, ok := byID["ohp-60"]; !ok {
+		t.Fatal("ohp-60 not added")
+	}
+
+	for _, bad := range []string{
+		`[{"id": "x", "title": "X", "metric": "reps", "threshold": 1}]`,
+		`[{"id": "x", "title": "X", "metric": "topSet", "threshold": 1}]`,
+		`[{"id": "x", "title": "X", "metric": "volume", "threshold": 0}]`,
+		`[{"title": "X", "metric": "volume", "threshold": 1}]`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := achievements.Load(path); err == nil {
+			t.Fatalf("Load(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rules := []achievements.Rule{
+		{ID: "streak-30", Metric: achievements.MetricStreak, Threshold: 30},
+		{ID: "squat-100", Metric: achievements.MetricTopSet, Catalog: "barbell-back-squat", Threshold: 100},
+		{ID: "volume-1m", Metric: achievements.MetricVolume, Threshold: 1_000_000},
+	}
+	p := store.AchievementProgress{LongestStreak: 12, LifetimeVolumeKg: 250_000, TopSetKg: map[string]float64{"barbell-back-squat": 95}}
+	got := achievements.Statuses(rules, p, []store.UnlockedAchievement{{ID: "squat-100", Value: 102.5, UnlockedAt: at}})
+	if got[0].Value != 12 || got[0].Unlocked {
+		t.Fatalf("streak = %+v", got[0])
+	}
+	// The squat was unlocked before the set behind it was edited down.
+	if got[1].Value != 102.5 || !got[1].Unlocked || !got[1].UnlockedAt.Equal(at) {
+		t.Fatalf("squat = %+v", got[1])
+	}
+	if got[2].Value != 250_000 || got[2].Unlocked {
+		t.Fatalf("volume = %+v", got[2])
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac whsec_test
+	got := achievements.Sign("whsec_test", 1700000000, []byte(`{"a":1}`))
+	if got != "sha256=38877139021993b830af32feea6e18a8da83eb2f6e49ee50bd9e4cf4ca4d3789" {
+		t.Fatalf("Sign = %s", got)
+	}
+}
@@ -0,0 +1,180 @@
+// Package assist writes natural-language session summaries of workout days.
+// Rules is the built-in summarizer; LLM delegates to an external chat
+// completions API and falls back to Rules when that fails.
+package assist
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"exercise-tracker/internal/models"
+)
+
+// Summarizer turns a day with its exercises and sets into a short summary.
+type Summarizer interface {
+	Summarize(ctx context.Context, day *models.DayWithDetails) (Summary, error)
+}
+
+type Summary struct {
+	Text string `json:"summary"`
+	// Provider names what wrote the summary: "rules" or "llm".
+	Provider string `json:"provider"`
+}
+
+// Rules summarizes from the logged numbers alone: totals, each exercise's
+// top set or cardio totals, and the day's notes.
+type Rules struct{}
+
+func (Rules) Summarize(_ context.Context, day *models.DayWithDetails) (Summary, error) {
+	return Summary{Text: rulesText(day), Provider: "rules"}, nil
+}
+
+func rulesText(day *models.DayWithDetails) string {
+	var b strings.Builder
+	switch {
+	case day.IsRestDay:
+		b.WriteString("Rest day.")
+	case workingSets(day) == 0:
+		b.WriteString("No working sets logged.")
+	default:
+		var exercises, sets int
+		var volume float64
+		for _, e := range day.Exercises {
+			n := 0
+			for _, s := range e.Sets {
+				if !s.IsWarmup {
+					n++
+					volume += s.VolumeKg
+				}
+			}
+			if n > 0 {
+				exercises++
+				sets += n
+			}
+		}
+		fmt.Fprintf(&b, "Trained %s for %s", plural(exercises, "exercise"), plural(sets, "working set"))
+		if volume > 0 {
+			fmt.Fprintf(&b, " and %s kg of volume", thousands(volume))
+		}
+		if day.DurationSeconds != nil && *day.DurationSeconds > 0 {
+			fmt.Fprintf(&b, " in %s", minutes(*day.DurationSeconds))
+		}
+		b.WriteString(".")
+		for _, e := range day.Exercises {
+			if line := exerciseLine(e); line != "" {
+				b.WriteString(" ")
+				b.WriteString(line)
+			}
+		}
+	}
+	if day.Notes != nil && strings.TrimSpace(*day.Notes) != "" {
+		fmt.Fprintf(&b, " Notes: %s", strings.TrimSpace(*day.Notes))
+	}
+	return b.String()
+}
+
+func workingSets(day *models.DayWithDetails) int {
+	n := 0
+	for _, e := range day.Exercises {
+		for _, s := range e.Sets {
+			if !s.IsWarmup {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// exerciseLine describes one exercise's working sets: the heaviest strength
+// set, or the time and distance of the rest. Empty without working sets.
+func exerciseLine(e models.Exercise) string {
+	var strength int
+	var top *models.Set
+	var seconds int
+	var meters float64
+	var other int
+	for i := range e.Sets {
+		s := &e.Sets[i]
+		if s.IsWarmup {
+			continue
+		}
+		if s.SetType == models.SetTypeStrength {
+			strength++
+			if top == nil || s.WeightKg > top.WeightKg || (s.WeightKg == top.WeightKg && s.Reps > top.Reps) {
+				top = s
+			}
+			continue
+		}
+		other++
+		if s.DurationSeconds != nil {
+			seconds += *s.DurationSeconds
+		}
+		if s.DistanceM != nil {
+			meters += *s.DistanceM
+		}
+	}
+	var parts []string
+	if strength > 0 {
+		p := plural(strength, "set")
+		if top.WeightKg > 0 {
+			p += fmt.Sprintf(", top %s kg × %d", kg(top.WeightKg), top.Reps)
+		} else {
+			p += fmt.Sprintf(", best %d reps", top.Reps)
+		}
+		if rpe := top.EffectiveRPE(); rpe != nil {
+			p += " @ RPE " + kg(*rpe)
+		}
+		parts = append(parts, p)
+	}
+	if other > 0 {
+		var p []string
+		if seconds > 0 {
+			p = append(p, minutes(seconds))
+		}
+		if meters > 0 {
+			p = append(p, kg(meters/1000)+" km")
+		}
+		if len(p) == 0 {
+			p = append(p, plural(other, "set"))
+		}
+		parts = append(parts, strings.Join(p, ", "))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return e.Name + ": " + strings.Join(parts, "; ") + "."
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// kg formats a number with at most two decimals and no trailing zeros.
+func kg(v float64) string {
+	return strconv.FormatFloat(float64(int64(v*100+0.5))/100, 'f', -1, 64)
+}
+
+// thousands formats a rounded number with comma separators.
+func thousands(v float64) string {
+	s := strconv.FormatInt(int64(v+0.5), 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func minutes(seconds int) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%d s", seconds)
+	}
+	m := (seconds + 30) / 60
+	if m < 60 {
+		return fmt.Sprintf("%d min", m)
+	}
+	return fmt.Sprintf("%d h %d min", m/60, m%60)
+}
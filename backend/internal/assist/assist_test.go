@@ -0,0 +1,84 @@
+package assist_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"exercise-tracker/internal/assist"
+	"exercise-tracker/internal/models"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func testDay() *models.DayWithDetails {
+	return &models.DayWithDetails{
+		WorkoutDay: models.WorkoutDay{Notes: ptr(" Felt strong. "), DurationSeconds: ptr(3100)},
+		Exercises: []models.Exercise{
+			{Name: "Bench Press", Sets: []models.Set{
+				{SetType: models.SetTypeStrength, Reps: 10, WeightKg: 40, IsWarmup: true, VolumeKg: 400},
+				{SetType: models.SetTypeStrength, Reps: 5, WeightKg: 100, RPE: ptr(8.0), VolumeKg: 500},
+				{SetType: models.SetTypeStrength, Reps: 8, WeightKg: 90, VolumeKg: 720},
+			}},
+			{Name: "Running", Sets: []models.Set{
+				{SetType: models.SetTypeCardio, DurationSeconds: ptr(1800), DistanceM: ptr(5000.0)},
+			}},
+		},
+	}
+}
+
+func TestRules(t *testing.T) {
+	got, err := assist.Rules{}.Summarize(context.Background(), testDay())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Trained 2 exercises for 3 working sets and 1,220 kg of volume in 52 min. " +
+		"Bench Press: 2 sets, top 100 kg × 5 @ RPE 8. Running: 30 min, 5 km. Notes: Felt strong."
+	if got.Text != want || got.Provider != "rules" {
+		t.Fatalf("got %+v\nwant %q", got, want)
+	}
+
+	rest, _ := assist.Rules{}.Summarize(context.Background(), &models.DayWithDetails{WorkoutDay: models.WorkoutDay{IsRestDay: true}})
+	if rest.Text != "Rest day." {
+		t.Fatalf("rest day = %q", rest.Text)
+	}
+}
+
+func TestLLM(t *testing.T) {
+	var prompt string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Messages[len(req.Messages)-1].Content
+		w.WriteHeader(status)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Solid bench day. "}}]}`))
+	}))
+	defer srv.Close()
+	llm := &assist.LLM{URL: srv.URL, APIKey: "key", Model: "m", Client: srv.Client()}
+
+	got, err := llm.Summarize(context.Background(), testDay())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "Solid bench day." || got.Provider != "llm" {
+		t.Fatalf("got %+v", got)
+	}
+	if !strings.Contains(prompt, "- 100 kg × 5, RPE 8") || strings.Contains(prompt, "40 kg") {
+		t.Fatalf("prompt = %q", prompt)
+	}
+
+	status = http.StatusTooManyRequests
+	got, err = llm.Summarize(context.Background(), testDay())
+	if err != nil || got.Provider != "rules" {
+		t.Fatalf("fallback = %+v, %v", got, err)
+	}
+}
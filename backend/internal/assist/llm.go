@@ -0,0 +1,155 @@
+package assist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"exercise-tracker/internal/models"
+)
+
+const (
+	maxResponseBytes = 1 << 20
+	// maxFactsBytes keeps the prompt of a very long session bounded.
+	maxFactsBytes = 8000
+	systemPrompt  = "You write a short summary of a gym session for the athlete who did it: two to four friendly sentences, " +
+		"highlighting the main lifts, effort and anything from their notes. Use only the facts given, keep weights in kg, " +
+		"and don't give medical advice."
+)
+
+// LLM summarizes through an OpenAI-compatible chat completions endpoint.
+// When the call fails, the Rules summary is returned instead.
+type LLM struct {
+	URL    string // e.g. https://api.openai.com/v1/chat/completions
+	APIKey string
+	Model  string
+	Client *http.Client
+}
+
+func (l *LLM) Summarize(ctx context.Context, day *models.DayWithDetails) (Summary, error) {
+	text, err := l.complete(ctx, facts(day))
+	if err != nil {
+		log.Printf("assist llm error, using rules: %v", err)
+		return Rules{}.Summarize(ctx, day)
+	}
+	return Summary{Text: text, Provider: "llm"}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func (l *LLM) complete(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":       l.Model,
+		"temperature": 0.3,
+		"messages": []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.APIKey)
+	}
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var out struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 || strings.TrimSpace(out.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("empty completion")
+	}
+	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+}
+
+// facts lists the day for the prompt: every working set, exercise comments
+// and the day's notes.
+func facts(day *models.DayWithDetails) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Date: %s\n", day.WorkoutDate.Format("2006-01-02"))
+	if day.IsRestDay {
+		b.WriteString("Rest day.\n")
+	}
+	if day.DurationSeconds != nil && *day.DurationSeconds > 0 {
+		fmt.Fprintf(&b, "Duration: %s\n", minutes(*day.DurationSeconds))
+	}
+	for _, e := range day.Exercises {
+		fmt.Fprintf(&b, "Exercise: %s\n", e.Name)
+		for _, s := range e.Sets {
+			if s.IsWarmup {
+				continue
+			}
+			b.WriteString("- ")
+			b.WriteString(setFact(s))
+			b.WriteString("\n")
+		}
+		if e.Comment != nil && strings.TrimSpace(*e.Comment) != "" {
+			fmt.Fprintf(&b, "  Comment: %s\n", strings.TrimSpace(*e.Comment))
+		}
+	}
+	if day.Notes != nil && strings.TrimSpace(*day.Notes) != "" {
+		fmt.Fprintf(&b, "Notes: %s\n", strings.TrimSpace(*day.Notes))
+	}
+	s := b.String()
+	if len(s) > maxFactsBytes {
+		s = strings.ToValidUTF8(s[:maxFactsBytes], "") + "\n(truncated)"
+	}
+	return s
+}
+
+func setFact(s models.Set) string {
+	var parts []string
+	if s.SetType == models.SetTypeStrength || s.Reps > 0 {
+		parts = append(parts, fmt.Sprintf("%s kg × %d", kg(s.WeightKg), s.Reps))
+	}
+	if s.DurationSeconds != nil {
+		parts = append(parts, minutes(*s.DurationSeconds))
+	}
+	if s.DistanceM != nil {
+		parts = append(parts, kg(*s.DistanceM)+" m")
+	}
+	if s.RPE != nil {
+		parts = append(parts, "RPE "+kg(*s.RPE))
+	}
+	if s.RIR != nil {
+		parts = append(parts, fmt.Sprintf("%d RIR", *s.RIR))
+	}
+	if s.AssistedReps > 0 {
+		parts = append(parts, fmt.Sprintf("%d assisted", s.AssistedReps))
+	}
+	if s.PartialReps > 0 {
+		parts = append(parts, fmt.Sprintf("+%d partials", s.PartialReps))
+	}
+	if s.ToFailure {
+		parts = append(parts, "to failure")
+	}
+	if s.Side != nil {
+		parts = append(parts, *s.Side)
+	}
+	return strings.Join(parts, ", ")
+}
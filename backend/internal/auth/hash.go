@@ -9,6 +9,16 @@ import (
 	"golang.org/x/crypto/argon2"
 )
 
+// Current Argon2id parameters. Hashes created with weaker settings are
+// upgraded on the next successful login (see NeedsRehash).
+const (
+	hashMemory      uint32 = 64 * 1024
+	hashIterations  uint32 = 3
+	hashParallelism uint8  = 2
+	hashSaltLen            = 16
+	hashKeyLen             = 32
+)
+
 // HashPassword returns a string encoding parameters, salt, and hash.
 // Format: argon2id$v=19$m=65536,t=3,p=2$<salt_b64>$<hash_b64>
 func HashPassword(plain string) (string, error) {
@@ -16,11 +26,11 @@ func HashPassword(plain string) (string, error) {
 		return "", fmt.Errorf("empty password")
 	}
 	var (
-		memory      uint32 = 64 * 1024
-		iterations  uint32 = 3
-		parallelism uint8  = 2
-		saltLen            = 16
-		keyLen             = 32
+		memory      = hashMemory
+		iterations  = hashIterations
+		parallelism = hashParallelism
+		saltLen     = hashSaltLen
+		keyLen      = hashKeyLen
 	)
 	salt := make([]byte, saltLen)
 	if _, err := rand.Read(salt); err != nil {
@@ -95,6 +105,43 @@ func VerifyPassword(encoded, plain string) (bool, error) {
 	return false, fmt.Errorf("unrecognized hash format")
 }
 
+// NeedsRehash reports whether encoded should be replaced with a fresh
+// HashPassword result: it uses the PHC variant, or any of its cost, salt or
+// key sizes are below the current defaults. Unparseable hashes report false
+// so a successful verify never turns into a failed login.
+func NeedsRehash(encoded string) bool {
+	phc := strings.HasPrefix(encoded, "$argon2id$")
+	if !phc && !strings.HasPrefix(encoded, "argon2id$") {
+		return false
+	}
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 {
+		return false
+	}
+	first := 1
+	if phc {
+		first = 2
+	}
+	memory, iterations, parallelism, err := parsePHCParams(parts[first : len(parts)-2])
+	if err != nil {
+		return false
+	}
+	salt, err := decodeB64(parts[len(parts)-2])
+	if err != nil {
+		return false
+	}
+	key, err := decodeB64(parts[len(parts)-1])
+	if err != nil {
+		return false
+	}
+	return phc ||
+		memory < hashMemory ||
+		iterations < hashIterations ||
+		parallelism < uint32(hashParallelism) ||
+		len(salt) < hashSaltLen ||
+		len(key) < hashKeyLen
+}
+
 func decodeB64(s string) ([]byte, error) {
 	if b, err := base64.RawStdEncoding.DecodeString(s); err == nil {
 		return b, nil
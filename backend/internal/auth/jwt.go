@@ -8,14 +8,18 @@ import (
 
 type Claims struct {
 	UserID string `json:"uid"`
+	// SessionID is the sessions row the token was issued for. Tokens from
+	// before server-side sessions have none.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func CreateToken(secret, userID string, ttl time.Duration) (string, time.Time, error) {
+func CreateToken(secret, userID, sessionID string, ttl time.Duration) (string, time.Time, error) {
 	now := time.Now()
 	exp := now.Add(ttl)
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(exp),
 			IssuedAt:  jwt.NewNumericDate(now),
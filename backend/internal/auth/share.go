@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// shareAudience keeps share tokens and session tokens from being accepted in
+// place of each other.
+const shareAudience = "share"
+
+// ShareClaims grant read-only access to one workout day.
+type ShareClaims struct {
+	DayID   string `json:"did"`
+	OwnerID string `json:"oid"`
+	jwt.RegisteredClaims
+}
+
+func CreateShareToken(secret, ownerID, dayID string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(ttl)
+	claims := &ShareClaims{
+		DayID:   dayID,
+		OwnerID: ownerID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{shareAudience},
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := tok.SignedString([]byte(secret))
+	return signed, exp, err
+}
+
+func ParseShareToken(secret, tokenStr string) (*ShareClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &ShareClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithAudience(shareAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(*ShareClaims); ok && token.Valid && claims.DayID != "" && claims.OwnerID != "" {
+		return claims, nil
+	}
+	return nil, jwt.ErrTokenInvalidClaims
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleConfig controls login throttling. A zero MaxFailures disables the
+// per-account limit; a zero MaxFailuresPerIP disables the per-IP limit.
+type ThrottleConfig struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	Window           time.Duration
+	Lockout          time.Duration
+}
+
+// LoginThrottle counts failed logins per account and per client IP in memory
+// and locks a key out once it exceeds its limit within Window.
+type LoginThrottle struct {
+	cfg ThrottleConfig
+
+	mu      sync.Mutex
+	entries map[string]*throttleEntry
+}
+
+type throttleEntry struct {
+	failures    []time.Time
+	lockedUntil time.Time
+}
+
+// LockState describes the throttle state after a check or failure.
+type LockState struct {
+	Locked     bool
+	RetryAfter time.Duration
+	// Remaining is how many more failures the account may have before it is
+	// locked; -1 when account limits are disabled.
+	Remaining int
+}
+
+func NewLoginThrottle(cfg ThrottleConfig) *LoginThrottle {
+	if cfg.Window <= 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	if cfg.Lockout <= 0 {
+		cfg.Lockout = cfg.Window
+	}
+	return &LoginThrottle{cfg: cfg, entries: make(map[string]*throttleEntry)}
+}
+
+func accountKey(email string) string { return "acct:" + strings.ToLower(strings.TrimSpace(email)) }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+// Check reports whether the account or IP is currently locked out.
+func (t *LoginThrottle) Check(email, ip string, now time.Time) LockState {
+	if t == nil {
+		return LockState{Remaining: -1}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state(email, ip, now)
+}
+
+// Failure records a failed attempt and returns the resulting state.
+func (t *LoginThrottle) Failure(email, ip string, now time.Time) LockState {
+	if t == nil {
+		return LockState{Remaining: -1}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(accountKey(email), t.cfg.MaxFailures, now)
+	t.record(ipKey(ip), t.cfg.MaxFailuresPerIP, now)
+	t.prune(now)
+
+	return t.state(email, ip, now)
+}
+
+// Success clears the account's failure history. The IP history is kept so a
+// single valid account can't be used to reset an IP spraying other accounts.
+func (t *LoginThrottle) Success(email string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	delete(t.entries, accountKey(email))
+	t.mu.Unlock()
+}
+
+func (t *LoginThrottle) state(email, ip string, now time.Time) LockState {
+	st := LockState{Remaining: t.remainingLocked(accountKey(email), t.cfg.MaxFailures, now)}
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		if e := t.entries[key]; e != nil && now.Before(e.lockedUntil) {
+			st.Locked = true
+			if d := e.lockedUntil.Sub(now); d > st.RetryAfter {
+				st.RetryAfter = d
+			}
+		}
+	}
+	return st
+}
+
+func (t *LoginThrottle) record(key string, limit int, now time.Time) {
+	if limit <= 0 {
+		return
+	}
+	e := t.entries[key]
+	if e == nil {
+		e = &throttleEntry{}
+		t.entries[key] = e
+	}
+	e.failures = append(recent(e.failures, now.Add(-t.cfg.Window)), now)
+	if len(e.failures) >= limit {
+		e.lockedUntil = now.Add(t.cfg.Lockout)
+		e.failures = nil
+	}
+}
+
+func (t *LoginThrottle) remainingLocked(key string, limit int, now time.Time) int {
+	if limit <= 0 {
+		return -1
+	}
+	e := t.entries[key]
+	if e == nil {
+		return limit
+	}
+	if now.Before(e.lockedUntil) {
+		return 0
+	}
+	return limit - len(recent(e.failures, now.Add(-t.cfg.Window)))
+}
+
+// prune drops entries with no recent failures and no active lock so the map
+// doesn't grow without bound.
+func (t *LoginThrottle) prune(now time.Time) {
+	cutoff := now.Add(-t.cfg.Window)
+	for key, e := range t.entries {
+		if now.After(e.lockedUntil) && len(recent(e.failures, cutoff)) == 0 {
+			delete(t.entries, key)
+		}
+	}
+}
+
+func recent(ts []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(ts) && !ts[i].After(cutoff) {
+		i++
+	}
+	return ts[i:]
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B vectors for SHA-1, truncated to six digits.
+func TestTOTPCodeRFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for _, tc := range []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	} {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestVerifyTOTPRefusesReplay(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	code, _ := TOTPCode(secret, TOTPStep(now))
+	step, ok := VerifyTOTP(secret, code, now, 0)
+	if !ok || step != TOTPStep(now) {
+		t.Fatalf("VerifyTOTP = %d, %v", step, ok)
+	}
+	if _, ok := VerifyTOTP(secret, code, now, step); ok {
+		t.Error("code accepted twice")
+	}
+	if _, ok := VerifyTOTP(secret, code, now.Add(5*time.Minute), 0); ok {
+		t.Error("stale code accepted")
+	}
+}
+
+func TestRecoveryCodeHashNormalizes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes", len(codes))
+	}
+	if HashRecoveryCode(" "+codes[0][:5]+" "+codes[0][6:]) != hashes[0] {
+		t.Error("hash depends on formatting")
+	}
+}
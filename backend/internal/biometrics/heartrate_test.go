@@ -0,0 +1,59 @@
+package biometrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	start := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	var in []Sample
+	for i := 0; i < 3600; i++ {
+		in = append(in, Sample{At: start.Add(time.Duration(i) * time.Second), BPM: 100 + i%40})
+	}
+	data, err := Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 4096 {
+		t.Errorf("encoded an hour in %d bytes", len(data))
+	}
+	out, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatal("round trip changed the samples")
+	}
+}
+
+func TestValidateAndSummarize(t *testing.T) {
+	start := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	samples, err := Validate([]Sample{
+		{At: start.Add(10 * time.Second), BPM: 150},
+		{At: start, BPM: 100},
+		{At: start.Add(10*time.Second + 200*time.Millisecond), BPM: 151},
+		{At: start.Add(20 * time.Minute), BPM: 120}, // after a long pause
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 || samples[0].BPM != 100 {
+		t.Fatalf("validated = %+v", samples)
+	}
+	st := Summarize(samples, Profile{WeightKg: 80, Age: 30, Sex: "male"})
+	// 10 s at 100, 60 s (capped) at 150, 1 s at 120.
+	if st.MaxBPM != 150 || st.AvgBPM != 142.5 || !st.EndedAt.Equal(start.Add(20*time.Minute)) {
+		t.Fatalf("stats = %+v", st)
+	}
+	if st.CaloriesKcal < 15 || st.CaloriesKcal > 17 {
+		t.Fatalf("calories = %v", st.CaloriesKcal)
+	}
+
+	for _, bad := range [][]Sample{nil, {{At: start, BPM: 10}}, {{BPM: 100}}} {
+		if _, err := Validate(bad); err == nil {
+			t.Errorf("Validate(%+v) succeeded", bad)
+		}
+	}
+}
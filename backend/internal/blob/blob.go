@@ -0,0 +1,29 @@
+// Package blob stores binary objects (catalog images) outside the main
+// catalog rows. Backends are selected via config.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the object and its content type, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	// Delete removes the object; deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// CatalogImageKey is the object key for a catalog entry's image.
+func CatalogImageKey(catalogID string) string {
+	return fmt.Sprintf("catalog/%s/image", catalogID)
+}
+
+// CatalogThumbnailKey is the object key for a catalog entry's thumbnail.
+func CatalogThumbnailKey(catalogID string) string {
+	return fmt.Sprintf("catalog/%s/thumb", catalogID)
+}
@@ -0,0 +1,47 @@
+package blob
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres keeps blobs in the blobs table. It is the default backend and
+// needs no extra infrastructure.
+type Postgres struct {
+	db *sqlx.DB
+}
+
+func NewPostgres(db *sqlx.DB) *Postgres { return &Postgres{db: db} }
+
+func (p *Postgres) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := p.db.ExecContext(ctx, `
+		insert into blobs (key, data, content_type)
+		values ($1, $2, $3)
+		on conflict (key) do update
+		set data = excluded.data, content_type = excluded.content_type, updated_at = now()
+	`, key, data, contentType)
+	return err
+}
+
+func (p *Postgres) Get(ctx context.Context, key string) ([]byte, string, error) {
+	var (
+		data        []byte
+		contentType string
+	)
+	err := p.db.QueryRowxContext(ctx, `select data, content_type from blobs where key = $1`, key).Scan(&data, &contentType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
+
+func (p *Postgres) Delete(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, `delete from blobs where key = $1`, key)
+	return err
+}
@@ -0,0 +1,230 @@
+// Package catalognorm canonicalizes the facet values (type, body part,
+// equipment, level) of imported catalog entries, so that "barbell", "BB"
+// and "Barbell" end up as one facet instead of three.
+package catalognorm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Fields that can be normalized, named as in the JSON import body.
+const (
+	FieldType      = "type"
+	FieldBodyPart  = "bodyPart"
+	FieldEquipment = "equipment"
+	FieldLevel     = "level"
+)
+
+var fields = []string{FieldType, FieldBodyPart, FieldEquipment, FieldLevel}
+
+// Rules configure normalization per field. Keys are field names.
+type Rules struct {
+	// Synonyms maps a spelling (matched case-insensitively) to its canonical
+	// value.
+	Synonyms map[string]map[string]string `json:"synonyms"`
+	// Allowed, when set for a field, is its closed set of values: anything
+	// that doesn't resolve to one of them is rejected.
+	Allowed map[string][]string `json:"allowed"`
+}
+
+// Default covers the spellings seen in the public exercise datasets.
+var Default = Rules{
+	Synonyms: map[string]map[string]string{
+		FieldType: {
+			"strength training": "Strength",
+			"cardiovascular":    "Cardio",
+		},
+		FieldEquipment: {
+			"bb":          "Barbell",
+			"db":          "Dumbbell",
+			"dumbbells":   "Dumbbell",
+			"kb":          "Kettlebells",
+			"kettlebell":  "Kettlebells",
+			"bodyweight":  "Body Only",
+			"body weight": "Body Only",
+			"none":        "Body Only",
+			"cables":      "Cable",
+			"bands":       "Bands",
+			"band":        "Bands",
+		},
+		FieldLevel: {
+			"novice":    "Beginner",
+			"beginners": "Beginner",
+			"medium":    "Intermediate",
+			"advanced":  "Expert",
+		},
+	},
+	Allowed: map[string][]string{
+		FieldLevel: {"Beginner", "Intermediate", "Expert"},
+	},
+}
+
+// Load reads rules from a JSON file and layers them over Default: synonyms
+// are added (the file wins on conflicts) and an allowed list replaces the
+// default one for its field. An empty path returns Default.
+func Load(path string) (Rules, error) {
+	rules := Rules{Synonyms: map[string]map[string]string{}, Allowed: map[string][]string{}}
+	for field, m := range Default.Synonyms {
+		rules.Synonyms[field] = map[string]string{}
+		for k, v := range m {
+			rules.Synonyms[field][k] = v
+		}
+	}
+	for field, values := range Default.Allowed {
+		rules.Allowed[field] = values
+	}
+	if path == "" {
+		return rules, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, err
+	}
+	var file Rules
+	if err := json.Unmarshal(b, &file); err != nil {
+		return Rules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for field, m := range file.Synonyms {
+		if !isField(field) {
+			return Rules{}, fmt.Errorf("%s: unknown field %q", path, field)
+		}
+		if rules.Synonyms[field] == nil {
+			rules.Synonyms[field] = map[string]string{}
+		}
+		for k, v := range m {
+			rules.Synonyms[field][fold(k)] = strings.TrimSpace(v)
+		}
+	}
+	for field, values := range file.Allowed {
+		if !isField(field) {
+			return Rules{}, fmt.Errorf("%s: unknown field %q", path, field)
+		}
+		rules.Allowed[field] = values
+	}
+	return rules, nil
+}
+
+func isField(name string) bool {
+	for _, f := range fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fold is the case- and spacing-insensitive key values are matched on.
+func fold(v string) string {
+	return strings.ToLower(strings.Join(strings.Fields(v), " "))
+}
+
+// Change is a value that was rewritten. Row is the 1-based position of the
+// entry in the import.
+type Change struct {
+	Row   int    `json:"row"`
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Rejection is a value with no allowed equivalent.
+type Rejection struct {
+	Row   int    `json:"row"`
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// Report collects what a Normalizer did over one import.
+type Report struct {
+	Normalized []Change    `json:"normalized"`
+	Rejected   []Rejection `json:"rejected"`
+}
+
+// NewReport returns a Report whose lists encode as [] when empty.
+func NewReport() *Report {
+	return &Report{Normalized: []Change{}, Rejected: []Rejection{}}
+}
+
+// Normalizer applies Rules over one import. Values it accepts become known,
+// so later rows fold onto the first spelling seen.
+type Normalizer struct {
+	rules Rules
+	// known maps field -> folded value -> canonical spelling.
+	known map[string]map[string]string
+}
+
+// New returns a Normalizer for rules. known lists the values already in the
+// catalog per field; spellings that differ only in case fold onto them.
+func New(rules Rules, known map[string][]string) *Normalizer {
+	n := &Normalizer{rules: rules, known: map[string]map[string]string{}}
+	for _, field := range fields {
+		n.known[field] = map[string]string{}
+		for _, v := range rules.Allowed[field] {
+			n.known[field][fold(v)] = v
+		}
+		if len(rules.Allowed[field]) > 0 {
+			continue
+		}
+		for _, v := range known[field] {
+			if _, ok := n.known[field][fold(v)]; !ok {
+				n.known[field][fold(v)] = v
+			}
+		}
+	}
+	return n
+}
+
+// Apply normalizes *value in place for field, recording the outcome of row
+// in rep. It reports false when the value was rejected. Blank values are
+// left for the caller's required-field checks.
+func (n *Normalizer) Apply(rep *Report, row int, field string, value *string) bool {
+	original := strings.TrimSpace(*value)
+	if original == "" {
+		return true
+	}
+	key := fold(original)
+	synonym, isSynonym := n.rules.Synonyms[field][key]
+	if isSynonym {
+		key = fold(synonym)
+	}
+	resolved, ok := n.known[field][key]
+	if !ok {
+		if len(n.rules.Allowed[field]) > 0 {
+			rep.Rejected = append(rep.Rejected, Rejection{Row: row, Field: field, Value: original})
+			return false
+		}
+		resolved = strings.Join(strings.Fields(original), " ")
+		if isSynonym {
+			resolved = synonym
+		}
+		n.known[field][key] = resolved
+	}
+	if resolved != original {
+		rep.Normalized = append(rep.Normalized, Change{Row: row, Field: field, From: original, To: resolved})
+	}
+	*value = resolved
+	return true
+}
+
+// Entry applies Apply to the four facet values of one entry and reports
+// false when any of them was rejected.
+func (n *Normalizer) Entry(rep *Report, row int, typ, bodyPart, equipment, level *string) bool {
+	ok := true
+	for _, f := range []struct {
+		field string
+		value *string
+	}{
+		{FieldType, typ},
+		{FieldBodyPart, bodyPart},
+		{FieldEquipment, equipment},
+		{FieldLevel, level},
+	} {
+		if !n.Apply(rep, row, f.field, f.value) {
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,80 @@
+package catalognorm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizerEntry(t *testing.T) {
+	n := New(Default, map[string][]string{
+		FieldEquipment: {"Barbell", "Body Only"},
+		FieldBodyPart:  {"Chest"},
+	})
+	rep := NewReport()
+
+	typ, body, equip, level := "strength", "chest", "BB", "advanced"
+	if !n.Entry(rep, 1, &typ, &body, &equip, &level) {
+		t.Fatalf("entry rejected: %+v", rep.Rejected)
+	}
+	if body != "Chest" || equip != "Barbell" || level != "Expert" || typ != "strength" {
+		t.Fatalf("got %q %q %q %q", typ, body, equip, level)
+	}
+	// The first spelling of a new value wins for later rows.
+	typ2, body2, equip2, level2 := "STRENGTH", "Chest", "  body   weight ", "Beginner"
+	if !n.Entry(rep, 2, &typ2, &body2, &equip2, &level2) {
+		t.Fatalf("entry rejected: %+v", rep.Rejected)
+	}
+	if typ2 != "strength" || equip2 != "Body Only" {
+		t.Fatalf("got %q %q", typ2, equip2)
+	}
+	want := []Change{
+		{1, FieldBodyPart, "chest", "Chest"},
+		{1, FieldEquipment, "BB", "Barbell"},
+		{1, FieldLevel, "advanced", "Expert"},
+		{2, FieldType, "STRENGTH", "strength"},
+		{2, FieldEquipment, "body   weight", "Body Only"},
+	}
+	if !reflect.DeepEqual(rep.Normalized, want) {
+		t.Fatalf("normalized = %+v", rep.Normalized)
+	}
+
+	typ3, body3, equip3, level3 := "Strength", "Chest", "Barbell", "Olympian"
+	if n.Entry(rep, 3, &typ3, &body3, &equip3, &level3) {
+		t.Fatal("unknown level accepted")
+	}
+	if !reflect.DeepEqual(rep.Rejected, []Rejection{{3, FieldLevel, "Olympian"}}) {
+		t.Fatalf("rejected = %+v", rep.Rejected)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`{
+		"synonyms": {"equipment": {"Smith": "Machine"}},
+		"allowed": {"level": ["Easy", "Hard"]}
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rules.Synonyms[FieldEquipment]["smith"] != "Machine" || rules.Synonyms[FieldEquipment]["bb"] != "Barbell" {
+		t.Fatalf("synonyms = %v", rules.Synonyms[FieldEquipment])
+	}
+	if !reflect.DeepEqual(rules.Allowed[FieldLevel], []string{"Easy", "Hard"}) {
+		t.Fatalf("allowed = %v", rules.Allowed[FieldLevel])
+	}
+	if Default.Synonyms[FieldEquipment]["smith"] != "" {
+		t.Fatal("Load modified Default")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"synonyms": {"colour": {}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("unknown field accepted")
+	}
+}
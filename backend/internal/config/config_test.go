@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetsecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_SECRET", "")
+	t.Setenv("TEST_SECRET_FILE", path)
+	if got := getsecret("TEST_SECRET", "def"); got != "from-file" {
+		t.Errorf("getsecret = %q, want from-file", got)
+	}
+
+	t.Setenv("TEST_SECRET_FILE", "")
+	t.Setenv("TEST_SECRET", "from-env")
+	if got := getsecret("TEST_SECRET", "def"); got != "from-env" {
+		t.Errorf("getsecret = %q, want from-env", got)
+	}
+}
+
+func TestCheckSecrets(t *testing.T) {
+	const url = "postgres://app:s3cret@db:5432/app"
+	if err := checkSecrets(url, "a-long-random-secret"); err != nil {
+		t.Errorf("checkSecrets(custom) = %v", err)
+	}
+	for _, tc := range []struct{ url, secret string }{
+		{defaultDatabaseURL, "a-long-random-secret"},
+		{url, defaultJWTSecret},
+		{url, "change-me"},
+		{url, "CHANGE-ME"},
+	} {
+		if err := checkSecrets(tc.url, tc.secret); err == nil {
+			t.Errorf("checkSecrets(%q, %q) = nil", tc.url, tc.secret)
+		}
+	}
+}
@@ -4,32 +4,128 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 )
 
+// DB is the primary database. Connections come from a pgxpool; the embedded
+// *sqlx.DB is the database/sql view of that pool every store goes through.
 type DB struct {
 	*sqlx.DB
+	pgx *pgxpool.Pool
+	// read is the optional read replica; nil means reads go to the primary.
+	read    *sqlx.DB
+	readPgx *pgxpool.Pool
+	poolCfg PoolConfig
+}
+
+// PoolConfig sizes the connection pool. pgxpool has no idle cap of its own,
+// so MaxIdleConns caps the idle connections database/sql holds on to, and
+// both close idle ones after ConnMaxIdleTime. StatementTimeout is set as the
+// session's statement_timeout so Postgres cancels runaway queries; 0 leaves
+// the server default.
+type PoolConfig struct {
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+}
+
+// DefaultPoolConfig is what Connect uses.
+var DefaultPoolConfig = PoolConfig{
+	MaxOpenConns:     25,
+	MaxIdleConns:     25,
+	ConnMaxLifetime:  60 * time.Minute,
+	ConnMaxIdleTime:  5 * time.Minute,
+	StatementTimeout: 30 * time.Second,
 }
 
 func Connect(ctx context.Context, databaseURL string) (*DB, error) {
-	d, err := sqlx.Open("pgx", databaseURL)
+	return ConnectPool(ctx, databaseURL, DefaultPoolConfig)
+}
+
+func ConnectPool(ctx context.Context, databaseURL string, pool PoolConfig) (*DB, error) {
+	p, d, err := open(ctx, databaseURL, pool)
 	if err != nil {
-		return nil, fmt.Errorf("open db: %w", err)
+		return nil, err
+	}
+	return &DB{DB: d, pgx: p, poolCfg: pool}, nil
+}
+
+// ConnectReader attaches a read replica that Reader hands out to read-heavy
+// stores. Writes and transactions keep using the primary.
+func (db *DB) ConnectReader(ctx context.Context, databaseURL string) error {
+	p, d, err := open(ctx, databaseURL, db.poolCfg)
+	if err != nil {
+		return fmt.Errorf("read replica: %w", err)
+	}
+	db.read, db.readPgx = d, p
+	return nil
+}
+
+// Writer returns the primary.
+func (db *DB) Writer() *sqlx.DB { return db.DB }
+
+// Reader returns the read replica, or the primary when none is configured.
+// Results can lag the primary by the replication delay.
+func (db *DB) Reader() *sqlx.DB {
+	if db.read != nil {
+		return db.read
+	}
+	return db.DB
+}
+
+// Close closes the primary and the read replica.
+func (db *DB) Close() error {
+	if db.read != nil {
+		_ = db.read.Close()
+		db.readPgx.Close()
+	}
+	err := db.DB.Close()
+	db.pgx.Close()
+	return err
+}
+
+func open(ctx context.Context, databaseURL string, pool PoolConfig) (*pgxpool.Pool, *sqlx.DB, error) {
+	cfg, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open db: %w", err)
+	}
+	if pool.StatementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(pool.StatementTimeout.Milliseconds(), 10)
+	}
+	if pool.MaxOpenConns > 0 {
+		cfg.MaxConns = int32(pool.MaxOpenConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		cfg.MaxConnLifetime = pool.ConnMaxLifetime
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		cfg.MaxConnIdleTime = pool.ConnMaxIdleTime
+	}
+	p, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open db: %w", err)
 	}
-	d.SetMaxOpenConns(25)
-	d.SetMaxIdleConns(25)
-	d.SetConnMaxIdleTime(5 * time.Minute)
-	d.SetConnMaxLifetime(60 * time.Minute)
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	if err := d.PingContext(ctx); err != nil {
-		_ = d.Close()
-		return nil, fmt.Errorf("ping db: %w", err)
+	if err := p.Ping(ctx); err != nil {
+		p.Close()
+		return nil, nil, fmt.Errorf("ping db: %w", err)
+	}
+	sqlDB := stdlib.OpenDBFromPool(p)
+	if pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 	}
-	return &DB{DB: d}, nil
+	return p, sqlx.NewDb(sqlDB, "pgx"), nil
 }
 
 // InTx runs fn inside a transaction with read committed isolation.
@@ -2,76 +2,404 @@ package db
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
+	"log"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 //go:embed migrations/*.sql
 var migrationFS embed.FS
 
-type migration struct {
-	Name string
-	SQL  string
+// baselineName is the combined schema the numbered migrations build on. It
+// always runs first and can't be rolled back.
+const baselineName = "schema.sql"
+
+const downSuffix = ".down.sql"
+
+// migrationLockKey is the advisory lock held while migrating, so replicas
+// booting at the same time apply migrations one after another.
+const migrationLockKey int64 = 0x6669746c6f67 // "fitlog"
+
+// withMigrationLock runs fn while holding the migration advisory lock on a
+// dedicated connection. The lock goes away with the connection if the
+// process dies.
+func (db *DB) withMigrationLock(ctx context.Context, fn func() error) error {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("migration lock: %w", err)
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.GetContext(ctx, &locked, `select pg_try_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("migration lock: %w", err)
+	}
+	if !locked {
+		log.Printf("waiting for another instance to finish migrating")
+		// Waiting can outlast statement_timeout; restore it before the
+		// connection goes back to the pool.
+		if _, err := conn.ExecContext(ctx, `set statement_timeout = 0`); err != nil {
+			return fmt.Errorf("migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), `reset statement_timeout`)
+		if _, err := conn.ExecContext(ctx, `select pg_advisory_lock($1)`, migrationLockKey); err != nil {
+			return fmt.Errorf("migration lock: %w", err)
+		}
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `select pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			log.Printf("migration unlock error: %v", err)
+		}
+	}()
+	return fn()
 }
 
-func (db *DB) Migrate(ctx context.Context) error {
+// Migration is one embedded up script and its optional down script
+// (NNN_name.down.sql next to NNN_name.sql).
+type Migration struct {
+	Name     string // file name of the up script
+	Version  string // numeric prefix, e.g. "017"; empty for the baseline
+	Up       string
+	Down     string
+	Checksum string // sha256 of Up
+}
+
+// Migrations returns the embedded migrations in the order they apply.
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	downs := map[string]string{}
+	var migs []Migration
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		b, err := migrationFS.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", e.Name(), err)
+		}
+		if strings.HasSuffix(e.Name(), downSuffix) {
+			downs[strings.TrimSuffix(e.Name(), downSuffix)+".sql"] = string(b)
+			continue
+		}
+		sum := sha256.Sum256(b)
+		m := Migration{Name: e.Name(), Up: string(b), Checksum: hex.EncodeToString(sum[:])}
+		if e.Name() != baselineName {
+			m.Version, _, _ = strings.Cut(e.Name(), "_")
+		}
+		migs = append(migs, m)
+	}
+	for i := range migs {
+		migs[i].Down = downs[migs[i].Name]
+		delete(downs, migs[i].Name)
+	}
+	for name := range downs {
+		return nil, fmt.Errorf("down migration for %s has no up script", name)
+	}
+	sort.Slice(migs, func(i, j int) bool {
+		if (migs[i].Name == baselineName) != (migs[j].Name == baselineName) {
+			return migs[i].Name == baselineName
+		}
+		return migs[i].Name < migs[j].Name
+	})
+	return migs, nil
+}
+
+// findMigration resolves a --to target given as a version ("017"), a file
+// name, or a file name without ".sql".
+func findMigration(migs []Migration, target string) (int, error) {
+	for i, m := range migs {
+		if target == m.Name || target+".sql" == m.Name || (m.Version != "" && target == m.Version) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown migration %q", target)
+}
+
+type appliedMigration struct {
+	Name      string    `db:"name"`
+	Checksum  *string   `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+func (db *DB) ensureMigrationsTable(ctx context.Context) error {
 	if _, err := db.ExecContext(ctx, `
 		create table if not exists schema_migrations (
 			id serial primary key,
 			name text not null unique,
 			applied_at timestamptz not null default now()
 		);
+		alter table schema_migrations add column if not exists checksum text null;
 	`); err != nil {
 		return fmt.Errorf("ensure schema_migrations: %w", err)
 	}
+	return nil
+}
 
-	entries, err := migrationFS.ReadDir("migrations")
-	if err != nil {
-		return fmt.Errorf("read migrations: %w", err)
+func (db *DB) appliedMigrations(ctx context.Context) (map[string]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := db.SelectContext(ctx, &rows, `select name, checksum, applied_at from schema_migrations`); err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
 	}
-	var migs []migration
-	for _, e := range entries {
-		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+	out := make(map[string]appliedMigration, len(rows))
+	for _, r := range rows {
+		out[r.Name] = r
+	}
+	return out, nil
+}
+
+// verifyChecksums fails when an applied migration's script changed since it
+// ran. Rows recorded before checksums existed adopt the current one.
+func (db *DB) verifyChecksums(ctx context.Context, migs []Migration, applied map[string]appliedMigration) error {
+	var modified []string
+	for _, m := range migs {
+		a, ok := applied[m.Name]
+		if !ok {
 			continue
 		}
-		b, err := migrationFS.ReadFile("migrations/" + e.Name())
-		if err != nil {
-			return fmt.Errorf("read migration %s: %w", e.Name(), err)
+		if a.Checksum == nil {
+			if _, err := db.ExecContext(ctx, `update schema_migrations set checksum = $2 where name = $1`, m.Name, m.Checksum); err != nil {
+				return fmt.Errorf("record checksum %s: %w", m.Name, err)
+			}
+			continue
+		}
+		if *a.Checksum != m.Checksum {
+			modified = append(modified, m.Name)
 		}
-		migs = append(migs, migration{Name: e.Name(), SQL: string(b)})
 	}
-	sort.Slice(migs, func(i, j int) bool { return migs[i].Name < migs[j].Name })
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migrations were modified: %s (restore them, or run migrate force after reconciling the schema)", strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// noStatementTimeout lifts the session statement_timeout for the rest of tx;
+// schema changes on big tables legitimately take longer than queries.
+func noStatementTimeout(ctx context.Context, tx *sqlx.Tx) error {
+	_, err := tx.ExecContext(ctx, `set local statement_timeout = 0`)
+	return err
+}
 
-	applied := map[string]bool{}
-	rows, err := db.QueryxContext(ctx, `select name from schema_migrations`)
+// Migrate applies every pending migration.
+func (db *DB) Migrate(ctx context.Context) error {
+	_, err := db.MigrateUp(ctx, "")
+	return err
+}
+
+// MigrateUp applies pending migrations up to and including to (all of them
+// when to is empty), each in its own transaction, and returns their names.
+func (db *DB) MigrateUp(ctx context.Context, to string) (done []string, err error) {
+	err = db.withMigrationLock(ctx, func() error {
+		done, err = db.migrateUp(ctx, to)
+		return err
+	})
+	return done, err
+}
+
+func (db *DB) migrateUp(ctx context.Context, to string) ([]string, error) {
+	migs, err := Migrations()
 	if err != nil {
-		return fmt.Errorf("read applied migrations: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return err
+	last := len(migs) - 1
+	if to != "" {
+		if last, err = findMigration(migs, to); err != nil {
+			return nil, err
 		}
-		applied[name] = true
 	}
-	for _, m := range migs {
-		if applied[m.Name] {
+	if err := db.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.verifyChecksums(ctx, migs, applied); err != nil {
+		return nil, err
+	}
+	var done []string
+	for _, m := range migs[:last+1] {
+		if _, ok := applied[m.Name]; ok {
 			continue
 		}
-		if strings.TrimSpace(m.SQL) == "" {
-			continue
+		err := db.InTx(ctx, func(tx *sqlx.Tx) error {
+			if err := noStatementTimeout(ctx, tx); err != nil {
+				return err
+			}
+			if strings.TrimSpace(m.Up) != "" {
+				if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, `insert into schema_migrations (name, checksum) values ($1, $2)`, m.Name, m.Checksum)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("apply migration %s: %w", m.Name, err)
 		}
-		if _, err := db.ExecContext(ctx, m.SQL); err != nil {
-			return fmt.Errorf("apply migration %s: %w", m.Name, err)
+		done = append(done, m.Name)
+	}
+	return done, nil
+}
+
+// MigrateDown rolls back applied migrations newer than to, newest first, and
+// returns their names. With an empty to only the latest one is rolled back.
+// It stops before touching anything when one of them has no down script.
+func (db *DB) MigrateDown(ctx context.Context, to string) (done []string, err error) {
+	err = db.withMigrationLock(ctx, func() error {
+		done, err = db.migrateDown(ctx, to)
+		return err
+	})
+	return done, err
+}
+
+func (db *DB) migrateDown(ctx context.Context, to string) ([]string, error) {
+	migs, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.verifyChecksums(ctx, migs, applied); err != nil {
+		return nil, err
+	}
+	var targets []Migration
+	if to == "" {
+		for i := len(migs) - 1; i >= 0; i-- {
+			if _, ok := applied[migs[i].Name]; ok {
+				targets = append(targets, migs[i])
+				break
+			}
 		}
-		if _, err := db.ExecContext(ctx, `insert into schema_migrations(name) values ($1)`, m.Name); err != nil {
-			return fmt.Errorf("record migration %s: %w", m.Name, err)
+	} else {
+		keep, err := findMigration(migs, to)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(migs) - 1; i > keep; i-- {
+			if _, ok := applied[migs[i].Name]; ok {
+				targets = append(targets, migs[i])
+			}
 		}
 	}
-	return nil
+	for _, m := range targets {
+		if strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %s has no down script", m.Name)
+		}
+	}
+	var done []string
+	for _, m := range targets {
+		err := db.InTx(ctx, func(tx *sqlx.Tx) error {
+			if err := noStatementTimeout(ctx, tx); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `delete from schema_migrations where name = $1`, m.Name)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("roll back migration %s: %w", m.Name, err)
+		}
+		done = append(done, m.Name)
+	}
+	return done, nil
+}
+
+// MigrationState describes one migration for status output. Rows recorded in
+// schema_migrations without an embedded script are reported as Missing.
+type MigrationState struct {
+	Name       string
+	Applied    bool
+	AppliedAt  *time.Time
+	Reversible bool
+	Modified   bool
+	Missing    bool
+}
+
+func (db *DB) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	migs, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationState, 0, len(migs))
+	for _, m := range migs {
+		st := MigrationState{Name: m.Name, Reversible: strings.TrimSpace(m.Down) != ""}
+		if a, ok := applied[m.Name]; ok {
+			at := a.AppliedAt
+			st.Applied, st.AppliedAt = true, &at
+			st.Modified = a.Checksum != nil && *a.Checksum != m.Checksum
+			delete(applied, m.Name)
+		}
+		out = append(out, st)
+	}
+	var missing []string
+	for name := range applied {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		at := applied[name].AppliedAt
+		out = append(out, MigrationState{Name: name, Applied: true, AppliedAt: &at, Missing: true})
+	}
+	return out, nil
 }
 
+// ForceMigration records every migration up to and including to as applied
+// with its current checksum, and forgets later ones, without running any
+// SQL. Use it after fixing the schema by hand.
+func (db *DB) ForceMigration(ctx context.Context, to string) error {
+	migs, err := Migrations()
+	if err != nil {
+		return err
+	}
+	last, err := findMigration(migs, to)
+	if err != nil {
+		return err
+	}
+	if err := db.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+	return db.withMigrationLock(ctx, func() error {
+		return db.forceMigration(ctx, migs, last)
+	})
+}
 
+func (db *DB) forceMigration(ctx context.Context, migs []Migration, last int) error {
+	return db.InTx(ctx, func(tx *sqlx.Tx) error {
+		for _, m := range migs[:last+1] {
+			if _, err := tx.ExecContext(ctx, `
+				insert into schema_migrations (name, checksum) values ($1, $2)
+				on conflict (name) do update set checksum = excluded.checksum`, m.Name, m.Checksum); err != nil {
+				return err
+			}
+		}
+		later := make([]string, 0, len(migs)-last-1)
+		for _, m := range migs[last+1:] {
+			later = append(later, m.Name)
+		}
+		_, err := tx.ExecContext(ctx, `delete from schema_migrations where name = any($1::text[])`, later)
+		return err
+	})
+}
@@ -0,0 +1,31 @@
+package db_test
+
+import (
+	"sort"
+	"testing"
+
+	"exercise-tracker/internal/db"
+)
+
+func TestMigrationsPaired(t *testing.T) {
+	migs, err := db.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migs) == 0 || migs[0].Name != "schema.sql" {
+		t.Fatalf("baseline must apply first, got %+v", migs[0].Name)
+	}
+	names := make([]string, 0, len(migs)-1)
+	for _, m := range migs[1:] {
+		names = append(names, m.Name)
+		if m.Version == "" {
+			t.Errorf("%s: no version prefix", m.Name)
+		}
+		if m.Down == "" {
+			t.Errorf("%s: missing down script", m.Name)
+		}
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("migrations out of order: %v", names)
+	}
+}
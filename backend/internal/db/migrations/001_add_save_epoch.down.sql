@@ -0,0 +1,4 @@
+-- 001_add_save_epoch.down.sql
+-- Reverts 001_add_save_epoch.sql
+
+alter table users drop column if exists save_epoch;
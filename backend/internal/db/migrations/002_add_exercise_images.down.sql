@@ -0,0 +1,6 @@
+-- 002_add_exercise_images.down.sql
+-- Reverts 002_add_exercise_images.sql; inline images are lost.
+
+alter table exercise_catalog
+  drop column if exists image_data,
+  drop column if exists image_mime_type;
@@ -0,0 +1,4 @@
+-- 003_add_audit_log.down.sql
+-- Reverts 003_add_audit_log.sql
+
+drop table if exists audit_log;
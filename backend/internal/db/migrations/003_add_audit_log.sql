@@ -0,0 +1,17 @@
+-- 003_add_audit_log.sql
+-- Audit trail for admin catalog mutations (imports, edits, deletes)
+
+create table if not exists audit_log (
+  id uuid primary key default gen_random_uuid(),
+  actor_id uuid null references users(id) on delete set null,
+  actor_email text null,
+  action text not null,
+  entity_type text not null,
+  entity_id text null,
+  before_data jsonb null,
+  after_data jsonb null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists audit_log_actor_created_idx on audit_log (actor_id, created_at desc);
+create index if not exists audit_log_created_idx on audit_log (created_at desc);
@@ -0,0 +1,14 @@
+-- 004_add_programs.down.sql
+-- Reverts 004_add_programs.sql
+
+alter table exercises
+  drop column if exists planned_sets,
+  drop column if exists planned_reps,
+  drop column if exists planned_weight_kg;
+
+alter table workout_days drop column if exists program_id;
+
+drop table if exists program_exercises;
+drop table if exists program_days;
+drop table if exists program_weeks;
+drop table if exists programs;
@@ -0,0 +1,57 @@
+-- 004_add_programs.sql
+-- Multi-week training programs and planned targets on scheduled exercises
+
+create table if not exists programs (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  name text not null,
+  description text null,
+  created_at timestamptz default now(),
+  updated_at timestamptz default now()
+);
+
+create index if not exists programs_user_idx on programs (user_id);
+
+create table if not exists program_weeks (
+  id uuid primary key default gen_random_uuid(),
+  program_id uuid not null references programs(id) on delete cascade,
+  week_number int not null check (week_number > 0),
+  name text null,
+  unique(program_id, week_number)
+);
+
+create table if not exists program_days (
+  id uuid primary key default gen_random_uuid(),
+  week_id uuid not null references program_weeks(id) on delete cascade,
+  day_number int not null check (day_number between 1 and 7),
+  name text null,
+  is_rest_day boolean not null default false,
+  unique(week_id, day_number)
+);
+
+create table if not exists program_exercises (
+  id uuid primary key default gen_random_uuid(),
+  program_day_id uuid not null references program_days(id) on delete cascade,
+  catalog_id uuid not null references exercise_catalog(id) on delete cascade,
+  position int not null,
+  target_sets int not null check (target_sets > 0),
+  target_reps int not null check (target_reps > 0),
+  target_weight_kg numeric(6,2) null check (target_weight_kg >= 0),
+  notes text null
+);
+
+create index if not exists program_exercises_day_position_idx on program_exercises (program_day_id, position);
+
+create trigger trg_programs_updated_at
+before update on programs
+for each row execute procedure set_updated_at();
+
+-- Scheduled days remember which program produced them; exercises carry the
+-- planned targets so adherence survives later edits to the program.
+alter table workout_days
+  add column if not exists program_id uuid null references programs(id) on delete set null;
+
+alter table exercises
+  add column if not exists planned_sets int null check (planned_sets > 0),
+  add column if not exists planned_reps int null check (planned_reps > 0),
+  add column if not exists planned_weight_kg numeric(6,2) null check (planned_weight_kg >= 0);
@@ -0,0 +1,7 @@
+-- 005_add_blob_storage.down.sql
+-- Reverts 005_add_blob_storage.sql. Run this only once images have been
+-- moved back inline; blob-backed images are lost.
+
+alter table exercise_catalog drop column if exists image_key;
+
+drop table if exists blobs;
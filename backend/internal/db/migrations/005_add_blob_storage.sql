@@ -0,0 +1,14 @@
+-- 005_add_blob_storage.sql
+-- Move catalog images behind a blob store. Existing image_data rows are
+-- migrated lazily on first read.
+
+create table if not exists blobs (
+  key text primary key,
+  data bytea not null,
+  content_type text not null default '',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+alter table exercise_catalog
+  add column if not exists image_key text null;
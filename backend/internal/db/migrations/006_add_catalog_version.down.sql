@@ -0,0 +1,10 @@
+-- 006_add_catalog_version.down.sql
+-- Reverts 006_add_catalog_version.sql
+
+drop trigger if exists trg_exercise_catalog_version on exercise_catalog;
+drop trigger if exists trg_catalog_primary_muscles_version on exercise_catalog_primary_muscles;
+drop trigger if exists trg_catalog_secondary_muscles_version on exercise_catalog_secondary_muscles;
+drop trigger if exists trg_exercise_links_version on exercise_links;
+
+drop function if exists bump_catalog_version();
+drop table if exists catalog_version;
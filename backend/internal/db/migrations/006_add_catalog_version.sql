@@ -0,0 +1,38 @@
+-- 006_add_catalog_version.sql
+-- Single-row counter bumped on any catalog write; used for ETags and cache
+-- invalidation.
+
+create table if not exists catalog_version (
+  id boolean primary key default true check (id),
+  version bigint not null default 1,
+  updated_at timestamptz not null default now()
+);
+
+insert into catalog_version (id) values (true) on conflict do nothing;
+
+create or replace function bump_catalog_version() returns trigger as $$
+begin
+  update catalog_version set version = version + 1, updated_at = now() where id;
+  return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_exercise_catalog_version on exercise_catalog;
+create trigger trg_exercise_catalog_version
+after insert or update or delete on exercise_catalog
+for each statement execute procedure bump_catalog_version();
+
+drop trigger if exists trg_catalog_primary_muscles_version on exercise_catalog_primary_muscles;
+create trigger trg_catalog_primary_muscles_version
+after insert or update or delete on exercise_catalog_primary_muscles
+for each statement execute procedure bump_catalog_version();
+
+drop trigger if exists trg_catalog_secondary_muscles_version on exercise_catalog_secondary_muscles;
+create trigger trg_catalog_secondary_muscles_version
+after insert or update or delete on exercise_catalog_secondary_muscles
+for each statement execute procedure bump_catalog_version();
+
+drop trigger if exists trg_exercise_links_version on exercise_links;
+create trigger trg_exercise_links_version
+after insert or update or delete on exercise_links
+for each statement execute procedure bump_catalog_version();
@@ -0,0 +1,8 @@
+-- 007_add_account_deletion.down.sql
+-- Reverts 007_add_account_deletion.sql
+
+drop index if exists users_purge_after_idx;
+
+alter table users
+  drop column if exists deleted_at,
+  drop column if exists purge_after;
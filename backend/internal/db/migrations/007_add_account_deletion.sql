@@ -0,0 +1,9 @@
+-- 007_add_account_deletion.sql
+-- Accounts can be scheduled for deletion with a grace period before their
+-- data is purged.
+
+alter table users
+  add column if not exists deleted_at timestamptz null,
+  add column if not exists purge_after timestamptz null;
+
+create index if not exists users_purge_after_idx on users (purge_after) where purge_after is not null;
@@ -0,0 +1,12 @@
+-- 008_add_sync_tombstones.down.sql
+-- Reverts 008_add_sync_tombstones.sql
+
+drop trigger if exists trg_exercises_deleted on exercises;
+drop trigger if exists trg_sets_deleted on sets;
+drop trigger if exists trg_rest_periods_deleted on rest_periods;
+
+drop function if exists record_deleted_exercise();
+drop function if exists record_deleted_set();
+drop function if exists record_deleted_rest();
+
+drop table if exists deleted_entities;
@@ -0,0 +1,56 @@
+-- 008_add_sync_tombstones.sql
+-- Record deleted exercises, sets and rests so a client with a stale save
+-- epoch can be told what disappeared since it last synced. No FK to users:
+-- rows are written while an account's data is being purged.
+
+create table if not exists deleted_entities (
+  user_id uuid not null,
+  entity_type text not null,
+  entity_id uuid not null,
+  deleted_at timestamptz not null default now()
+);
+
+create index if not exists deleted_entities_user_deleted_idx on deleted_entities (user_id, deleted_at);
+
+create or replace function record_deleted_exercise() returns trigger as $$
+begin
+  insert into deleted_entities (user_id, entity_type, entity_id)
+  select d.user_id, 'exercise', old.id from workout_days d where d.id = old.day_id;
+  return old;
+end;
+$$ language plpgsql;
+
+create or replace function record_deleted_set() returns trigger as $$
+begin
+  insert into deleted_entities (user_id, entity_type, entity_id) values (old.user_id, 'set', old.id);
+  return old;
+end;
+$$ language plpgsql;
+
+-- Rests removed by an exercise delete cascade find no exercise row and are
+-- skipped; the exercise tombstone covers them.
+create or replace function record_deleted_rest() returns trigger as $$
+begin
+  insert into deleted_entities (user_id, entity_type, entity_id)
+  select d.user_id, 'rest', old.id
+  from exercises e
+  join workout_days d on d.id = e.day_id
+  where e.id = old.exercise_id;
+  return old;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_exercises_deleted on exercises;
+create trigger trg_exercises_deleted
+after delete on exercises
+for each row execute procedure record_deleted_exercise();
+
+drop trigger if exists trg_sets_deleted on sets;
+create trigger trg_sets_deleted
+after delete on sets
+for each row execute procedure record_deleted_set();
+
+drop trigger if exists trg_rest_periods_deleted on rest_periods;
+create trigger trg_rest_periods_deleted
+after delete on rest_periods
+for each row execute procedure record_deleted_rest();
@@ -0,0 +1,9 @@
+-- 009_add_session_timing.down.sql
+-- Reverts 009_add_session_timing.sql
+
+alter table workout_days drop constraint if exists workout_days_session_order;
+
+alter table workout_days
+  drop column if exists duration_seconds,
+  drop column if exists started_at,
+  drop column if exists finished_at;
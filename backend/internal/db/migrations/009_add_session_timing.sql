@@ -0,0 +1,15 @@
+-- 009_add_session_timing.sql
+-- Track when a workout session started and finished.
+
+alter table workout_days
+  add column if not exists started_at timestamptz null,
+  add column if not exists finished_at timestamptz null;
+
+alter table workout_days
+  add column if not exists duration_seconds int
+  generated always as (extract(epoch from (finished_at - started_at))::int) stored;
+
+alter table workout_days drop constraint if exists workout_days_session_order;
+alter table workout_days
+  add constraint workout_days_session_order
+  check (finished_at is null or started_at is null or finished_at >= started_at);
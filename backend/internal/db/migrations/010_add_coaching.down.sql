@@ -0,0 +1,4 @@
+-- 010_add_coaching.down.sql
+-- Reverts 010_add_coaching.sql
+
+drop table if exists coach_links;
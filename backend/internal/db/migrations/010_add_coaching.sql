@@ -0,0 +1,18 @@
+-- 010_add_coaching.sql
+-- Clients invite coaches by email; an accepted link gives the coach read (and
+-- optionally write) access to the client's workouts.
+
+create table if not exists coach_links (
+  id uuid primary key default gen_random_uuid(),
+  client_id uuid not null references users(id) on delete cascade,
+  coach_email citext not null,
+  coach_id uuid null references users(id) on delete cascade,
+  can_write boolean not null default false,
+  status text not null default 'pending' check (status in ('pending', 'active')),
+  created_at timestamptz not null default now(),
+  accepted_at timestamptz null,
+  unique (client_id, coach_email)
+);
+
+create index if not exists coach_links_coach_idx on coach_links (coach_id) where coach_id is not null;
+create index if not exists coach_links_email_idx on coach_links (coach_email);
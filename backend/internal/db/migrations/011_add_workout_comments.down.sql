@@ -0,0 +1,4 @@
+-- 011_add_workout_comments.down.sql
+-- Reverts 011_add_workout_comments.sql
+
+drop table if exists workout_comments;
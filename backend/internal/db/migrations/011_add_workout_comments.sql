@@ -0,0 +1,17 @@
+-- 011_add_workout_comments.sql
+-- Feedback threads on workout days, optionally pinned to one exercise.
+-- read_at is set when the day's owner has seen a comment written by someone
+-- else (a coach).
+
+create table if not exists workout_comments (
+  id uuid primary key default gen_random_uuid(),
+  day_id uuid not null references workout_days(id) on delete cascade,
+  exercise_id uuid null references exercises(id) on delete cascade,
+  author_id uuid not null references users(id) on delete cascade,
+  body text not null check (length(body) between 1 and 4000),
+  created_at timestamptz not null default now(),
+  read_at timestamptz null
+);
+
+create index if not exists workout_comments_day_idx on workout_comments (day_id, created_at);
+create index if not exists workout_comments_unread_idx on workout_comments (day_id) where read_at is null;
@@ -0,0 +1,4 @@
+-- 012_add_api_keys.down.sql
+-- Reverts 012_add_api_keys.sql
+
+drop table if exists api_keys;
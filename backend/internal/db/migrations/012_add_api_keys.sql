@@ -0,0 +1,17 @@
+-- 012_add_api_keys.sql
+-- Personal API keys for scripts and third-party apps. Only a SHA-256 of the
+-- key is stored; prefix is kept so users can tell keys apart.
+
+create table if not exists api_keys (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  name text not null,
+  prefix text not null,
+  key_hash text not null unique,
+  scope text not null check (scope in ('read', 'write')),
+  created_at timestamptz not null default now(),
+  last_used_at timestamptz null,
+  revoked_at timestamptz null
+);
+
+create index if not exists api_keys_user_idx on api_keys (user_id);
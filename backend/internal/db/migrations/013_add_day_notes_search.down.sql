@@ -0,0 +1,4 @@
+-- 013_add_day_notes_search.down.sql
+-- Reverts 013_add_day_notes_search.sql
+
+drop index if exists workout_days_notes_trgm_idx;
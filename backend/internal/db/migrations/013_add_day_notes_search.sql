@@ -0,0 +1,5 @@
+-- 013_add_day_notes_search.sql
+-- Trigram index on day notes for history search; exercise names and comments
+-- are already indexed.
+
+create index if not exists workout_days_notes_trgm_idx on workout_days using gin (notes gin_trgm_ops);
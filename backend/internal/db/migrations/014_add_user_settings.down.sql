@@ -0,0 +1,4 @@
+-- 014_add_user_settings.down.sql
+-- Reverts 014_add_user_settings.sql
+
+drop table if exists user_settings;
@@ -0,0 +1,9 @@
+-- 014_add_user_settings.sql
+-- Per-user training preferences. A missing row means all defaults.
+
+create table if not exists user_settings (
+  user_id uuid primary key references users(id) on delete cascade,
+  bar_weight_kg numeric(6,2) not null default 20 check (bar_weight_kg >= 0),
+  plate_increment_kg numeric(6,2) not null default 1.25 check (plate_increment_kg > 0),
+  updated_at timestamptz not null default now()
+);
@@ -0,0 +1,7 @@
+-- 015_add_equipment_profile.down.sql
+-- Reverts 015_add_equipment_profile.sql
+
+alter table user_settings
+  drop column if exists units,
+  drop column if exists plates,
+  drop column if exists bar_weights;
@@ -0,0 +1,9 @@
+-- 015_add_equipment_profile.sql
+-- Weight unit preference and home/gym equipment for the plate calculator.
+-- plates is a JSON array of {weight, count}; plates and bar_weights are in
+-- the user's unit. Null means the defaults for that unit.
+
+alter table user_settings
+  add column if not exists units text not null default 'kg' check (units in ('kg', 'lb')),
+  add column if not exists plates jsonb,
+  add column if not exists bar_weights jsonb;
@@ -0,0 +1,12 @@
+-- 016_add_set_types.down.sql
+-- Reverts 016_add_set_types.sql. Fails while non-strength sets without reps
+-- exist; delete or convert them first.
+
+alter table sets drop constraint if exists sets_type_measurement;
+alter table sets drop constraint if exists sets_reps_check;
+alter table sets add constraint sets_reps_check check (reps > 0);
+
+alter table sets
+  drop column if exists set_type,
+  drop column if exists duration_seconds,
+  drop column if exists distance_m;
@@ -0,0 +1,20 @@
+-- 016_add_set_types.sql
+-- Sets can be strength (reps x weight), cardio, duration (planks, holds) or
+-- distance work. Only strength sets need reps; the other types need their
+-- measurement.
+
+alter table sets
+  add column if not exists set_type text not null default 'strength'
+    check (set_type in ('strength', 'cardio', 'duration', 'distance')),
+  add column if not exists duration_seconds int null check (duration_seconds >= 0),
+  add column if not exists distance_m numeric(9,2) null check (distance_m >= 0);
+
+alter table sets drop constraint if exists sets_reps_check;
+alter table sets add constraint sets_reps_check check (reps >= 0 and (set_type <> 'strength' or reps > 0));
+
+alter table sets drop constraint if exists sets_type_measurement;
+alter table sets add constraint sets_type_measurement check (
+  (set_type <> 'duration' or duration_seconds is not null)
+  and (set_type <> 'distance' or distance_m is not null)
+  and (set_type <> 'cardio' or duration_seconds is not null or distance_m is not null)
+);
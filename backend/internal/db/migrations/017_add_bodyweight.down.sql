@@ -0,0 +1,41 @@
+-- 017_add_bodyweight.down.sql
+-- Reverts 017_add_bodyweight.sql: volume goes back to weight_kg * reps and
+-- set_facts is rebuilt on top of it.
+
+drop trigger if exists trg_bodyweight_refresh_loads on bodyweight_entries;
+drop trigger if exists trg_sets_effective_load on sets;
+drop function if exists refresh_bodyweight_loads();
+drop function if exists set_effective_load();
+drop function if exists bodyweight_load(uuid, uuid, date, numeric);
+
+drop materialized view if exists set_facts;
+
+alter table sets alter column volume_kg set expression as (weight_kg * reps);
+alter table sets drop column if exists effective_load_kg;
+
+create materialized view set_facts as
+select
+  s.id as set_id,
+  s.user_id,
+  d.workout_date,
+  coalesce(ec.slug, lower(e.name)) as exercise_slug,
+  e.name as exercise_name,
+  e.comment as exercise_comment,
+  s.reps,
+  s.weight_kg,
+  s.volume_kg,
+  s.is_warmup,
+  s.performed_at,
+  extract(isodow from d.workout_date) as dow
+from sets s
+join exercises e on e.id = s.exercise_id
+join workout_days d on d.id = e.day_id
+left join exercise_catalog ec on ec.id = e.catalog_id
+with no data;
+
+create index if not exists set_facts_user_date_idx on set_facts (user_id, workout_date);
+create index if not exists set_facts_slug_idx on set_facts (exercise_slug);
+create index if not exists set_facts_workout_date_idx on set_facts (workout_date);
+create index if not exists set_facts_workout_date_brin on set_facts using brin (workout_date);
+
+drop table if exists bodyweight_entries;
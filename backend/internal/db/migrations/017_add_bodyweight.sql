@@ -0,0 +1,92 @@
+-- 017_add_bodyweight.sql
+-- Bodyweight log, and effective load for bodyweight exercises: the closest
+-- bodyweight measurement (preferring one on or before the workout date)
+-- times the catalog multiplier, plus any added weight. volume_kg uses the
+-- effective load when there is one.
+
+create table if not exists bodyweight_entries (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  measured_on date not null,
+  weight_kg numeric(5,2) not null check (weight_kg > 0),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  unique (user_id, measured_on)
+);
+
+alter table sets add column if not exists effective_load_kg numeric(7,2) null;
+
+create or replace function bodyweight_load(p_exercise_id uuid, p_user_id uuid, p_date date, p_weight numeric)
+returns numeric as $$
+  select round(bw.weight_kg * c.multiplier + p_weight, 2)
+  from exercises e
+  join exercise_catalog c on c.id = e.catalog_id
+  cross join lateral (
+    select b.weight_kg
+    from bodyweight_entries b
+    where b.user_id = p_user_id
+    order by (b.measured_on > p_date), abs(b.measured_on - p_date)
+    limit 1
+  ) bw
+  where e.id = p_exercise_id and c.equipment in ('Body Only', 'Bodyweight')
+$$ language sql stable;
+
+create or replace function set_effective_load() returns trigger as $$
+begin
+  new.effective_load_kg := bodyweight_load(new.exercise_id, new.user_id, new.workout_date, new.weight_kg);
+  return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_sets_effective_load on sets;
+create trigger trg_sets_effective_load
+before insert or update of exercise_id, workout_date, weight_kg on sets
+for each row execute procedure set_effective_load();
+
+create or replace function refresh_bodyweight_loads() returns trigger as $$
+declare
+  uid uuid := coalesce(new.user_id, old.user_id);
+begin
+  update sets s
+  set effective_load_kg = bodyweight_load(s.exercise_id, s.user_id, s.workout_date, s.weight_kg)
+  from exercises e
+  join exercise_catalog c on c.id = e.catalog_id
+  where s.user_id = uid and e.id = s.exercise_id and c.equipment in ('Body Only', 'Bodyweight');
+  return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_bodyweight_refresh_loads on bodyweight_entries;
+create trigger trg_bodyweight_refresh_loads
+after insert or update or delete on bodyweight_entries
+for each row execute procedure refresh_bodyweight_loads();
+
+-- set_facts depends on volume_kg; rebuild it around the new expression.
+drop materialized view if exists set_facts;
+
+alter table sets alter column volume_kg set expression as (coalesce(effective_load_kg, weight_kg) * reps);
+
+create materialized view set_facts as
+select
+  s.id as set_id,
+  s.user_id,
+  d.workout_date,
+  coalesce(ec.slug, lower(e.name)) as exercise_slug,
+  e.name as exercise_name,
+  e.comment as exercise_comment,
+  s.reps,
+  s.weight_kg,
+  s.volume_kg,
+  s.is_warmup,
+  s.performed_at,
+  extract(isodow from d.workout_date) as dow
+from sets s
+join exercises e on e.id = s.exercise_id
+join workout_days d on d.id = e.day_id
+left join exercise_catalog ec on ec.id = e.catalog_id
+with no data;
+
+create index if not exists set_facts_user_date_idx on set_facts (user_id, workout_date);
+create index if not exists set_facts_slug_idx on set_facts (exercise_slug);
+create index if not exists set_facts_workout_date_idx on set_facts (workout_date);
+create index if not exists set_facts_workout_date_brin on set_facts using brin (workout_date);
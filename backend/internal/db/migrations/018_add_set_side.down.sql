@@ -0,0 +1,4 @@
+-- 018_add_set_side.down.sql
+-- Reverts 018_add_set_side.sql
+
+alter table sets drop column if exists side;
@@ -0,0 +1,4 @@
+-- 018_add_set_side.sql
+-- Which side a unilateral set worked. Null means not recorded.
+
+alter table sets add column if not exists side text null check (side in ('left', 'right', 'both'));
@@ -0,0 +1,4 @@
+-- 019_add_gym_profiles.down.sql
+-- Reverts 019_add_gym_profiles.sql
+
+drop table if exists gym_profiles;
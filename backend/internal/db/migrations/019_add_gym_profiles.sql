@@ -0,0 +1,16 @@
+-- 019_add_gym_profiles.sql
+-- Named places a user trains at and the equipment available there; the
+-- catalog search can be limited to what a profile can do.
+
+create table if not exists gym_profiles (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  name text not null,
+  kind text not null check (kind in ('home', 'commercial')),
+  equipment text[] not null default '{}',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  unique (user_id, name)
+);
+
+create index if not exists idx_gym_profiles_user on gym_profiles(user_id);
@@ -0,0 +1,15 @@
+-- 020_add_catalog_review.down.sql
+-- Reverts 020_add_catalog_review.sql. Pending and rejected submissions are
+-- deleted so they don't become public once the status column is gone.
+
+delete from exercise_catalog where status <> 'approved';
+
+drop index if exists exercise_catalog_review_idx;
+drop index if exists exercise_catalog_submitted_by_idx;
+
+alter table exercise_catalog
+  drop column if exists status,
+  drop column if exists submitted_by,
+  drop column if exists review_feedback,
+  drop column if exists reviewed_by,
+  drop column if exists reviewed_at;
@@ -0,0 +1,15 @@
+-- 020_add_catalog_review.sql
+-- User-submitted catalog entries wait in a review queue. Existing and
+-- admin-imported entries are approved; pending ones are only visible to
+-- their author until an admin approves or rejects them.
+
+alter table exercise_catalog
+  add column if not exists status text not null default 'approved'
+    check (status in ('pending', 'approved', 'rejected')),
+  add column if not exists submitted_by uuid null references users(id) on delete set null,
+  add column if not exists review_feedback text null,
+  add column if not exists reviewed_by uuid null references users(id) on delete set null,
+  add column if not exists reviewed_at timestamptz null;
+
+create index if not exists exercise_catalog_review_idx on exercise_catalog (status, created_at) where status <> 'approved';
+create index if not exists exercise_catalog_submitted_by_idx on exercise_catalog (submitted_by) where submitted_by is not null;
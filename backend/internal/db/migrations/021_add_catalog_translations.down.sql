@@ -0,0 +1,4 @@
+-- 021_add_catalog_translations.down.sql
+-- Reverts 021_add_catalog_translations.sql
+
+drop table if exists catalog_translations;
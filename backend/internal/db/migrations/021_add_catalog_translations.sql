@@ -0,0 +1,19 @@
+-- 021_add_catalog_translations.sql
+-- Per-locale names and descriptions for catalog entries. Locales are
+-- lowercase BCP 47 tags ("de", "pt-br"); the base columns stay English.
+
+create table if not exists catalog_translations (
+  catalog_id uuid not null references exercise_catalog(id) on delete cascade,
+  locale text not null check (locale ~ '^[a-z]{2,3}(-[a-z0-9]{2,8})*$'),
+  name text not null,
+  description text null,
+  updated_at timestamptz not null default now(),
+  primary key (catalog_id, locale)
+);
+
+create index if not exists catalog_translations_name_trgm_idx on catalog_translations using gin (name gin_trgm_ops);
+
+drop trigger if exists trg_catalog_translations_version on catalog_translations;
+create trigger trg_catalog_translations_version
+after insert or update or delete on catalog_translations
+for each statement execute procedure bump_catalog_version();
@@ -0,0 +1,4 @@
+-- 022_add_link_metadata.down.sql
+-- Reverts 022_add_link_metadata.sql
+
+drop table if exists link_metadata;
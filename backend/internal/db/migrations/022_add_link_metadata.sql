@@ -0,0 +1,18 @@
+-- 022_add_link_metadata.sql
+-- oEmbed/OpenGraph metadata for catalog links, fetched in the background.
+-- Failed fetches are kept (status 'failed') and retried after a while.
+
+create table if not exists link_metadata (
+  url text primary key,
+  status text not null check (status in ('ok', 'failed')),
+  provider text null,
+  title text null,
+  thumbnail_url text null,
+  error text null,
+  fetched_at timestamptz not null default now()
+);
+
+drop trigger if exists trg_link_metadata_version on link_metadata;
+create trigger trg_link_metadata_version
+after insert or update or delete on link_metadata
+for each statement execute procedure bump_catalog_version();
@@ -0,0 +1,4 @@
+-- 023_add_jobs.down.sql
+-- Reverts 023_add_jobs.sql
+
+drop table if exists jobs;
@@ -0,0 +1,22 @@
+-- 023_add_jobs.sql
+-- Background job queue. Workers claim due rows with "for update skip
+-- locked", so several server instances can share it. dedupe_key, when set,
+-- makes enqueueing the same logical job twice a no-op.
+
+create table if not exists jobs (
+  id uuid primary key default gen_random_uuid(),
+  kind text not null,
+  payload jsonb not null default '{}'::jsonb,
+  dedupe_key text null unique,
+  status text not null default 'queued' check (status in ('queued', 'running', 'done', 'failed')),
+  run_at timestamptz not null default now(),
+  attempts int not null default 0,
+  max_attempts int not null default 5 check (max_attempts > 0),
+  last_error text null,
+  locked_at timestamptz null,
+  finished_at timestamptz null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists jobs_due_idx on jobs (run_at) where status in ('queued', 'running');
+create index if not exists jobs_finished_idx on jobs (finished_at) where finished_at is not null;
@@ -0,0 +1,5 @@
+-- 024_add_reminders.down.sql
+-- Reverts 024_add_reminders.sql
+
+drop table if exists push_subscriptions;
+drop table if exists reminders;
@@ -0,0 +1,31 @@
+-- 024_add_reminders.sql
+-- Workout reminders (ISO weekdays 1 = Monday .. 7 = Sunday, local time in
+-- the reminder's timezone) and the web push subscriptions they go to.
+
+create table if not exists reminders (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  days_of_week int[] not null check (cardinality(days_of_week) > 0 and days_of_week <@ array[1,2,3,4,5,6,7]),
+  time_of_day time not null,
+  timezone text not null,
+  channels text[] not null check (cardinality(channels) > 0 and channels <@ array['email','push']::text[]),
+  enabled boolean not null default true,
+  next_run_at timestamptz null,
+  last_sent_at timestamptz null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists reminders_user_idx on reminders (user_id);
+create index if not exists reminders_due_idx on reminders (next_run_at) where enabled;
+
+create table if not exists push_subscriptions (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  endpoint text not null unique,
+  p256dh text not null,
+  auth text not null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists push_subscriptions_user_idx on push_subscriptions (user_id);
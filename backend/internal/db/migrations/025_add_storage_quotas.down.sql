@@ -0,0 +1,10 @@
+-- 025_add_storage_quotas.down.sql
+-- Reverts 025_add_storage_quotas.sql
+
+drop trigger if exists trg_sets_usage on sets;
+drop trigger if exists trg_workout_days_usage on workout_days;
+drop function if exists track_set_usage();
+drop function if exists track_workout_day_usage();
+drop table if exists user_quotas;
+drop table if exists quota_defaults;
+drop table if exists user_usage;
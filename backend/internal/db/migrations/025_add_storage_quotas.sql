@@ -0,0 +1,89 @@
+-- 025_add_storage_quotas.sql
+-- Per-user storage quotas on workout days and sets. user_usage keeps running
+-- counts maintained by triggers, so a quota check is a single row update
+-- rather than a count over the user's history. Limits come from
+-- user_quotas, falling back to the single quota_defaults row; null means
+-- unlimited.
+
+create table if not exists user_usage (
+  user_id uuid primary key references users(id) on delete cascade,
+  workout_days integer not null default 0,
+  sets integer not null default 0
+);
+
+insert into user_usage (user_id, workout_days, sets)
+select u.id,
+       (select count(*) from workout_days d where d.user_id = u.id),
+       (select count(*) from sets s where s.user_id = u.id)
+from users u
+on conflict (user_id) do nothing;
+
+create table if not exists quota_defaults (
+  singleton boolean primary key default true check (singleton),
+  max_workout_days integer null check (max_workout_days >= 0),
+  max_sets integer null check (max_sets >= 0),
+  updated_at timestamptz not null default now()
+);
+
+insert into quota_defaults (singleton) values (true) on conflict do nothing;
+
+create table if not exists user_quotas (
+  user_id uuid primary key references users(id) on delete cascade,
+  max_workout_days integer null check (max_workout_days >= 0),
+  max_sets integer null check (max_sets >= 0),
+  updated_at timestamptz not null default now()
+);
+
+create or replace function track_workout_day_usage() returns trigger as $$
+declare
+  used integer;
+  lim integer;
+begin
+  if tg_op = 'DELETE' then
+    update user_usage set workout_days = workout_days - 1 where user_id = old.user_id;
+    return old;
+  end if;
+  insert into user_usage (user_id, workout_days) values (new.user_id, 1)
+  on conflict (user_id) do update set workout_days = user_usage.workout_days + 1
+  returning workout_days into used;
+  select coalesce(q.max_workout_days, d.max_workout_days) into lim
+  from quota_defaults d left join user_quotas q on q.user_id = new.user_id;
+  if lim is not null and used > lim then
+    raise exception 'storage quota exceeded: at most % workout days', lim
+      using errcode = '23514', constraint = 'quota_workout_days', detail = lim::text;
+  end if;
+  return new;
+end;
+$$ language plpgsql;
+
+create or replace function track_set_usage() returns trigger as $$
+declare
+  used integer;
+  lim integer;
+begin
+  if tg_op = 'DELETE' then
+    update user_usage set sets = sets - 1 where user_id = old.user_id;
+    return old;
+  end if;
+  insert into user_usage (user_id, sets) values (new.user_id, 1)
+  on conflict (user_id) do update set sets = user_usage.sets + 1
+  returning sets into used;
+  select coalesce(q.max_sets, d.max_sets) into lim
+  from quota_defaults d left join user_quotas q on q.user_id = new.user_id;
+  if lim is not null and used > lim then
+    raise exception 'storage quota exceeded: at most % sets', lim
+      using errcode = '23514', constraint = 'quota_sets', detail = lim::text;
+  end if;
+  return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_workout_days_usage on workout_days;
+create trigger trg_workout_days_usage
+after insert or delete on workout_days
+for each row execute procedure track_workout_day_usage();
+
+drop trigger if exists trg_sets_usage on sets;
+create trigger trg_sets_usage
+after insert or delete on sets
+for each row execute procedure track_set_usage();
@@ -0,0 +1,4 @@
+-- 026_add_sessions.down.sql
+-- Reverts 026_add_sessions.sql
+
+drop table if exists sessions;
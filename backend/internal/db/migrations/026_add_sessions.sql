@@ -0,0 +1,16 @@
+-- 026_add_sessions.sql
+-- Server-side record of each sign-in. Session JWTs carry the row id, so a
+-- revoked row signs that device out before its token expires.
+
+create table if not exists sessions (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  user_agent text not null default '',
+  ip text not null default '',
+  created_at timestamptz not null default now(),
+  last_seen_at timestamptz not null default now(),
+  expires_at timestamptz not null,
+  revoked_at timestamptz null
+);
+
+create index if not exists sessions_user_idx on sessions (user_id);
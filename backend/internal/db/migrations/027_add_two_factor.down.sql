@@ -0,0 +1,5 @@
+-- 027_add_two_factor.down.sql
+-- Reverts 027_add_two_factor.sql
+
+drop table if exists user_recovery_codes;
+drop table if exists user_totp;
@@ -0,0 +1,20 @@
+-- 027_add_two_factor.sql
+-- TOTP two-factor authentication. A row without enabled_at is an enrollment
+-- awaiting its first code. last_step is the newest time step accepted, so a
+-- code can't be used twice. Recovery codes are stored as SHA-256 hashes.
+
+create table if not exists user_totp (
+  user_id uuid primary key references users(id) on delete cascade,
+  secret text not null,
+  enabled_at timestamptz null,
+  last_step bigint not null default 0,
+  created_at timestamptz not null default now()
+);
+
+create table if not exists user_recovery_codes (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  code_hash text not null,
+  used_at timestamptz null,
+  unique (user_id, code_hash)
+);
@@ -0,0 +1,5 @@
+-- 028_add_guest_users.down.sql
+-- Reverts 028_add_guest_users.sql. Unclaimed guest accounts are kept as
+-- ordinary accounts nobody can sign in to.
+
+alter table users drop column if exists is_guest;
@@ -0,0 +1,7 @@
+-- 028_add_guest_users.sql
+-- Guest accounts: created without credentials so people can start logging
+-- right away, and claimed later by setting an email and password. Guests
+-- get a placeholder address under the reserved .invalid TLD and an empty
+-- password hash, which never verifies.
+
+alter table users add column if not exists is_guest boolean not null default false;
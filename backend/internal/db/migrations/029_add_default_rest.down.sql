@@ -0,0 +1,5 @@
+-- 029_add_default_rest.down.sql
+-- Reverts 029_add_default_rest.sql
+
+drop table if exists user_exercise_rest;
+alter table user_settings drop column if exists default_rest_seconds;
@@ -0,0 +1,16 @@
+-- 029_add_default_rest.sql
+-- Default rest between sets: a per-user fallback in user_settings and
+-- per-exercise overrides keyed by catalog entry. /api/save uses them to
+-- insert rest periods when a batch asks for autoRest.
+
+alter table user_settings
+  add column if not exists default_rest_seconds integer null
+  check (default_rest_seconds between 1 and 3600);
+
+create table if not exists user_exercise_rest (
+  user_id uuid not null references users(id) on delete cascade,
+  catalog_id uuid not null references exercise_catalog(id) on delete cascade,
+  rest_seconds integer not null check (rest_seconds between 1 and 3600),
+  updated_at timestamptz not null default now(),
+  primary key (user_id, catalog_id)
+);
@@ -0,0 +1,6 @@
+-- 030_add_set_autostamp.down.sql
+-- Reverts 030_add_set_autostamp.sql. Timestamps already stamped are kept.
+
+drop trigger if exists trg_sets_stamp_performed_at on sets;
+drop function if exists stamp_set_performed_at();
+alter table user_settings drop column if exists stamp_sets;
@@ -0,0 +1,23 @@
+-- 030_add_set_autostamp.sql
+-- Optional server-side set timestamps. With user_settings.stamp_sets on,
+-- a set inserted without performed_at gets the wall-clock time of the
+-- insert (clock_timestamp, so sets saved in one batch still differ).
+
+alter table user_settings add column if not exists stamp_sets boolean not null default false;
+
+create or replace function stamp_set_performed_at()
+returns trigger as $$
+begin
+  if new.performed_at is null
+     and exists (select 1 from user_settings where user_id = new.user_id and stamp_sets) then
+    new.performed_at := clock_timestamp();
+  end if;
+  return new;
+end;
+$$ language plpgsql;
+
+-- Named to sort after trg_sets_denorm_insert, which fills user_id.
+drop trigger if exists trg_sets_stamp_performed_at on sets;
+create trigger trg_sets_stamp_performed_at
+before insert on sets
+for each row execute procedure stamp_set_performed_at();
@@ -0,0 +1,16 @@
+-- 031_add_change_journal.down.sql
+-- Reverts 031_add_change_journal.sql
+
+drop trigger if exists trg_workout_days_journal on workout_days;
+drop trigger if exists trg_workout_days_journal_delete on workout_days;
+drop trigger if exists trg_exercises_journal on exercises;
+drop trigger if exists trg_exercises_journal_delete on exercises;
+drop trigger if exists trg_sets_journal on sets;
+drop trigger if exists trg_sets_journal_delete on sets;
+drop trigger if exists trg_rest_periods_journal on rest_periods;
+drop trigger if exists trg_rest_periods_journal_delete on rest_periods;
+
+drop function if exists journal_change();
+drop function if exists journal_revert(bigint);
+
+drop table if exists change_journal;
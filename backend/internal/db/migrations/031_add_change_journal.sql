@@ -0,0 +1,181 @@
+-- 031_add_change_journal.sql
+-- Journal every change to days, exercises, sets and rests for the day history
+-- and undo. Updates keep only the fields that changed (before/after), inserts
+-- the new row and deletes the old one. The source and save op index come from
+-- transaction-local settings (fitlog.journal_source, fitlog.journal_op) that
+-- the save batch sets; plain REST writes default to 'rest'. Rows sharing a
+-- tx_id are one change for undo. No FK to users, like deleted_entities.
+
+create table if not exists change_journal (
+  id bigserial primary key,
+  user_id uuid not null,
+  day_id uuid null,
+  tx_id bigint not null default txid_current(),
+  entity text not null check (entity in ('day', 'exercise', 'set', 'rest')),
+  entity_id uuid not null,
+  action text not null check (action in ('insert', 'update', 'delete')),
+  before jsonb null,
+  after jsonb null,
+  source text not null default 'rest',
+  op_index int null,
+  created_at timestamptz not null default now(),
+  undone_at timestamptz null
+);
+
+create index if not exists change_journal_user_idx on change_journal (user_id, id desc);
+create index if not exists change_journal_day_idx on change_journal (day_id, id desc);
+
+-- Runs after insert and update, so generated columns are filled in, and
+-- before delete, so a parent is journaled ahead of the rows its delete
+-- cascades to. Those children find the parent gone and take the user and day
+-- from its journal entry instead.
+create or replace function journal_change() returns trigger as $$
+declare
+  entity text := tg_argv[0];
+  rec jsonb;
+  v_before jsonb;
+  v_after jsonb;
+  v_user uuid;
+  v_day uuid;
+  k text;
+begin
+  if tg_op = 'DELETE' then
+    rec := to_jsonb(old);
+    v_before := rec;
+  elsif tg_op = 'INSERT' then
+    rec := to_jsonb(new);
+    v_after := rec;
+  else
+    rec := to_jsonb(new);
+    v_before := '{}';
+    v_after := '{}';
+    for k in select jsonb_object_keys(rec) loop
+      if k <> 'updated_at' and (to_jsonb(old) -> k) is distinct from (rec -> k) then
+        v_before := v_before || jsonb_build_object(k, to_jsonb(old) -> k);
+        v_after := v_after || jsonb_build_object(k, rec -> k);
+      end if;
+    end loop;
+    if v_before = '{}' then
+      return null;
+    end if;
+  end if;
+
+  if entity = 'day' then
+    v_user := (rec ->> 'user_id')::uuid;
+    v_day := (rec ->> 'id')::uuid;
+  elsif entity = 'exercise' then
+    v_day := (rec ->> 'day_id')::uuid;
+    select d.user_id into v_user from workout_days d where d.id = v_day;
+    if v_user is null then
+      select j.user_id into v_user from change_journal j
+      where j.entity = 'day' and j.entity_id = v_day
+      order by j.id desc limit 1;
+    end if;
+  else
+    select e.day_id, d.user_id into v_day, v_user
+    from exercises e join workout_days d on d.id = e.day_id
+    where e.id = (rec ->> 'exercise_id')::uuid;
+    if v_user is null then
+      select j.day_id, j.user_id into v_day, v_user from change_journal j
+      where j.entity = 'exercise' and j.entity_id = (rec ->> 'exercise_id')::uuid
+      order by j.id desc limit 1;
+    end if;
+  end if;
+
+  -- Nothing to undo for an account being purged.
+  if v_user is not null and exists (select 1 from users u where u.id = v_user) then
+    insert into change_journal (user_id, day_id, entity, entity_id, action, before, after, source, op_index)
+    values (
+      v_user, v_day, entity, (rec ->> 'id')::uuid, lower(tg_op), v_before, v_after,
+      coalesce(nullif(current_setting('fitlog.journal_source', true), ''), 'rest'),
+      nullif(current_setting('fitlog.journal_op', true), '')::int
+    );
+  end if;
+
+  if tg_op = 'DELETE' then
+    return old;
+  end if;
+  return null;
+end;
+$$ language plpgsql;
+
+-- journal_revert undoes one journal entry: an insert is deleted, a delete is
+-- restored from its old row and an update gets its changed fields back.
+-- Generated columns are left to the database.
+create or replace function journal_revert(entry_id bigint) returns void as $$
+declare
+  j change_journal;
+  tbl text;
+  cols text;
+begin
+  select * into j from change_journal where id = entry_id;
+  tbl := case j.entity
+    when 'day' then 'workout_days'
+    when 'exercise' then 'exercises'
+    when 'set' then 'sets'
+    else 'rest_periods'
+  end;
+
+  if j.action = 'insert' then
+    execute format('delete from %I where id = $1', tbl) using j.entity_id;
+    return;
+  end if;
+
+  select string_agg(quote_ident(a.attname), ', ' order by a.attnum) into cols
+  from pg_attribute a
+  where a.attrelid = tbl::regclass and a.attnum > 0 and not a.attisdropped and a.attgenerated = ''
+    and (j.action = 'delete' or j.before ? a.attname);
+  if cols is null then
+    return;
+  end if;
+
+  if j.action = 'delete' then
+    execute format('insert into %I (%s) select %s from jsonb_populate_record(null::%I, $1) on conflict (id) do nothing',
+      tbl, cols, cols, tbl) using j.before;
+    delete from deleted_entities where entity_id = j.entity_id;
+  else
+    execute format('update %I t set (%s) = (select %s from jsonb_populate_record(null::%I, $1)) where t.id = $2',
+      tbl, cols, cols, tbl) using j.before, j.entity_id;
+  end if;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_workout_days_journal on workout_days;
+create trigger trg_workout_days_journal
+after insert or update on workout_days
+for each row execute procedure journal_change('day');
+
+drop trigger if exists trg_workout_days_journal_delete on workout_days;
+create trigger trg_workout_days_journal_delete
+before delete on workout_days
+for each row execute procedure journal_change('day');
+
+drop trigger if exists trg_exercises_journal on exercises;
+create trigger trg_exercises_journal
+after insert or update on exercises
+for each row execute procedure journal_change('exercise');
+
+drop trigger if exists trg_exercises_journal_delete on exercises;
+create trigger trg_exercises_journal_delete
+before delete on exercises
+for each row execute procedure journal_change('exercise');
+
+drop trigger if exists trg_sets_journal on sets;
+create trigger trg_sets_journal
+after insert or update on sets
+for each row execute procedure journal_change('set');
+
+drop trigger if exists trg_sets_journal_delete on sets;
+create trigger trg_sets_journal_delete
+before delete on sets
+for each row execute procedure journal_change('set');
+
+drop trigger if exists trg_rest_periods_journal on rest_periods;
+create trigger trg_rest_periods_journal
+after insert or update on rest_periods
+for each row execute procedure journal_change('rest');
+
+drop trigger if exists trg_rest_periods_journal_delete on rest_periods;
+create trigger trg_rest_periods_journal_delete
+before delete on rest_periods
+for each row execute procedure journal_change('rest');
@@ -0,0 +1,4 @@
+-- 032_add_template_shares.down.sql
+-- Reverts 032_add_template_shares.sql
+
+drop table if exists template_shares;
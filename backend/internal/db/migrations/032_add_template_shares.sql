@@ -0,0 +1,18 @@
+-- 032_add_template_shares.sql
+-- Share codes for copying a workout day or a program into another account.
+-- The template is snapshotted when published, with exercises referenced by
+-- catalog slug, so later edits or deletes of the source don't change what an
+-- importer gets.
+
+create table if not exists template_shares (
+  code text primary key,
+  user_id uuid not null references users(id) on delete cascade,
+  kind text not null check (kind in ('day', 'program')),
+  source_id uuid not null,
+  name text not null,
+  snapshot jsonb not null,
+  imports int not null default 0,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists template_shares_user_idx on template_shares (user_id, created_at desc);
@@ -0,0 +1,6 @@
+-- 033_add_catalog_instructions.down.sql
+-- Reverts 033_add_catalog_instructions.sql
+
+alter table exercise_catalog drop column if exists common_mistakes;
+alter table exercise_catalog drop column if exists cues;
+alter table exercise_catalog drop column if exists instructions;
@@ -0,0 +1,8 @@
+-- 033_add_catalog_instructions.sql
+-- Structured coaching content for catalog entries: ordered instruction
+-- steps, form cues and common mistakes. Stored as arrays next to links;
+-- the catalog version trigger picks up edits like any other column.
+
+alter table exercise_catalog add column if not exists instructions text[] not null default '{}'::text[];
+alter table exercise_catalog add column if not exists cues text[] not null default '{}'::text[];
+alter table exercise_catalog add column if not exists common_mistakes text[] not null default '{}'::text[];
@@ -0,0 +1,5 @@
+-- 034_add_muscle_aliases.down.sql
+-- Reverts 034_add_muscle_aliases.sql. Muscles merged away stay merged.
+
+drop function if exists canonical_muscle(text);
+drop table if exists muscle_aliases;
@@ -0,0 +1,49 @@
+-- 034_add_muscle_aliases.sql
+-- Canonical muscle taxonomy. muscle_types keeps the canonical names;
+-- muscle_aliases maps other spellings (stored lower-cased) onto them.
+-- canonical_muscle() resolves a free-form name at write time: an alias
+-- first, then a case-insensitive match on a canonical name, else the name
+-- itself (which then becomes a new canonical name).
+
+create table if not exists muscle_aliases (
+  alias text primary key check (alias = lower(btrim(alias)) and alias <> ''),
+  muscle text not null references muscle_types(name) on delete cascade,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists muscle_aliases_muscle_idx on muscle_aliases (muscle);
+
+create or replace function canonical_muscle(name text)
+returns text as $$
+  select coalesce(
+    (select a.muscle from muscle_aliases a where a.alias = lower(btrim(name))),
+    (select mt.name from muscle_types mt where lower(mt.name) = lower(btrim(name)) order by mt.name limit 1),
+    btrim(name)
+  )
+$$ language sql stable;
+
+-- Common spellings of the dataset's names, for the canonical names that
+-- are already present.
+insert into muscle_aliases (alias, muscle)
+select v.alias, mt.name
+from (values
+  ('abs', 'Abdominals'),
+  ('bicep', 'Biceps'),
+  ('calf', 'Calves'),
+  ('deltoids', 'Shoulders'),
+  ('delts', 'Shoulders'),
+  ('erector spinae', 'Lower Back'),
+  ('forearm', 'Forearms'),
+  ('glute', 'Glutes'),
+  ('hamstring', 'Hamstrings'),
+  ('lat', 'Lats'),
+  ('latissimus dorsi', 'Lats'),
+  ('pecs', 'Chest'),
+  ('pectorals', 'Chest'),
+  ('quads', 'Quadriceps'),
+  ('rhomboids', 'Middle Back'),
+  ('trapezius', 'Traps'),
+  ('tricep', 'Triceps')
+) as v(alias, muscle)
+join muscle_types mt on mt.name = v.muscle
+on conflict do nothing;
@@ -0,0 +1,12 @@
+-- 035_add_stat_rollups.down.sql
+-- Reverts 035_add_stat_rollups.sql
+
+drop trigger if exists trg_exercises_stats_update on exercises;
+drop trigger if exists trg_sets_stats_delete on sets;
+drop trigger if exists trg_sets_stats_update on sets;
+drop trigger if exists trg_sets_stats_insert on sets;
+drop function if exists exercises_refresh_stat_rollups();
+drop function if exists sets_refresh_stat_rollups();
+drop function if exists refresh_stat_rollups(uuid, date[]);
+drop table if exists stats_exercise_daily;
+drop table if exists stats_daily;
@@ -0,0 +1,186 @@
+-- 035_add_stat_rollups.sql
+-- Per-user daily and per-exercise daily rollups of working sets, read by
+-- the analytics endpoints instead of aggregating sets on every request.
+-- Statement-level triggers on sets (and on exercises changing catalog
+-- entry) refresh the (user, date) rows a statement touched;
+-- refresh_stat_rollups(null, null) rebuilds everything; the admin
+-- recompute endpoint calls it. Neither table has a foreign key to users:
+-- rows go away with the user's sets.
+
+create table if not exists stats_daily (
+  user_id uuid not null,
+  workout_date date not null,
+  working_sets int not null,
+  tonnage_kg numeric(14,2) not null,
+  -- cardio, duration and distance sets
+  cardio_sets int not null,
+  duration_seconds bigint not null,
+  distance_m numeric(14,2) not null,
+  refreshed_at timestamptz not null default now(),
+  primary key (user_id, workout_date)
+);
+
+create table if not exists stats_exercise_daily (
+  user_id uuid not null,
+  catalog_id uuid not null references exercise_catalog(id) on delete cascade,
+  workout_date date not null,
+  working_sets int not null,
+  tonnage_kg numeric(14,2) not null,
+  -- heaviest strength working set; null when the day had none
+  top_weight_kg numeric(6,2) null,
+  left_sets int not null,
+  right_sets int not null,
+  left_volume_kg numeric(14,2) not null,
+  right_volume_kg numeric(14,2) not null,
+  refreshed_at timestamptz not null default now(),
+  primary key (user_id, catalog_id, workout_date)
+);
+
+create index if not exists stats_exercise_daily_user_date_idx on stats_exercise_daily (user_id, workout_date);
+
+-- refresh_stat_rollups recomputes the rollups of p_user (every user when
+-- null) on p_dates (every date when null). Rows are upserted rather than
+-- replaced so concurrent refreshes of one day don't collide on the key.
+create or replace function refresh_stat_rollups(p_user uuid, p_dates date[])
+returns void as $$
+begin
+  insert into stats_daily as d (user_id, workout_date, working_sets, tonnage_kg, cardio_sets, duration_seconds, distance_m)
+  select s.user_id, s.workout_date,
+         count(*),
+         coalesce(sum(s.volume_kg), 0),
+         count(*) filter (where s.set_type <> 'strength'),
+         coalesce(sum(s.duration_seconds) filter (where s.set_type <> 'strength'), 0),
+         coalesce(sum(s.distance_m) filter (where s.set_type <> 'strength'), 0)
+  from sets s
+  where s.is_warmup = false
+    and (p_user is null or s.user_id = p_user)
+    and (p_dates is null or s.workout_date = any(p_dates))
+  group by s.user_id, s.workout_date
+  on conflict (user_id, workout_date) do update
+  set working_sets = excluded.working_sets,
+      tonnage_kg = excluded.tonnage_kg,
+      cardio_sets = excluded.cardio_sets,
+      duration_seconds = excluded.duration_seconds,
+      distance_m = excluded.distance_m,
+      refreshed_at = now();
+
+  delete from stats_daily d
+  where (p_user is null or d.user_id = p_user)
+    and (p_dates is null or d.workout_date = any(p_dates))
+    and not exists (
+      select 1 from sets s
+      where s.user_id = d.user_id and s.workout_date = d.workout_date and s.is_warmup = false
+    );
+
+  insert into stats_exercise_daily as d (
+    user_id, catalog_id, workout_date, working_sets, tonnage_kg, top_weight_kg,
+    left_sets, right_sets, left_volume_kg, right_volume_kg
+  )
+  select s.user_id, e.catalog_id, s.workout_date,
+         count(*),
+         coalesce(sum(s.volume_kg), 0),
+         max(s.weight_kg) filter (where s.set_type = 'strength'),
+         count(*) filter (where s.side = 'left'),
+         count(*) filter (where s.side = 'right'),
+         coalesce(sum(s.volume_kg) filter (where s.side = 'left'), 0),
+         coalesce(sum(s.volume_kg) filter (where s.side = 'right'), 0)
+  from sets s
+  join exercises e on e.id = s.exercise_id
+  where s.is_warmup = false
+    and (p_user is null or s.user_id = p_user)
+    and (p_dates is null or s.workout_date = any(p_dates))
+  group by s.user_id, e.catalog_id, s.workout_date
+  on conflict (user_id, catalog_id, workout_date) do update
+  set working_sets = excluded.working_sets,
+      tonnage_kg = excluded.tonnage_kg,
+      top_weight_kg = excluded.top_weight_kg,
+      left_sets = excluded.left_sets,
+      right_sets = excluded.right_sets,
+      left_volume_kg = excluded.left_volume_kg,
+      right_volume_kg = excluded.right_volume_kg,
+      refreshed_at = now();
+
+  delete from stats_exercise_daily d
+  where (p_user is null or d.user_id = p_user)
+    and (p_dates is null or d.workout_date = any(p_dates))
+    and not exists (
+      select 1 from sets s join exercises e on e.id = s.exercise_id
+      where s.user_id = d.user_id and s.workout_date = d.workout_date
+        and e.catalog_id = d.catalog_id and s.is_warmup = false
+    );
+end;
+$$ language plpgsql;
+
+create or replace function sets_refresh_stat_rollups()
+returns trigger as $$
+declare
+  r record;
+begin
+  if TG_OP = 'INSERT' then
+    for r in select user_id, array_agg(distinct workout_date) as dates from new_rows group by user_id loop
+      perform refresh_stat_rollups(r.user_id, r.dates);
+    end loop;
+  elsif TG_OP = 'DELETE' then
+    for r in select user_id, array_agg(distinct workout_date) as dates from old_rows group by user_id loop
+      perform refresh_stat_rollups(r.user_id, r.dates);
+    end loop;
+  else
+    for r in
+      select user_id, array_agg(distinct workout_date) as dates
+      from (select user_id, workout_date from new_rows union select user_id, workout_date from old_rows) changed
+      group by user_id
+    loop
+      perform refresh_stat_rollups(r.user_id, r.dates);
+    end loop;
+  end if;
+  return null;
+end;
+$$ language plpgsql;
+
+-- Transition tables allow one event per trigger.
+drop trigger if exists trg_sets_stats_insert on sets;
+create trigger trg_sets_stats_insert
+after insert on sets
+referencing new table as new_rows
+for each statement execute procedure sets_refresh_stat_rollups();
+
+drop trigger if exists trg_sets_stats_update on sets;
+create trigger trg_sets_stats_update
+after update on sets
+referencing old table as old_rows new table as new_rows
+for each statement execute procedure sets_refresh_stat_rollups();
+
+drop trigger if exists trg_sets_stats_delete on sets;
+create trigger trg_sets_stats_delete
+after delete on sets
+referencing old table as old_rows
+for each statement execute procedure sets_refresh_stat_rollups();
+
+-- Re-pointing an exercise at another catalog entry moves its sets between
+-- per-exercise rows without touching the sets themselves.
+create or replace function exercises_refresh_stat_rollups()
+returns trigger as $$
+declare
+  r record;
+begin
+  for r in
+    select s.user_id, array_agg(distinct s.workout_date) as dates
+    from new_rows n
+    join old_rows o on o.id = n.id
+    join sets s on s.exercise_id = n.id
+    where n.catalog_id is distinct from o.catalog_id
+    group by s.user_id
+  loop
+    perform refresh_stat_rollups(r.user_id, r.dates);
+  end loop;
+  return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_exercises_stats_update on exercises;
+create trigger trg_exercises_stats_update
+after update on exercises
+referencing old table as old_rows new table as new_rows
+for each statement execute procedure exercises_refresh_stat_rollups();
+
+select refresh_stat_rollups(null, null);
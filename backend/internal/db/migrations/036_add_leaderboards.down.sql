@@ -0,0 +1,4 @@
+-- 036_add_leaderboards.down.sql
+-- Reverts 036_add_leaderboards.sql
+
+drop table if exists leaderboard_profiles;
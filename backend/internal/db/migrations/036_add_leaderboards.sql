@@ -0,0 +1,16 @@
+-- 036_add_leaderboards.sql
+-- Opt-in relative-strength leaderboards. Only users with opted_in rank; a
+-- missing row means opted out. With hide_identity the user ranks without a
+-- name, and a display name is required before identity can be shown, so an
+-- email address never appears on a board.
+
+create table if not exists leaderboard_profiles (
+  user_id uuid primary key references users(id) on delete cascade,
+  opted_in boolean not null default false,
+  hide_identity boolean not null default true,
+  display_name text null check (display_name is null or char_length(display_name) between 1 and 40),
+  updated_at timestamptz not null default now(),
+  constraint leaderboard_profiles_display_name_required check (hide_identity or display_name is not null)
+);
+
+create index if not exists leaderboard_profiles_opted_in_idx on leaderboard_profiles (user_id) where opted_in;
@@ -0,0 +1,10 @@
+-- 037_add_friends_feed.down.sql
+-- Reverts 037_add_friends_feed.sql
+
+drop table if exists feed_cursor;
+drop function if exists refresh_feed_items(uuid[]);
+drop table if exists feed_items;
+drop index if exists change_journal_tx_idx;
+alter table user_settings drop column if exists day_visibility;
+alter table workout_days drop column if exists visibility;
+drop table if exists friendships;
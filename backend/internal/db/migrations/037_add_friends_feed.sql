@@ -0,0 +1,118 @@
+-- 037_add_friends_feed.sql
+-- Friends and an activity feed. Users send friend requests by email, like
+-- coach invitations; an accepted request makes both users friends.
+--
+-- Days are private unless shared: workout_days.visibility overrides the
+-- owner's user_settings.day_visibility, and only 'friends' days reach the
+-- feed. feed_items holds one summary per workout day with working sets. It
+-- is built from change_journal, which already records every write to days,
+-- exercises and sets: the feed consumes journal entries of transactions
+-- older than feed_cursor.xmin_horizon and re-summarizes the days they
+-- touched. Every transaction below the snapshot xmin has finished, so no
+-- entry can appear behind the cursor later.
+
+create table if not exists friendships (
+  id uuid primary key default gen_random_uuid(),
+  requester_id uuid not null references users(id) on delete cascade,
+  addressee_email citext not null,
+  addressee_id uuid null references users(id) on delete cascade,
+  status text not null default 'pending' check (status in ('pending', 'accepted')),
+  created_at timestamptz not null default now(),
+  accepted_at timestamptz null,
+  unique (requester_id, addressee_email)
+);
+
+create index if not exists friendships_addressee_idx on friendships (addressee_id) where addressee_id is not null;
+create index if not exists friendships_email_idx on friendships (addressee_email);
+
+alter table workout_days
+  add column if not exists visibility text null check (visibility in ('private', 'friends'));
+
+alter table user_settings
+  add column if not exists day_visibility text not null default 'private'
+  check (day_visibility in ('private', 'friends'));
+
+create table if not exists feed_items (
+  day_id uuid primary key references workout_days(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  workout_date date not null,
+  exercises int not null,
+  working_sets int not null,
+  tonnage_kg numeric(14,2) not null,
+  -- [{catalogId, name, weightKg, previousBestKg}] for exercises whose top
+  -- working set beat every earlier day
+  prs jsonb not null default '[]',
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists feed_items_user_date_idx on feed_items (user_id, workout_date desc);
+
+create table if not exists feed_cursor (
+  id boolean primary key default true check (id),
+  xmin_horizon bigint not null
+);
+
+create index if not exists change_journal_tx_idx on change_journal (tx_id);
+
+-- refresh_feed_items re-summarizes the given days, dropping the ones left
+-- without working sets.
+create or replace function refresh_feed_items(p_days uuid[])
+returns void as $$
+begin
+  delete from feed_items f
+  where f.day_id = any(p_days)
+    and not exists (
+      select 1 from sets s join exercises e on e.id = s.exercise_id
+      where e.day_id = f.day_id and s.is_warmup = false
+    );
+
+  insert into feed_items as f (day_id, user_id, workout_date, exercises, working_sets, tonnage_kg, prs)
+  select d.id, d.user_id, d.workout_date, t.exercises, t.working_sets, t.tonnage_kg, coalesce(p.prs, '[]')
+  from workout_days d
+  join lateral (
+    select count(distinct e.id) as exercises,
+           count(*) as working_sets,
+           coalesce(sum(s.volume_kg) filter (where s.set_type = 'strength'), 0) as tonnage_kg
+    from exercises e
+    join sets s on s.exercise_id = e.id and s.is_warmup = false
+    where e.day_id = d.id
+  ) t on t.working_sets > 0
+  left join lateral (
+    select jsonb_agg(jsonb_build_object(
+             'catalogId', x.catalog_id, 'name', x.name, 'weightKg', x.top, 'previousBestKg', x.previous
+           ) order by x.name) as prs
+    from (
+      select e.catalog_id, min(e.name) as name, max(s.weight_kg) as top,
+             (select max(o.weight_kg)
+              from sets o
+              join exercises oe on oe.id = o.exercise_id
+              where o.user_id = d.user_id and oe.catalog_id = e.catalog_id
+                and o.is_warmup = false and o.set_type = 'strength'
+                and o.workout_date < d.workout_date) as previous
+      from exercises e
+      join sets s on s.exercise_id = e.id
+      where e.day_id = d.id and e.catalog_id is not null
+        and s.is_warmup = false and s.set_type = 'strength' and s.weight_kg > 0
+      group by e.catalog_id
+    ) x
+    where x.top > x.previous
+  ) p on true
+  where d.id = any(p_days)
+  on conflict (day_id) do update
+  set workout_date = excluded.workout_date,
+      exercises = excluded.exercises,
+      working_sets = excluded.working_sets,
+      tonnage_kg = excluded.tonnage_kg,
+      prs = excluded.prs,
+      updated_at = now();
+end;
+$$ language plpgsql;
+
+-- Backfill, then start consuming the journal from here.
+select refresh_feed_items(array(
+  select distinct e.day_id from exercises e join sets s on s.exercise_id = e.id where s.is_warmup = false
+));
+
+insert into feed_cursor (xmin_horizon)
+values (txid_snapshot_xmin(txid_current_snapshot()))
+on conflict (id) do nothing;
@@ -0,0 +1,5 @@
+-- 038_add_challenges.down.sql
+-- Reverts 038_add_challenges.sql
+
+drop table if exists challenge_members;
+drop table if exists challenges;
@@ -0,0 +1,30 @@
+-- 038_add_challenges.sql
+-- Time-boxed challenges: a metric (volume, working sets, training days or
+-- longest streak of consecutive training days), optionally limited to one
+-- catalog exercise, over an inclusive date range. Public challenges are
+-- listed to everyone; private ones can be joined by id. Standings are read
+-- from the stat rollups, so they follow logged sets without refreshing.
+
+create table if not exists challenges (
+  id uuid primary key default gen_random_uuid(),
+  creator_id uuid not null references users(id) on delete cascade,
+  name text not null check (char_length(name) between 1 and 80),
+  metric text not null check (metric in ('volume', 'sets', 'days', 'streak')),
+  catalog_id uuid null references exercise_catalog(id) on delete cascade,
+  starts_on date not null,
+  ends_on date not null,
+  is_public boolean not null default true,
+  created_at timestamptz not null default now(),
+  check (ends_on >= starts_on and ends_on - starts_on < 366)
+);
+
+create index if not exists challenges_public_ends_idx on challenges (ends_on) where is_public;
+
+create table if not exists challenge_members (
+  challenge_id uuid not null references challenges(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  joined_at timestamptz not null default now(),
+  primary key (challenge_id, user_id)
+);
+
+create index if not exists challenge_members_user_idx on challenge_members (user_id);
@@ -0,0 +1,5 @@
+-- 039_add_achievements.down.sql
+-- Reverts 039_add_achievements.sql
+
+drop table if exists achievement_webhooks;
+drop table if exists user_achievements;
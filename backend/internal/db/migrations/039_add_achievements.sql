@@ -0,0 +1,20 @@
+-- 039_add_achievements.sql
+-- Unlocked achievements per user, keyed by rule id (the rules live in code
+-- or ACHIEVEMENTS_FILE), and an optional per-user webhook that is POSTed
+-- each unlock, signed with its secret.
+
+create table if not exists user_achievements (
+  user_id uuid not null references users(id) on delete cascade,
+  achievement text not null,
+  -- the metric value that met the rule's threshold
+  value numeric(14,2) not null,
+  unlocked_at timestamptz not null default now(),
+  primary key (user_id, achievement)
+);
+
+create table if not exists achievement_webhooks (
+  user_id uuid primary key references users(id) on delete cascade,
+  url text not null,
+  secret text not null,
+  created_at timestamptz not null default now()
+);
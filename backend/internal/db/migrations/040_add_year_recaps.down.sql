@@ -0,0 +1,4 @@
+-- 040_add_year_recaps.down.sql
+-- Reverts 040_add_year_recaps.sql
+
+drop table if exists year_recaps;
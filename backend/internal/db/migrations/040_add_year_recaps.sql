@@ -0,0 +1,15 @@
+-- 040_add_year_recaps.sql
+-- Cached "Year in Review" summaries, generated from the stat rollups by a
+-- background job once the year is over (or on first request). A recap is
+-- regenerated when the year's rollups change after it was built.
+
+create table if not exists year_recaps (
+  user_id uuid not null references users(id) on delete cascade,
+  year int not null,
+  data jsonb not null,
+  -- training days the recap was built from; a mismatch means days were
+  -- added or removed since
+  training_days int not null,
+  generated_at timestamptz not null default now(),
+  primary key (user_id, year)
+);
@@ -0,0 +1,4 @@
+-- 041_add_day_heart_rate.down.sql
+-- Reverts 041_add_day_heart_rate.sql
+
+drop table if exists day_heart_rate;
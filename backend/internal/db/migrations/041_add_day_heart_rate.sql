@@ -0,0 +1,20 @@
+-- 041_add_day_heart_rate.sql
+-- Heart-rate samples uploaded from a watch export, one series per workout
+-- day. The samples are stored compressed (see internal/biometrics); the
+-- summary columns are kept alongside so day summaries don't decode them.
+
+create table if not exists day_heart_rate (
+  day_id uuid primary key references workout_days(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  samples bytea not null,
+  sample_count int not null,
+  started_at timestamptz not null,
+  ended_at timestamptz not null,
+  avg_bpm numeric(5,1) not null,
+  max_bpm int not null,
+  calories_kcal numeric(7,1) not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists day_heart_rate_user_idx on day_heart_rate (user_id);
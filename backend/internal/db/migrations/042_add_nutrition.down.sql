@@ -0,0 +1,4 @@
+-- 042_add_nutrition.down.sql
+-- Reverts 042_add_nutrition.sql
+
+drop table if exists nutrition_entries;
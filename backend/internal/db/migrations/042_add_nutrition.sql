@@ -0,0 +1,19 @@
+-- 042_add_nutrition.sql
+-- A minimal nutrition log: entries of calories and macros (protein, and
+-- optionally carbs and fat) per day, summed for the daily dashboard next to
+-- training volume from the stat rollups.
+
+create table if not exists nutrition_entries (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  eaten_on date not null,
+  calories int not null check (calories >= 0),
+  protein_g numeric(6,1) not null default 0 check (protein_g >= 0),
+  carbs_g numeric(6,1) null check (carbs_g >= 0),
+  fat_g numeric(6,1) null check (fat_g >= 0),
+  note text null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists nutrition_entries_user_date_idx on nutrition_entries (user_id, eaten_on);
@@ -0,0 +1,4 @@
+-- 043_add_checkins.down.sql
+-- Reverts 043_add_checkins.sql
+
+drop table if exists daily_checkins;
@@ -0,0 +1,15 @@
+-- 043_add_checkins.sql
+-- Daily recovery check-ins: hours slept, soreness and motivation (1-5), one
+-- per user and date, reported next to training load in the analytics.
+
+create table if not exists daily_checkins (
+  user_id uuid not null references users(id) on delete cascade,
+  checkin_date date not null,
+  sleep_hours numeric(3,1) null check (sleep_hours between 0 and 24),
+  soreness smallint null check (soreness between 1 and 5),
+  motivation smallint null check (motivation between 1 and 5),
+  note text null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  primary key (user_id, checkin_date)
+);
@@ -0,0 +1,4 @@
+-- 044_add_goals.down.sql
+-- Reverts 044_add_goals.sql
+
+drop table if exists goals;
@@ -0,0 +1,22 @@
+-- 044_add_goals.sql
+-- Per-exercise goals: a target estimated 1RM, top-set weight or reps (at or
+-- above a weight) for a catalog exercise, optionally by a date. Progress is
+-- computed from the logged sets when goals are read.
+
+create table if not exists goals (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  catalog_id uuid not null references exercise_catalog(id) on delete cascade,
+  metric text not null check (metric in ('e1rm', 'weight', 'reps')),
+  target_value numeric(7,2) not null check (target_value > 0),
+  -- reps goals: the least weight a set needs to count
+  at_weight_kg numeric(6,2) null check (at_weight_kg >= 0),
+  -- e1rm goals: the estimate's equation
+  formula text not null default 'epley',
+  target_date date null,
+  note text null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists goals_user_idx on goals (user_id, created_at);
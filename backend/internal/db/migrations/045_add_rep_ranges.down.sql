@@ -0,0 +1,12 @@
+-- 045_add_rep_ranges.down.sql
+-- Reverts 045_add_rep_ranges.sql
+
+alter table exercises
+  drop constraint if exists exercises_planned_rep_range_check,
+  drop column if exists planned_amrap,
+  drop column if exists planned_reps_max;
+
+alter table program_exercises
+  drop constraint if exists program_exercises_rep_range_check,
+  drop column if exists amrap,
+  drop column if exists target_reps_max;
@@ -0,0 +1,19 @@
+-- 045_add_rep_ranges.sql
+-- Rep ranges and AMRAP on planned targets. The existing reps column becomes
+-- the bottom of the range, with an optional top; amrap marks the last planned
+-- set as many reps as possible, with the reps as its minimum. Scheduled
+-- exercises copy both so adherence can compare them with the logged sets.
+
+alter table program_exercises
+  add column if not exists target_reps_max int null,
+  add column if not exists amrap boolean not null default false;
+
+alter table program_exercises
+  add constraint program_exercises_rep_range_check check (target_reps_max >= target_reps);
+
+alter table exercises
+  add column if not exists planned_reps_max int null,
+  add column if not exists planned_amrap boolean not null default false;
+
+alter table exercises
+  add constraint exercises_planned_rep_range_check check (planned_reps_max >= planned_reps);
@@ -0,0 +1,38 @@
+-- 046_add_rep_annotations.down.sql
+-- Reverts 046_add_rep_annotations.sql: volume goes back to every rep and
+-- set_facts is rebuilt on top of it.
+
+drop materialized view if exists set_facts;
+
+alter table sets alter column volume_kg set expression as (coalesce(effective_load_kg, weight_kg) * reps);
+
+alter table sets
+  drop constraint if exists sets_assisted_reps_check,
+  drop column if exists partial_reps,
+  drop column if exists assisted_reps,
+  drop column if exists to_failure;
+
+create materialized view set_facts as
+select
+  s.id as set_id,
+  s.user_id,
+  d.workout_date,
+  coalesce(ec.slug, lower(e.name)) as exercise_slug,
+  e.name as exercise_name,
+  e.comment as exercise_comment,
+  s.reps,
+  s.weight_kg,
+  s.volume_kg,
+  s.is_warmup,
+  s.performed_at,
+  extract(isodow from d.workout_date) as dow
+from sets s
+join exercises e on e.id = s.exercise_id
+join workout_days d on d.id = e.day_id
+left join exercise_catalog ec on ec.id = e.catalog_id
+with no data;
+
+create index if not exists set_facts_user_date_idx on set_facts (user_id, workout_date);
+create index if not exists set_facts_slug_idx on set_facts (exercise_slug);
+create index if not exists set_facts_workout_date_idx on set_facts (workout_date);
+create index if not exists set_facts_workout_date_brin on set_facts using brin (workout_date);
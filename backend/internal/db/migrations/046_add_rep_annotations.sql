@@ -0,0 +1,43 @@
+-- 046_add_rep_annotations.sql
+-- How a set's reps were done. to_failure marks a set taken to failure;
+-- assisted_reps counts how many of reps a spotter (or band) helped with;
+-- partial_reps are partial-range reps done after the last full rep and are
+-- not part of reps. Volume only counts unassisted full reps, so set_facts is
+-- rebuilt around the new expression as in 017.
+
+alter table sets
+  add column if not exists to_failure boolean not null default false,
+  add column if not exists assisted_reps int not null default 0,
+  add column if not exists partial_reps int not null default 0 check (partial_reps >= 0);
+
+alter table sets
+  add constraint sets_assisted_reps_check check (assisted_reps >= 0 and assisted_reps <= reps);
+
+drop materialized view if exists set_facts;
+
+alter table sets alter column volume_kg set expression as (coalesce(effective_load_kg, weight_kg) * (reps - assisted_reps));
+
+create materialized view set_facts as
+select
+  s.id as set_id,
+  s.user_id,
+  d.workout_date,
+  coalesce(ec.slug, lower(e.name)) as exercise_slug,
+  e.name as exercise_name,
+  e.comment as exercise_comment,
+  s.reps,
+  s.weight_kg,
+  s.volume_kg,
+  s.is_warmup,
+  s.performed_at,
+  extract(isodow from d.workout_date) as dow
+from sets s
+join exercises e on e.id = s.exercise_id
+join workout_days d on d.id = e.day_id
+left join exercise_catalog ec on ec.id = e.catalog_id
+with no data;
+
+create index if not exists set_facts_user_date_idx on set_facts (user_id, workout_date);
+create index if not exists set_facts_slug_idx on set_facts (exercise_slug);
+create index if not exists set_facts_workout_date_idx on set_facts (workout_date);
+create index if not exists set_facts_workout_date_brin on set_facts using brin (workout_date);
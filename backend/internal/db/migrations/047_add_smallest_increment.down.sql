@@ -0,0 +1,4 @@
+-- 047_add_smallest_increment.down.sql
+-- Reverts 047_add_smallest_increment.sql
+
+alter table user_settings drop column if exists smallest_increment;
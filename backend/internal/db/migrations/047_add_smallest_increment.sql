@@ -0,0 +1,7 @@
+-- 047_add_smallest_increment.sql
+-- The smallest total jump a user can load (micro plates, e.g. 1.25 kg or
+-- 2.5 lb), in their unit. Progression suggestions and warmups round to the
+-- bar plus whole steps of it; null falls back to two of the smallest plate.
+
+alter table user_settings
+  add column if not exists smallest_increment numeric(6,3) check (smallest_increment > 0);
@@ -0,0 +1,6 @@
+-- 048_add_set_rir.down.sql
+-- Reverts 048_add_set_rir.sql
+
+alter table sets
+  drop constraint if exists sets_rir_rpe_check,
+  drop column if exists rir;
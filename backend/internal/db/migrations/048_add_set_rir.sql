@@ -0,0 +1,9 @@
+-- 048_add_set_rir.sql
+-- Reps in reserve alongside RPE. Either may be logged; when both are, they
+-- must roughly agree (rpe about 10 - rir, within one).
+
+alter table sets
+  add column if not exists rir int check (rir >= 0 and rir <= 10);
+
+alter table sets
+  add constraint sets_rir_rpe_check check (rpe is null or rir is null or abs(rpe + rir - 10) <= 1);
@@ -0,0 +1,4 @@
+-- 049_add_link_checks.down.sql
+-- Reverts 049_add_link_checks.sql
+
+drop table if exists link_checks;
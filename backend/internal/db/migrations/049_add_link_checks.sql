@@ -0,0 +1,16 @@
+-- 049_add_link_checks.sql
+-- Results of the background dead-link check of catalog links. failures counts
+-- checks in a row that found the link gone (reset by a good check), and
+-- failing_since is when that run started; a link is reported dead once
+-- failures reaches store.DeadLinkFailures.
+
+create table if not exists link_checks (
+  url text primary key,
+  status_code int null,
+  error text null,
+  failures int not null default 0 check (failures >= 0),
+  failing_since timestamptz null,
+  checked_at timestamptz not null default now()
+);
+
+create index if not exists link_checks_checked_at_idx on link_checks (checked_at);
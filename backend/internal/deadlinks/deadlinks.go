@@ -0,0 +1,168 @@
+// Package deadlinks checks catalog link URLs in the background through the
+// jobs queue and records the ones that stop resolving, so admins can clean up
+// the library.
+package deadlinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"exercise-tracker/internal/jobs"
+	"exercise-tracker/internal/linkmeta"
+	"exercise-tracker/internal/store"
+)
+
+// JobCheck runs one pass over the links that are due.
+const JobCheck = "deadlinks.check"
+
+const (
+	scanEvery      = time.Hour
+	batchSize      = 100
+	requestTimeout = 15 * time.Second
+)
+
+// Store is the persistence the checker needs; *store.Catalog implements it.
+type Store interface {
+	DueLinkChecks(ctx context.Context, checkedBefore time.Time, limit int) ([]string, error)
+	SaveLinkCheck(ctx context.Context, c store.LinkCheck) error
+}
+
+type Checker struct {
+	Store Store
+	Queue *jobs.Queue
+	// Interval is how long a link waits after a check before the next one.
+	Interval time.Duration
+	// Client defaults to one that only connects to public addresses.
+	Client *http.Client
+}
+
+// Register adds the job handler to the queue. Call before the queue runs.
+func (c *Checker) Register() {
+	if c.Client == nil {
+		c.Client = newClient()
+	}
+	c.Queue.Handle(JobCheck, c.check)
+}
+
+// Run enqueues a check pass once an hour until ctx is done. Each pass only
+// checks the links that are due, so most are no-ops.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(scanEvery)
+	defer ticker.Stop()
+	for {
+		now := time.Now().UTC()
+		if _, err := c.Queue.Enqueue(ctx, JobCheck, struct{}{}, jobs.EnqueueOptions{
+			DedupeKey:   "deadlinks:" + now.Truncate(scanEvery).Format(time.RFC3339),
+			MaxAttempts: 3,
+		}); err != nil && ctx.Err() == nil {
+			log.Printf("dead link schedule error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) check(ctx context.Context, _ jobs.Job) error {
+	before := time.Now().Add(-c.Interval)
+	for {
+		urls, err := c.Store.DueLinkChecks(ctx, before, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, u := range urls {
+			res := c.Check(ctx, u)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := c.Store.SaveLinkCheck(ctx, res); err != nil {
+				return err
+			}
+		}
+		if len(urls) < batchSize {
+			return nil
+		}
+	}
+}
+
+// Check requests rawURL, with HEAD and then GET for servers that don't
+// support HEAD, and reports whether the link is dead.
+func (c *Checker) Check(ctx context.Context, rawURL string) store.LinkCheck {
+	out := store.LinkCheck{URL: rawURL}
+	fail := func(err error) store.LinkCheck {
+		msg := err.Error()
+		out.Dead, out.Error = true, &msg
+		return out
+	}
+	if _, err := linkmeta.ValidateURL(rawURL); err != nil {
+		return fail(err)
+	}
+	status, err := c.status(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.status(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	out.StatusCode = &status
+	if Dead(status) {
+		return fail(fmt.Errorf("status %d", status))
+	}
+	return out
+}
+
+// Dead reports whether a response status means the link is gone. Pages that
+// refuse or throttle the checker (401, 403, 429) still exist.
+func Dead(status int) bool {
+	switch {
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return true
+	case status >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Checker) status(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "FitLog link checker")
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	return res.StatusCode, nil
+}
+
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: requestTimeout, Control: linkmeta.PublicOnly}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   requestTimeout,
+			ResponseHeaderTimeout: requestTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       time.Minute,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return nil
+		},
+	}
+}
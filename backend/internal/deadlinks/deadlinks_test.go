@@ -0,0 +1,34 @@
+package deadlinks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/private":
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+	c := &Checker{Client: srv.Client()}
+	for path, dead := range map[string]bool{"/ok": false, "/gone": true, "/get-only": false, "/private": false} {
+		res := c.Check(context.Background(), srv.URL+path)
+		if res.Dead != dead {
+			t.Errorf("%s: dead = %v, want %v (%+v)", path, res.Dead, dead, res)
+		}
+	}
+	if res := c.Check(context.Background(), "ftp://example.com/x"); !res.Dead || res.Error == nil {
+		t.Fatalf("invalid URL should be dead with an error, got %+v", res)
+	}
+}
@@ -0,0 +1,309 @@
+// Package freeexercisedb imports the exercise library published by
+// yuhonas/free-exercise-db: it maps the dataset's JSON schema onto catalog
+// entries and downloads each exercise's image into the blob store.
+package freeexercisedb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"exercise-tracker/internal/jobs"
+	"exercise-tracker/internal/linkmeta"
+	"exercise-tracker/internal/store"
+)
+
+// JobImages downloads the images of one import.
+const JobImages = "freeexercisedb.images"
+
+// DefaultImageBase is where the dataset's relative image paths are served.
+const DefaultImageBase = "https://raw.githubusercontent.com/yuhonas/free-exercise-db/main/exercises/"
+
+const (
+	maxImageBytes  = 5 << 20
+	requestTimeout = 30 * time.Second
+)
+
+// Exercise is one record of exercises.json.
+type Exercise struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Force            *string  `json:"force"`
+	Level            string   `json:"level"`
+	Mechanic         *string  `json:"mechanic"`
+	Equipment        *string  `json:"equipment"`
+	PrimaryMuscles   []string `json:"primaryMuscles"`
+	SecondaryMuscles []string `json:"secondaryMuscles"`
+	Instructions     []string `json:"instructions"`
+	Category         string   `json:"category"`
+	// Images are paths relative to the dataset's exercises/ directory.
+	Images []string `json:"images"`
+}
+
+// categories maps the dataset's categories onto catalog types.
+var categories = map[string]string{
+	"strength":              "Strength",
+	"stretching":            "Stretching",
+	"plyometrics":           "Plyometrics",
+	"powerlifting":          "Powerlifting",
+	"olympic weightlifting": "Olympic Weightlifting",
+	"strongman":             "Strongman",
+	"cardio":                "Cardio",
+}
+
+// Parse decodes exercises.json, either the combined array or a single
+// exercise file.
+func Parse(r io.Reader) ([]Exercise, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var out []Exercise
+	if err := json.Unmarshal(data, &out); err != nil {
+		var single Exercise
+		if errSingle := json.Unmarshal(data, &single); errSingle != nil {
+			return nil, err
+		}
+		out = append(out, single)
+	}
+	return out, nil
+}
+
+// Entry maps e onto a catalog entry. Categories, muscles, equipment and
+// levels are lower case in the dataset and title-cased here to match the
+// catalog's facets; a missing equipment is "Other" and a muscle group becomes
+// the body part. Unknown categories are title-cased and left to the facet
+// normalizer.
+func (e Exercise) Entry() (store.CatalogEntry, error) {
+	name := strings.TrimSpace(e.Name)
+	if name == "" {
+		return store.CatalogEntry{}, errors.New("name is required")
+	}
+	if len(e.PrimaryMuscles) == 0 {
+		return store.CatalogEntry{}, fmt.Errorf("%s: primaryMuscles is required", name)
+	}
+	typ, ok := categories[strings.ToLower(strings.TrimSpace(e.Category))]
+	if !ok {
+		typ = titleCase(e.Category)
+	}
+	equipment := "Other"
+	if e.Equipment != nil && strings.TrimSpace(*e.Equipment) != "" {
+		equipment = titleCase(*e.Equipment)
+	}
+	return store.CatalogEntry{
+		Name:             name,
+		Type:             typ,
+		BodyPart:         titleCase(e.PrimaryMuscles[0]),
+		Equipment:        equipment,
+		Level:            titleCase(e.Level),
+		PrimaryMuscles:   titleCaseAll(e.PrimaryMuscles),
+		SecondaryMuscles: titleCaseAll(e.SecondaryMuscles),
+		Instructions:     e.Instructions,
+	}, nil
+}
+
+// ImageURL returns the URL of the exercise's first image under base, or ""
+// when it has none.
+func (e Exercise) ImageURL(base string) string {
+	if len(e.Images) == 0 || strings.TrimSpace(e.Images[0]) == "" {
+		return ""
+	}
+	if base == "" {
+		base = DefaultImageBase
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(strings.TrimSpace(e.Images[0]), "/")
+}
+
+// titleCase capitalizes each word, including after hyphens, so "e-z curl
+// bar" becomes "E-Z Curl Bar".
+func titleCase(s string) string {
+	b := []byte(strings.ToLower(strings.Join(strings.Fields(s), " ")))
+	for i := range b {
+		if (i == 0 || b[i-1] == ' ' || b[i-1] == '-') && b[i] >= 'a' && b[i] <= 'z' {
+			b[i] -= 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+func titleCaseAll(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s = titleCase(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ImageStore is the persistence image downloads need; *store.Catalog
+// implements it.
+type ImageStore interface {
+	CatalogIDsWithoutImage(ctx context.Context, names []string) (map[string]string, error)
+	PutCatalogImage(ctx context.Context, id string, data []byte, mimeType string) error
+}
+
+// ImageFailure is an image that couldn't be downloaded or stored.
+type ImageFailure struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// ImageResult summarizes a download pass.
+type ImageResult struct {
+	Downloaded int            `json:"downloaded"`
+	Skipped    int            `json:"skipped"`
+	Failures   []ImageFailure `json:"failures"`
+}
+
+type Downloader struct {
+	Store ImageStore
+	// Queue runs downloads enqueued by Enqueue; only needed for that.
+	Queue *jobs.Queue
+	// Base is prepended to the dataset's image paths; DefaultImageBase when
+	// empty.
+	Base string
+	// Client defaults to one that only connects to public addresses.
+	Client *http.Client
+}
+
+// Register adds the job handler to the queue. Call before the queue runs.
+func (d *Downloader) Register() {
+	if d.Client == nil {
+		d.Client = newClient()
+	}
+	d.Queue.Handle(JobImages, d.run)
+}
+
+// imagesPayload is the part of each exercise a download job needs.
+type imagesPayload struct {
+	Exercises []Exercise `json:"exercises"`
+}
+
+// Enqueue schedules Images for exercises on the job queue, for imports that
+// can't wait for every image to download.
+func (d *Downloader) Enqueue(ctx context.Context, exercises []Exercise) error {
+	var p imagesPayload
+	for _, e := range exercises {
+		if len(e.Images) > 0 {
+			p.Exercises = append(p.Exercises, Exercise{Name: e.Name, Images: e.Images[:1]})
+		}
+	}
+	if len(p.Exercises) == 0 {
+		return nil
+	}
+	_, err := d.Queue.Enqueue(ctx, JobImages, p, jobs.EnqueueOptions{MaxAttempts: 3})
+	return err
+}
+
+func (d *Downloader) run(ctx context.Context, job jobs.Job) error {
+	var p imagesPayload
+	if err := job.Decode(&p); err != nil {
+		return err
+	}
+	res, err := d.Images(ctx, p.Exercises)
+	if err != nil {
+		return err
+	}
+	for _, f := range res.Failures {
+		log.Printf("free-exercise-db image error name=%q url=%s: %s", f.Name, f.URL, f.Reason)
+	}
+	log.Printf("free-exercise-db images: %d downloaded, %d skipped, %d failed", res.Downloaded, res.Skipped, len(res.Failures))
+	return nil
+}
+
+// Images downloads the first image of every exercise whose catalog entry has
+// none yet, so re-running an import doesn't fetch images again or replace
+// ones uploaded by hand. A failed image is reported and the rest continue.
+func (d *Downloader) Images(ctx context.Context, exercises []Exercise) (ImageResult, error) {
+	res := ImageResult{Failures: []ImageFailure{}}
+	if d.Client == nil {
+		d.Client = newClient()
+	}
+	names := make([]string, 0, len(exercises))
+	for _, e := range exercises {
+		names = append(names, strings.TrimSpace(e.Name))
+	}
+	missing, err := d.Store.CatalogIDsWithoutImage(ctx, names)
+	if err != nil {
+		return res, err
+	}
+	for _, e := range exercises {
+		name := strings.TrimSpace(e.Name)
+		id, ok := missing[name]
+		imageURL := e.ImageURL(d.Base)
+		if !ok || imageURL == "" {
+			res.Skipped++
+			continue
+		}
+		delete(missing, name)
+		data, mimeType, err := d.fetch(ctx, imageURL)
+		if err == nil {
+			err = d.Store.PutCatalogImage(ctx, id, data, mimeType)
+		}
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		if err != nil {
+			res.Failures = append(res.Failures, ImageFailure{Name: name, URL: imageURL, Reason: err.Error()})
+			continue
+		}
+		res.Downloaded++
+	}
+	return res, nil
+}
+
+func (d *Downloader) fetch(ctx context.Context, rawURL string) ([]byte, string, error) {
+	if _, err := linkmeta.ValidateURL(rawURL); err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "FitLog catalog importer")
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("status %d", res.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageBytes {
+		return nil, "", errors.New("image is too large")
+	}
+	mimeType := http.DetectContentType(data)
+	switch mimeType {
+	case "image/jpeg", "image/png":
+		return data, mimeType, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported image type %s", mimeType)
+	}
+}
+
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: requestTimeout, Control: linkmeta.PublicOnly}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   requestTimeout,
+			ResponseHeaderTimeout: requestTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       time.Minute,
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package freeexercisedb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `[
+  {"id": "3_4_Sit-Up", "name": "3/4 Sit-Up", "force": "pull", "level": "beginner", "mechanic": "compound",
+   "equipment": "body only", "primaryMuscles": ["abdominals"], "secondaryMuscles": [],
+   "instructions": ["Lie down on the floor.", "Flex your hips and spine."], "category": "strength",
+   "images": ["3_4_Sit-Up/0.jpg", "3_4_Sit-Up/1.jpg"]},
+  {"id": "EZ-Bar_Curl", "name": "EZ-Bar Curl", "force": "pull", "level": "intermediate", "mechanic": "isolation",
+   "equipment": "e-z curl bar", "primaryMuscles": ["biceps"], "secondaryMuscles": ["forearms", "middle back"],
+   "instructions": [], "category": "olympic weightlifting", "images": []},
+  {"id": "Ankle_Circles", "name": "Ankle Circles", "force": null, "level": "beginner", "mechanic": null,
+   "equipment": null, "primaryMuscles": ["calves"], "secondaryMuscles": [], "instructions": [],
+   "category": "stretching", "images": ["Ankle_Circles/0.jpg"]}
+]`
+
+func TestEntry(t *testing.T) {
+	exercises, err := Parse(strings.NewReader(sample))
+	if err != nil || len(exercises) != 3 {
+		t.Fatalf("parse: %d exercises, %v", len(exercises), err)
+	}
+	curl, err := exercises[1].Entry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if curl.Type != "Olympic Weightlifting" || curl.BodyPart != "Biceps" || curl.Equipment != "E-Z Curl Bar" || curl.Level != "Intermediate" {
+		t.Fatalf("facets %+v", curl)
+	}
+	if !reflect.DeepEqual(curl.SecondaryMuscles, []string{"Forearms", "Middle Back"}) {
+		t.Fatalf("secondary muscles %v", curl.SecondaryMuscles)
+	}
+	circles, _ := exercises[2].Entry()
+	if circles.Equipment != "Other" {
+		t.Fatalf("null equipment mapped to %q", circles.Equipment)
+	}
+	if got := exercises[0].ImageURL(""); got != DefaultImageBase+"3_4_Sit-Up/0.jpg" {
+		t.Fatalf("image url %q", got)
+	}
+	if exercises[1].ImageURL("") != "" {
+		t.Fatal("exercise without images has an image url")
+	}
+	if _, err := (Exercise{Name: "No Muscles"}).Entry(); err == nil {
+		t.Fatal("expected an error without primary muscles")
+	}
+}
+
+type fakeImages struct {
+	missing map[string]string
+	put     map[string]string
+}
+
+func (f *fakeImages) CatalogIDsWithoutImage(_ context.Context, names []string) (map[string]string, error) {
+	return f.missing, nil
+}
+
+func (f *fakeImages) PutCatalogImage(_ context.Context, id string, _ []byte, mimeType string) error {
+	f.put[id] = mimeType
+	return nil
+}
+
+func TestImages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/3_4_Sit-Up/0.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"))
+	}))
+	defer srv.Close()
+	exercises, _ := Parse(strings.NewReader(sample))
+	// EZ-Bar Curl already has an image.
+	store := &fakeImages{missing: map[string]string{"3/4 Sit-Up": "a", "Ankle Circles": "c"}, put: map[string]string{}}
+	d := &Downloader{Store: store, Base: srv.URL, Client: srv.Client()}
+	res, err := d.Images(context.Background(), exercises)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Downloaded != 1 || res.Skipped != 1 || len(res.Failures) != 1 || res.Failures[0].Name != "Ankle Circles" {
+		t.Fatalf("result %+v", res)
+	}
+	if store.put["a"] != "image/jpeg" {
+		t.Fatalf("stored %v", store.put)
+	}
+}
@@ -0,0 +1,401 @@
+// Package grpcapi serves catalog and workout data over gRPC alongside the
+// REST API. Messages are google.protobuf.Struct values with the REST JSON
+// shapes; see api/proto/fitlog/v1/fitlog.proto.
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"exercise-tracker/internal/achievements"
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/realtime"
+	"exercise-tracker/internal/store"
+)
+
+// APIKeyVerifier resolves a hashed API key to its owner and scope.
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, hash string) (userID, scope string, ok bool, err error)
+}
+
+// SessionVerifier reports whether a session is still active.
+type SessionVerifier interface {
+	Touch(ctx context.Context, userID, sessionID string) (bool, error)
+}
+
+type Server struct {
+	Catalog *store.Catalog
+	Days    *store.Days
+	Save    *store.Save
+	// SaveLimits are the REST save limits, applied to Days/Save too.
+	SaveLimits store.SaveLimits
+	// Sets, Hub and Achievements get the same realtime events and
+	// achievement evaluation as REST saves; each may be nil.
+	Sets         *store.Sets
+	Hub          *realtime.Hub
+	Achievements *achievements.Engine
+	JWTSecret    string
+	APIKeys      APIKeyVerifier
+	// Sessions rejects tokens of revoked sessions; nil trusts the JWT alone.
+	Sessions SessionVerifier
+	// Maintenance, when on, makes write methods fail with Unavailable.
+	Maintenance *middleware.Maintenance
+}
+
+// writeMethods need a session or a write-scoped API key.
+var writeMethods = map[string]bool{
+	"/fitlog.v1.Days/Save": true,
+}
+
+// NewGRPCServer returns a grpc.Server with authentication and both services
+// registered.
+func (s *Server) NewGRPCServer() *grpc.Server {
+	g := grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+	g.RegisterService(&catalogServiceDesc, s)
+	g.RegisterService(&daysServiceDesc, s)
+	return g
+}
+
+type userIDKey struct{}
+
+func userID(ctx context.Context) string {
+	v, _ := ctx.Value(userIDKey{}).(string)
+	return v
+}
+
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if v := md.Get("authorization"); len(v) > 0 {
+		if len(v[0]) > 7 && strings.EqualFold(v[0][:7], "bearer ") {
+			token = strings.TrimSpace(v[0][7:])
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if auth.IsAPIKey(token) {
+		if s.APIKeys == nil {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+		uid, scope, ok, err := s.APIKeys.Verify(ctx, auth.HashAPIKey(token))
+		if err != nil {
+			log.Printf("grpc api key verify error: %v", err)
+			return nil, status.Error(codes.Internal, "server error")
+		}
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+		if writeMethods[method] && scope != auth.APIScopeWrite {
+			return nil, status.Error(codes.PermissionDenied, "api key is read-only")
+		}
+		return context.WithValue(ctx, userIDKey{}, uid), nil
+	}
+	claims, err := auth.ParseToken(s.JWTSecret, token)
+	if err != nil || claims == nil || claims.UserID == "" {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	if claims.SessionID != "" && s.Sessions != nil {
+		active, err := s.Sessions.Touch(ctx, claims.UserID, claims.SessionID)
+		if err != nil {
+			log.Printf("grpc session verify error: %v", err)
+			return nil, status.Error(codes.Internal, "server error")
+		}
+		if !active {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+	}
+	return context.WithValue(ctx, userIDKey{}, claims.UserID), nil
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if writeMethods[info.FullMethod] && s.Maintenance != nil {
+		if st := s.Maintenance.State(); st.Enabled {
+			return nil, status.Error(codes.Unavailable, "maintenance: "+st.Reason)
+		}
+	}
+	return handler(ctx, req)
+}
+
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a authedStream) Context() context.Context { return a.ctx }
+
+func (s *Server) streamAuth(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, authedStream{ServerStream: ss, ctx: ctx})
+}
+
+// decodeStruct copies a Struct request into dst through its JSON form.
+func decodeStruct(in *structpb.Struct, dst any) error {
+	b, err := in.MarshalJSON()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	return nil
+}
+
+// encodeStruct converts v to a Struct through its JSON form.
+func encodeStruct(v any) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "server error")
+	}
+	out := &structpb.Struct{}
+	if err := out.UnmarshalJSON(b); err != nil {
+		return nil, status.Error(codes.Internal, "server error")
+	}
+	return out, nil
+}
+
+func internalError(op string, err error) error {
+	log.Printf("grpc %s error: %v", op, err)
+	return status.Error(codes.Internal, "server error")
+}
+
+// Catalog service
+
+var catalogServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fitlog.v1.Catalog",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Get", Handler: unary("/fitlog.v1.Catalog/Get", (*Server).catalogGet)},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Search", Handler: catalogSearchStream, ServerStreams: true},
+	},
+	Metadata: "fitlog/v1/fitlog.proto",
+}
+
+type catalogSearchRequest struct {
+	Q         string   `json:"q"`
+	Types     []string `json:"types"`
+	BodyParts []string `json:"bodyParts"`
+	Equipment []string `json:"equipment"`
+	Levels    []string `json:"levels"`
+	Muscles   []string `json:"muscles"`
+	Sort      string   `json:"sort"`
+	Page      int      `json:"page"`
+	PageSize  int      `json:"pageSize"`
+}
+
+func catalogSearchStream(srv any, stream grpc.ServerStream) error {
+	s := srv.(*Server)
+	in := &structpb.Struct{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	var req catalogSearchRequest
+	if err := decodeStruct(in, &req); err != nil {
+		return err
+	}
+	res, err := s.Catalog.Search(stream.Context(), store.CatalogSearchParams{
+		Q:         req.Q,
+		Types:     req.Types,
+		BodyParts: req.BodyParts,
+		Equipment: req.Equipment,
+		Levels:    req.Levels,
+		Muscles:   req.Muscles,
+		Sort:      req.Sort,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		ViewerID:  userID(stream.Context()),
+	})
+	if err != nil {
+		return internalError("catalog search", err)
+	}
+	for _, it := range res.Items {
+		msg, err := encodeStruct(it)
+		if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) catalogGet(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := decodeStruct(in, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ID) == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	rec, err := s.Catalog.GetCatalogEntry(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		return nil, internalError("catalog get", err)
+	}
+	if !rec.VisibleTo(userID(ctx)) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return encodeStruct(rec)
+}
+
+// Days service
+
+var daysServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fitlog.v1.Days",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Get", Handler: unary("/fitlog.v1.Days/Get", (*Server).dayGet)},
+		{MethodName: "Save", Handler: unary("/fitlog.v1.Days/Save", (*Server).daySave)},
+	},
+	Metadata: "fitlog/v1/fitlog.proto",
+}
+
+func (s *Server) dayGet(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	uid := userID(ctx)
+	var req struct {
+		ID   string `json:"id"`
+		Date string `json:"date"`
+	}
+	if err := decodeStruct(in, &req); err != nil {
+		return nil, err
+	}
+	dayID := req.ID
+	if dayID == "" {
+		dt, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "id or date (YYYY-MM-DD) is required")
+		}
+		day, err := s.Days.GetByUserAndDate(ctx, uid, dt)
+		if err != nil {
+			return nil, internalError("day get", err)
+		}
+		if day == nil {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		dayID = day.ID
+	}
+	detail, err := s.Days.GetWithDetails(ctx, uid, dayID)
+	if err != nil {
+		return nil, internalError("day get", err)
+	}
+	if detail == nil {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return encodeStruct(detail)
+}
+
+func (s *Server) daySave(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	uid := userID(ctx)
+	var req struct {
+		IdempotencyKey string            `json:"idempotencyKey"`
+		Ops            []json.RawMessage `json:"ops"`
+	}
+	if err := decodeStruct(in, &req); err != nil {
+		return nil, err
+	}
+	if err := s.SaveLimits.Check(req.IdempotencyKey, req.Ops); err != nil {
+		var le *store.SaveLimitError
+		if errors.As(err, &le) && le.Code == store.SaveLimitTooManyOps {
+			return nil, status.Error(codes.ResourceExhausted, le.Message)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	mapping, updatedAt, err := s.Save.ProcessBatch(ctx, uid, req.Ops, req.IdempotencyKey)
+	if qe, ok := store.AsQuotaError(err); ok {
+		return nil, status.Error(codes.ResourceExhausted, qe.Error())
+	}
+	if err != nil {
+		if !store.IsSaveInputError(err) {
+			return nil, internalError("save batch", err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	epoch := s.afterSave(ctx, uid, mapping)
+	return encodeStruct(map[string]any{
+		"mapping":     mapping,
+		"updatedAt":   updatedAt,
+		"serverEpoch": epoch,
+	})
+}
+
+// afterSave bumps the user's save epoch, publishes the realtime events and
+// queues achievement evaluation like the REST save handler, returning the new
+// epoch.
+func (s *Server) afterSave(ctx context.Context, uid string, mapping store.SaveMapping) int64 {
+	epoch := time.Now().UnixMilli()
+	if err := s.Save.SetEpoch(ctx, uid, epoch); err != nil {
+		log.Printf("grpc save epoch update error: %v", err)
+	}
+	if s.Hub != nil {
+		store.AfterCommit(ctx, func() {
+			s.Hub.Publish(uid, realtime.Event{Type: realtime.EventSaveEpoch, Data: map[string]int64{"serverEpoch": epoch}})
+		})
+	}
+	if len(mapping.Sets) == 0 {
+		return epoch
+	}
+	if s.Sets != nil && s.Hub != nil {
+		ids := make([]string, 0, len(mapping.Sets))
+		for _, m := range mapping.Sets {
+			ids = append(ids, m.ID)
+		}
+		prs, err := s.Sets.DetectPersonalRecords(ctx, uid, ids)
+		if err != nil {
+			log.Printf("grpc personal record detection error: %v", err)
+		} else {
+			store.AfterCommit(ctx, func() {
+				for _, pr := range prs {
+					s.Hub.Publish(uid, realtime.Event{Type: realtime.EventPersonalRecord, Data: pr})
+				}
+			})
+		}
+	}
+	s.Achievements.Enqueue(ctx, uid)
+	return epoch
+}
+
+// unary adapts a typed Struct -> Struct method to a grpc.MethodDesc handler.
+func unary(fullMethod string, fn func(*Server, context.Context, *structpb.Struct) (*structpb.Struct, error)) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := &structpb.Struct{}
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		call := func(ctx context.Context, req any) (any, error) {
+			return fn(srv.(*Server), ctx, req.(*structpb.Struct))
+		}
+		if interceptor == nil {
+			return call(ctx, in)
+		}
+		return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, call)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"exercise-tracker/internal/achievements"
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/linkmeta"
+)
+
+type AchievementsHandler struct {
+	Achievements AchievementsStore
+	Rules        []achievements.Rule
+}
+
+type achievementWebhookRequest struct {
+	URL string `json:"url"`
+}
+
+// List returns every achievement with the caller's progress towards it.
+func (h *AchievementsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	progress, err := h.Achievements.Progress(r.Context(), uid, achievements.Slugs(h.Rules))
+	if err != nil {
+		log.Printf("achievement progress error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	unlocked, err := h.Achievements.Unlocked(r.Context(), uid)
+	if err != nil {
+		log.Printf("list achievements error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": achievements.Statuses(h.Rules, progress, unlocked)})
+}
+
+// Webhook returns the caller's unlock webhook, without its secret.
+func (h *AchievementsHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	hook, err := h.Achievements.Webhook(r.Context(), uid)
+	if err != nil {
+		log.Printf("get achievement webhook error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if hook == nil {
+		http.NotFound(w, r)
+		return
+	}
+	hook.Secret = ""
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// SetWebhook points the caller's webhook at a URL and returns it with a new
+// signing secret.
+func (h *AchievementsHandler) SetWebhook(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req achievementWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	url, err := linkmeta.ValidateURL(req.URL)
+	if err != nil || len(url) > 2048 {
+		http.Error(w, "url must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+	hook, err := h.Achievements.SetWebhook(r.Context(), uid, url)
+	if err != nil {
+		log.Printf("set achievement webhook error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// DeleteWebhook removes the caller's webhook.
+func (h *AchievementsHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Achievements.DeleteWebhook(r.Context(), uid)
+	if err != nil {
+		log.Printf("delete achievement webhook error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/store"
+)
+
+func TestAchievementsSetWebhook(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "invalid json", body: `{`, status: http.StatusBadRequest},
+		{name: "missing url", body: `{}`, status: http.StatusBadRequest},
+		{name: "not http", body: `{"url":"ftp://example.com/hook"}`, status: http.StatusBadRequest},
+		{name: "saved", body: `{"url":" https://example.com/hook "}`, status: http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			achievements := &mocks.AchievementsStore{
+				SetWebhookFunc: func(_ context.Context, userID, url string) (*store.AchievementWebhook, error) {
+					got = url
+					return &store.AchievementWebhook{URL: url, Secret: "whsec_x"}, nil
+				},
+			}
+			h := &handlers.AchievementsHandler{Achievements: achievements}
+			w := httptest.NewRecorder()
+			h.SetWebhook(w, newRequest(http.MethodPut, "/api/achievements/webhook", tc.body, "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+			if tc.status == http.StatusOK && got != "https://example.com/hook" {
+				t.Fatalf("store got url %q", got)
+			}
+		})
+	}
+}
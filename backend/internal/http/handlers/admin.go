@@ -1,22 +1,81 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"exercise-tracker/internal/catalognorm"
+	"exercise-tracker/internal/freeexercisedb"
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/linkmeta"
 	"exercise-tracker/internal/store"
 )
 
 type AdminHandler struct {
-	Users       *store.Users
-	Catalog     *store.Catalog
+	Users       UsersStore
+	Catalog     CatalogStore
+	Audit       AuditStore
 	AdminEmails map[string]struct{}
+	// Links, when set, fetches metadata for links of imported entries.
+	Links *linkmeta.Fetcher
+	// Maintenance is the switch behind /api/admin/maintenance.
+	Maintenance *middleware.Maintenance
+	Usage       UsageStore
+	// Analytics backs the stats rollup recompute.
+	Analytics AnalyticsStore
+	// Normalize canonicalizes facet values of imported entries.
+	Normalize catalognorm.Rules
+	// Images, when set, downloads the images of free-exercise-db imports.
+	Images *freeexercisedb.Downloader
+}
+
+// normalizer starts normalizing one import against the catalog's current
+// facet values.
+func (h *AdminHandler) normalizer(r *http.Request) (*catalognorm.Normalizer, error) {
+	f, err := h.Catalog.Facets(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return catalognorm.New(h.Normalize, map[string][]string{
+		catalognorm.FieldType:      f.Types,
+		catalognorm.FieldBodyPart:  f.BodyParts,
+		catalognorm.FieldEquipment: f.Equipment,
+		catalognorm.FieldLevel:     f.Levels,
+	}), nil
+}
+
+// requireAdmin writes 401/403 and returns false unless the caller's email is
+// listed in ADMIN_EMAILS.
+func (h *AdminHandler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	u, err := h.Users.ByID(r.Context(), uid)
+	if err != nil {
+		log.Printf("admin lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return false
+	}
+	if u == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if _, ok := h.AdminEmails[strings.ToLower(u.Email)]; !ok {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
 }
 
 type catalogPayload struct {
@@ -31,6 +90,35 @@ type catalogPayload struct {
 	Links            []string `json:"links"`
 	Multiplier       *float64 `json:"multiplier"`
 	BaseWeightKg     *float64 `json:"baseWeightKg"`
+	Instructions     []string `json:"instructions"`
+	Cues             []string `json:"cues"`
+	CommonMistakes   []string `json:"commonMistakes"`
+}
+
+// Limits on the structured coaching text of a catalog entry.
+const (
+	maxCatalogTextItems = 30
+	maxCatalogTextLen   = 500
+)
+
+// textList trims values and drops blanks, keeping order and repeats, and
+// rejects lists over the coaching text limits.
+func textList(field string, values []string) ([]string, error) {
+	var out []string
+	for _, v := range values {
+		trimmed := strings.TrimSpace(v)
+		if trimmed == "" {
+			continue
+		}
+		if len([]rune(trimmed)) > maxCatalogTextLen {
+			return nil, fmt.Errorf("%s items must be at most %d characters", field, maxCatalogTextLen)
+		}
+		out = append(out, trimmed)
+	}
+	if len(out) > maxCatalogTextItems {
+		return nil, fmt.Errorf("%s allows at most %d items", field, maxCatalogTextItems)
+	}
+	return out, nil
 }
 
 func (p catalogPayload) toCatalogEntry() (store.CatalogEntry, error) {
@@ -58,6 +146,26 @@ func (p catalogPayload) toCatalogEntry() (store.CatalogEntry, error) {
 	if len(primaryMuscles) == 0 {
 		return store.CatalogEntry{}, errors.New("primaryMuscles is required")
 	}
+	links := sanitizeList(p.Links)
+	for i, link := range links {
+		valid, err := linkmeta.ValidateURL(link)
+		if err != nil {
+			return store.CatalogEntry{}, err
+		}
+		links[i] = valid
+	}
+	instructions, err := textList("instructions", p.Instructions)
+	if err != nil {
+		return store.CatalogEntry{}, err
+	}
+	cues, err := textList("cues", p.Cues)
+	if err != nil {
+		return store.CatalogEntry{}, err
+	}
+	mistakes, err := textList("commonMistakes", p.CommonMistakes)
+	if err != nil {
+		return store.CatalogEntry{}, err
+	}
 	entry := store.CatalogEntry{
 		Name:             name,
 		Description:      trimStringPtr(p.Description),
@@ -67,9 +175,12 @@ func (p catalogPayload) toCatalogEntry() (store.CatalogEntry, error) {
 		Level:            level,
 		PrimaryMuscles:   primaryMuscles,
 		SecondaryMuscles: sanitizeList(p.SecondaryMuscles),
-		Links:            sanitizeList(p.Links),
+		Links:            links,
 		Multiplier:       p.Multiplier,
 		BaseWeightKg:     p.BaseWeightKg,
+		Instructions:     instructions,
+		Cues:             cues,
+		CommonMistakes:   mistakes,
 	}
 	return entry, nil
 }
@@ -202,8 +313,18 @@ func (h *AdminHandler) UpsertCatalogJSON(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	norm, err := h.normalizer(r)
+	if err != nil {
+		log.Printf("catalog facets error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	report := catalognorm.NewReport()
 	entries := make([]store.CatalogEntry, 0, len(payloads))
-	for _, p := range payloads {
+	for i, p := range payloads {
+		if !norm.Entry(report, i+1, &p.Type, &p.BodyPart, &p.Equipment, &p.Level) {
+			continue
+		}
 		entry, err := p.toCatalogEntry()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -219,7 +340,18 @@ func (h *AdminHandler) UpsertCatalogJSON(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "server error", http.StatusInternalServerError)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"upserted": 1, "entry": rec})
+		recordAudit(r, h.Audit, store.AuditRecordParams{
+			Action:     store.AuditCatalogCreate,
+			EntityType: "catalog_entry",
+			EntityID:   rec.ID,
+			After:      rec,
+		})
+		h.Links.Enqueue(rec.Links...)
+		writeJSON(w, http.StatusOK, map[string]any{"upserted": 1, "entry": rec, "normalized": report.Normalized, "rejected": report.Rejected})
+		return
+	}
+	if len(entries) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"upserted": 0, "normalized": report.Normalized, "rejected": report.Rejected})
 		return
 	}
 
@@ -228,7 +360,15 @@ func (h *AdminHandler) UpsertCatalogJSON(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"upserted": n})
+	for _, e := range entries {
+		h.Links.Enqueue(e.Links...)
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogImport,
+		EntityType: "catalog",
+		After:      entries,
+	})
+	writeJSON(w, http.StatusOK, map[string]any{"upserted": n, "normalized": report.Normalized, "rejected": report.Rejected})
 }
 
 func (h *AdminHandler) UpsertCatalogCSV(w http.ResponseWriter, r *http.Request) {
@@ -276,14 +416,24 @@ func (h *AdminHandler) UpsertCatalogCSV(w http.ResponseWriter, r *http.Request)
 	iLinks := index("links")
 	iMultiplier := index("multiplier")
 	iBase := index("base_weight_kg")
+	iInstructions := index("instructions")
+	iCues := index("cues")
+	iMistakes := index("common_mistakes")
 
 	if iName < 0 || iType < 0 || iBody < 0 || iEquip < 0 || iLevel < 0 || iPrimary < 0 {
 		http.Error(w, "csv must include name,type,body_part,equipment,level,primary_muscle headers", http.StatusBadRequest)
 		return
 	}
 
+	norm, err := h.normalizer(r)
+	if err != nil {
+		log.Printf("catalog facets error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	report := catalognorm.NewReport()
 	var entries []store.CatalogEntry
-	for {
+	for row := 1; ; row++ {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
@@ -312,6 +462,15 @@ func (h *AdminHandler) UpsertCatalogCSV(w http.ResponseWriter, r *http.Request)
 		if iLinks >= 0 {
 			p.Links = strings.Split(record[iLinks], "|")
 		}
+		if iInstructions >= 0 {
+			p.Instructions = strings.Split(record[iInstructions], "|")
+		}
+		if iCues >= 0 {
+			p.Cues = strings.Split(record[iCues], "|")
+		}
+		if iMistakes >= 0 {
+			p.CommonMistakes = strings.Split(record[iMistakes], "|")
+		}
 		if iMultiplier >= 0 && strings.TrimSpace(record[iMultiplier]) != "" {
 			if val, err := parseFloat(record[iMultiplier]); err == nil {
 				p.Multiplier = val
@@ -322,6 +481,9 @@ func (h *AdminHandler) UpsertCatalogCSV(w http.ResponseWriter, r *http.Request)
 				p.BaseWeightKg = val
 			}
 		}
+		if !norm.Entry(report, row, &p.Type, &p.BodyPart, &p.Equipment, &p.Level) {
+			continue
+		}
 		entry, err := p.toCatalogEntry()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -331,7 +493,7 @@ func (h *AdminHandler) UpsertCatalogCSV(w http.ResponseWriter, r *http.Request)
 	}
 
 	if len(entries) == 0 {
-		writeJSON(w, http.StatusOK, map[string]any{"upserted": 0})
+		writeJSON(w, http.StatusOK, map[string]any{"upserted": 0, "normalized": report.Normalized, "rejected": report.Rejected})
 		return
 	}
 	n, err := h.Catalog.Upsert(r.Context(), entries)
@@ -339,5 +501,268 @@ func (h *AdminHandler) UpsertCatalogCSV(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"upserted": n})
+	for _, e := range entries {
+		h.Links.Enqueue(e.Links...)
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogImport,
+		EntityType: "catalog",
+		After:      entries,
+	})
+	writeJSON(w, http.StatusOK, map[string]any{"upserted": n, "normalized": report.Normalized, "rejected": report.Rejected})
+}
+
+// CacheStats reports hit/miss counters for the in-memory catalog cache.
+func (h *AdminHandler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Catalog.CacheStats())
+}
+
+type mergeCatalogRequest struct {
+	SourceID string `json:"sourceId"`
+	TargetID string `json:"targetId"`
+}
+
+// MergeCatalog folds a duplicate entry (sourceId) into targetId and deletes
+// the duplicate.
+func (h *AdminHandler) MergeCatalog(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	var req mergeCatalogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.SourceID, req.TargetID = strings.TrimSpace(req.SourceID), strings.TrimSpace(req.TargetID)
+	if req.SourceID == "" || req.TargetID == "" {
+		http.Error(w, "sourceId and targetId are required", http.StatusBadRequest)
+		return
+	}
+	before, err := h.Catalog.GetCatalogEntry(r.Context(), req.SourceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("catalog load entry error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	res, err := h.Catalog.MergeCatalogEntries(r.Context(), req.SourceID, req.TargetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrCatalogMergeSelf):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, sql.ErrNoRows):
+			http.NotFound(w, r)
+		default:
+			log.Printf("catalog merge error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogMerge,
+		EntityType: "catalog_entry",
+		EntityID:   req.SourceID,
+		Before:     before,
+		After:      res,
+	})
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ListMuscles returns the canonical muscles with their aliases and usage.
+func (h *AdminHandler) ListMuscles(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	muscles, err := h.Catalog.Muscles(r.Context())
+	if err != nil {
+		log.Printf("catalog muscles error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": muscles})
+}
+
+type mergeMusclesRequest struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// MergeMuscles folds the muscle named source into target, re-pointing the
+// catalog entries that use it and keeping source as an alias of target.
+func (h *AdminHandler) MergeMuscles(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	var req mergeMusclesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.Source, req.Target = strings.TrimSpace(req.Source), strings.TrimSpace(req.Target)
+	if req.Source == "" || req.Target == "" {
+		http.Error(w, "source and target are required", http.StatusBadRequest)
+		return
+	}
+	res, err := h.Catalog.MergeMuscles(r.Context(), req.Source, req.Target)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrMuscleMergeSelf):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, sql.ErrNoRows):
+			http.NotFound(w, r)
+		default:
+			log.Printf("muscle merge error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditMuscleMerge,
+		EntityType: "muscle",
+		EntityID:   req.Source,
+		After:      res,
+	})
+	writeJSON(w, http.StatusOK, res)
+}
+
+// CatalogDuplicates suggests likely duplicate entries by name similarity.
+// Query: minSimilarity (0.3-1, default 0.6), limit (default 50, max 200).
+func (h *AdminHandler) CatalogDuplicates(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	minSimilarity := 0.6
+	if v := r.URL.Query().Get("minSimilarity"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		// Below 0.3 the trigram operator's default threshold would hide pairs.
+		if err != nil || f < 0.3 || f > 1 {
+			http.Error(w, "minSimilarity must be between 0.3 and 1", http.StatusBadRequest)
+			return
+		}
+		minSimilarity = f
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 200 {
+			http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	items, err := h.Catalog.DuplicateCandidates(r.Context(), minSimilarity, limit)
+	if err != nil {
+		log.Printf("catalog duplicates error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// DeadLinks lists catalog links the background checker found dead, one item
+// per entry still using the link. Query: limit (default 100, max 500).
+func (h *AdminHandler) DeadLinks(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	items, err := h.Catalog.DeadLinks(r.Context(), limit)
+	if err != nil {
+		log.Printf("catalog dead links error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// csvExportHeaders mirrors the columns understood by UpsertCatalogCSV so an
+// export can be edited and re-imported unchanged.
+var csvExportHeaders = []string{"name", "description", "type", "body_part", "equipment", "level", "primary_muscle", "secondary_muscles", "links", "multiplier", "base_weight_kg", "instructions", "cues", "common_mistakes"}
+
+// ExportCatalog streams the whole catalog as CSV (default) or JSON.
+func (h *AdminHandler) ExportCatalog(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		http.Error(w, "format must be csv or json", http.StatusBadRequest)
+		return
+	}
+	filename := "catalog-" + time.Now().UTC().Format("20060102") + "." + format
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	var err error
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		first := true
+		_, _ = io.WriteString(w, "[")
+		err = h.Catalog.ExportCatalog(r.Context(), func(rec *store.CatalogRecord) error {
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			return enc.Encode(rec)
+		})
+		if err == nil {
+			_, _ = io.WriteString(w, "]\n")
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		cw := csv.NewWriter(w)
+		_ = cw.Write(csvExportHeaders)
+		err = h.Catalog.ExportCatalog(r.Context(), func(rec *store.CatalogRecord) error {
+			desc := ""
+			if rec.Description != nil {
+				desc = *rec.Description
+			}
+			return cw.Write([]string{
+				rec.Name,
+				desc,
+				rec.Type,
+				rec.BodyPart,
+				rec.Equipment,
+				rec.Level,
+				strings.Join(rec.PrimaryMuscles, "|"),
+				strings.Join(rec.SecondaryMuscles, "|"),
+				strings.Join(rec.Links, "|"),
+				formatOptionalFloat(rec.Multiplier),
+				formatOptionalFloat(rec.BaseWeightKg),
+				strings.Join(rec.Instructions, "|"),
+				strings.Join(rec.Cues, "|"),
+				strings.Join(rec.CommonMistakes, "|"),
+			})
+		})
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+	}
+	if err != nil {
+		// Headers are already sent; the truncated body is all we can signal.
+		log.Printf("catalog export error: %v", err)
+	}
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
 }
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+)
+
+type AnalyticsHandler struct {
+	Analytics AnalyticsStore
+}
+
+// DayAdherence returns planned vs. logged work for each exercise of a day.
+func (h *AnalyticsHandler) DayAdherence(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	dayID := chi.URLParam(r, "dayId")
+	if dayID == "" {
+		http.Error(w, "dayId required", http.StatusBadRequest)
+		return
+	}
+	res, err := h.Analytics.DayAdherence(r.Context(), uid, dayID)
+	if err != nil {
+		log.Printf("day adherence error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if res == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+const maxStatsWeeks = 52
+
+// MuscleSplit returns weekly set counts and tonnage per muscle.
+// Query: weeks (default 8, max 52), secondaryFactor (default 0.5, 0..1).
+func (h *AnalyticsHandler) MuscleSplit(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	weeks := 8
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxStatsWeeks {
+			http.Error(w, "weeks must be between 1 and 52", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	factor := 0.5
+	if v := r.URL.Query().Get("secondaryFactor"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			http.Error(w, "secondaryFactor must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		factor = f
+	}
+	split, err := h.Analytics.MuscleSplit(r.Context(), uid, weeks, factor, time.Now())
+	if err != nil {
+		log.Printf("muscle split error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weeks":           weeks,
+		"secondaryFactor": factor,
+		"items":           split,
+	})
+}
+
+// SessionDurations returns weekly workout counts and durations.
+// Query: weeks (default 8, max 52).
+func (h *AnalyticsHandler) SessionDurations(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	weeks := 8
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxStatsWeeks {
+			http.Error(w, "weeks must be between 1 and 52", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	items, err := h.Analytics.SessionDurations(r.Context(), uid, weeks, time.Now())
+	if err != nil {
+		log.Printf("session durations error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weeks": weeks,
+		"items": items,
+	})
+}
+
+// TrainingLoad returns weekly tonnage with the acute:chronic workload ratio
+// and deload/spike flags. Query: weeks (default 12, max 52).
+func (h *AnalyticsHandler) TrainingLoad(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	weeks := 12
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxStatsWeeks {
+			http.Error(w, "weeks must be between 1 and 52", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	items, err := h.Analytics.TrainingLoad(r.Context(), uid, weeks, time.Now())
+	if err != nil {
+		log.Printf("training load error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weeks": weeks,
+		"items": items,
+	})
+}
+
+// Recovery returns the training load weeks with the average sleep,
+// soreness and motivation of the daily check-ins, and how each correlates
+// with the tonnage lifted the same day. Query: weeks (default 12, max 52).
+func (h *AnalyticsHandler) Recovery(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	weeks := 12
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxStatsWeeks {
+			http.Error(w, "weeks must be between 1 and 52", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	rec, err := h.Analytics.Recovery(r.Context(), uid, weeks, time.Now())
+	if err != nil {
+		log.Printf("recovery error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weeks":        weeks,
+		"items":        rec.Weeks,
+		"correlations": rec.Correlations,
+	})
+}
+
+// Cardio returns weekly time and distance from non-strength sets.
+// Query: weeks (default 8, max 52).
+func (h *AnalyticsHandler) Cardio(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	weeks := 8
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxStatsWeeks {
+			http.Error(w, "weeks must be between 1 and 52", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	items, err := h.Analytics.Cardio(r.Context(), uid, weeks, time.Now())
+	if err != nil {
+		log.Printf("cardio stats error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weeks": weeks,
+		"items": items,
+	})
+}
+
+// SideBalance returns per-exercise left vs right volume for unilateral sets.
+// Query: weeks (default 8, max 52).
+func (h *AnalyticsHandler) SideBalance(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	weeks := 8
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxStatsWeeks {
+			http.Error(w, "weeks must be between 1 and 52", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	items, err := h.Analytics.SideBalance(r.Context(), uid, weeks, time.Now())
+	if err != nil {
+		log.Printf("side balance stats error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weeks": weeks,
+		"items": items,
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/http/middleware"
+)
+
+// APIKeysHandler manages personal API keys. Keys can only be managed from a
+// cookie session, never with another key.
+type APIKeysHandler struct {
+	Keys APIKeysStore
+}
+
+type createAPIKeyRequest struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"` // read (default) | write
+}
+
+func (h *APIKeysHandler) sessionUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	if _, viaKey := middleware.APIKeyScopeFromContext(r.Context()); viaKey {
+		http.Error(w, "api keys cannot manage api keys", http.StatusForbidden)
+		return "", false
+	}
+	return uid, true
+}
+
+// Create issues a key. The plaintext key is only ever returned here.
+func (h *APIKeysHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		http.Error(w, "name must be 1-100 characters", http.StatusBadRequest)
+		return
+	}
+	switch req.Scope {
+	case "":
+		req.Scope = auth.APIScopeRead
+	case auth.APIScopeRead, auth.APIScopeWrite:
+	default:
+		http.Error(w, "scope must be read or write", http.StatusBadRequest)
+		return
+	}
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		log.Printf("api key generate error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	k, err := h.Keys.Create(r.Context(), uid, req.Name, prefix, hash, req.Scope)
+	if err != nil {
+		log.Printf("api key create error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"key":    key,
+		"apiKey": k,
+	})
+}
+
+func (h *APIKeysHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	keys, err := h.Keys.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("api key list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": keys})
+}
+
+func (h *APIKeysHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	revoked, err := h.Keys.Revoke(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("api key revoke error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
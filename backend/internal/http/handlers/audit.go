@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+// recordAudit writes an audit row for the current user. Audit failures are
+// logged but never fail the mutation that triggered them.
+func recordAudit(r *http.Request, audit AuditStore, p store.AuditRecordParams) {
+	if audit == nil {
+		return
+	}
+	uid, _ := middleware.ActorIDFromContext(r.Context())
+	p.ActorID = uid
+	if err := audit.Record(r.Context(), p); err != nil {
+		log.Printf("audit record error: %v", err)
+	}
+}
+
+// ListAudit returns catalog audit entries, newest first.
+// Query: actor (email or user id), action, from/to (YYYY-MM-DD or RFC3339), limit, offset.
+func (h *AdminHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	filter := store.AuditFilter{Action: strings.TrimSpace(q.Get("action"))}
+	if actor := strings.TrimSpace(q.Get("actor")); actor != "" {
+		if strings.Contains(actor, "@") {
+			filter.ActorEmail = actor
+		} else {
+			filter.ActorID = actor
+		}
+	}
+	for _, p := range []struct {
+		key string
+		dst **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := strings.TrimSpace(q.Get(p.key))
+		if raw == "" {
+			continue
+		}
+		t, err := parseDateOrTime(raw)
+		if err != nil {
+			http.Error(w, "invalid "+p.key, http.StatusBadRequest)
+			return
+		}
+		*p.dst = &t
+	}
+	filter.Limit, _ = strconv.Atoi(q.Get("limit"))
+	filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+	entries, err := h.Audit.List(r.Context(), filter)
+	if err != nil {
+		log.Printf("audit list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": entries})
+}
+
+func parseDateOrTime(raw string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
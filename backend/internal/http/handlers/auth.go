@@ -2,19 +2,39 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"exercise-tracker/internal/auth"
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/models"
 	"exercise-tracker/internal/store"
 )
 
 type AuthHandler struct {
-	Users       *store.Users
+	Users       UsersStore
 	JWTSecret   string
 	CookieDomain string
+	Audit        AuditStore
+	// Sessions records each sign-in so it can be listed and revoked; nil
+	// issues tokens without a session.
+	Sessions SessionsStore
+	// TwoFactor enables TOTP enrollment and its check at login; nil turns
+	// 2FA off.
+	TwoFactor TwoFactorStore
+	// Throttle limits failed logins per account and IP; nil disables it.
+	Throttle *auth.LoginThrottle
+	// GuestMode allows POST /api/auth/guest.
+	GuestMode bool
+	// DeletionGrace is how long a soft-deleted account can still be
+	// restored by signing in before its data is purged.
+	DeletionGrace time.Duration
 }
 
 type registerRequest struct {
@@ -25,6 +45,15 @@ type registerRequest struct {
 type authResponse struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
+	Guest  bool   `json:"guest,omitempty"`
+}
+
+// newAuthResponse hides a guest's placeholder email.
+func newAuthResponse(u *models.User) authResponse {
+	if u.IsGuest {
+		return authResponse{UserID: u.ID, Guest: true}
+	}
+	return authResponse{UserID: u.ID, Email: u.Email}
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -57,19 +86,20 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	token, exp, err := auth.CreateToken(h.JWTSecret, u.ID, 30*24*time.Hour)
-	if err != nil {
+	if err := h.startSession(w, r, u.ID); err != nil {
+		log.Printf("start session error: %v", err)
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	mw := middleware.AuthConfig{JWTSecret: h.JWTSecret, CookieDomain: h.CookieDomain}
-	mw.SetSessionCookie(w, token, exp)
 	writeJSON(w, http.StatusCreated, authResponse{UserID: u.ID, Email: u.Email})
 }
 
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// Code or RecoveryCode is required for accounts with 2FA enabled.
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -78,28 +108,69 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
+	ip := clientIP(r)
+	if st := h.Throttle.Check(req.Email, ip, time.Now()); st.Locked {
+		writeLockout(w, st)
+		return
+	}
 	u, err := h.Users.ByEmail(r.Context(), strings.TrimSpace(req.Email))
 	if err != nil || u == nil {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		h.loginFailed(w, req.Email, ip)
 		return
 	}
 	ok, _ := auth.VerifyPassword(u.PasswordHash, req.Password)
 	if !ok {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		h.loginFailed(w, req.Email, ip)
 		return
 	}
-	token, exp, err := auth.CreateToken(h.JWTSecret, u.ID, 30*24*time.Hour)
+	required, passed, err := h.verifySecondFactor(r, u.ID, req.Code, req.RecoveryCode)
 	if err != nil {
+		log.Printf("login 2fa error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if required && !passed {
+		if req.Code == "" && req.RecoveryCode == "" {
+			// Not a failure: the client asks for the code and retries.
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "two_factor_required"})
+			return
+		}
+		h.loginFailed(w, req.Email, ip)
+		return
+	}
+	h.Throttle.Success(req.Email)
+	if auth.NeedsRehash(u.PasswordHash) {
+		// Upgrade legacy/weaker hashes while we have the plaintext. Failure
+		// only means we try again next login.
+		if hash, err := auth.HashPassword(req.Password); err == nil {
+			if err := h.Users.UpdatePasswordHash(r.Context(), u.ID, hash); err != nil {
+				log.Printf("password rehash error: %v", err)
+			}
+		}
+	}
+	if u.DeletedAt != nil {
+		// Signing in during the grace period cancels the pending deletion.
+		if err := h.Users.CancelDeletion(r.Context(), u.ID); err != nil {
+			log.Printf("cancel deletion error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := h.startSession(w, r, u.ID); err != nil {
+		log.Printf("start session error: %v", err)
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	mw := middleware.AuthConfig{JWTSecret: h.JWTSecret, CookieDomain: h.CookieDomain}
-	mw.SetSessionCookie(w, token, exp)
 	writeJSON(w, http.StatusOK, authResponse{UserID: u.ID, Email: u.Email})
 }
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	mw := middleware.AuthConfig{JWTSecret: h.JWTSecret, CookieDomain: h.CookieDomain}
+	if claims, ok := mw.SessionClaims(r); ok && claims.SessionID != "" && h.Sessions != nil {
+		if _, err := h.Sessions.Revoke(r.Context(), claims.UserID, claims.SessionID); err != nil {
+			log.Printf("logout revoke session error: %v", err)
+		}
+	}
 	mw.ClearSessionCookie(w)
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -115,7 +186,111 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	writeJSON(w, http.StatusOK, authResponse{UserID: u.ID, Email: u.Email})
+	writeJSON(w, http.StatusOK, newAuthResponse(u))
+}
+
+// loginFailed records the failure and responds 401, or 429 if this attempt
+// tipped the account or IP into lockout.
+func (h *AuthHandler) loginFailed(w http.ResponseWriter, email, ip string) {
+	st := h.Throttle.Failure(email, ip, time.Now())
+	if st.Locked {
+		writeLockout(w, st)
+		return
+	}
+	if st.Remaining >= 0 {
+		w.Header().Set("X-Login-Attempts-Remaining", strconv.Itoa(st.Remaining))
+	}
+	http.Error(w, "invalid credentials", http.StatusUnauthorized)
+}
+
+func writeLockout(w http.ResponseWriter, st auth.LockState) {
+	secs := int(math.Ceil(st.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	w.Header().Set("X-Login-Attempts-Remaining", "0")
+	http.Error(w, fmt.Sprintf("too many failed login attempts; try again in %ds", secs), http.StatusTooManyRequests)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+	// Soft schedules deletion after the grace period instead of purging now.
+	Soft bool `json:"soft"`
+}
+
+// DeleteMe removes the caller's account after re-checking their password.
+// With soft=true the account is only scheduled for purge and can be restored
+// by signing in again before the grace period ends.
+func (h *AuthHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	var req deleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	u, err := h.Users.ByID(r.Context(), uid)
+	if err != nil {
+		log.Printf("delete account lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if u == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// Guests have no password to confirm.
+	if ok, _ := auth.VerifyPassword(u.PasswordHash, req.Password); !ok && !u.IsGuest {
+		http.Error(w, "invalid credentials", http.StatusForbidden)
+		return
+	}
+
+	mw := middleware.AuthConfig{JWTSecret: h.JWTSecret, CookieDomain: h.CookieDomain}
+	anon := store.AnonymizedUserID(u.ID)
+	if req.Soft && h.DeletionGrace > 0 {
+		purgeAfter := time.Now().UTC().Add(h.DeletionGrace)
+		if err := h.Users.ScheduleDeletion(r.Context(), u.ID, purgeAfter); err != nil {
+			log.Printf("schedule deletion error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		h.recordAccountAudit(r, store.AuditAccountDeleteScheduled, anon, map[string]any{"purgeAfter": purgeAfter})
+		mw.ClearSessionCookie(w)
+		writeJSON(w, http.StatusAccepted, map[string]any{"purgeAfter": purgeAfter})
+		return
+	}
+	if err := h.Users.Purge(r.Context(), u.ID); err != nil {
+		log.Printf("purge account error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.recordAccountAudit(r, store.AuditAccountDelete, anon, nil)
+	mw.ClearSessionCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recordAccountAudit writes an account lifecycle row without an actor so no
+// personal data survives the purge.
+func (h *AuthHandler) recordAccountAudit(r *http.Request, action, anonID string, after any) {
+	if h.Audit == nil {
+		return
+	}
+	if err := h.Audit.Record(r.Context(), store.AuditRecordParams{
+		Action:     action,
+		EntityType: "user",
+		EntityID:   anonID,
+		After:      after,
+	}); err != nil {
+		log.Printf("audit record error: %v", err)
+	}
 }
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
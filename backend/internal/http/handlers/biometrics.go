@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/biometrics"
+	"exercise-tracker/internal/http/middleware"
+)
+
+// maxBiometricsBody fits MaxSamples samples as JSON with room to spare.
+const maxBiometricsBody = 2 << 20
+
+type BiometricsHandler struct {
+	Biometrics BiometricsStore
+}
+
+type putBiometricsRequest struct {
+	HeartRate []biometrics.Sample `json:"heartRate"`
+	// Profile refines the calorie estimate; it isn't stored.
+	Profile biometrics.Profile `json:"profile"`
+}
+
+// Put replaces the day's heart-rate samples, e.g. from a watch export, and
+// returns the average and max heart rate with the estimated calories.
+func (h *BiometricsHandler) Put(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req putBiometricsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBiometricsBody)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	switch req.Profile.Sex {
+	case "", "male", "female":
+	default:
+		http.Error(w, "profile.sex must be male or female", http.StatusBadRequest)
+		return
+	}
+	if req.Profile.WeightKg < 0 || req.Profile.WeightKg > 500 || req.Profile.Age < 0 || req.Profile.Age > 120 {
+		http.Error(w, "invalid profile", http.StatusBadRequest)
+		return
+	}
+	samples, err := biometrics.Validate(req.HeartRate)
+	if err != nil {
+		http.Error(w, "heartRate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	hr, err := h.Biometrics.PutHeartRate(r.Context(), uid, chi.URLParam(r, "dayId"), samples, req.Profile)
+	if err != nil {
+		log.Printf("biometrics put error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if hr == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"heartRate": hr})
+}
+
+// Get returns the day's heart-rate summary and samples.
+func (h *BiometricsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	hr, err := h.Biometrics.HeartRate(r.Context(), uid, chi.URLParam(r, "dayId"))
+	if err != nil {
+		log.Printf("biometrics get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if hr == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"heartRate": hr})
+}
+
+func (h *BiometricsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Biometrics.DeleteHeartRate(r.Context(), uid, chi.URLParam(r, "dayId"))
+	if err != nil {
+		log.Printf("biometrics delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
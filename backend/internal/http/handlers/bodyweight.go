@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+)
+
+type BodyweightHandler struct {
+	Bodyweight BodyweightStore
+}
+
+type logBodyweightRequest struct {
+	Date     string  `json:"date"` // YYYY-MM-DD, default today
+	WeightKg float64 `json:"weightKg"`
+}
+
+// List returns bodyweight entries, newest first. Query: from, to (YYYY-MM-DD).
+func (h *BodyweightHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var bounds [2]*time.Time
+	for i, key := range []string{"from", "to"} {
+		if v := r.URL.Query().Get(key); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				http.Error(w, "invalid "+key, http.StatusBadRequest)
+				return
+			}
+			bounds[i] = &t
+		}
+	}
+	items, err := h.Bodyweight.List(r.Context(), uid, bounds[0], bounds[1])
+	if err != nil {
+		log.Printf("bodyweight list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Log records (or replaces) the bodyweight for a date.
+func (h *BodyweightHandler) Log(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req logBodyweightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.WeightKg <= 0 || req.WeightKg > 500 {
+		http.Error(w, "weightKg must be between 0 and 500", http.StatusBadRequest)
+		return
+	}
+	date := time.Now().UTC()
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+		date = t
+	}
+	entry, err := h.Bodyweight.Log(r.Context(), uid, date, req.WeightKg)
+	if err != nil {
+		log.Printf("bodyweight log error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (h *BodyweightHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Bodyweight.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("bodyweight delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
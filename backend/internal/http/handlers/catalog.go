@@ -4,47 +4,51 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/linkmeta"
+	"exercise-tracker/internal/musclemap"
+	"exercise-tracker/internal/progression"
 	"exercise-tracker/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type CatalogHandler struct {
-	Catalog *store.Catalog
+	Catalog CatalogStore
+	Audit   AuditStore
+	Gyms    GymsStore
+	Links   *linkmeta.Fetcher
 }
 
 func (h *CatalogHandler) Search(w http.ResponseWriter, r *http.Request) {
 	// require auth
-	if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	q := strings.TrimSpace(r.URL.Query().Get("q"))
-	typ := strings.TrimSpace(r.URL.Query().Get("type"))
-	body := strings.TrimSpace(r.URL.Query().Get("bodyPart"))
-	equip := strings.TrimSpace(r.URL.Query().Get("equipment"))
-	level := strings.TrimSpace(r.URL.Query().Get("level"))
-	muscle := strings.TrimSpace(r.URL.Query().Get("muscle"))
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	sort := strings.TrimSpace(r.URL.Query().Get("sort"))
-	res, err := h.Catalog.Search(r.Context(), store.CatalogSearchParams{
-		Q:         q,
-		Type:      typ,
-		BodyPart:  body,
-		Equipment: equip,
-		Level:     level,
-		Muscle:    muscle,
-		Page:      page,
-		PageSize:  pageSize,
-		Sort:      sort,
-	})
+	params := catalogSearchParams(r.URL.Query())
+	params.ViewerID = uid
+	params.Locales = requestLocales(r)
+	w.Header().Add("Vary", "Accept-Language")
+	// Gym-filtered results also depend on the profile, which the catalog
+	// ETag doesn't cover.
+	if r.URL.Query().Get("gym") != "" {
+		if !h.applyGym(w, r, uid, &params) {
+			return
+		}
+	} else if h.catalogNotModified(w, r, catalogCacheControl) {
+		return
+	}
+	res, err := h.Catalog.Search(r.Context(), params)
 	if err != nil {
 		log.Printf("catalog search error: %v", err)
 		http.Error(w, "server error", http.StatusInternalServerError)
@@ -53,12 +57,78 @@ func (h *CatalogHandler) Search(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, res)
 }
 
+func catalogSearchParams(query url.Values) store.CatalogSearchParams {
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	return store.CatalogSearchParams{
+		Q:          strings.TrimSpace(query.Get("q")),
+		Types:      queryList(query, "type"),
+		BodyParts:  queryList(query, "bodyPart"),
+		Equipment:  queryList(query, "equipment"),
+		Levels:     queryList(query, "level"),
+		Muscles:    queryList(query, "muscle"),
+		Page:       page,
+		PageSize:   pageSize,
+		Sort:       strings.TrimSpace(query.Get("sort")),
+		WithFacets: query.Get("facets") == "true",
+	}
+}
+
+// applyGym limits params to the equipment of the ?gym= profile. It writes the
+// error response and returns false when the profile can't be used.
+func (h *CatalogHandler) applyGym(w http.ResponseWriter, r *http.Request, uid string, params *store.CatalogSearchParams) bool {
+	gym, err := h.Gyms.Get(r.Context(), uid, r.URL.Query().Get("gym"))
+	if err != nil {
+		log.Printf("catalog gym profile error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return false
+	}
+	if gym == nil {
+		http.Error(w, "gym profile not found", http.StatusNotFound)
+		return false
+	}
+	params.AvailableEquipment = gym.Equipment
+	return true
+}
+
+// queryList collects every non-empty value of a repeatable query parameter.
+func queryList(q url.Values, key string) []string {
+	var out []string
+	for _, v := range q[key] {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func (h *CatalogHandler) Facets(w http.ResponseWriter, r *http.Request) {
 	// require auth
-	if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	withCounts := r.URL.Query().Get("withCounts") == "true"
+	params := catalogSearchParams(r.URL.Query())
+	params.ViewerID = uid
+	if withCounts && r.URL.Query().Get("gym") != "" {
+		if !h.applyGym(w, r, uid, &params) {
+			return
+		}
+	} else if h.catalogNotModified(w, r, catalogCacheControl) {
+		return
+	}
+	if withCounts {
+		counts, err := h.Catalog.FacetCounts(r.Context(), params)
+		if err != nil {
+			log.Printf("catalog facet counts error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, counts)
+		return
+	}
 	f, err := h.Catalog.Facets(r.Context())
 	if err != nil {
 		log.Printf("catalog facets error: %v", err)
@@ -69,7 +139,8 @@ func (h *CatalogHandler) Facets(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CatalogHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
-	if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -78,6 +149,10 @@ func (h *CatalogHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "id is required", http.StatusBadRequest)
 		return
 	}
+	w.Header().Add("Vary", "Accept-Language")
+	if h.catalogNotModified(w, r, catalogCacheControl) {
+		return
+	}
 	rec, err := h.Catalog.GetCatalogEntry(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -88,9 +163,55 @@ func (h *CatalogHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	if !rec.VisibleTo(uid) {
+		http.NotFound(w, r)
+		return
+	}
+	if err := h.Catalog.Localize(r.Context(), rec, requestLocales(r)); err != nil {
+		log.Printf("catalog localize error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	writeJSON(w, http.StatusOK, rec)
 }
 
+// MuscleMap returns the entry's primary and secondary muscles as activation
+// levels on the body diagram regions of package musclemap.
+func (h *CatalogHandler) MuscleMap(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	if h.catalogNotModified(w, r, catalogCacheControl) {
+		return
+	}
+	rec, err := h.Catalog.GetCatalogEntry(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("catalog muscle map error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !rec.VisibleTo(uid) {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      rec.ID,
+		"muscles": musclemap.Build(rec.PrimaryMuscles, rec.SecondaryMuscles),
+		"regions": musclemap.Regions,
+	})
+}
+
 func (h *CatalogHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
 	if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
@@ -129,6 +250,17 @@ func (h *CatalogHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
 
 	removeImage := strings.EqualFold(strings.TrimSpace(r.FormValue("removeImage")), "true")
 
+	before, err := h.Catalog.GetCatalogEntry(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("catalog load entry error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+
 	var (
 		imageData     []byte
 		imageMimeType string
@@ -183,6 +315,14 @@ func (h *CatalogHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogUpdate,
+		EntityType: "catalog_entry",
+		EntityID:   id,
+		Before:     before,
+		After:      rec,
+	})
+	h.Links.Enqueue(rec.Links...)
 	writeJSON(w, http.StatusOK, rec)
 }
 
@@ -196,7 +336,23 @@ func (h *CatalogHandler) GetImage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "id is required", http.StatusBadRequest)
 		return
 	}
-	data, mimeType, err := h.Catalog.GetCatalogImage(r.Context(), id)
+	if h.catalogNotModified(w, r, catalogImageCacheControl) {
+		return
+	}
+	var (
+		data     []byte
+		mimeType string
+		err      error
+	)
+	switch r.URL.Query().Get("size") {
+	case "", "full":
+		data, mimeType, err = h.Catalog.GetCatalogImage(r.Context(), id)
+	case "thumb":
+		data, mimeType, err = h.Catalog.GetCatalogThumbnail(r.Context(), id)
+	default:
+		http.Error(w, "size must be full or thumb", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			http.NotFound(w, r)
@@ -228,6 +384,16 @@ func (h *CatalogHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "id is required", http.StatusBadRequest)
 		return
 	}
+	before, err := h.Catalog.GetCatalogEntry(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("catalog load entry error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	if err := h.Catalog.DeleteCatalogEntry(r.Context(), id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			http.NotFound(w, r)
@@ -237,6 +403,12 @@ func (h *CatalogHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogDelete,
+		EntityType: "catalog_entry",
+		EntityID:   id,
+		Before:     before,
+	})
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -266,7 +438,13 @@ func (h *CatalogHandler) GetExerciseStats(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	stats, hasMore, err := h.Catalog.GetExerciseStats(r.Context(), id, userID, limit, offset)
+	formula, err := progression.ParseFormula(r.URL.Query().Get("formula"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, hasMore, err := h.Catalog.GetExerciseStats(r.Context(), id, userID, limit, offset, formula)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			http.NotFound(w, r)
@@ -282,8 +460,102 @@ func (h *CatalogHandler) GetExerciseStats(w http.ResponseWriter, r *http.Request
 		"highestWeightKg": stats.HighestWeightKg,
 		"history":         stats.History,
 		"hasMore":        hasMore,
+		"formula":         stats.Formula,
+		"bestE1rmKg":      stats.BestE1RMKg,
+		"bestE1rmDate":    stats.BestE1RMDate,
 	}
 	writeJSON(w, http.StatusOK, response)
 }
 
+const (
+	defaultProgressPoints = 100
+	maxProgressPoints     = 500
+)
 
+// GetProgress returns chart-ready (date, value) points for the user's history
+// of a catalog exercise: ?metric=e1rm|topset|volume&range=6m&points=100.
+func (h *CatalogHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	metric := q.Get("metric")
+	if metric == "" {
+		metric = store.ProgressE1RM
+	}
+	if metric != store.ProgressE1RM && metric != store.ProgressTopSet && metric != store.ProgressVolume {
+		http.Error(w, "metric must be e1rm, topset or volume", http.StatusBadRequest)
+		return
+	}
+	rng := q.Get("range")
+	if rng == "" {
+		rng = "6m"
+	}
+	since, err := progressSince(rng, time.Now().UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	points := defaultProgressPoints
+	if v := q.Get("points"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 2 || n > maxProgressPoints {
+			http.Error(w, "points must be between 2 and 500", http.StatusBadRequest)
+			return
+		}
+		points = n
+	}
+	formula, err := progression.ParseFormula(q.Get("formula"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	series, err := h.Catalog.ExerciseProgress(r.Context(), id, userID, metric, formula, since, points)
+	if err != nil {
+		log.Printf("catalog exercise progress error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"metric": metric,
+		"range":  rng,
+		"points": series,
+	})
+}
+
+// progressSince turns a range like 30d, 12w, 6m, 1y or all into a start
+// date; all returns nil.
+func progressSince(rng string, now time.Time) (*time.Time, error) {
+	if rng == "all" {
+		return nil, nil
+	}
+	bad := fmt.Errorf("range must look like 30d, 12w, 6m, 1y or all")
+	if len(rng) < 2 {
+		return nil, bad
+	}
+	n, err := strconv.Atoi(rng[:len(rng)-1])
+	if err != nil || n <= 0 || n > 100 {
+		return nil, bad
+	}
+	var t time.Time
+	switch rng[len(rng)-1] {
+	case 'd':
+		t = now.AddDate(0, 0, -n)
+	case 'w':
+		t = now.AddDate(0, 0, -7*n)
+	case 'm':
+		t = now.AddDate(0, -n, 0)
+	case 'y':
+		t = now.AddDate(-n, 0, 0)
+	default:
+		return nil, bad
+	}
+	return &t, nil
+}
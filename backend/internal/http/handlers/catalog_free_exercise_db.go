@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"exercise-tracker/internal/catalognorm"
+	"exercise-tracker/internal/freeexercisedb"
+	"exercise-tracker/internal/store"
+)
+
+// maxFreeExerciseDBBody fits the full free-exercise-db exercises.json with
+// room to grow.
+const maxFreeExerciseDBBody = 16 << 20
+
+// ImportFreeExerciseDB upserts the exercises of a free-exercise-db
+// exercises.json body. Their facets go through the same normalization as the
+// other imports, and the images of entries that have none are downloaded in
+// the background unless ?images=false.
+func (h *AdminHandler) ImportFreeExerciseDB(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	exercises, err := freeexercisedb.Parse(http.MaxBytesReader(w, r.Body, maxFreeExerciseDBBody))
+	if err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	norm, err := h.normalizer(r)
+	if err != nil {
+		log.Printf("catalog facets error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	report := catalognorm.NewReport()
+	entries := make([]store.CatalogEntry, 0, len(exercises))
+	accepted := make([]freeexercisedb.Exercise, 0, len(exercises))
+	for i, e := range exercises {
+		entry, err := e.Entry()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("exercise %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		if !norm.Entry(report, i+1, &entry.Type, &entry.BodyPart, &entry.Equipment, &entry.Level) {
+			continue
+		}
+		entries = append(entries, entry)
+		accepted = append(accepted, e)
+	}
+	if len(entries) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"upserted": 0, "normalized": report.Normalized, "rejected": report.Rejected, "imagesQueued": false})
+		return
+	}
+
+	n, err := h.Catalog.Upsert(r.Context(), entries)
+	if err != nil {
+		log.Printf("free-exercise-db import error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogImport,
+		EntityType: "catalog",
+		After:      entries,
+	})
+	queued := false
+	if h.Images != nil && r.URL.Query().Get("images") != "false" {
+		if err := h.Images.Enqueue(r.Context(), accepted); err != nil {
+			log.Printf("free-exercise-db image enqueue error: %v", err)
+		} else {
+			queued = true
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"upserted": n, "normalized": report.Normalized, "rejected": report.Rejected, "imagesQueued": queued})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// publicCatalogCacheControl lets shared caches in front of the marketing
+// site keep anonymous catalog reads for a few minutes.
+const publicCatalogCacheControl = "public, max-age=300"
+
+// PublicSearch is Search for visitors without a session: approved entries
+// only, and no gym filter.
+func (h *CatalogHandler) PublicSearch(w http.ResponseWriter, r *http.Request) {
+	params := catalogSearchParams(r.URL.Query())
+	params.Locales = requestLocales(r)
+	w.Header().Add("Vary", "Accept-Language")
+	if h.catalogNotModified(w, r, publicCatalogCacheControl) {
+		return
+	}
+	res, err := h.Catalog.Search(r.Context(), params)
+	if err != nil {
+		log.Printf("public catalog search error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// PublicFacets is Facets for visitors without a session.
+func (h *CatalogHandler) PublicFacets(w http.ResponseWriter, r *http.Request) {
+	if h.catalogNotModified(w, r, publicCatalogCacheControl) {
+		return
+	}
+	if r.URL.Query().Get("withCounts") == "true" {
+		counts, err := h.Catalog.FacetCounts(r.Context(), catalogSearchParams(r.URL.Query()))
+		if err != nil {
+			log.Printf("public catalog facet counts error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, counts)
+		return
+	}
+	f, err := h.Catalog.Facets(r.Context())
+	if err != nil {
+		log.Printf("public catalog facets error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
+
+// PublicGetEntry is GetEntry for visitors without a session; pending
+// submissions are not found.
+func (h *CatalogHandler) PublicGetEntry(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	if h.catalogNotModified(w, r, publicCatalogCacheControl) {
+		return
+	}
+	rec, err := h.Catalog.GetCatalogEntry(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("public catalog get entry error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !rec.VisibleTo("") {
+		http.NotFound(w, r)
+		return
+	}
+	if err := h.Catalog.Localize(r.Context(), rec, requestLocales(r)); err != nil {
+		log.Printf("catalog localize error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
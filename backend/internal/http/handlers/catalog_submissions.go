@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+// Submit queues a user's new catalog entry for admin review. Until approved
+// it only shows up in the author's own searches.
+func (h *CatalogHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload catalogPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	entry, err := payload.toCatalogEntry()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rec, err := h.Catalog.SubmitCatalogEntry(r.Context(), uid, entry, nil, "")
+	if err != nil {
+		if errors.Is(err, store.ErrCatalogNameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("catalog submit error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogSubmit,
+		EntityType: "catalog_entry",
+		EntityID:   rec.ID,
+		After:      rec,
+	})
+	h.Links.Enqueue(rec.Links...)
+	writeJSON(w, http.StatusCreated, rec)
+}
+
+// Submissions lists the caller's submissions with their review state and
+// feedback.
+func (h *CatalogHandler) Submissions(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.Catalog.Submissions(r.Context(), uid, "")
+	if err != nil {
+		log.Printf("catalog submissions error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// ListSubmissions is the admin review queue. Query: status (pending by
+// default, approved, rejected or all).
+func (h *AdminHandler) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = store.CatalogPending
+	case "all":
+		status = ""
+	case store.CatalogPending, store.CatalogApproved, store.CatalogRejected:
+	default:
+		http.Error(w, "status must be pending, approved, rejected or all", http.StatusBadRequest)
+		return
+	}
+	items, err := h.Catalog.Submissions(r.Context(), "", status)
+	if err != nil {
+		log.Printf("catalog submissions error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+type reviewSubmissionRequest struct {
+	Feedback *string `json:"feedback"`
+}
+
+func (h *AdminHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request) {
+	h.reviewSubmission(w, r, store.CatalogApproved)
+}
+
+// RejectSubmission requires feedback for the author.
+func (h *AdminHandler) RejectSubmission(w http.ResponseWriter, r *http.Request) {
+	h.reviewSubmission(w, r, store.CatalogRejected)
+}
+
+func (h *AdminHandler) reviewSubmission(w http.ResponseWriter, r *http.Request, status string) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	uid, _ := middleware.UserIDFromContext(r.Context())
+	var req reviewSubmissionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+	}
+	req.Feedback = trimStringPtr(req.Feedback)
+	if status == store.CatalogRejected && req.Feedback == nil {
+		http.Error(w, "feedback is required", http.StatusBadRequest)
+		return
+	}
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+	rec, err := h.Catalog.ReviewCatalogEntry(r.Context(), id, uid, status, req.Feedback)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.NotFound(w, r)
+		case errors.Is(err, store.ErrCatalogNotPending):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("catalog review error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	action := store.AuditCatalogApprove
+	if status == store.CatalogRejected {
+		action = store.AuditCatalogReject
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     action,
+		EntityType: "catalog_entry",
+		EntityID:   rec.ID,
+		After:      rec,
+	})
+	writeJSON(w, http.StatusOK, rec)
+}
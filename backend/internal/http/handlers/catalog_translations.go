@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/store"
+)
+
+// ListTranslations returns every translation of a catalog entry.
+func (h *AdminHandler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	items, err := h.Catalog.Translations(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("catalog translations error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+type translationRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// PutTranslation creates or replaces the entry's name and description for
+// the {locale} in the path.
+func (h *AdminHandler) PutTranslation(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	locale, ok := normalizeLocale(chi.URLParam(r, "locale"))
+	if !ok {
+		http.Error(w, "invalid locale", http.StatusBadRequest)
+		return
+	}
+	if base, _, _ := strings.Cut(locale, "-"); base == "en" {
+		http.Error(w, "english is the base catalog language", http.StatusBadRequest)
+		return
+	}
+	var req translationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	t, err := h.Catalog.PutTranslation(r.Context(), store.CatalogTranslation{
+		CatalogID:   chi.URLParam(r, "id"),
+		Locale:      locale,
+		Name:        name,
+		Description: trimStringPtr(req.Description),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("catalog translation put error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogTranslate,
+		EntityType: "catalog_entry",
+		EntityID:   t.CatalogID,
+		After:      t,
+	})
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (h *AdminHandler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	locale, ok := normalizeLocale(chi.URLParam(r, "locale"))
+	if !ok {
+		http.Error(w, "invalid locale", http.StatusBadRequest)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	deleted, err := h.Catalog.DeleteTranslation(r.Context(), id, locale)
+	if err != nil {
+		log.Printf("catalog translation delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditCatalogTranslate,
+		EntityType: "catalog_entry",
+		EntityID:   id,
+		Before:     map[string]string{"locale": locale},
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+type ChallengesHandler struct {
+	Challenges ChallengesStore
+}
+
+const (
+	maxChallengeName = 80
+	maxChallengeDays = 366
+)
+
+type createChallengeRequest struct {
+	Name      string  `json:"name"`
+	Metric    string  `json:"metric"`    // volume | sets | days | streak
+	CatalogID *string `json:"catalogId"` // omit for every exercise
+	StartsOn  string  `json:"startsOn"`  // YYYY-MM-DD
+	EndsOn    string  `json:"endsOn"`    // YYYY-MM-DD, inclusive
+	Public    *bool   `json:"public"`    // default true
+}
+
+// List returns public challenges and the ones the caller joined. Query:
+// status (active (default), upcoming, past or all).
+func (h *ChallengesHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = store.ChallengesActive
+	}
+	switch status {
+	case store.ChallengesActive, store.ChallengesUpcoming, store.ChallengesPast, store.ChallengesAll:
+	default:
+		http.Error(w, "status must be active, upcoming, past or all", http.StatusBadRequest)
+		return
+	}
+	items, err := h.Challenges.List(r.Context(), uid, status, time.Now().UTC())
+	if err != nil {
+		log.Printf("list challenges error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Create adds a challenge and joins the caller to it.
+func (h *ChallengesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req createChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, msg := req.params(time.Now().UTC())
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	c, err := h.Challenges.Create(r.Context(), uid, p)
+	if errors.Is(err, store.ErrUnknownCatalogEntry) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("create challenge error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (req createChallengeRequest) params(today time.Time) (store.ChallengeParams, string) {
+	p := store.ChallengeParams{Name: strings.TrimSpace(req.Name), Metric: req.Metric, Public: true}
+	if p.Name == "" || utf8.RuneCountInString(p.Name) > maxChallengeName {
+		return p, "name is required (max 80 characters)"
+	}
+	switch p.Metric {
+	case store.ChallengeVolume, store.ChallengeSets, store.ChallengeDays, store.ChallengeStreak:
+	default:
+		return p, "metric must be volume, sets, days or streak"
+	}
+	if req.CatalogID != nil && *req.CatalogID != "" {
+		p.CatalogID = req.CatalogID
+	}
+	var err error
+	if p.StartsOn, err = time.Parse("2006-01-02", req.StartsOn); err != nil {
+		return p, "invalid startsOn"
+	}
+	if p.EndsOn, err = time.Parse("2006-01-02", req.EndsOn); err != nil {
+		return p, "invalid endsOn"
+	}
+	if p.EndsOn.Before(p.StartsOn) || p.EndsOn.Sub(p.StartsOn) >= maxChallengeDays*24*time.Hour {
+		return p, "endsOn must be on or after startsOn, within 366 days"
+	}
+	if p.EndsOn.Format("2006-01-02") < today.Format("2006-01-02") {
+		return p, "endsOn is in the past"
+	}
+	if req.Public != nil {
+		p.Public = *req.Public
+	}
+	return p, ""
+}
+
+// Get returns a challenge with its current standings.
+func (h *ChallengesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	c, err := h.Challenges.Get(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get challenge error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if c == nil {
+		http.NotFound(w, r)
+		return
+	}
+	standings, err := h.Challenges.Standings(r.Context(), c, uid)
+	if err != nil {
+		log.Printf("challenge standings error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"challenge": c, "standings": standings})
+}
+
+// Join adds the caller to a challenge that hasn't ended.
+func (h *ChallengesHandler) Join(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	c, err := h.Challenges.Join(r.Context(), uid, chi.URLParam(r, "id"), time.Now().UTC())
+	if errors.Is(err, store.ErrChallengeEnded) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("join challenge error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if c == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// Leave removes the caller from a challenge.
+func (h *ChallengesHandler) Leave(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	left, err := h.Challenges.Leave(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("leave challenge error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !left {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete removes a challenge the caller created.
+func (h *ChallengesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Challenges.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("delete challenge error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/store"
+)
+
+func TestChallengesCreate(t *testing.T) {
+	year := time.Now().UTC().Year() + 1
+	on := func(month time.Month, day int) string {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	}
+	body := func(fields string) string {
+		return `{"name":" March squats ","metric":"volume","startsOn":"` + on(3, 1) + `","endsOn":"` + on(3, 31) + `"` + fields + `}`
+	}
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{name: "invalid json", body: `{`, status: http.StatusBadRequest},
+		{name: "missing name", body: `{"name":" ","metric":"volume","startsOn":"` + on(3, 1) + `","endsOn":"` + on(3, 31) + `"}`, status: http.StatusBadRequest},
+		{name: "long name", body: `{"name":"` + strings.Repeat("x", 81) + `","metric":"sets","startsOn":"` + on(3, 1) + `","endsOn":"` + on(3, 31) + `"}`, status: http.StatusBadRequest},
+		{name: "bad metric", body: `{"name":"x","metric":"reps","startsOn":"` + on(3, 1) + `","endsOn":"` + on(3, 31) + `"}`, status: http.StatusBadRequest},
+		{name: "ends before start", body: `{"name":"x","metric":"days","startsOn":"` + on(3, 31) + `","endsOn":"` + on(3, 1) + `"}`, status: http.StatusBadRequest},
+		{name: "too long", body: `{"name":"x","metric":"streak","startsOn":"` + on(1, 1) + `","endsOn":"` + time.Date(year+1, 1, 2, 0, 0, 0, 0, time.UTC).Format("2006-01-02") + `"}`, status: http.StatusBadRequest},
+		{name: "ended", body: `{"name":"x","metric":"days","startsOn":"2001-03-01","endsOn":"2001-03-31"}`, status: http.StatusBadRequest},
+		{name: "unknown exercise", body: body(`,"catalogId":"cat-x"`), err: store.ErrUnknownCatalogEntry, status: http.StatusBadRequest},
+		{name: "created", body: body(`,"catalogId":"cat-1","public":false`), status: http.StatusCreated},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got store.ChallengeParams
+			challenges := &mocks.ChallengesStore{
+				CreateFunc: func(_ context.Context, userID string, p store.ChallengeParams) (*store.Challenge, error) {
+					got = p
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &store.Challenge{ID: "ch-1", Name: p.Name, Metric: p.Metric}, nil
+				},
+			}
+			h := &handlers.ChallengesHandler{Challenges: challenges}
+			w := httptest.NewRecorder()
+			h.Create(w, newRequest(http.MethodPost, "/api/challenges", tc.body, "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+			if tc.status == http.StatusCreated {
+				if got.Name != "March squats" || got.CatalogID == nil || *got.CatalogID != "cat-1" || got.Public {
+					t.Fatalf("store got %+v, want trimmed name, the catalog id and a private challenge", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+const maxCheckinNote = 500
+
+type CheckinsHandler struct {
+	Checkins CheckinsStore
+}
+
+type putCheckinRequest struct {
+	SleepHours *float64 `json:"sleepHours"`
+	Soreness   *int     `json:"soreness"`   // 1-5
+	Motivation *int     `json:"motivation"` // 1-5
+	Note       *string  `json:"note"`
+}
+
+// List returns check-ins, newest first. Query: from, to (YYYY-MM-DD).
+func (h *CheckinsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bounds, ok := dateBounds(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.Checkins.List(r.Context(), uid, bounds[0], bounds[1])
+	if err != nil {
+		log.Printf("checkins list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Put records (or replaces) the check-in for the {date} in the path.
+func (h *CheckinsHandler) Put(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		http.Error(w, "invalid date", http.StatusBadRequest)
+		return
+	}
+	var req putCheckinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.SleepHours == nil && req.Soreness == nil && req.Motivation == nil {
+		http.Error(w, "sleepHours, soreness or motivation is required", http.StatusBadRequest)
+		return
+	}
+	if req.SleepHours != nil && (*req.SleepHours < 0 || *req.SleepHours > 24) {
+		http.Error(w, "sleepHours must be between 0 and 24", http.StatusBadRequest)
+		return
+	}
+	for name, v := range map[string]*int{"soreness": req.Soreness, "motivation": req.Motivation} {
+		if v != nil && (*v < 1 || *v > 5) {
+			http.Error(w, name+" must be between 1 and 5", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Note != nil {
+		if note := strings.TrimSpace(*req.Note); note == "" {
+			req.Note = nil
+		} else if len(note) > maxCheckinNote {
+			http.Error(w, "note is too long", http.StatusBadRequest)
+			return
+		} else {
+			req.Note = &note
+		}
+	}
+	checkin, err := h.Checkins.Put(r.Context(), uid, date, store.CheckinParams{
+		SleepHours: req.SleepHours, Soreness: req.Soreness, Motivation: req.Motivation, Note: req.Note,
+	})
+	if err != nil {
+		log.Printf("checkin put error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, checkin)
+}
+
+func (h *CheckinsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		http.Error(w, "invalid date", http.StatusBadRequest)
+		return
+	}
+	deleted, err := h.Checkins.Delete(r.Context(), uid, date)
+	if err != nil {
+		log.Printf("checkin delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+// CoachingHandler manages coach invitations from both sides. Delegated
+// access to a client's data goes through middleware.Delegated.
+type CoachingHandler struct {
+	Coaching CoachingStore
+	Users    UsersStore
+}
+
+type inviteCoachRequest struct {
+	Email    string `json:"email"`
+	CanWrite bool   `json:"canWrite"`
+}
+
+// Invite lets the current user invite a coach by email.
+func (h *CoachingHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req inviteCoachRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !strings.Contains(req.Email, "@") {
+		http.Error(w, "valid email is required", http.StatusBadRequest)
+		return
+	}
+	link, err := h.Coaching.Invite(r.Context(), uid, req.Email, req.CanWrite)
+	if err != nil {
+		if errors.Is(err, store.ErrSelfCoach) {
+			http.Error(w, "cannot invite yourself", http.StatusBadRequest)
+			return
+		}
+		log.Printf("coach invite error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, link)
+}
+
+// ListCoaches returns the coaches the current user has invited.
+func (h *CoachingHandler) ListCoaches(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	links, err := h.Coaching.Coaches(r.Context(), uid)
+	if err != nil {
+		log.Printf("list coaches error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": links})
+}
+
+// ListClients returns the current user's clients and pending invitations.
+func (h *CoachingHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	links, err := h.Coaching.Clients(r.Context(), uid, email)
+	if err != nil {
+		log.Printf("list clients error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": links})
+}
+
+// Accept activates an invitation addressed to the current user's email.
+func (h *CoachingHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	link, err := h.Coaching.Accept(r.Context(), chi.URLParam(r, "id"), uid, email)
+	if err != nil {
+		log.Printf("accept invite error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if link == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+// Remove ends a coaching link from either side.
+func (h *CoachingHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	removed, err := h.Coaching.Remove(r.Context(), chi.URLParam(r, "id"), uid, email)
+	if err != nil {
+		log.Printf("remove coach link error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CoachingHandler) currentUser(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", "", false
+	}
+	u, err := h.Users.ByID(r.Context(), uid)
+	if err != nil {
+		log.Printf("coaching user lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return "", "", false
+	}
+	if u == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", "", false
+	}
+	return uid, strings.ToLower(u.Email), true
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/realtime"
+	"exercise-tracker/internal/store"
+)
+
+const maxCommentLen = 4000
+
+// CommentsHandler serves feedback threads on workout days. Under
+// /api/clients/{userId} the user is the athlete and the actor is the coach.
+type CommentsHandler struct {
+	Comments CommentsStore
+	Hub      *realtime.Hub
+}
+
+type createCommentRequest struct {
+	ExerciseID *string `json:"exerciseId"`
+	Body       string  `json:"body"`
+}
+
+func (h *CommentsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	comments, err := h.Comments.ListByDay(r.Context(), uid, chi.URLParam(r, "dayId"))
+	if err != nil {
+		log.Printf("list comments error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if comments == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": comments})
+}
+
+func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	actor, _ := middleware.ActorIDFromContext(r.Context())
+	var req createCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" || utf8.RuneCountInString(body) > maxCommentLen {
+		http.Error(w, "body must be 1-4000 characters", http.StatusBadRequest)
+		return
+	}
+	c, err := h.Comments.Create(r.Context(), uid, actor, chi.URLParam(r, "dayId"), req.ExerciseID, body)
+	if err != nil {
+		if errors.Is(err, store.ErrCommentTarget) {
+			http.Error(w, "exercise does not belong to this day", http.StatusBadRequest)
+			return
+		}
+		log.Printf("create comment error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if c == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if h.Hub != nil && actor != uid {
+		store.AfterCommit(r.Context(), func() {
+			h.Hub.Publish(uid, realtime.Event{Type: realtime.EventComment, Data: c})
+		})
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (h *CommentsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	actor, ok := middleware.ActorIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Comments.Delete(r.Context(), actor, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("delete comment error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkRead clears the unread flag on a day's comments for its owner.
+func (h *CommentsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	n, err := h.Comments.MarkRead(r.Context(), uid, chi.URLParam(r, "dayId"))
+	if err != nil {
+		log.Printf("mark comments read error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"marked": n})
+}
+
+// Unread returns unread comment counts per day plus the total.
+func (h *CommentsHandler) Unread(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	counts, err := h.Comments.UnreadCounts(r.Context(), uid)
+	if err != nil {
+		log.Printf("unread comments error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	total := 0
+	for _, c := range counts {
+		total += c.Count
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total": total, "items": counts})
+}
@@ -1,20 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"exercise-tracker/internal/achievements"
+	"exercise-tracker/internal/assist"
 	"exercise-tracker/internal/http/middleware"
 	"exercise-tracker/internal/models"
 	"exercise-tracker/internal/store"
 )
 
 type DaysHandler struct {
-	Days *store.Days
+	Days         DaysStore
+	Achievements *achievements.Engine
+	Assist       assist.Summarizer
 }
 
 type ensureDayRequest struct {
@@ -22,7 +30,10 @@ type ensureDayRequest struct {
 }
 
 type updateDayRequest struct {
-	IsRestDay *bool `json:"isRestDay"`
+	IsRestDay *bool   `json:"isRestDay"`
+	Notes     *string `json:"notes"`
+	// Visibility is private, friends, or "" for the account default.
+	Visibility *string `json:"visibility"`
 }
 
 func (h *DaysHandler) GetByDate(w http.ResponseWriter, r *http.Request) {
@@ -45,6 +56,9 @@ func (h *DaysHandler) GetByDate(w http.ResponseWriter, r *http.Request) {
 	if ensure {
 		// ensure day exists
 		if _, err := h.Days.GetOrCreate(r.Context(), uid, dt); err != nil {
+			if writeQuotaError(w, err) {
+				return
+			}
 			http.Error(w, "server error", http.StatusInternalServerError)
 			return
 		}
@@ -85,6 +99,9 @@ func (h *DaysHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	day, err := h.Days.GetOrCreate(r.Context(), uid, dt)
 	if err != nil {
+		if writeQuotaError(w, err) {
+			return
+		}
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
@@ -112,16 +129,270 @@ func (h *DaysHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
-	if req.IsRestDay == nil {
-		http.Error(w, "isRestDay required", http.StatusBadRequest)
+	if req.IsRestDay == nil && req.Notes == nil && req.Visibility == nil {
+		http.Error(w, "isRestDay, notes or visibility required", http.StatusBadRequest)
 		return
 	}
-	day, err := h.Days.SetRestDay(r.Context(), uid, dayID, *req.IsRestDay)
+	if v := req.Visibility; v != nil && *v != "" && *v != store.VisibilityPrivate && *v != store.VisibilityFriends {
+		http.Error(w, "visibility must be private, friends or empty", http.StatusBadRequest)
+		return
+	}
+	if hasPrecondition(r) {
+		current, err := h.Days.GetForUpdate(r.Context(), uid, dayID)
+		if err != nil {
+			log.Printf("lock day error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if current == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if preconditionFailed(r, current.UpdatedAt) {
+			detail, err := h.Days.GetWithDetails(r.Context(), uid, dayID)
+			if err != nil {
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			writePreconditionFailed(w, current.UpdatedAt, detail)
+			return
+		}
+	}
+	var day *models.WorkoutDay
+	var err error
+	if req.IsRestDay != nil {
+		day, err = h.Days.SetRestDay(r.Context(), uid, dayID, *req.IsRestDay)
+		if err != nil {
+			if errors.Is(err, store.ErrRestDayHasExercises) {
+				http.Error(w, "remove existing exercises before marking rest day", http.StatusConflict)
+				return
+			}
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if day == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	if req.Notes != nil {
+		day, err = h.Days.SetNotes(r.Context(), uid, dayID, *req.Notes)
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if day == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	if req.Visibility != nil {
+		day, err = h.Days.SetVisibility(r.Context(), uid, dayID, *req.Visibility)
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if day == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	detail, err := h.Days.GetWithDetails(r.Context(), uid, day.ID)
 	if err != nil {
-		if errors.Is(err, store.ErrRestDayHasExercises) {
-			http.Error(w, "remove existing exercises before marking rest day", http.StatusConflict)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	setValidators(w, day.UpdatedAt)
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// maxBatchDays caps how many ids and dates one batch request may name.
+const maxBatchDays = 62
+
+type batchDaysRequest struct {
+	IDs   []string `json:"ids"`
+	Dates []string `json:"dates"` // YYYY-MM-DD
+}
+
+// Batch returns every requested day with details in one response, oldest
+// first. Ids or dates without a day are left out.
+func (h *DaysHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req batchDaysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs)+len(req.Dates) == 0 {
+		http.Error(w, "ids or dates required", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs)+len(req.Dates) > maxBatchDays {
+		http.Error(w, "too many days", http.StatusBadRequest)
+		return
+	}
+	dates := make([]time.Time, 0, len(req.Dates))
+	for _, ds := range req.Dates {
+		dt, err := time.Parse("2006-01-02", ds)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+		dates = append(dates, dt)
+	}
+	ids := req.IDs
+	if ids == nil {
+		ids = []string{}
+	}
+	days, err := h.Days.ListWithDetails(r.Context(), uid, ids, dates)
+	if err != nil {
+		log.Printf("batch days error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": days})
+}
+
+// Week returns lightweight summaries for the seven days from ?start=
+// (default: this week's Monday).
+func (h *DaysHandler) Week(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	start := store.WeekStart(time.Now())
+	if v := r.URL.Query().Get("start"); v != "" {
+		dt, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "invalid start", http.StatusBadRequest)
 			return
 		}
+		start = dt
+	}
+	days, err := h.Days.WeekSummary(r.Context(), uid, start)
+	if err != nil {
+		log.Printf("week summary error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"start": start.Format("2006-01-02"), "items": days})
+}
+
+// Search finds ?q= in the user's day notes, exercise names and exercise
+// comments across all history, grouped by day.
+func (h *DaysHandler) Search(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len([]rune(q)) < 2 {
+		http.Error(w, "q must be at least 2 characters", http.StatusBadRequest)
+		return
+	}
+	results, err := h.Days.SearchHistory(r.Context(), uid, q)
+	if err != nil {
+		log.Printf("history search error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"q": q, "items": results})
+}
+
+// ExerciseTimeline returns an exercise's sets and rests merged in workout
+// order as {kind: "set"|"rest", set|rest} entries.
+func (h *DaysHandler) ExerciseTimeline(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	timeline, err := h.Days.ExerciseTimeline(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("exercise timeline error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if timeline == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": timeline})
+}
+
+// SessionTimeline returns a day's sets ordered by when they were performed,
+// with offsets, gaps and pace stats.
+func (h *DaysHandler) SessionTimeline(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	timeline, err := h.Days.SessionTimeline(r.Context(), uid, chi.URLParam(r, "dayId"))
+	if err != nil {
+		log.Printf("session timeline error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if timeline == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, timeline)
+}
+
+type sessionTimeRequest struct {
+	At *time.Time `json:"at"` // defaults to now
+}
+
+// StartSession marks when the day's workout started.
+func (h *DaysHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	h.setSessionTime(w, r, h.Days.StartSession)
+}
+
+// FinishSession marks when the day's workout finished, and has the day's
+// achievements evaluated.
+func (h *DaysHandler) FinishSession(w http.ResponseWriter, r *http.Request) {
+	h.setSessionTime(w, r, func(ctx context.Context, userID, dayID string, at time.Time) (*models.WorkoutDay, error) {
+		day, err := h.Days.FinishSession(ctx, userID, dayID, at)
+		if err == nil && day != nil {
+			h.Achievements.Enqueue(ctx, userID)
+		}
+		return day, err
+	})
+}
+
+func (h *DaysHandler) setSessionTime(w http.ResponseWriter, r *http.Request, set func(ctx context.Context, userID, dayID string, at time.Time) (*models.WorkoutDay, error)) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	dayID := chi.URLParam(r, "dayId")
+	var req sessionTimeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+	}
+	at := time.Now().UTC()
+	if req.At != nil {
+		at = req.At.UTC()
+	}
+	day, err := set(r.Context(), uid, dayID, at)
+	if err != nil {
+		if errors.Is(err, store.ErrSessionOrder) {
+			http.Error(w, "session cannot finish before it starts", http.StatusBadRequest)
+			return
+		}
+		log.Printf("session time error: %v", err)
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
@@ -129,10 +400,32 @@ func (h *DaysHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	detail, err := h.Days.GetWithDetails(r.Context(), uid, day.ID)
+	writeJSON(w, http.StatusOK, day)
+}
+
+// Summarize returns a natural-language summary of the day's session from its
+// sets and notes.
+func (h *DaysHandler) Summarize(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	day, err := h.Days.GetWithDetails(r.Context(), uid, chi.URLParam(r, "dayId"))
 	if err != nil {
+		log.Printf("summarize day error: %v", err)
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, detail)
+	if day == nil {
+		http.NotFound(w, r)
+		return
+	}
+	summary, err := h.Assist.Summarize(r.Context(), day)
+	if err != nil {
+		log.Printf("summarize day error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
 }
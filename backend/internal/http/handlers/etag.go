@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	catalogCacheControl      = "private, no-cache"
+	catalogImageCacheControl = "private, max-age=3600"
+)
+
+// catalogNotModified sets ETag/Cache-Control from the catalog version and
+// writes a 304 when the client's copy is current. Callers return when it
+// reports true. If the version can't be read the response is served uncached.
+func (h *CatalogHandler) catalogNotModified(w http.ResponseWriter, r *http.Request, cacheControl string) bool {
+	v, err := h.Catalog.Version(r.Context())
+	if err != nil {
+		log.Printf("catalog version error: %v", err)
+		return false
+	}
+	etag := fmt.Sprintf(`W/"catalog-%d"`, v)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// etagMatches implements the weak comparison used for If-None-Match.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
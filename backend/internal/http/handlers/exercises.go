@@ -1,18 +1,27 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/progression"
 	"exercise-tracker/internal/store"
 )
 
 type ExercisesHandler struct {
-	Exercises *store.Exercises
+	Exercises ExercisesStore
+	Catalog   CatalogStore
+	Sets      SetsStore
+	Settings  SettingsStore
 }
 
 type createExerciseRequest struct {
@@ -66,6 +75,22 @@ func (h *ExercisesHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
+	if hasPrecondition(r) {
+		current, err := h.Exercises.GetForUpdate(r.Context(), uid, id)
+		if err != nil {
+			log.Printf("lock exercise error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if current == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if preconditionFailed(r, current.UpdatedAt) {
+			writePreconditionFailed(w, current.UpdatedAt, current)
+			return
+		}
+	}
 	ex, err := h.Exercises.Update(r.Context(), uid, id, req.Position, req.Comment)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
@@ -75,6 +100,7 @@ func (h *ExercisesHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	setValidators(w, ex.UpdatedAt)
 	writeJSON(w, http.StatusOK, ex)
 }
 
@@ -96,3 +122,248 @@ func (h *ExercisesHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+type moveExerciseRequest struct {
+	DayID    string `json:"dayId"`
+	Position *int   `json:"position"`
+}
+
+// Move reassigns an exercise with its sets and rests to another day.
+func (h *ExercisesHandler) Move(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	var req moveExerciseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.DayID) == "" {
+		http.Error(w, "dayId is required", http.StatusBadRequest)
+		return
+	}
+	ex, err := h.Exercises.Move(r.Context(), uid, id, req.DayID, req.Position)
+	if err != nil {
+		if errors.Is(err, store.ErrExerciseOnRestDay) {
+			http.Error(w, "cannot move exercises to a rest day", http.StatusConflict)
+			return
+		}
+		log.Printf("move exercise error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if ex == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, ex)
+}
+
+type reorderRequest struct {
+	OrderedIDs []string `json:"orderedIds"`
+}
+
+// Reorder sets the order of a day's exercises. Body {orderedIds} must list
+// each of the day's exercises once.
+func (h *ExercisesHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	reorder(w, r, chi.URLParam(r, "dayId"), h.Exercises.Reorder)
+}
+
+func reorder(w http.ResponseWriter, r *http.Request, parentID string, apply func(ctx context.Context, userID, parentID string, ids []string) (bool, error)) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req reorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	found, err := apply(r.Context(), uid, parentID, req.OrderedIDs)
+	if err != nil {
+		if errors.Is(err, store.ErrReorderIDs) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("reorder error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// suggestionHistoryDays is how many recent sessions feed the progression rules.
+const suggestionHistoryDays = 6
+
+// Suggestion returns the next-session target for a catalog entry.
+// Query: rule=linear|double, incrementKg, targetReps, minReps, maxReps.
+// Weights round to what the user can load: the exercise's base (the bar for
+// barbell lifts) plus whole steps of their smallest increment.
+func (h *ExercisesHandler) Suggestion(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	catalogID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if catalogID == "" {
+		http.Error(w, "catalog id is required", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	rule := progression.Rule(strings.ToLower(strings.TrimSpace(q.Get("rule"))))
+	if rule == "" {
+		rule = progression.RuleLinear
+	}
+	cfg := progression.DefaultConfig(rule)
+	for key, dst := range map[string]*int{"targetReps": &cfg.TargetReps, "minReps": &cfg.MinReps, "maxReps": &cfg.MaxReps} {
+		if v := q.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, "invalid "+key, http.StatusBadRequest)
+				return
+			}
+			*dst = n
+		}
+	}
+	if v := q.Get("incrementKg"); v != "" {
+		inc, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "invalid incrementKg", http.StatusBadRequest)
+			return
+		}
+		cfg.IncrementKg = inc
+	}
+	if err := cfg.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry, err := h.Catalog.GetCatalogEntry(r.Context(), catalogID)
+	if err != nil {
+		log.Printf("suggestion catalog error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.NotFound(w, r)
+		return
+	}
+	settings, err := h.Settings.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("suggestion settings error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	var entryBase float64
+	if entry.BaseWeightKg != nil {
+		entryBase = *entry.BaseWeightKg
+	}
+	cfg.BaseKg = loadBase(entry.Equipment, entryBase, settings)
+	cfg.StepKg = settings.StepKg()
+
+	stats, _, err := h.Catalog.GetExerciseStats(r.Context(), catalogID, uid, suggestionHistoryDays, 0, progression.FormulaEpley)
+	if err != nil {
+		log.Printf("suggestion history error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	history := make([]progression.Session, 0, len(stats.History))
+	for _, day := range stats.History {
+		date, _ := time.Parse("2006-01-02", day.WorkoutDate)
+		sess := progression.Session{Date: date}
+		for _, s := range day.Sets {
+			if !s.IsWarmup {
+				sess.Sets = append(sess.Sets, progression.Set{Reps: s.Reps, WeightKg: s.WeightKg})
+			}
+		}
+		history = append(history, sess)
+	}
+	suggestion, err := progression.Suggest(history, cfg)
+	if errors.Is(err, progression.ErrNoHistory) {
+		writeJSON(w, http.StatusOK, map[string]any{"catalogId": catalogID, "suggestion": nil})
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"catalogId": catalogID, "suggestion": suggestion})
+}
+
+// GenerateWarmups returns a warmup ramp toward ?workingWeight= (kg) for one
+// of the user's exercises, or inserts it ahead of the exercise's sets with
+// ?create=true. Loads round to the base from loadBase plus whole steps of the
+// user's smallest increment.
+func (h *ExercisesHandler) GenerateWarmups(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	working, err := strconv.ParseFloat(r.URL.Query().Get("workingWeight"), 64)
+	if err != nil || working <= 0 {
+		http.Error(w, "workingWeight must be > 0", http.StatusBadRequest)
+		return
+	}
+	eq, err := h.Exercises.Equipment(r.Context(), uid, id)
+	if err != nil {
+		log.Printf("warmup equipment error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if eq == nil {
+		http.NotFound(w, r)
+		return
+	}
+	settings, err := h.Settings.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("warmup settings error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	base := loadBase(eq.Equipment, eq.BaseWeightKg, settings)
+	warmups := progression.Warmups(working, base, settings.StepKg())
+	resp := map[string]any{
+		"exerciseId":      id,
+		"workingWeightKg": working,
+		"baseWeightKg":    base,
+		"warmups":         warmups,
+	}
+	if r.URL.Query().Get("create") != "true" {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	created, err := h.Sets.InsertWarmups(r.Context(), uid, id, warmups)
+	if writeQuotaError(w, err) {
+		return
+	}
+	if err != nil {
+		log.Printf("warmup insert error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if created == nil {
+		http.NotFound(w, r)
+		return
+	}
+	resp["sets"] = created
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// loadBase is the lightest load of an exercise: the catalog base weight, or
+// the user's bar weight for barbell exercises without one.
+func loadBase(equipment string, baseWeightKg float64, settings store.UserSettings) float64 {
+	if baseWeightKg <= 0 && strings.EqualFold(equipment, "barbell") {
+		return settings.BarWeightKg
+	}
+	return baseWeightKg
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+// FriendsHandler manages friend requests from both sides and serves the
+// activity feed of friends' shared workout days.
+type FriendsHandler struct {
+	Friends FriendsStore
+	Feed    FeedStore
+	Users   UsersStore
+}
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+)
+
+type friendRequest struct {
+	Email string `json:"email"`
+}
+
+// List returns the current user's friends and pending requests both ways.
+func (h *FriendsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.Friends.List(r.Context(), uid, email)
+	if err != nil {
+		log.Printf("list friends error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Request sends a friend request by email, or accepts the one that user
+// already sent.
+func (h *FriendsHandler) Request(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	var req friendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !strings.Contains(req.Email, "@") {
+		http.Error(w, "valid email is required", http.StatusBadRequest)
+		return
+	}
+	f, err := h.Friends.Request(r.Context(), uid, email, req.Email)
+	if err != nil {
+		if errors.Is(err, store.ErrSelfFriend) {
+			http.Error(w, "cannot befriend yourself", http.StatusBadRequest)
+			return
+		}
+		log.Printf("friend request error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, f)
+}
+
+// Accept accepts a request addressed to the current user's email.
+func (h *FriendsHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	f, err := h.Friends.Accept(r.Context(), chi.URLParam(r, "id"), uid, email)
+	if err != nil {
+		log.Printf("accept friend error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if f == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
+
+// Remove unfriends, or cancels or declines a request.
+func (h *FriendsHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	uid, email, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	removed, err := h.Friends.Remove(r.Context(), chi.URLParam(r, "id"), uid, email)
+	if err != nil {
+		log.Printf("remove friend error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListFeed returns friends' recent shared workout days with their PRs, newest
+// first. Query: before (YYYY-MM-DD, for the next page), limit (default 20,
+// max 100). A failed refresh is logged and the feed served as last built.
+func (h *FriendsHandler) ListFeed(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	limit := defaultFeedLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxFeedLimit {
+			http.Error(w, "limit must be 1-100", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	var before *time.Time
+	if v := q.Get("before"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "invalid before", http.StatusBadRequest)
+			return
+		}
+		before = &t
+	}
+	if err := h.Feed.Refresh(r.Context()); err != nil {
+		log.Printf("feed refresh error: %v", err)
+	}
+	items, err := h.Feed.List(r.Context(), uid, before, limit)
+	if err != nil {
+		log.Printf("feed error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *FriendsHandler) currentUser(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", "", false
+	}
+	u, err := h.Users.ByID(r.Context(), uid)
+	if err != nil {
+		log.Printf("friends user lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return "", "", false
+	}
+	if u == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", "", false
+	}
+	return uid, strings.ToLower(u.Email), true
+}
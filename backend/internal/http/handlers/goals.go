@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+const maxGoalNote = 500
+
+type GoalsHandler struct {
+	Goals GoalsStore
+}
+
+type goalRequest struct {
+	CatalogID  string   `json:"catalogId"`
+	Metric     string   `json:"metric"` // e1rm, weight or reps
+	Target     float64  `json:"target"`
+	AtWeightKg *float64 `json:"atWeightKg"` // reps goals only
+	Formula    string   `json:"formula"`    // e1rm goals only, default epley
+	TargetDate string   `json:"targetDate"` // YYYY-MM-DD, optional
+	Note       *string  `json:"note"`
+}
+
+func (req goalRequest) params() (store.GoalParams, error) {
+	p := store.GoalParams{CatalogID: strings.TrimSpace(req.CatalogID), Metric: req.Metric, Target: req.Target, AtWeightKg: req.AtWeightKg}
+	if p.CatalogID == "" {
+		return p, errors.New("catalogId is required")
+	}
+	switch req.Metric {
+	case store.GoalE1RM, store.GoalWeight:
+		if req.Target <= 0 || req.Target > 1000 {
+			return p, errors.New("target must be between 0 and 1000 kg")
+		}
+	case store.GoalReps:
+		if req.Target < 1 || req.Target > 1000 || req.Target != float64(int(req.Target)) {
+			return p, errors.New("target must be a whole number of reps from 1 to 1000")
+		}
+	default:
+		return p, errors.New("metric must be e1rm, weight or reps")
+	}
+	if req.AtWeightKg != nil && (req.Metric != store.GoalReps || *req.AtWeightKg < 0 || *req.AtWeightKg > 1000) {
+		return p, errors.New("atWeightKg is only for reps goals, between 0 and 1000 kg")
+	}
+	formula, err := progression.ParseFormula(req.Formula)
+	if err != nil {
+		return p, err
+	}
+	p.Formula = formula
+	if req.TargetDate != "" {
+		d, err := time.Parse("2006-01-02", req.TargetDate)
+		if err != nil {
+			return p, errors.New("invalid targetDate")
+		}
+		p.TargetDate = &d
+	}
+	if req.Note != nil {
+		if note := strings.TrimSpace(*req.Note); note == "" {
+			p.Note = nil
+		} else if len(note) > maxGoalNote {
+			return p, errors.New("note is too long")
+		} else {
+			p.Note = &note
+		}
+	}
+	return p, nil
+}
+
+// List returns the user's goals with their progress.
+func (h *GoalsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	goals, err := h.Goals.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("goals list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": goals})
+}
+
+func (h *GoalsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	goal, err := h.Goals.Get(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("goal get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if goal == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+func (h *GoalsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req goalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, err := req.params()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	goal, err := h.Goals.Create(r.Context(), uid, p)
+	if err != nil {
+		log.Printf("goal create error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if goal == nil {
+		http.Error(w, "unknown catalogId", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, goal)
+}
+
+// Update replaces a goal's definition.
+func (h *GoalsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req goalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, err := req.params()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	goal, err := h.Goals.Update(r.Context(), uid, chi.URLParam(r, "id"), p)
+	if err != nil {
+		log.Printf("goal update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if goal == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+func (h *GoalsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Goals.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("goal delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+func TestGoalsCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		unknown bool
+		status  int
+	}{
+		{name: "e1rm", body: `{"catalogId":"c1","metric":"e1rm","target":140,"formula":"brzycki","targetDate":"2026-06-01"}`, status: http.StatusCreated},
+		{name: "reps at weight", body: `{"catalogId":"c1","metric":"reps","target":10,"atWeightKg":60}`, status: http.StatusCreated},
+		{name: "fractional reps", body: `{"catalogId":"c1","metric":"reps","target":7.5}`, status: http.StatusBadRequest},
+		{name: "atWeightKg on weight goal", body: `{"catalogId":"c1","metric":"weight","target":100,"atWeightKg":60}`, status: http.StatusBadRequest},
+		{name: "unknown metric", body: `{"catalogId":"c1","metric":"volume","target":100}`, status: http.StatusBadRequest},
+		{name: "bad date", body: `{"catalogId":"c1","metric":"weight","target":100,"targetDate":"June"}`, status: http.StatusBadRequest},
+		{name: "unknown exercise", body: `{"catalogId":"nope","metric":"weight","target":100}`, unknown: true, status: http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			goals := &mocks.GoalsStore{
+				CreateFunc: func(_ context.Context, _ string, p store.GoalParams) (*store.Goal, error) {
+					if tc.unknown {
+						return nil, nil
+					}
+					if p.Metric == store.GoalE1RM && p.Formula != progression.FormulaBrzycki {
+						t.Errorf("formula = %q", p.Formula)
+					}
+					return &store.Goal{CatalogID: p.CatalogID, Metric: p.Metric, Target: p.Target}, nil
+				},
+			}
+			h := &handlers.GoalsHandler{Goals: goals}
+			w := httptest.NewRecorder()
+			h.Create(w, newRequest(http.MethodPost, "/api/goals", tc.body, "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/store"
+)
+
+// Guest signs in as a new account without credentials. Everything a guest
+// logs is kept when they later claim the account.
+func (h *AuthHandler) Guest(w http.ResponseWriter, r *http.Request) {
+	if !h.GuestMode {
+		http.NotFound(w, r)
+		return
+	}
+	u, err := h.Users.CreateGuest(r.Context())
+	if err != nil {
+		log.Printf("create guest error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.startSession(w, r, u.ID); err != nil {
+		log.Printf("start session error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newAuthResponse(u))
+}
+
+// Claim gives the caller's guest account an email and password. The user id
+// stays the same, so the current session and all data carry over.
+func (h *AuthHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || len(req.Password) < 6 {
+		http.Error(w, "invalid email or password", http.StatusBadRequest)
+		return
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	u, err := h.Users.Claim(r.Context(), uid, req.Email, hash)
+	if errors.Is(err, store.ErrEmailTaken) {
+		http.Error(w, "email already in use", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("claim guest error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if u == nil {
+		http.Error(w, "account is not a guest", http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAuthResponse(u))
+}
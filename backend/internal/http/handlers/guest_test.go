@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/store"
+)
+
+func TestClaimGuest(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		user   *models.User
+		err    error
+		status int
+	}{
+		{name: "short password", body: `{"email":"a@example.com","password":"abc"}`, status: http.StatusBadRequest},
+		{name: "not a guest", body: `{"email":"a@example.com","password":"hunter22"}`, status: http.StatusConflict},
+		{name: "email taken", body: `{"email":"a@example.com","password":"hunter22"}`, err: store.ErrEmailTaken, status: http.StatusConflict},
+		{name: "claimed", body: `{"email":" a@example.com ","password":"hunter22"}`, user: &models.User{ID: "user-1", Email: "a@example.com"}, status: http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &handlers.AuthHandler{Users: &mocks.UsersStore{
+				ClaimFunc: func(_ context.Context, id, email, _ string) (*models.User, error) {
+					if id != "user-1" || email != "a@example.com" {
+						t.Errorf("Claim(%q, %q)", id, email)
+					}
+					return tc.user, tc.err
+				},
+			}}
+			rec := httptest.NewRecorder()
+			h.Claim(rec, newRequest(http.MethodPost, "/api/auth/claim", tc.body, "user-1", nil))
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.status, rec.Body)
+			}
+			if tc.status == http.StatusOK && !strings.Contains(rec.Body.String(), `"email":"a@example.com"`) {
+				t.Errorf("body = %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestGuestHidesPlaceholderEmail(t *testing.T) {
+	h := &handlers.AuthHandler{
+		JWTSecret: "test-secret",
+		GuestMode: true,
+		Users: &mocks.UsersStore{
+			CreateGuestFunc: func(context.Context) (*models.User, error) {
+				return &models.User{ID: "guest-1", Email: "guest-x@guest.invalid", IsGuest: true}, nil
+			},
+		},
+	}
+	rec := httptest.NewRecorder()
+	h.Guest(rec, newRequest(http.MethodPost, "/api/auth/guest", "", "", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "guest.invalid") || !strings.Contains(body, `"guest":true`) {
+		t.Errorf("body = %s", body)
+	}
+	if rec.Header().Get("Set-Cookie") == "" {
+		t.Error("no session cookie")
+	}
+}
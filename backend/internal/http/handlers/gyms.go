@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+type GymsHandler struct {
+	Gyms GymsStore
+}
+
+type gymProfileRequest struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"` // home or commercial
+	Equipment []string `json:"equipment"`
+}
+
+func (req gymProfileRequest) params() (store.GymProfileParams, string) {
+	p := store.GymProfileParams{Name: strings.TrimSpace(req.Name), Kind: req.Kind}
+	if p.Name == "" {
+		return p, "name is required"
+	}
+	if p.Kind != store.GymHome && p.Kind != store.GymCommercial {
+		return p, "kind must be home or commercial"
+	}
+	for _, e := range req.Equipment {
+		if e = strings.TrimSpace(e); e != "" {
+			p.Equipment = append(p.Equipment, e)
+		}
+	}
+	return p, ""
+}
+
+func (h *GymsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.Gyms.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("gym list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *GymsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	gym, err := h.Gyms.Get(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("gym get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if gym == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, gym)
+}
+
+func (h *GymsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req gymProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, msg := req.params()
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	gym, err := h.Gyms.Create(r.Context(), uid, p)
+	if err != nil {
+		writeGymError(w, "gym create", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, gym)
+}
+
+// Update replaces the profile's name, kind and equipment list.
+func (h *GymsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req gymProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, msg := req.params()
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	gym, err := h.Gyms.Update(r.Context(), uid, chi.URLParam(r, "id"), p)
+	if err != nil {
+		writeGymError(w, "gym update", err)
+		return
+	}
+	if gym == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, gym)
+}
+
+func (h *GymsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Gyms.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("gym delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeGymError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, store.ErrUnknownEquipment):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrGymNameTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("%s error: %v", op, err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+// newRequest builds a request as the router would hand it to a handler: with
+// the user from the auth middleware and the chi URL params filled in.
+func newRequest(method, target, body, userID string, params map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := r.Context()
+	if userID != "" {
+		ctx = middleware.WithUserID(ctx, userID)
+	}
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	return r.WithContext(ctx)
+}
+
+func TestGymsCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{name: "invalid json", body: `{`, status: http.StatusBadRequest},
+		{name: "missing name", body: `{"name":" ","kind":"home"}`, status: http.StatusBadRequest},
+		{name: "bad kind", body: `{"name":"Garage","kind":"outdoor"}`, status: http.StatusBadRequest},
+		{name: "unknown equipment", body: `{"name":"Garage","kind":"home","equipment":["Anvil"]}`, err: store.ErrUnknownEquipment, status: http.StatusBadRequest},
+		{name: "name taken", body: `{"name":"Garage","kind":"home"}`, err: store.ErrGymNameTaken, status: http.StatusConflict},
+		{name: "created", body: `{"name":" Garage ","kind":"home","equipment":["Barbell",""]}`, status: http.StatusCreated},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got store.GymProfileParams
+			gyms := &mocks.GymsStore{
+				CreateFunc: func(_ context.Context, userID string, p store.GymProfileParams) (*store.GymProfile, error) {
+					if userID != "user-1" {
+						t.Errorf("userID = %q, want user-1", userID)
+					}
+					got = p
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &store.GymProfile{ID: "gym-1", Name: p.Name, Kind: p.Kind, Equipment: p.Equipment}, nil
+				},
+			}
+			h := &handlers.GymsHandler{Gyms: gyms}
+			w := httptest.NewRecorder()
+			h.Create(w, newRequest(http.MethodPost, "/api/gyms", tc.body, "user-1", nil))
+
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+			if tc.status == http.StatusCreated {
+				if got.Name != "Garage" || len(got.Equipment) != 1 || got.Equipment[0] != "Barbell" {
+					t.Fatalf("store got %+v, want trimmed name and blank equipment dropped", got)
+				}
+			}
+		})
+	}
+}
+
+func TestGymsRequiresUser(t *testing.T) {
+	h := &handlers.GymsHandler{Gyms: &mocks.GymsStore{}}
+	w := httptest.NewRecorder()
+	h.List(w, newRequest(http.MethodGet, "/api/gyms", "", "", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestGymsDelete(t *testing.T) {
+	gyms := &mocks.GymsStore{
+		DeleteFunc: func(_ context.Context, _ string, id string) (bool, error) {
+			return id == "gym-1", nil
+		},
+	}
+	h := &handlers.GymsHandler{Gyms: gyms}
+
+	w := httptest.NewRecorder()
+	h.Delete(w, newRequest(http.MethodDelete, "/api/gyms/gym-1", "", "user-1", map[string]string{"id": "gym-1"}))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.Delete(w, newRequest(http.MethodDelete, "/api/gyms/other", "", "user-1", map[string]string{"id": "other"}))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
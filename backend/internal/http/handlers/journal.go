@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+type JournalHandler struct {
+	Journal JournalStore
+}
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+// DayHistory lists the latest changes to a day and everything on it, newest
+// first. Query: limit (default 50, max 200).
+func (h *JournalHandler) DayHistory(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			http.Error(w, "limit must be 1-200", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	items, err := h.Journal.DayHistory(r.Context(), uid, chi.URLParam(r, "dayId"), limit)
+	if err != nil {
+		log.Printf("day history error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Undo reverts the user's most recent change and returns the journal entries
+// it rolled back.
+func (h *JournalHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.Journal.Undo(r.Context(), uid)
+	if errors.Is(err, store.ErrNothingToUndo) || errors.Is(err, store.ErrUndoConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("undo error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+type LeaderboardsHandler struct {
+	Leaderboards LeaderboardsStore
+}
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 100
+	maxDisplayNameLen       = 40
+)
+
+type updateLeaderboardProfileRequest struct {
+	OptedIn      *bool `json:"optedIn"`
+	HideIdentity *bool `json:"hideIdentity"`
+	// DisplayName of "" clears the name.
+	DisplayName *string `json:"displayName"`
+}
+
+// Get ranks the users who opted in by best estimated 1RM over bodyweight on
+// the catalog exercise. Query: formula (epley|brzycki|lombardi), limit
+// (default 50, max 100).
+func (h *LeaderboardsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	limit := defaultLeaderboardLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLeaderboardLimit {
+			http.Error(w, "limit must be 1-100", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	formula, err := progression.ParseFormula(q.Get("formula"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	board, err := h.Leaderboards.Leaderboard(r.Context(), chi.URLParam(r, "catalogId"), uid, formula, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("leaderboard error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, board)
+}
+
+// Profile returns the caller's leaderboard consent and privacy settings.
+func (h *LeaderboardsHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	profile, err := h.Leaderboards.Profile(r.Context(), uid)
+	if err != nil {
+		log.Printf("leaderboard profile error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// UpdateProfile changes only the fields present in the body. Showing your
+// identity needs a display name; the account email is never shown.
+func (h *LeaderboardsHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req updateLeaderboardProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.DisplayName != nil {
+		name := strings.TrimSpace(*req.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLen || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+			http.Error(w, "displayName must be at most 40 printable characters", http.StatusBadRequest)
+			return
+		}
+		req.DisplayName = &name
+	}
+	profile, err := h.Leaderboards.UpdateProfile(r.Context(), uid, store.UpdateLeaderboardProfileParams{
+		OptedIn:      req.OptedIn,
+		HideIdentity: req.HideIdentity,
+		DisplayName:  req.DisplayName,
+	})
+	if errors.Is(err, store.ErrLeaderboardNameRequired) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("leaderboard profile update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
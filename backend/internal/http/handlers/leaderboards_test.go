@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+func TestLeaderboardsGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		err     error
+		status  int
+		formula progression.Formula
+		limit   int
+	}{
+		{name: "defaults", target: "/api/leaderboards/cat-1", status: http.StatusOK, formula: progression.FormulaEpley, limit: 50},
+		{name: "formula and limit", target: "/api/leaderboards/cat-1?formula=brzycki&limit=10", status: http.StatusOK, formula: progression.FormulaBrzycki, limit: 10},
+		{name: "limit too high", target: "/api/leaderboards/cat-1?limit=101", status: http.StatusBadRequest},
+		{name: "unknown formula", target: "/api/leaderboards/cat-1?formula=guess", status: http.StatusBadRequest},
+		{name: "unknown entry", target: "/api/leaderboards/cat-1", err: sql.ErrNoRows, status: http.StatusNotFound, formula: progression.FormulaEpley, limit: 50},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			boards := &mocks.LeaderboardsStore{
+				LeaderboardFunc: func(_ context.Context, catalogID, viewerID string, formula progression.Formula, limit int) (*store.Leaderboard, error) {
+					if catalogID != "cat-1" || viewerID != "user-1" {
+						t.Errorf("Leaderboard(%q, %q), want cat-1 for user-1", catalogID, viewerID)
+					}
+					if formula != tc.formula || limit != tc.limit {
+						t.Errorf("formula, limit = %q, %d, want %q, %d", formula, limit, tc.formula, tc.limit)
+					}
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &store.Leaderboard{CatalogID: catalogID, Formula: formula, Entries: []store.LeaderboardEntry{}}, nil
+				},
+			}
+			h := &handlers.LeaderboardsHandler{Leaderboards: boards}
+			w := httptest.NewRecorder()
+			h.Get(w, newRequest(http.MethodGet, tc.target, "", "user-1", map[string]string{"catalogId": "cat-1"}))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLeaderboardsUpdateProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		want   string // DisplayName passed to the store, "<nil>" for none
+	}{
+		{name: "invalid json", body: `{`, status: http.StatusBadRequest},
+		{name: "name too long", body: `{"displayName":"` + strings.Repeat("x", 41) + `"}`, status: http.StatusBadRequest},
+		{name: "control character", body: `{"displayName":"a\u0007b"}`, status: http.StatusBadRequest},
+		{name: "show identity without a name", body: `{"hideIdentity":false}`, err: store.ErrLeaderboardNameRequired, status: http.StatusBadRequest, want: "<nil>"},
+		{name: "opt in", body: `{"optedIn":true}`, status: http.StatusOK, want: "<nil>"},
+		{name: "name trimmed", body: `{"displayName":"  Iron Mike "}`, status: http.StatusOK, want: "Iron Mike"},
+		{name: "name cleared", body: `{"displayName":"   "}`, status: http.StatusOK, want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			boards := &mocks.LeaderboardsStore{
+				UpdateProfileFunc: func(_ context.Context, userID string, p store.UpdateLeaderboardProfileParams) (store.LeaderboardProfile, error) {
+					got := "<nil>"
+					if p.DisplayName != nil {
+						got = *p.DisplayName
+					}
+					if got != tc.want {
+						t.Errorf("DisplayName = %q, want %q", got, tc.want)
+					}
+					if tc.err != nil {
+						return store.LeaderboardProfile{}, tc.err
+					}
+					return store.LeaderboardProfile{HideIdentity: true}, nil
+				},
+			}
+			h := &handlers.LeaderboardsHandler{Leaderboards: boards}
+			w := httptest.NewRecorder()
+			h.UpdateProfile(w, newRequest(http.MethodPatch, "/api/settings/leaderboard", tc.body, "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+		})
+	}
+}
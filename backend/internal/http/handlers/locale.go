@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxLocales bounds how many Accept-Language tags are used for lookups.
+const maxLocales = 8
+
+var localeTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// normalizeLocale lowercases a BCP 47 tag ("pt_BR" -> "pt-br"); ok is false
+// when it isn't one.
+func normalizeLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	return tag, localeTag.MatchString(tag)
+}
+
+// requestLocales returns the Accept-Language tags that should be translated
+// to, most preferred first, each followed by its base language. The catalog
+// itself is English, so anything ranked below English is dropped.
+func requestLocales(r *http.Request) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag, ok := normalizeLocale(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	var out []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if !seen[tag] && len(out) < maxLocales {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	for _, t := range tags {
+		base, _, _ := strings.Cut(t.tag, "-")
+		if base == "en" {
+			break
+		}
+		add(t.tag)
+		add(base)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"exercise-tracker/internal/store"
+)
+
+// GetMaintenance reports whether maintenance mode is on.
+func (h *AdminHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if h.Maintenance == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Maintenance.State())
+}
+
+// SetMaintenance turns maintenance mode on or off. Body: {enabled, reason?}.
+func (h *AdminHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if h.Maintenance == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var req struct {
+		Enabled *bool  `json:"enabled"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Enabled == nil {
+		http.Error(w, "enabled is required", http.StatusBadRequest)
+		return
+	}
+	before := h.Maintenance.State()
+	after := h.Maintenance.Set(*req.Enabled, strings.TrimSpace(req.Reason))
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditMaintenance,
+		EntityType: "server",
+		Before:     before,
+		After:      after,
+	})
+	writeJSON(w, http.StatusOK, after)
+}
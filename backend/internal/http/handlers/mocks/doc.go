@@ -0,0 +1,6 @@
+// Package mocks has fakes for the store interfaces handlers depend on, for
+// handler tests that should not need a database. Set the XxxFunc fields a
+// test expects to be called; any other method panics.
+package mocks
+
+//go:generate go run ./gen
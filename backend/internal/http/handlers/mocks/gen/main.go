@@ -0,0 +1,172 @@
+// Command gen writes mocks.go: one struct per store interface declared in
+// handlers/stores.go, with a XxxFunc field per method. Calling a method whose
+// func is unset panics, so a test notices when a handler reaches a store it
+// did not expect.
+//
+// Run it through go generate from the mocks directory.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	source   = "../stores.go"
+	output   = "mocks.go"
+	handlers = "exercise-tracker/internal/http/handlers"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	var names []string
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			it, ok := ts.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			names = append(names, ts.Name.Name)
+			writeMock(&body, fset, ts.Name.Name, it)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gen from %s; DO NOT EDIT.\n\n", strings.TrimPrefix(source, "../"))
+	fmt.Fprintf(&out, "package mocks\n\n")
+	writeImports(&out, file, body.String())
+	out.WriteString("var (\n")
+	for _, n := range names {
+		fmt.Fprintf(&out, "\t_ handlers.%s = (*%s)(nil)\n", n, n)
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("format: %v\n%s", err, out.Bytes())
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeMock(w *bytes.Buffer, fset *token.FileSet, name string, it *ast.InterfaceType) {
+	type method struct {
+		name, params, args, results string
+		returns                     bool
+	}
+	var methods []method
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			continue
+		}
+		var params, args []string
+		for i, p := range ft.Params.List {
+			typ := expr(fset, p.Type)
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent("p" + strconv.Itoa(i))}
+			}
+			for _, n := range names {
+				params = append(params, n.Name+" "+typ)
+				args = append(args, n.Name)
+			}
+		}
+		var results []string
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				results = append(results, expr(fset, r.Type))
+			}
+		}
+		res := strings.Join(results, ", ")
+		if len(results) > 1 {
+			res = "(" + res + ")"
+		}
+		methods = append(methods, method{
+			name:    m.Names[0].Name,
+			params:  strings.Join(params, ", "),
+			args:    strings.Join(args, ", "),
+			results: res,
+			returns: len(results) > 0,
+		})
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].name < methods[j].name })
+
+	fmt.Fprintf(w, "\n// %s is a fake handlers.%s.\n", name, name)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t%sFunc func(%s) %s\n", m.name, m.params, m.results)
+	}
+	w.WriteString("}\n")
+	for _, m := range methods {
+		fmt.Fprintf(w, "\nfunc (m *%s) %s(%s) %s {\n", name, m.name, m.params, m.results)
+		fmt.Fprintf(w, "\tif m.%sFunc == nil {\n", m.name)
+		fmt.Fprintf(w, "\t\tpanic(\"mocks: unexpected call to %s.%s\")\n", name, m.name)
+		w.WriteString("\t}\n")
+		if m.returns {
+			fmt.Fprintf(w, "\treturn m.%sFunc(%s)\n", m.name, m.args)
+		} else {
+			fmt.Fprintf(w, "\tm.%sFunc(%s)\n", m.name, m.args)
+		}
+		w.WriteString("}\n")
+	}
+}
+
+// writeImports keeps the imports of the source file that the generated code
+// refers to, plus the handlers package for the interface assertions.
+func writeImports(w *bytes.Buffer, file *ast.File, body string) {
+	paths := []string{handlers}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		pkg := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			pkg = imp.Name.Name
+		}
+		if strings.Contains(body, pkg+".") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	w.WriteString("import (\n")
+	for _, p := range paths {
+		if !strings.Contains(p, ".") && !strings.HasPrefix(p, "exercise-tracker") {
+			fmt.Fprintf(w, "\t%q\n", p)
+		}
+	}
+	w.WriteString("\n")
+	for _, p := range paths {
+		if strings.HasPrefix(p, "exercise-tracker") {
+			fmt.Fprintf(w, "\t%q\n", p)
+		}
+	}
+	w.WriteString(")\n\n")
+}
+
+func expr(fset *token.FileSet, e ast.Expr) string {
+	var b bytes.Buffer
+	_ = printer.Fprint(&b, fset, e)
+	return b.String()
+}
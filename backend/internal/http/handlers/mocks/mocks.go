@@ -0,0 +1,1661 @@
+// Code generated by gen from stores.go; DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"exercise-tracker/internal/biometrics"
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+var (
+	_ handlers.UsersStore        = (*UsersStore)(nil)
+	_ handlers.DaysStore         = (*DaysStore)(nil)
+	_ handlers.CatalogStore      = (*CatalogStore)(nil)
+	_ handlers.AuditStore        = (*AuditStore)(nil)
+	_ handlers.GymsStore         = (*GymsStore)(nil)
+	_ handlers.BodyweightStore   = (*BodyweightStore)(nil)
+	_ handlers.SettingsStore     = (*SettingsStore)(nil)
+	_ handlers.SetsStore         = (*SetsStore)(nil)
+	_ handlers.ExercisesStore    = (*ExercisesStore)(nil)
+	_ handlers.ProgramsStore     = (*ProgramsStore)(nil)
+	_ handlers.AnalyticsStore    = (*AnalyticsStore)(nil)
+	_ handlers.APIKeysStore      = (*APIKeysStore)(nil)
+	_ handlers.SessionsStore     = (*SessionsStore)(nil)
+	_ handlers.TwoFactorStore    = (*TwoFactorStore)(nil)
+	_ handlers.CoachingStore     = (*CoachingStore)(nil)
+	_ handlers.CommentsStore     = (*CommentsStore)(nil)
+	_ handlers.RemindersStore    = (*RemindersStore)(nil)
+	_ handlers.UsageStore        = (*UsageStore)(nil)
+	_ handlers.SaveStore         = (*SaveStore)(nil)
+	_ handlers.JournalStore      = (*JournalStore)(nil)
+	_ handlers.TemplatesStore    = (*TemplatesStore)(nil)
+	_ handlers.LeaderboardsStore = (*LeaderboardsStore)(nil)
+	_ handlers.FriendsStore      = (*FriendsStore)(nil)
+	_ handlers.FeedStore         = (*FeedStore)(nil)
+	_ handlers.ChallengesStore   = (*ChallengesStore)(nil)
+	_ handlers.AchievementsStore = (*AchievementsStore)(nil)
+	_ handlers.RecapsStore       = (*RecapsStore)(nil)
+	_ handlers.BiometricsStore   = (*BiometricsStore)(nil)
+	_ handlers.NutritionStore    = (*NutritionStore)(nil)
+	_ handlers.CheckinsStore     = (*CheckinsStore)(nil)
+	_ handlers.GoalsStore        = (*GoalsStore)(nil)
+)
+
+// UsersStore is a fake handlers.UsersStore.
+type UsersStore struct {
+	ByEmailFunc            func(ctx context.Context, email string) (*models.User, error)
+	ByIDFunc               func(ctx context.Context, id string) (*models.User, error)
+	CancelDeletionFunc     func(ctx context.Context, id string) error
+	ClaimFunc              func(ctx context.Context, id string, email string, passwordHash string) (*models.User, error)
+	CreateFunc             func(ctx context.Context, email string, passwordHash string) (*models.User, error)
+	CreateGuestFunc        func(ctx context.Context) (*models.User, error)
+	PurgeFunc              func(ctx context.Context, id string) error
+	ScheduleDeletionFunc   func(ctx context.Context, id string, purgeAfter time.Time) error
+	UpdatePasswordHashFunc func(ctx context.Context, id string, passwordHash string) error
+}
+
+func (m *UsersStore) ByEmail(ctx context.Context, email string) (*models.User, error) {
+	if m.ByEmailFunc == nil {
+		panic("mocks: unexpected call to UsersStore.ByEmail")
+	}
+	return m.ByEmailFunc(ctx, email)
+}
+
+func (m *UsersStore) ByID(ctx context.Context, id string) (*models.User, error) {
+	if m.ByIDFunc == nil {
+		panic("mocks: unexpected call to UsersStore.ByID")
+	}
+	return m.ByIDFunc(ctx, id)
+}
+
+func (m *UsersStore) CancelDeletion(ctx context.Context, id string) error {
+	if m.CancelDeletionFunc == nil {
+		panic("mocks: unexpected call to UsersStore.CancelDeletion")
+	}
+	return m.CancelDeletionFunc(ctx, id)
+}
+
+func (m *UsersStore) Claim(ctx context.Context, id string, email string, passwordHash string) (*models.User, error) {
+	if m.ClaimFunc == nil {
+		panic("mocks: unexpected call to UsersStore.Claim")
+	}
+	return m.ClaimFunc(ctx, id, email, passwordHash)
+}
+
+func (m *UsersStore) Create(ctx context.Context, email string, passwordHash string) (*models.User, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to UsersStore.Create")
+	}
+	return m.CreateFunc(ctx, email, passwordHash)
+}
+
+func (m *UsersStore) CreateGuest(ctx context.Context) (*models.User, error) {
+	if m.CreateGuestFunc == nil {
+		panic("mocks: unexpected call to UsersStore.CreateGuest")
+	}
+	return m.CreateGuestFunc(ctx)
+}
+
+func (m *UsersStore) Purge(ctx context.Context, id string) error {
+	if m.PurgeFunc == nil {
+		panic("mocks: unexpected call to UsersStore.Purge")
+	}
+	return m.PurgeFunc(ctx, id)
+}
+
+func (m *UsersStore) ScheduleDeletion(ctx context.Context, id string, purgeAfter time.Time) error {
+	if m.ScheduleDeletionFunc == nil {
+		panic("mocks: unexpected call to UsersStore.ScheduleDeletion")
+	}
+	return m.ScheduleDeletionFunc(ctx, id, purgeAfter)
+}
+
+func (m *UsersStore) UpdatePasswordHash(ctx context.Context, id string, passwordHash string) error {
+	if m.UpdatePasswordHashFunc == nil {
+		panic("mocks: unexpected call to UsersStore.UpdatePasswordHash")
+	}
+	return m.UpdatePasswordHashFunc(ctx, id, passwordHash)
+}
+
+// DaysStore is a fake handlers.DaysStore.
+type DaysStore struct {
+	ExerciseTimelineFunc func(ctx context.Context, userID string, exerciseID string) ([]models.ExerciseEntry, error)
+	FinishSessionFunc    func(ctx context.Context, userID string, dayID string, at time.Time) (*models.WorkoutDay, error)
+	GetByUserAndDateFunc func(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error)
+	GetForUpdateFunc     func(ctx context.Context, userID string, dayID string) (*models.WorkoutDay, error)
+	GetOrCreateFunc      func(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error)
+	GetWithDetailsFunc   func(ctx context.Context, userID string, dayID string) (*models.DayWithDetails, error)
+	ListWithDetailsFunc  func(ctx context.Context, userID string, dayIDs []string, dates []time.Time) ([]models.DayWithDetails, error)
+	SearchHistoryFunc    func(ctx context.Context, userID string, q string) ([]store.DaySearchResult, error)
+	SessionTimelineFunc  func(ctx context.Context, userID string, dayID string) (*store.SessionTimeline, error)
+	SetNotesFunc         func(ctx context.Context, userID string, dayID string, notes string) (*models.WorkoutDay, error)
+	SetRestDayFunc       func(ctx context.Context, userID string, dayID string, rest bool) (*models.WorkoutDay, error)
+	SetVisibilityFunc    func(ctx context.Context, userID string, dayID string, visibility string) (*models.WorkoutDay, error)
+	StartSessionFunc     func(ctx context.Context, userID string, dayID string, at time.Time) (*models.WorkoutDay, error)
+	WeekSummaryFunc      func(ctx context.Context, userID string, start time.Time) ([]store.DaySummary, error)
+}
+
+func (m *DaysStore) ExerciseTimeline(ctx context.Context, userID string, exerciseID string) ([]models.ExerciseEntry, error) {
+	if m.ExerciseTimelineFunc == nil {
+		panic("mocks: unexpected call to DaysStore.ExerciseTimeline")
+	}
+	return m.ExerciseTimelineFunc(ctx, userID, exerciseID)
+}
+
+func (m *DaysStore) FinishSession(ctx context.Context, userID string, dayID string, at time.Time) (*models.WorkoutDay, error) {
+	if m.FinishSessionFunc == nil {
+		panic("mocks: unexpected call to DaysStore.FinishSession")
+	}
+	return m.FinishSessionFunc(ctx, userID, dayID, at)
+}
+
+func (m *DaysStore) GetByUserAndDate(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error) {
+	if m.GetByUserAndDateFunc == nil {
+		panic("mocks: unexpected call to DaysStore.GetByUserAndDate")
+	}
+	return m.GetByUserAndDateFunc(ctx, userID, date)
+}
+
+func (m *DaysStore) GetForUpdate(ctx context.Context, userID string, dayID string) (*models.WorkoutDay, error) {
+	if m.GetForUpdateFunc == nil {
+		panic("mocks: unexpected call to DaysStore.GetForUpdate")
+	}
+	return m.GetForUpdateFunc(ctx, userID, dayID)
+}
+
+func (m *DaysStore) GetOrCreate(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error) {
+	if m.GetOrCreateFunc == nil {
+		panic("mocks: unexpected call to DaysStore.GetOrCreate")
+	}
+	return m.GetOrCreateFunc(ctx, userID, date)
+}
+
+func (m *DaysStore) GetWithDetails(ctx context.Context, userID string, dayID string) (*models.DayWithDetails, error) {
+	if m.GetWithDetailsFunc == nil {
+		panic("mocks: unexpected call to DaysStore.GetWithDetails")
+	}
+	return m.GetWithDetailsFunc(ctx, userID, dayID)
+}
+
+func (m *DaysStore) ListWithDetails(ctx context.Context, userID string, dayIDs []string, dates []time.Time) ([]models.DayWithDetails, error) {
+	if m.ListWithDetailsFunc == nil {
+		panic("mocks: unexpected call to DaysStore.ListWithDetails")
+	}
+	return m.ListWithDetailsFunc(ctx, userID, dayIDs, dates)
+}
+
+func (m *DaysStore) SearchHistory(ctx context.Context, userID string, q string) ([]store.DaySearchResult, error) {
+	if m.SearchHistoryFunc == nil {
+		panic("mocks: unexpected call to DaysStore.SearchHistory")
+	}
+	return m.SearchHistoryFunc(ctx, userID, q)
+}
+
+func (m *DaysStore) SessionTimeline(ctx context.Context, userID string, dayID string) (*store.SessionTimeline, error) {
+	if m.SessionTimelineFunc == nil {
+		panic("mocks: unexpected call to DaysStore.SessionTimeline")
+	}
+	return m.SessionTimelineFunc(ctx, userID, dayID)
+}
+
+func (m *DaysStore) SetNotes(ctx context.Context, userID string, dayID string, notes string) (*models.WorkoutDay, error) {
+	if m.SetNotesFunc == nil {
+		panic("mocks: unexpected call to DaysStore.SetNotes")
+	}
+	return m.SetNotesFunc(ctx, userID, dayID, notes)
+}
+
+func (m *DaysStore) SetRestDay(ctx context.Context, userID string, dayID string, rest bool) (*models.WorkoutDay, error) {
+	if m.SetRestDayFunc == nil {
+		panic("mocks: unexpected call to DaysStore.SetRestDay")
+	}
+	return m.SetRestDayFunc(ctx, userID, dayID, rest)
+}
+
+func (m *DaysStore) SetVisibility(ctx context.Context, userID string, dayID string, visibility string) (*models.WorkoutDay, error) {
+	if m.SetVisibilityFunc == nil {
+		panic("mocks: unexpected call to DaysStore.SetVisibility")
+	}
+	return m.SetVisibilityFunc(ctx, userID, dayID, visibility)
+}
+
+func (m *DaysStore) StartSession(ctx context.Context, userID string, dayID string, at time.Time) (*models.WorkoutDay, error) {
+	if m.StartSessionFunc == nil {
+		panic("mocks: unexpected call to DaysStore.StartSession")
+	}
+	return m.StartSessionFunc(ctx, userID, dayID, at)
+}
+
+func (m *DaysStore) WeekSummary(ctx context.Context, userID string, start time.Time) ([]store.DaySummary, error) {
+	if m.WeekSummaryFunc == nil {
+		panic("mocks: unexpected call to DaysStore.WeekSummary")
+	}
+	return m.WeekSummaryFunc(ctx, userID, start)
+}
+
+// CatalogStore is a fake handlers.CatalogStore.
+type CatalogStore struct {
+	CacheStatsFunc                  func() store.CatalogCacheStats
+	CatalogIDsWithoutImageFunc      func(ctx context.Context, names []string) (map[string]string, error)
+	CreateCatalogEntryWithImageFunc func(ctx context.Context, entry store.CatalogEntry, imageData []byte, imageMimeType string) (*store.CatalogRecord, error)
+	DeadLinksFunc                   func(ctx context.Context, limit int) ([]store.DeadLink, error)
+	DeleteCatalogEntryFunc          func(ctx context.Context, id string) error
+	DeleteTranslationFunc           func(ctx context.Context, catalogID string, locale string) (bool, error)
+	DuplicateCandidatesFunc         func(ctx context.Context, minSimilarity float64, limit int) ([]store.CatalogDuplicate, error)
+	ExerciseProgressFunc            func(ctx context.Context, catalogID string, userID string, metric string, formula progression.Formula, since *time.Time, maxPoints int) ([]store.ProgressPoint, error)
+	ExportCatalogFunc               func(ctx context.Context, fn func(*store.CatalogRecord) error) error
+	FacetCountsFunc                 func(ctx context.Context, p store.CatalogSearchParams) (*store.CatalogFacetCounts, error)
+	FacetsFunc                      func(ctx context.Context) (store.CatalogFacets, error)
+	GetCatalogEntryFunc             func(ctx context.Context, id string) (*store.CatalogRecord, error)
+	GetCatalogImageFunc             func(ctx context.Context, id string) ([]byte, string, error)
+	GetCatalogThumbnailFunc         func(ctx context.Context, id string) ([]byte, string, error)
+	GetExerciseStatsFunc            func(ctx context.Context, catalogID string, userID string, limit int, offset int, formula progression.Formula) (*store.ExerciseStats, bool, error)
+	LocalizeFunc                    func(ctx context.Context, rec *store.CatalogRecord, locales []string) error
+	MatchExerciseFunc               func(ctx context.Context, userID string, name string, limit int) ([]store.CatalogMatch, error)
+	MergeCatalogEntriesFunc         func(ctx context.Context, sourceID string, targetID string) (*store.CatalogMergeResult, error)
+	MergeMusclesFunc                func(ctx context.Context, source string, target string) (*store.MuscleMergeResult, error)
+	MusclesFunc                     func(ctx context.Context) ([]store.CanonicalMuscle, error)
+	PutCatalogImageFunc             func(ctx context.Context, id string, data []byte, mimeType string) error
+	PutTranslationFunc              func(ctx context.Context, t store.CatalogTranslation) (*store.CatalogTranslation, error)
+	ReviewCatalogEntryFunc          func(ctx context.Context, id string, reviewerID string, status string, feedback *string) (*store.CatalogRecord, error)
+	SearchFunc                      func(ctx context.Context, p store.CatalogSearchParams) (store.CatalogSearchResult, error)
+	SubmissionsFunc                 func(ctx context.Context, userID string, status string) ([]store.CatalogRecord, error)
+	SubmitCatalogEntryFunc          func(ctx context.Context, userID string, entry store.CatalogEntry, imageData []byte, imageMimeType string) (*store.CatalogRecord, error)
+	TranslationsFunc                func(ctx context.Context, catalogID string) ([]store.CatalogTranslation, error)
+	UpdateCatalogEntryFunc          func(ctx context.Context, id string, entry store.CatalogEntry, imageData []byte, imageMimeType string, removeImage bool) error
+	UpsertFunc                      func(ctx context.Context, entries []store.CatalogEntry) (int, error)
+	VersionFunc                     func(ctx context.Context) (int64, error)
+}
+
+func (m *CatalogStore) CacheStats() store.CatalogCacheStats {
+	if m.CacheStatsFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.CacheStats")
+	}
+	return m.CacheStatsFunc()
+}
+
+func (m *CatalogStore) CatalogIDsWithoutImage(ctx context.Context, names []string) (map[string]string, error) {
+	if m.CatalogIDsWithoutImageFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.CatalogIDsWithoutImage")
+	}
+	return m.CatalogIDsWithoutImageFunc(ctx, names)
+}
+
+func (m *CatalogStore) CreateCatalogEntryWithImage(ctx context.Context, entry store.CatalogEntry, imageData []byte, imageMimeType string) (*store.CatalogRecord, error) {
+	if m.CreateCatalogEntryWithImageFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.CreateCatalogEntryWithImage")
+	}
+	return m.CreateCatalogEntryWithImageFunc(ctx, entry, imageData, imageMimeType)
+}
+
+func (m *CatalogStore) DeadLinks(ctx context.Context, limit int) ([]store.DeadLink, error) {
+	if m.DeadLinksFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.DeadLinks")
+	}
+	return m.DeadLinksFunc(ctx, limit)
+}
+
+func (m *CatalogStore) DeleteCatalogEntry(ctx context.Context, id string) error {
+	if m.DeleteCatalogEntryFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.DeleteCatalogEntry")
+	}
+	return m.DeleteCatalogEntryFunc(ctx, id)
+}
+
+func (m *CatalogStore) DeleteTranslation(ctx context.Context, catalogID string, locale string) (bool, error) {
+	if m.DeleteTranslationFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.DeleteTranslation")
+	}
+	return m.DeleteTranslationFunc(ctx, catalogID, locale)
+}
+
+func (m *CatalogStore) DuplicateCandidates(ctx context.Context, minSimilarity float64, limit int) ([]store.CatalogDuplicate, error) {
+	if m.DuplicateCandidatesFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.DuplicateCandidates")
+	}
+	return m.DuplicateCandidatesFunc(ctx, minSimilarity, limit)
+}
+
+func (m *CatalogStore) ExerciseProgress(ctx context.Context, catalogID string, userID string, metric string, formula progression.Formula, since *time.Time, maxPoints int) ([]store.ProgressPoint, error) {
+	if m.ExerciseProgressFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.ExerciseProgress")
+	}
+	return m.ExerciseProgressFunc(ctx, catalogID, userID, metric, formula, since, maxPoints)
+}
+
+func (m *CatalogStore) ExportCatalog(ctx context.Context, fn func(*store.CatalogRecord) error) error {
+	if m.ExportCatalogFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.ExportCatalog")
+	}
+	return m.ExportCatalogFunc(ctx, fn)
+}
+
+func (m *CatalogStore) FacetCounts(ctx context.Context, p store.CatalogSearchParams) (*store.CatalogFacetCounts, error) {
+	if m.FacetCountsFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.FacetCounts")
+	}
+	return m.FacetCountsFunc(ctx, p)
+}
+
+func (m *CatalogStore) Facets(ctx context.Context) (store.CatalogFacets, error) {
+	if m.FacetsFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Facets")
+	}
+	return m.FacetsFunc(ctx)
+}
+
+func (m *CatalogStore) GetCatalogEntry(ctx context.Context, id string) (*store.CatalogRecord, error) {
+	if m.GetCatalogEntryFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.GetCatalogEntry")
+	}
+	return m.GetCatalogEntryFunc(ctx, id)
+}
+
+func (m *CatalogStore) GetCatalogImage(ctx context.Context, id string) ([]byte, string, error) {
+	if m.GetCatalogImageFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.GetCatalogImage")
+	}
+	return m.GetCatalogImageFunc(ctx, id)
+}
+
+func (m *CatalogStore) GetCatalogThumbnail(ctx context.Context, id string) ([]byte, string, error) {
+	if m.GetCatalogThumbnailFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.GetCatalogThumbnail")
+	}
+	return m.GetCatalogThumbnailFunc(ctx, id)
+}
+
+func (m *CatalogStore) GetExerciseStats(ctx context.Context, catalogID string, userID string, limit int, offset int, formula progression.Formula) (*store.ExerciseStats, bool, error) {
+	if m.GetExerciseStatsFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.GetExerciseStats")
+	}
+	return m.GetExerciseStatsFunc(ctx, catalogID, userID, limit, offset, formula)
+}
+
+func (m *CatalogStore) Localize(ctx context.Context, rec *store.CatalogRecord, locales []string) error {
+	if m.LocalizeFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Localize")
+	}
+	return m.LocalizeFunc(ctx, rec, locales)
+}
+
+func (m *CatalogStore) MatchExercise(ctx context.Context, userID string, name string, limit int) ([]store.CatalogMatch, error) {
+	if m.MatchExerciseFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.MatchExercise")
+	}
+	return m.MatchExerciseFunc(ctx, userID, name, limit)
+}
+
+func (m *CatalogStore) MergeCatalogEntries(ctx context.Context, sourceID string, targetID string) (*store.CatalogMergeResult, error) {
+	if m.MergeCatalogEntriesFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.MergeCatalogEntries")
+	}
+	return m.MergeCatalogEntriesFunc(ctx, sourceID, targetID)
+}
+
+func (m *CatalogStore) MergeMuscles(ctx context.Context, source string, target string) (*store.MuscleMergeResult, error) {
+	if m.MergeMusclesFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.MergeMuscles")
+	}
+	return m.MergeMusclesFunc(ctx, source, target)
+}
+
+func (m *CatalogStore) Muscles(ctx context.Context) ([]store.CanonicalMuscle, error) {
+	if m.MusclesFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Muscles")
+	}
+	return m.MusclesFunc(ctx)
+}
+
+func (m *CatalogStore) PutCatalogImage(ctx context.Context, id string, data []byte, mimeType string) error {
+	if m.PutCatalogImageFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.PutCatalogImage")
+	}
+	return m.PutCatalogImageFunc(ctx, id, data, mimeType)
+}
+
+func (m *CatalogStore) PutTranslation(ctx context.Context, t store.CatalogTranslation) (*store.CatalogTranslation, error) {
+	if m.PutTranslationFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.PutTranslation")
+	}
+	return m.PutTranslationFunc(ctx, t)
+}
+
+func (m *CatalogStore) ReviewCatalogEntry(ctx context.Context, id string, reviewerID string, status string, feedback *string) (*store.CatalogRecord, error) {
+	if m.ReviewCatalogEntryFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.ReviewCatalogEntry")
+	}
+	return m.ReviewCatalogEntryFunc(ctx, id, reviewerID, status, feedback)
+}
+
+func (m *CatalogStore) Search(ctx context.Context, p store.CatalogSearchParams) (store.CatalogSearchResult, error) {
+	if m.SearchFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Search")
+	}
+	return m.SearchFunc(ctx, p)
+}
+
+func (m *CatalogStore) Submissions(ctx context.Context, userID string, status string) ([]store.CatalogRecord, error) {
+	if m.SubmissionsFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Submissions")
+	}
+	return m.SubmissionsFunc(ctx, userID, status)
+}
+
+func (m *CatalogStore) SubmitCatalogEntry(ctx context.Context, userID string, entry store.CatalogEntry, imageData []byte, imageMimeType string) (*store.CatalogRecord, error) {
+	if m.SubmitCatalogEntryFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.SubmitCatalogEntry")
+	}
+	return m.SubmitCatalogEntryFunc(ctx, userID, entry, imageData, imageMimeType)
+}
+
+func (m *CatalogStore) Translations(ctx context.Context, catalogID string) ([]store.CatalogTranslation, error) {
+	if m.TranslationsFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Translations")
+	}
+	return m.TranslationsFunc(ctx, catalogID)
+}
+
+func (m *CatalogStore) UpdateCatalogEntry(ctx context.Context, id string, entry store.CatalogEntry, imageData []byte, imageMimeType string, removeImage bool) error {
+	if m.UpdateCatalogEntryFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.UpdateCatalogEntry")
+	}
+	return m.UpdateCatalogEntryFunc(ctx, id, entry, imageData, imageMimeType, removeImage)
+}
+
+func (m *CatalogStore) Upsert(ctx context.Context, entries []store.CatalogEntry) (int, error) {
+	if m.UpsertFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Upsert")
+	}
+	return m.UpsertFunc(ctx, entries)
+}
+
+func (m *CatalogStore) Version(ctx context.Context) (int64, error) {
+	if m.VersionFunc == nil {
+		panic("mocks: unexpected call to CatalogStore.Version")
+	}
+	return m.VersionFunc(ctx)
+}
+
+// AuditStore is a fake handlers.AuditStore.
+type AuditStore struct {
+	ListFunc   func(ctx context.Context, f store.AuditFilter) ([]store.AuditEntry, error)
+	RecordFunc func(ctx context.Context, p store.AuditRecordParams) error
+}
+
+func (m *AuditStore) List(ctx context.Context, f store.AuditFilter) ([]store.AuditEntry, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to AuditStore.List")
+	}
+	return m.ListFunc(ctx, f)
+}
+
+func (m *AuditStore) Record(ctx context.Context, p store.AuditRecordParams) error {
+	if m.RecordFunc == nil {
+		panic("mocks: unexpected call to AuditStore.Record")
+	}
+	return m.RecordFunc(ctx, p)
+}
+
+// GymsStore is a fake handlers.GymsStore.
+type GymsStore struct {
+	CreateFunc func(ctx context.Context, userID string, p store.GymProfileParams) (*store.GymProfile, error)
+	DeleteFunc func(ctx context.Context, userID string, id string) (bool, error)
+	GetFunc    func(ctx context.Context, userID string, id string) (*store.GymProfile, error)
+	ListFunc   func(ctx context.Context, userID string) ([]store.GymProfile, error)
+	UpdateFunc func(ctx context.Context, userID string, id string, p store.GymProfileParams) (*store.GymProfile, error)
+}
+
+func (m *GymsStore) Create(ctx context.Context, userID string, p store.GymProfileParams) (*store.GymProfile, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to GymsStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, p)
+}
+
+func (m *GymsStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to GymsStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *GymsStore) Get(ctx context.Context, userID string, id string) (*store.GymProfile, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to GymsStore.Get")
+	}
+	return m.GetFunc(ctx, userID, id)
+}
+
+func (m *GymsStore) List(ctx context.Context, userID string) ([]store.GymProfile, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to GymsStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *GymsStore) Update(ctx context.Context, userID string, id string, p store.GymProfileParams) (*store.GymProfile, error) {
+	if m.UpdateFunc == nil {
+		panic("mocks: unexpected call to GymsStore.Update")
+	}
+	return m.UpdateFunc(ctx, userID, id, p)
+}
+
+// BodyweightStore is a fake handlers.BodyweightStore.
+type BodyweightStore struct {
+	DeleteFunc func(ctx context.Context, userID string, id string) (bool, error)
+	ListFunc   func(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.BodyweightEntry, error)
+	LogFunc    func(ctx context.Context, userID string, date time.Time, weightKg float64) (*store.BodyweightEntry, error)
+}
+
+func (m *BodyweightStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to BodyweightStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *BodyweightStore) List(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.BodyweightEntry, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to BodyweightStore.List")
+	}
+	return m.ListFunc(ctx, userID, from, to)
+}
+
+func (m *BodyweightStore) Log(ctx context.Context, userID string, date time.Time, weightKg float64) (*store.BodyweightEntry, error) {
+	if m.LogFunc == nil {
+		panic("mocks: unexpected call to BodyweightStore.Log")
+	}
+	return m.LogFunc(ctx, userID, date, weightKg)
+}
+
+// SettingsStore is a fake handlers.SettingsStore.
+type SettingsStore struct {
+	DeleteExerciseRestFunc func(ctx context.Context, userID string, catalogID string) (bool, error)
+	ExerciseRestsFunc      func(ctx context.Context, userID string) ([]store.ExerciseRest, error)
+	GetFunc                func(ctx context.Context, userID string) (store.UserSettings, error)
+	SetExerciseRestFunc    func(ctx context.Context, userID string, catalogID string, seconds int) (*store.ExerciseRest, error)
+	UpdateFunc             func(ctx context.Context, userID string, p store.UpdateSettingsParams) (store.UserSettings, error)
+}
+
+func (m *SettingsStore) DeleteExerciseRest(ctx context.Context, userID string, catalogID string) (bool, error) {
+	if m.DeleteExerciseRestFunc == nil {
+		panic("mocks: unexpected call to SettingsStore.DeleteExerciseRest")
+	}
+	return m.DeleteExerciseRestFunc(ctx, userID, catalogID)
+}
+
+func (m *SettingsStore) ExerciseRests(ctx context.Context, userID string) ([]store.ExerciseRest, error) {
+	if m.ExerciseRestsFunc == nil {
+		panic("mocks: unexpected call to SettingsStore.ExerciseRests")
+	}
+	return m.ExerciseRestsFunc(ctx, userID)
+}
+
+func (m *SettingsStore) Get(ctx context.Context, userID string) (store.UserSettings, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to SettingsStore.Get")
+	}
+	return m.GetFunc(ctx, userID)
+}
+
+func (m *SettingsStore) SetExerciseRest(ctx context.Context, userID string, catalogID string, seconds int) (*store.ExerciseRest, error) {
+	if m.SetExerciseRestFunc == nil {
+		panic("mocks: unexpected call to SettingsStore.SetExerciseRest")
+	}
+	return m.SetExerciseRestFunc(ctx, userID, catalogID, seconds)
+}
+
+func (m *SettingsStore) Update(ctx context.Context, userID string, p store.UpdateSettingsParams) (store.UserSettings, error) {
+	if m.UpdateFunc == nil {
+		panic("mocks: unexpected call to SettingsStore.Update")
+	}
+	return m.UpdateFunc(ctx, userID, p)
+}
+
+// SetsStore is a fake handlers.SetsStore.
+type SetsStore struct {
+	CreateFunc                func(ctx context.Context, p store.CreateSetParams) (*models.Set, error)
+	CreateRestFunc            func(ctx context.Context, p store.CreateRestParams) (*models.RestPeriod, error)
+	DeleteFunc                func(ctx context.Context, id string, userID string) (bool, error)
+	DeleteRestFunc            func(ctx context.Context, restID string, userID string) (bool, error)
+	DetectPersonalRecordsFunc func(ctx context.Context, userID string, setIDs []string) ([]store.PersonalRecord, error)
+	GetForUpdateFunc          func(ctx context.Context, userID string, id string) (*models.Set, error)
+	InsertWarmupsFunc         func(ctx context.Context, userID string, exerciseID string, warmups []progression.WarmupSet) ([]models.Set, error)
+	ReorderFunc               func(ctx context.Context, userID string, exerciseID string, ids []string) (bool, error)
+	UpdateFunc                func(ctx context.Context, p store.UpdateSetParams) (*models.Set, error)
+	UpdateRestFunc            func(ctx context.Context, p store.UpdateRestParams) (*models.RestPeriod, error)
+}
+
+func (m *SetsStore) Create(ctx context.Context, p store.CreateSetParams) (*models.Set, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to SetsStore.Create")
+	}
+	return m.CreateFunc(ctx, p)
+}
+
+func (m *SetsStore) CreateRest(ctx context.Context, p store.CreateRestParams) (*models.RestPeriod, error) {
+	if m.CreateRestFunc == nil {
+		panic("mocks: unexpected call to SetsStore.CreateRest")
+	}
+	return m.CreateRestFunc(ctx, p)
+}
+
+func (m *SetsStore) Delete(ctx context.Context, id string, userID string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to SetsStore.Delete")
+	}
+	return m.DeleteFunc(ctx, id, userID)
+}
+
+func (m *SetsStore) DeleteRest(ctx context.Context, restID string, userID string) (bool, error) {
+	if m.DeleteRestFunc == nil {
+		panic("mocks: unexpected call to SetsStore.DeleteRest")
+	}
+	return m.DeleteRestFunc(ctx, restID, userID)
+}
+
+func (m *SetsStore) DetectPersonalRecords(ctx context.Context, userID string, setIDs []string) ([]store.PersonalRecord, error) {
+	if m.DetectPersonalRecordsFunc == nil {
+		panic("mocks: unexpected call to SetsStore.DetectPersonalRecords")
+	}
+	return m.DetectPersonalRecordsFunc(ctx, userID, setIDs)
+}
+
+func (m *SetsStore) GetForUpdate(ctx context.Context, userID string, id string) (*models.Set, error) {
+	if m.GetForUpdateFunc == nil {
+		panic("mocks: unexpected call to SetsStore.GetForUpdate")
+	}
+	return m.GetForUpdateFunc(ctx, userID, id)
+}
+
+func (m *SetsStore) InsertWarmups(ctx context.Context, userID string, exerciseID string, warmups []progression.WarmupSet) ([]models.Set, error) {
+	if m.InsertWarmupsFunc == nil {
+		panic("mocks: unexpected call to SetsStore.InsertWarmups")
+	}
+	return m.InsertWarmupsFunc(ctx, userID, exerciseID, warmups)
+}
+
+func (m *SetsStore) Reorder(ctx context.Context, userID string, exerciseID string, ids []string) (bool, error) {
+	if m.ReorderFunc == nil {
+		panic("mocks: unexpected call to SetsStore.Reorder")
+	}
+	return m.ReorderFunc(ctx, userID, exerciseID, ids)
+}
+
+func (m *SetsStore) Update(ctx context.Context, p store.UpdateSetParams) (*models.Set, error) {
+	if m.UpdateFunc == nil {
+		panic("mocks: unexpected call to SetsStore.Update")
+	}
+	return m.UpdateFunc(ctx, p)
+}
+
+func (m *SetsStore) UpdateRest(ctx context.Context, p store.UpdateRestParams) (*models.RestPeriod, error) {
+	if m.UpdateRestFunc == nil {
+		panic("mocks: unexpected call to SetsStore.UpdateRest")
+	}
+	return m.UpdateRestFunc(ctx, p)
+}
+
+// ExercisesStore is a fake handlers.ExercisesStore.
+type ExercisesStore struct {
+	CreateFunc       func(ctx context.Context, userID string, dayID string, catalogID string, position int, comment *string) (*models.Exercise, error)
+	DeleteFunc       func(ctx context.Context, userID string, id string) (bool, error)
+	EquipmentFunc    func(ctx context.Context, userID string, id string) (*store.ExerciseEquipment, error)
+	GetForUpdateFunc func(ctx context.Context, userID string, id string) (*models.Exercise, error)
+	MoveFunc         func(ctx context.Context, userID string, id string, dayID string, position *int) (*models.Exercise, error)
+	ReorderFunc      func(ctx context.Context, userID string, dayID string, ids []string) (bool, error)
+	UpdateFunc       func(ctx context.Context, userID string, id string, position *int, comment *string) (*models.Exercise, error)
+}
+
+func (m *ExercisesStore) Create(ctx context.Context, userID string, dayID string, catalogID string, position int, comment *string) (*models.Exercise, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, dayID, catalogID, position, comment)
+}
+
+func (m *ExercisesStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *ExercisesStore) Equipment(ctx context.Context, userID string, id string) (*store.ExerciseEquipment, error) {
+	if m.EquipmentFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.Equipment")
+	}
+	return m.EquipmentFunc(ctx, userID, id)
+}
+
+func (m *ExercisesStore) GetForUpdate(ctx context.Context, userID string, id string) (*models.Exercise, error) {
+	if m.GetForUpdateFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.GetForUpdate")
+	}
+	return m.GetForUpdateFunc(ctx, userID, id)
+}
+
+func (m *ExercisesStore) Move(ctx context.Context, userID string, id string, dayID string, position *int) (*models.Exercise, error) {
+	if m.MoveFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.Move")
+	}
+	return m.MoveFunc(ctx, userID, id, dayID, position)
+}
+
+func (m *ExercisesStore) Reorder(ctx context.Context, userID string, dayID string, ids []string) (bool, error) {
+	if m.ReorderFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.Reorder")
+	}
+	return m.ReorderFunc(ctx, userID, dayID, ids)
+}
+
+func (m *ExercisesStore) Update(ctx context.Context, userID string, id string, position *int, comment *string) (*models.Exercise, error) {
+	if m.UpdateFunc == nil {
+		panic("mocks: unexpected call to ExercisesStore.Update")
+	}
+	return m.UpdateFunc(ctx, userID, id, position, comment)
+}
+
+// ProgramsStore is a fake handlers.ProgramsStore.
+type ProgramsStore struct {
+	CatalogIDsFunc func(ctx context.Context, userID string, slugs []string) (map[string]string, error)
+	CreateFunc     func(ctx context.Context, userID string, in store.ProgramInput) (*models.Program, error)
+	DeleteFunc     func(ctx context.Context, userID string, id string) (bool, error)
+	GetFunc        func(ctx context.Context, userID string, id string) (*models.Program, error)
+	ListFunc       func(ctx context.Context, userID string) ([]models.Program, error)
+	ReplaceFunc    func(ctx context.Context, userID string, id string, in store.ProgramInput) (*models.Program, error)
+	ScheduleFunc   func(ctx context.Context, userID string, id string, start time.Time) (*store.ScheduleResult, error)
+}
+
+func (m *ProgramsStore) CatalogIDs(ctx context.Context, userID string, slugs []string) (map[string]string, error) {
+	if m.CatalogIDsFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.CatalogIDs")
+	}
+	return m.CatalogIDsFunc(ctx, userID, slugs)
+}
+
+func (m *ProgramsStore) Create(ctx context.Context, userID string, in store.ProgramInput) (*models.Program, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, in)
+}
+
+func (m *ProgramsStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *ProgramsStore) Get(ctx context.Context, userID string, id string) (*models.Program, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.Get")
+	}
+	return m.GetFunc(ctx, userID, id)
+}
+
+func (m *ProgramsStore) List(ctx context.Context, userID string) ([]models.Program, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *ProgramsStore) Replace(ctx context.Context, userID string, id string, in store.ProgramInput) (*models.Program, error) {
+	if m.ReplaceFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.Replace")
+	}
+	return m.ReplaceFunc(ctx, userID, id, in)
+}
+
+func (m *ProgramsStore) Schedule(ctx context.Context, userID string, id string, start time.Time) (*store.ScheduleResult, error) {
+	if m.ScheduleFunc == nil {
+		panic("mocks: unexpected call to ProgramsStore.Schedule")
+	}
+	return m.ScheduleFunc(ctx, userID, id, start)
+}
+
+// AnalyticsStore is a fake handlers.AnalyticsStore.
+type AnalyticsStore struct {
+	CardioFunc           func(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklyCardio, error)
+	DayAdherenceFunc     func(ctx context.Context, userID string, dayID string) (*store.DayAdherence, error)
+	MuscleSplitFunc      func(ctx context.Context, userID string, weeks int, secondaryFactor float64, now time.Time) ([]store.WeeklyMuscleSplit, error)
+	RecomputeStatsFunc   func(ctx context.Context, userID string) (int64, error)
+	RecoveryFunc         func(ctx context.Context, userID string, weeks int, now time.Time) (*store.Recovery, error)
+	SessionDurationsFunc func(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklySessionDuration, error)
+	SideBalanceFunc      func(ctx context.Context, userID string, weeks int, now time.Time) ([]store.SideVolume, error)
+	TrainingLoadFunc     func(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklyLoad, error)
+}
+
+func (m *AnalyticsStore) Cardio(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklyCardio, error) {
+	if m.CardioFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.Cardio")
+	}
+	return m.CardioFunc(ctx, userID, weeks, now)
+}
+
+func (m *AnalyticsStore) DayAdherence(ctx context.Context, userID string, dayID string) (*store.DayAdherence, error) {
+	if m.DayAdherenceFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.DayAdherence")
+	}
+	return m.DayAdherenceFunc(ctx, userID, dayID)
+}
+
+func (m *AnalyticsStore) MuscleSplit(ctx context.Context, userID string, weeks int, secondaryFactor float64, now time.Time) ([]store.WeeklyMuscleSplit, error) {
+	if m.MuscleSplitFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.MuscleSplit")
+	}
+	return m.MuscleSplitFunc(ctx, userID, weeks, secondaryFactor, now)
+}
+
+func (m *AnalyticsStore) RecomputeStats(ctx context.Context, userID string) (int64, error) {
+	if m.RecomputeStatsFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.RecomputeStats")
+	}
+	return m.RecomputeStatsFunc(ctx, userID)
+}
+
+func (m *AnalyticsStore) Recovery(ctx context.Context, userID string, weeks int, now time.Time) (*store.Recovery, error) {
+	if m.RecoveryFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.Recovery")
+	}
+	return m.RecoveryFunc(ctx, userID, weeks, now)
+}
+
+func (m *AnalyticsStore) SessionDurations(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklySessionDuration, error) {
+	if m.SessionDurationsFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.SessionDurations")
+	}
+	return m.SessionDurationsFunc(ctx, userID, weeks, now)
+}
+
+func (m *AnalyticsStore) SideBalance(ctx context.Context, userID string, weeks int, now time.Time) ([]store.SideVolume, error) {
+	if m.SideBalanceFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.SideBalance")
+	}
+	return m.SideBalanceFunc(ctx, userID, weeks, now)
+}
+
+func (m *AnalyticsStore) TrainingLoad(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklyLoad, error) {
+	if m.TrainingLoadFunc == nil {
+		panic("mocks: unexpected call to AnalyticsStore.TrainingLoad")
+	}
+	return m.TrainingLoadFunc(ctx, userID, weeks, now)
+}
+
+// APIKeysStore is a fake handlers.APIKeysStore.
+type APIKeysStore struct {
+	CreateFunc func(ctx context.Context, userID string, name string, prefix string, hash string, scope string) (*store.APIKey, error)
+	ListFunc   func(ctx context.Context, userID string) ([]store.APIKey, error)
+	RevokeFunc func(ctx context.Context, userID string, id string) (bool, error)
+}
+
+func (m *APIKeysStore) Create(ctx context.Context, userID string, name string, prefix string, hash string, scope string) (*store.APIKey, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to APIKeysStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, name, prefix, hash, scope)
+}
+
+func (m *APIKeysStore) List(ctx context.Context, userID string) ([]store.APIKey, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to APIKeysStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *APIKeysStore) Revoke(ctx context.Context, userID string, id string) (bool, error) {
+	if m.RevokeFunc == nil {
+		panic("mocks: unexpected call to APIKeysStore.Revoke")
+	}
+	return m.RevokeFunc(ctx, userID, id)
+}
+
+// SessionsStore is a fake handlers.SessionsStore.
+type SessionsStore struct {
+	CreateFunc func(ctx context.Context, userID string, userAgent string, ip string, expiresAt time.Time) (*store.Session, error)
+	ListFunc   func(ctx context.Context, userID string) ([]store.Session, error)
+	RevokeFunc func(ctx context.Context, userID string, id string) (bool, error)
+}
+
+func (m *SessionsStore) Create(ctx context.Context, userID string, userAgent string, ip string, expiresAt time.Time) (*store.Session, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to SessionsStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, userAgent, ip, expiresAt)
+}
+
+func (m *SessionsStore) List(ctx context.Context, userID string) ([]store.Session, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to SessionsStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *SessionsStore) Revoke(ctx context.Context, userID string, id string) (bool, error) {
+	if m.RevokeFunc == nil {
+		panic("mocks: unexpected call to SessionsStore.Revoke")
+	}
+	return m.RevokeFunc(ctx, userID, id)
+}
+
+// TwoFactorStore is a fake handlers.TwoFactorStore.
+type TwoFactorStore struct {
+	DisableFunc              func(ctx context.Context, userID string) error
+	EnableFunc               func(ctx context.Context, userID string, step int64, recoveryHashes []string) (bool, error)
+	EnrollFunc               func(ctx context.Context, userID string, secret string) (bool, error)
+	GetFunc                  func(ctx context.Context, userID string) (*store.TOTP, error)
+	RecoveryCodesLeftFunc    func(ctx context.Context, userID string) (int, error)
+	ReplaceRecoveryCodesFunc func(ctx context.Context, userID string, hashes []string) error
+	UseRecoveryCodeFunc      func(ctx context.Context, userID string, hash string) (bool, error)
+	UseStepFunc              func(ctx context.Context, userID string, step int64) (bool, error)
+}
+
+func (m *TwoFactorStore) Disable(ctx context.Context, userID string) error {
+	if m.DisableFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.Disable")
+	}
+	return m.DisableFunc(ctx, userID)
+}
+
+func (m *TwoFactorStore) Enable(ctx context.Context, userID string, step int64, recoveryHashes []string) (bool, error) {
+	if m.EnableFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.Enable")
+	}
+	return m.EnableFunc(ctx, userID, step, recoveryHashes)
+}
+
+func (m *TwoFactorStore) Enroll(ctx context.Context, userID string, secret string) (bool, error) {
+	if m.EnrollFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.Enroll")
+	}
+	return m.EnrollFunc(ctx, userID, secret)
+}
+
+func (m *TwoFactorStore) Get(ctx context.Context, userID string) (*store.TOTP, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.Get")
+	}
+	return m.GetFunc(ctx, userID)
+}
+
+func (m *TwoFactorStore) RecoveryCodesLeft(ctx context.Context, userID string) (int, error) {
+	if m.RecoveryCodesLeftFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.RecoveryCodesLeft")
+	}
+	return m.RecoveryCodesLeftFunc(ctx, userID)
+}
+
+func (m *TwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	if m.ReplaceRecoveryCodesFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.ReplaceRecoveryCodes")
+	}
+	return m.ReplaceRecoveryCodesFunc(ctx, userID, hashes)
+}
+
+func (m *TwoFactorStore) UseRecoveryCode(ctx context.Context, userID string, hash string) (bool, error) {
+	if m.UseRecoveryCodeFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.UseRecoveryCode")
+	}
+	return m.UseRecoveryCodeFunc(ctx, userID, hash)
+}
+
+func (m *TwoFactorStore) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	if m.UseStepFunc == nil {
+		panic("mocks: unexpected call to TwoFactorStore.UseStep")
+	}
+	return m.UseStepFunc(ctx, userID, step)
+}
+
+// CoachingStore is a fake handlers.CoachingStore.
+type CoachingStore struct {
+	AcceptFunc  func(ctx context.Context, linkID string, coachID string, coachEmail string) (*store.CoachLink, error)
+	ClientsFunc func(ctx context.Context, coachID string, coachEmail string) ([]store.CoachLink, error)
+	CoachesFunc func(ctx context.Context, clientID string) ([]store.CoachLink, error)
+	InviteFunc  func(ctx context.Context, clientID string, coachEmail string, canWrite bool) (*store.CoachLink, error)
+	RemoveFunc  func(ctx context.Context, linkID string, userID string, userEmail string) (bool, error)
+}
+
+func (m *CoachingStore) Accept(ctx context.Context, linkID string, coachID string, coachEmail string) (*store.CoachLink, error) {
+	if m.AcceptFunc == nil {
+		panic("mocks: unexpected call to CoachingStore.Accept")
+	}
+	return m.AcceptFunc(ctx, linkID, coachID, coachEmail)
+}
+
+func (m *CoachingStore) Clients(ctx context.Context, coachID string, coachEmail string) ([]store.CoachLink, error) {
+	if m.ClientsFunc == nil {
+		panic("mocks: unexpected call to CoachingStore.Clients")
+	}
+	return m.ClientsFunc(ctx, coachID, coachEmail)
+}
+
+func (m *CoachingStore) Coaches(ctx context.Context, clientID string) ([]store.CoachLink, error) {
+	if m.CoachesFunc == nil {
+		panic("mocks: unexpected call to CoachingStore.Coaches")
+	}
+	return m.CoachesFunc(ctx, clientID)
+}
+
+func (m *CoachingStore) Invite(ctx context.Context, clientID string, coachEmail string, canWrite bool) (*store.CoachLink, error) {
+	if m.InviteFunc == nil {
+		panic("mocks: unexpected call to CoachingStore.Invite")
+	}
+	return m.InviteFunc(ctx, clientID, coachEmail, canWrite)
+}
+
+func (m *CoachingStore) Remove(ctx context.Context, linkID string, userID string, userEmail string) (bool, error) {
+	if m.RemoveFunc == nil {
+		panic("mocks: unexpected call to CoachingStore.Remove")
+	}
+	return m.RemoveFunc(ctx, linkID, userID, userEmail)
+}
+
+// CommentsStore is a fake handlers.CommentsStore.
+type CommentsStore struct {
+	CreateFunc       func(ctx context.Context, ownerID string, authorID string, dayID string, exerciseID *string, body string) (*models.WorkoutComment, error)
+	DeleteFunc       func(ctx context.Context, authorID string, id string) (bool, error)
+	ListByDayFunc    func(ctx context.Context, ownerID string, dayID string) ([]models.WorkoutComment, error)
+	MarkReadFunc     func(ctx context.Context, ownerID string, dayID string) (int64, error)
+	UnreadCountsFunc func(ctx context.Context, ownerID string) ([]store.UnreadCommentCount, error)
+}
+
+func (m *CommentsStore) Create(ctx context.Context, ownerID string, authorID string, dayID string, exerciseID *string, body string) (*models.WorkoutComment, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to CommentsStore.Create")
+	}
+	return m.CreateFunc(ctx, ownerID, authorID, dayID, exerciseID, body)
+}
+
+func (m *CommentsStore) Delete(ctx context.Context, authorID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to CommentsStore.Delete")
+	}
+	return m.DeleteFunc(ctx, authorID, id)
+}
+
+func (m *CommentsStore) ListByDay(ctx context.Context, ownerID string, dayID string) ([]models.WorkoutComment, error) {
+	if m.ListByDayFunc == nil {
+		panic("mocks: unexpected call to CommentsStore.ListByDay")
+	}
+	return m.ListByDayFunc(ctx, ownerID, dayID)
+}
+
+func (m *CommentsStore) MarkRead(ctx context.Context, ownerID string, dayID string) (int64, error) {
+	if m.MarkReadFunc == nil {
+		panic("mocks: unexpected call to CommentsStore.MarkRead")
+	}
+	return m.MarkReadFunc(ctx, ownerID, dayID)
+}
+
+func (m *CommentsStore) UnreadCounts(ctx context.Context, ownerID string) ([]store.UnreadCommentCount, error) {
+	if m.UnreadCountsFunc == nil {
+		panic("mocks: unexpected call to CommentsStore.UnreadCounts")
+	}
+	return m.UnreadCountsFunc(ctx, ownerID)
+}
+
+// RemindersStore is a fake handlers.RemindersStore.
+type RemindersStore struct {
+	CreateFunc                 func(ctx context.Context, userID string, p store.ReminderParams) (*store.Reminder, error)
+	DeleteFunc                 func(ctx context.Context, userID string, id string) (bool, error)
+	DeletePushSubscriptionFunc func(ctx context.Context, userID string, endpoint string) (bool, error)
+	ListFunc                   func(ctx context.Context, userID string) ([]store.Reminder, error)
+	SavePushSubscriptionFunc   func(ctx context.Context, userID string, sub store.PushSubscription) (*store.PushSubscription, error)
+	UpdateFunc                 func(ctx context.Context, userID string, id string, p store.ReminderParams) (*store.Reminder, error)
+}
+
+func (m *RemindersStore) Create(ctx context.Context, userID string, p store.ReminderParams) (*store.Reminder, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to RemindersStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, p)
+}
+
+func (m *RemindersStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to RemindersStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *RemindersStore) DeletePushSubscription(ctx context.Context, userID string, endpoint string) (bool, error) {
+	if m.DeletePushSubscriptionFunc == nil {
+		panic("mocks: unexpected call to RemindersStore.DeletePushSubscription")
+	}
+	return m.DeletePushSubscriptionFunc(ctx, userID, endpoint)
+}
+
+func (m *RemindersStore) List(ctx context.Context, userID string) ([]store.Reminder, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to RemindersStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *RemindersStore) SavePushSubscription(ctx context.Context, userID string, sub store.PushSubscription) (*store.PushSubscription, error) {
+	if m.SavePushSubscriptionFunc == nil {
+		panic("mocks: unexpected call to RemindersStore.SavePushSubscription")
+	}
+	return m.SavePushSubscriptionFunc(ctx, userID, sub)
+}
+
+func (m *RemindersStore) Update(ctx context.Context, userID string, id string, p store.ReminderParams) (*store.Reminder, error) {
+	if m.UpdateFunc == nil {
+		panic("mocks: unexpected call to RemindersStore.Update")
+	}
+	return m.UpdateFunc(ctx, userID, id, p)
+}
+
+// UsageStore is a fake handlers.UsageStore.
+type UsageStore struct {
+	DefaultsFunc        func(ctx context.Context) (store.Quota, error)
+	DeleteUserQuotaFunc func(ctx context.Context, userID string) (bool, error)
+	GetFunc             func(ctx context.Context, userID string) (*store.UserUsage, error)
+	SetDefaultsFunc     func(ctx context.Context, q store.Quota) (store.Quota, error)
+	SetUserQuotaFunc    func(ctx context.Context, userID string, q store.Quota) (*store.Quota, error)
+	UserQuotaFunc       func(ctx context.Context, userID string) (*store.Quota, error)
+}
+
+func (m *UsageStore) Defaults(ctx context.Context) (store.Quota, error) {
+	if m.DefaultsFunc == nil {
+		panic("mocks: unexpected call to UsageStore.Defaults")
+	}
+	return m.DefaultsFunc(ctx)
+}
+
+func (m *UsageStore) DeleteUserQuota(ctx context.Context, userID string) (bool, error) {
+	if m.DeleteUserQuotaFunc == nil {
+		panic("mocks: unexpected call to UsageStore.DeleteUserQuota")
+	}
+	return m.DeleteUserQuotaFunc(ctx, userID)
+}
+
+func (m *UsageStore) Get(ctx context.Context, userID string) (*store.UserUsage, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to UsageStore.Get")
+	}
+	return m.GetFunc(ctx, userID)
+}
+
+func (m *UsageStore) SetDefaults(ctx context.Context, q store.Quota) (store.Quota, error) {
+	if m.SetDefaultsFunc == nil {
+		panic("mocks: unexpected call to UsageStore.SetDefaults")
+	}
+	return m.SetDefaultsFunc(ctx, q)
+}
+
+func (m *UsageStore) SetUserQuota(ctx context.Context, userID string, q store.Quota) (*store.Quota, error) {
+	if m.SetUserQuotaFunc == nil {
+		panic("mocks: unexpected call to UsageStore.SetUserQuota")
+	}
+	return m.SetUserQuotaFunc(ctx, userID, q)
+}
+
+func (m *UsageStore) UserQuota(ctx context.Context, userID string) (*store.Quota, error) {
+	if m.UserQuotaFunc == nil {
+		panic("mocks: unexpected call to UsageStore.UserQuota")
+	}
+	return m.UserQuotaFunc(ctx, userID)
+}
+
+// SaveStore is a fake handlers.SaveStore.
+type SaveStore struct {
+	ChangesSinceFunc        func(ctx context.Context, userID string, epoch int64) (*store.SyncChanges, error)
+	CurrentEpochFunc        func(ctx context.Context, userID string) int64
+	ProcessBatchFunc        func(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (store.SaveMapping, time.Time, error)
+	ProcessBatchPartialFunc func(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (store.SaveMapping, []store.SaveOpResult, time.Time, error)
+	SetEpochFunc            func(ctx context.Context, userID string, epoch int64) error
+}
+
+func (m *SaveStore) ChangesSince(ctx context.Context, userID string, epoch int64) (*store.SyncChanges, error) {
+	if m.ChangesSinceFunc == nil {
+		panic("mocks: unexpected call to SaveStore.ChangesSince")
+	}
+	return m.ChangesSinceFunc(ctx, userID, epoch)
+}
+
+func (m *SaveStore) CurrentEpoch(ctx context.Context, userID string) int64 {
+	if m.CurrentEpochFunc == nil {
+		panic("mocks: unexpected call to SaveStore.CurrentEpoch")
+	}
+	return m.CurrentEpochFunc(ctx, userID)
+}
+
+func (m *SaveStore) ProcessBatch(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (store.SaveMapping, time.Time, error) {
+	if m.ProcessBatchFunc == nil {
+		panic("mocks: unexpected call to SaveStore.ProcessBatch")
+	}
+	return m.ProcessBatchFunc(ctx, userID, rawOps, idKey)
+}
+
+func (m *SaveStore) ProcessBatchPartial(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (store.SaveMapping, []store.SaveOpResult, time.Time, error) {
+	if m.ProcessBatchPartialFunc == nil {
+		panic("mocks: unexpected call to SaveStore.ProcessBatchPartial")
+	}
+	return m.ProcessBatchPartialFunc(ctx, userID, rawOps, idKey)
+}
+
+func (m *SaveStore) SetEpoch(ctx context.Context, userID string, epoch int64) error {
+	if m.SetEpochFunc == nil {
+		panic("mocks: unexpected call to SaveStore.SetEpoch")
+	}
+	return m.SetEpochFunc(ctx, userID, epoch)
+}
+
+// JournalStore is a fake handlers.JournalStore.
+type JournalStore struct {
+	DayHistoryFunc func(ctx context.Context, userID string, dayID string, limit int) ([]store.JournalEntry, error)
+	UndoFunc       func(ctx context.Context, userID string) ([]store.JournalEntry, error)
+}
+
+func (m *JournalStore) DayHistory(ctx context.Context, userID string, dayID string, limit int) ([]store.JournalEntry, error) {
+	if m.DayHistoryFunc == nil {
+		panic("mocks: unexpected call to JournalStore.DayHistory")
+	}
+	return m.DayHistoryFunc(ctx, userID, dayID, limit)
+}
+
+func (m *JournalStore) Undo(ctx context.Context, userID string) ([]store.JournalEntry, error) {
+	if m.UndoFunc == nil {
+		panic("mocks: unexpected call to JournalStore.Undo")
+	}
+	return m.UndoFunc(ctx, userID)
+}
+
+// TemplatesStore is a fake handlers.TemplatesStore.
+type TemplatesStore struct {
+	DeleteFunc  func(ctx context.Context, userID string, code string) (bool, error)
+	GetFunc     func(ctx context.Context, code string) (*store.TemplateShare, error)
+	ImportFunc  func(ctx context.Context, userID string, code string, date time.Time) (*store.TemplateImport, error)
+	ListFunc    func(ctx context.Context, userID string) ([]store.TemplateShare, error)
+	PublishFunc func(ctx context.Context, userID string, kind string, sourceID string) (*store.TemplateShare, error)
+}
+
+func (m *TemplatesStore) Delete(ctx context.Context, userID string, code string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to TemplatesStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, code)
+}
+
+func (m *TemplatesStore) Get(ctx context.Context, code string) (*store.TemplateShare, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to TemplatesStore.Get")
+	}
+	return m.GetFunc(ctx, code)
+}
+
+func (m *TemplatesStore) Import(ctx context.Context, userID string, code string, date time.Time) (*store.TemplateImport, error) {
+	if m.ImportFunc == nil {
+		panic("mocks: unexpected call to TemplatesStore.Import")
+	}
+	return m.ImportFunc(ctx, userID, code, date)
+}
+
+func (m *TemplatesStore) List(ctx context.Context, userID string) ([]store.TemplateShare, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to TemplatesStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *TemplatesStore) Publish(ctx context.Context, userID string, kind string, sourceID string) (*store.TemplateShare, error) {
+	if m.PublishFunc == nil {
+		panic("mocks: unexpected call to TemplatesStore.Publish")
+	}
+	return m.PublishFunc(ctx, userID, kind, sourceID)
+}
+
+// LeaderboardsStore is a fake handlers.LeaderboardsStore.
+type LeaderboardsStore struct {
+	LeaderboardFunc   func(ctx context.Context, catalogID string, viewerID string, formula progression.Formula, limit int) (*store.Leaderboard, error)
+	ProfileFunc       func(ctx context.Context, userID string) (store.LeaderboardProfile, error)
+	UpdateProfileFunc func(ctx context.Context, userID string, p store.UpdateLeaderboardProfileParams) (store.LeaderboardProfile, error)
+}
+
+func (m *LeaderboardsStore) Leaderboard(ctx context.Context, catalogID string, viewerID string, formula progression.Formula, limit int) (*store.Leaderboard, error) {
+	if m.LeaderboardFunc == nil {
+		panic("mocks: unexpected call to LeaderboardsStore.Leaderboard")
+	}
+	return m.LeaderboardFunc(ctx, catalogID, viewerID, formula, limit)
+}
+
+func (m *LeaderboardsStore) Profile(ctx context.Context, userID string) (store.LeaderboardProfile, error) {
+	if m.ProfileFunc == nil {
+		panic("mocks: unexpected call to LeaderboardsStore.Profile")
+	}
+	return m.ProfileFunc(ctx, userID)
+}
+
+func (m *LeaderboardsStore) UpdateProfile(ctx context.Context, userID string, p store.UpdateLeaderboardProfileParams) (store.LeaderboardProfile, error) {
+	if m.UpdateProfileFunc == nil {
+		panic("mocks: unexpected call to LeaderboardsStore.UpdateProfile")
+	}
+	return m.UpdateProfileFunc(ctx, userID, p)
+}
+
+// FriendsStore is a fake handlers.FriendsStore.
+type FriendsStore struct {
+	AcceptFunc  func(ctx context.Context, id string, userID string, userEmail string) (*store.Friendship, error)
+	ListFunc    func(ctx context.Context, userID string, userEmail string) ([]store.Friendship, error)
+	RemoveFunc  func(ctx context.Context, id string, userID string, userEmail string) (bool, error)
+	RequestFunc func(ctx context.Context, userID string, userEmail string, email string) (*store.Friendship, error)
+}
+
+func (m *FriendsStore) Accept(ctx context.Context, id string, userID string, userEmail string) (*store.Friendship, error) {
+	if m.AcceptFunc == nil {
+		panic("mocks: unexpected call to FriendsStore.Accept")
+	}
+	return m.AcceptFunc(ctx, id, userID, userEmail)
+}
+
+func (m *FriendsStore) List(ctx context.Context, userID string, userEmail string) ([]store.Friendship, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to FriendsStore.List")
+	}
+	return m.ListFunc(ctx, userID, userEmail)
+}
+
+func (m *FriendsStore) Remove(ctx context.Context, id string, userID string, userEmail string) (bool, error) {
+	if m.RemoveFunc == nil {
+		panic("mocks: unexpected call to FriendsStore.Remove")
+	}
+	return m.RemoveFunc(ctx, id, userID, userEmail)
+}
+
+func (m *FriendsStore) Request(ctx context.Context, userID string, userEmail string, email string) (*store.Friendship, error) {
+	if m.RequestFunc == nil {
+		panic("mocks: unexpected call to FriendsStore.Request")
+	}
+	return m.RequestFunc(ctx, userID, userEmail, email)
+}
+
+// FeedStore is a fake handlers.FeedStore.
+type FeedStore struct {
+	ListFunc    func(ctx context.Context, userID string, before *time.Time, limit int) ([]store.FeedItem, error)
+	RefreshFunc func(ctx context.Context) error
+}
+
+func (m *FeedStore) List(ctx context.Context, userID string, before *time.Time, limit int) ([]store.FeedItem, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to FeedStore.List")
+	}
+	return m.ListFunc(ctx, userID, before, limit)
+}
+
+func (m *FeedStore) Refresh(ctx context.Context) error {
+	if m.RefreshFunc == nil {
+		panic("mocks: unexpected call to FeedStore.Refresh")
+	}
+	return m.RefreshFunc(ctx)
+}
+
+// ChallengesStore is a fake handlers.ChallengesStore.
+type ChallengesStore struct {
+	CreateFunc    func(ctx context.Context, userID string, p store.ChallengeParams) (*store.Challenge, error)
+	DeleteFunc    func(ctx context.Context, userID string, id string) (bool, error)
+	GetFunc       func(ctx context.Context, userID string, id string) (*store.Challenge, error)
+	JoinFunc      func(ctx context.Context, userID string, id string, today time.Time) (*store.Challenge, error)
+	LeaveFunc     func(ctx context.Context, userID string, id string) (bool, error)
+	ListFunc      func(ctx context.Context, userID string, status string, today time.Time) ([]store.Challenge, error)
+	StandingsFunc func(ctx context.Context, c *store.Challenge, viewerID string) ([]store.ChallengeStanding, error)
+}
+
+func (m *ChallengesStore) Create(ctx context.Context, userID string, p store.ChallengeParams) (*store.Challenge, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, p)
+}
+
+func (m *ChallengesStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *ChallengesStore) Get(ctx context.Context, userID string, id string) (*store.Challenge, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.Get")
+	}
+	return m.GetFunc(ctx, userID, id)
+}
+
+func (m *ChallengesStore) Join(ctx context.Context, userID string, id string, today time.Time) (*store.Challenge, error) {
+	if m.JoinFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.Join")
+	}
+	return m.JoinFunc(ctx, userID, id, today)
+}
+
+func (m *ChallengesStore) Leave(ctx context.Context, userID string, id string) (bool, error) {
+	if m.LeaveFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.Leave")
+	}
+	return m.LeaveFunc(ctx, userID, id)
+}
+
+func (m *ChallengesStore) List(ctx context.Context, userID string, status string, today time.Time) ([]store.Challenge, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.List")
+	}
+	return m.ListFunc(ctx, userID, status, today)
+}
+
+func (m *ChallengesStore) Standings(ctx context.Context, c *store.Challenge, viewerID string) ([]store.ChallengeStanding, error) {
+	if m.StandingsFunc == nil {
+		panic("mocks: unexpected call to ChallengesStore.Standings")
+	}
+	return m.StandingsFunc(ctx, c, viewerID)
+}
+
+// AchievementsStore is a fake handlers.AchievementsStore.
+type AchievementsStore struct {
+	DeleteWebhookFunc func(ctx context.Context, userID string) (bool, error)
+	ProgressFunc      func(ctx context.Context, userID string, slugs []string) (store.AchievementProgress, error)
+	SetWebhookFunc    func(ctx context.Context, userID string, url string) (*store.AchievementWebhook, error)
+	UnlockedFunc      func(ctx context.Context, userID string) ([]store.UnlockedAchievement, error)
+	WebhookFunc       func(ctx context.Context, userID string) (*store.AchievementWebhook, error)
+}
+
+func (m *AchievementsStore) DeleteWebhook(ctx context.Context, userID string) (bool, error) {
+	if m.DeleteWebhookFunc == nil {
+		panic("mocks: unexpected call to AchievementsStore.DeleteWebhook")
+	}
+	return m.DeleteWebhookFunc(ctx, userID)
+}
+
+func (m *AchievementsStore) Progress(ctx context.Context, userID string, slugs []string) (store.AchievementProgress, error) {
+	if m.ProgressFunc == nil {
+		panic("mocks: unexpected call to AchievementsStore.Progress")
+	}
+	return m.ProgressFunc(ctx, userID, slugs)
+}
+
+func (m *AchievementsStore) SetWebhook(ctx context.Context, userID string, url string) (*store.AchievementWebhook, error) {
+	if m.SetWebhookFunc == nil {
+		panic("mocks: unexpected call to AchievementsStore.SetWebhook")
+	}
+	return m.SetWebhookFunc(ctx, userID, url)
+}
+
+func (m *AchievementsStore) Unlocked(ctx context.Context, userID string) ([]store.UnlockedAchievement, error) {
+	if m.UnlockedFunc == nil {
+		panic("mocks: unexpected call to AchievementsStore.Unlocked")
+	}
+	return m.UnlockedFunc(ctx, userID)
+}
+
+func (m *AchievementsStore) Webhook(ctx context.Context, userID string) (*store.AchievementWebhook, error) {
+	if m.WebhookFunc == nil {
+		panic("mocks: unexpected call to AchievementsStore.Webhook")
+	}
+	return m.WebhookFunc(ctx, userID)
+}
+
+// RecapsStore is a fake handlers.RecapsStore.
+type RecapsStore struct {
+	GenerateFunc func(ctx context.Context, userID string, year int) (*store.YearRecap, error)
+	GetFunc      func(ctx context.Context, userID string, year int) (*store.YearRecap, error)
+}
+
+func (m *RecapsStore) Generate(ctx context.Context, userID string, year int) (*store.YearRecap, error) {
+	if m.GenerateFunc == nil {
+		panic("mocks: unexpected call to RecapsStore.Generate")
+	}
+	return m.GenerateFunc(ctx, userID, year)
+}
+
+func (m *RecapsStore) Get(ctx context.Context, userID string, year int) (*store.YearRecap, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to RecapsStore.Get")
+	}
+	return m.GetFunc(ctx, userID, year)
+}
+
+// BiometricsStore is a fake handlers.BiometricsStore.
+type BiometricsStore struct {
+	DeleteHeartRateFunc func(ctx context.Context, userID string, dayID string) (bool, error)
+	HeartRateFunc       func(ctx context.Context, userID string, dayID string) (*store.HeartRate, error)
+	PutHeartRateFunc    func(ctx context.Context, userID string, dayID string, samples []biometrics.Sample, p biometrics.Profile) (*store.HeartRate, error)
+}
+
+func (m *BiometricsStore) DeleteHeartRate(ctx context.Context, userID string, dayID string) (bool, error) {
+	if m.DeleteHeartRateFunc == nil {
+		panic("mocks: unexpected call to BiometricsStore.DeleteHeartRate")
+	}
+	return m.DeleteHeartRateFunc(ctx, userID, dayID)
+}
+
+func (m *BiometricsStore) HeartRate(ctx context.Context, userID string, dayID string) (*store.HeartRate, error) {
+	if m.HeartRateFunc == nil {
+		panic("mocks: unexpected call to BiometricsStore.HeartRate")
+	}
+	return m.HeartRateFunc(ctx, userID, dayID)
+}
+
+func (m *BiometricsStore) PutHeartRate(ctx context.Context, userID string, dayID string, samples []biometrics.Sample, p biometrics.Profile) (*store.HeartRate, error) {
+	if m.PutHeartRateFunc == nil {
+		panic("mocks: unexpected call to BiometricsStore.PutHeartRate")
+	}
+	return m.PutHeartRateFunc(ctx, userID, dayID, samples, p)
+}
+
+// NutritionStore is a fake handlers.NutritionStore.
+type NutritionStore struct {
+	DashboardFunc func(ctx context.Context, userID string, from time.Time, to time.Time) (*store.NutritionDashboard, error)
+	DeleteFunc    func(ctx context.Context, userID string, id string) (bool, error)
+	ListFunc      func(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.NutritionEntry, error)
+	LogFunc       func(ctx context.Context, userID string, p store.NutritionParams) (*store.NutritionEntry, error)
+}
+
+func (m *NutritionStore) Dashboard(ctx context.Context, userID string, from time.Time, to time.Time) (*store.NutritionDashboard, error) {
+	if m.DashboardFunc == nil {
+		panic("mocks: unexpected call to NutritionStore.Dashboard")
+	}
+	return m.DashboardFunc(ctx, userID, from, to)
+}
+
+func (m *NutritionStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to NutritionStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *NutritionStore) List(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.NutritionEntry, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to NutritionStore.List")
+	}
+	return m.ListFunc(ctx, userID, from, to)
+}
+
+func (m *NutritionStore) Log(ctx context.Context, userID string, p store.NutritionParams) (*store.NutritionEntry, error) {
+	if m.LogFunc == nil {
+		panic("mocks: unexpected call to NutritionStore.Log")
+	}
+	return m.LogFunc(ctx, userID, p)
+}
+
+// CheckinsStore is a fake handlers.CheckinsStore.
+type CheckinsStore struct {
+	DeleteFunc func(ctx context.Context, userID string, date time.Time) (bool, error)
+	ListFunc   func(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.Checkin, error)
+	PutFunc    func(ctx context.Context, userID string, date time.Time, p store.CheckinParams) (*store.Checkin, error)
+}
+
+func (m *CheckinsStore) Delete(ctx context.Context, userID string, date time.Time) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to CheckinsStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, date)
+}
+
+func (m *CheckinsStore) List(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.Checkin, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to CheckinsStore.List")
+	}
+	return m.ListFunc(ctx, userID, from, to)
+}
+
+func (m *CheckinsStore) Put(ctx context.Context, userID string, date time.Time, p store.CheckinParams) (*store.Checkin, error) {
+	if m.PutFunc == nil {
+		panic("mocks: unexpected call to CheckinsStore.Put")
+	}
+	return m.PutFunc(ctx, userID, date, p)
+}
+
+// GoalsStore is a fake handlers.GoalsStore.
+type GoalsStore struct {
+	CreateFunc func(ctx context.Context, userID string, p store.GoalParams) (*store.Goal, error)
+	DeleteFunc func(ctx context.Context, userID string, id string) (bool, error)
+	GetFunc    func(ctx context.Context, userID string, id string) (*store.Goal, error)
+	ListFunc   func(ctx context.Context, userID string) ([]store.Goal, error)
+	UpdateFunc func(ctx context.Context, userID string, id string, p store.GoalParams) (*store.Goal, error)
+}
+
+func (m *GoalsStore) Create(ctx context.Context, userID string, p store.GoalParams) (*store.Goal, error) {
+	if m.CreateFunc == nil {
+		panic("mocks: unexpected call to GoalsStore.Create")
+	}
+	return m.CreateFunc(ctx, userID, p)
+}
+
+func (m *GoalsStore) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	if m.DeleteFunc == nil {
+		panic("mocks: unexpected call to GoalsStore.Delete")
+	}
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+func (m *GoalsStore) Get(ctx context.Context, userID string, id string) (*store.Goal, error) {
+	if m.GetFunc == nil {
+		panic("mocks: unexpected call to GoalsStore.Get")
+	}
+	return m.GetFunc(ctx, userID, id)
+}
+
+func (m *GoalsStore) List(ctx context.Context, userID string) ([]store.Goal, error) {
+	if m.ListFunc == nil {
+		panic("mocks: unexpected call to GoalsStore.List")
+	}
+	return m.ListFunc(ctx, userID)
+}
+
+func (m *GoalsStore) Update(ctx context.Context, userID string, id string, p store.GoalParams) (*store.Goal, error) {
+	if m.UpdateFunc == nil {
+		panic("mocks: unexpected call to GoalsStore.Update")
+	}
+	return m.UpdateFunc(ctx, userID, id, p)
+}
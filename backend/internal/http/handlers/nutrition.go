@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+const (
+	// nutritionDashboardDays is the default dashboard range, ending today.
+	nutritionDashboardDays = 30
+	maxNutritionRangeDays  = 366
+	maxNutritionNote       = 500
+)
+
+type NutritionHandler struct {
+	Nutrition NutritionStore
+}
+
+type logNutritionRequest struct {
+	Date     string   `json:"date"` // YYYY-MM-DD, default today
+	Calories int      `json:"calories"`
+	ProteinG float64  `json:"proteinG"`
+	CarbsG   *float64 `json:"carbsG"`
+	FatG     *float64 `json:"fatG"`
+	Note     *string  `json:"note"`
+}
+
+// List returns nutrition entries, newest first. Query: from, to (YYYY-MM-DD).
+func (h *NutritionHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bounds, ok := dateBounds(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.Nutrition.List(r.Context(), uid, bounds[0], bounds[1])
+	if err != nil {
+		log.Printf("nutrition list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Log adds an entry of calories and macros to a date.
+func (h *NutritionHandler) Log(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req logNutritionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Calories < 0 || req.Calories > 20000 {
+		http.Error(w, "calories must be between 0 and 20000", http.StatusBadRequest)
+		return
+	}
+	for name, g := range map[string]*float64{"proteinG": &req.ProteinG, "carbsG": req.CarbsG, "fatG": req.FatG} {
+		if g != nil && (*g < 0 || *g > 2000) {
+			http.Error(w, name+" must be between 0 and 2000", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Calories == 0 && req.ProteinG == 0 {
+		http.Error(w, "calories or proteinG is required", http.StatusBadRequest)
+		return
+	}
+	if req.Note != nil {
+		if note := strings.TrimSpace(*req.Note); note == "" {
+			req.Note = nil
+		} else if len(note) > maxNutritionNote {
+			http.Error(w, "note is too long", http.StatusBadRequest)
+			return
+		} else {
+			req.Note = &note
+		}
+	}
+	date := time.Now().UTC()
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+		date = t
+	}
+	entry, err := h.Nutrition.Log(r.Context(), uid, store.NutritionParams{
+		Date: date, Calories: req.Calories, ProteinG: req.ProteinG, CarbsG: req.CarbsG, FatG: req.FatG, Note: req.Note,
+	})
+	if err != nil {
+		log.Printf("nutrition log error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (h *NutritionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Nutrition.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("nutrition delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Dashboard returns each day's intake next to its training volume, with
+// averages on training and rest days and how calories track volume. Query:
+// from, to (YYYY-MM-DD), default the last 30 days.
+func (h *NutritionHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bounds, ok := dateBounds(w, r)
+	if !ok {
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if bounds[1] != nil {
+		to = *bounds[1]
+	}
+	from := to.AddDate(0, 0, -(nutritionDashboardDays - 1))
+	if bounds[0] != nil {
+		from = *bounds[0]
+	}
+	if from.After(to) || to.Sub(from) >= maxNutritionRangeDays*24*time.Hour {
+		http.Error(w, "from must be before to, at most 366 days apart", http.StatusBadRequest)
+		return
+	}
+	dash, err := h.Nutrition.Dashboard(r.Context(), uid, from, to)
+	if err != nil {
+		log.Printf("nutrition dashboard error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, dash)
+}
+
+// dateBounds parses the from and to query parameters (YYYY-MM-DD), either
+// of which may be absent. On a bad value it writes 400 and returns false.
+func dateBounds(w http.ResponseWriter, r *http.Request) ([2]*time.Time, bool) {
+	var bounds [2]*time.Time
+	for i, key := range []string{"from", "to"} {
+		if v := r.URL.Query().Get(key); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				http.Error(w, "invalid "+key, http.StatusBadRequest)
+				return bounds, false
+			}
+			bounds[i] = &t
+		}
+	}
+	return bounds, true
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sets, exercises and days carry an updatedAt that the database bumps on
+// every change. Their PATCH handlers answer with it as ETag and
+// Last-Modified, and honor If-Match or If-Unmodified-Since so that two
+// devices editing the same row don't silently overwrite each other. Their
+// routes always run in a request transaction, so the row locked for the
+// check stays locked through the update.
+
+// entityETag is the validator for a row last updated at updatedAt.
+func entityETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`"%d"`, updatedAt.UnixMicro())
+}
+
+func setValidators(w http.ResponseWriter, updatedAt time.Time) {
+	w.Header().Set("ETag", entityETag(updatedAt))
+	w.Header().Set("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+}
+
+// hasPrecondition reports whether the request makes its update conditional,
+// so the handler needs to lock and check the current row first.
+func hasPrecondition(r *http.Request) bool {
+	return r.Header.Get("If-Match") != "" || r.Header.Get("If-Unmodified-Since") != ""
+}
+
+// preconditionFailed evaluates If-Match, or If-Unmodified-Since when there is
+// no If-Match, against a row last updated at updatedAt. An unparseable date
+// is ignored.
+func preconditionFailed(r *http.Request, updatedAt time.Time) bool {
+	if header := r.Header.Get("If-Match"); header != "" {
+		etag := entityETag(updatedAt)
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || candidate == etag {
+				return false
+			}
+		}
+		return true
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since"))
+	if err != nil {
+		return false
+	}
+	return updatedAt.Truncate(time.Second).After(since)
+}
+
+// writePreconditionFailed answers 412 with the row as it is now, for the
+// client to merge and retry.
+func writePreconditionFailed(w http.ResponseWriter, updatedAt time.Time, current any) {
+	setValidators(w, updatedAt)
+	writeJSON(w, http.StatusPreconditionFailed, current)
+}
@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/store"
+)
+
+func TestSetsUpdatePrecondition(t *testing.T) {
+	current := time.Date(2024, 5, 1, 18, 30, 15, 123456000, time.UTC)
+	etag := fmt.Sprintf(`"%d"`, current.UnixMicro())
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		status  int
+		updated bool
+	}{
+		{name: "no precondition", status: http.StatusOK, updated: true},
+		{name: "matching etag", header: "If-Match", value: etag, status: http.StatusOK, updated: true},
+		{name: "stale etag", header: "If-Match", value: `"1"`, status: http.StatusPreconditionFailed},
+		{name: "unmodified since", header: "If-Unmodified-Since", value: current.Format(http.TimeFormat), status: http.StatusOK, updated: true},
+		{name: "modified since", header: "If-Unmodified-Since", value: current.Add(-time.Minute).Format(http.TimeFormat), status: http.StatusPreconditionFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			updated := false
+			sets := &mocks.SetsStore{
+				GetForUpdateFunc: func(_ context.Context, _, id string) (*models.Set, error) {
+					return &models.Set{ID: id, Reps: 5, UpdatedAt: current}, nil
+				},
+				UpdateFunc: func(_ context.Context, p store.UpdateSetParams) (*models.Set, error) {
+					updated = true
+					return &models.Set{ID: p.ID, Reps: *p.Reps, UpdatedAt: current.Add(time.Minute)}, nil
+				},
+			}
+			h := &handlers.SetsHandler{Sets: sets}
+			r := newRequest(http.MethodPatch, "/api/sets/set-1", `{"reps":8}`, "user-1", map[string]string{"id": "set-1"})
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+			w := httptest.NewRecorder()
+			h.Update(w, r)
+			if w.Code != tc.status || updated != tc.updated {
+				t.Fatalf("status = %d, updated = %t; want %d, %t", w.Code, updated, tc.status, tc.updated)
+			}
+			if w.Header().Get("ETag") == "" {
+				t.Error("missing ETag")
+			}
+			if tc.status == http.StatusPreconditionFailed {
+				var got models.Set
+				if err := json.NewDecoder(w.Body).Decode(&got); err != nil || got.Reps != 5 {
+					t.Errorf("412 body = %+v, %v; want the current set", got, err)
+				}
+			}
+		})
+	}
+}
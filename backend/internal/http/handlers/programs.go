@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/programs"
+	"exercise-tracker/internal/store"
+)
+
+type ProgramsHandler struct {
+	Programs ProgramsStore
+}
+
+func (h *ProgramsHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	programs, err := h.Programs.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("programs list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": programs})
+}
+
+func (h *ProgramsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	prog, err := h.Programs.Get(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("programs get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if prog == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, prog)
+}
+
+func (h *ProgramsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req store.ProgramInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prog, err := h.Programs.Create(r.Context(), uid, req)
+	if err != nil {
+		log.Printf("programs create error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, prog)
+}
+
+func (h *ProgramsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req store.ProgramInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prog, err := h.Programs.Replace(r.Context(), uid, chi.URLParam(r, "id"), req)
+	if err != nil {
+		log.Printf("programs update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if prog == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, prog)
+}
+
+func (h *ProgramsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	okDel, err := h.Programs.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("programs delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !okDel {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type scheduleProgramRequest struct {
+	StartDate string `json:"startDate"` // YYYY-MM-DD, first day of week 1
+}
+
+// Schedule materializes the program into workout days starting at startDate.
+func (h *ProgramsHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req scheduleProgramRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		http.Error(w, "invalid startDate", http.StatusBadRequest)
+		return
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if start.Before(today) {
+		http.Error(w, "startDate must not be in the past", http.StatusBadRequest)
+		return
+	}
+	res, err := h.Programs.Schedule(r.Context(), uid, chi.URLParam(r, "id"), start)
+	if writeQuotaError(w, err) {
+		return
+	}
+	if err != nil {
+		log.Printf("programs schedule error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if res == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+type templateView struct {
+	programs.Template
+	TrainingMaxes []string `json:"trainingMaxes"`
+}
+
+// Templates lists the built-in program templates with the lifts each needs a
+// training max for.
+func (h *ProgramsHandler) Templates(w http.ResponseWriter, r *http.Request) {
+	items := make([]templateView, len(programs.Builtin))
+	for i, t := range programs.Builtin {
+		items[i] = templateView{Template: t, TrainingMaxes: t.TrainingMaxes()}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+type fromTemplateRequest struct {
+	Name          string             `json:"name"`          // defaults to the template's
+	Weeks         int                `json:"weeks"`         // defaults to the template's
+	TrainingMaxes map[string]float64 `json:"trainingMaxes"` // kg by lift key
+}
+
+// FromTemplate creates a program from the built-in {templateId}, planning
+// every week's weights from the user's training maxes.
+func (h *ProgramsHandler) FromTemplate(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	tmpl := programs.Find(chi.URLParam(r, "templateId"))
+	if tmpl == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var req fromTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Weeks < 0 || req.Weeks > programs.MaxWeeks {
+		http.Error(w, fmt.Sprintf("weeks must be between 1 and %d", programs.MaxWeeks), http.StatusBadRequest)
+		return
+	}
+	for _, lift := range tmpl.TrainingMaxes() {
+		if kg := req.TrainingMaxes[lift]; kg <= 0 || kg > 1000 {
+			http.Error(w, "trainingMaxes."+lift+" must be between 0 and 1000 kg", http.StatusBadRequest)
+			return
+		}
+	}
+	slugs := tmpl.Slugs()
+	catalog, err := h.Programs.CatalogIDs(r.Context(), uid, slugs)
+	if err != nil {
+		log.Printf("programs template catalog error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	var missing []string
+	for _, slug := range slugs {
+		if _, ok := catalog[slug]; !ok {
+			missing = append(missing, slug)
+		}
+	}
+	if len(missing) > 0 {
+		http.Error(w, "catalog is missing "+strings.Join(missing, ", "), http.StatusConflict)
+		return
+	}
+	in, err := tmpl.Build(strings.TrimSpace(req.Name), req.Weeks, req.TrainingMaxes, catalog)
+	if err == nil {
+		err = in.Validate()
+	}
+	if err != nil {
+		log.Printf("programs template build error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	prog, err := h.Programs.Create(r.Context(), uid, in)
+	if err != nil {
+		log.Printf("programs create error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, prog)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/parse"
+	"exercise-tracker/internal/store"
+)
+
+const (
+	maxQuickLogText = 2000
+	// quickLogMatches is the best catalog match plus alternatives the client
+	// can offer instead.
+	quickLogMatches = 3
+)
+
+type quickLogRequest struct {
+	Text string `json:"text"`
+	Date string `json:"date"` // YYYY-MM-DD, default today (UTC)
+	// CatalogIDs overrides the matched exercise per entry, by position; ""
+	// keeps the match.
+	CatalogIDs     []string `json:"catalogIds"`
+	DryRun         bool     `json:"dryRun"`
+	IdempotencyKey string   `json:"idempotencyKey"`
+}
+
+type quickLogEntry struct {
+	parse.Entry
+	CatalogID    *string              `json:"catalogId"`
+	CatalogName  *string              `json:"catalogName"`
+	Alternatives []store.CatalogMatch `json:"alternatives"`
+	ExerciseID   string               `json:"exerciseId,omitempty"`
+}
+
+type quickLogResponse struct {
+	Applied     bool               `json:"applied"`
+	Date        string             `json:"date"`
+	DayID       string             `json:"dayId,omitempty"`
+	Entries     []quickLogEntry    `json:"entries"`
+	Mapping     *store.SaveMapping `json:"mapping,omitempty"`
+	ServerEpoch int64              `json:"serverEpoch,omitempty"`
+	Error       *saveErrorResponse `json:"error,omitempty"`
+}
+
+// QuickLog parses free text such as "bench 3x5 @ 80kg, squat 5x5 100",
+// matches each exercise against the catalog and logs it on the day through
+// the save pipeline. With dryRun it only returns the parsed entries for
+// confirmation. Any entry without a catalog match fails the whole request
+// with 422 and the entries, so the client can pick catalogIds.
+func (h *SaveHandler) QuickLog(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req quickLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if len(req.Text) > maxQuickLogText {
+		http.Error(w, fmt.Sprintf("text exceeds %d characters", maxQuickLogText), http.StatusBadRequest)
+		return
+	}
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.Date != "" {
+		d, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+		date = d
+	}
+	parsed, err := parse.QuickLog(req.Text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.CatalogIDs) > len(parsed) {
+		http.Error(w, "more catalogIds than entries", http.StatusBadRequest)
+		return
+	}
+
+	resp := quickLogResponse{Date: date.Format("2006-01-02"), Entries: make([]quickLogEntry, len(parsed))}
+	unmatched := 0
+	for i, p := range parsed {
+		e := quickLogEntry{Entry: p, Alternatives: []store.CatalogMatch{}}
+		matches, err := h.Catalog.MatchExercise(r.Context(), uid, p.Name, quickLogMatches)
+		if err != nil {
+			log.Printf("quick log match error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if len(matches) > 0 {
+			e.CatalogID, e.CatalogName = &matches[0].ID, &matches[0].Name
+			e.Alternatives = matches[1:]
+		}
+		if i < len(req.CatalogIDs) && req.CatalogIDs[i] != "" {
+			id := req.CatalogIDs[i]
+			e.CatalogID, e.CatalogName = &id, nil
+			for j := range matches {
+				if matches[j].ID == id {
+					e.CatalogName = &matches[j].Name
+				}
+			}
+		}
+		if e.CatalogID == nil {
+			unmatched++
+		}
+		resp.Entries[i] = e
+	}
+	if unmatched > 0 {
+		resp.Error = &saveErrorResponse{Code: "unmatched", Message: fmt.Sprintf("%d exercise(s) have no catalog match; pass catalogIds.", unmatched)}
+		writeJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+	if req.DryRun {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	day, err := h.Days.GetOrCreate(r.Context(), uid, date)
+	if err != nil {
+		log.Printf("quick log day error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	detail, err := h.Days.GetWithDetails(r.Context(), uid, day.ID)
+	if err != nil || detail == nil {
+		log.Printf("quick log day error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	position := 0
+	for _, ex := range detail.Exercises {
+		if ex.Position >= position {
+			position = ex.Position + 1
+		}
+	}
+	ops, err := quickLogOps(day.ID, position, resp.Entries)
+	if err != nil {
+		log.Printf("quick log ops error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if h.Limits.MaxOps > 0 && len(ops) > h.Limits.MaxOps {
+		writeSaveError(w, http.StatusRequestEntityTooLarge, "too_many_ops",
+			fmt.Sprintf("Quick log makes %d ops; the limit is %d. Split it up.", len(ops), h.Limits.MaxOps))
+		return
+	}
+	mapping, _, err := h.Service.ProcessBatch(r.Context(), uid, ops, req.IdempotencyKey)
+	if qe, ok := store.AsQuotaError(err); ok {
+		writeSaveError(w, http.StatusForbidden, "quota_exceeded", qe.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("quick log save error: %v", err)
+		if !store.IsSaveInputError(err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		resp.Error = &saveErrorResponse{Code: "invalid_request", Message: err.Error()}
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+	byLocal := map[string]string{}
+	for _, m := range mapping.Exercises {
+		byLocal[m.LocalID] = m.ID
+	}
+	for i := range resp.Entries {
+		resp.Entries[i].ExerciseID = byLocal[quickLogLocalID(i)]
+	}
+	resp.Applied, resp.DayID, resp.Mapping = true, day.ID, &mapping
+	resp.ServerEpoch = h.afterCommit(r, uid, mapping)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func quickLogLocalID(i int) string { return fmt.Sprintf("quicklog-%d", i) }
+
+// quickLogOps turns matched entries into createExercise and createSet ops,
+// appending the exercises to the day from position on.
+func quickLogOps(dayID string, position int, entries []quickLogEntry) ([]json.RawMessage, error) {
+	var ops []json.RawMessage
+	add := func(op map[string]any) error {
+		b, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, b)
+		return nil
+	}
+	for i, e := range entries {
+		if e.CatalogID == nil {
+			return nil, errors.New("unmatched entry")
+		}
+		exLocal := quickLogLocalID(i)
+		if err := add(map[string]any{
+			"type": "createExercise", "localId": exLocal, "dayId": dayID,
+			"catalogId": *e.CatalogID, "position": position + i,
+		}); err != nil {
+			return nil, err
+		}
+		for j, s := range e.Sets {
+			if err := add(map[string]any{
+				"type": "createSet", "localId": fmt.Sprintf("%s-%d", exLocal, j), "exerciseId": exLocal,
+				"position": j, "reps": s.Reps, "weightKg": s.WeightKg, "setType": "strength",
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ops, nil
+}
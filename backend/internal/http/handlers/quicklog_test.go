@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/store"
+)
+
+func TestQuickLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		ops    int
+	}{
+		{name: "parse error", body: `{"text":"bench heavy"}`, status: http.StatusBadRequest},
+		{name: "unmatched", body: `{"text":"zercher 3x5 60"}`, status: http.StatusUnprocessableEntity},
+		{name: "override", body: `{"text":"zercher 3x5 60","catalogIds":["cat-zercher"],"dryRun":true}`, status: http.StatusOK},
+		{name: "dry run", body: `{"text":"bench 3x5 @ 80kg","dryRun":true}`, status: http.StatusOK},
+		{name: "applied", body: `{"text":"bench 3x5 @ 80kg, bench 1x8 60","date":"2026-03-02"}`, status: http.StatusOK, ops: 2 + 4},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var ops []json.RawMessage
+			catalog := &mocks.CatalogStore{
+				MatchExerciseFunc: func(_ context.Context, _ string, name string, _ int) ([]store.CatalogMatch, error) {
+					if !strings.HasPrefix(name, "bench") {
+						return nil, nil
+					}
+					return []store.CatalogMatch{{ID: "cat-bench", Name: "Bench Press", Score: 1}}, nil
+				},
+			}
+			days := &mocks.DaysStore{
+				GetOrCreateFunc: func(_ context.Context, _ string, date time.Time) (*models.WorkoutDay, error) {
+					return &models.WorkoutDay{ID: "day-1", WorkoutDate: date}, nil
+				},
+				GetWithDetailsFunc: func(_ context.Context, _, _ string) (*models.DayWithDetails, error) {
+					return &models.DayWithDetails{Exercises: []models.Exercise{{Position: 0}}}, nil
+				},
+			}
+			saves := &mocks.SaveStore{
+				ProcessBatchFunc: func(_ context.Context, _ string, raw []json.RawMessage, _ string) (store.SaveMapping, time.Time, error) {
+					ops = raw
+					return store.SaveMapping{Exercises: []store.LocalIdMap{{LocalID: "quicklog-0", ID: "ex-1"}}}, time.Now(), nil
+				},
+				SetEpochFunc: func(context.Context, string, int64) error { return nil },
+			}
+			h := &handlers.SaveHandler{Service: saves, Days: days, Catalog: catalog}
+			w := httptest.NewRecorder()
+			h.QuickLog(w, newRequest(http.MethodPost, "/api/quicklog", tc.body, "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+			if len(ops) != tc.ops {
+				t.Fatalf("ops = %d, want %d", len(ops), tc.ops)
+			}
+			if tc.ops > 0 && !strings.Contains(string(ops[0]), `"position":1`) {
+				t.Fatalf("first op = %s, want position after the day's exercises", ops[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	apphttp "exercise-tracker/internal/http"
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/realtime"
+)
+
+type RealtimeHandler struct {
+	Hub *realtime.Hub
+	// Origins are the frontend origins allowed to open a socket, as for
+	// CORS. Browsers send the session cookie cross-site, so a handshake from
+	// any other page is refused; with none configured (development) any
+	// origin is accepted.
+	Origins apphttp.Origins
+}
+
+type realtimeMessage struct {
+	Type            string `json:"type"`
+	ID              string `json:"id"`
+	ExerciseID      string `json:"exerciseId"`
+	DurationSeconds int    `json:"durationSeconds"`
+}
+
+// maxRestTimerSeconds caps client-requested timers at one hour.
+const maxRestTimerSeconds = 3600
+
+// Connect upgrades the request to a websocket and streams the user's events.
+func (h *RealtimeHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && len(h.Origins) > 0 && !h.Origins.Allow(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	conn, err := realtime.Upgrade(w, r)
+	if err != nil {
+		if errors.Is(err, realtime.ErrNotWebSocket) {
+			http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		log.Printf("realtime upgrade error: %v", err)
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	h.Hub.Serve(uid, conn, func(raw []byte) {
+		var msg realtimeMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return
+		}
+		id := strings.TrimSpace(msg.ID)
+		switch msg.Type {
+		case "restTimer.start":
+			if id == "" || msg.DurationSeconds <= 0 || msg.DurationSeconds > maxRestTimerSeconds {
+				return
+			}
+			h.Hub.StartRestTimer(uid, realtime.RestTimer{
+				ID:              id,
+				ExerciseID:      strings.TrimSpace(msg.ExerciseID),
+				DurationSeconds: msg.DurationSeconds,
+			})
+		case "restTimer.cancel":
+			if id != "" {
+				h.Hub.CancelRestTimer(uid, id)
+			}
+		}
+	})
+}
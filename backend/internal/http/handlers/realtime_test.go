@@ -0,0 +1,33 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apphttp "exercise-tracker/internal/http"
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/realtime"
+)
+
+func TestRealtimeConnectOrigin(t *testing.T) {
+	origins, err := apphttp.ParseOrigins("https://fitlog.app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &handlers.RealtimeHandler{Hub: realtime.NewHub(), Origins: origins}
+	for origin, status := range map[string]int{
+		"https://evil.example": http.StatusForbidden,
+		// An allowed origin gets past the check to the handshake, which
+		// this plain request doesn't make.
+		"https://fitlog.app": http.StatusUpgradeRequired,
+	} {
+		r := newRequest(http.MethodGet, "/api/ws", "", "user-1", nil)
+		r.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		h.Connect(rec, r)
+		if rec.Code != status {
+			t.Errorf("origin %s: status %d, want %d", origin, rec.Code, status)
+		}
+	}
+}
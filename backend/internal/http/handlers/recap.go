@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"exercise-tracker/internal/http/middleware"
+)
+
+type RecapHandler struct {
+	Recaps RecapsStore
+}
+
+// minRecapYear bounds the year query.
+const minRecapYear = 2000
+
+// Get returns the caller's Year in Review for a finished year. Query: year
+// (default last year). It is served from the cache the year-end job fills,
+// and generated on the spot when missing or out of date.
+func (h *RecapHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	current := time.Now().UTC().Year()
+	year := current - 1
+	if v := r.URL.Query().Get("year"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < minRecapYear || n >= current {
+			http.Error(w, "year must be a past year", http.StatusBadRequest)
+			return
+		}
+		year = n
+	}
+	recap, err := h.Recaps.Get(r.Context(), uid, year)
+	if err != nil {
+		log.Printf("get recap error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if recap == nil {
+		if recap, err = h.Recaps.Generate(r.Context(), uid, year); err != nil {
+			log.Printf("generate recap error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, recap)
+}
@@ -0,0 +1,59 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/store"
+)
+
+func TestRecapGet(t *testing.T) {
+	last := time.Now().UTC().Year() - 1
+	tests := []struct {
+		name      string
+		query     string
+		cached    bool
+		status    int
+		year      int
+		generated bool
+	}{
+		{name: "bad year", query: "?year=abc", status: http.StatusBadRequest},
+		{name: "current year", query: "?year=" + strconv.Itoa(last+1), status: http.StatusBadRequest},
+		{name: "cached", cached: true, status: http.StatusOK, year: last},
+		{name: "generated on request", query: "?year=2021", status: http.StatusOK, year: 2021, generated: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotYear int
+			generated := false
+			recaps := &mocks.RecapsStore{
+				GetFunc: func(_ context.Context, _ string, year int) (*store.YearRecap, error) {
+					gotYear = year
+					if tc.cached {
+						return &store.YearRecap{Year: year}, nil
+					}
+					return nil, nil
+				},
+				GenerateFunc: func(_ context.Context, _ string, year int) (*store.YearRecap, error) {
+					generated = true
+					return &store.YearRecap{Year: year}, nil
+				},
+			}
+			h := &handlers.RecapHandler{Recaps: recaps}
+			w := httptest.NewRecorder()
+			h.Get(w, newRequest(http.MethodGet, "/api/stats/recap"+tc.query, "", "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+			if tc.status == http.StatusOK && (gotYear != tc.year || generated != tc.generated) {
+				t.Fatalf("year = %d, generated = %v; want %d, %v", gotYear, generated, tc.year, tc.generated)
+			}
+		})
+	}
+}
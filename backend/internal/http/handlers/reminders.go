@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/linkmeta"
+	"exercise-tracker/internal/notify"
+	"exercise-tracker/internal/reminders"
+	"exercise-tracker/internal/store"
+)
+
+type RemindersHandler struct {
+	Reminders RemindersStore
+	// Push is nil when VAPID keys aren't configured; push subscriptions are
+	// still accepted but PushKey reports 404.
+	Push *notify.WebPush
+}
+
+var timeOfDayRe = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+type reminderRequest struct {
+	DaysOfWeek []int    `json:"daysOfWeek"` // 1 = Monday .. 7 = Sunday
+	Time       string   `json:"time"`       // HH:MM, local to timezone
+	Timezone   string   `json:"timezone"`   // IANA name, e.g. Europe/Berlin
+	Channels   []string `json:"channels"`   // email, push
+	Enabled    *bool    `json:"enabled"`    // default true
+}
+
+func (req reminderRequest) params(now time.Time) (store.ReminderParams, string) {
+	p := store.ReminderParams{
+		DaysOfWeek: req.DaysOfWeek,
+		TimeOfDay:  strings.TrimSpace(req.Time),
+		Timezone:   strings.TrimSpace(req.Timezone),
+		Channels:   req.Channels,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if len(p.DaysOfWeek) == 0 {
+		return p, "daysOfWeek is required"
+	}
+	for _, d := range p.DaysOfWeek {
+		if d < 1 || d > 7 {
+			return p, "daysOfWeek must be between 1 (Monday) and 7 (Sunday)"
+		}
+	}
+	if !timeOfDayRe.MatchString(p.TimeOfDay) {
+		return p, "time must be HH:MM"
+	}
+	if p.Timezone == "" {
+		return p, "timezone is required"
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return p, "unknown timezone"
+	}
+	if len(p.Channels) == 0 {
+		return p, "channels is required"
+	}
+	for _, c := range p.Channels {
+		if c != store.ReminderEmail && c != store.ReminderPush {
+			return p, "channels must be email or push"
+		}
+	}
+	next, err := reminders.NextRun(p, now)
+	if err != nil {
+		return p, err.Error()
+	}
+	p.NextRunAt = next
+	return p, ""
+}
+
+func (h *RemindersHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.Reminders.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("reminder list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *RemindersHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req reminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, msg := req.params(time.Now())
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	rem, err := h.Reminders.Create(r.Context(), uid, p)
+	if err != nil {
+		log.Printf("reminder create error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, rem)
+}
+
+// Update replaces the reminder's schedule and channels and reschedules it.
+func (h *RemindersHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req reminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, msg := req.params(time.Now())
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	rem, err := h.Reminders.Update(r.Context(), uid, chi.URLParam(r, "id"), p)
+	if err != nil {
+		log.Printf("reminder update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if rem == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, rem)
+}
+
+func (h *RemindersHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Reminders.Delete(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("reminder delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PushKey returns the VAPID public key browsers pass to
+// PushManager.subscribe as applicationServerKey.
+func (h *RemindersHandler) PushKey(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.Push == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"publicKey": h.Push.PublicKey()})
+}
+
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// Subscribe takes the browser's PushSubscription.toJSON() as the body.
+func (h *RemindersHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req pushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasPrefix(req.Endpoint, "https://") || req.Keys.P256dh == "" || req.Keys.Auth == "" {
+		http.Error(w, "endpoint (https) and keys.p256dh, keys.auth are required", http.StatusBadRequest)
+		return
+	}
+	if !validPushEndpoint(req.Endpoint) {
+		http.Error(w, "endpoint must be a public push service URL", http.StatusBadRequest)
+		return
+	}
+	sub, err := h.Reminders.SavePushSubscription(r.Context(), uid, store.PushSubscription{
+		Endpoint: req.Endpoint, P256dh: req.Keys.P256dh, Auth: req.Keys.Auth,
+	})
+	if err != nil {
+		log.Printf("push subscribe error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+// validPushEndpoint rejects endpoints that name a private or loopback
+// address outright; hostnames are checked again when delivery dials them.
+func validPushEndpoint(endpoint string) bool {
+	if _, err := linkmeta.ValidateURL(endpoint); err != nil {
+		return false
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return linkmeta.PublicIP(ip)
+	}
+	return true
+}
+
+func (h *RemindersHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Endpoint == "" {
+		http.Error(w, "endpoint is required", http.StatusBadRequest)
+		return
+	}
+	deleted, err := h.Reminders.DeletePushSubscription(r.Context(), uid, req.Endpoint)
+	if err != nil {
+		log.Printf("push unsubscribe error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/store"
+)
+
+func TestRemindersCreateValidation(t *testing.T) {
+	bodies := map[string]string{
+		"no days":      `{"time":"07:30","timezone":"UTC","channels":["email"]}`,
+		"bad day":      `{"daysOfWeek":[0],"time":"07:30","timezone":"UTC","channels":["email"]}`,
+		"bad time":     `{"daysOfWeek":[1],"time":"7:30","timezone":"UTC","channels":["email"]}`,
+		"bad timezone": `{"daysOfWeek":[1],"time":"07:30","timezone":"Mars/Olympus","channels":["email"]}`,
+		"bad channel":  `{"daysOfWeek":[1],"time":"07:30","timezone":"UTC","channels":["sms"]}`,
+	}
+	// The store must not be reached for invalid input; the empty mock panics
+	// if it is.
+	h := &handlers.RemindersHandler{Reminders: &mocks.RemindersStore{}}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.Create(w, newRequest(http.MethodPost, "/api/settings/reminders", body, "user-1", nil))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400 (body %q)", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRemindersCreateSchedulesNextRun(t *testing.T) {
+	reminders := &mocks.RemindersStore{
+		CreateFunc: func(_ context.Context, _ string, p store.ReminderParams) (*store.Reminder, error) {
+			if !p.Enabled {
+				t.Error("enabled should default to true")
+			}
+			if p.NextRunAt == nil {
+				t.Error("next run was not computed")
+			}
+			return &store.Reminder{ID: "rem-1", NextRunAt: p.NextRunAt}, nil
+		},
+	}
+	h := &handlers.RemindersHandler{Reminders: reminders}
+	w := httptest.NewRecorder()
+	body := `{"daysOfWeek":[1,3,5],"time":"07:30","timezone":"Europe/Berlin","channels":["email","push"]}`
+	h.Create(w, newRequest(http.MethodPost, "/api/settings/reminders", body, "user-1", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (body %q)", w.Code, w.Body.String())
+	}
+}
+
+func TestRemindersUpdateStoreError(t *testing.T) {
+	reminders := &mocks.RemindersStore{
+		UpdateFunc: func(context.Context, string, string, store.ReminderParams) (*store.Reminder, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	h := &handlers.RemindersHandler{Reminders: reminders}
+	w := httptest.NewRecorder()
+	body := `{"daysOfWeek":[7],"time":"18:00","timezone":"UTC","channels":["email"]}`
+	h.Update(w, newRequest(http.MethodPut, "/api/settings/reminders/rem-1", body, "user-1", map[string]string{"id": "rem-1"}))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestRemindersSubscribeRejectsPrivateEndpoints(t *testing.T) {
+	h := &handlers.RemindersHandler{Reminders: &mocks.RemindersStore{}}
+	for _, endpoint := range []string{"http://push.example.com/x", "https://127.0.0.1/x", "https://169.254.169.254/latest", "https://[::1]/x", "https://localhost:8080/x"} {
+		w := httptest.NewRecorder()
+		body := `{"endpoint":"` + endpoint + `","keys":{"p256dh":"k","auth":"a"}}`
+		h.Subscribe(w, newRequest(http.MethodPost, "/api/settings/push/subscriptions", body, "user-1", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", endpoint, w.Code)
+		}
+	}
+}
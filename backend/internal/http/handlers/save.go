@@ -2,17 +2,28 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"exercise-tracker/internal/achievements"
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/realtime"
 	"exercise-tracker/internal/store"
 )
 
 type SaveHandler struct {
-	Service *store.Save
+	Service      SaveStore
+	Sets         SetsStore
+	Hub          *realtime.Hub
+	Achievements *achievements.Engine
+	Limits       store.SaveLimits
+	// Days and Catalog are only used by QuickLog.
+	Days    DaysStore
+	Catalog CatalogStore
 }
 
 type saveRequest struct {
@@ -20,6 +31,12 @@ type saveRequest struct {
 	IdempotencyKey  string            `json:"idempotencyKey"`
 	ClientEpoch     int64             `json:"clientEpoch"`
 	Ops             []json.RawMessage `json:"ops"`
+	// ContinueOnError applies ops independently and reports per-op results
+	// instead of rolling back the whole batch on the first failure.
+	ContinueOnError bool `json:"continueOnError"`
+	// AutoRest inserts the exercise's default rest between created sets;
+	// see store.WithAutoRest.
+	AutoRest bool `json:"autoRest"`
 }
 
 type saveResponse struct {
@@ -28,6 +45,10 @@ type saveResponse struct {
 	UpdatedAt time.Time            `json:"updatedAt,omitempty"`
   	ServerEpoch int64              `json:"serverEpoch,omitempty"`
 	Error     *saveErrorResponse   `json:"error,omitempty"`
+	Results   []store.SaveOpResult `json:"results,omitempty"`
+	// Changes is set on stale_epoch conflicts: what changed on the server
+	// since the client's epoch.
+	Changes *store.SyncChanges `json:"changes,omitempty"`
 }
 
 type saveErrorResponse struct {
@@ -42,8 +63,17 @@ func (h *SaveHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if h.Limits.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.Limits.MaxBodyBytes)
+	}
 	var req saveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeSaveError(w, http.StatusRequestEntityTooLarge, "payload_too_large",
+				fmt.Sprintf("Request body exceeds %d bytes.", tooLarge.Limit))
+			return
+		}
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
@@ -58,31 +88,52 @@ func (h *SaveHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, saveResponse{Applied: false, Mapping: store.SaveMapping{}})
 		return
 	}
+	if err := h.Limits.Check(req.IdempotencyKey, req.Ops); err != nil {
+		writeSaveLimitError(w, err)
+		return
+	}
 
 	// Epoch pre-check
 	serverEpoch := h.Service.CurrentEpoch(r.Context(), uid)
 	if req.ClientEpoch > 0 && req.ClientEpoch < serverEpoch {
+		changes, err := h.Service.ChangesSince(r.Context(), uid, req.ClientEpoch)
+		if err != nil {
+			// The client can still recover by refetching.
+			log.Printf("save changes error: %v", err)
+		}
 		writeJSON(w, http.StatusConflict, saveResponse{
 			Applied:     false,
 			ServerEpoch: serverEpoch,
 			Error:       &saveErrorResponse{Code: "stale_epoch", Message: "Client epoch behind server."},
+			Changes:     changes,
 		})
 		return
 	}
+	if req.AutoRest {
+		r = r.WithContext(store.WithAutoRest(r.Context()))
+	}
+	if req.ContinueOnError {
+		h.handlePartial(w, r, uid, req)
+		return
+	}
 	mapping, updatedAt, err := h.Service.ProcessBatch(r.Context(), uid, req.Ops, req.IdempotencyKey)
+	if qe, ok := store.AsQuotaError(err); ok {
+		writeSaveError(w, http.StatusForbidden, "quota_exceeded", qe.Error())
+		return
+	}
 	if err != nil {
 		log.Printf("save batch error: %v", err)
+		if !store.IsSaveInputError(err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
 		writeJSON(w, http.StatusBadRequest, saveResponse{
 			Applied: false,
 			Error:   &saveErrorResponse{Code: "invalid_request", Message: err.Error()},
 		})
 		return
 	}
-	// Update epoch after successful commit
-	serverEpoch = time.Now().UnixMilli()
-	if err := h.Service.SetEpoch(r.Context(), uid, serverEpoch); err != nil {
-		log.Printf("save epoch update error: %v", err)
-	}
+	serverEpoch = h.afterCommit(r, uid, mapping)
 	writeJSON(w, http.StatusOK, saveResponse{
 		Applied:     true,
 		Mapping:     mapping,
@@ -91,6 +142,82 @@ func (h *SaveHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handlePartial runs a continueOnError batch. The response is 200 whenever
+// the transaction commits; Applied is true if at least one op was applied and
+// Results lists the outcome of every op.
+func (h *SaveHandler) handlePartial(w http.ResponseWriter, r *http.Request, uid string, req saveRequest) {
+	mapping, results, updatedAt, err := h.Service.ProcessBatchPartial(r.Context(), uid, req.Ops, req.IdempotencyKey)
+	if err != nil {
+		log.Printf("save batch error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	applied := false
+	for _, res := range results {
+		if res.Status == store.SaveOpApplied {
+			applied = true
+			break
+		}
+	}
+	resp := saveResponse{
+		Applied:   applied,
+		Mapping:   mapping,
+		UpdatedAt: updatedAt,
+		Results:   results,
+	}
+	if applied {
+		resp.ServerEpoch = h.afterCommit(r, uid, mapping)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// afterCommit bumps the user's save epoch and queues realtime events for a
+// committed batch, returning the new epoch. With TX_PER_REQUEST the events
+// go out once the request transaction commits.
+func (h *SaveHandler) afterCommit(r *http.Request, uid string, mapping store.SaveMapping) int64 {
+	// Update epoch after successful commit
+	serverEpoch := time.Now().UnixMilli()
+	if err := h.Service.SetEpoch(r.Context(), uid, serverEpoch); err != nil {
+		log.Printf("save epoch update error: %v", err)
+	}
+	store.AfterCommit(r.Context(), func() {
+		h.Hub.Publish(uid, realtime.Event{Type: realtime.EventSaveEpoch, Data: map[string]int64{"serverEpoch": serverEpoch}})
+	})
+	if h.Sets != nil && len(mapping.Sets) > 0 {
+		ids := make([]string, 0, len(mapping.Sets))
+		for _, m := range mapping.Sets {
+			ids = append(ids, m.ID)
+		}
+		publishPersonalRecords(r, h.Sets, h.Hub, uid, ids)
+		h.Achievements.Enqueue(r.Context(), uid)
+	}
+	return serverEpoch
+}
+
+func writeSaveError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, saveResponse{
+		Applied: false,
+		Error:   &saveErrorResponse{Code: code, Message: message},
+	})
+}
+
+// writeSaveLimitError writes a *store.SaveLimitError from SaveLimits.Check.
+func writeSaveLimitError(w http.ResponseWriter, err error) {
+	var le *store.SaveLimitError
+	if !errors.As(err, &le) {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	switch le.Code {
+	case store.SaveLimitTooManyOps:
+		writeSaveError(w, http.StatusRequestEntityTooLarge, le.Code, le.Message)
+	case store.SaveLimitInvalidJSON:
+		http.Error(w, "invalid json", http.StatusBadRequest)
+	default:
+		writeSaveError(w, http.StatusUnprocessableEntity, le.Code, le.Message)
+	}
+}
+
 // Epoch returns the current server save epoch for the authenticated user.
 func (h *SaveHandler) Epoch(w http.ResponseWriter, r *http.Request) {
 	uid, ok := middleware.UserIDFromContext(r.Context())
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/http/middleware"
+)
+
+// sessionTTL is how long a sign-in lasts.
+const sessionTTL = 30 * 24 * time.Hour
+
+// maxUserAgentLen caps the stored User-Agent; it is only for display.
+const maxUserAgentLen = 512
+
+// startSession records a session for userID and sets its cookie.
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, userID string) error {
+	var sessionID string
+	if h.Sessions != nil {
+		ua := r.UserAgent()
+		if len(ua) > maxUserAgentLen {
+			ua = ua[:maxUserAgentLen]
+		}
+		sess, err := h.Sessions.Create(r.Context(), userID, ua, clientIP(r), time.Now().Add(sessionTTL))
+		if err != nil {
+			return err
+		}
+		sessionID = sess.ID
+	}
+	token, exp, err := auth.CreateToken(h.JWTSecret, userID, sessionID, sessionTTL)
+	if err != nil {
+		return err
+	}
+	mw := middleware.AuthConfig{JWTSecret: h.JWTSecret, CookieDomain: h.CookieDomain}
+	mw.SetSessionCookie(w, token, exp)
+	return nil
+}
+
+// sessionUser is the caller of a session or 2FA management route. Like API
+// key management, it needs a cookie session rather than an API key.
+func (h *AuthHandler) sessionUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	if _, viaKey := middleware.APIKeyScopeFromContext(r.Context()); viaKey {
+		http.Error(w, "api keys cannot manage the account", http.StatusForbidden)
+		return "", false
+	}
+	return uid, true
+}
+
+// ListSessions returns the caller's signed-in devices, flagging the one
+// making the request.
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	if h.Sessions == nil {
+		http.NotFound(w, r)
+		return
+	}
+	sessions, err := h.Sessions.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("session list error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	current, _ := middleware.SessionIDFromContext(r.Context())
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": sessions})
+}
+
+// RevokeSession signs a device out. Revoking the current session also
+// clears its cookie.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return
+	}
+	if h.Sessions == nil {
+		http.NotFound(w, r)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	revoked, err := h.Sessions.Revoke(r.Context(), uid, id)
+	if err != nil {
+		log.Printf("session revoke error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.NotFound(w, r)
+		return
+	}
+	if current, _ := middleware.SessionIDFromContext(r.Context()); current == id {
+		mw := middleware.AuthConfig{JWTSecret: h.JWTSecret, CookieDomain: h.CookieDomain}
+		mw.ClearSessionCookie(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
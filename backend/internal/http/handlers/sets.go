@@ -2,17 +2,42 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"exercise-tracker/internal/achievements"
 	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/realtime"
 	"exercise-tracker/internal/store"
 )
 
 type SetsHandler struct {
-	Sets *store.Sets
+	Sets         SetsStore
+	Hub          *realtime.Hub
+	Achievements *achievements.Engine
+}
+
+// publishPersonalRecords notifies the user's connected devices about any new
+// records among setIDs. Failures are logged; they never fail the request.
+func publishPersonalRecords(r *http.Request, sets SetsStore, hub *realtime.Hub, userID string, setIDs []string) {
+	if hub == nil {
+		return
+	}
+	prs, err := sets.DetectPersonalRecords(r.Context(), userID, setIDs)
+	if err != nil {
+		log.Printf("personal record detection error: %v", err)
+		return
+	}
+	store.AfterCommit(r.Context(), func() {
+		for _, pr := range prs {
+			hub.Publish(userID, realtime.Event{Type: realtime.EventPersonalRecord, Data: pr})
+		}
+	})
 }
 
 type createSetRequest struct {
@@ -20,10 +45,22 @@ type createSetRequest struct {
 	Reps        int      `json:"reps"`
 	WeightKg    float64  `json:"weightKg"`
 	RPE         *float64 `json:"rpe"`
+	RIR         *int     `json:"rir"` // reps in reserve; about 10 - rpe
 	IsWarmup    bool     `json:"isWarmup"`
 	RestSeconds *int     `json:"restSeconds"`
 	Tempo       *string  `json:"tempo"`
 	PerformedAt *string  `json:"performedAt"`
+	// Type is strength (default), cardio, duration or distance.
+	Type            string   `json:"type"`
+	DurationSeconds *int     `json:"durationSeconds"`
+	DistanceM       *float64 `json:"distanceM"`
+	// Side is left, right or both for unilateral work; omit otherwise.
+	Side *string `json:"side"`
+	// AssistedReps are the reps of Reps that were helped through;
+	// PartialReps come after the last full rep and aren't part of Reps.
+	ToFailure    bool `json:"toFailure"`
+	AssistedReps int  `json:"assistedReps"`
+	PartialReps  int  `json:"partialReps"`
 }
 
 func (h *SetsHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -45,34 +82,90 @@ func (h *SetsHandler) Create(w http.ResponseWriter, r *http.Request) {
 			performedAt = &t
 		}
 	}
+	if req.Type != "" && !models.ValidSetType(req.Type) {
+		http.Error(w, "type must be strength, cardio, duration or distance", http.StatusBadRequest)
+		return
+	}
+	if req.Side != nil && !models.ValidSide(*req.Side) {
+		http.Error(w, "side must be left, right or both", http.StatusBadRequest)
+		return
+	}
+	if req.Tempo != nil {
+		tempo, err := models.NormalizeTempo(*req.Tempo)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Tempo = &tempo
+		if tempo == "" {
+			req.Tempo = nil
+		}
+	}
+	if req.AssistedReps < 0 || req.AssistedReps > req.Reps {
+		http.Error(w, store.ErrAssistedReps.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.PartialReps < 0 {
+		http.Error(w, "partialReps must be >= 0", http.StatusBadRequest)
+		return
+	}
+	if err := models.CheckEffort(req.RPE, req.RIR); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	created, err := h.Sets.Create(r.Context(), store.CreateSetParams{
-		ExerciseID:  exerciseID,
-		UserID:      uid,
-		Position:    req.Position,
-		Reps:        req.Reps,
-		WeightKg:    req.WeightKg,
-		RPE:         req.RPE,
-		IsWarmup:    req.IsWarmup,
-		RestSeconds: req.RestSeconds,
-		Tempo:       req.Tempo,
-		PerformedAt: performedAt,
+		ExerciseID:      exerciseID,
+		UserID:          uid,
+		Position:        req.Position,
+		Reps:            req.Reps,
+		WeightKg:        req.WeightKg,
+		RPE:             req.RPE,
+		RIR:             req.RIR,
+		IsWarmup:        req.IsWarmup,
+		RestSeconds:     req.RestSeconds,
+		Tempo:           req.Tempo,
+		PerformedAt:     performedAt,
+		SetType:         req.Type,
+		DurationSeconds: req.DurationSeconds,
+		DistanceM:       req.DistanceM,
+		Side:            req.Side,
+		ToFailure:       req.ToFailure,
+		AssistedReps:    req.AssistedReps,
+		PartialReps:     req.PartialReps,
 	})
+	if errors.Is(err, store.ErrSetMeasurement) || errors.Is(err, store.ErrAssistedReps) || errors.Is(err, models.ErrEffortMismatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if writeQuotaError(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	publishPersonalRecords(r, h.Sets, h.Hub, uid, []string{created.ID})
+	h.Achievements.Enqueue(r.Context(), uid)
 	writeJSON(w, http.StatusCreated, created)
 }
 
 type updateSetRequest struct {
-	Position    *int     `json:"position"`
-	Reps        *int     `json:"reps"`
-	WeightKg    *float64 `json:"weightKg"`
-	RPE         *float64 `json:"rpe"`
-	IsWarmup    *bool    `json:"isWarmup"`
-	RestSeconds *int     `json:"restSeconds"`
-	Tempo       *string  `json:"tempo"`
-	PerformedAt *string  `json:"performedAt"`
+	Position        *int     `json:"position"`
+	Reps            *int     `json:"reps"`
+	WeightKg        *float64 `json:"weightKg"`
+	RPE             *float64 `json:"rpe"`
+	RIR             *int     `json:"rir"`
+	IsWarmup        *bool    `json:"isWarmup"`
+	RestSeconds     *int     `json:"restSeconds"`
+	Tempo           *string  `json:"tempo"`
+	PerformedAt     *string  `json:"performedAt"`
+	Type            *string  `json:"type"`
+	DurationSeconds *int     `json:"durationSeconds"`
+	DistanceM       *float64 `json:"distanceM"`
+	Side            *string  `json:"side"`
+	ToFailure       *bool    `json:"toFailure"`
+	AssistedReps    *int     `json:"assistedReps"`
+	PartialReps     *int     `json:"partialReps"`
 }
 
 func (h *SetsHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -94,18 +187,71 @@ func (h *SetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 			performedAt = &t
 		}
 	}
+	if req.Type != nil && !models.ValidSetType(*req.Type) {
+		http.Error(w, "type must be strength, cardio, duration or distance", http.StatusBadRequest)
+		return
+	}
+	if req.Side != nil && !models.ValidSide(*req.Side) {
+		http.Error(w, "side must be left, right or both", http.StatusBadRequest)
+		return
+	}
+	if req.Tempo != nil {
+		// An empty tempo clears it.
+		tempo, err := models.NormalizeTempo(*req.Tempo)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Tempo = &tempo
+	}
+	if req.PartialReps != nil && *req.PartialReps < 0 {
+		http.Error(w, "partialReps must be >= 0", http.StatusBadRequest)
+		return
+	}
+	if err := models.CheckEffort(req.RPE, req.RIR); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if hasPrecondition(r) {
+		current, err := h.Sets.GetForUpdate(r.Context(), uid, id)
+		if err != nil {
+			log.Printf("lock set error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if current == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if preconditionFailed(r, current.UpdatedAt) {
+			writePreconditionFailed(w, current.UpdatedAt, current)
+			return
+		}
+	}
 	updated, err := h.Sets.Update(r.Context(), store.UpdateSetParams{
-		ID:          id,
-		UserID:      uid,
-		Position:    req.Position,
-		Reps:        req.Reps,
-		WeightKg:    req.WeightKg,
-		RPE:         req.RPE,
-		IsWarmup:    req.IsWarmup,
-		RestSeconds: req.RestSeconds,
-		Tempo:       req.Tempo,
-		PerformedAt: performedAt,
+		ID:              id,
+		UserID:          uid,
+		Position:        req.Position,
+		Reps:            req.Reps,
+		WeightKg:        req.WeightKg,
+		RPE:             req.RPE,
+		RIR:             req.RIR,
+		IsWarmup:        req.IsWarmup,
+		RestSeconds:     req.RestSeconds,
+		Tempo:           req.Tempo,
+		PerformedAt:     performedAt,
+		SetType:         req.Type,
+		DurationSeconds: req.DurationSeconds,
+		DistanceM:       req.DistanceM,
+		Side:            req.Side,
+		ToFailure:       req.ToFailure,
+		AssistedReps:    req.AssistedReps,
+		PartialReps:     req.PartialReps,
 	})
+	if errors.Is(err, store.ErrSetMeasurement) || errors.Is(err, store.ErrAssistedReps) || errors.Is(err, models.ErrEffortMismatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
@@ -114,6 +260,11 @@ func (h *SetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	if req.WeightKg != nil || req.IsWarmup != nil {
+		publishPersonalRecords(r, h.Sets, h.Hub, uid, []string{updated.ID})
+	}
+	h.Achievements.Enqueue(r.Context(), uid)
+	setValidators(w, updated.UpdatedAt)
 	writeJSON(w, http.StatusOK, updated)
 }
 
@@ -250,3 +401,9 @@ func (h *SetsHandler) DeleteRest(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Reorder sets the order of an exercise's sets. Body {orderedIds} must list
+// each of the exercise's sets once.
+func (h *SetsHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	reorder(w, r, chi.URLParam(r, "id"), h.Sets.Reorder)
+}
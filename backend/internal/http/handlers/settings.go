@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/plates"
+	"exercise-tracker/internal/store"
+)
+
+type SettingsHandler struct {
+	Settings SettingsStore
+}
+
+type updateSettingsRequest struct {
+	BarWeightKg      *float64        `json:"barWeightKg"`
+	PlateIncrementKg *float64        `json:"plateIncrementKg"`
+	Units            *string         `json:"units"`      // kg | lb
+	Plates           *[]plates.Plate `json:"plates"`     // [{weight, count}] in units
+	BarWeights       *[]float64      `json:"barWeights"` // in units
+	// DefaultRestSeconds of 0 clears the default.
+	DefaultRestSeconds *int  `json:"defaultRestSeconds"`
+	StampSets          *bool `json:"stampSets"`
+	// DayVisibility is private or friends.
+	DayVisibility *string `json:"dayVisibility"`
+	// SmallestIncrement is in units; 0 clears it.
+	SmallestIncrement *float64 `json:"smallestIncrement"`
+}
+
+const (
+	maxPlateSizes = 20
+	maxBarWeights = 10
+	maxRestSecs   = 3600
+)
+
+func (req updateSettingsRequest) validate() string {
+	if req.BarWeightKg != nil && (*req.BarWeightKg < 0 || *req.BarWeightKg > 100) {
+		return "barWeightKg must be between 0 and 100"
+	}
+	if req.PlateIncrementKg != nil && (*req.PlateIncrementKg <= 0 || *req.PlateIncrementKg > 25) {
+		return "plateIncrementKg must be > 0 and at most 25"
+	}
+	if req.SmallestIncrement != nil && (*req.SmallestIncrement < 0 || *req.SmallestIncrement > 25) {
+		return "smallestIncrement must be between 0 and 25"
+	}
+	if req.DefaultRestSeconds != nil && (*req.DefaultRestSeconds < 0 || *req.DefaultRestSeconds > maxRestSecs) {
+		return "defaultRestSeconds must be between 0 and 3600"
+	}
+	if req.DayVisibility != nil && *req.DayVisibility != store.VisibilityPrivate && *req.DayVisibility != store.VisibilityFriends {
+		return "dayVisibility must be private or friends"
+	}
+	if req.Units != nil && *req.Units != plates.UnitKg && *req.Units != plates.UnitLb {
+		return "units must be kg or lb"
+	}
+	if req.Plates != nil {
+		if len(*req.Plates) > maxPlateSizes {
+			return "too many plate sizes"
+		}
+		for _, p := range *req.Plates {
+			if p.Weight <= 0 || p.Weight > 100 || p.Count < 0 || p.Count > 100 {
+				return "plates need a weight in (0, 100] and a count in [0, 100]"
+			}
+		}
+	}
+	if req.BarWeights != nil {
+		if len(*req.BarWeights) > maxBarWeights {
+			return "too many bar weights"
+		}
+		for _, b := range *req.BarWeights {
+			if b < 0 || b > 100 {
+				return "bar weights must be between 0 and 100"
+			}
+		}
+	}
+	return ""
+}
+
+func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	settings, err := h.Settings.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("settings get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// Update changes only the fields present in the body. Plates, bar weights and
+// the smallest increment are in the user's unit, so switching units without
+// sending them resets them to that unit's defaults.
+func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req updateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	params := store.UpdateSettingsParams{
+		BarWeightKg:      req.BarWeightKg,
+		PlateIncrementKg: req.PlateIncrementKg,
+		Units:            req.Units,
+
+		DefaultRestSeconds: req.DefaultRestSeconds,
+		StampSets:          req.StampSets,
+		DayVisibility:      req.DayVisibility,
+		SmallestIncrement:  req.SmallestIncrement,
+	}
+	if req.Plates != nil {
+		params.Plates = *req.Plates
+	}
+	if req.BarWeights != nil {
+		params.BarWeights = *req.BarWeights
+	}
+	if req.Units != nil && req.Plates == nil && req.BarWeights == nil {
+		current, err := h.Settings.Get(r.Context(), uid)
+		if err != nil {
+			log.Printf("settings get error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		params.ResetEquipment = current.Units != *req.Units
+	}
+	settings, err := h.Settings.Update(r.Context(), uid, params)
+	if err != nil {
+		log.Printf("settings update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// ExerciseRests lists the caller's per-exercise default rests.
+func (h *SettingsHandler) ExerciseRests(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.Settings.ExerciseRests(r.Context(), uid)
+	if err != nil {
+		log.Printf("exercise rests error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+type exerciseRestRequest struct {
+	RestSeconds int `json:"restSeconds"`
+}
+
+// SetExerciseRest sets the default rest for one catalog exercise,
+// overriding defaultRestSeconds.
+func (h *SettingsHandler) SetExerciseRest(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req exerciseRestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.RestSeconds < 1 || req.RestSeconds > maxRestSecs {
+		http.Error(w, "restSeconds must be between 1 and 3600", http.StatusBadRequest)
+		return
+	}
+	rest, err := h.Settings.SetExerciseRest(r.Context(), uid, chi.URLParam(r, "catalogId"), req.RestSeconds)
+	if errors.Is(err, store.ErrUnknownCatalogEntry) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("exercise rest update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rest)
+}
+
+func (h *SettingsHandler) DeleteExerciseRest(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Settings.DeleteExerciseRest(r.Context(), uid, chi.URLParam(r, "catalogId"))
+	if err != nil {
+		log.Printf("exercise rest delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/models"
+)
+
+const (
+	defaultShareTTL = 7 * 24 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+)
+
+// ShareHandler issues and serves read-only links to a single workout day.
+// Links are stateless signed tokens and stay valid until they expire.
+type ShareHandler struct {
+	Days      DaysStore
+	JWTSecret string
+}
+
+type shareDayRequest struct {
+	ExpiresInHours *int `json:"expiresInHours"` // default 168, max 720
+}
+
+// Create returns a public token for one of the user's days.
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	dayID := chi.URLParam(r, "dayId")
+	var req shareDayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	ttl := defaultShareTTL
+	if req.ExpiresInHours != nil {
+		ttl = time.Duration(*req.ExpiresInHours) * time.Hour
+		if ttl <= 0 || ttl > maxShareTTL {
+			http.Error(w, "expiresInHours must be between 1 and 720", http.StatusBadRequest)
+			return
+		}
+	}
+	day, err := h.Days.GetWithDetails(r.Context(), uid, dayID)
+	if err != nil {
+		log.Printf("share day error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if day == nil {
+		http.NotFound(w, r)
+		return
+	}
+	token, exp, err := auth.CreateShareToken(h.JWTSecret, uid, day.ID, ttl)
+	if err != nil {
+		log.Printf("share token error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"token":     token,
+		"path":      "/public/workouts/" + token,
+		"expiresAt": exp.UTC(),
+	})
+}
+
+// Get renders a shared day without authentication.
+func (h *ShareHandler) Get(w http.ResponseWriter, r *http.Request) {
+	claims, err := auth.ParseShareToken(h.JWTSecret, chi.URLParam(r, "token"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	day, err := h.Days.GetWithDetails(r.Context(), claims.OwnerID, claims.DayID)
+	if err != nil {
+		log.Printf("shared day error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if day == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	writeJSON(w, http.StatusOK, publicDay(day))
+}
+
+// publicDay strips owner identifiers from a day before it leaves the API
+// unauthenticated.
+func publicDay(d *models.DayWithDetails) *models.DayWithDetails {
+	d.UserID = ""
+	d.ProgramID = nil
+	d.Comments = nil
+	d.UnreadComments = 0
+	for i := range d.Exercises {
+		ex := &d.Exercises[i]
+		for j := range ex.Sets {
+			ex.Sets[j].UserID = ""
+		}
+		for _, e := range ex.Timeline {
+			if e.Set != nil {
+				e.Set.UserID = ""
+			}
+		}
+	}
+	return d
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"exercise-tracker/internal/store"
+)
+
+// RecomputeStats rebuilds the analytics rollups. Body: {userId?}; without a
+// user every account is rebuilt.
+func (h *AdminHandler) RecomputeStats(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if h.Analytics == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var req struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.UserID = strings.TrimSpace(req.UserID)
+	if req.UserID != "" {
+		u, err := h.Users.ByID(r.Context(), req.UserID)
+		if err != nil {
+			log.Printf("stats user lookup error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if u == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	days, err := h.Analytics.RecomputeStats(r.Context(), req.UserID)
+	if err != nil {
+		log.Printf("stats recompute error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	res := map[string]any{"days": days}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditStatsRecompute,
+		EntityType: "server",
+		EntityID:   req.UserID,
+		After:      res,
+	})
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"exercise-tracker/internal/biometrics"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+// The interfaces below are what handlers need from the store package, so
+// handler tests can swap in the fakes from handlers/mocks instead of a
+// database. Each lists only the methods handlers call.
+
+// UsersStore is implemented by *store.Users.
+type UsersStore interface {
+	ByEmail(ctx context.Context, email string) (*models.User, error)
+	ByID(ctx context.Context, id string) (*models.User, error)
+	CancelDeletion(ctx context.Context, id string) error
+	Claim(ctx context.Context, id string, email string, passwordHash string) (*models.User, error)
+	Create(ctx context.Context, email string, passwordHash string) (*models.User, error)
+	CreateGuest(ctx context.Context) (*models.User, error)
+	Purge(ctx context.Context, id string) error
+	ScheduleDeletion(ctx context.Context, id string, purgeAfter time.Time) error
+	UpdatePasswordHash(ctx context.Context, id string, passwordHash string) error
+}
+
+// DaysStore is implemented by *store.Days.
+type DaysStore interface {
+	ExerciseTimeline(ctx context.Context, userID string, exerciseID string) ([]models.ExerciseEntry, error)
+	FinishSession(ctx context.Context, userID string, dayID string, at time.Time) (*models.WorkoutDay, error)
+	GetByUserAndDate(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error)
+	GetForUpdate(ctx context.Context, userID string, dayID string) (*models.WorkoutDay, error)
+	GetOrCreate(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error)
+	GetWithDetails(ctx context.Context, userID string, dayID string) (*models.DayWithDetails, error)
+	ListWithDetails(ctx context.Context, userID string, dayIDs []string, dates []time.Time) ([]models.DayWithDetails, error)
+	SearchHistory(ctx context.Context, userID string, q string) ([]store.DaySearchResult, error)
+	SessionTimeline(ctx context.Context, userID string, dayID string) (*store.SessionTimeline, error)
+	SetNotes(ctx context.Context, userID string, dayID string, notes string) (*models.WorkoutDay, error)
+	SetRestDay(ctx context.Context, userID string, dayID string, rest bool) (*models.WorkoutDay, error)
+	SetVisibility(ctx context.Context, userID string, dayID string, visibility string) (*models.WorkoutDay, error)
+	StartSession(ctx context.Context, userID string, dayID string, at time.Time) (*models.WorkoutDay, error)
+	WeekSummary(ctx context.Context, userID string, start time.Time) ([]store.DaySummary, error)
+}
+
+// CatalogStore is implemented by *store.Catalog.
+type CatalogStore interface {
+	CacheStats() store.CatalogCacheStats
+	CatalogIDsWithoutImage(ctx context.Context, names []string) (map[string]string, error)
+	CreateCatalogEntryWithImage(ctx context.Context, entry store.CatalogEntry, imageData []byte, imageMimeType string) (*store.CatalogRecord, error)
+	DeadLinks(ctx context.Context, limit int) ([]store.DeadLink, error)
+	DeleteCatalogEntry(ctx context.Context, id string) error
+	DeleteTranslation(ctx context.Context, catalogID string, locale string) (bool, error)
+	DuplicateCandidates(ctx context.Context, minSimilarity float64, limit int) ([]store.CatalogDuplicate, error)
+	ExerciseProgress(ctx context.Context, catalogID string, userID string, metric string, formula progression.Formula, since *time.Time, maxPoints int) ([]store.ProgressPoint, error)
+	ExportCatalog(ctx context.Context, fn func(*store.CatalogRecord) error) error
+	FacetCounts(ctx context.Context, p store.CatalogSearchParams) (*store.CatalogFacetCounts, error)
+	Facets(ctx context.Context) (store.CatalogFacets, error)
+	GetCatalogEntry(ctx context.Context, id string) (*store.CatalogRecord, error)
+	GetCatalogImage(ctx context.Context, id string) ([]byte, string, error)
+	GetCatalogThumbnail(ctx context.Context, id string) ([]byte, string, error)
+	GetExerciseStats(ctx context.Context, catalogID string, userID string, limit int, offset int, formula progression.Formula) (*store.ExerciseStats, bool, error)
+	Localize(ctx context.Context, rec *store.CatalogRecord, locales []string) error
+	MatchExercise(ctx context.Context, userID string, name string, limit int) ([]store.CatalogMatch, error)
+	MergeCatalogEntries(ctx context.Context, sourceID string, targetID string) (*store.CatalogMergeResult, error)
+	MergeMuscles(ctx context.Context, source string, target string) (*store.MuscleMergeResult, error)
+	Muscles(ctx context.Context) ([]store.CanonicalMuscle, error)
+	PutCatalogImage(ctx context.Context, id string, data []byte, mimeType string) error
+	PutTranslation(ctx context.Context, t store.CatalogTranslation) (*store.CatalogTranslation, error)
+	ReviewCatalogEntry(ctx context.Context, id string, reviewerID string, status string, feedback *string) (*store.CatalogRecord, error)
+	Search(ctx context.Context, p store.CatalogSearchParams) (store.CatalogSearchResult, error)
+	Submissions(ctx context.Context, userID string, status string) ([]store.CatalogRecord, error)
+	SubmitCatalogEntry(ctx context.Context, userID string, entry store.CatalogEntry, imageData []byte, imageMimeType string) (*store.CatalogRecord, error)
+	Translations(ctx context.Context, catalogID string) ([]store.CatalogTranslation, error)
+	UpdateCatalogEntry(ctx context.Context, id string, entry store.CatalogEntry, imageData []byte, imageMimeType string, removeImage bool) error
+	Upsert(ctx context.Context, entries []store.CatalogEntry) (int, error)
+	Version(ctx context.Context) (int64, error)
+}
+
+// AuditStore is implemented by *store.Audit.
+type AuditStore interface {
+	List(ctx context.Context, f store.AuditFilter) ([]store.AuditEntry, error)
+	Record(ctx context.Context, p store.AuditRecordParams) error
+}
+
+// GymsStore is implemented by *store.Gyms.
+type GymsStore interface {
+	Create(ctx context.Context, userID string, p store.GymProfileParams) (*store.GymProfile, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	Get(ctx context.Context, userID string, id string) (*store.GymProfile, error)
+	List(ctx context.Context, userID string) ([]store.GymProfile, error)
+	Update(ctx context.Context, userID string, id string, p store.GymProfileParams) (*store.GymProfile, error)
+}
+
+// BodyweightStore is implemented by *store.Bodyweight.
+type BodyweightStore interface {
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	List(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.BodyweightEntry, error)
+	Log(ctx context.Context, userID string, date time.Time, weightKg float64) (*store.BodyweightEntry, error)
+}
+
+// SettingsStore is implemented by *store.Settings.
+type SettingsStore interface {
+	DeleteExerciseRest(ctx context.Context, userID string, catalogID string) (bool, error)
+	ExerciseRests(ctx context.Context, userID string) ([]store.ExerciseRest, error)
+	Get(ctx context.Context, userID string) (store.UserSettings, error)
+	SetExerciseRest(ctx context.Context, userID string, catalogID string, seconds int) (*store.ExerciseRest, error)
+	Update(ctx context.Context, userID string, p store.UpdateSettingsParams) (store.UserSettings, error)
+}
+
+// SetsStore is implemented by *store.Sets.
+type SetsStore interface {
+	Create(ctx context.Context, p store.CreateSetParams) (*models.Set, error)
+	CreateRest(ctx context.Context, p store.CreateRestParams) (*models.RestPeriod, error)
+	Delete(ctx context.Context, id string, userID string) (bool, error)
+	DeleteRest(ctx context.Context, restID string, userID string) (bool, error)
+	DetectPersonalRecords(ctx context.Context, userID string, setIDs []string) ([]store.PersonalRecord, error)
+	GetForUpdate(ctx context.Context, userID string, id string) (*models.Set, error)
+	InsertWarmups(ctx context.Context, userID string, exerciseID string, warmups []progression.WarmupSet) ([]models.Set, error)
+	Reorder(ctx context.Context, userID string, exerciseID string, ids []string) (bool, error)
+	Update(ctx context.Context, p store.UpdateSetParams) (*models.Set, error)
+	UpdateRest(ctx context.Context, p store.UpdateRestParams) (*models.RestPeriod, error)
+}
+
+// ExercisesStore is implemented by *store.Exercises.
+type ExercisesStore interface {
+	Create(ctx context.Context, userID string, dayID string, catalogID string, position int, comment *string) (*models.Exercise, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	Equipment(ctx context.Context, userID string, id string) (*store.ExerciseEquipment, error)
+	GetForUpdate(ctx context.Context, userID string, id string) (*models.Exercise, error)
+	Move(ctx context.Context, userID string, id string, dayID string, position *int) (*models.Exercise, error)
+	Reorder(ctx context.Context, userID string, dayID string, ids []string) (bool, error)
+	Update(ctx context.Context, userID string, id string, position *int, comment *string) (*models.Exercise, error)
+}
+
+// ProgramsStore is implemented by *store.Programs.
+type ProgramsStore interface {
+	CatalogIDs(ctx context.Context, userID string, slugs []string) (map[string]string, error)
+	Create(ctx context.Context, userID string, in store.ProgramInput) (*models.Program, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	Get(ctx context.Context, userID string, id string) (*models.Program, error)
+	List(ctx context.Context, userID string) ([]models.Program, error)
+	Replace(ctx context.Context, userID string, id string, in store.ProgramInput) (*models.Program, error)
+	Schedule(ctx context.Context, userID string, id string, start time.Time) (*store.ScheduleResult, error)
+}
+
+// AnalyticsStore is implemented by *store.Analytics.
+type AnalyticsStore interface {
+	Cardio(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklyCardio, error)
+	DayAdherence(ctx context.Context, userID string, dayID string) (*store.DayAdherence, error)
+	MuscleSplit(ctx context.Context, userID string, weeks int, secondaryFactor float64, now time.Time) ([]store.WeeklyMuscleSplit, error)
+	RecomputeStats(ctx context.Context, userID string) (int64, error)
+	Recovery(ctx context.Context, userID string, weeks int, now time.Time) (*store.Recovery, error)
+	SessionDurations(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklySessionDuration, error)
+	SideBalance(ctx context.Context, userID string, weeks int, now time.Time) ([]store.SideVolume, error)
+	TrainingLoad(ctx context.Context, userID string, weeks int, now time.Time) ([]store.WeeklyLoad, error)
+}
+
+// APIKeysStore is implemented by *store.APIKeys.
+type APIKeysStore interface {
+	Create(ctx context.Context, userID string, name string, prefix string, hash string, scope string) (*store.APIKey, error)
+	List(ctx context.Context, userID string) ([]store.APIKey, error)
+	Revoke(ctx context.Context, userID string, id string) (bool, error)
+}
+
+// SessionsStore is implemented by *store.Sessions.
+type SessionsStore interface {
+	Create(ctx context.Context, userID string, userAgent string, ip string, expiresAt time.Time) (*store.Session, error)
+	List(ctx context.Context, userID string) ([]store.Session, error)
+	Revoke(ctx context.Context, userID string, id string) (bool, error)
+}
+
+// TwoFactorStore is implemented by *store.TwoFactor.
+type TwoFactorStore interface {
+	Disable(ctx context.Context, userID string) error
+	Enable(ctx context.Context, userID string, step int64, recoveryHashes []string) (bool, error)
+	Enroll(ctx context.Context, userID string, secret string) (bool, error)
+	Get(ctx context.Context, userID string) (*store.TOTP, error)
+	RecoveryCodesLeft(ctx context.Context, userID string) (int, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID string, hash string) (bool, error)
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+}
+
+// CoachingStore is implemented by *store.Coaching.
+type CoachingStore interface {
+	Accept(ctx context.Context, linkID string, coachID string, coachEmail string) (*store.CoachLink, error)
+	Clients(ctx context.Context, coachID string, coachEmail string) ([]store.CoachLink, error)
+	Coaches(ctx context.Context, clientID string) ([]store.CoachLink, error)
+	Invite(ctx context.Context, clientID string, coachEmail string, canWrite bool) (*store.CoachLink, error)
+	Remove(ctx context.Context, linkID string, userID string, userEmail string) (bool, error)
+}
+
+// CommentsStore is implemented by *store.Comments.
+type CommentsStore interface {
+	Create(ctx context.Context, ownerID string, authorID string, dayID string, exerciseID *string, body string) (*models.WorkoutComment, error)
+	Delete(ctx context.Context, authorID string, id string) (bool, error)
+	ListByDay(ctx context.Context, ownerID string, dayID string) ([]models.WorkoutComment, error)
+	MarkRead(ctx context.Context, ownerID string, dayID string) (int64, error)
+	UnreadCounts(ctx context.Context, ownerID string) ([]store.UnreadCommentCount, error)
+}
+
+// RemindersStore is implemented by *store.Reminders.
+type RemindersStore interface {
+	Create(ctx context.Context, userID string, p store.ReminderParams) (*store.Reminder, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	DeletePushSubscription(ctx context.Context, userID string, endpoint string) (bool, error)
+	List(ctx context.Context, userID string) ([]store.Reminder, error)
+	SavePushSubscription(ctx context.Context, userID string, sub store.PushSubscription) (*store.PushSubscription, error)
+	Update(ctx context.Context, userID string, id string, p store.ReminderParams) (*store.Reminder, error)
+}
+
+// UsageStore is implemented by *store.Usage.
+type UsageStore interface {
+	DeleteUserQuota(ctx context.Context, userID string) (bool, error)
+	Defaults(ctx context.Context) (store.Quota, error)
+	Get(ctx context.Context, userID string) (*store.UserUsage, error)
+	SetDefaults(ctx context.Context, q store.Quota) (store.Quota, error)
+	SetUserQuota(ctx context.Context, userID string, q store.Quota) (*store.Quota, error)
+	UserQuota(ctx context.Context, userID string) (*store.Quota, error)
+}
+
+// SaveStore is implemented by *store.Save.
+type SaveStore interface {
+	ChangesSince(ctx context.Context, userID string, epoch int64) (*store.SyncChanges, error)
+	CurrentEpoch(ctx context.Context, userID string) int64
+	ProcessBatch(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (store.SaveMapping, time.Time, error)
+	ProcessBatchPartial(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (store.SaveMapping, []store.SaveOpResult, time.Time, error)
+	SetEpoch(ctx context.Context, userID string, epoch int64) error
+}
+
+// JournalStore is implemented by *store.Journal.
+type JournalStore interface {
+	DayHistory(ctx context.Context, userID string, dayID string, limit int) ([]store.JournalEntry, error)
+	Undo(ctx context.Context, userID string) ([]store.JournalEntry, error)
+}
+
+// TemplatesStore is implemented by *store.Templates.
+type TemplatesStore interface {
+	Delete(ctx context.Context, userID string, code string) (bool, error)
+	Get(ctx context.Context, code string) (*store.TemplateShare, error)
+	Import(ctx context.Context, userID string, code string, date time.Time) (*store.TemplateImport, error)
+	List(ctx context.Context, userID string) ([]store.TemplateShare, error)
+	Publish(ctx context.Context, userID string, kind string, sourceID string) (*store.TemplateShare, error)
+}
+
+// LeaderboardsStore is implemented by *store.Leaderboards.
+type LeaderboardsStore interface {
+	Leaderboard(ctx context.Context, catalogID string, viewerID string, formula progression.Formula, limit int) (*store.Leaderboard, error)
+	Profile(ctx context.Context, userID string) (store.LeaderboardProfile, error)
+	UpdateProfile(ctx context.Context, userID string, p store.UpdateLeaderboardProfileParams) (store.LeaderboardProfile, error)
+}
+
+// FriendsStore is implemented by *store.Friends.
+type FriendsStore interface {
+	Accept(ctx context.Context, id string, userID string, userEmail string) (*store.Friendship, error)
+	List(ctx context.Context, userID string, userEmail string) ([]store.Friendship, error)
+	Remove(ctx context.Context, id string, userID string, userEmail string) (bool, error)
+	Request(ctx context.Context, userID string, userEmail string, email string) (*store.Friendship, error)
+}
+
+// FeedStore is implemented by *store.Feed.
+type FeedStore interface {
+	List(ctx context.Context, userID string, before *time.Time, limit int) ([]store.FeedItem, error)
+	Refresh(ctx context.Context) error
+}
+
+// ChallengesStore is implemented by *store.Challenges.
+type ChallengesStore interface {
+	Create(ctx context.Context, userID string, p store.ChallengeParams) (*store.Challenge, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	Get(ctx context.Context, userID string, id string) (*store.Challenge, error)
+	Join(ctx context.Context, userID string, id string, today time.Time) (*store.Challenge, error)
+	Leave(ctx context.Context, userID string, id string) (bool, error)
+	List(ctx context.Context, userID string, status string, today time.Time) ([]store.Challenge, error)
+	Standings(ctx context.Context, c *store.Challenge, viewerID string) ([]store.ChallengeStanding, error)
+}
+
+// AchievementsStore is implemented by *store.Achievements.
+type AchievementsStore interface {
+	DeleteWebhook(ctx context.Context, userID string) (bool, error)
+	Progress(ctx context.Context, userID string, slugs []string) (store.AchievementProgress, error)
+	SetWebhook(ctx context.Context, userID string, url string) (*store.AchievementWebhook, error)
+	Unlocked(ctx context.Context, userID string) ([]store.UnlockedAchievement, error)
+	Webhook(ctx context.Context, userID string) (*store.AchievementWebhook, error)
+}
+
+// RecapsStore is implemented by *store.Recaps.
+type RecapsStore interface {
+	Generate(ctx context.Context, userID string, year int) (*store.YearRecap, error)
+	Get(ctx context.Context, userID string, year int) (*store.YearRecap, error)
+}
+
+// BiometricsStore is implemented by *store.Biometrics.
+type BiometricsStore interface {
+	DeleteHeartRate(ctx context.Context, userID string, dayID string) (bool, error)
+	HeartRate(ctx context.Context, userID string, dayID string) (*store.HeartRate, error)
+	PutHeartRate(ctx context.Context, userID string, dayID string, samples []biometrics.Sample, p biometrics.Profile) (*store.HeartRate, error)
+}
+
+// NutritionStore is implemented by *store.Nutrition.
+type NutritionStore interface {
+	Dashboard(ctx context.Context, userID string, from time.Time, to time.Time) (*store.NutritionDashboard, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	List(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.NutritionEntry, error)
+	Log(ctx context.Context, userID string, p store.NutritionParams) (*store.NutritionEntry, error)
+}
+
+// CheckinsStore is implemented by *store.Checkins.
+type CheckinsStore interface {
+	Delete(ctx context.Context, userID string, date time.Time) (bool, error)
+	List(ctx context.Context, userID string, from *time.Time, to *time.Time) ([]store.Checkin, error)
+	Put(ctx context.Context, userID string, date time.Time, p store.CheckinParams) (*store.Checkin, error)
+}
+
+// GoalsStore is implemented by *store.Goals.
+type GoalsStore interface {
+	Create(ctx context.Context, userID string, p store.GoalParams) (*store.Goal, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	Get(ctx context.Context, userID string, id string) (*store.Goal, error)
+	List(ctx context.Context, userID string) ([]store.Goal, error)
+	Update(ctx context.Context, userID string, id string, p store.GoalParams) (*store.Goal, error)
+}
+
+var (
+	_ UsersStore        = (*store.Users)(nil)
+	_ DaysStore         = (*store.Days)(nil)
+	_ CatalogStore      = (*store.Catalog)(nil)
+	_ AuditStore        = (*store.Audit)(nil)
+	_ GymsStore         = (*store.Gyms)(nil)
+	_ BodyweightStore   = (*store.Bodyweight)(nil)
+	_ SettingsStore     = (*store.Settings)(nil)
+	_ SetsStore         = (*store.Sets)(nil)
+	_ ExercisesStore    = (*store.Exercises)(nil)
+	_ ProgramsStore     = (*store.Programs)(nil)
+	_ AnalyticsStore    = (*store.Analytics)(nil)
+	_ APIKeysStore      = (*store.APIKeys)(nil)
+	_ SessionsStore     = (*store.Sessions)(nil)
+	_ TwoFactorStore    = (*store.TwoFactor)(nil)
+	_ CoachingStore     = (*store.Coaching)(nil)
+	_ CommentsStore     = (*store.Comments)(nil)
+	_ RemindersStore    = (*store.Reminders)(nil)
+	_ UsageStore        = (*store.Usage)(nil)
+	_ SaveStore         = (*store.Save)(nil)
+	_ JournalStore      = (*store.Journal)(nil)
+	_ TemplatesStore    = (*store.Templates)(nil)
+	_ LeaderboardsStore = (*store.Leaderboards)(nil)
+	_ FriendsStore      = (*store.Friends)(nil)
+	_ FeedStore         = (*store.Feed)(nil)
+	_ ChallengesStore   = (*store.Challenges)(nil)
+	_ AchievementsStore = (*store.Achievements)(nil)
+	_ RecapsStore       = (*store.Recaps)(nil)
+	_ BiometricsStore   = (*store.Biometrics)(nil)
+	_ NutritionStore    = (*store.Nutrition)(nil)
+	_ CheckinsStore     = (*store.Checkins)(nil)
+	_ GoalsStore        = (*store.Goals)(nil)
+)
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+// TemplatesHandler publishes days and programs as share codes that other
+// users can import into their own accounts.
+type TemplatesHandler struct {
+	Templates TemplatesStore
+}
+
+type publishTemplateRequest struct {
+	Kind string `json:"kind"` // day or program
+	ID   string `json:"id"`
+}
+
+// Publish snapshots one of the user's days or programs under a new code.
+func (h *TemplatesHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req publishTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Kind != store.TemplateKindDay && req.Kind != store.TemplateKindProgram {
+		http.Error(w, "kind must be day or program", http.StatusBadRequest)
+		return
+	}
+	if req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	share, err := h.Templates.Publish(r.Context(), uid, req.Kind, req.ID)
+	if err != nil {
+		log.Printf("publish template error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if share == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusCreated, share)
+}
+
+// List returns the share codes the user has published.
+func (h *TemplatesHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.Templates.List(r.Context(), uid)
+	if err != nil {
+		log.Printf("list templates error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Delete withdraws a share code; copies already imported stay.
+func (h *TemplatesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	deleted, err := h.Templates.Delete(r.Context(), uid, chi.URLParam(r, "code"))
+	if err != nil {
+		log.Printf("delete template error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Get previews the template behind a code before importing it.
+func (h *TemplatesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	share, err := h.Templates.Get(r.Context(), chi.URLParam(r, "code"))
+	if err != nil {
+		log.Printf("get template error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if share == nil {
+		http.NotFound(w, r)
+		return
+	}
+	// The source row belongs to the publisher.
+	share.SourceID = ""
+	writeJSON(w, http.StatusOK, share)
+}
+
+type importTemplateRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD for day templates, default today
+}
+
+// Import copies the template behind a code into the user's account.
+func (h *TemplatesHandler) Import(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req importTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+		date = t
+	}
+	res, err := h.Templates.Import(r.Context(), uid, chi.URLParam(r, "code"), date)
+	if errors.Is(err, store.ErrExerciseOnRestDay) {
+		http.Error(w, "cannot import exercises into a rest day", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("import template error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if res == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/store"
+)
+
+func TestTemplatesImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		res    *store.TemplateImport
+		err    error
+		status int
+	}{
+		{name: "bad date", body: `{"date":"tomorrow"}`, status: http.StatusBadRequest},
+		{name: "unknown code", body: `{}`, status: http.StatusNotFound},
+		{name: "rest day", body: `{"date":"2024-05-01"}`, err: store.ErrExerciseOnRestDay, status: http.StatusConflict},
+		{name: "imported", body: ``, res: &store.TemplateImport{Kind: store.TemplateKindDay, DayID: "day-1", Imported: 3}, status: http.StatusCreated},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			templates := &mocks.TemplatesStore{
+				ImportFunc: func(_ context.Context, userID, code string, date time.Time) (*store.TemplateImport, error) {
+					if userID != "user-1" || code != "ABCD2345" {
+						t.Errorf("Import(%q, %q)", userID, code)
+					}
+					if date.Hour() != 0 || date.Minute() != 0 {
+						t.Errorf("date = %v, want midnight", date)
+					}
+					return tc.res, tc.err
+				},
+			}
+			h := &handlers.TemplatesHandler{Templates: templates}
+			w := httptest.NewRecorder()
+			h.Import(w, newRequest(http.MethodPost, "/api/templates/import/ABCD2345", tc.body, "user-1", map[string]string{"code": "ABCD2345"}))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+		})
+	}
+}
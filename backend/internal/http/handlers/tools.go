@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/plates"
+)
+
+type ToolsHandler struct {
+	Settings SettingsStore
+}
+
+// Plates returns the per-side plate breakdown for ?target= using the user's
+// plate inventory. target and bar (default: the first of the user's bar
+// weights) are in the user's unit.
+func (h *ToolsHandler) Plates(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	target, err := strconv.ParseFloat(q.Get("target"), 64)
+	if err != nil || target <= 0 || target > 1000 {
+		http.Error(w, "target must be between 0 and 1000", http.StatusBadRequest)
+		return
+	}
+	settings, err := h.Settings.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("plates settings error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	var bar float64
+	if len(settings.BarWeights) > 0 {
+		bar = settings.BarWeights[0]
+	}
+	if v := q.Get("bar"); v != "" {
+		b, err := strconv.ParseFloat(v, 64)
+		if err != nil || b < 0 || b > 100 {
+			http.Error(w, "bar must be between 0 and 100", http.StatusBadRequest)
+			return
+		}
+		bar = b
+	}
+	res := plates.Breakdown(target, bar, settings.Plates)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"units":     settings.Units,
+		"target":    res.Target,
+		"bar":       res.Bar,
+		"perSide":   res.PerSide,
+		"achieved":  res.Achieved,
+		"remainder": res.Remainder,
+	})
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"exercise-tracker/internal/auth"
+)
+
+// totpIssuer labels the account in authenticator apps.
+const totpIssuer = "FitLog"
+
+// verifySecondFactor checks a TOTP code or recovery code for userID. required
+// is false when the user has no 2FA enabled; passed consumes the code.
+func (h *AuthHandler) verifySecondFactor(r *http.Request, userID, code, recoveryCode string) (required, passed bool, err error) {
+	if h.TwoFactor == nil {
+		return false, false, nil
+	}
+	t, err := h.TwoFactor.Get(r.Context(), userID)
+	if err != nil || t == nil || t.EnabledAt == nil {
+		return false, false, err
+	}
+	switch {
+	case recoveryCode != "":
+		passed, err = h.TwoFactor.UseRecoveryCode(r.Context(), userID, auth.HashRecoveryCode(recoveryCode))
+	case code != "":
+		if step, ok := auth.VerifyTOTP(t.Secret, code, time.Now(), t.LastStep); ok {
+			passed, err = h.TwoFactor.UseStep(r.Context(), userID, step)
+		}
+	}
+	return true, passed, err
+}
+
+// twoFactorUser is sessionUser for the 2FA routes, which 404 when 2FA is off.
+func (h *AuthHandler) twoFactorUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	uid, ok := h.sessionUser(w, r)
+	if !ok {
+		return "", false
+	}
+	if h.TwoFactor == nil {
+		http.NotFound(w, r)
+		return "", false
+	}
+	return uid, true
+}
+
+// twoFactorThrottleKey is the login throttle key for a signed-in user's
+// password and code checks, kept apart from login attempts on their email.
+func twoFactorThrottleKey(userID string) string { return "2fa:" + userID }
+
+// twoFactorFailed records a failed check and responds 403 with msg, or 429
+// if this attempt tipped the user or IP into lockout.
+func (h *AuthHandler) twoFactorFailed(w http.ResponseWriter, key, ip, msg string) {
+	if st := h.Throttle.Failure(key, ip, time.Now()); st.Locked {
+		writeLockout(w, st)
+		return
+	}
+	http.Error(w, msg, http.StatusForbidden)
+}
+
+// TwoFactorStatus reports whether 2FA is on and how many recovery codes
+// remain.
+func (h *AuthHandler) TwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.TwoFactor.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("2fa status error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	enabled := t != nil && t.EnabledAt != nil
+	left := 0
+	if enabled {
+		if left, err = h.TwoFactor.RecoveryCodesLeft(r.Context(), uid); err != nil {
+			log.Printf("2fa status error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"enabled":           enabled,
+		"pending":           t != nil && t.EnabledAt == nil,
+		"recoveryCodesLeft": left,
+	})
+}
+
+// TwoFactorEnroll generates a secret for the caller to add to an
+// authenticator app. The otpauth URL is what the client renders as a QR
+// code. 2FA stays off until TwoFactorEnable confirms a code.
+func (h *AuthHandler) TwoFactorEnroll(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.Users.ByID(r.Context(), uid)
+	if err != nil || u == nil {
+		if err != nil {
+			log.Printf("2fa enroll lookup error: %v", err)
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		log.Printf("2fa secret error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	stored, err := h.TwoFactor.Enroll(r.Context(), uid, secret)
+	if err != nil {
+		log.Printf("2fa enroll error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !stored {
+		http.Error(w, "two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"secret":     secret,
+		"otpauthUrl": auth.TOTPURI(totpIssuer, u.Email, secret),
+	})
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorEnable confirms enrollment with a code from the app and returns
+// the recovery codes, which are never shown again.
+func (h *AuthHandler) TwoFactorEnable(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	var req twoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	t, err := h.TwoFactor.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("2fa enable error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if t == nil || t.EnabledAt != nil {
+		http.Error(w, "no pending enrollment", http.StatusConflict)
+		return
+	}
+	step, ok := auth.VerifyTOTP(t.Secret, req.Code, time.Now(), t.LastStep)
+	if !ok {
+		http.Error(w, "invalid code", http.StatusBadRequest)
+		return
+	}
+	codes, hashes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		log.Printf("2fa recovery codes error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	enabled, err := h.TwoFactor.Enable(r.Context(), uid, step, hashes)
+	if err != nil {
+		log.Printf("2fa enable error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !enabled {
+		http.Error(w, "no pending enrollment", http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"recoveryCodes": codes})
+}
+
+type twoFactorDisableRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// TwoFactorDisable turns 2FA off after re-checking the password and a
+// second factor.
+func (h *AuthHandler) TwoFactorDisable(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	var req twoFactorDisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	key, ip := twoFactorThrottleKey(uid), clientIP(r)
+	if st := h.Throttle.Check(key, ip, time.Now()); st.Locked {
+		writeLockout(w, st)
+		return
+	}
+	u, err := h.Users.ByID(r.Context(), uid)
+	if err != nil {
+		log.Printf("2fa disable lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if u == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if ok, _ := auth.VerifyPassword(u.PasswordHash, req.Password); !ok {
+		h.twoFactorFailed(w, key, ip, "invalid credentials")
+		return
+	}
+	required, passed, err := h.verifySecondFactor(r, uid, req.Code, req.RecoveryCode)
+	if err != nil {
+		log.Printf("2fa disable error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if required && !passed {
+		h.twoFactorFailed(w, key, ip, "invalid code")
+		return
+	}
+	h.Throttle.Success(key)
+	if err := h.TwoFactor.Disable(r.Context(), uid); err != nil {
+		log.Printf("2fa disable error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TwoFactorRecoveryCodes replaces the recovery codes after checking a
+// current TOTP code.
+func (h *AuthHandler) TwoFactorRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	uid, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	var req twoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	key, ip := twoFactorThrottleKey(uid), clientIP(r)
+	if st := h.Throttle.Check(key, ip, time.Now()); st.Locked {
+		writeLockout(w, st)
+		return
+	}
+	required, passed, err := h.verifySecondFactor(r, uid, req.Code, "")
+	if err != nil {
+		log.Printf("2fa recovery codes error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !required {
+		http.Error(w, "two-factor authentication is not enabled", http.StatusConflict)
+		return
+	}
+	if !passed {
+		h.twoFactorFailed(w, key, ip, "invalid code")
+		return
+	}
+	h.Throttle.Success(key)
+	codes, hashes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		log.Printf("2fa recovery codes error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.TwoFactor.ReplaceRecoveryCodes(r.Context(), uid, hashes); err != nil {
+		log.Printf("2fa recovery codes error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"recoveryCodes": codes})
+}
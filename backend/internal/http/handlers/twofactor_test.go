@@ -0,0 +1,120 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/store"
+)
+
+func TestLoginRequiresSecondFactor(t *testing.T) {
+	hash, err := auth.HashPassword("hunter22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := auth.TOTPCode(secret, auth.TOTPStep(time.Now()))
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	enabledAt := time.Now()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{name: "no code", body: `{"email":"a@example.com","password":"hunter22"}`, status: http.StatusUnauthorized, want: "two_factor_required"},
+		{name: "wrong code", body: `{"email":"a@example.com","password":"hunter22","code":"` + wrong + `"}`, status: http.StatusUnauthorized, want: "invalid credentials"},
+		{name: "totp", body: `{"email":"a@example.com","password":"hunter22","code":"` + code + `"}`, status: http.StatusOK},
+		{name: "recovery code", body: `{"email":"a@example.com","password":"hunter22","recoveryCode":"ABCDE-FGHJK"}`, status: http.StatusOK},
+		{name: "bad password", body: `{"email":"a@example.com","password":"nope","code":"` + code + `"}`, status: http.StatusUnauthorized, want: "invalid credentials"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &handlers.AuthHandler{
+				JWTSecret: "test-secret",
+				Users: &mocks.UsersStore{
+					ByEmailFunc: func(context.Context, string) (*models.User, error) {
+						return &models.User{ID: "user-1", Email: "a@example.com", PasswordHash: hash}, nil
+					},
+				},
+				TwoFactor: &mocks.TwoFactorStore{
+					GetFunc: func(context.Context, string) (*store.TOTP, error) {
+						return &store.TOTP{Secret: secret, EnabledAt: &enabledAt}, nil
+					},
+					UseStepFunc: func(context.Context, string, int64) (bool, error) { return true, nil },
+					UseRecoveryCodeFunc: func(_ context.Context, _ string, h string) (bool, error) {
+						return h == auth.HashRecoveryCode("abcde-fghjk"), nil
+					},
+				},
+			}
+			rec := httptest.NewRecorder()
+			h.Login(rec, newRequest(http.MethodPost, "/api/auth/login", tc.body, "", nil))
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.status, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tc.want) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body, tc.want)
+			}
+			if cookie := rec.Header().Get("Set-Cookie"); (tc.status == http.StatusOK) != (cookie != "") {
+				t.Errorf("Set-Cookie = %q", cookie)
+			}
+		})
+	}
+}
+
+func TestTwoFactorDisableThrottled(t *testing.T) {
+	hash, err := auth.HashPassword("hunter22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabledAt := time.Now()
+	disabled := false
+	h := &handlers.AuthHandler{
+		Throttle: auth.NewLoginThrottle(auth.ThrottleConfig{MaxFailures: 2, Window: time.Minute}),
+		Users: &mocks.UsersStore{
+			ByIDFunc: func(context.Context, string) (*models.User, error) {
+				return &models.User{ID: "user-1", PasswordHash: hash}, nil
+			},
+		},
+		TwoFactor: &mocks.TwoFactorStore{
+			GetFunc: func(context.Context, string) (*store.TOTP, error) {
+				return &store.TOTP{Secret: "JBSWY3DPEHPK3PXP", EnabledAt: &enabledAt}, nil
+			},
+			UseRecoveryCodeFunc: func(context.Context, string, string) (bool, error) { return true, nil },
+			DisableFunc: func(context.Context, string) error {
+				disabled = true
+				return nil
+			},
+		},
+	}
+	for i, want := range []int{http.StatusForbidden, http.StatusTooManyRequests, http.StatusTooManyRequests} {
+		// The last attempt has the right password and code but is still locked out.
+		body := `{"password":"wrong","recoveryCode":"ABCDE-FGHJK"}`
+		if i == 2 {
+			body = `{"password":"hunter22","recoveryCode":"ABCDE-FGHJK"}`
+		}
+		rec := httptest.NewRecorder()
+		h.TwoFactorDisable(rec, newRequest(http.MethodPost, "/api/auth/2fa/disable", body, "user-1", nil))
+		if rec.Code != want {
+			t.Fatalf("attempt %d: status = %d, want %d (%s)", i+1, rec.Code, want, rec.Body)
+		}
+	}
+	if disabled {
+		t.Fatal("2fa was disabled while locked out")
+	}
+}
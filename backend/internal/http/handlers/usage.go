@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/store"
+)
+
+type UsageHandler struct {
+	Usage UsageStore
+}
+
+// Get returns the user's row counts, estimated bytes and effective quota.
+func (h *UsageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	usage, err := h.Usage.Get(r.Context(), uid)
+	if err != nil {
+		log.Printf("usage get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// writeQuotaError answers 403 with the quota message when err was raised by
+// a storage quota, and reports whether it did.
+func writeQuotaError(w http.ResponseWriter, err error) bool {
+	qe, ok := store.AsQuotaError(err)
+	if !ok {
+		return false
+	}
+	http.Error(w, qe.Error(), http.StatusForbidden)
+	return true
+}
+
+func validQuota(q store.Quota) bool {
+	return (q.MaxWorkoutDays == nil || *q.MaxWorkoutDays >= 0) && (q.MaxSets == nil || *q.MaxSets >= 0)
+}
+
+// GetQuotaDefaults returns the quota for users without an override.
+func (h *AdminHandler) GetQuotaDefaults(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	q, err := h.Usage.Defaults(r.Context())
+	if err != nil {
+		log.Printf("quota defaults error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, q)
+}
+
+// SetQuotaDefaults replaces the default quota. Body: {maxWorkoutDays, maxSets},
+// null for unlimited.
+func (h *AdminHandler) SetQuotaDefaults(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	var req store.Quota
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !validQuota(req) {
+		http.Error(w, "quota limits must not be negative", http.StatusBadRequest)
+		return
+	}
+	before, err := h.Usage.Defaults(r.Context())
+	if err != nil {
+		log.Printf("quota defaults error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	after, err := h.Usage.SetDefaults(r.Context(), req)
+	if err != nil {
+		log.Printf("quota defaults update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditQuotaUpdate,
+		EntityType: "quota_defaults",
+		Before:     before,
+		After:      after,
+	})
+	writeJSON(w, http.StatusOK, after)
+}
+
+// GetUserUsage returns another user's usage and effective quota, plus their
+// override if any.
+func (h *AdminHandler) GetUserUsage(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id := chi.URLParam(r, "id")
+	u, err := h.Users.ByID(r.Context(), id)
+	if err != nil {
+		log.Printf("usage user lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if u == nil {
+		http.NotFound(w, r)
+		return
+	}
+	usage, err := h.Usage.Get(r.Context(), id)
+	if err != nil {
+		log.Printf("usage get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	override, err := h.Usage.UserQuota(r.Context(), id)
+	if err != nil {
+		log.Printf("user quota get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"usage": usage, "override": override})
+}
+
+// SetUserQuota sets a user's override. Body: {maxWorkoutDays, maxSets}; a
+// null limit falls back to the default.
+func (h *AdminHandler) SetUserQuota(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id := chi.URLParam(r, "id")
+	var req store.Quota
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !validQuota(req) {
+		http.Error(w, "quota limits must not be negative", http.StatusBadRequest)
+		return
+	}
+	u, err := h.Users.ByID(r.Context(), id)
+	if err != nil {
+		log.Printf("usage user lookup error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if u == nil {
+		http.NotFound(w, r)
+		return
+	}
+	before, err := h.Usage.UserQuota(r.Context(), id)
+	if err != nil {
+		log.Printf("user quota get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	after, err := h.Usage.SetUserQuota(r.Context(), id, req)
+	if err != nil {
+		log.Printf("user quota update error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditQuotaUpdate,
+		EntityType: "user_quota",
+		EntityID:   id,
+		Before:     before,
+		After:      after,
+	})
+	writeJSON(w, http.StatusOK, after)
+}
+
+// DeleteUserQuota drops a user's override so the defaults apply again.
+func (h *AdminHandler) DeleteUserQuota(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id := chi.URLParam(r, "id")
+	before, err := h.Usage.UserQuota(r.Context(), id)
+	if err != nil {
+		log.Printf("user quota get error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	deleted, err := h.Usage.DeleteUserQuota(r.Context(), id)
+	if err != nil {
+		log.Printf("user quota delete error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.NotFound(w, r)
+		return
+	}
+	recordAudit(r, h.Audit, store.AuditRecordParams{
+		Action:     store.AuditQuotaUpdate,
+		EntityType: "user_quota",
+		EntityID:   id,
+		Before:     before,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"exercise-tracker/internal/http/middleware"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/parse"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+const maxVoiceTranscript = 500
+
+type voiceLogRequest struct {
+	Transcript string `json:"transcript"`
+	DayID      string `json:"dayId"`
+	// ExerciseID is the exercise on screen; sets without a spoken exercise
+	// name go to it, or to the day's last exercise when empty.
+	ExerciseID     string `json:"exerciseId"`
+	DryRun         bool   `json:"dryRun"`
+	IdempotencyKey string `json:"idempotencyKey"`
+}
+
+type voiceLogResponse struct {
+	Applied   bool   `json:"applied"`
+	Heard     string `json:"heard"`
+	DayID     string `json:"dayId"`
+	CatalogID string `json:"catalogId"`
+	// ExerciseID is empty on a dry run that would add the exercise.
+	ExerciseID   string             `json:"exerciseId,omitempty"`
+	ExerciseName string             `json:"exerciseName"`
+	Sets         []parse.Set        `json:"sets"`
+	Mapping      *store.SaveMapping `json:"mapping,omitempty"`
+	ServerEpoch  int64              `json:"serverEpoch,omitempty"`
+}
+
+// VoiceLog appends sets from an already transcribed utterance such as
+// "bench, three sets of five at eighty kilos", "eight reps at 100" or "same
+// again". Without an exercise name the sets go to the exercise in context;
+// a named exercise not yet on the day is added. Omitted weights default to
+// the last working set of the exercise, today or else in the user's history.
+func (h *SaveHandler) VoiceLog(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req voiceLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.DayID == "" {
+		http.Error(w, "dayId is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Transcript) > maxVoiceTranscript {
+		http.Error(w, fmt.Sprintf("transcript exceeds %d characters", maxVoiceTranscript), http.StatusBadRequest)
+		return
+	}
+	u, err := parse.Transcript(req.Transcript)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	day, err := h.Days.GetWithDetails(r.Context(), uid, req.DayID)
+	if err != nil {
+		log.Printf("voice log day error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if day == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := voiceLogResponse{Heard: u.Heard, DayID: day.ID}
+	var target *models.Exercise
+	switch {
+	case u.Name != "":
+		matches, err := h.Catalog.MatchExercise(r.Context(), uid, u.Name, 1)
+		if err != nil {
+			log.Printf("voice log match error: %v", err)
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		if len(matches) == 0 {
+			writeSaveError(w, http.StatusUnprocessableEntity, "unmatched", fmt.Sprintf("No exercise matches %q.", u.Name))
+			return
+		}
+		resp.CatalogID, resp.ExerciseName = matches[0].ID, matches[0].Name
+		for i := range day.Exercises {
+			if e := &day.Exercises[i]; e.CatalogID != nil && *e.CatalogID == resp.CatalogID {
+				target = e
+			}
+		}
+	case req.ExerciseID != "":
+		for i := range day.Exercises {
+			if day.Exercises[i].ID == req.ExerciseID {
+				target = &day.Exercises[i]
+			}
+		}
+		if target == nil {
+			http.Error(w, "exercise not found", http.StatusNotFound)
+			return
+		}
+	default:
+		for i := range day.Exercises {
+			if target == nil || day.Exercises[i].Position > target.Position {
+				target = &day.Exercises[i]
+			}
+		}
+		if target == nil {
+			writeSaveError(w, http.StatusUnprocessableEntity, "no_exercise", "The day has no exercises yet; say the exercise name.")
+			return
+		}
+	}
+	if target != nil {
+		resp.ExerciseID, resp.ExerciseName = target.ID, target.Name
+		if target.CatalogID != nil {
+			resp.CatalogID = *target.CatalogID
+		}
+	}
+
+	last, err := h.lastWorkingSet(r, uid, target, resp.CatalogID)
+	if err != nil {
+		log.Printf("voice log history error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if u.Repeat {
+		if last == nil {
+			writeSaveError(w, http.StatusUnprocessableEntity, "no_previous_set", "There is no earlier set to repeat.")
+			return
+		}
+		resp.Sets = []parse.Set{*last}
+	}
+	for _, g := range u.Groups {
+		s := parse.Set{Reps: g.Reps}
+		switch {
+		case g.WeightKg != nil:
+			s.WeightKg = *g.WeightKg
+		case last != nil:
+			s.WeightKg = last.WeightKg
+		}
+		for n := 0; n < g.Sets; n++ {
+			resp.Sets = append(resp.Sets, s)
+		}
+	}
+	if req.DryRun {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	ops, err := voiceLogOps(day, target, resp.CatalogID, resp.Sets)
+	if err != nil {
+		log.Printf("voice log ops error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	mapping, _, err := h.Service.ProcessBatch(r.Context(), uid, ops, req.IdempotencyKey)
+	if qe, ok := store.AsQuotaError(err); ok {
+		writeSaveError(w, http.StatusForbidden, "quota_exceeded", qe.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("voice log save error: %v", err)
+		writeSaveError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	for _, m := range mapping.Exercises {
+		resp.ExerciseID = m.ID
+	}
+	resp.Applied, resp.Mapping = true, &mapping
+	resp.ServerEpoch = h.afterCommit(r, uid, mapping)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// lastWorkingSet is the exercise's last working strength set on the day, or
+// else the last one of its most recent session. Nil without either.
+func (h *SaveHandler) lastWorkingSet(r *http.Request, uid string, e *models.Exercise, catalogID string) (*parse.Set, error) {
+	if e != nil {
+		var last *models.Set
+		for i := range e.Sets {
+			s := &e.Sets[i]
+			if !s.IsWarmup && s.SetType == models.SetTypeStrength && (last == nil || s.Position > last.Position) {
+				last = s
+			}
+		}
+		if last != nil {
+			return &parse.Set{Reps: last.Reps, WeightKg: last.WeightKg}, nil
+		}
+	}
+	if catalogID == "" {
+		return nil, nil
+	}
+	stats, _, err := h.Catalog.GetExerciseStats(r.Context(), catalogID, uid, 1, 0, progression.FormulaEpley)
+	if err != nil || stats == nil {
+		return nil, err
+	}
+	var last *parse.Set
+	for _, day := range stats.History {
+		for _, s := range day.Sets {
+			if !s.IsWarmup {
+				last = &parse.Set{Reps: s.Reps, WeightKg: s.WeightKg}
+			}
+		}
+	}
+	return last, nil
+}
+
+// voiceLogOps appends sets to the target exercise, first adding the exercise
+// at the end of the day when target is nil.
+func voiceLogOps(day *models.DayWithDetails, target *models.Exercise, catalogID string, sets []parse.Set) ([]json.RawMessage, error) {
+	var ops []json.RawMessage
+	add := func(op map[string]any) error {
+		b, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, b)
+		return nil
+	}
+	exerciseID, position := "", 0
+	if target != nil {
+		exerciseID = target.ID
+		// Sets and rests share the exercise's positions.
+		for _, s := range target.Sets {
+			position = max(position, s.Position+1)
+		}
+		for _, rp := range target.Rests {
+			position = max(position, rp.Position+1)
+		}
+	} else {
+		exerciseID = "voicelog-exercise"
+		exPosition := 0
+		for _, e := range day.Exercises {
+			exPosition = max(exPosition, e.Position+1)
+		}
+		if err := add(map[string]any{
+			"type": "createExercise", "localId": exerciseID, "dayId": day.ID,
+			"catalogId": catalogID, "position": exPosition,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for i, s := range sets {
+		if err := add(map[string]any{
+			"type": "createSet", "localId": fmt.Sprintf("voicelog-set-%d", i), "exerciseId": exerciseID,
+			"position": position + i, "reps": s.Reps, "weightKg": s.WeightKg, "setType": "strength",
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
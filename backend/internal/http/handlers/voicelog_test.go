@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/handlers"
+	"exercise-tracker/internal/http/handlers/mocks"
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+func TestVoiceLog(t *testing.T) {
+	bench := "cat-bench"
+	day := &models.DayWithDetails{
+		WorkoutDay: models.WorkoutDay{ID: "day-1"},
+		Exercises: []models.Exercise{
+			{ID: "ex-squat", Name: "Squat", Position: 0},
+			{ID: "ex-bench", Name: "Bench Press", CatalogID: &bench, Position: 1, Sets: []models.Set{
+				{Position: 0, Reps: 5, WeightKg: 80, SetType: models.SetTypeStrength},
+			}},
+		},
+	}
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   []string // substrings of the save ops, in order
+	}{
+		{name: "missing day", body: `{"transcript":"8 reps"}`, status: http.StatusBadRequest},
+		{name: "last weight on current exercise", body: `{"transcript":"eight reps","dayId":"day-1"}`, status: http.StatusOK,
+			want: []string{`"exerciseId":"ex-bench"`, `"position":1,"reps":8`, `"weightKg":80`}},
+		{name: "same again", body: `{"transcript":"same again","dayId":"day-1","exerciseId":"ex-bench"}`, status: http.StatusOK,
+			want: []string{`"reps":5`, `"weightKg":80`}},
+		{name: "nothing to repeat", body: `{"transcript":"again","dayId":"day-1","exerciseId":"ex-squat"}`, status: http.StatusUnprocessableEntity},
+		{name: "new exercise from history", body: `{"transcript":"curls 2 sets of 12","dayId":"day-1"}`, status: http.StatusOK,
+			want: []string{`"position":2,"type":"createExercise"`, `"weightKg":12.5`, `"weightKg":12.5`}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var ops []json.RawMessage
+			days := &mocks.DaysStore{
+				GetWithDetailsFunc: func(context.Context, string, string) (*models.DayWithDetails, error) { return day, nil },
+			}
+			catalog := &mocks.CatalogStore{
+				MatchExerciseFunc: func(context.Context, string, string, int) ([]store.CatalogMatch, error) {
+					return []store.CatalogMatch{{ID: "cat-curl", Name: "Biceps Curl"}}, nil
+				},
+				GetExerciseStatsFunc: func(_ context.Context, catalogID, _ string, _, _ int, _ progression.Formula) (*store.ExerciseStats, bool, error) {
+					if catalogID != "cat-curl" {
+						return &store.ExerciseStats{}, false, nil
+					}
+					return &store.ExerciseStats{History: []store.ExerciseHistoryItem{{Sets: []store.SetHistory{{Reps: 10, WeightKg: 12.5}}}}}, false, nil
+				},
+			}
+			saves := &mocks.SaveStore{
+				ProcessBatchFunc: func(_ context.Context, _ string, raw []json.RawMessage, _ string) (store.SaveMapping, time.Time, error) {
+					ops = raw
+					return store.SaveMapping{}, time.Now(), nil
+				},
+				SetEpochFunc: func(context.Context, string, int64) error { return nil },
+			}
+			h := &handlers.SaveHandler{Service: saves, Days: days, Catalog: catalog}
+			w := httptest.NewRecorder()
+			h.VoiceLog(w, newRequest(http.MethodPost, "/api/voicelog", tc.body, "user-1", nil))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.status, w.Body.String())
+			}
+			var all string
+			for _, op := range ops {
+				all += string(op)
+			}
+			rest := all
+			for _, s := range tc.want {
+				i := strings.Index(rest, s)
+				if i < 0 {
+					t.Fatalf("ops %s\nmissing %s", all, s)
+				}
+				rest = rest[i+len(s):]
+			}
+		})
+	}
+}
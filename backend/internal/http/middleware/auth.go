@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"exercise-tracker/internal/auth"
@@ -11,6 +13,8 @@ import (
 type contextKey string
 
 const userIDKey contextKey = "userID"
+const apiKeyScopeKey contextKey = "apiKeyScope"
+const sessionIDKey contextKey = "sessionID"
 const sessionCookieName = "session"
 
 func WithUserID(ctx context.Context, userID string) context.Context {
@@ -22,9 +26,41 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	return v, ok && v != ""
 }
 
+// APIKeyVerifier resolves a hashed API key to its owner and scope.
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, hash string) (userID, scope string, ok bool, err error)
+}
+
+// APIKeyScopeFromContext reports the scope of the API key that authenticated
+// the request; ok is false for cookie sessions.
+func APIKeyScopeFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(apiKeyScopeKey).(string)
+	return v, ok && v != ""
+}
+
+// SessionIDFromContext returns the server-side session of a cookie-authenticated
+// request; ok is false for API keys and tokens issued without a session.
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(sessionIDKey).(string)
+	return v, ok && v != ""
+}
+
+// SessionVerifier reports whether a session is still active, recording the
+// activity.
+type SessionVerifier interface {
+	Touch(ctx context.Context, userID, sessionID string) (bool, error)
+}
+
 type AuthConfig struct {
 	JWTSecret    string
 	CookieDomain string
+	// APIKeys enables "Authorization: Bearer <key>" as an alternative to the
+	// session cookie. Nil disables it.
+	APIKeys APIKeyVerifier
+	// Sessions rejects tokens whose session was revoked or expired. Nil
+	// trusts the JWT alone. Tokens without a session ID predate
+	// server-side sessions and are accepted until they expire.
+	Sessions SessionVerifier
 }
 
 func (c AuthConfig) cookieSettings() (http.SameSite, bool) {
@@ -67,6 +103,20 @@ func (c AuthConfig) ClearSessionCookie(w http.ResponseWriter) {
 	})
 }
 
+// SessionClaims parses the request's session cookie without checking the
+// session store, for routes outside Middleware such as logout.
+func (c AuthConfig) SessionClaims(r *http.Request) (*auth.Claims, bool) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil, false
+	}
+	claims, err := auth.ParseToken(c.JWTSecret, cookie.Value)
+	if err != nil || claims == nil || claims.UserID == "" {
+		return nil, false
+	}
+	return claims, true
+}
+
 func (c AuthConfig) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Public endpoints (do not require session)
@@ -74,6 +124,10 @@ func (c AuthConfig) Middleware(next http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 			return
 		}
+		if key, ok := bearerToken(r); ok && c.APIKeys != nil && auth.IsAPIKey(key) {
+			c.serveAPIKey(w, r, next, key)
+			return
+		}
 		cookie, err := r.Cookie(sessionCookieName)
 		if err != nil {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
@@ -85,13 +139,56 @@ func (c AuthConfig) Middleware(next http.Handler) http.Handler {
 			return
 		}
 		ctx := WithUserID(r.Context(), claims.UserID)
+		if claims.SessionID != "" && c.Sessions != nil {
+			active, err := c.Sessions.Touch(r.Context(), claims.UserID, claims.SessionID)
+			if err != nil {
+				log.Printf("session verify error: %v", err)
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			if !active {
+				c.ClearSessionCookie(w)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			ctx = context.WithValue(ctx, sessionIDKey, claims.SessionID)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+func (c AuthConfig) serveAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, key string) {
+	userID, scope, ok, err := c.APIKeys.Verify(r.Context(), auth.HashAPIKey(key))
+	if err != nil {
+		log.Printf("api key verify error: %v", err)
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if scope != auth.APIScopeWrite && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "api key is read-only", http.StatusForbidden)
+		return
+	}
+	ctx := WithUserID(r.Context(), userID)
+	ctx = context.WithValue(ctx, apiKeyScopeKey, scope)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "bearer ") {
+		return "", false
+	}
+	tok := strings.TrimSpace(h[7:])
+	return tok, tok != ""
+}
+
 func isPublicAuthPath(p string) bool {
 	switch p {
-	case "/api/auth/register", "/api/auth/login", "/api/auth/logout":
+	case "/api/auth/register", "/api/auth/login", "/api/auth/logout", "/api/auth/guest":
 		return true
 	default:
 		return false
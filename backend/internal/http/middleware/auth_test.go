@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/auth"
+	"exercise-tracker/internal/http/middleware"
+)
+
+type sessionSet map[string]bool
+
+func (s sessionSet) Touch(_ context.Context, _, sessionID string) (bool, error) {
+	return s[sessionID], nil
+}
+
+func TestAuthMiddlewareChecksSession(t *testing.T) {
+	const secret = "test-secret"
+	cfg := middleware.AuthConfig{JWTSecret: secret, Sessions: sessionSet{"active": true}}
+	var gotSession string
+	h := cfg.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSession, _ = middleware.SessionIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		sessionID string
+		want      int
+	}{
+		{"active", http.StatusOK},
+		{"revoked", http.StatusUnauthorized},
+		{"", http.StatusOK}, // issued before sessions existed
+	}
+	for _, tc := range tests {
+		token, _, err := auth.CreateToken(secret, "user-1", tc.sessionID, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotSession = ""
+		req := httptest.NewRequest(http.MethodGet, "/api/days", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: token})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("session %q: status %d, want %d", tc.sessionID, rec.Code, tc.want)
+		}
+		if rec.Code == http.StatusOK && gotSession != tc.sessionID {
+			t.Errorf("session %q: context session %q", tc.sessionID, gotSession)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"exercise-tracker/internal/http/middleware"
+)
+
+func jsonHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func TestCompressLargeJSON(t *testing.T) {
+	body := `{"items":"` + strings.Repeat("squat ", 500) + `"}`
+	r := httptest.NewRequest(http.MethodGet, "/api/catalog", nil)
+	r.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	middleware.Compress(jsonHandler(body)).ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != body {
+		t.Fatalf("round trip mismatch: %d bytes, want %d", len(plain), len(body))
+	}
+}
+
+func TestCompressSkips(t *testing.T) {
+	large := `{"items":"` + strings.Repeat("x", 2000) + `"}`
+	tests := []struct {
+		name   string
+		accept string
+		h      http.Handler
+	}{
+		{name: "small body", accept: "gzip", h: jsonHandler(`{"ok":true}`)},
+		{name: "not accepted", accept: "identity", h: jsonHandler(large)},
+		{name: "q=0", accept: "gzip;q=0", h: jsonHandler(large)},
+		{name: "not json", accept: "gzip", h: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, large)
+		})},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/x", nil)
+			r.Header.Set("Accept-Encoding", tc.accept)
+			w := httptest.NewRecorder()
+			middleware.Compress(tc.h).ServeHTTP(w, r)
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Fatalf("Content-Encoding = %q, want none", got)
+			}
+			if w.Body.Len() == 0 {
+				t.Fatal("empty body")
+			}
+		})
+	}
+}
+
+func TestDecompressRequest(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	_, _ = io.WriteString(zw, `{"ops":[]}`)
+	_ = zw.Close()
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(b)
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/api/save", &zipped)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	middleware.DecompressRequest(echo).ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != `{"ops":[]}` {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/api/save", strings.NewReader("not gzip"))
+	r.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	middleware.DecompressRequest(echo).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid gzip: status %d, want 400", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/api/save", strings.NewReader("{}"))
+	r.Header.Set("Content-Encoding", "br")
+	w = httptest.NewRecorder()
+	middleware.DecompressRequest(echo).ServeHTTP(w, r)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("br: status %d, want 415", w.Code)
+	}
+}
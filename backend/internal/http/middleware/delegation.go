@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const actorIDKey contextKey = "actorID"
+
+// ActorIDFromContext returns the authenticated user behind the request. It
+// differs from UserIDFromContext when a coach acts on a client's data.
+func ActorIDFromContext(ctx context.Context) (string, bool) {
+	if v, ok := ctx.Value(actorIDKey).(string); ok && v != "" {
+		return v, true
+	}
+	return UserIDFromContext(ctx)
+}
+
+// DelegationChecker decides whether coachID may act for clientID.
+type DelegationChecker interface {
+	Access(ctx context.Context, coachID, clientID string) (canWrite bool, ok bool, err error)
+}
+
+// Delegated authorizes /api/clients/{userId}/... routes: the authenticated
+// user must have an active coaching link to userId, with write access for
+// anything but GET/HEAD. Downstream handlers then run as the client, so they
+// need no coaching awareness; the coach stays available as the actor.
+func Delegated(checker DelegationChecker) func(http.Handler) http.Handler {
+	return delegated(checker, false)
+}
+
+// DelegatedFeedback is Delegated for routes any active coach may write to,
+// such as comments, even with read-only access.
+func DelegatedFeedback(checker DelegationChecker) func(http.Handler) http.Handler {
+	return delegated(checker, true)
+}
+
+func delegated(checker DelegationChecker, readOnlyMayWrite bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			coachID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			clientID := chi.URLParam(r, "userId")
+			canWrite, ok, err := checker.Access(r.Context(), coachID, clientID)
+			if err != nil {
+				log.Printf("delegation check error: %v", err)
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			if !canWrite && !readOnlyMayWrite && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				http.Error(w, "read-only access", http.StatusForbidden)
+				return
+			}
+			ctx := context.WithValue(r.Context(), actorIDKey, coachID)
+			ctx = WithUserID(ctx, clientID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
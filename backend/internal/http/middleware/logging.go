@@ -26,6 +26,12 @@ func (rr *responseRecorder) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap exposes the underlying writer so http.ResponseController can reach
+// Hijack/Flush (needed for websocket upgrades).
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
 // RequestLogger logs method, path, status code, duration, and the authenticated
 // user (when present) for every request that passes through the router.
 func RequestLogger(next http.Handler) http.Handler {
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaintenanceState is the current maintenance setting as reported by the
+// admin endpoint.
+type MaintenanceState struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// Maintenance is the server's maintenance switch. While it is on, mutating
+// requests get a 503 with the reason; reads are served as usual. The switch
+// is per process, so with several replicas flip it on each (or set
+// MAINTENANCE_MODE and restart).
+type Maintenance struct {
+	mu    sync.RWMutex
+	state MaintenanceState
+}
+
+func NewMaintenance(enabled bool, reason string) *Maintenance {
+	m := &Maintenance{}
+	m.Set(enabled, reason)
+	return m
+}
+
+func (m *Maintenance) State() MaintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Set turns maintenance mode on or off. Since is kept when the mode is
+// already on and only the reason changes.
+func (m *Maintenance) Set(enabled bool, reason string) MaintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !enabled {
+		m.state = MaintenanceState{}
+		return m.state
+	}
+	since := m.state.Since
+	if since == nil {
+		now := time.Now().UTC()
+		since = &now
+	}
+	m.state = MaintenanceState{Enabled: true, Reason: reason, Since: since}
+	return m.state
+}
+
+// Middleware refuses POST, PUT, PATCH and DELETE requests while maintenance
+// mode is on, except for the exempt paths (matched exactly), so that admins
+// can still sign in and switch it off.
+func (m *Maintenance) Middleware(exempt ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]struct{}, len(exempt))
+	for _, p := range exempt {
+		skip[p] = struct{}{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+			st := m.State()
+			if _, ok := skip[r.URL.Path]; !st.Enabled || ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			reason := st.Reason
+			if reason == "" {
+				reason = "The server is in maintenance mode; changes are disabled for now."
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"error":  "maintenance",
+				"reason": reason,
+				"since":  st.Since,
+			})
+		})
+	}
+}
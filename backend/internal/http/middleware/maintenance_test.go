@@ -0,0 +1,39 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"exercise-tracker/internal/http/middleware"
+)
+
+func TestMaintenanceBlocksWrites(t *testing.T) {
+	m := middleware.NewMaintenance(true, "Upgrading the database.")
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := m.Middleware("/api/admin/maintenance")(ok)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/days", http.StatusOK},
+		{http.MethodPost, "/api/save", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/sets/1", http.StatusServiceUnavailable},
+		{http.MethodPut, "/api/admin/maintenance", http.StatusOK},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+	}
+
+	m.Set(false, "")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/save", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("after disabling: %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/http/middleware"
+)
+
+func TestRateLimiterRefills(t *testing.T) {
+	l := middleware.NewRateLimiter(60, 2)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("1.2.3.4", now); !ok {
+			t.Fatalf("request %d refused within the burst", i)
+		}
+	}
+	ok, wait := l.Allow("1.2.3.4", now)
+	if ok || wait != time.Second {
+		t.Fatalf("third request = %t, wait %v; want refused for 1s", ok, wait)
+	}
+	if ok, _ := l.Allow("5.6.7.8", now); !ok {
+		t.Fatal("another IP shares the bucket")
+	}
+	if ok, _ := l.Allow("1.2.3.4", now.Add(time.Second)); !ok {
+		t.Fatal("no token after a second at 60/min")
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	l := middleware.NewRateLimiter(1, 1)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/public/catalog", nil)
+		r.RemoteAddr = "1.2.3.4:5555"
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Fatalf("request %d = %d, want %d", i, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/store"
+)
+
+// bufferedResponse holds a handler's response until the request transaction
+// has been committed, so a failed commit can still turn into a 500.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// Transaction runs each mutating request (POST, PUT, PATCH, DELETE) inside one
+// database transaction that every store call made with the request context
+// joins. It commits when the handler responds with a status below 400 and
+// rolls back otherwise or on panic. The response is buffered until then.
+// Nested inside another Transaction, it joins the outer one.
+func Transaction(db *sqlx.DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if store.InRequestTx(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tx, ctx, err := store.BeginRequestTx(r.Context(), db)
+			if err != nil {
+				log.Printf("begin request tx error: %v", err)
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			buf := &bufferedResponse{header: w.Header()}
+			defer func() {
+				if p := recover(); p != nil {
+					_ = tx.Rollback()
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(buf, r.WithContext(ctx))
+
+			if buf.status == 0 {
+				buf.status = http.StatusOK
+			}
+			if buf.status >= 400 {
+				if err := tx.Rollback(); err != nil {
+					log.Printf("rollback request tx error: %v", err)
+				}
+			} else if err := tx.Commit(); err != nil {
+				log.Printf("commit request tx error: %v", err)
+				_ = tx.Rollback()
+				for k := range buf.header {
+					delete(buf.header, k)
+				}
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(buf.status)
+			_, _ = w.Write(buf.body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Origins is a validated list of frontend origins allowed by CORS. An entry
+// is scheme://host[:port]; the host may start with a "*." label to allow
+// every subdomain of the rest (https://*.example.com allows
+// https://staging.example.com but not https://example.com).
+type Origins []originPattern
+
+type originPattern struct {
+	scheme string
+	host   string // without the "*." when wildcard
+	port   string
+	wild   bool
+}
+
+// ParseOrigins parses a comma-separated list of origins, as given in
+// FRONTEND_ORIGIN.
+func ParseOrigins(list string) (Origins, error) {
+	var out Origins
+	for _, raw := range strings.Split(list, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		p, err := parseOrigin(raw)
+		if err != nil {
+			return nil, fmt.Errorf("origin %q: %w", raw, err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func parseOrigin(raw string) (originPattern, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return originPattern{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return originPattern{}, fmt.Errorf("scheme must be http or https")
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return originPattern{}, fmt.Errorf("must be scheme://host[:port] only")
+	}
+	host := strings.ToLower(u.Hostname())
+	p := originPattern{scheme: u.Scheme, host: host, port: u.Port()}
+	if rest, ok := strings.CutPrefix(host, "*."); ok {
+		// A wildcard directly over a public suffix (*.com) would allow
+		// anyone's site.
+		if !strings.Contains(rest, ".") {
+			return originPattern{}, fmt.Errorf("wildcard needs at least two labels after it")
+		}
+		p.host, p.wild = rest, true
+	}
+	if p.host == "" || strings.Contains(p.host, "*") {
+		return originPattern{}, fmt.Errorf(`only a leading "*." wildcard is supported`)
+	}
+	return p, nil
+}
+
+// Allow reports whether an Origin request header matches one of the
+// patterns.
+func (o Origins) Allow(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, p := range o {
+		if u.Scheme != p.scheme || u.Port() != p.port {
+			continue
+		}
+		if p.wild {
+			if sub, ok := strings.CutSuffix(host, "."+p.host); ok && sub != "" {
+				return true
+			}
+		} else if host == p.host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package http
+
+import "testing"
+
+func TestParseOriginsRejects(t *testing.T) {
+	for _, in := range []string{
+		"*",
+		"localhost:5173",
+		"ftp://example.com",
+		"https://example.com/app",
+		"https://example.com?x=1",
+		"https://user@example.com",
+		"https://*.com",
+		"https://app.*.example.com",
+		"https://*",
+	} {
+		if _, err := ParseOrigins(in); err == nil {
+			t.Errorf("ParseOrigins(%q) = nil error", in)
+		}
+	}
+}
+
+func TestOriginsAllow(t *testing.T) {
+	o, err := ParseOrigins(" http://localhost:5173, https://fitlog.app/ ,https://*.staging.fitlog.app,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o) != 3 {
+		t.Fatalf("got %d origins, want 3", len(o))
+	}
+	for origin, want := range map[string]bool{
+		"http://localhost:5173":               true,
+		"http://localhost:3000":               false,
+		"https://localhost:5173":              false,
+		"https://fitlog.app":                  true,
+		"https://FitLog.app":                  true,
+		"http://fitlog.app":                   false,
+		"https://evil-fitlog.app":             false,
+		"https://pr-12.staging.fitlog.app":    true,
+		"https://a.b.staging.fitlog.app":      true,
+		"https://staging.fitlog.app":          false,
+		"https://pr-12.staging.fitlog.app.io": false,
+		"null":                                false,
+	} {
+		if got := o.Allow(origin); got != want {
+			t.Errorf("Allow(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
@@ -16,15 +16,17 @@ type Dependencies interface {
 	RegisterRoutes(r chi.Router)
 }
 
-func NewRouter(frontendOrigin string, authMw func(http.Handler) http.Handler, register func(r chi.Router)) http.Handler {
+// NewRouter builds the root router. CORS allows the given frontend origins,
+// or any origin when there are none (development).
+func NewRouter(origins Origins, authMw func(http.Handler) http.Handler, register func(r chi.Router)) http.Handler {
 	r := chi.NewRouter()
 
-	if frontendOrigin != "" {
+	if len(origins) > 0 {
 		r.Use(cors.Handler(cors.Options{
-			AllowedOrigins:   []string{frontendOrigin},
+			AllowOriginFunc:  func(_ *http.Request, origin string) bool { return origins.Allow(origin) },
 			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-Unmodified-Since", "X-CSRF-Token"},
+			ExposedHeaders:   []string{"ETag", "Last-Modified", "Link", "Retry-After", "X-Login-Attempts-Remaining"},
 			AllowCredentials: true,
 			MaxAge:           300,
 		}))
@@ -33,8 +35,8 @@ func NewRouter(frontendOrigin string, authMw func(http.Handler) http.Handler, re
 		r.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"*"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-Unmodified-Since", "X-CSRF-Token"},
+			ExposedHeaders:   []string{"ETag", "Last-Modified", "Link", "Retry-After", "X-Login-Attempts-Remaining"},
 			AllowCredentials: true,
 			MaxAge:           300,
 		}))
@@ -0,0 +1,107 @@
+// Package imaging produces small previews of catalog images.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+)
+
+// ThumbnailSize is the longest edge, in pixels, of generated thumbnails.
+const ThumbnailSize = 128
+
+var ErrEmptyImage = errors.New("image has no pixels")
+
+// Thumbnail decodes a PNG (for APNG only the default frame is used) or JPEG
+// and returns a PNG scaled down so its longest edge is at most maxDim. Images
+// that already fit are re-encoded unscaled.
+func Thumbnail(data []byte, maxDim int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return nil, ErrEmptyImage
+	}
+	w, h := fit(b.Dx(), b.Dy(), maxDim)
+
+	var dst *image.NRGBA
+	if w == b.Dx() && h == b.Dy() {
+		dst = image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	} else {
+		dst = downscale(src, w, h)
+	}
+
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fit(w, h, maxDim int) (int, int) {
+	if maxDim <= 0 || (w <= maxDim && h <= maxDim) {
+		return w, h
+	}
+	if w >= h {
+		nh := h * maxDim / w
+		if nh < 1 {
+			nh = 1
+		}
+		return maxDim, nh
+	}
+	nw := w * maxDim / h
+	if nw < 1 {
+		nw = 1
+	}
+	return nw, maxDim
+}
+
+// downscale box-filters src into a w×h image: each destination pixel is the
+// alpha-weighted average of the source pixels it covers.
+func downscale(src image.Image, w, h int) *image.NRGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := b.Min.Y + (y+1)*sh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := b.Min.X + (x+1)*sw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// RGBA returns alpha-premultiplied 16-bit channels.
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					bl += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			c := color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			}
+			dst.Set(x, y, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,179 @@
+// Package jobs is a small Postgres-backed background job queue. Jobs are
+// rows in the jobs table; Run claims due ones with "for update skip locked"
+// and retries failures with exponential backoff.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"exercise-tracker/internal/store"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	pollEvery = 5 * time.Second
+	// staleAfter reclaims jobs whose worker died mid-run.
+	staleAfter = 10 * time.Minute
+	// keepFinished is how long done and failed jobs stay for inspection.
+	keepFinished = 7 * 24 * time.Hour
+	maxBackoff   = time.Hour
+)
+
+type Job struct {
+	ID       string          `db:"id"`
+	Kind     string          `db:"kind"`
+	Payload  json.RawMessage `db:"payload"`
+	Attempts int             `db:"attempts"`
+}
+
+// Decode unmarshals the job's payload into v.
+func (j Job) Decode(v any) error { return json.Unmarshal(j.Payload, v) }
+
+// Handler runs one job. Returning an error schedules a retry until the job's
+// attempts run out.
+type Handler func(ctx context.Context, job Job) error
+
+type Queue struct {
+	db       *sqlx.DB
+	handlers map[string]Handler
+}
+
+func New(db *sqlx.DB) *Queue { return &Queue{db: db, handlers: map[string]Handler{}} }
+
+// Handle registers the handler for kind. Call before Run.
+func (q *Queue) Handle(kind string, h Handler) { q.handlers[kind] = h }
+
+type EnqueueOptions struct {
+	RunAt time.Time
+	// DedupeKey makes a second enqueue with the same key a no-op.
+	DedupeKey   string
+	MaxAttempts int
+}
+
+// Enqueue adds a job. It reports false when DedupeKey was already used.
+// Inside a request transaction the job is written with it, so a rolled back
+// request leaves no job behind.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, opts EnqueueOptions) (bool, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("job payload: %w", err)
+	}
+	if opts.RunAt.IsZero() {
+		opts.RunAt = time.Now()
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	res, err := store.Conn(ctx, q.db).ExecContext(ctx, `
+		insert into jobs (kind, payload, dedupe_key, run_at, max_attempts)
+		values ($1, $2, nullif($3, ''), $4, $5)
+		on conflict (dedupe_key) do nothing`, kind, raw, opts.DedupeKey, opts.RunAt, opts.MaxAttempts)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Run works through due jobs until ctx is done, polling when idle.
+func (q *Queue) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollEvery)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+	for {
+		for ctx.Err() == nil {
+			ran, err := q.runOne(ctx)
+			if err != nil {
+				log.Printf("jobs claim error: %v", err)
+				break
+			}
+			if !ran {
+				break
+			}
+		}
+		if time.Since(lastPrune) > time.Hour {
+			if _, err := q.db.ExecContext(ctx, `delete from jobs where finished_at < $1`, time.Now().Add(-keepFinished)); err != nil {
+				log.Printf("jobs prune error: %v", err)
+			}
+			lastPrune = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOne claims and runs a single due job; ran is false when none was due.
+func (q *Queue) runOne(ctx context.Context) (ran bool, err error) {
+	var job Job
+	err = q.db.GetContext(ctx, &job, `
+		update jobs set status = 'running', locked_at = now(), attempts = attempts + 1
+		where id = (
+		  select id from jobs
+		  where (status = 'queued' and run_at <= now())
+		     or (status = 'running' and locked_at < $1)
+		  order by run_at
+		  limit 1
+		  for update skip locked
+		)
+		returning id, kind, payload, attempts`, time.Now().Add(-staleAfter))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	h, ok := q.handlers[job.Kind]
+	var runErr error
+	if !ok {
+		runErr = fmt.Errorf("no handler for job kind %q", job.Kind)
+	} else {
+		runErr = safeRun(ctx, h, job)
+	}
+	if runErr == nil {
+		_, err = q.db.ExecContext(ctx, `
+			update jobs set status = 'done', finished_at = now(), locked_at = null, last_error = null
+			where id = $1`, job.ID)
+		return true, err
+	}
+	log.Printf("job %s (%s) attempt %d failed: %v", job.ID, job.Kind, job.Attempts, runErr)
+	_, err = q.db.ExecContext(ctx, `
+		update jobs
+		set status = case when attempts >= max_attempts then 'failed' else 'queued' end,
+		    finished_at = case when attempts >= max_attempts then now() end,
+		    run_at = now() + $2 * interval '1 second',
+		    locked_at = null,
+		    last_error = $3
+		where id = $1`, job.ID, backoff(job.Attempts).Seconds(), runErr.Error())
+	return true, err
+}
+
+func safeRun(ctx context.Context, h Handler, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, job)
+}
+
+// backoff doubles from 30s per attempt, capped at maxBackoff.
+func backoff(attempts int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,226 @@
+// Package linkmeta fetches oEmbed/OpenGraph metadata (title, thumbnail,
+// provider) for catalog links in the background.
+package linkmeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"exercise-tracker/internal/store"
+)
+
+// Store is the persistence the fetcher needs; *store.Catalog implements it.
+type Store interface {
+	SaveLinkMetadata(ctx context.Context, m store.LinkMetadata) error
+	PendingLinks(ctx context.Context, retryFailedBefore time.Time, limit int) ([]string, error)
+}
+
+const (
+	// maxBodyBytes caps how much of a page or oEmbed response is read.
+	maxBodyBytes = 1 << 20
+	// retryAfter is how long a failed fetch waits before being retried.
+	retryAfter = 24 * time.Hour
+	// backfillEvery is how often links still lacking metadata are picked up.
+	backfillEvery = 10 * time.Minute
+	backfillBatch = 100
+	queueSize     = 256
+)
+
+// ValidateURL checks that raw is an absolute http(s) URL with a host and
+// returns it trimmed.
+func ValidateURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid link %q", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("link %q must be http or https", raw)
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return "", fmt.Errorf("invalid link %q", raw)
+	}
+	return raw, nil
+}
+
+type Fetcher struct {
+	store  Store
+	client *http.Client
+	queue  chan string
+}
+
+// New returns a fetcher whose HTTP client refuses to connect to loopback,
+// private and link-local addresses, so catalog links can't be used to probe
+// the internal network.
+func New(s Store, timeout time.Duration) *Fetcher {
+	dialer := &net.Dialer{Timeout: timeout, Control: PublicOnly}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       time.Minute,
+	}
+	return &Fetcher{
+		store: s,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return errors.New("too many redirects")
+				}
+				return nil
+			},
+		},
+		queue: make(chan string, queueSize),
+	}
+}
+
+// PublicOnly is a net.Dialer Control that refuses loopback, private,
+// link-local and multicast addresses. Use it for any client that fetches
+// user-supplied URLs.
+func PublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !PublicIP(net.ParseIP(host)) {
+		return fmt.Errorf("refusing to connect to %s", host)
+	}
+	return nil
+}
+
+// PublicIP reports whether ip is none of the addresses PublicOnly refuses.
+func PublicIP(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast()
+}
+
+// Enqueue schedules urls for fetching. It never blocks: when the queue is
+// full the links are left to the periodic backfill. A nil Fetcher ignores
+// the call.
+func (f *Fetcher) Enqueue(urls ...string) {
+	if f == nil {
+		return
+	}
+	for _, u := range urls {
+		select {
+		case f.queue <- u:
+		default:
+			return
+		}
+	}
+}
+
+// Run processes the queue and periodically backfills links without metadata
+// until ctx is done.
+func (f *Fetcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(backfillEvery)
+	defer ticker.Stop()
+	f.backfill(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u := <-f.queue:
+			f.fetchAndSave(ctx, u)
+		case <-ticker.C:
+			f.backfill(ctx)
+		}
+	}
+}
+
+func (f *Fetcher) backfill(ctx context.Context) {
+	urls, err := f.store.PendingLinks(ctx, time.Now().Add(-retryAfter), backfillBatch)
+	if err != nil {
+		log.Printf("link metadata backfill error: %v", err)
+		return
+	}
+	for _, u := range urls {
+		if ctx.Err() != nil {
+			return
+		}
+		f.fetchAndSave(ctx, u)
+	}
+}
+
+func (f *Fetcher) fetchAndSave(ctx context.Context, rawURL string) {
+	m, err := f.Fetch(ctx, rawURL)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		msg := err.Error()
+		m = store.LinkMetadata{URL: rawURL, Status: store.LinkMetadataFailed, Error: &msg}
+	}
+	if err := f.store.SaveLinkMetadata(ctx, m); err != nil {
+		log.Printf("link metadata save error: %v", err)
+	}
+}
+
+// Fetch returns metadata for rawURL, from the provider's oEmbed endpoint when
+// it has one and from the page's OpenGraph tags otherwise.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (store.LinkMetadata, error) {
+	if _, err := ValidateURL(rawURL); err != nil {
+		return store.LinkMetadata{}, err
+	}
+	var (
+		info pageInfo
+		err  error
+	)
+	if endpoint, ok := oEmbedEndpoint(rawURL); ok {
+		info, err = f.fetchOEmbed(ctx, endpoint)
+	} else {
+		info, err = f.fetchOpenGraph(ctx, rawURL)
+	}
+	if err != nil {
+		return store.LinkMetadata{}, err
+	}
+	if info.Provider == "" {
+		if u, err := url.Parse(rawURL); err == nil {
+			info.Provider = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+	}
+	return store.LinkMetadata{
+		URL:          rawURL,
+		Status:       store.LinkMetadataOK,
+		Provider:     nonEmpty(info.Provider),
+		Title:        nonEmpty(info.Title),
+		ThumbnailURL: nonEmpty(info.Thumbnail),
+	}, nil
+}
+
+func (f *Fetcher) get(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "FitLog link preview")
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("fetch %s: status %d", rawURL, res.StatusCode)
+	}
+	return res, nil
+}
+
+func nonEmpty(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
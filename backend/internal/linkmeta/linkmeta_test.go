@@ -0,0 +1,49 @@
+package linkmeta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	for raw, ok := range map[string]bool{
+		"https://www.youtube.com/watch?v=abc": true,
+		" http://example.com/squat ":          true,
+		"javascript:alert(1)":                 false,
+		"ftp://example.com/file":              false,
+		"https://":                            false,
+		"https://user:pw@example.com/":        false,
+		"not a url":                           false,
+	} {
+		if _, err := ValidateURL(raw); (err == nil) != ok {
+			t.Errorf("ValidateURL(%q) err=%v, want ok=%t", raw, err, ok)
+		}
+	}
+}
+
+func TestOEmbedEndpoint(t *testing.T) {
+	got, ok := oEmbedEndpoint("https://youtu.be/abc")
+	if !ok || !strings.HasPrefix(got, "https://www.youtube.com/oembed?format=json&url=https%3A%2F%2Fyoutu.be%2Fabc") {
+		t.Fatalf("youtu.be: %q %t", got, ok)
+	}
+	if _, ok := oEmbedEndpoint("https://example.com/video"); ok {
+		t.Fatal("example.com should not have an oEmbed endpoint")
+	}
+}
+
+func TestParseOpenGraph(t *testing.T) {
+	doc := `<html><head><title>Fallback</title>
+<meta property="og:title" content="Barbell Squat">
+<meta property="og:image" content="/img/squat.jpg"/>
+<meta property="og:site_name" content="ExRx">
+</head><body><meta property="og:title" content="ignored"></body></html>`
+	got := parseOpenGraph(strings.NewReader(doc))
+	want := pageInfo{Provider: "ExRx", Title: "Barbell Squat", Thumbnail: "/img/squat.jpg"}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	got = parseOpenGraph(strings.NewReader(`<html><head><title> Plain </title></head></html>`))
+	if got.Title != "Plain" {
+		t.Fatalf("title fallback: %+v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package linkmeta
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type pageInfo struct {
+	Provider  string
+	Title     string
+	Thumbnail string
+}
+
+// oEmbedProviders maps hosts to their oEmbed endpoints.
+var oEmbedProviders = map[string]string{
+	"youtube.com":   "https://www.youtube.com/oembed",
+	"m.youtube.com": "https://www.youtube.com/oembed",
+	"youtu.be":      "https://www.youtube.com/oembed",
+	"vimeo.com":     "https://vimeo.com/api/oembed.json",
+}
+
+func oEmbedEndpoint(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	endpoint, ok := oEmbedProviders[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")]
+	if !ok {
+		return "", false
+	}
+	return endpoint + "?format=json&url=" + url.QueryEscape(rawURL), true
+}
+
+func (f *Fetcher) fetchOEmbed(ctx context.Context, endpoint string) (pageInfo, error) {
+	res, err := f.get(ctx, endpoint, "application/json")
+	if err != nil {
+		return pageInfo{}, err
+	}
+	defer res.Body.Close()
+	var body struct {
+		Title        string `json:"title"`
+		ProviderName string `json:"provider_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxBodyBytes)).Decode(&body); err != nil {
+		return pageInfo{}, err
+	}
+	return pageInfo{Provider: body.ProviderName, Title: body.Title, Thumbnail: body.ThumbnailURL}, nil
+}
+
+func (f *Fetcher) fetchOpenGraph(ctx context.Context, rawURL string) (pageInfo, error) {
+	res, err := f.get(ctx, rawURL, "text/html")
+	if err != nil {
+		return pageInfo{}, err
+	}
+	defer res.Body.Close()
+	if mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mt != "text/html" && mt != "application/xhtml+xml" {
+		return pageInfo{}, nil
+	}
+	info := parseOpenGraph(io.LimitReader(res.Body, maxBodyBytes))
+	// Relative thumbnails resolve against the final (post-redirect) URL.
+	if info.Thumbnail != "" {
+		if ref, err := url.Parse(info.Thumbnail); err == nil {
+			info.Thumbnail = res.Request.URL.ResolveReference(ref).String()
+		}
+	}
+	return info, nil
+}
+
+// parseOpenGraph reads og:title, og:image and og:site_name from the document
+// head, falling back to <title> for the title.
+func parseOpenGraph(r io.Reader) pageInfo {
+	var info pageInfo
+	var docTitle string
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if info.Title == "" {
+				info.Title = docTitle
+			}
+			return info
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "title":
+				if docTitle == "" && z.Next() == html.TextToken {
+					docTitle = strings.TrimSpace(string(z.Text()))
+				}
+			case "meta":
+				var prop, content string
+				for _, a := range tok.Attr {
+					switch a.Key {
+					case "property", "name":
+						prop = strings.ToLower(a.Val)
+					case "content":
+						content = strings.TrimSpace(a.Val)
+					}
+				}
+				switch prop {
+				case "og:title":
+					info.Title = content
+				case "og:image", "og:image:url":
+					if info.Thumbnail == "" {
+						info.Thumbnail = content
+					}
+				case "og:site_name":
+					info.Provider = content
+				}
+			case "body":
+				// Metadata lives in the head; don't scan the whole page.
+				if info.Title == "" {
+					info.Title = docTitle
+				}
+				return info
+			}
+		}
+	}
+}
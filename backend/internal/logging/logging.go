@@ -0,0 +1,79 @@
+// Package logging adds a process-wide verbosity level and value redaction on
+// top of the standard log package.
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var (
+	level  atomic.Int32
+	redact atomic.Bool
+)
+
+func init() {
+	level.Store(int32(LevelInfo))
+	redact.Store(true)
+}
+
+// ParseLevel accepts debug, info, warn or error (case-insensitive).
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+func SetLevel(l Level) { level.Store(int32(l)) }
+
+// SetRedact toggles Redact; it is on by default.
+func SetRedact(on bool) { redact.Store(on) }
+
+func Enabled(l Level) bool { return int32(l) >= level.Load() }
+
+func Debugf(format string, args ...any) {
+	if Enabled(LevelDebug) {
+		log.Printf("[debug] "+format, args...)
+	}
+}
+
+func Infof(format string, args ...any) {
+	if Enabled(LevelInfo) {
+		log.Printf(format, args...)
+	}
+}
+
+// Redact replaces a sensitive value (idempotency keys and the like) with a
+// short stable hash so log lines can still be correlated. Empty values render
+// as "-".
+func Redact(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	if !redact.Load() {
+		return s
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "h:" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+// maxEffortGap is how far RPE and 10 - RIR may disagree on one set.
+const maxEffortGap = 1
+
+var (
+	ErrInvalidRPE     = errors.New("rpe must be between 0 and 10")
+	ErrInvalidRIR     = errors.New("rir must be between 0 and 10")
+	ErrEffortMismatch = errors.New("rpe and rir disagree; rpe should be about 10 - rir")
+)
+
+// CheckEffort validates a set's RPE and reps in reserve. Either may be nil;
+// when both are given they must roughly agree (RPE 8 is 2 RIR).
+func CheckEffort(rpe *float64, rir *int) error {
+	if rpe != nil && (*rpe < 0 || *rpe > 10) {
+		return ErrInvalidRPE
+	}
+	if rir != nil && (*rir < 0 || *rir > 10) {
+		return ErrInvalidRIR
+	}
+	if rpe != nil && rir != nil && math.Abs(*rpe+float64(*rir)-10) > maxEffortGap {
+		return ErrEffortMismatch
+	}
+	return nil
+}
+
+// EffectiveRPE is the set's RPE, or 10 - RIR when only RIR was logged; nil
+// when neither was.
+func (s Set) EffectiveRPE() *float64 {
+	return EffectiveRPE(s.RPE, s.RIR)
+}
+
+func EffectiveRPE(rpe *float64, rir *int) *float64 {
+	if rpe != nil {
+		return rpe
+	}
+	if rir == nil {
+		return nil
+	}
+	v := float64(10 - *rir)
+	return &v
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckEffort(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	i := func(v int) *int { return &v }
+	cases := []struct {
+		rpe  *float64
+		rir  *int
+		want error
+	}{
+		{nil, nil, nil},
+		{f(8), nil, nil},
+		{nil, i(2), nil},
+		{f(8.5), i(2), nil},
+		{f(9), i(0), nil},
+		{f(6), i(2), ErrEffortMismatch},
+		{f(11), nil, ErrInvalidRPE},
+		{nil, i(-1), ErrInvalidRIR},
+	}
+	for _, c := range cases {
+		if err := CheckEffort(c.rpe, c.rir); !errors.Is(err, c.want) {
+			t.Errorf("CheckEffort(%v, %v) = %v, want %v", c.rpe, c.rir, err, c.want)
+		}
+	}
+	if got := EffectiveRPE(nil, i(3)); got == nil || *got != 7 {
+		t.Fatalf("EffectiveRPE from rir 3 = %v, want 7", got)
+	}
+	if got := EffectiveRPE(f(9), i(2)); *got != 9 {
+		t.Fatalf("EffectiveRPE should prefer rpe, got %v", *got)
+	}
+}
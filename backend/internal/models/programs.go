@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type Program struct {
+	ID          string        `db:"id" json:"id"`
+	UserID      string        `db:"user_id" json:"userId"`
+	Name        string        `db:"name" json:"name"`
+	Description *string       `db:"description" json:"description,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time     `db:"updated_at" json:"updatedAt"`
+	Weeks       []ProgramWeek `json:"weeks"`
+}
+
+type ProgramWeek struct {
+	ID         string       `db:"id" json:"id"`
+	ProgramID  string       `db:"program_id" json:"programId"`
+	WeekNumber int          `db:"week_number" json:"weekNumber"`
+	Name       *string      `db:"name" json:"name,omitempty"`
+	Days       []ProgramDay `json:"days"`
+}
+
+// ProgramDay is one day of a program week; DayNumber 1..7 is the offset from
+// the week's start date when scheduling.
+type ProgramDay struct {
+	ID        string            `db:"id" json:"id"`
+	WeekID    string            `db:"week_id" json:"weekId"`
+	DayNumber int               `db:"day_number" json:"dayNumber"`
+	Name      *string           `db:"name" json:"name,omitempty"`
+	IsRestDay bool              `db:"is_rest_day" json:"isRestDay"`
+	Exercises []ProgramExercise `json:"exercises"`
+}
+
+// ProgramExercise is a planned exercise. TargetReps is the bottom of a rep
+// range when TargetRepsMax is set ("8-12"); AMRAP marks the last set as many
+// reps as possible, at least TargetReps.
+type ProgramExercise struct {
+	ID             string   `db:"id" json:"id"`
+	ProgramDayID   string   `db:"program_day_id" json:"programDayId"`
+	CatalogID      string   `db:"catalog_id" json:"catalogId"`
+	Name           string   `db:"name" json:"name"`
+	Position       int      `db:"position" json:"position"`
+	TargetSets     int      `db:"target_sets" json:"targetSets"`
+	TargetReps     int      `db:"target_reps" json:"targetReps"`
+	TargetRepsMax  *int     `db:"target_reps_max" json:"targetRepsMax,omitempty"`
+	AMRAP          bool     `db:"amrap" json:"amrap"`
+	TargetWeightKg *float64 `db:"target_weight_kg" json:"targetWeightKg,omitempty"`
+	Notes          *string  `db:"notes" json:"notes,omitempty"`
+}
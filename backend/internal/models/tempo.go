@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// maxTempoCount caps each phase of a tempo, in seconds.
+const maxTempoCount = 60
+
+var ErrInvalidTempo = errors.New("tempo must be four counts such as 3-1-1-0, each 0-60 seconds or X")
+
+// NormalizeTempo validates a lifting tempo - eccentric, bottom pause,
+// concentric and top pause, in seconds, with X for explosive - and returns it
+// as "3-1-X-0". Counts may be separated by -, :, / or spaces, or run together
+// as single digits ("31X0"). A blank tempo normalizes to "".
+func NormalizeTempo(s string) (string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == ':' || r == '/' || r == ' '
+	})
+	if len(parts) == 1 && len(s) == 4 {
+		parts = strings.Split(s, "")
+	}
+	if len(parts) != 4 {
+		return "", ErrInvalidTempo
+	}
+	for i, p := range parts {
+		if p == "X" {
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || n > maxTempoCount {
+			return "", ErrInvalidTempo
+		}
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// TempoSeconds returns how long one rep takes at tempo, counting X as one
+// second; false when tempo isn't valid.
+func TempoSeconds(tempo string) (int, bool) {
+	norm, err := NormalizeTempo(tempo)
+	if err != nil || norm == "" {
+		return 0, false
+	}
+	total := 0
+	for _, p := range strings.Split(norm, "-") {
+		if p == "X" {
+			total++
+			continue
+		}
+		n, _ := strconv.Atoi(p)
+		total += n
+	}
+	return total, true
+}
+
+// TimeUnderTension estimates a set's seconds under tension as reps × tempo,
+// or nil without reps or a valid tempo.
+func TimeUnderTension(tempo *string, reps int) *int {
+	if tempo == nil || reps <= 0 {
+		return nil
+	}
+	perRep, ok := TempoSeconds(*tempo)
+	if !ok {
+		return nil
+	}
+	tut := perRep * reps
+	return &tut
+}
+
+// MarshalJSON adds timeUnderTensionSeconds, derived from the set's tempo and
+// reps, to every set payload.
+func (s Set) MarshalJSON() ([]byte, error) {
+	type set Set
+	return json.Marshal(struct {
+		set
+		TimeUnderTensionSeconds *int `json:"timeUnderTensionSeconds,omitempty"`
+	}{set(s), TimeUnderTension(s.Tempo, s.Reps)})
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTempo(t *testing.T) {
+	for in, want := range map[string]string{
+		"3-1-1-0":    "3-1-1-0",
+		" 31x0 ":     "3-1-X-0",
+		"4:0:1:2":    "4-0-1-2",
+		"10 / 2 x 0": "10-2-X-0",
+		"03-1-1-0":   "3-1-1-0",
+		"":           "",
+	} {
+		got, err := NormalizeTempo(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeTempo(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"slow", "3-1-1", "3-1-1-0-0", "3-1-61-0", "3-1-Y-0", "311"} {
+		if _, err := NormalizeTempo(in); err == nil {
+			t.Errorf("NormalizeTempo(%q) should fail", in)
+		}
+	}
+}
+
+func TestTimeUnderTension(t *testing.T) {
+	tempo := "3-1-X-0"
+	if got := TimeUnderTension(&tempo, 8); got == nil || *got != 40 {
+		t.Fatalf("got %v, want 40", got)
+	}
+	bad := "slow"
+	if TimeUnderTension(&bad, 8) != nil || TimeUnderTension(&tempo, 0) != nil || TimeUnderTension(nil, 8) != nil {
+		t.Fatal("expected nil without a valid tempo or reps")
+	}
+}
+
+func TestSetJSONTimeUnderTension(t *testing.T) {
+	tempo := "3-1-X-0"
+	b, err := json.Marshal(Set{ID: "s1", Reps: 8, Tempo: &tempo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"timeUnderTensionSeconds":40`) || !strings.Contains(string(b), `"id":"s1"`) {
+		t.Fatalf("json = %s", b)
+	}
+	b, _ = json.Marshal(&Set{ID: "s2", Reps: 8})
+	if strings.Contains(string(b), "timeUnderTension") {
+		t.Fatalf("set without tempo has tut: %s", b)
+	}
+}
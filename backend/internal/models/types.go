@@ -3,11 +3,14 @@ package models
 import "time"
 
 type User struct {
-	ID           string    `db:"id" json:"id"`
-	Email        string    `db:"email" json:"email"`
-	PasswordHash string    `db:"password_hash" json:"-"`
-	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+	ID           string     `db:"id" json:"id"`
+	Email        string     `db:"email" json:"email"`
+	PasswordHash string     `db:"password_hash" json:"-"`
+	DeletedAt    *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
+	PurgeAfter   *time.Time `db:"purge_after" json:"purgeAfter,omitempty"`
+	IsGuest      bool       `db:"is_guest" json:"isGuest"`
+	CreatedAt    time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updatedAt"`
 }
 
 type WorkoutDay struct {
@@ -15,23 +18,38 @@ type WorkoutDay struct {
 	UserID      string    `db:"user_id" json:"userId"`
 	WorkoutDate time.Time `db:"workout_date" json:"workoutDate"`
 	Timezone    *string   `db:"timezone" json:"timezone,omitempty"`
-	Notes       *string   `db:"notes" json:"notes,omitempty"`
+	Notes       *string   `db:"notes" json:"notes"`
 	IsRestDay   bool      `db:"is_rest_day" json:"isRestDay"`
-	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+	Visibility  *string   `db:"visibility" json:"visibility"` // private | friends; nil uses the owner's default
+	ProgramID   *string   `db:"program_id" json:"programId,omitempty"`
+	// Session timing; DurationSeconds is set once both ends are known.
+	StartedAt       *time.Time `db:"started_at" json:"startedAt,omitempty"`
+	FinishedAt      *time.Time `db:"finished_at" json:"finishedAt,omitempty"`
+	DurationSeconds *int       `db:"duration_seconds" json:"durationSeconds,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updatedAt"`
 }
 
 type Exercise struct {
-	ID        string          `db:"id" json:"id"`
-	DayID     string          `db:"day_id" json:"dayId"`
-	CatalogID *string         `db:"catalog_id" json:"catalogId,omitempty"`
-	Name      string          `db:"name" json:"name"`
-	Position  int             `db:"position" json:"position"`
-	Comment   *string         `db:"comment" json:"comment,omitempty"`
-	CreatedAt time.Time       `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time       `db:"updated_at" json:"updatedAt"`
-	Sets      []Set           `json:"sets,omitempty"`
-	Timeline  []ExerciseEntry `json:"timeline,omitempty"`
+	ID        string  `db:"id" json:"id"`
+	DayID     string  `db:"day_id" json:"dayId"`
+	CatalogID *string `db:"catalog_id" json:"catalogId,omitempty"`
+	Name      string  `db:"name" json:"name"`
+	Position  int     `db:"position" json:"position"`
+	Comment   *string `db:"comment" json:"comment,omitempty"`
+	// Planned targets, set when the exercise was scheduled from a program.
+	// PlannedReps is the bottom of the range when PlannedRepsMax is set;
+	// PlannedAMRAP marks the last planned set as many reps as possible.
+	PlannedSets     *int            `db:"planned_sets" json:"plannedSets,omitempty"`
+	PlannedReps     *int            `db:"planned_reps" json:"plannedReps,omitempty"`
+	PlannedRepsMax  *int            `db:"planned_reps_max" json:"plannedRepsMax,omitempty"`
+	PlannedAMRAP    bool            `db:"planned_amrap" json:"plannedAmrap"`
+	PlannedWeightKg *float64        `db:"planned_weight_kg" json:"plannedWeightKg,omitempty"`
+	CreatedAt       time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updatedAt"`
+	Sets            []Set           `json:"sets,omitempty"`
+	Rests           []RestPeriod    `json:"rests,omitempty"`
+	Timeline        []ExerciseEntry `json:"timeline"`
 }
 
 type Set struct {
@@ -43,13 +61,64 @@ type Set struct {
 	Reps        int        `db:"reps" json:"reps"`
 	WeightKg    float64    `db:"weight_kg" json:"weightKg"`
 	RPE         *float64   `db:"rpe" json:"rpe,omitempty"`
+	RIR         *int       `db:"rir" json:"rir,omitempty"`
 	IsWarmup    bool       `db:"is_warmup" json:"isWarmup"`
 	RestSeconds *int       `db:"rest_seconds" json:"restSeconds,omitempty"`
 	Tempo       *string    `db:"tempo" json:"tempo,omitempty"`
 	PerformedAt *time.Time `db:"performed_at" json:"performedAt,omitempty"`
-	VolumeKg    float64    `db:"volume_kg" json:"volumeKg"`
-	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
-	UpdatedAt   time.Time  `db:"updated_at" json:"updatedAt"`
+	// SetType is strength, cardio, duration or distance; non-strength sets
+	// may have zero reps and carry DurationSeconds and/or DistanceM.
+	SetType         string   `db:"set_type" json:"type"`
+	DurationSeconds *int     `db:"duration_seconds" json:"durationSeconds,omitempty"`
+	DistanceM       *float64 `db:"distance_m" json:"distanceM,omitempty"`
+	// Side marks unilateral work: left, right or both. Nil when not recorded.
+	Side *string `db:"side" json:"side,omitempty"`
+	// ToFailure marks a set taken to failure. AssistedReps counts how many of
+	// Reps were helped through; PartialReps are partial-range reps after the
+	// last full one and aren't part of Reps.
+	ToFailure    bool `db:"to_failure" json:"toFailure"`
+	AssistedReps int  `db:"assisted_reps" json:"assistedReps"`
+	PartialReps  int  `db:"partial_reps" json:"partialReps"`
+	// VolumeKg counts unassisted full reps only.
+	VolumeKg float64 `db:"volume_kg" json:"volumeKg"`
+	// EffectiveLoadKg is the weight moved: bodyweight x catalog multiplier
+	// plus WeightKg for bodyweight exercises, otherwise WeightKg.
+	EffectiveLoadKg float64   `db:"effective_load_kg" json:"effectiveLoadKg"`
+	CreatedAt       time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Set types.
+const (
+	SetTypeStrength = "strength"
+	SetTypeCardio   = "cardio"
+	SetTypeDuration = "duration"
+	SetTypeDistance = "distance"
+)
+
+// ValidSetType reports whether t is a known set type.
+func ValidSetType(t string) bool {
+	switch t {
+	case SetTypeStrength, SetTypeCardio, SetTypeDuration, SetTypeDistance:
+		return true
+	}
+	return false
+}
+
+// Set sides for unilateral work.
+const (
+	SideLeft  = "left"
+	SideRight = "right"
+	SideBoth  = "both"
+)
+
+// ValidSide reports whether side is a known set side.
+func ValidSide(side string) bool {
+	switch side {
+	case SideLeft, SideRight, SideBoth:
+		return true
+	}
+	return false
 }
 
 type RestPeriod struct {
@@ -67,8 +136,23 @@ type ExerciseEntry struct {
 	Rest *RestPeriod `json:"rest,omitempty"`
 }
 
+// WorkoutComment is feedback on a day, or on one exercise when ExerciseID is
+// set. ReadAt applies to comments from someone other than the day's owner.
+type WorkoutComment struct {
+	ID          string     `db:"id" json:"id"`
+	DayID       string     `db:"day_id" json:"dayId"`
+	ExerciseID  *string    `db:"exercise_id" json:"exerciseId,omitempty"`
+	AuthorID    string     `db:"author_id" json:"authorId"`
+	AuthorEmail string     `db:"author_email" json:"authorEmail"`
+	Body        string     `db:"body" json:"body"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+	ReadAt      *time.Time `db:"read_at" json:"readAt,omitempty"`
+}
+
 // Composite response
 type DayWithDetails struct {
 	WorkoutDay
-	Exercises []Exercise `json:"exercises"`
+	Exercises      []Exercise       `json:"exercises"`
+	Comments       []WorkoutComment `json:"comments,omitempty"`
+	UnreadComments int              `json:"unreadComments"`
 }
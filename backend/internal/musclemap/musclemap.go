@@ -0,0 +1,176 @@
+// Package musclemap turns the free-form muscle names on catalog entries into
+// activation levels on a fixed set of body diagram regions.
+package musclemap
+
+import (
+	"sort"
+	"strings"
+)
+
+// Version identifies the region set below. Bump it when regions are added,
+// renamed or moved between views so clients can refresh their diagrams.
+const Version = 1
+
+// Activation levels.
+const (
+	Primary   = 1.0
+	Secondary = 0.5
+)
+
+// Views of the body diagram.
+const (
+	Anterior  = "anterior"
+	Posterior = "posterior"
+)
+
+// Region is a highlightable area of the body diagram. A region can appear on
+// both views (shoulders, for example).
+type Region struct {
+	ID    string   `json:"id"`
+	Views []string `json:"views"`
+}
+
+// Regions is the canonical taxonomy, sorted by ID.
+var Regions = []Region{
+	{"abdominals", []string{Anterior}},
+	{"abductors", []string{Anterior, Posterior}},
+	{"adductors", []string{Anterior}},
+	{"biceps", []string{Anterior}},
+	{"calves", []string{Posterior}},
+	{"chest", []string{Anterior}},
+	{"forearms", []string{Anterior, Posterior}},
+	{"glutes", []string{Posterior}},
+	{"hamstrings", []string{Posterior}},
+	{"hip-flexors", []string{Anterior}},
+	{"lats", []string{Posterior}},
+	{"lower-back", []string{Posterior}},
+	{"middle-back", []string{Posterior}},
+	{"neck", []string{Anterior, Posterior}},
+	{"obliques", []string{Anterior}},
+	{"quadriceps", []string{Anterior}},
+	{"shoulders", []string{Anterior, Posterior}},
+	{"tibialis", []string{Anterior}},
+	{"traps", []string{Posterior}},
+	{"triceps", []string{Posterior}},
+}
+
+// aliases maps normalized muscle names, as found in the catalog, to region
+// IDs. Region IDs themselves are resolved without an entry here.
+var aliases = map[string]string{
+	"abs":               "abdominals",
+	"core":              "abdominals",
+	"rectus abdominis":  "abdominals",
+	"abductor":          "abductors",
+	"hip abductors":     "abductors",
+	"adductor":          "adductors",
+	"hip adductors":     "adductors",
+	"inner thighs":      "adductors",
+	"bicep":             "biceps",
+	"biceps brachii":    "biceps",
+	"calf":              "calves",
+	"gastrocnemius":     "calves",
+	"soleus":            "calves",
+	"pecs":              "chest",
+	"pectorals":         "chest",
+	"pectoralis major":  "chest",
+	"forearm":           "forearms",
+	"grip":              "forearms",
+	"glute":             "glutes",
+	"gluteus maximus":   "glutes",
+	"gluteus medius":    "abductors",
+	"hamstring":         "hamstrings",
+	"hip flexor":        "hip-flexors",
+	"hip flexors":       "hip-flexors",
+	"latissimus dorsi":  "lats",
+	"lat":               "lats",
+	"lower back":        "lower-back",
+	"erector spinae":    "lower-back",
+	"middle back":       "middle-back",
+	"upper back":        "middle-back",
+	"rhomboids":         "middle-back",
+	"oblique":           "obliques",
+	"quads":             "quadriceps",
+	"quad":              "quadriceps",
+	"delts":             "shoulders",
+	"deltoids":          "shoulders",
+	"front delts":       "shoulders",
+	"rear delts":        "shoulders",
+	"side delts":        "shoulders",
+	"shoulder":          "shoulders",
+	"tibialis anterior": "tibialis",
+	"shins":             "tibialis",
+	"trapezius":         "traps",
+	"trap":              "traps",
+	"tricep":            "triceps",
+	"triceps brachii":   "triceps",
+}
+
+var regionsByID = func() map[string]Region {
+	m := make(map[string]Region, len(Regions))
+	for _, r := range Regions {
+		m[r.ID] = r
+	}
+	return m
+}()
+
+// Map is the activation of each region per view. Regions that an exercise
+// doesn't work are absent. Unmapped lists input names with no region, so
+// they can be added to the aliases.
+type Map struct {
+	Version   int                `json:"version"`
+	Anterior  map[string]float64 `json:"anterior"`
+	Posterior map[string]float64 `json:"posterior"`
+	Unmapped  []string           `json:"unmapped"`
+}
+
+// Resolve returns the region ID for a catalog muscle name, or "" when the
+// name is unknown.
+func Resolve(muscle string) string {
+	key := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(muscle, "-", " "))), " ")
+	if id, ok := aliases[key]; ok {
+		return id
+	}
+	if id := strings.ReplaceAll(key, " ", "-"); regionsByID[id].ID != "" {
+		return id
+	}
+	return ""
+}
+
+// Build maps primary muscles to Primary and secondary ones to Secondary. A
+// region reached by both keeps the higher level.
+func Build(primary, secondary []string) Map {
+	m := Map{
+		Version:   Version,
+		Anterior:  map[string]float64{},
+		Posterior: map[string]float64{},
+		Unmapped:  []string{},
+	}
+	unmapped := map[string]struct{}{}
+	apply := func(muscles []string, level float64) {
+		for _, name := range muscles {
+			id := Resolve(name)
+			if id == "" {
+				if trimmed := strings.TrimSpace(name); trimmed != "" {
+					unmapped[trimmed] = struct{}{}
+				}
+				continue
+			}
+			for _, view := range regionsByID[id].Views {
+				dst := m.Anterior
+				if view == Posterior {
+					dst = m.Posterior
+				}
+				if level > dst[id] {
+					dst[id] = level
+				}
+			}
+		}
+	}
+	apply(primary, Primary)
+	apply(secondary, Secondary)
+	for name := range unmapped {
+		m.Unmapped = append(m.Unmapped, name)
+	}
+	sort.Strings(m.Unmapped)
+	return m
+}
@@ -0,0 +1,59 @@
+package musclemap
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	cases := map[string]string{
+		"Quadriceps":       "quadriceps",
+		"Middle Back":      "middle-back",
+		"  lower   back ":  "lower-back",
+		"Latissimus Dorsi": "lats",
+		"rear-delts":       "shoulders",
+		"Hip Flexors":      "hip-flexors",
+		"Spleen":           "",
+		"":                 "",
+	}
+	for in, want := range cases {
+		if got := Resolve(in); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	m := Build([]string{"Chest", "Shoulders"}, []string{"Triceps", "Shoulders", "Serratus"})
+	wantAnterior := map[string]float64{"chest": Primary, "shoulders": Primary}
+	wantPosterior := map[string]float64{"shoulders": Primary, "triceps": Secondary}
+	if !reflect.DeepEqual(m.Anterior, wantAnterior) {
+		t.Errorf("anterior = %v, want %v", m.Anterior, wantAnterior)
+	}
+	if !reflect.DeepEqual(m.Posterior, wantPosterior) {
+		t.Errorf("posterior = %v, want %v", m.Posterior, wantPosterior)
+	}
+	if !reflect.DeepEqual(m.Unmapped, []string{"Serratus"}) {
+		t.Errorf("unmapped = %v", m.Unmapped)
+	}
+	if m.Version != Version {
+		t.Errorf("version = %d", m.Version)
+	}
+}
+
+func TestRegionsSortedAndViewed(t *testing.T) {
+	if !sort.SliceIsSorted(Regions, func(i, j int) bool { return Regions[i].ID < Regions[j].ID }) {
+		t.Fatal("Regions must be sorted by ID")
+	}
+	for _, r := range Regions {
+		if len(r.Views) == 0 {
+			t.Errorf("region %s has no view", r.ID)
+		}
+	}
+	for alias, id := range aliases {
+		if _, ok := regionsByID[id]; !ok {
+			t.Errorf("alias %q points at unknown region %q", alias, id)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package notify delivers user notifications by email and web push.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// ErrNotConfigured is returned by senders whose settings are missing.
+var ErrNotConfigured = errors.New("notification channel not configured")
+
+// Mailer sends plain-text email over SMTP. A nil Mailer is not configured.
+type Mailer struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+// NewMailer returns nil when addr or from is empty.
+func NewMailer(addr, from, username, password string) *Mailer {
+	if addr == "" || from == "" {
+		return nil
+	}
+	return &Mailer{Addr: addr, From: from, Username: username, Password: password}
+}
+
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	if m == nil {
+		return ErrNotConfigured
+	}
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return errors.New("invalid header value")
+	}
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return fmt.Errorf("smtp addr: %w", err)
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + time.Now().UTC().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		body,
+	}, "\r\n")
+	// net/smtp has no context support; run it so a cancelled ctx returns.
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(m.Addr, auth, m.From, []string{to}, []byte(msg)) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+
+	"exercise-tracker/internal/linkmeta"
+)
+
+// ErrSubscriptionGone means the push service no longer knows the
+// subscription; it should be deleted.
+var ErrSubscriptionGone = errors.New("push subscription expired")
+
+const pushTimeout = 15 * time.Second
+
+// pushClient delivers to user-supplied endpoints, so like the link fetchers
+// it refuses private and loopback addresses and doesn't follow redirects.
+var pushClient = &http.Client{
+	Timeout: pushTimeout,
+	Transport: &http.Transport{
+		DialContext:           (&net.Dialer{Timeout: pushTimeout, Control: linkmeta.PublicOnly}).DialContext,
+		TLSHandshakeTimeout:   pushTimeout,
+		ResponseHeaderTimeout: pushTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       time.Minute,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// PushSubscription is what the browser's PushManager.subscribe() returns.
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	P256dh   string `json:"p256dh"`
+	Auth     string `json:"auth"`
+}
+
+// WebPush sends encrypted (RFC 8291) messages signed with a VAPID key
+// (RFC 8292). A nil WebPush is not configured.
+type WebPush struct {
+	publicKey string // base64url, uncompressed P-256 point
+	key       *ecdsa.PrivateKey
+	subject   string
+	client    *http.Client
+}
+
+// NewWebPush takes the VAPID key pair as unpadded base64url strings (the
+// format web-push tooling generates) and a mailto: or https: subject. It
+// returns nil, nil when the keys are empty.
+func NewWebPush(publicKey, privateKey, subject string) (*WebPush, error) {
+	if publicKey == "" || privateKey == "" {
+		return nil, nil
+	}
+	d, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("vapid private key: %w", err)
+	}
+	priv, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("vapid private key: %w", err)
+	}
+	pub := priv.PublicKey().Bytes()
+	if base64.RawURLEncoding.EncodeToString(pub) != publicKey {
+		return nil, errors.New("vapid public key does not match the private key")
+	}
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+	return &WebPush{publicKey: publicKey, key: key, subject: subject, client: pushClient}, nil
+}
+
+// PublicKey is the applicationServerKey browsers subscribe with.
+func (p *WebPush) PublicKey() string {
+	if p == nil {
+		return ""
+	}
+	return p.publicKey
+}
+
+// Send delivers payload to sub. It returns ErrSubscriptionGone when the push
+// service answers 404 or 410.
+func (p *WebPush) Send(ctx context.Context, sub PushSubscription, payload []byte, ttl time.Duration) error {
+	if p == nil {
+		return ErrNotConfigured
+	}
+	body, err := encryptPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" {
+		return fmt.Errorf("invalid push endpoint")
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": p.subject,
+	}).SignedString(p.key)
+	if err != nil {
+		return fmt.Errorf("vapid token: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(ttl.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+p.publicKey)
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case res.StatusCode >= 300:
+		return fmt.Errorf("push service: status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// recordSize is the aes128gcm record size advertised in the header; the
+// payload always fits in a single record.
+const recordSize = 4096
+
+// encryptPayload implements the aes128gcm content coding of RFC 8291 with a
+// fresh sender key and salt per message.
+func encryptPayload(sub PushSubscription, payload []byte) ([]byte, error) {
+	uaPublic, err := base64.RawURLEncoding.DecodeString(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("p256dh: %w", err)
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	if len(payload) > recordSize-16-1-86 {
+		return nil, errors.New("push payload too large")
+	}
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("p256dh: %w", err)
+	}
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asKey.PublicKey().Bytes()
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, nonce, err := deriveKeys(shared, authSecret, salt, uaPublic, asPublic)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// A single, final record: payload followed by the 0x02 delimiter.
+	plain := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plain, nil), nil
+}
+
+func deriveKeys(shared, authSecret, salt, uaPublic, asPublic []byte) (cek, nonce []byte, err error) {
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, authSecret, keyInfo), ikm); err != nil {
+		return nil, nil, err
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek = make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
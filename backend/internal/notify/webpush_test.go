@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+// TestEncryptPayloadRoundTrip decrypts as the user agent would.
+func TestEncryptPayloadRoundTrip(t *testing.T) {
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+	uaPublic := uaKey.PublicKey().Bytes()
+	sub := PushSubscription{
+		Endpoint: "https://push.example.com/x",
+		P256dh:   base64.RawURLEncoding.EncodeToString(uaPublic),
+		Auth:     base64.RawURLEncoding.EncodeToString(authSecret),
+	}
+	body, err := encryptPayload(sub, []byte(`{"title":"Leg day"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != recordSize {
+		t.Fatalf("record size = %d", rs)
+	}
+	idLen := int(body[20])
+	asPublic := body[21 : 21+idLen]
+	asKey, err := ecdh.P256().NewPublicKey(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := uaKey.ECDH(asKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek, nonce, err := deriveKeys(shared, authSecret, salt, uaPublic, asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if got, want := string(plain), "{\"title\":\"Leg day\"}\x02"; got != want {
+		t.Fatalf("plaintext = %q, want %q", got, want)
+	}
+}
+
+func TestNewWebPushRejectsMismatchedKeys(t *testing.T) {
+	a, _ := ecdh.P256().GenerateKey(rand.Reader)
+	b, _ := ecdh.P256().GenerateKey(rand.Reader)
+	enc := base64.RawURLEncoding.EncodeToString
+	if _, err := NewWebPush(enc(b.PublicKey().Bytes()), enc(a.Bytes()), "mailto:ops@example.com"); err == nil {
+		t.Fatal("expected mismatch error")
+	}
+	p, err := NewWebPush(enc(a.PublicKey().Bytes()), enc(a.Bytes()), "mailto:ops@example.com")
+	if err != nil || p == nil {
+		t.Fatalf("NewWebPush: %v", err)
+	}
+}
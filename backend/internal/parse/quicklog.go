@@ -0,0 +1,270 @@
+// Package parse reads the free-text quick log, e.g.
+// "bench 3x5 @ 80kg, squat 5x5 100", into exercises and sets.
+//
+// Entries are separated by commas, semicolons or new lines. Each is an
+// exercise name followed by one or more groups of "<sets>x<reps>", each
+// optionally followed by a weight: "@ 80", "80kg" or "175 lb". Groups
+// without a weight are bodyweight sets (0 kg).
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Limits on one quick log.
+const (
+	MaxEntries      = 20
+	MaxSetsPerGroup = 20
+	MaxReps         = 1000
+	MaxWeightKg     = 1000
+)
+
+const kgPerLb = 0.45359237
+
+type Set struct {
+	Reps     int     `json:"reps"`
+	WeightKg float64 `json:"weightKg"`
+}
+
+type Entry struct {
+	// Text is the entry as written.
+	Text string `json:"text"`
+	// Name is the exercise name with abbreviations expanded.
+	Name string `json:"name"`
+	Sets []Set  `json:"sets"`
+}
+
+// Error reports the entry that failed to parse.
+type Error struct {
+	Text string
+	Msg  string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("%q: %s", e.Text, e.Msg) }
+
+// abbreviations are expanded word by word in exercise names.
+var abbreviations = map[string]string{
+	"bb":    "barbell",
+	"db":    "dumbbell",
+	"kb":    "kettlebell",
+	"ohp":   "overhead press",
+	"rdl":   "romanian deadlift",
+	"dl":    "deadlift",
+	"deads": "deadlift",
+}
+
+// QuickLog parses text into entries.
+func QuickLog(text string) ([]Entry, error) {
+	var out []Entry
+	for _, raw := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		e, err := entry(raw)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+		if len(out) > MaxEntries {
+			return nil, fmt.Errorf("at most %d exercises per quick log", MaxEntries)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("nothing to log")
+	}
+	return out, nil
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokNumber
+	tokTimes
+	tokAt
+	tokUnit
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+// tokenize splits an entry into words, numbers, "x", "@" and units. An "x"
+// between digits ("3x5") is a times sign; a unit may be glued to its number
+// ("80kg").
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	rs := []rune(strings.ToLower(s))
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '@':
+			toks = append(toks, token{kind: tokAt, text: "@"})
+			i++
+		case r == '×' || r == '*':
+			toks = append(toks, token{kind: tokTimes, text: "x"})
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(string(rs[i:j]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", string(rs[i:j]))
+			}
+			toks = append(toks, token{kind: tokNumber, text: string(rs[i:j]), num: n})
+			i = j
+		case unicode.IsLetter(r) || r == '-' || r == '\'':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || rs[j] == '-' || rs[j] == '\'') {
+				j++
+			}
+			word := string(rs[i:j])
+			prevNumber := len(toks) > 0 && toks[len(toks)-1].kind == tokNumber
+			switch {
+			case word == "x":
+				toks = append(toks, token{kind: tokTimes, text: "x"})
+			case prevNumber && isUnit(word):
+				toks = append(toks, token{kind: tokUnit, text: word})
+			default:
+				toks = append(toks, token{kind: tokWord, text: word})
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return toks, nil
+}
+
+func isUnit(w string) bool {
+	switch w {
+	case "kg", "kgs", "lb", "lbs":
+		return true
+	}
+	return false
+}
+
+// Group is one "<sets>x<reps>" group with the weight written after it, nil
+// when none was.
+type Group struct {
+	Sets     int      `json:"sets"`
+	Reps     int      `json:"reps"`
+	WeightKg *float64 `json:"weightKg"`
+}
+
+func entry(text string) (Entry, error) {
+	fail := func(format string, args ...any) (Entry, error) {
+		return Entry{}, &Error{Text: text, Msg: fmt.Sprintf(format, args...)}
+	}
+	toks, err := tokenize(text)
+	if err != nil {
+		return fail("%v", err)
+	}
+	words, toks := name(toks)
+	if len(words) == 0 {
+		return fail("expected an exercise name first")
+	}
+	e := Entry{Text: text, Name: strings.Join(words, " ")}
+	if len(toks) == 0 {
+		return fail("expected sets x reps after %q", e.Name)
+	}
+	groups, err := readGroups(toks, false)
+	if err != nil {
+		return fail("%v", err)
+	}
+	for _, g := range groups {
+		weight := 0.0
+		if g.WeightKg != nil {
+			weight = *g.WeightKg
+		}
+		for n := 0; n < g.Sets; n++ {
+			e.Sets = append(e.Sets, Set{Reps: g.Reps, WeightKg: weight})
+		}
+	}
+	return e, nil
+}
+
+// name takes the leading words as the exercise name, abbreviations expanded,
+// and returns the tokens after it.
+func name(toks []token) ([]string, []token) {
+	var words []string
+	i := 0
+	for ; i < len(toks) && toks[i].kind == tokWord; i++ {
+		w := toks[i].text
+		if full, ok := abbreviations[w]; ok {
+			w = full
+		}
+		words = append(words, w)
+	}
+	return words, toks[i:]
+}
+
+// readGroups reads the set groups that make up the rest of an entry. With
+// bare, a reps count without "<sets>x" is a single set ("8 @ 100").
+func readGroups(toks []token, bare bool) ([]Group, error) {
+	var out []Group
+	for i := 0; i < len(toks); {
+		var sets, reps float64
+		switch {
+		case i+2 < len(toks) && toks[i].kind == tokNumber && toks[i+1].kind == tokTimes && toks[i+2].kind == tokNumber:
+			sets, reps = toks[i].num, toks[i+2].num
+			i += 3
+		case bare && toks[i].kind == tokNumber && !(i+1 < len(toks) && toks[i+1].kind == tokTimes):
+			sets, reps = 1, toks[i].num
+			i++
+		default:
+			return nil, errors.New("expected sets x reps, e.g. 3x5")
+		}
+		if sets != math.Trunc(sets) || sets < 1 || sets > MaxSetsPerGroup {
+			return nil, fmt.Errorf("sets must be a whole number from 1 to %d", MaxSetsPerGroup)
+		}
+		if reps != math.Trunc(reps) || reps < 1 || reps > MaxReps {
+			return nil, fmt.Errorf("reps must be a whole number from 1 to %d", MaxReps)
+		}
+		g := Group{Sets: int(sets), Reps: int(reps)}
+		at := i < len(toks) && toks[i].kind == tokAt
+		if at {
+			i++
+		}
+		// A number right after the group is its weight unless it starts the
+		// next group. With bare any number can, so it needs "@" or a unit.
+		weighted := at
+		if !at && i < len(toks) && toks[i].kind == tokNumber {
+			next := tokWord
+			if i+1 < len(toks) {
+				next = toks[i+1].kind
+			}
+			weighted = next != tokTimes && (!bare || next == tokUnit)
+		}
+		if weighted && i < len(toks) && toks[i].kind == tokNumber {
+			weight := toks[i].num
+			i++
+			if i < len(toks) && toks[i].kind == tokUnit {
+				if strings.HasPrefix(toks[i].text, "lb") {
+					weight = math.Round(weight*kgPerLb*100) / 100
+				}
+				i++
+			}
+			if weight > MaxWeightKg {
+				return nil, fmt.Errorf("weight must be at most %d kg", MaxWeightKg)
+			}
+			g.WeightKg = &weight
+		} else if at {
+			return nil, errors.New("expected a weight after @")
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
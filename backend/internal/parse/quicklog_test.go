@@ -0,0 +1,58 @@
+package parse
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func sets(n, reps int, kg float64) []Set {
+	out := make([]Set, n)
+	for i := range out {
+		out[i] = Set{Reps: reps, WeightKg: kg}
+	}
+	return out
+}
+
+func TestQuickLog(t *testing.T) {
+	got, err := QuickLog("Bench 3x5 @ 80kg, squat 5x5 100; OHP 2 x 8 @95 lbs\npull-ups 3×10\nrdl 1x8 60 1x6 70")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Text: "Bench 3x5 @ 80kg", Name: "bench", Sets: sets(3, 5, 80)},
+		{Text: "squat 5x5 100", Name: "squat", Sets: sets(5, 5, 100)},
+		{Text: "OHP 2 x 8 @95 lbs", Name: "overhead press", Sets: sets(2, 8, 43.09)},
+		{Text: "pull-ups 3×10", Name: "pull-ups", Sets: sets(3, 10, 0)},
+		{Text: "rdl 1x8 60 1x6 70", Name: "romanian deadlift", Sets: append(sets(1, 8, 60), sets(1, 6, 70)...)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestQuickLogErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		" , ",
+		"3x5 @ 80",
+		"bench",
+		"bench 80",
+		"bench 3x",
+		"bench 3x5 @",
+		"bench 0x5",
+		"bench 3x2.5",
+		"bench 50x5",
+		"bench 3x5 @ 2000",
+	} {
+		_, err := QuickLog(text)
+		if err == nil {
+			t.Errorf("QuickLog(%q) succeeded", text)
+			continue
+		}
+		var pe *Error
+		if text != "" && text != " , " && !errors.As(err, &pe) {
+			t.Errorf("QuickLog(%q) = %v, want *Error", text, err)
+		}
+	}
+}
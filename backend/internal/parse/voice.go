@@ -0,0 +1,155 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Utterance is a transcribed voice command, e.g. "bench, three sets of five
+// at eighty kilos", "eight reps at 100" or "same again".
+type Utterance struct {
+	// Heard is the transcript rewritten in quick log syntax.
+	Heard string `json:"heard"`
+	// Name is the exercise named, empty to log on the current one.
+	Name   string  `json:"name,omitempty"`
+	Groups []Group `json:"groups,omitempty"`
+	// Repeat asks for another set like the last one.
+	Repeat bool `json:"repeat,omitempty"`
+}
+
+var (
+	// fillers are dropped from transcripts.
+	fillers = map[string]bool{
+		"um": true, "uh": true, "okay": true, "ok": true, "so": true, "and": true, "then": true,
+		"i": true, "did": true, "just": true, "log": true, "add": true, "please": true, "a": true, "an": true,
+		"reps": true, "rep": true, "repetitions": true, "for": true,
+	}
+	// spoken maps words to quick log syntax.
+	spoken = map[string]string{
+		"at": "@", "with": "@", "by": "x", "times": "x",
+		"kilos": "kg", "kilo": "kg", "kilograms": "kg", "kilogram": "kg",
+		"pounds": "lb", "pound": "lb",
+		"bodyweight": "@ 0",
+	}
+	repeats = map[string]bool{
+		"again": true, "same": true, "same again": true, "repeat": true, "another": true,
+		"another set": true, "another one": true, "one more": true, "one more set": true,
+	}
+	smallNumbers = map[string]int{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8,
+		"nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15,
+		"sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	tens = map[string]int{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	}
+)
+
+// Transcript parses a voice command. Unlike QuickLog the exercise name is
+// optional, a bare reps count is one set and a weight without "@" needs its
+// unit, since speech runs numbers together.
+func Transcript(text string) (Utterance, error) {
+	fail := func(msg string) (Utterance, error) {
+		return Utterance{}, &Error{Text: text, Msg: msg}
+	}
+	// Periods only end words, so "82.5" survives.
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;:!?", r)
+	})
+	var kept []string
+	for _, w := range words {
+		w = strings.TrimSuffix(w, ".")
+		if w != "" && !fillers[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return fail("nothing heard")
+	}
+	if repeats[strings.Join(kept, " ")] {
+		return Utterance{Heard: "again", Repeat: true}, nil
+	}
+
+	kept = numbers(kept)
+	var out []string
+	for i := 0; i < len(kept); i++ {
+		w := kept[i]
+		switch {
+		case (w == "sets" || w == "set") && i+1 < len(kept) && kept[i+1] == "of":
+			// "three sets of five"; "set of five" is one set.
+			if len(out) == 0 || !isNumber(out[len(out)-1]) {
+				out = append(out, "1")
+			}
+			out = append(out, "x")
+			i++
+		case w == "body" && i+1 < len(kept) && kept[i+1] == "weight":
+			out = append(out, "@", "0")
+			i++
+		case spoken[w] != "":
+			out = append(out, spoken[w])
+		default:
+			out = append(out, w)
+		}
+	}
+	heard := strings.Join(out, " ")
+
+	toks, err := tokenize(heard)
+	if err != nil {
+		return fail(err.Error())
+	}
+	words, toks = name(toks)
+	u := Utterance{Heard: heard, Name: strings.Join(words, " ")}
+	if len(toks) == 0 {
+		return fail("expected reps, e.g. \"eight reps at 100\"")
+	}
+	if u.Groups, err = readGroups(toks, true); err != nil {
+		return fail(err.Error())
+	}
+	return u, nil
+}
+
+func isNumber(w string) bool {
+	_, err := strconv.ParseFloat(w, 64)
+	return err == nil
+}
+
+// numbers replaces spelled-out numbers up to the hundreds with digits:
+// "one hundred twenty five" is 125, while "three five" stays two numbers.
+func numbers(words []string) []string {
+	var out []string
+	n, last := 0, "" // last is "", "unit", "ten" or "hundred"
+	flush := func() {
+		if last != "" {
+			out = append(out, strconv.Itoa(n))
+		}
+		n, last = 0, ""
+	}
+	for _, w := range words {
+		if v, ok := smallNumbers[w]; ok {
+			if last == "unit" || (v >= 10 && last == "ten") {
+				flush()
+			}
+			n, last = n+v, "unit"
+			continue
+		}
+		if v, ok := tens[w]; ok {
+			if last == "unit" || last == "ten" {
+				flush()
+			}
+			n, last = n+v, "ten"
+			continue
+		}
+		if w == "hundred" && last != "hundred" {
+			if last == "" {
+				n = 1
+			}
+			n, last = n*100, "hundred"
+			continue
+		}
+		flush()
+		out = append(out, w)
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,40 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func kg(v float64) *float64 { return &v }
+
+func TestTranscript(t *testing.T) {
+	tests := []struct {
+		text string
+		want Utterance
+	}{
+		{"Bench, three sets of five at eighty kilos.", Utterance{Heard: "bench 3 x 5 @ 80 kg", Name: "bench",
+			Groups: []Group{{Sets: 3, Reps: 5, WeightKg: kg(80)}}}},
+		{"eight reps at 102.5", Utterance{Heard: "8 @ 102.5", Groups: []Group{{Sets: 1, Reps: 8, WeightKg: kg(102.5)}}}},
+		{"um twelve reps", Utterance{Heard: "12", Groups: []Group{{Sets: 1, Reps: 12}}}},
+		{"a set of 10 with one hundred twenty five pounds", Utterance{Heard: "1 x 10 @ 125 lb",
+			Groups: []Group{{Sets: 1, Reps: 10, WeightKg: kg(56.7)}}}},
+		{"pull ups ten reps bodyweight then eight", Utterance{Heard: "pull ups 10 @ 0 8", Name: "pull ups",
+			Groups: []Group{{Sets: 1, Reps: 10, WeightKg: kg(0)}, {Sets: 1, Reps: 8}}}},
+		{"Same again, please", Utterance{Heard: "again", Repeat: true}},
+	}
+	for _, tc := range tests {
+		got, err := Transcript(tc.text)
+		if err != nil {
+			t.Errorf("Transcript(%q): %v", tc.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Transcript(%q)\ngot  %+v\nwant %+v", tc.text, got, tc.want)
+		}
+	}
+	for _, text := range []string{"", "um", "bench", "eight at"} {
+		if _, err := Transcript(text); err == nil {
+			t.Errorf("Transcript(%q) succeeded", text)
+		}
+	}
+}
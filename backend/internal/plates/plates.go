@@ -0,0 +1,128 @@
+// Package plates works out which plates to load on each side of a bar.
+package plates
+
+import (
+	"math"
+	"sort"
+)
+
+// Weight units. Plate and bar weights are in the unit they were entered in.
+const (
+	UnitKg = "kg"
+	UnitLb = "lb"
+)
+
+const kgPerLb = 0.45359237
+
+// Plate is a plate size and how many of them are available in total (both
+// sides together).
+type Plate struct {
+	Weight float64 `json:"weight"`
+	Count  int     `json:"count"`
+}
+
+// Default inventories, ten of each size.
+var (
+	DefaultKg = []Plate{{25, 10}, {20, 10}, {15, 10}, {10, 10}, {5, 10}, {2.5, 10}, {1.25, 10}}
+	DefaultLb = []Plate{{45, 10}, {35, 10}, {25, 10}, {10, 10}, {5, 10}, {2.5, 10}}
+)
+
+// Default bar weights per unit.
+var (
+	DefaultBarsKg = []float64{20, 15}
+	DefaultBarsLb = []float64{45, 35}
+)
+
+// ToKg converts w in unit to kilograms.
+func ToKg(w float64, unit string) float64 {
+	if unit == UnitLb {
+		return w * kgPerLb
+	}
+	return w
+}
+
+// FromKg converts kilograms to unit, rounded to 2 decimals.
+func FromKg(kg float64, unit string) float64 {
+	if unit == UnitLb {
+		kg /= kgPerLb
+	}
+	return math.Round(kg*100) / 100
+}
+
+type Result struct {
+	Target   float64 `json:"target"`
+	Bar      float64 `json:"bar"`
+	PerSide  []Plate `json:"perSide"`
+	Achieved float64 `json:"achieved"`
+	// Remainder is target minus achieved; non-zero when the target can't be
+	// loaded exactly with the inventory.
+	Remainder float64 `json:"remainder"`
+}
+
+// Breakdown finds the per-side plates that get closest to target without
+// going over, using at most Count/2 plates of each size per side. Among
+// equally close loads it picks the one with the fewest plates, then the one
+// with more of the heavier plates.
+func Breakdown(target, bar float64, inventory []Plate) Result {
+	res := Result{Target: target, Bar: bar, PerSide: []Plate{}, Achieved: bar}
+	if target <= bar {
+		res.Remainder = round2(target - bar)
+		return res
+	}
+	// Work in hundredths so plate sums stay exact.
+	side := int(math.Floor((target-bar)/2*100 + 1e-6))
+	sizes := make([]Plate, 0, len(inventory))
+	for _, p := range inventory {
+		if p.Weight > 0 && p.Count >= 2 {
+			sizes = append(sizes, p)
+		}
+	}
+	// Lightest first so that, on ties, later (heavier) sizes win.
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Weight < sizes[j].Weight })
+
+	// best[w] is the fewest plates summing to exactly w, -1 if unreachable;
+	// pick[i][w] records how many of size i were used to reach w.
+	best := make([]int, side+1)
+	for w := range best {
+		best[w] = -1
+	}
+	best[0] = 0
+	pick := make([][]int, len(sizes))
+	for i, p := range sizes {
+		unit := int(math.Round(p.Weight * 100))
+		next := make([]int, side+1)
+		pick[i] = make([]int, side+1)
+		for w := range next {
+			next[w] = -1
+			for k := 0; k <= p.Count/2 && k*unit <= w; k++ {
+				prev := best[w-k*unit]
+				if prev < 0 {
+					continue
+				}
+				if next[w] < 0 || prev+k <= next[w] {
+					next[w] = prev + k
+					pick[i][w] = k
+				}
+			}
+		}
+		best = next
+	}
+	w := side
+	for w > 0 && best[w] < 0 {
+		w--
+	}
+	res.Achieved = round2(bar + 2*float64(w)/100)
+	res.Remainder = round2(target - res.Achieved)
+	for i := len(sizes) - 1; i >= 0; i-- {
+		k := pick[i][w]
+		if k > 0 {
+			res.PerSide = append(res.PerSide, Plate{Weight: sizes[i].Weight, Count: k})
+		}
+		w -= k * int(math.Round(sizes[i].Weight*100))
+	}
+	// Heaviest first, the order they go on the bar.
+	sort.Slice(res.PerSide, func(i, j int) bool { return res.PerSide[i].Weight > res.PerSide[j].Weight })
+	return res
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
@@ -0,0 +1,67 @@
+package plates
+
+import "testing"
+
+func TestBreakdown(t *testing.T) {
+	cases := []struct {
+		name      string
+		target    float64
+		bar       float64
+		inventory []Plate
+		perSide   []Plate
+		remainder float64
+	}{
+		{
+			name:   "exact kg load",
+			target: 102.5, bar: 20, inventory: DefaultKg,
+			perSide: []Plate{{25, 1}, {15, 1}, {1.25, 1}},
+		},
+		{
+			name:   "exact lb load",
+			target: 225, bar: 45, inventory: DefaultLb,
+			perSide: []Plate{{45, 2}},
+		},
+		{
+			name:   "limited inventory uses smaller plates",
+			target: 140, bar: 20, inventory: []Plate{{20, 2}, {10, 6}, {5, 4}},
+			perSide: []Plate{{20, 1}, {10, 3}, {5, 2}},
+		},
+		{
+			name:   "unreachable rounds down",
+			target: 101, bar: 20, inventory: DefaultKg,
+			perSide:   []Plate{{25, 1}, {15, 1}},
+			remainder: 1,
+		},
+		{
+			name:   "below bar",
+			target: 15, bar: 20, inventory: DefaultKg,
+			perSide:   []Plate{},
+			remainder: -5,
+		},
+	}
+	for _, c := range cases {
+		got := Breakdown(c.target, c.bar, c.inventory)
+		if got.Remainder != c.remainder {
+			t.Errorf("%s: remainder = %v, want %v", c.name, got.Remainder, c.remainder)
+		}
+		if len(got.PerSide) != len(c.perSide) {
+			t.Errorf("%s: perSide = %+v, want %+v", c.name, got.PerSide, c.perSide)
+			continue
+		}
+		for i := range got.PerSide {
+			if got.PerSide[i] != c.perSide[i] {
+				t.Errorf("%s: perSide = %+v, want %+v", c.name, got.PerSide, c.perSide)
+				break
+			}
+		}
+	}
+}
+
+func TestUnitConversion(t *testing.T) {
+	if got := FromKg(ToKg(225, UnitLb), UnitLb); got != 225 {
+		t.Fatalf("round trip = %v", got)
+	}
+	if got := FromKg(100, UnitLb); got != 220.46 {
+		t.Fatalf("FromKg(100, lb) = %v", got)
+	}
+}
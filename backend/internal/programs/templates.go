@@ -0,0 +1,260 @@
+// Package programs holds the built-in program templates and expands them into
+// a store.ProgramInput from a lifter's training maxes.
+package programs
+
+import (
+	"fmt"
+	"sort"
+
+	"exercise-tracker/internal/progression"
+	"exercise-tracker/internal/store"
+)
+
+// roundKg is the loading increment planned weights are rounded down to.
+const roundKg = 2.5
+
+// MaxWeeks caps how many weeks one instantiation may generate.
+const MaxWeeks = 52
+
+// Lifts maps the lift keys templates use, and training maxes are given for,
+// to catalog slugs.
+var Lifts = map[string]string{
+	"squat":    "barbell-back-squat",
+	"bench":    "barbell-bench-press",
+	"deadlift": "barbell-deadlift",
+	"press":    "barbell-overhead-press",
+	"row":      "barbell-row",
+	"pulldown": "lat-pulldown",
+	"dbrow":    "dumbbell-row",
+}
+
+// Slot is one prescription within a session.
+type Slot struct {
+	Lift string `json:"lift"`
+	Sets int    `json:"sets"`
+	Reps int    `json:"reps"`
+	// Percent is of the lift's training max; 0 leaves the weight unplanned
+	// and needs no training max.
+	Percent float64 `json:"percent,omitempty"`
+	// IncrementKg is added each time the slot is trained, or to the training
+	// max once per rotation for PerCycle templates.
+	IncrementKg float64 `json:"incrementKg,omitempty"`
+	// AMRAP marks the last set as many reps as possible.
+	AMRAP bool `json:"amrap,omitempty"`
+}
+
+type Session struct {
+	Name  string `json:"name"`
+	Slots []Slot `json:"slots"`
+}
+
+type Template struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Days are the day numbers (1-7) trained each week.
+	Days []int `json:"days"`
+	// Sessions are trained in rotation across Days, so a rotation can
+	// alternate within a week or span several.
+	Sessions []Session `json:"sessions"`
+	// PerCycle raises training maxes after each full rotation (5/3/1);
+	// otherwise a slot's weight climbs every time it's trained, separately
+	// for each lift and sets×reps scheme.
+	PerCycle bool `json:"perCycle"`
+	// Weeks is how many weeks are generated by default.
+	Weeks int `json:"weeks"`
+}
+
+// Builtin is the template library, in the order it is listed.
+var Builtin = []Template{
+	{
+		ID:          "531",
+		Name:        "5/3/1",
+		Description: "Wendler's four-week wave on the four main lifts: 5s, 3s, 5/3/1 and a deload, each top set for as many reps as possible. Training maxes go up 2.5 kg (5 kg squat and deadlift) each cycle.",
+		Days:        []int{1, 2, 4, 5},
+		Sessions:    wendler(),
+		PerCycle:    true,
+		Weeks:       4,
+	},
+	{
+		ID:          "stronglifts-5x5",
+		Name:        "StrongLifts 5×5",
+		Description: "Two alternating full-body workouts three days a week, starting at 80% of the training max and adding weight every session.",
+		Days:        []int{1, 3, 5},
+		Sessions: []Session{
+			{Name: "Workout A", Slots: []Slot{
+				{Lift: "squat", Sets: 5, Reps: 5, Percent: 0.8, IncrementKg: 2.5},
+				{Lift: "bench", Sets: 5, Reps: 5, Percent: 0.8, IncrementKg: 2.5},
+				{Lift: "row", Sets: 5, Reps: 5, Percent: 0.8, IncrementKg: 2.5},
+			}},
+			{Name: "Workout B", Slots: []Slot{
+				{Lift: "squat", Sets: 5, Reps: 5, Percent: 0.8, IncrementKg: 2.5},
+				{Lift: "press", Sets: 5, Reps: 5, Percent: 0.8, IncrementKg: 2.5},
+				{Lift: "deadlift", Sets: 1, Reps: 5, Percent: 0.8, IncrementKg: 5},
+			}},
+		},
+		Weeks: 12,
+	},
+	{
+		ID:          "gzclp",
+		Name:        "GZCLP",
+		Description: "Four days a week of a heavy tier 1 lift (5×3+ from 85%), a volume tier 2 lift (3×10 from 65%) and a tier 3 accessory, adding weight every session.",
+		Days:        []int{1, 2, 4, 5},
+		Sessions: []Session{
+			{Name: "A1", Slots: []Slot{
+				{Lift: "squat", Sets: 5, Reps: 3, Percent: 0.85, IncrementKg: 5, AMRAP: true},
+				{Lift: "bench", Sets: 3, Reps: 10, Percent: 0.65, IncrementKg: 2.5},
+				{Lift: "pulldown", Sets: 3, Reps: 15, AMRAP: true},
+			}},
+			{Name: "B1", Slots: []Slot{
+				{Lift: "press", Sets: 5, Reps: 3, Percent: 0.85, IncrementKg: 2.5, AMRAP: true},
+				{Lift: "deadlift", Sets: 3, Reps: 10, Percent: 0.65, IncrementKg: 5},
+				{Lift: "dbrow", Sets: 3, Reps: 15, AMRAP: true},
+			}},
+			{Name: "A2", Slots: []Slot{
+				{Lift: "bench", Sets: 5, Reps: 3, Percent: 0.85, IncrementKg: 2.5, AMRAP: true},
+				{Lift: "squat", Sets: 3, Reps: 10, Percent: 0.65, IncrementKg: 5},
+				{Lift: "pulldown", Sets: 3, Reps: 15, AMRAP: true},
+			}},
+			{Name: "B2", Slots: []Slot{
+				{Lift: "deadlift", Sets: 5, Reps: 3, Percent: 0.85, IncrementKg: 5, AMRAP: true},
+				{Lift: "press", Sets: 3, Reps: 10, Percent: 0.65, IncrementKg: 2.5},
+				{Lift: "dbrow", Sets: 3, Reps: 15, AMRAP: true},
+			}},
+		},
+		Weeks: 12,
+	},
+}
+
+// wendler builds the 16 sessions of a 5/3/1 cycle: four weeks of press,
+// deadlift, bench and squat days, each a main lift at three percentages.
+func wendler() []Session {
+	type set struct {
+		pct  float64
+		reps int
+	}
+	weeks := []struct {
+		name  string
+		sets  []set
+		amrap bool
+	}{
+		{"5s", []set{{0.65, 5}, {0.75, 5}, {0.85, 5}}, true},
+		{"3s", []set{{0.70, 3}, {0.80, 3}, {0.90, 3}}, true},
+		{"5/3/1", []set{{0.75, 5}, {0.85, 3}, {0.95, 1}}, true},
+		{"deload", []set{{0.40, 5}, {0.50, 5}, {0.60, 5}}, false},
+	}
+	days := []struct {
+		name, lift string
+		inc        float64
+	}{
+		{"Press", "press", 2.5},
+		{"Deadlift", "deadlift", 5},
+		{"Bench", "bench", 2.5},
+		{"Squat", "squat", 5},
+	}
+	var out []Session
+	for _, w := range weeks {
+		for _, d := range days {
+			s := Session{Name: d.name + " (" + w.name + ")"}
+			for i, st := range w.sets {
+				s.Slots = append(s.Slots, Slot{
+					Lift: d.lift, Sets: 1, Reps: st.reps, Percent: st.pct, IncrementKg: d.inc,
+					AMRAP: w.amrap && i == len(w.sets)-1,
+				})
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Find returns the built-in template with id, or nil.
+func Find(id string) *Template {
+	for i := range Builtin {
+		if Builtin[i].ID == id {
+			return &Builtin[i]
+		}
+	}
+	return nil
+}
+
+// TrainingMaxes returns the lift keys the template needs a training max for,
+// sorted.
+func (t Template) TrainingMaxes() []string {
+	return t.lifts(func(s Slot) bool { return s.Percent > 0 })
+}
+
+// Slugs returns the catalog slugs of every lift the template uses, sorted.
+func (t Template) Slugs() []string {
+	lifts := t.lifts(func(Slot) bool { return true })
+	out := make([]string, len(lifts))
+	for i, l := range lifts {
+		out[i] = Lifts[l]
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (t Template) lifts(keep func(Slot) bool) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, s := range t.Sessions {
+		for _, sl := range s.Slots {
+			if keep(sl) && !seen[sl.Lift] {
+				seen[sl.Lift] = true
+				out = append(out, sl.Lift)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Build expands weeks of the template into a program. maxes holds a training
+// max in kg for every lift in TrainingMaxes, and catalog maps the template's
+// slugs to catalog ids; a slug missing from it is an error.
+func (t Template) Build(name string, weeks int, maxes map[string]float64, catalog map[string]string) (store.ProgramInput, error) {
+	if weeks <= 0 {
+		weeks = t.Weeks
+	}
+	if name == "" {
+		name = t.Name
+	}
+	desc := t.Description
+	in := store.ProgramInput{Name: name, Description: &desc}
+	trained := make(map[string]int)
+	n := 0
+	for w := 1; w <= weeks; w++ {
+		week := store.ProgramWeekInput{WeekNumber: w}
+		for _, dayNumber := range t.Days {
+			sess := t.Sessions[n%len(t.Sessions)]
+			cycle := n / len(t.Sessions)
+			n++
+			day := store.ProgramDayInput{DayNumber: dayNumber, Name: &sess.Name}
+			for _, sl := range sess.Slots {
+				slug := Lifts[sl.Lift]
+				catalogID, ok := catalog[slug]
+				if !ok {
+					return in, fmt.Errorf("catalog has no %q exercise", slug)
+				}
+				ex := store.ProgramExerciseInput{CatalogID: catalogID, TargetSets: sl.Sets, TargetReps: sl.Reps, AMRAP: sl.AMRAP}
+				if sl.Percent > 0 {
+					var kg float64
+					if t.PerCycle {
+						kg = (maxes[sl.Lift] + float64(cycle)*sl.IncrementKg) * sl.Percent
+					} else {
+						key := fmt.Sprintf("%s %dx%d", sl.Lift, sl.Sets, sl.Reps)
+						kg = maxes[sl.Lift]*sl.Percent + float64(trained[key])*sl.IncrementKg
+						trained[key]++
+					}
+					kg = progression.RoundToIncrement(kg, roundKg)
+					ex.TargetWeightKg = &kg
+				}
+				day.Exercises = append(day.Exercises, ex)
+			}
+			week.Days = append(week.Days, day)
+		}
+		in.Weeks = append(in.Weeks, week)
+	}
+	return in, nil
+}
@@ -0,0 +1,97 @@
+package programs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func catalogFor(t Template) map[string]string {
+	out := make(map[string]string)
+	for _, slug := range t.Slugs() {
+		out[slug] = "id-" + slug
+	}
+	return out
+}
+
+func TestBuiltinBuild(t *testing.T) {
+	for _, tmpl := range Builtin {
+		maxes := make(map[string]float64)
+		for _, lift := range tmpl.TrainingMaxes() {
+			maxes[lift] = 100
+		}
+		in, err := tmpl.Build("", 0, maxes, catalogFor(tmpl))
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.ID, err)
+		}
+		if err := in.Validate(); err != nil {
+			t.Fatalf("%s: invalid program: %v", tmpl.ID, err)
+		}
+		if len(in.Weeks) != tmpl.Weeks || len(in.Weeks[0].Days) != len(tmpl.Days) {
+			t.Fatalf("%s: got %d weeks of %d days", tmpl.ID, len(in.Weeks), len(in.Weeks[0].Days))
+		}
+	}
+}
+
+func TestBuild531(t *testing.T) {
+	tmpl := Find("531")
+	if got := tmpl.TrainingMaxes(); !reflect.DeepEqual(got, []string{"bench", "deadlift", "press", "squat"}) {
+		t.Fatalf("training maxes %v", got)
+	}
+	maxes := map[string]float64{"press": 60, "deadlift": 180, "bench": 100, "squat": 150}
+	in, err := tmpl.Build("", 8, maxes, catalogFor(*tmpl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Week 1 squat: 65/75/85% of 150, rounded down to 2.5 kg.
+	squat := in.Weeks[0].Days[3]
+	var got []float64
+	for _, ex := range squat.Exercises {
+		got = append(got, *ex.TargetWeightKg)
+	}
+	if want := []float64{97.5, 112.5, 127.5}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("week 1 squat %v, want %v", got, want)
+	}
+	if !squat.Exercises[2].AMRAP || squat.Exercises[1].AMRAP {
+		t.Fatal("only the top set should be AMRAP")
+	}
+	// Week 5 starts cycle 2 with the squat max up 5 kg: 85% of 155.
+	if kg := *in.Weeks[4].Days[3].Exercises[2].TargetWeightKg; kg != 130 {
+		t.Fatalf("cycle 2 squat top set %v, want 130", kg)
+	}
+}
+
+func TestBuildLinear(t *testing.T) {
+	tmpl := Find("stronglifts-5x5")
+	maxes := map[string]float64{"squat": 100, "bench": 80, "row": 70, "press": 50, "deadlift": 120}
+	in, err := tmpl.Build("My 5x5", 2, maxes, catalogFor(*tmpl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Name != "My 5x5" {
+		t.Fatalf("name %q", in.Name)
+	}
+	// Squat is trained every session: 80, 82.5, 85, ...
+	var squats []float64
+	for _, w := range in.Weeks {
+		for _, d := range w.Days {
+			squats = append(squats, *d.Exercises[0].TargetWeightKg)
+		}
+	}
+	if want := []float64{80, 82.5, 85, 87.5, 90, 92.5}; !reflect.DeepEqual(squats, want) {
+		t.Fatalf("squats %v, want %v", squats, want)
+	}
+	// Deadlift is trained on B days and climbs 5 kg each time: 96, 101, 106.
+	if kg := *in.Weeks[1].Days[2].Exercises[2].TargetWeightKg; kg != 105 {
+		t.Fatalf("third deadlift %v, want 105", kg)
+	}
+}
+
+func TestBuildMissingCatalog(t *testing.T) {
+	tmpl := Find("gzclp")
+	if _, err := tmpl.Build("", 1, map[string]float64{}, map[string]string{}); err == nil {
+		t.Fatal("expected an error for a missing catalog exercise")
+	}
+	if Find("nope") != nil {
+		t.Fatal("unknown template found")
+	}
+}
@@ -0,0 +1,53 @@
+package progression
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Formula selects an estimated one-rep-max equation.
+type Formula string
+
+const (
+	FormulaEpley    Formula = "epley"
+	FormulaBrzycki  Formula = "brzycki"
+	FormulaLombardi Formula = "lombardi"
+)
+
+// ParseFormula maps a query value to a Formula, defaulting to Epley.
+func ParseFormula(s string) (Formula, error) {
+	switch f := Formula(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormulaEpley, nil
+	case FormulaEpley, FormulaBrzycki, FormulaLombardi:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown formula %q", s)
+	}
+}
+
+// EstimateOneRepMax returns the estimated 1RM for weight x reps. It returns 0
+// when the inputs are outside the formula's usable range.
+func EstimateOneRepMax(f Formula, weightKg float64, reps int) float64 {
+	if weightKg <= 0 || reps <= 0 {
+		return 0
+	}
+	if reps == 1 {
+		return weightKg
+	}
+	var v float64
+	switch f {
+	case FormulaBrzycki:
+		// Undefined at 37 reps and meaningless well before that.
+		if reps >= 37 {
+			return 0
+		}
+		v = weightKg * 36 / float64(37-reps)
+	case FormulaLombardi:
+		v = weightKg * math.Pow(float64(reps), 0.10)
+	default:
+		v = weightKg * (1 + float64(reps)/30)
+	}
+	return math.Round(v*100) / 100
+}
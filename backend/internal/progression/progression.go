@@ -0,0 +1,224 @@
+// Package progression turns a lifter's recent history for one exercise into a
+// suggestion for the next session.
+package progression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+type Rule string
+
+const (
+	// RuleLinear adds weight every session the target reps are hit for all
+	// working sets, and deloads after repeated failures.
+	RuleLinear Rule = "linear"
+	// RuleDouble works up through a rep range at a fixed weight, then adds
+	// weight and drops back to the bottom of the range.
+	RuleDouble Rule = "double"
+)
+
+var ErrNoHistory = errors.New("no working sets in history")
+
+type Config struct {
+	Rule        Rule
+	IncrementKg float64
+	// Linear progression
+	TargetReps          int
+	DeloadAfterFailures int
+	DeloadPct           float64
+	// Double progression
+	MinReps int
+	MaxReps int
+	// BaseKg is the lightest load, such as the empty bar; suggestions never go
+	// below it. StepKg is the smallest jump the lifter can load (micro
+	// plates); when set, suggestions are BaseKg plus whole steps rather than
+	// multiples of IncrementKg.
+	BaseKg float64
+	StepKg float64
+}
+
+// DefaultConfig returns sensible defaults for rule.
+func DefaultConfig(rule Rule) Config {
+	return Config{
+		Rule:                rule,
+		IncrementKg:         2.5,
+		TargetReps:          5,
+		DeloadAfterFailures: 3,
+		DeloadPct:           0.1,
+		MinReps:             8,
+		MaxReps:             12,
+	}
+}
+
+func (c Config) Validate() error {
+	switch c.Rule {
+	case RuleLinear:
+		if c.TargetReps <= 0 {
+			return errors.New("targetReps must be > 0")
+		}
+		if c.DeloadPct < 0 || c.DeloadPct >= 1 {
+			return errors.New("deloadPct must be in [0, 1)")
+		}
+	case RuleDouble:
+		if c.MinReps <= 0 || c.MaxReps < c.MinReps {
+			return errors.New("rep range must satisfy 0 < minReps <= maxReps")
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", c.Rule)
+	}
+	if c.IncrementKg <= 0 {
+		return errors.New("incrementKg must be > 0")
+	}
+	if c.BaseKg < 0 || c.StepKg < 0 {
+		return errors.New("baseKg and stepKg must be >= 0")
+	}
+	return nil
+}
+
+// load rounds weight down to something the lifter can load.
+func (c Config) load(weight float64) float64 {
+	if c.StepKg > 0 {
+		return Loadable(weight, c.BaseKg, c.StepKg)
+	}
+	return math.Max(RoundToIncrement(weight, c.IncrementKg), c.BaseKg)
+}
+
+// addWeight returns top plus IncrementKg, rounded to a loadable weight but
+// always at least one step heavier than top.
+func (c Config) addWeight(top float64) float64 {
+	w := c.load(top + c.IncrementKg)
+	if w > top {
+		return w
+	}
+	step := c.StepKg
+	if step <= 0 {
+		step = c.IncrementKg
+	}
+	return c.load(top + step)
+}
+
+type Set struct {
+	Reps     int
+	WeightKg float64
+}
+
+// Session is one day's working sets (warmups excluded).
+type Session struct {
+	Date time.Time
+	Sets []Set
+}
+
+type Suggestion struct {
+	Rule     Rule    `json:"rule"`
+	WeightKg float64 `json:"weightKg"`
+	Reps     int     `json:"reps"`
+	Sets     int     `json:"sets"`
+	Reason   string  `json:"reason"`
+}
+
+// Suggest computes the next session's target. history must be ordered most
+// recent first; sessions without sets are ignored.
+func Suggest(history []Session, cfg Config) (Suggestion, error) {
+	if err := cfg.Validate(); err != nil {
+		return Suggestion{}, err
+	}
+	sessions := make([]Session, 0, len(history))
+	for _, s := range history {
+		if len(s.Sets) > 0 {
+			sessions = append(sessions, s)
+		}
+	}
+	if len(sessions) == 0 {
+		return Suggestion{}, ErrNoHistory
+	}
+	switch cfg.Rule {
+	case RuleDouble:
+		return suggestDouble(sessions, cfg), nil
+	default:
+		return suggestLinear(sessions, cfg), nil
+	}
+}
+
+func suggestLinear(sessions []Session, cfg Config) Suggestion {
+	last := sessions[0]
+	top, topSets := topWeightSets(last)
+	out := Suggestion{Rule: RuleLinear, Reps: cfg.TargetReps, Sets: len(topSets)}
+	if hitAll(topSets, cfg.TargetReps) {
+		out.WeightKg = cfg.addWeight(top)
+		out.Reason = fmt.Sprintf("hit %d reps on all sets at %.2fkg; add %.2fkg", cfg.TargetReps, top, out.WeightKg-top)
+		return out
+	}
+	failures := 0
+	for _, s := range sessions {
+		w, sets := topWeightSets(s)
+		if w != top || hitAll(sets, cfg.TargetReps) {
+			break
+		}
+		failures++
+	}
+	if cfg.DeloadAfterFailures > 0 && failures >= cfg.DeloadAfterFailures {
+		out.WeightKg = cfg.load(top * (1 - cfg.DeloadPct))
+		out.Reason = fmt.Sprintf("missed target at %.2fkg for %d sessions; deload %.0f%%", top, failures, cfg.DeloadPct*100)
+		return out
+	}
+	out.WeightKg = top
+	out.Reason = fmt.Sprintf("missed target at %.2fkg; repeat weight", top)
+	return out
+}
+
+func suggestDouble(sessions []Session, cfg Config) Suggestion {
+	top, topSets := topWeightSets(sessions[0])
+	out := Suggestion{Rule: RuleDouble, Sets: len(topSets)}
+	if hitAll(topSets, cfg.MaxReps) {
+		out.WeightKg = cfg.addWeight(top)
+		out.Reps = cfg.MinReps
+		out.Reason = fmt.Sprintf("reached %d reps on all sets; add %.2fkg and restart at %d", cfg.MaxReps, out.WeightKg-top, cfg.MinReps)
+		return out
+	}
+	lowest := topSets[0].Reps
+	for _, s := range topSets[1:] {
+		lowest = min(lowest, s.Reps)
+	}
+	out.WeightKg = top
+	out.Reps = max(cfg.MinReps, min(lowest+1, cfg.MaxReps))
+	out.Reason = fmt.Sprintf("work up the %d-%d range at %.2fkg", cfg.MinReps, cfg.MaxReps, top)
+	return out
+}
+
+// topWeightSets returns the heaviest weight in the session and the sets
+// performed at it.
+func topWeightSets(s Session) (float64, []Set) {
+	top := s.Sets[0].WeightKg
+	for _, set := range s.Sets[1:] {
+		top = math.Max(top, set.WeightKg)
+	}
+	var out []Set
+	for _, set := range s.Sets {
+		if set.WeightKg == top {
+			out = append(out, set)
+		}
+	}
+	return top, out
+}
+
+func hitAll(sets []Set, reps int) bool {
+	for _, s := range sets {
+		if s.Reps < reps {
+			return false
+		}
+	}
+	return len(sets) > 0
+}
+
+// RoundToIncrement rounds weight down to the nearest multiple of increment.
+func RoundToIncrement(weight, increment float64) float64 {
+	if increment <= 0 {
+		return weight
+	}
+	// Small epsilon keeps 102.49999 from flooring to 100.
+	steps := math.Floor(weight/increment + 1e-9)
+	return math.Round(steps*increment*100) / 100
+}
@@ -0,0 +1,190 @@
+package progression
+
+import (
+	"errors"
+	"testing"
+)
+
+func sess(sets ...Set) Session { return Session{Sets: sets} }
+
+func TestSuggestLinear(t *testing.T) {
+	cfg := DefaultConfig(RuleLinear)
+	cases := []struct {
+		name       string
+		history    []Session
+		wantWeight float64
+		wantReps   int
+	}{
+		{
+			name:       "all sets hit adds increment",
+			history:    []Session{sess(Set{5, 100}, Set{5, 100}, Set{5, 100})},
+			wantWeight: 102.5,
+			wantReps:   5,
+		},
+		{
+			name:       "missed set repeats weight",
+			history:    []Session{sess(Set{5, 100}, Set{4, 100}), sess(Set{5, 97.5}, Set{5, 97.5})},
+			wantWeight: 100,
+			wantReps:   5,
+		},
+		{
+			name: "three failures deloads",
+			history: []Session{
+				sess(Set{4, 100}), sess(Set{3, 100}), sess(Set{4, 100}),
+			},
+			wantWeight: 90,
+			wantReps:   5,
+		},
+		{
+			name:       "lighter back-off sets are ignored",
+			history:    []Session{sess(Set{5, 100}, Set{3, 80})},
+			wantWeight: 102.5,
+			wantReps:   5,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Suggest(tc.history, cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.WeightKg != tc.wantWeight || got.Reps != tc.wantReps {
+				t.Fatalf("got %.2fkg x %d, want %.2fkg x %d (%s)", got.WeightKg, got.Reps, tc.wantWeight, tc.wantReps, got.Reason)
+			}
+		})
+	}
+}
+
+func TestSuggestDouble(t *testing.T) {
+	cfg := DefaultConfig(RuleDouble)
+	got, err := Suggest([]Session{sess(Set{12, 20}, Set{12, 20}, Set{12, 20})}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.WeightKg != 22.5 || got.Reps != 8 {
+		t.Fatalf("top of range: got %.2fkg x %d, want 22.50kg x 8", got.WeightKg, got.Reps)
+	}
+
+	got, err = Suggest([]Session{sess(Set{10, 20}, Set{9, 20}, Set{8, 20})}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.WeightKg != 20 || got.Reps != 9 {
+		t.Fatalf("mid range: got %.2fkg x %d, want 20.00kg x 9", got.WeightKg, got.Reps)
+	}
+}
+
+func TestSuggestLoadable(t *testing.T) {
+	cfg := DefaultConfig(RuleLinear)
+	cfg.BaseKg, cfg.StepKg = 20, 1
+	got, _ := Suggest([]Session{sess(Set{5, 61}, Set{5, 61})}, cfg)
+	if got.WeightKg != 63 {
+		t.Fatalf("micro plates: got %.2fkg, want 63.00kg", got.WeightKg)
+	}
+	// A step bigger than the increment still moves up one step.
+	cfg.IncrementKg, cfg.StepKg = 1, 2.5
+	got, _ = Suggest([]Session{sess(Set{5, 60})}, cfg)
+	if got.WeightKg != 62.5 {
+		t.Fatalf("coarse step: got %.2fkg, want 62.50kg", got.WeightKg)
+	}
+	// Deloads never go below the empty bar.
+	got, _ = Suggest([]Session{sess(Set{3, 21}), sess(Set{3, 21}), sess(Set{3, 21})}, cfg)
+	if got.WeightKg != 20 {
+		t.Fatalf("deload: got %.2fkg, want the 20.00kg bar", got.WeightKg)
+	}
+}
+
+func TestSuggestNoHistory(t *testing.T) {
+	_, err := Suggest([]Session{{}}, DefaultConfig(RuleLinear))
+	if !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected ErrNoHistory, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig(RuleDouble)
+	cfg.MinReps, cfg.MaxReps = 12, 8
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected invalid rep range error")
+	}
+	if err := DefaultConfig("wave").Validate(); err == nil {
+		t.Fatal("expected unknown rule error")
+	}
+}
+
+func TestRoundToIncrement(t *testing.T) {
+	cases := map[float64]float64{102.5: 102.5, 101.9: 100, 90.0: 90, 91.24: 90}
+	for in, want := range cases {
+		if got := RoundToIncrement(in, 2.5); got != want {
+			t.Errorf("RoundToIncrement(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestEstimateOneRepMax(t *testing.T) {
+	cases := []struct {
+		f    Formula
+		w    float64
+		reps int
+		want float64
+	}{
+		{FormulaEpley, 100, 5, 116.67},
+		{FormulaBrzycki, 100, 5, 112.5},
+		{FormulaLombardi, 100, 5, 117.46},
+		{FormulaEpley, 100, 1, 100},
+		{FormulaBrzycki, 100, 40, 0},
+	}
+	for _, tc := range cases {
+		if got := EstimateOneRepMax(tc.f, tc.w, tc.reps); got != tc.want {
+			t.Errorf("%s(%v x %d) = %v, want %v", tc.f, tc.w, tc.reps, got, tc.want)
+		}
+	}
+}
+
+func TestWarmups(t *testing.T) {
+	cases := []struct {
+		name    string
+		working float64
+		base    float64
+		step    float64
+		want    []WarmupSet
+	}{
+		{
+			name:    "barbell ramp",
+			working: 100, base: 20, step: 2.5,
+			want: []WarmupSet{{20, 10, 20}, {40, 8, 40}, {60, 5, 60}, {80, 3, 80}},
+		},
+		{
+			name:    "rounds down to loadable weight",
+			working: 87.5, base: 20, step: 5,
+			want: []WarmupSet{{23, 10, 20}, {40, 8, 35}, {57, 5, 50}, {80, 3, 70}},
+		},
+		{
+			name:    "light working weight collapses repeats",
+			working: 30, base: 20, step: 2.5,
+			want: []WarmupSet{{67, 8, 20}, {75, 3, 22.5}},
+		},
+		{
+			name:    "no base",
+			working: 50, base: 0, step: 2,
+			want: []WarmupSet{{40, 8, 20}, {60, 5, 30}, {80, 3, 40}},
+		},
+		{
+			name:    "working at base",
+			working: 20, base: 20, step: 2.5,
+			want: []WarmupSet{},
+		},
+	}
+	for _, c := range cases {
+		got := Warmups(c.working, c.base, c.step)
+		if len(got) != len(c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: set %d = %+v, want %+v", c.name, i, got[i], c.want[i])
+			}
+		}
+	}
+}
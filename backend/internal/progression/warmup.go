@@ -0,0 +1,65 @@
+package progression
+
+import "math"
+
+// warmupScheme ramps toward the working weight; reps drop as load rises.
+var warmupScheme = []struct {
+	Pct  int
+	Reps int
+}{
+	{40, 8},
+	{60, 5},
+	{80, 3},
+}
+
+// emptyBarReps is used for the first set at the base weight alone.
+const emptyBarReps = 10
+
+// WarmupSet's Percent is the rounded share of the working weight it loads.
+type WarmupSet struct {
+	Percent  int     `json:"percent"`
+	Reps     int     `json:"reps"`
+	WeightKg float64 `json:"weightKg"`
+}
+
+// Warmups builds a ramp up to workingKg. Loads are rounded down to what can be
+// loaded as baseKg (bar or machine start weight) plus whole steps of stepKg
+// (the smallest total jump, e.g. two of the smallest plates). When the base
+// is below the first ramp weight, a set with the base alone comes first.
+// Sets that would repeat the previous load or reach the working weight are
+// dropped.
+func Warmups(workingKg, baseKg, stepKg float64) []WarmupSet {
+	out := []WarmupSet{}
+	if workingKg <= 0 || workingKg <= baseKg {
+		return out
+	}
+	load := func(target float64) float64 { return Loadable(target, baseKg, stepKg) }
+	pct := func(w float64) int { return int(math.Round(w / workingKg * 100)) }
+	last := -1.0
+	if baseKg > 0 && baseKg < load(workingKg*float64(warmupScheme[0].Pct)/100) {
+		out = append(out, WarmupSet{Percent: pct(baseKg), Reps: emptyBarReps, WeightKg: baseKg})
+		last = baseKg
+	}
+	for _, step := range warmupScheme {
+		w := load(workingKg * float64(step.Pct) / 100)
+		if w <= last || w >= workingKg {
+			continue
+		}
+		out = append(out, WarmupSet{Percent: pct(w), Reps: step.Reps, WeightKg: w})
+		last = w
+	}
+	return out
+}
+
+// Loadable rounds target down to baseKg plus whole steps of stepKg, and
+// never below baseKg. A zero stepKg only rounds to 2 decimals.
+func Loadable(target, baseKg, stepKg float64) float64 {
+	if target <= baseKg {
+		return baseKg
+	}
+	if stepKg <= 0 {
+		return math.Round(target*100) / 100
+	}
+	steps := math.Floor((target-baseKg)/stepKg + 1e-9)
+	return math.Round((baseKg+steps*stepKg)*100) / 100
+}
@@ -0,0 +1,206 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event types pushed to clients.
+const (
+	EventRestTimerStarted = "restTimer.started"
+	EventRestTimerDone    = "restTimer.done"
+	EventRestTimerCancel  = "restTimer.cancelled"
+	EventSaveEpoch        = "save.epoch"
+	EventPersonalRecord   = "pr"
+	EventComment          = "comment"
+	EventAchievement      = "achievement"
+)
+
+type Event struct {
+	Type string    `json:"type"`
+	Data any       `json:"data,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// RestTimer is a server-tracked countdown shared between a user's devices.
+type RestTimer struct {
+	ID              string    `json:"id"`
+	ExerciseID      string    `json:"exerciseId,omitempty"`
+	DurationSeconds int       `json:"durationSeconds"`
+	StartedAt       time.Time `json:"startedAt"`
+	EndsAt          time.Time `json:"endsAt"`
+}
+
+const clientBuffer = 32
+
+type Client struct {
+	userID string
+	send   chan []byte
+}
+
+// Hub fans events out to every connection belonging to the same user.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[string]map[*Client]struct{}
+	timers  map[string]map[string]*time.Timer
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[string]map[*Client]struct{}),
+		timers:  make(map[string]map[string]*time.Timer),
+	}
+}
+
+func (h *Hub) register(userID string) *Client {
+	c := &Client{userID: userID, send: make(chan []byte, clientBuffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	set, ok := h.clients[userID]
+	if !ok {
+		set = make(map[*Client]struct{})
+		h.clients[userID] = set
+	}
+	set[c] = struct{}{}
+	return c
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	set := h.clients[c.userID]
+	if _, ok := set[c]; !ok {
+		return
+	}
+	delete(set, c)
+	close(c.send)
+	if len(set) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+// Publish delivers ev to every connected client of userID. Slow clients whose
+// buffers are full are dropped rather than blocking the publisher.
+func (h *Hub) Publish(userID string, ev Event) {
+	if h == nil || userID == "" {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now().UTC()
+	}
+	msg, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("realtime marshal error: %v", err)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[userID] {
+		select {
+		case c.send <- msg:
+		default:
+			delete(h.clients[userID], c)
+			close(c.send)
+		}
+	}
+}
+
+// StartRestTimer schedules a completion event for the timer. Starting a timer
+// with an ID that is already running replaces it.
+func (h *Hub) StartRestTimer(userID string, t RestTimer) {
+	if t.StartedAt.IsZero() {
+		t.StartedAt = time.Now().UTC()
+	}
+	t.EndsAt = t.StartedAt.Add(time.Duration(t.DurationSeconds) * time.Second)
+
+	h.mu.Lock()
+	timers, ok := h.timers[userID]
+	if !ok {
+		timers = make(map[string]*time.Timer)
+		h.timers[userID] = timers
+	}
+	if prev, ok := timers[t.ID]; ok {
+		prev.Stop()
+	}
+	timers[t.ID] = time.AfterFunc(time.Until(t.EndsAt), func() {
+		h.mu.Lock()
+		delete(h.timers[userID], t.ID)
+		if len(h.timers[userID]) == 0 {
+			delete(h.timers, userID)
+		}
+		h.mu.Unlock()
+		h.Publish(userID, Event{Type: EventRestTimerDone, Data: t})
+	})
+	h.mu.Unlock()
+
+	h.Publish(userID, Event{Type: EventRestTimerStarted, Data: t})
+}
+
+// CancelRestTimer stops a running timer; it reports whether one was found.
+func (h *Hub) CancelRestTimer(userID, timerID string) bool {
+	h.mu.Lock()
+	t, ok := h.timers[userID][timerID]
+	if ok {
+		t.Stop()
+		delete(h.timers[userID], timerID)
+		if len(h.timers[userID]) == 0 {
+			delete(h.timers, userID)
+		}
+	}
+	h.mu.Unlock()
+	if ok {
+		h.Publish(userID, Event{Type: EventRestTimerCancel, Data: map[string]string{"id": timerID}})
+	}
+	return ok
+}
+
+const (
+	writeTimeout = 10 * time.Second
+	pingInterval = 30 * time.Second
+)
+
+// Serve pumps hub events to conn until the client disconnects. Incoming
+// messages are handed to onMessage.
+func (h *Hub) Serve(userID string, conn *Conn, onMessage func([]byte)) {
+	c := h.register(userID)
+	defer conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if onMessage != nil {
+				onMessage(msg)
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-c.send:
+			if !ok {
+				return
+			}
+			if err := conn.WriteText(msg, writeTimeout); err != nil {
+				h.unregister(c)
+				return
+			}
+		case <-ticker.C:
+			if err := conn.Ping(writeTimeout); err != nil {
+				h.unregister(c)
+				return
+			}
+		case <-done:
+			h.unregister(c)
+			return
+		}
+	}
+}
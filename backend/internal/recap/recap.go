@@ -0,0 +1,124 @@
+// Package recap generates every user's "Year in Review" in the first week
+// of the new year through the jobs queue, so the recaps are cached before
+// most people ask for them.
+package recap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"exercise-tracker/internal/jobs"
+	"exercise-tracker/internal/store"
+)
+
+// Job kinds: JobYear fans a year out into one JobGenerate per user.
+const (
+	JobYear     = "recap.year"
+	JobGenerate = "recap.generate"
+)
+
+const (
+	scanEvery = time.Hour
+	// grace waits a day into the new year, until it is over in every time
+	// zone.
+	grace = 24 * time.Hour
+	// window is how long after the new year starts recaps are generated in
+	// bulk; later ones are generated on request.
+	window      = 7 * 24 * time.Hour
+	fanOutBatch = 500
+)
+
+type yearPayload struct {
+	Year int `json:"year"`
+}
+
+type generatePayload struct {
+	UserID string `json:"userId"`
+	Year   int    `json:"year"`
+}
+
+type Scheduler struct {
+	Recaps *store.Recaps
+	Queue  *jobs.Queue
+}
+
+// Register adds the job handlers to the queue. Call before the queue runs.
+func (s *Scheduler) Register() {
+	s.Queue.Handle(JobYear, s.fanOut)
+	s.Queue.Handle(JobGenerate, s.generate)
+}
+
+// Run enqueues the previous year's recaps once an hour during the window
+// until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scanEvery)
+	defer ticker.Stop()
+	for {
+		if err := s.enqueueYear(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+			log.Printf("recap schedule error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Due reports the year whose recaps are due at now, if any.
+func Due(now time.Time) (int, bool) {
+	start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	since := now.Sub(start)
+	return now.Year() - 1, since >= grace && since < window
+}
+
+func (s *Scheduler) enqueueYear(ctx context.Context, now time.Time) error {
+	year, ok := Due(now)
+	if !ok {
+		return nil
+	}
+	// Finished jobs are pruned after a week, so the key holds for the
+	// whole window.
+	_, err := s.Queue.Enqueue(ctx, JobYear, yearPayload{Year: year}, jobs.EnqueueOptions{
+		DedupeKey:   fmt.Sprintf("recap:%d", year),
+		MaxAttempts: 5,
+	})
+	return err
+}
+
+func (s *Scheduler) fanOut(ctx context.Context, job jobs.Job) error {
+	var p yearPayload
+	if err := job.Decode(&p); err != nil {
+		return fmt.Errorf("recap payload: %w", err)
+	}
+	after := ""
+	for {
+		users, err := s.Recaps.Pending(ctx, p.Year, after, fanOutBatch)
+		if err != nil {
+			return err
+		}
+		for _, uid := range users {
+			if _, err := s.Queue.Enqueue(ctx, JobGenerate, generatePayload{UserID: uid, Year: p.Year}, jobs.EnqueueOptions{
+				DedupeKey:   fmt.Sprintf("recap:%d:%s", p.Year, uid),
+				MaxAttempts: 3,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(users) < fanOutBatch {
+			return nil
+		}
+		after = users[len(users)-1]
+	}
+}
+
+func (s *Scheduler) generate(ctx context.Context, job jobs.Job) error {
+	var p generatePayload
+	if err := job.Decode(&p); err != nil {
+		return fmt.Errorf("recap payload: %w", err)
+	}
+	_, err := s.Recaps.Generate(ctx, p.UserID, p.Year)
+	return err
+}
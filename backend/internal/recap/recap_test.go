@@ -0,0 +1,28 @@
+package recap_test
+
+import (
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/recap"
+)
+
+func TestDue(t *testing.T) {
+	cases := []struct {
+		now  time.Time
+		year int
+		due  bool
+	}{
+		{time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), 2025, false},
+		{time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), 2025, true},
+		{time.Date(2026, 1, 7, 23, 0, 0, 0, time.UTC), 2025, true},
+		{time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), 2025, false},
+		{time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC), 2025, false},
+	}
+	for _, c := range cases {
+		year, due := recap.Due(c.now)
+		if year != c.year || due != c.due {
+			t.Errorf("Due(%s) = %d, %v; want %d, %v", c.now, year, due, c.year, c.due)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package reminders schedules workout reminders and delivers them through
+// the jobs queue.
+package reminders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"exercise-tracker/internal/jobs"
+	"exercise-tracker/internal/notify"
+	"exercise-tracker/internal/store"
+)
+
+// JobSend is the job kind that delivers one reminder occurrence.
+const JobSend = "reminder.send"
+
+const (
+	scanEvery = time.Minute
+	// lateBy drops occurrences the scheduler missed by more than this (e.g.
+	// after downtime) instead of sending a burst of stale reminders.
+	lateBy = 2 * time.Hour
+)
+
+// Next returns the first occurrence strictly after after: the given local
+// time on one of days (ISO weekdays, 1 = Monday) in loc. timeOfDay is HH:MM.
+// On days where that wall time doesn't exist (DST gaps) time.Date's
+// normalization moves it forward.
+func Next(days []int, timeOfDay string, loc *time.Location, after time.Time) (time.Time, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(timeOfDay, "%d:%d", &hour, &minute); err != nil {
+		return time.Time{}, fmt.Errorf("time of day: %w", err)
+	}
+	want := map[int]bool{}
+	for _, d := range days {
+		want[d] = true
+	}
+	local := after.In(loc)
+	for i := 0; i <= 7; i++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+i, hour, minute, 0, 0, loc)
+		if want[isoWeekday(day)] && day.After(after) {
+			return day, nil
+		}
+	}
+	return time.Time{}, errors.New("no weekday selected")
+}
+
+func isoWeekday(t time.Time) int {
+	if wd := int(t.Weekday()); wd != 0 {
+		return wd
+	}
+	return 7
+}
+
+// NextRun is Next for a stored reminder; nil when the reminder is disabled.
+func NextRun(r store.ReminderParams, after time.Time) (*time.Time, error) {
+	if !r.Enabled {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	next, err := Next(r.DaysOfWeek, r.TimeOfDay, loc, after)
+	if err != nil {
+		return nil, err
+	}
+	next = next.UTC()
+	return &next, nil
+}
+
+type sendPayload struct {
+	ReminderID string    `json:"reminderId"`
+	UserID     string    `json:"userId"`
+	Channels   []string  `json:"channels"`
+	At         time.Time `json:"at"`
+}
+
+// Scheduler turns due reminders into send jobs. Mail or Push may be nil when
+// that channel isn't configured; those sends are skipped.
+type Scheduler struct {
+	Reminders *store.Reminders
+	Users     *store.Users
+	Queue     *jobs.Queue
+	Mail      *notify.Mailer
+	Push      *notify.WebPush
+}
+
+// Register adds the send handler to the queue. Call before the queue runs.
+func (s *Scheduler) Register() { s.Queue.Handle(JobSend, s.send) }
+
+// Run checks for due reminders every minute until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scanEvery)
+	defer ticker.Stop()
+	for {
+		if err := s.enqueueDue(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+			log.Printf("reminders schedule error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) enqueueDue(ctx context.Context, now time.Time) error {
+	due, err := s.Reminders.Due(ctx, now, 500)
+	if err != nil {
+		return err
+	}
+	for _, r := range due {
+		at := *r.NextRunAt
+		if now.Sub(at) <= lateBy {
+			// The dedupe key makes a re-scan after a failed Advance harmless.
+			_, err := s.Queue.Enqueue(ctx, JobSend, sendPayload{ReminderID: r.ID, UserID: r.UserID, Channels: r.Channels, At: at}, jobs.EnqueueOptions{
+				DedupeKey:   fmt.Sprintf("reminder:%s:%d", r.ID, at.Unix()),
+				MaxAttempts: 3,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		next, err := NextRun(store.ReminderParams{
+			DaysOfWeek: r.DaysOfWeek, TimeOfDay: r.TimeOfDay, Timezone: r.Timezone, Enabled: true,
+		}, now)
+		if err != nil {
+			log.Printf("reminders next run %s: %v", r.ID, err)
+			continue
+		}
+		if err := s.Reminders.Advance(ctx, r.ID, at, *next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	reminderSubject = "Time to train"
+	reminderBody    = "This is your FitLog workout reminder. Open the app to start today's session."
+)
+
+func (s *Scheduler) send(ctx context.Context, job jobs.Job) error {
+	var p sendPayload
+	if err := job.Decode(&p); err != nil {
+		return fmt.Errorf("reminder payload: %w", err)
+	}
+	// A retry goes out on every channel again; a duplicate beats a missed
+	// reminder.
+	var errs []error
+	for _, ch := range p.Channels {
+		var err error
+		switch ch {
+		case store.ReminderEmail:
+			err = s.sendEmail(ctx, p.UserID)
+		case store.ReminderPush:
+			err = s.sendPush(ctx, p.UserID)
+		}
+		if errors.Is(err, notify.ErrNotConfigured) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return s.Reminders.MarkSent(ctx, p.ReminderID, time.Now().UTC())
+}
+
+func (s *Scheduler) sendEmail(ctx context.Context, userID string) error {
+	if s.Mail == nil {
+		return notify.ErrNotConfigured
+	}
+	u, err := s.Users.ByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.DeletedAt != nil || u.IsGuest {
+		return nil
+	}
+	return s.Mail.Send(ctx, u.Email, reminderSubject, reminderBody)
+}
+
+func (s *Scheduler) sendPush(ctx context.Context, userID string) error {
+	if s.Push == nil {
+		return notify.ErrNotConfigured
+	}
+	subs, err := s.Reminders.PushSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]string{"title": reminderSubject, "body": reminderBody, "tag": "workout-reminder"})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, sub := range subs {
+		err := s.Push.Send(ctx, notify.PushSubscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, payload, time.Hour)
+		if errors.Is(err, notify.ErrSubscriptionGone) {
+			if err := s.Reminders.DropPushEndpoint(ctx, sub.Endpoint); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
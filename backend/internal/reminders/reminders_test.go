@@ -0,0 +1,41 @@
+package reminders_test
+
+import (
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/reminders"
+)
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	// Wednesday 2024-03-27 10:00 Berlin (CET, before the DST switch).
+	after := time.Date(2024, 3, 27, 10, 0, 0, 0, berlin)
+	cases := []struct {
+		name string
+		days []int
+		tod  string
+		want time.Time
+	}{
+		{"later today", []int{3}, "18:30", time.Date(2024, 3, 27, 18, 30, 0, 0, berlin)},
+		{"earlier today rolls to next week", []int{3}, "07:00", time.Date(2024, 4, 3, 7, 0, 0, 0, berlin)},
+		{"next selected day", []int{1, 5}, "07:00", time.Date(2024, 3, 29, 7, 0, 0, 0, berlin)},
+		{"across DST", []int{1}, "07:00", time.Date(2024, 4, 1, 7, 0, 0, 0, berlin)},
+		{"sunday is 7", []int{7}, "09:00", time.Date(2024, 3, 31, 9, 0, 0, 0, berlin)},
+	}
+	for _, c := range cases {
+		got, err := reminders.Next(c.days, c.tod, berlin, after)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !got.Equal(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+	if _, err := reminders.Next(nil, "07:00", berlin, after); err == nil {
+		t.Error("expected an error with no days")
+	}
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Achievements struct {
+	db *sqlx.DB
+}
+
+func NewAchievements(db *sqlx.DB) *Achievements { return &Achievements{db: db} }
+
+// AchievementProgress is what achievement rules are evaluated against, all
+// from working sets.
+type AchievementProgress struct {
+	Workouts         int     `db:"workouts"`
+	LongestStreak    int     `db:"longest_streak"`
+	LifetimeVolumeKg float64 `db:"lifetime_volume_kg"`
+	// TopSetKg is the heaviest working set per catalog slug asked for.
+	TopSetKg map[string]float64 `db:"-"`
+}
+
+// Progress reads the user's totals from the stat rollups, with the top sets
+// of the given catalog slugs.
+func (s *Achievements) Progress(ctx context.Context, userID string, slugs []string) (AchievementProgress, error) {
+	var p AchievementProgress
+	if err := conn(ctx, s.db).GetContext(ctx, &p, `
+		with days as (
+		  select workout_date, tonnage_kg from stats_daily where user_id = $1 and working_sets > 0
+		), runs as (
+		  select count(*) as length
+		  from (select workout_date - (row_number() over (order by workout_date))::int as run from days) r
+		  group by run
+		)
+		select (select count(*) from days) as workouts,
+		       coalesce((select max(length) from runs), 0) as longest_streak,
+		       coalesce((select sum(tonnage_kg) from days), 0)::float8 as lifetime_volume_kg
+	`, userID); err != nil {
+		return AchievementProgress{}, err
+	}
+	p.TopSetKg = map[string]float64{}
+	if len(slugs) == 0 {
+		return p, nil
+	}
+	var rows []struct {
+		Slug string  `db:"slug"`
+		Top  float64 `db:"top"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select ec.slug, max(x.top_weight_kg)::float8 as top
+		from stats_exercise_daily x
+		join exercise_catalog ec on ec.id = x.catalog_id
+		where x.user_id = $1 and ec.slug = any($2::text[]) and x.top_weight_kg is not null
+		group by ec.slug
+	`, userID, slugs); err != nil {
+		return AchievementProgress{}, err
+	}
+	for _, r := range rows {
+		p.TopSetKg[r.Slug] = r.Top
+	}
+	return p, nil
+}
+
+type UnlockedAchievement struct {
+	ID         string    `db:"achievement" json:"id"`
+	Value      float64   `db:"value" json:"value"`
+	UnlockedAt time.Time `db:"unlocked_at" json:"unlockedAt"`
+}
+
+// Unlocked returns the user's unlocked achievements, newest first.
+func (s *Achievements) Unlocked(ctx context.Context, userID string) ([]UnlockedAchievement, error) {
+	out := []UnlockedAchievement{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select achievement, value::float8 as value, unlocked_at
+		from user_achievements
+		where user_id = $1
+		order by unlocked_at desc, achievement
+	`, userID)
+	return out, err
+}
+
+// Unlock records an achievement and reports whether it is new. Once
+// unlocked it stays unlocked, even if the sets behind it are deleted.
+func (s *Achievements) Unlock(ctx context.Context, userID, id string, value float64) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		insert into user_achievements (user_id, achievement, value)
+		values ($1, $2, $3)
+		on conflict do nothing
+	`, userID, id, value)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// AchievementWebhook receives a signed POST for every unlock. The secret is
+// only shown when it is generated.
+type AchievementWebhook struct {
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Webhook returns the user's webhook, or nil, nil when none is set.
+func (s *Achievements) Webhook(ctx context.Context, userID string) (*AchievementWebhook, error) {
+	var h AchievementWebhook
+	err := conn(ctx, s.db).GetContext(ctx, &h, `select url, secret, created_at from achievement_webhooks where user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// SetWebhook points the user's webhook at url with a fresh signing secret.
+func (s *Achievements) SetWebhook(ctx context.Context, userID, url string) (*AchievementWebhook, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	var h AchievementWebhook
+	err := conn(ctx, s.db).GetContext(ctx, &h, `
+		insert into achievement_webhooks (user_id, url, secret)
+		values ($1, $2, $3)
+		on conflict (user_id) do update
+		set url = excluded.url, secret = excluded.secret, created_at = now()
+		returning url, secret, created_at
+	`, userID, url, "whsec_"+hex.EncodeToString(b))
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (s *Achievements) DeleteWebhook(ctx context.Context, userID string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from achievement_webhooks where user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,648 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/models"
+)
+
+// Analytics holds read-only reporting queries over a user's training data.
+// Weekly totals read the stats_daily and stats_exercise_daily rollups, which
+// triggers on sets keep current (see migration 035).
+type Analytics struct {
+	db *sqlx.DB
+}
+
+func NewAnalytics(db *sqlx.DB) *Analytics { return &Analytics{db: db} }
+
+// Planned-reps results of a logged set.
+const (
+	RepsBelow = "below" // short of the planned reps, or the bottom of the range
+	RepsMet   = "met"
+	RepsAbove = "above" // past the top of the range; never for an AMRAP set
+)
+
+type LoggedSet struct {
+	Reps     int     `db:"reps" json:"reps"`
+	WeightKg float64 `db:"weight_kg" json:"weightKg"`
+	// RepsResult compares Reps with the plan; empty for unplanned exercises.
+	RepsResult string `db:"-" json:"repsResult,omitempty"`
+}
+
+type ExerciseAdherence struct {
+	ExerciseID      string      `json:"exerciseId"`
+	Name            string      `json:"name"`
+	Planned         bool        `json:"planned"`
+	PlannedSets     *int        `json:"plannedSets,omitempty"`
+	PlannedReps     *int        `json:"plannedReps,omitempty"`
+	PlannedRepsMax  *int        `json:"plannedRepsMax,omitempty"`
+	PlannedAMRAP    bool        `json:"plannedAmrap"`
+	PlannedWeightKg *float64    `json:"plannedWeightKg,omitempty"`
+	LoggedSets      []LoggedSet `json:"loggedSets"`
+	CompletedSets   int         `json:"completedSets"`
+	CompletionPct   *float64    `json:"completionPct,omitempty"`
+	// AMRAPReps is what the AMRAP set (the last planned one) reached, nil
+	// until that many sets are logged.
+	AMRAPReps *int `json:"amrapReps,omitempty"`
+}
+
+type DayAdherence struct {
+	DayID         string              `json:"dayId"`
+	WorkoutDate   string              `json:"workoutDate"`
+	ProgramID     *string             `json:"programId,omitempty"`
+	Exercises     []ExerciseAdherence `json:"exercises"`
+	CompletionPct *float64            `json:"completionPct,omitempty"`
+}
+
+// DayAdherence compares each exercise's planned targets with the working sets
+// logged against it. A logged set counts toward completion when it reaches the
+// planned reps (the bottom of a range) and, if planned, weight. Unplanned
+// exercises are listed without a completion percentage and do not affect the
+// day total.
+func (a *Analytics) DayAdherence(ctx context.Context, userID, dayID string) (*DayAdherence, error) {
+	var day struct {
+		ID          string         `db:"id"`
+		WorkoutDate string         `db:"workout_date"`
+		ProgramID   sql.NullString `db:"program_id"`
+	}
+	if err := conn(ctx, a.db).QueryRowxContext(ctx, `
+		select id, to_char(workout_date, 'YYYY-MM-DD') as workout_date, program_id
+		from workout_days where id = $1 and user_id = $2`, dayID, userID).StructScan(&day); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var exercises []struct {
+		ID              string   `db:"id"`
+		Name            string   `db:"name"`
+		PlannedSets     *int     `db:"planned_sets"`
+		PlannedReps     *int     `db:"planned_reps"`
+		PlannedRepsMax  *int     `db:"planned_reps_max"`
+		PlannedAMRAP    bool     `db:"planned_amrap"`
+		PlannedWeightKg *float64 `db:"planned_weight_kg"`
+	}
+	if err := conn(ctx, a.db).SelectContext(ctx, &exercises, `
+		select id, name, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg
+		from exercises where day_id = $1
+		order by position, created_at`, dayID); err != nil {
+		return nil, err
+	}
+	var sets []struct {
+		ExerciseID string  `db:"exercise_id"`
+		Reps       int     `db:"reps"`
+		WeightKg   float64 `db:"weight_kg"`
+	}
+	if err := conn(ctx, a.db).SelectContext(ctx, &sets, `
+		select s.exercise_id, s.reps, s.weight_kg
+		from sets s join exercises e on e.id = s.exercise_id
+		where e.day_id = $1 and s.user_id = $2 and s.is_warmup = false
+		order by s.position, s.created_at`, dayID, userID); err != nil {
+		return nil, err
+	}
+	byExercise := make(map[string][]LoggedSet)
+	for _, s := range sets {
+		byExercise[s.ExerciseID] = append(byExercise[s.ExerciseID], LoggedSet{Reps: s.Reps, WeightKg: s.WeightKg})
+	}
+
+	out := &DayAdherence{DayID: day.ID, WorkoutDate: day.WorkoutDate, Exercises: make([]ExerciseAdherence, 0, len(exercises))}
+	if day.ProgramID.Valid {
+		out.ProgramID = &day.ProgramID.String
+	}
+	var totalPct float64
+	planned := 0
+	for _, ex := range exercises {
+		logged := byExercise[ex.ID]
+		if logged == nil {
+			logged = []LoggedSet{}
+		}
+		item := ExerciseAdherence{
+			ExerciseID:      ex.ID,
+			Name:            ex.Name,
+			Planned:         ex.PlannedSets != nil,
+			PlannedSets:     ex.PlannedSets,
+			PlannedReps:     ex.PlannedReps,
+			PlannedRepsMax:  ex.PlannedRepsMax,
+			PlannedAMRAP:    ex.PlannedAMRAP,
+			PlannedWeightKg: ex.PlannedWeightKg,
+			LoggedSets:      logged,
+		}
+		if ex.PlannedSets != nil && *ex.PlannedSets > 0 {
+			scorePlanned(&item)
+			totalPct += *item.CompletionPct
+			planned++
+		}
+		out.Exercises = append(out.Exercises, item)
+	}
+	if planned > 0 {
+		pct := roundTo(totalPct/float64(planned), 1)
+		out.CompletionPct = &pct
+	}
+	return out, nil
+}
+
+// scorePlanned fills item's completion, each logged set's RepsResult and the
+// AMRAP reps from its plan. PlannedSets must be set.
+func scorePlanned(item *ExerciseAdherence) {
+	planned := *item.PlannedSets
+	for i := range item.LoggedSets {
+		s := &item.LoggedSets[i]
+		amrap := item.PlannedAMRAP && i == planned-1
+		if amrap {
+			reps := s.Reps
+			item.AMRAPReps = &reps
+		}
+		if item.PlannedReps != nil {
+			top := item.PlannedReps
+			if item.PlannedRepsMax != nil {
+				top = item.PlannedRepsMax
+			}
+			switch {
+			case s.Reps < *item.PlannedReps:
+				s.RepsResult = RepsBelow
+				continue
+			case s.Reps > *top && !amrap:
+				s.RepsResult = RepsAbove
+			default:
+				s.RepsResult = RepsMet
+			}
+		}
+		if item.PlannedWeightKg != nil && s.WeightKg < *item.PlannedWeightKg {
+			continue
+		}
+		item.CompletedSets++
+	}
+	pct := roundTo(100*math.Min(float64(item.CompletedSets)/float64(planned), 1), 1)
+	item.CompletionPct = &pct
+}
+
+func roundTo(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
+
+// pearson returns the correlation coefficient of xs and ys, false with fewer
+// than three pairs or when either doesn't vary.
+func pearson(xs, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	if len(xs) < 3 {
+		return 0, false
+	}
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx, my = mx/n, my/n
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, false
+	}
+	return sxy / math.Sqrt(sxx*syy), true
+}
+
+type MuscleVolume struct {
+	Muscle    string  `db:"muscle" json:"muscle"`
+	Sets      float64 `db:"sets" json:"sets"`
+	TonnageKg float64 `db:"tonnage_kg" json:"tonnageKg"`
+}
+
+type WeeklyMuscleSplit struct {
+	WeekStart string         `json:"weekStart"`
+	Muscles   []MuscleVolume `json:"muscles"`
+}
+
+// MuscleSplit returns per-week working set counts and tonnage per muscle for
+// the last `weeks` ISO weeks (including the current one). Sets count fully
+// toward primary muscles and at secondaryFactor toward secondary muscles.
+func (a *Analytics) MuscleSplit(ctx context.Context, userID string, weeks int, secondaryFactor float64, now time.Time) ([]WeeklyMuscleSplit, error) {
+	since := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	const q = `
+		with ws as (
+		  select date_trunc('week', d.workout_date)::date as week_start, d.catalog_id, d.working_sets, d.tonnage_kg
+		  from stats_exercise_daily d
+		  where d.user_id = $1 and d.workout_date >= $2
+		), weighted as (
+		  select ws.week_start, pm.muscle, 1.0::float8 as factor, ws.working_sets, ws.tonnage_kg
+		  from ws join exercise_catalog_primary_muscles pm on pm.catalog_id = ws.catalog_id
+		  union all
+		  select ws.week_start, sm.muscle, $3::float8, ws.working_sets, ws.tonnage_kg
+		  from ws join exercise_catalog_secondary_muscles sm on sm.catalog_id = ws.catalog_id
+		)
+		select to_char(week_start, 'YYYY-MM-DD') as week_start, muscle,
+		       sum(factor * working_sets)::float8 as sets,
+		       round(sum(factor * tonnage_kg)::numeric, 2)::float8 as tonnage_kg
+		from weighted
+		where factor > 0
+		group by week_start, muscle
+		order by week_start, sets desc, muscle
+	`
+	var rows []struct {
+		WeekStart string `db:"week_start"`
+		MuscleVolume
+	}
+	if err := conn(ctx, a.db).SelectContext(ctx, &rows, q, userID, since, secondaryFactor); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[string][]MuscleVolume)
+	for _, r := range rows {
+		byWeek[r.WeekStart] = append(byWeek[r.WeekStart], r.MuscleVolume)
+	}
+	// Emit every week in range so charts get explicit zero weeks.
+	out := make([]WeeklyMuscleSplit, 0, weeks)
+	for i := 0; i < weeks; i++ {
+		key := since.AddDate(0, 0, 7*i).Format("2006-01-02")
+		muscles := byWeek[key]
+		if muscles == nil {
+			muscles = []MuscleVolume{}
+		}
+		out = append(out, WeeklyMuscleSplit{WeekStart: key, Muscles: muscles})
+	}
+	return out, nil
+}
+
+type WeeklySessionDuration struct {
+	WeekStart    string  `db:"week_start" json:"weekStart"`
+	Sessions     int     `db:"sessions" json:"sessions"`
+	TotalSeconds int     `db:"total_seconds" json:"totalSeconds"`
+	AvgSeconds   float64 `db:"avg_seconds" json:"avgSeconds"`
+}
+
+// SessionDurations returns per-week session counts and durations for the last
+// `weeks` ISO weeks. Only days with both start and finish times count.
+func (a *Analytics) SessionDurations(ctx context.Context, userID string, weeks int, now time.Time) ([]WeeklySessionDuration, error) {
+	since := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	const q = `
+		select to_char(date_trunc('week', workout_date)::date, 'YYYY-MM-DD') as week_start,
+		       count(*) as sessions,
+		       sum(duration_seconds) as total_seconds,
+		       round(avg(duration_seconds), 1)::float8 as avg_seconds
+		from workout_days
+		where user_id = $1 and workout_date >= $2 and duration_seconds is not null
+		group by 1
+		order by 1
+	`
+	var rows []WeeklySessionDuration
+	if err := conn(ctx, a.db).SelectContext(ctx, &rows, q, userID, since); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[string]WeeklySessionDuration, len(rows))
+	for _, r := range rows {
+		byWeek[r.WeekStart] = r
+	}
+	out := make([]WeeklySessionDuration, 0, weeks)
+	for i := 0; i < weeks; i++ {
+		key := since.AddDate(0, 0, 7*i).Format("2006-01-02")
+		w, ok := byWeek[key]
+		if !ok {
+			w = WeeklySessionDuration{WeekStart: key}
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+type WeeklyCardio struct {
+	WeekStart       string  `db:"week_start" json:"weekStart"`
+	Sets            int     `db:"sets" json:"sets"`
+	DurationSeconds int     `db:"duration_seconds" json:"durationSeconds"`
+	DistanceM       float64 `db:"distance_m" json:"distanceM"`
+}
+
+// Cardio returns per-week totals of time and distance logged in cardio,
+// duration and distance sets for the last `weeks` ISO weeks.
+func (a *Analytics) Cardio(ctx context.Context, userID string, weeks int, now time.Time) ([]WeeklyCardio, error) {
+	since := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	const q = `
+		select to_char(date_trunc('week', workout_date)::date, 'YYYY-MM-DD') as week_start,
+		       sum(cardio_sets) as sets,
+		       sum(duration_seconds) as duration_seconds,
+		       sum(distance_m)::float8 as distance_m
+		from stats_daily
+		where user_id = $1 and cardio_sets > 0 and workout_date >= $2
+		group by 1
+		order by 1
+	`
+	var rows []WeeklyCardio
+	if err := conn(ctx, a.db).SelectContext(ctx, &rows, q, userID, since); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[string]WeeklyCardio, len(rows))
+	for _, r := range rows {
+		byWeek[r.WeekStart] = r
+	}
+	out := make([]WeeklyCardio, 0, weeks)
+	for i := 0; i < weeks; i++ {
+		key := since.AddDate(0, 0, 7*i).Format("2006-01-02")
+		w, ok := byWeek[key]
+		if !ok {
+			w = WeeklyCardio{WeekStart: key}
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+type SideVolume struct {
+	CatalogID     string  `db:"catalog_id" json:"catalogId"`
+	Name          string  `db:"name" json:"name"`
+	LeftSets      int     `db:"left_sets" json:"leftSets"`
+	RightSets     int     `db:"right_sets" json:"rightSets"`
+	LeftVolumeKg  float64 `db:"left_volume_kg" json:"leftVolumeKg"`
+	RightVolumeKg float64 `db:"right_volume_kg" json:"rightVolumeKg"`
+	// ImbalancePct is how far the weaker side's volume trails the stronger
+	// side's, in percent. Nil until both sides have volume.
+	ImbalancePct *float64 `db:"-" json:"imbalancePct,omitempty"`
+	WeakerSide   string   `db:"-" json:"weakerSide,omitempty"`
+}
+
+// SideBalance returns left and right working-set volume per catalog exercise
+// over the last `weeks` ISO weeks, for exercises with any left or right sets.
+// Sets marked both or without a side are ignored.
+func (a *Analytics) SideBalance(ctx context.Context, userID string, weeks int, now time.Time) ([]SideVolume, error) {
+	since := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	const q = `
+		select d.catalog_id, c.name,
+		       sum(d.left_sets) as left_sets,
+		       sum(d.right_sets) as right_sets,
+		       sum(d.left_volume_kg)::float8 as left_volume_kg,
+		       sum(d.right_volume_kg)::float8 as right_volume_kg
+		from stats_exercise_daily d
+		join exercise_catalog c on c.id = d.catalog_id
+		where d.user_id = $1 and d.left_sets + d.right_sets > 0 and d.workout_date >= $2
+		group by d.catalog_id, c.name
+		order by c.name
+	`
+	out := []SideVolume{}
+	if err := conn(ctx, a.db).SelectContext(ctx, &out, q, userID, since); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].ImbalancePct, out[i].WeakerSide = sideImbalance(out[i].LeftVolumeKg, out[i].RightVolumeKg)
+	}
+	return out, nil
+}
+
+func sideImbalance(left, right float64) (*float64, string) {
+	if left <= 0 || right <= 0 {
+		return nil, ""
+	}
+	if left == right {
+		pct := 0.0
+		return &pct, ""
+	}
+	weaker, stronger, side := left, right, models.SideLeft
+	if right < left {
+		weaker, stronger, side = right, left, models.SideRight
+	}
+	pct := math.Round((stronger-weaker)/stronger*1000) / 10
+	return &pct, side
+}
+
+// WeekStart returns the Monday (UTC midnight) of t's ISO week.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(d.Weekday()) + 6) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+// Training load thresholds on the acute:chronic workload ratio.
+const (
+	chronicLoadWeeks = 4
+	deloadRatio      = 0.6
+	spikeRatio       = 1.5
+)
+
+// Training load flags.
+const (
+	LoadDeload = "deload"
+	LoadSpike  = "spike"
+)
+
+type WeeklyLoad struct {
+	WeekStart string   `json:"weekStart"`
+	Sets      int      `json:"sets"`
+	TonnageKg float64  `json:"tonnageKg"`
+	ChronicKg *float64 `json:"chronicKg,omitempty"`
+	ACWR      *float64 `json:"acwr,omitempty"`
+	Flag      string   `json:"flag,omitempty"`
+}
+
+// TrainingLoad returns weekly working-set tonnage for the last `weeks` ISO
+// weeks with the acute:chronic workload ratio: the week's tonnage over the
+// mean of the four weeks before it. Trained weeks with a ratio below 0.6 are
+// flagged as deloads, above 1.5 as spikes.
+func (a *Analytics) TrainingLoad(ctx context.Context, userID string, weeks int, now time.Time) ([]WeeklyLoad, error) {
+	total := weeks + chronicLoadWeeks
+	since := WeekStart(now).AddDate(0, 0, -7*(total-1))
+	const q = `
+		select to_char(date_trunc('week', workout_date)::date, 'YYYY-MM-DD') as week_start,
+		       sum(working_sets) as sets,
+		       sum(tonnage_kg)::float8 as tonnage_kg
+		from stats_daily
+		where user_id = $1 and workout_date >= $2
+		group by 1
+	`
+	var rows []struct {
+		WeekStart string  `db:"week_start"`
+		Sets      int     `db:"sets"`
+		TonnageKg float64 `db:"tonnage_kg"`
+	}
+	if err := conn(ctx, a.db).SelectContext(ctx, &rows, q, userID, since); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[string]int, len(rows))
+	for i, r := range rows {
+		byWeek[r.WeekStart] = i
+	}
+	series := make([]WeeklyLoad, total)
+	for i := range series {
+		key := since.AddDate(0, 0, 7*i).Format("2006-01-02")
+		series[i].WeekStart = key
+		if j, ok := byWeek[key]; ok {
+			series[i].Sets = rows[j].Sets
+			series[i].TonnageKg = roundTo(rows[j].TonnageKg, 2)
+		}
+	}
+	return classifyLoad(series, chronicLoadWeeks)[chronicLoadWeeks:], nil
+}
+
+// classifyLoad fills ChronicKg, ACWR and Flag for every week that has
+// `chronic` earlier weeks with some training in them.
+func classifyLoad(series []WeeklyLoad, chronic int) []WeeklyLoad {
+	for i := chronic; i < len(series); i++ {
+		var sum float64
+		for _, w := range series[i-chronic : i] {
+			sum += w.TonnageKg
+		}
+		if sum == 0 {
+			continue
+		}
+		mean := roundTo(sum/float64(chronic), 2)
+		ratio := roundTo(series[i].TonnageKg/mean, 2)
+		series[i].ChronicKg = &mean
+		series[i].ACWR = &ratio
+		switch {
+		case series[i].TonnageKg > 0 && ratio < deloadRatio:
+			series[i].Flag = LoadDeload
+		case ratio > spikeRatio:
+			series[i].Flag = LoadSpike
+		}
+	}
+	return series
+}
+
+type WeeklyRecovery struct {
+	WeeklyLoad
+	Checkins int `json:"checkins"`
+	// Averages over the week's check-ins; nil without any.
+	SleepHours *float64 `json:"sleepHours"`
+	Soreness   *float64 `json:"soreness"`
+	Motivation *float64 `json:"motivation"`
+}
+
+// RecoveryCorrelations are Pearson's r between a check-in value and that
+// day's tonnage, over trained days with a check-in; nil with fewer than
+// three such days.
+type RecoveryCorrelations struct {
+	SleepTonnage      *float64 `json:"sleepTonnage"`
+	SorenessTonnage   *float64 `json:"sorenessTonnage"`
+	MotivationTonnage *float64 `json:"motivationTonnage"`
+}
+
+type Recovery struct {
+	Weeks        []WeeklyRecovery     `json:"weeks"`
+	Correlations RecoveryCorrelations `json:"correlations"`
+}
+
+type checkinDay struct {
+	Date       string   `db:"checkin_date"`
+	SleepHours *float64 `db:"sleep_hours"`
+	Soreness   *float64 `db:"soreness"`
+	Motivation *float64 `db:"motivation"`
+	TonnageKg  float64  `db:"tonnage_kg"`
+}
+
+// Recovery returns the TrainingLoad weeks with the averages of the daily
+// check-ins in each, and how the check-ins correlate with the tonnage
+// lifted the same day.
+func (a *Analytics) Recovery(ctx context.Context, userID string, weeks int, now time.Time) (*Recovery, error) {
+	load, err := a.TrainingLoad(ctx, userID, weeks, now)
+	if err != nil {
+		return nil, err
+	}
+	since := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	var days []checkinDay
+	if err := conn(ctx, a.db).SelectContext(ctx, &days, `
+		select to_char(c.checkin_date, 'YYYY-MM-DD') as checkin_date, c.sleep_hours::float8 as sleep_hours,
+		       c.soreness::float8 as soreness, c.motivation::float8 as motivation,
+		       coalesce(sd.tonnage_kg, 0)::float8 as tonnage_kg
+		from daily_checkins c
+		left join stats_daily sd on sd.user_id = c.user_id and sd.workout_date = c.checkin_date
+		where c.user_id = $1 and c.checkin_date >= $2
+		order by c.checkin_date`, userID, since); err != nil {
+		return nil, err
+	}
+	return summarizeRecovery(load, days), nil
+}
+
+func summarizeRecovery(load []WeeklyLoad, days []checkinDay) *Recovery {
+	out := &Recovery{Weeks: make([]WeeklyRecovery, len(load))}
+	byWeek := make(map[string]int, len(load))
+	for i, w := range load {
+		out.Weeks[i].WeeklyLoad = w
+		byWeek[w.WeekStart] = i
+	}
+	sleep, soreness, motivation := make([]running, len(load)), make([]running, len(load)), make([]running, len(load))
+	// pairs of (value, tonnage) for sleep, soreness and motivation
+	var xs, ys [3][]float64
+	for _, d := range days {
+		date, _ := time.Parse("2006-01-02", d.Date)
+		if i, ok := byWeek[WeekStart(date).Format("2006-01-02")]; ok {
+			out.Weeks[i].Checkins++
+			sleep[i].add(d.SleepHours)
+			soreness[i].add(d.Soreness)
+			motivation[i].add(d.Motivation)
+		}
+		if d.TonnageKg == 0 {
+			continue
+		}
+		for k, v := range []*float64{d.SleepHours, d.Soreness, d.Motivation} {
+			if v != nil {
+				xs[k], ys[k] = append(xs[k], *v), append(ys[k], d.TonnageKg)
+			}
+		}
+	}
+	for i := range out.Weeks {
+		out.Weeks[i].SleepHours = sleep[i].mean(1)
+		out.Weeks[i].Soreness = soreness[i].mean(2)
+		out.Weeks[i].Motivation = motivation[i].mean(2)
+	}
+	out.Correlations = RecoveryCorrelations{
+		SleepTonnage:      correlation(xs[0], ys[0]),
+		SorenessTonnage:   correlation(xs[1], ys[1]),
+		MotivationTonnage: correlation(xs[2], ys[2]),
+	}
+	return out
+}
+
+// running averages optional values.
+type running struct {
+	sum float64
+	n   int
+}
+
+func (r *running) add(v *float64) {
+	if v != nil {
+		r.sum += *v
+		r.n++
+	}
+}
+
+func (r running) mean(decimals int) *float64 {
+	if r.n == 0 {
+		return nil
+	}
+	m := roundTo(r.sum/float64(r.n), decimals)
+	return &m
+}
+
+func correlation(xs, ys []float64) *float64 {
+	r, ok := pearson(xs, ys)
+	if !ok {
+		return nil
+	}
+	r = roundTo(r, 2)
+	return &r
+}
+
+// RecomputeStats rebuilds the stat rollups of userID, or of every user when
+// userID is empty, and returns how many daily rows that user (or everyone)
+// now has. The triggers keep rollups current; this repairs drift, e.g. from
+// two sessions editing one day at the same moment. It writes, so a must not
+// be built on a read replica.
+func (a *Analytics) RecomputeStats(ctx context.Context, userID string) (int64, error) {
+	if _, err := conn(ctx, a.db).ExecContext(ctx, `select refresh_stat_rollups(nullif($1, '')::uuid, null)`, userID); err != nil {
+		return 0, err
+	}
+	var days int64
+	if err := conn(ctx, a.db).GetContext(ctx, &days, `
+		select count(*) from stats_daily
+		where nullif($1, '')::uuid is null or user_id = nullif($1, '')::uuid`, userID); err != nil {
+		return 0, err
+	}
+	return days, nil
+}
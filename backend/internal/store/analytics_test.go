@@ -0,0 +1,109 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyLoad(t *testing.T) {
+	series := []WeeklyLoad{
+		{TonnageKg: 0},
+		{TonnageKg: 10000},
+		{TonnageKg: 10000},
+		{TonnageKg: 10000},
+		{TonnageKg: 10000}, // steady
+		{TonnageKg: 4000},  // deload
+		{TonnageKg: 0},     // week off
+		{TonnageKg: 20000}, // spike
+	}
+	got := classifyLoad(series, 4)
+	if got[3].ACWR != nil {
+		t.Fatalf("week without full history got a ratio")
+	}
+	want := []struct {
+		acwr float64
+		flag string
+	}{
+		{1.33, ""},
+		{0.4, LoadDeload},
+		{0, ""},
+		{3.33, LoadSpike},
+	}
+	for i, w := range want {
+		wk := got[i+4]
+		if wk.ACWR == nil || *wk.ACWR != w.acwr || wk.Flag != w.flag {
+			acwr := -1.0
+			if wk.ACWR != nil {
+				acwr = *wk.ACWR
+			}
+			t.Errorf("week %d: acwr=%v flag=%q, want %v %q", i+4, acwr, wk.Flag, w.acwr, w.flag)
+		}
+	}
+}
+
+func TestSideImbalance(t *testing.T) {
+	cases := []struct {
+		left, right float64
+		pct         float64
+		weaker      string
+		ok          bool
+	}{
+		{1000, 800, 20, "right", true},
+		{600, 1000, 40, "left", true},
+		{500, 500, 0, "", true},
+		{500, 0, 0, "", false},
+	}
+	for _, c := range cases {
+		pct, weaker := sideImbalance(c.left, c.right)
+		if (pct != nil) != c.ok || weaker != c.weaker || (pct != nil && *pct != c.pct) {
+			t.Errorf("sideImbalance(%v, %v) = %v %q, want %v %q", c.left, c.right, pct, weaker, c.pct, c.weaker)
+		}
+	}
+}
+
+func TestSummarizeRecovery(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	load := []WeeklyLoad{{WeekStart: "2026-03-02", TonnageKg: 18000}, {WeekStart: "2026-03-09"}}
+	got := summarizeRecovery(load, []checkinDay{
+		{Date: "2026-03-02", SleepHours: f(8), Soreness: f(1), Motivation: f(5), TonnageKg: 8000},
+		{Date: "2026-03-03", SleepHours: f(6), Soreness: f(4)},
+		{Date: "2026-03-04", SleepHours: f(7), Soreness: f(2), TonnageKg: 6000},
+		{Date: "2026-03-06", SleepHours: f(6), Soreness: f(4), TonnageKg: 4000},
+	})
+	w := got.Weeks[0]
+	if w.Checkins != 4 || *w.SleepHours != 6.8 || *w.Soreness != 2.75 || *w.Motivation != 5 || w.TonnageKg != 18000 {
+		t.Fatalf("week = %+v", w)
+	}
+	if got.Weeks[1].Checkins != 0 || got.Weeks[1].SleepHours != nil {
+		t.Fatalf("empty week = %+v", got.Weeks[1])
+	}
+	c := got.Correlations
+	if c.SleepTonnage == nil || *c.SleepTonnage != 1 || c.SorenessTonnage == nil || *c.SorenessTonnage >= 0 || c.MotivationTonnage != nil {
+		t.Fatalf("correlations = %+v", c)
+	}
+}
+
+func TestScorePlanned(t *testing.T) {
+	three, eight, twelve := 3, 8, 12
+	kg := 60.0
+	item := ExerciseAdherence{
+		PlannedSets: &three, PlannedReps: &eight, PlannedRepsMax: &twelve, PlannedAMRAP: true, PlannedWeightKg: &kg,
+		LoggedSets: []LoggedSet{{Reps: 13, WeightKg: 60}, {Reps: 7, WeightKg: 60}, {Reps: 15, WeightKg: 60}, {Reps: 10, WeightKg: 55}},
+	}
+	scorePlanned(&item)
+	var got []string
+	for _, s := range item.LoggedSets {
+		got = append(got, s.RepsResult)
+	}
+	// The third set is the AMRAP one, so 15 reps is still met.
+	if want := []string{RepsAbove, RepsBelow, RepsMet, RepsMet}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("results %v, want %v", got, want)
+	}
+	if item.AMRAPReps == nil || *item.AMRAPReps != 15 {
+		t.Fatalf("amrap reps %v, want 15", item.AMRAPReps)
+	}
+	// The 55 kg set is short on weight; two of three planned sets count.
+	if item.CompletedSets != 2 || *item.CompletionPct != 66.7 {
+		t.Fatalf("completed %d (%v%%), want 2 (66.7%%)", item.CompletedSets, *item.CompletionPct)
+	}
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeys struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeys(db *sqlx.DB) *APIKeys { return &APIKeys{db: db} }
+
+type APIKey struct {
+	ID         string     `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	Prefix     string     `db:"prefix" json:"prefix"`
+	Scope      string     `db:"scope" json:"scope"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt,omitempty"`
+}
+
+func (s *APIKeys) Create(ctx context.Context, userID, name, prefix, hash, scope string) (*APIKey, error) {
+	const q = `
+		insert into api_keys (user_id, name, prefix, key_hash, scope)
+		values ($1, $2, $3, $4, $5)
+		returning id, name, prefix, scope, created_at, last_used_at
+	`
+	var k APIKey
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, name, prefix, hash, scope).StructScan(&k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// List returns the user's active keys, newest first.
+func (s *APIKeys) List(ctx context.Context, userID string) ([]APIKey, error) {
+	out := []APIKey{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select id, name, prefix, scope, created_at, last_used_at
+		from api_keys
+		where user_id = $1 and revoked_at is null
+		order by created_at desc
+	`, userID)
+	return out, err
+}
+
+func (s *APIKeys) Revoke(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update api_keys set revoked_at = now()
+		where id = $1 and user_id = $2 and revoked_at is null
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Verify resolves an active key hash to its owner and scope, stamping
+// last_used_at at most once a minute.
+func (s *APIKeys) Verify(ctx context.Context, hash string) (userID, scope string, ok bool, err error) {
+	err = conn(ctx, s.db).QueryRowxContext(ctx, `
+		update api_keys k
+		set last_used_at = case
+		  when k.last_used_at is null or k.last_used_at < now() - interval '1 minute' then now()
+		  else k.last_used_at end
+		from users u
+		where k.key_hash = $1 and k.revoked_at is null and u.id = k.user_id and u.deleted_at is null
+		returning k.user_id, k.scope
+	`, hash).Scan(&userID, &scope)
+	if err == sql.ErrNoRows {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	return userID, scope, true, nil
+}
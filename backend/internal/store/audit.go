@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Audit actions recorded for catalog mutations.
+const (
+	AuditCatalogImport    = "catalog.import"
+	AuditCatalogCreate    = "catalog.create"
+	AuditCatalogUpdate    = "catalog.update"
+	AuditCatalogDelete    = "catalog.delete"
+	AuditCatalogMerge     = "catalog.merge"
+	AuditCatalogSubmit    = "catalog.submit"
+	AuditCatalogApprove   = "catalog.approve"
+	AuditCatalogReject    = "catalog.reject"
+	AuditCatalogTranslate = "catalog.translate"
+	AuditMuscleMerge      = "catalog.muscle_merge"
+)
+
+// Audit actions recorded for account lifecycle events. These rows never carry
+// the actor; the entity id is AnonymizedUserID.
+const (
+	AuditAccountDeleteScheduled = "account.delete_scheduled"
+	AuditAccountDelete          = "account.delete"
+	AuditAccountPurge           = "account.purge"
+)
+
+// Audit actions recorded for server administration.
+const (
+	AuditMaintenance    = "server.maintenance"
+	AuditQuotaUpdate    = "server.quota"
+	AuditStatsRecompute = "server.stats_recompute"
+)
+
+type Audit struct {
+	db *sqlx.DB
+}
+
+func NewAudit(db *sqlx.DB) *Audit { return &Audit{db: db} }
+
+type AuditEntry struct {
+	ID         string          `db:"id" json:"id"`
+	ActorID    *string         `db:"actor_id" json:"actorId,omitempty"`
+	ActorEmail *string         `db:"actor_email" json:"actorEmail,omitempty"`
+	Action     string          `db:"action" json:"action"`
+	EntityType string          `db:"entity_type" json:"entityType"`
+	EntityID   *string         `db:"entity_id" json:"entityId,omitempty"`
+	Before     json.RawMessage `db:"before_data" json:"before,omitempty"`
+	After      json.RawMessage `db:"after_data" json:"after,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"createdAt"`
+}
+
+type AuditRecordParams struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+// Record stores one audit row. The actor's email is copied at write time so
+// the trail stays readable after the account is gone.
+func (s *Audit) Record(ctx context.Context, p AuditRecordParams) error {
+	before, err := marshalSnapshot(p.Before)
+	if err != nil {
+		return fmt.Errorf("audit before: %w", err)
+	}
+	after, err := marshalSnapshot(p.After)
+	if err != nil {
+		return fmt.Errorf("audit after: %w", err)
+	}
+	const q = `
+		insert into audit_log (actor_id, actor_email, action, entity_type, entity_id, before_data, after_data)
+		values (nullif($1, '')::uuid, (select email from users where id = nullif($1, '')::uuid), $2, $3, nullif($4, ''), $5, $6)
+	`
+	_, err = conn(ctx, s.db).ExecContext(ctx, q, p.ActorID, p.Action, p.EntityType, p.EntityID, before, after)
+	return err
+}
+
+func marshalSnapshot(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+type AuditFilter struct {
+	ActorID    string
+	ActorEmail string
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+func (s *Audit) List(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	if f.Limit <= 0 || f.Limit > 200 {
+		f.Limit = 50
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	where := []string{}
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.ActorID != "" {
+		where = append(where, "actor_id::text = "+arg(f.ActorID))
+	}
+	if f.ActorEmail != "" {
+		where = append(where, "lower(actor_email) = lower("+arg(f.ActorEmail)+")")
+	}
+	if f.Action != "" {
+		where = append(where, "action = "+arg(f.Action))
+	}
+	if f.From != nil {
+		where = append(where, "created_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		where = append(where, "created_at < "+arg(*f.To))
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = "where " + strings.Join(where, " and ")
+	}
+	q := `
+		select id, actor_id, actor_email, action, entity_type, entity_id, before_data, after_data, created_at
+		from audit_log
+		` + cond + `
+		order by created_at desc
+		limit ` + arg(f.Limit) + ` offset ` + arg(f.Offset)
+	out := []AuditEntry{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/biometrics"
+)
+
+type Biometrics struct {
+	db *sqlx.DB
+}
+
+func NewBiometrics(db *sqlx.DB) *Biometrics { return &Biometrics{db: db} }
+
+// HeartRateSummary is a day's heart rate as shown in day summaries.
+type HeartRateSummary struct {
+	AvgBPM       float64 `db:"avg_bpm" json:"avgBpm"`
+	MaxBPM       int     `db:"max_bpm" json:"maxBpm"`
+	CaloriesKcal float64 `db:"calories_kcal" json:"caloriesKcal"`
+}
+
+type HeartRate struct {
+	DayID string `db:"day_id" json:"dayId"`
+	HeartRateSummary
+	SampleCount int       `db:"sample_count" json:"sampleCount"`
+	StartedAt   time.Time `db:"started_at" json:"startedAt"`
+	EndedAt     time.Time `db:"ended_at" json:"endedAt"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+	// Samples is only loaded by HeartRate.
+	Samples []biometrics.Sample `db:"-" json:"samples,omitempty"`
+}
+
+const heartRateColumns = `day_id, avg_bpm, max_bpm, calories_kcal, sample_count, started_at, ended_at, updated_at`
+
+// PutHeartRate replaces the day's heart-rate samples, which must be
+// validated, and returns the new summary, or nil, nil if the day isn't the
+// user's. Without a profile weight the bodyweight logged closest to the
+// workout date is used for the calorie estimate.
+func (s *Biometrics) PutHeartRate(ctx context.Context, userID, dayID string, samples []biometrics.Sample, p biometrics.Profile) (*HeartRate, error) {
+	var weight sql.NullFloat64
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		select (
+		  select b.weight_kg::float8
+		  from bodyweight_entries b
+		  where b.user_id = d.user_id
+		  order by (b.measured_on > d.workout_date), abs(b.measured_on - d.workout_date)
+		  limit 1
+		)
+		from workout_days d
+		where d.id = $1 and d.user_id = $2`, dayID, userID).Scan(&weight)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.WeightKg <= 0 && weight.Valid {
+		p.WeightKg = weight.Float64
+	}
+	st := biometrics.Summarize(samples, p)
+	data, err := biometrics.Encode(samples)
+	if err != nil {
+		return nil, err
+	}
+	var out HeartRate
+	err = conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into day_heart_rate (day_id, user_id, samples, sample_count, started_at, ended_at, avg_bpm, max_bpm, calories_kcal)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		on conflict (day_id) do update
+		set samples = excluded.samples, sample_count = excluded.sample_count,
+		    started_at = excluded.started_at, ended_at = excluded.ended_at,
+		    avg_bpm = excluded.avg_bpm, max_bpm = excluded.max_bpm,
+		    calories_kcal = excluded.calories_kcal, updated_at = now()
+		returning `+heartRateColumns,
+		dayID, userID, data, st.Samples, st.StartedAt, st.EndedAt, st.AvgBPM, st.MaxBPM, st.CaloriesKcal).StructScan(&out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HeartRate returns the day's summary with its decoded samples, or nil, nil
+// without any.
+func (s *Biometrics) HeartRate(ctx context.Context, userID, dayID string) (*HeartRate, error) {
+	var row struct {
+		HeartRate
+		Data []byte `db:"samples"`
+	}
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		select `+heartRateColumns+`, samples
+		from day_heart_rate
+		where day_id = $1 and user_id = $2`, dayID, userID).StructScan(&row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := row.HeartRate
+	if out.Samples, err = biometrics.Decode(row.Data); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *Biometrics) DeleteHeartRate(ctx context.Context, userID, dayID string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from day_heart_rate where day_id = $1 and user_id = $2`, dayID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
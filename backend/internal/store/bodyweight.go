@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Bodyweight struct {
+	db *sqlx.DB
+}
+
+func NewBodyweight(db *sqlx.DB) *Bodyweight { return &Bodyweight{db: db} }
+
+type BodyweightEntry struct {
+	ID         string    `db:"id" json:"id"`
+	MeasuredOn string    `db:"measured_on" json:"date"`
+	WeightKg   float64   `db:"weight_kg" json:"weightKg"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+const bodyweightColumns = `id, to_char(measured_on, 'YYYY-MM-DD') as measured_on, weight_kg, created_at, updated_at`
+
+// Log records the user's bodyweight for a date, replacing any earlier entry
+// for that date. Effective loads of bodyweight sets are recomputed by a
+// trigger.
+func (s *Bodyweight) Log(ctx context.Context, userID string, date time.Time, weightKg float64) (*BodyweightEntry, error) {
+	q := `
+		insert into bodyweight_entries (user_id, measured_on, weight_kg)
+		values ($1, $2, $3)
+		on conflict (user_id, measured_on) do update
+		set weight_kg = excluded.weight_kg, updated_at = now()
+		returning ` + bodyweightColumns
+	var out BodyweightEntry
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, date.Format("2006-01-02"), weightKg).StructScan(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns entries between from and to (inclusive, either may be nil),
+// newest first.
+func (s *Bodyweight) List(ctx context.Context, userID string, from, to *time.Time) ([]BodyweightEntry, error) {
+	out := []BodyweightEntry{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select `+bodyweightColumns+`
+		from bodyweight_entries
+		where user_id = $1
+		  and ($2::date is null or measured_on >= $2::date)
+		  and ($3::date is null or measured_on <= $3::date)
+		order by measured_on desc`, userID, dateArg(from), dateArg(to))
+	return out, err
+}
+
+func (s *Bodyweight) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from bodyweight_entries where id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func dateArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
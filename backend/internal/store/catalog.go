@@ -4,20 +4,55 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/blob"
+	"exercise-tracker/internal/imaging"
+	"exercise-tracker/internal/progression"
 )
 
 type Catalog struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	read  *sqlx.DB
+	blobs blob.Store
+	cache *catalogCache
+}
+
+// NewCatalog returns the catalog store; images are read and written through
+// blobs (falling back to the Postgres blob table when nil).
+func NewCatalog(db *sqlx.DB, blobs blob.Store) *Catalog {
+	if blobs == nil {
+		blobs = blob.NewPostgres(db)
+	}
+	return &Catalog{db: db, blobs: blobs}
+}
+
+// UseReader sends Search, Facets, FacetCounts and Version to a read replica.
+func (s *Catalog) UseReader(db *sqlx.DB) { s.read = db }
+
+// reader is conn for the replica-backed reads.
+func (s *Catalog) reader(ctx context.Context) queryer {
+	if s.read == nil {
+		return conn(ctx, s.db)
+	}
+	return conn(ctx, s.read)
 }
 
-func NewCatalog(db *sqlx.DB) *Catalog {
-	return &Catalog{db: db}
+// Version returns the catalog version counter, bumped by triggers on every
+// write to the catalog tables.
+func (s *Catalog) Version(ctx context.Context) (int64, error) {
+	var v int64
+	if err := s.reader(ctx).GetContext(ctx, &v, `select version from catalog_version where id`); err != nil {
+		return 0, err
+	}
+	return v, nil
 }
 
 type CatalogEntry struct {
@@ -32,6 +67,11 @@ type CatalogEntry struct {
 	Links            []string `json:"links,omitempty"`
 	Multiplier       *float64 `json:"multiplier,omitempty"`
 	BaseWeightKg     *float64 `json:"baseWeightKg,omitempty"`
+	// Instructions are ordered steps; Cues and CommonMistakes are short
+	// coaching notes shown on the exercise detail page.
+	Instructions   []string `json:"instructions,omitempty"`
+	Cues           []string `json:"cues,omitempty"`
+	CommonMistakes []string `json:"commonMistakes,omitempty"`
 }
 
 type CatalogRecord struct {
@@ -51,6 +91,18 @@ type CatalogRecord struct {
 	HasImage         bool      `json:"hasImage"`
 	CreatedAt        time.Time `json:"createdAt"`
 	UpdatedAt        time.Time `json:"updatedAt"`
+	Instructions     []string  `json:"instructions"`
+	Cues             []string  `json:"cues"`
+	CommonMistakes   []string  `json:"commonMistakes"`
+	// Review state; see CatalogPending and friends.
+	Status         string     `json:"status"`
+	SubmittedBy    *string    `json:"submittedBy,omitempty"`
+	ReviewFeedback *string    `json:"reviewFeedback,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+	// Locale is set when Name/Description come from a translation.
+	Locale *string `json:"locale,omitempty"`
+	// LinkPreviews follows Links, adding fetched oEmbed/OpenGraph metadata.
+	LinkPreviews []LinkPreview `json:"linkPreviews"`
 }
 
 var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
@@ -62,12 +114,19 @@ func slugify(name string) string {
 	return s
 }
 
-// Upsert inserts or updates catalog rows by slug.
+// Upsert inserts or updates catalog rows by slug. The whole batch is written
+// with a fixed number of set-based statements (unnest over column arrays)
+// regardless of its size. When a slug repeats within entries, the last entry
+// wins.
 func (s *Catalog) Upsert(ctx context.Context, entries []CatalogEntry) (affected int, err error) {
 	if len(entries) == 0 {
 		return 0, nil
 	}
-	tx, err := s.db.BeginTxx(ctx, nil)
+	batch, err := newCatalogBatch(entries)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := beginTx(ctx, s.db)
 	if err != nil {
 		return 0, err
 	}
@@ -76,127 +135,238 @@ func (s *Catalog) Upsert(ctx context.Context, entries []CatalogEntry) (affected
 			_ = tx.Rollback()
 		}
 	}()
-	for _, entry := range entries {
-		if err = upsertCatalogEntry(ctx, tx, entry); err != nil {
-			return affected, err
-		}
-		affected++
+	if err = batch.write(ctx, tx.Tx); err != nil {
+		return 0, err
 	}
 	if err = tx.Commit(); err != nil {
-		return affected, err
+		return 0, err
 	}
-	return affected, nil
+	AfterCommit(ctx, s.cache.invalidate)
+	return len(entries), nil
 }
 
-func upsertCatalogEntry(ctx context.Context, tx *sqlx.Tx, entry CatalogEntry) error {
-	name := strings.TrimSpace(entry.Name)
-	if name == "" {
-		return fmt.Errorf("catalog name is required")
-	}
-	slug := slugify(name)
-	var (
-		description sql.NullString
-		multiplier  sql.NullFloat64
-		baseWeight  sql.NullFloat64
-	)
-	if entry.Description != nil {
-		if trimmed := strings.TrimSpace(*entry.Description); trimmed != "" {
-			description = sql.NullString{String: trimmed, Valid: true}
+// catalogBatch holds validated entries as parallel column arrays ready to be
+// passed to unnest().
+type catalogBatch struct {
+	names        []string
+	slugs        []string
+	descriptions []*string
+	types        []string
+	bodyParts    []string
+	equipment    []string
+	levels       []string
+	multipliers  []*float64
+	baseWeights  []*float64
+	links        []string // JSON-encoded text arrays; Postgres can't unnest ragged 2-D arrays
+	instructions []string // JSON-encoded, like links
+	cues         []string
+	mistakes     []string
+
+	primarySlugs     []string
+	primaryMuscles   []string
+	secondarySlugs   []string
+	secondaryMuscles []string
+}
+
+func newCatalogBatch(entries []CatalogEntry) (*catalogBatch, error) {
+	b := &catalogBatch{}
+	index := make(map[string]int, len(entries))
+	primary := make([][]string, 0, len(entries))
+	secondary := make([][]string, 0, len(entries))
+	for i, entry := range entries {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			return nil, fmt.Errorf("entry %d: catalog name is required", i)
+		}
+		typeVal, err := normalizeRequired("type", entry.Type)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		bodyPart, err := normalizeRequired("bodyPart", entry.BodyPart)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		equipment, err := normalizeRequired("equipment", entry.Equipment)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		level, err := normalizeRequired("level", entry.Level)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		primaryMuscles := sanitizeList(entry.PrimaryMuscles)
+		if len(primaryMuscles) == 0 {
+			return nil, fmt.Errorf("entry %d: primaryMuscles is required", i)
+		}
+		var description *string
+		if entry.Description != nil {
+			if trimmed := strings.TrimSpace(*entry.Description); trimmed != "" {
+				description = &trimmed
+			}
+		}
+		links := sanitizeList(entry.Links)
+		if links == nil {
+			links = []string{}
+		}
+		linksJSON, err := json.Marshal(links)
+		if err != nil {
+			return nil, err
+		}
+		var texts [3][]byte
+		for k, list := range [][]string{entry.Instructions, entry.Cues, entry.CommonMistakes} {
+			if texts[k], err = json.Marshal(sanitizeSteps(list)); err != nil {
+				return nil, err
+			}
 		}
-	}
-	typeVal, err := normalizeRequired("type", entry.Type)
-	if err != nil {
-		return err
-	}
-	bodyPart, err := normalizeRequired("bodyPart", entry.BodyPart)
-	if err != nil {
-		return err
-	}
-	equipment, err := normalizeRequired("equipment", entry.Equipment)
-	if err != nil {
-		return err
-	}
-	level, err := normalizeRequired("level", entry.Level)
-	if err != nil {
-		return err
-	}
-	primaryMuscles := sanitizeList(entry.PrimaryMuscles)
-	if len(primaryMuscles) == 0 {
-		return fmt.Errorf("primaryMuscles is required")
-	}
-	if entry.Multiplier != nil {
-		multiplier = sql.NullFloat64{Float64: *entry.Multiplier, Valid: true}
-	}
-	if entry.BaseWeightKg != nil {
-		baseWeight = sql.NullFloat64{Float64: *entry.BaseWeightKg, Valid: true}
-	}
-	secondaries := sanitizeList(entry.SecondaryMuscles)
 
-	for _, ref := range []struct {
-		value string
-		sql   string
-	}{
-		{typeVal, `insert into exercise_types(name) values ($1) on conflict do nothing`},
-		{bodyPart, `insert into body_parts(name) values ($1) on conflict do nothing`},
-		{equipment, `insert into equipment_types(name) values ($1) on conflict do nothing`},
-		{level, `insert into levels(name) values ($1) on conflict do nothing`},
-	} {
-		if _, err := tx.ExecContext(ctx, ref.sql, ref.value); err != nil {
-			return err
+		slug := slugify(name)
+		j, seen := index[slug]
+		if !seen {
+			j = len(b.slugs)
+			index[slug] = j
+			b.names = append(b.names, "")
+			b.slugs = append(b.slugs, slug)
+			b.descriptions = append(b.descriptions, nil)
+			b.types = append(b.types, "")
+			b.bodyParts = append(b.bodyParts, "")
+			b.equipment = append(b.equipment, "")
+			b.levels = append(b.levels, "")
+			b.multipliers = append(b.multipliers, nil)
+			b.baseWeights = append(b.baseWeights, nil)
+			b.links = append(b.links, "")
+			b.instructions = append(b.instructions, "")
+			b.cues = append(b.cues, "")
+			b.mistakes = append(b.mistakes, "")
+			primary = append(primary, nil)
+			secondary = append(secondary, nil)
+		}
+		b.names[j] = name
+		b.descriptions[j] = description
+		b.types[j] = typeVal
+		b.bodyParts[j] = bodyPart
+		b.equipment[j] = equipment
+		b.levels[j] = level
+		b.multipliers[j] = entry.Multiplier
+		b.baseWeights[j] = entry.BaseWeightKg
+		b.links[j] = string(linksJSON)
+		b.instructions[j] = string(texts[0])
+		b.cues[j] = string(texts[1])
+		b.mistakes[j] = string(texts[2])
+		primary[j] = primaryMuscles
+		secondary[j] = sanitizeList(entry.SecondaryMuscles)
+	}
+	for j, slug := range b.slugs {
+		for _, m := range primary[j] {
+			b.primarySlugs = append(b.primarySlugs, slug)
+			b.primaryMuscles = append(b.primaryMuscles, m)
+		}
+		for _, m := range secondary[j] {
+			b.secondarySlugs = append(b.secondarySlugs, slug)
+			b.secondaryMuscles = append(b.secondaryMuscles, m)
 		}
 	}
-	for _, muscle := range primaryMuscles {
-		if _, err := tx.ExecContext(ctx, `insert into muscle_types(name) values ($1) on conflict do nothing`, muscle); err != nil {
+	return b, nil
+}
+
+func (b *catalogBatch) write(ctx context.Context, tx *sqlx.Tx) error {
+	// Spellings are resolved row by row; a pair that collapses onto one
+	// muscle is dropped by the on conflict below.
+	for _, names := range []*[]string{&b.primaryMuscles, &b.secondaryMuscles} {
+		var resolved []string
+		if err := tx.SelectContext(ctx, &resolved, `
+			select canonical_muscle(u.name)
+			from unnest($1::text[]) with ordinality as u(name, ord)
+			order by u.ord`, *names); err != nil {
 			return err
 		}
+		*names = resolved
 	}
-	for _, muscle := range secondaries {
-		if _, err := tx.ExecContext(ctx, `insert into muscle_types(name) values ($1) on conflict do nothing`, muscle); err != nil {
+	muscles := append(append([]string{}, b.primaryMuscles...), b.secondaryMuscles...)
+	for _, ref := range []struct {
+		values []string
+		sql    string
+	}{
+		{b.types, `insert into exercise_types(name) select distinct unnest($1::text[]) on conflict do nothing`},
+		{b.bodyParts, `insert into body_parts(name) select distinct unnest($1::text[]) on conflict do nothing`},
+		{b.equipment, `insert into equipment_types(name) select distinct unnest($1::text[]) on conflict do nothing`},
+		{b.levels, `insert into levels(name) select distinct unnest($1::text[]) on conflict do nothing`},
+		{muscles, `insert into muscle_types(name) select distinct unnest($1::text[]) on conflict do nothing`},
+	} {
+		if _, err := tx.ExecContext(ctx, ref.sql, ref.values); err != nil {
 			return err
 		}
 	}
-	links := sanitizeList(entry.Links)
-	if links == nil {
-		links = []string{}
-	}
+
+	// Existing rows are updated first so a missing multiplier/base weight can
+	// keep the stored value; ON CONFLICT can't see which inputs were null.
 	const q = `
-insert into exercise_catalog (name, slug, description, type, body_part, equipment, level, multiplier, base_weight_kg, links)
-values ($1, $2, $3, $4, $5, $6, $7, coalesce($8, 1), coalesce($9, 0), $10)
-on conflict (slug) do update
-set name = excluded.name,
-    description = excluded.description,
-    type = excluded.type,
-    body_part = excluded.body_part,
-    equipment = excluded.equipment,
-    level = excluded.level,
-    multiplier = case when $8 is null then exercise_catalog.multiplier else excluded.multiplier end,
-    base_weight_kg = case when $9 is null then exercise_catalog.base_weight_kg else excluded.base_weight_kg end,
-    links = excluded.links
-returning id
+with src as (
+  select
+    u.name, u.slug, u.description, u.type, u.body_part, u.equipment, u.level,
+    u.multiplier, u.base_weight_kg,
+    array(select jsonb_array_elements_text(u.links::jsonb)) as links,
+    array(select jsonb_array_elements_text(u.instructions::jsonb)) as instructions,
+    array(select jsonb_array_elements_text(u.cues::jsonb)) as cues,
+    array(select jsonb_array_elements_text(u.common_mistakes::jsonb)) as common_mistakes
+  from unnest(
+    $1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[],
+    $8::float8[], $9::float8[], $10::text[], $11::text[], $12::text[], $13::text[]
+  ) as u(name, slug, description, type, body_part, equipment, level, multiplier, base_weight_kg, links,
+         instructions, cues, common_mistakes)
+),
+updated as (
+  update exercise_catalog ec
+  set name = src.name,
+      description = src.description,
+      type = src.type,
+      body_part = src.body_part,
+      equipment = src.equipment,
+      level = src.level,
+      multiplier = coalesce(src.multiplier, ec.multiplier),
+      base_weight_kg = coalesce(src.base_weight_kg, ec.base_weight_kg),
+      links = src.links,
+      instructions = src.instructions,
+      cues = src.cues,
+      common_mistakes = src.common_mistakes
+  from src
+  where ec.slug = src.slug
+  returning ec.slug
+)
+insert into exercise_catalog (name, slug, description, type, body_part, equipment, level, multiplier, base_weight_kg, links,
+                              instructions, cues, common_mistakes)
+select src.name, src.slug, src.description, src.type, src.body_part, src.equipment, src.level,
+       coalesce(src.multiplier, 1), coalesce(src.base_weight_kg, 0), src.links,
+       src.instructions, src.cues, src.common_mistakes
+from src
+where not exists (select 1 from updated where updated.slug = src.slug)
 `
-	var catalogID string
-	if err := tx.QueryRowxContext(ctx, q, name, slug, description, typeVal, bodyPart, equipment, level, multiplier, baseWeight, links).Scan(&catalogID); err != nil {
-		return err
-	}
-	if _, err := tx.ExecContext(ctx, `delete from exercise_catalog_primary_muscles where catalog_id = $1`, catalogID); err != nil {
+	if _, err := tx.ExecContext(ctx, q,
+		b.names, b.slugs, b.descriptions, b.types, b.bodyParts, b.equipment, b.levels,
+		b.multipliers, b.baseWeights, b.links, b.instructions, b.cues, b.mistakes,
+	); err != nil {
 		return err
 	}
-	for _, muscle := range primaryMuscles {
-		if _, err := tx.ExecContext(ctx, `
-			insert into exercise_catalog_primary_muscles (catalog_id, muscle)
-			values ($1, $2)
-			on conflict do nothing`, catalogID, muscle); err != nil {
+
+	for _, m := range []struct {
+		table  string
+		slugs  []string
+		values []string
+	}{
+		{"exercise_catalog_primary_muscles", b.primarySlugs, b.primaryMuscles},
+		{"exercise_catalog_secondary_muscles", b.secondarySlugs, b.secondaryMuscles},
+	} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			delete from %s m
+			using exercise_catalog ec
+			where m.catalog_id = ec.id and ec.slug = any($1::text[])`, m.table), b.slugs); err != nil {
 			return err
 		}
-	}
-	if _, err := tx.ExecContext(ctx, `delete from exercise_catalog_secondary_muscles where catalog_id = $1`, catalogID); err != nil {
-		return err
-	}
-	for _, muscle := range secondaries {
-		if _, err := tx.ExecContext(ctx, `
-			insert into exercise_catalog_secondary_muscles (catalog_id, muscle)
-			values ($1, $2)
-			on conflict do nothing`, catalogID, muscle); err != nil {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			insert into %s (catalog_id, muscle)
+			select ec.id, u.muscle
+			from unnest($1::text[], $2::text[]) as u(slug, muscle)
+			join exercise_catalog ec on ec.slug = u.slug
+			on conflict do nothing`, m.table), m.slugs, m.values); err != nil {
 			return err
 		}
 	}
@@ -234,12 +404,22 @@ func sanitizeList(values []string) []string {
 	return out
 }
 
-func (s *Catalog) GetCatalogEntry(ctx context.Context, id string) (*CatalogRecord, error) {
-	trimmed := strings.TrimSpace(id)
-	if trimmed == "" {
-		return nil, fmt.Errorf("id is required")
+// sanitizeSteps trims values and drops blanks. Unlike sanitizeList it keeps
+// duplicates, since instruction steps may legitimately repeat, and it never
+// returns nil so the result can be written to a not-null array column.
+func sanitizeSteps(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			out = append(out, trimmed)
+		}
 	}
-	const q = `
+	return out
+}
+
+// catalogRecordSelect selects the columns read by scanCatalogRecord; callers
+// append their own where/order clause.
+const catalogRecordSelect = `
 select
   ec.id,
   ec.name,
@@ -262,12 +442,27 @@ select
     from exercise_catalog_secondary_muscles sm
     where sm.catalog_id = ec.id
   ), '[]'::json) as secondary_json,
-  case when ec.image_data is not null then true else false end as has_image,
+  case when ec.image_key is not null or ec.image_data is not null then true else false end as has_image,
   ec.created_at,
-  ec.updated_at
+  ec.updated_at,
+  ec.status,
+  ec.submitted_by,
+  ec.review_feedback,
+  ec.reviewed_at,
+  coalesce(array_to_json(ec.instructions), '[]'::json) as instructions_json,
+  coalesce(array_to_json(ec.cues), '[]'::json) as cues_json,
+  coalesce(array_to_json(ec.common_mistakes), '[]'::json) as mistakes_json,
+  coalesce((
+    select json_agg(json_build_object(
+      'url', u.url, 'provider', lm.provider, 'title', lm.title, 'thumbnailUrl', lm.thumbnail_url
+    ) order by u.ord)
+    from unnest(ec.links) with ordinality as u(url, ord)
+    left join link_metadata lm on lm.url = u.url and lm.status = 'ok'
+  ), '[]'::json) as link_previews_json
 from exercise_catalog ec
-where ec.id = $1
 `
+
+func scanCatalogRecord(row interface{ Scan(...any) error }) (*CatalogRecord, error) {
 	var (
 		record        CatalogRecord
 		description   sql.NullString
@@ -276,8 +471,10 @@ where ec.id = $1
 		primaryJSON   []byte
 		linksJSON     []byte
 		secondaryJSON []byte
+		previewsJSON  []byte
+		textsJSON     [3][]byte
 	)
-	if err := s.db.QueryRowxContext(ctx, q, trimmed).Scan(
+	if err := row.Scan(
 		&record.ID,
 		&record.Name,
 		&record.Slug,
@@ -294,6 +491,14 @@ where ec.id = $1
 		&record.HasImage,
 		&record.CreatedAt,
 		&record.UpdatedAt,
+		&record.Status,
+		&record.SubmittedBy,
+		&record.ReviewFeedback,
+		&record.ReviewedAt,
+		&textsJSON[0],
+		&textsJSON[1],
+		&textsJSON[2],
+		&previewsJSON,
 	); err != nil {
 		return nil, err
 	}
@@ -324,15 +529,47 @@ where ec.id = $1
 	if record.SecondaryMuscles == nil {
 		record.SecondaryMuscles = []string{}
 	}
+	if err := json.Unmarshal(previewsJSON, &record.LinkPreviews); err != nil {
+		return nil, err
+	}
+	if record.LinkPreviews == nil {
+		record.LinkPreviews = []LinkPreview{}
+	}
+	for k, dst := range []*[]string{&record.Instructions, &record.Cues, &record.CommonMistakes} {
+		if err := json.Unmarshal(textsJSON[k], dst); err != nil {
+			return nil, err
+		}
+		if *dst == nil {
+			*dst = []string{}
+		}
+	}
 	return &record, nil
 }
 
+func (s *Catalog) getCatalogEntry(ctx context.Context, id string) (*CatalogRecord, error) {
+	trimmed := strings.TrimSpace(id)
+	if trimmed == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	row := conn(ctx, s.db).QueryRowxContext(ctx, catalogRecordSelect+"where ec.id = $1", trimmed)
+	return scanCatalogRecord(row)
+}
+
 func (s *Catalog) UpdateCatalogEntry(ctx context.Context, id string, entry CatalogEntry, imageData []byte, imageMimeType string, removeImage bool) error {
 	trimmed := strings.TrimSpace(id)
 	if trimmed == "" {
 		return fmt.Errorf("id is required")
 	}
-	tx, err := s.db.BeginTxx(ctx, nil)
+	// Upload first so the row never points at a missing object.
+	imageKey := ""
+	if len(imageData) > 0 {
+		imageKey = blob.CatalogImageKey(trimmed)
+		if err := s.blobs.Put(ctx, imageKey, imageData, strings.TrimSpace(imageMimeType)); err != nil {
+			return fmt.Errorf("store image: %w", err)
+		}
+		putThumbnail(ctx, s.blobs, trimmed, imageData)
+	}
+	tx, err := beginTx(ctx, s.db)
 	if err != nil {
 		return err
 	}
@@ -341,13 +578,24 @@ func (s *Catalog) UpdateCatalogEntry(ctx context.Context, id string, entry Catal
 			_ = tx.Rollback()
 		}
 	}()
-	if err = updateCatalogEntry(ctx, tx, trimmed, entry, imageData, imageMimeType, removeImage); err != nil {
+	if err = updateCatalogEntry(ctx, tx.Tx, trimmed, entry, imageKey, imageMimeType, removeImage); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
 		return err
 	}
-	return tx.Commit()
+	AfterCommit(ctx, s.cache.invalidate)
+	if removeImage && imageKey == "" {
+		for _, key := range []string{blob.CatalogImageKey(trimmed), blob.CatalogThumbnailKey(trimmed)} {
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				log.Printf("catalog image delete error: %v", err)
+			}
+		}
+	}
+	return nil
 }
 
-func updateCatalogEntry(ctx context.Context, tx *sqlx.Tx, id string, entry CatalogEntry, imageData []byte, imageMimeType string, removeImage bool) error {
+func updateCatalogEntry(ctx context.Context, tx *sqlx.Tx, id string, entry CatalogEntry, imageKey string, imageMimeType string, removeImage bool) error {
 
 	name := strings.TrimSpace(entry.Name)
 	if name == "" {
@@ -391,6 +639,12 @@ func updateCatalogEntry(ctx context.Context, tx *sqlx.Tx, id string, entry Catal
 		baseWeight = sql.NullFloat64{Float64: *entry.BaseWeightKg, Valid: true}
 	}
 	secondaries := sanitizeList(entry.SecondaryMuscles)
+	if primaryMuscles, err = canonicalMuscles(ctx, tx, primaryMuscles); err != nil {
+		return err
+	}
+	if secondaries, err = canonicalMuscles(ctx, tx, secondaries); err != nil {
+		return err
+	}
 
 	for _, ref := range []struct {
 		value string
@@ -431,21 +685,29 @@ set name = $2,
     multiplier = coalesce($9, exercise_catalog.multiplier),
     base_weight_kg = coalesce($10, exercise_catalog.base_weight_kg),
     links = $11,
-    image_data = case
-      when octet_length($12::bytea) > 0 then $12
+    image_key = case
+      when $12::text <> '' then $12
       when $13::boolean is true then null
+      else exercise_catalog.image_key
+    end,
+    image_data = case
+      when $12::text <> '' or $13::boolean is true then null
       else exercise_catalog.image_data
     end,
     image_mime_type = case
-      when octet_length($12::bytea) > 0 then nullif($14, '')
+      when $12::text <> '' then nullif($14, '')
       when $13::boolean is true then null
       else exercise_catalog.image_mime_type
-    end
+    end,
+    instructions = $15,
+    cues = $16,
+    common_mistakes = $17
 where id = $1
 returning id
 `
 	var updatedID string
-	if err := tx.QueryRowxContext(ctx, q, id, name, slug, description, typeVal, bodyPart, equipment, level, multiplier, baseWeight, links, imageData, removeImage, strings.TrimSpace(imageMimeType)).Scan(&updatedID); err != nil {
+	if err := tx.QueryRowxContext(ctx, q, id, name, slug, description, typeVal, bodyPart, equipment, level, multiplier, baseWeight, links, imageKey, removeImage, strings.TrimSpace(imageMimeType),
+		sanitizeSteps(entry.Instructions), sanitizeSteps(entry.Cues), sanitizeSteps(entry.CommonMistakes)).Scan(&updatedID); err != nil {
 		return err
 	}
 	if _, err := tx.ExecContext(ctx, `delete from exercise_catalog_primary_muscles where catalog_id = $1`, id); err != nil {
@@ -479,26 +741,99 @@ func (s *Catalog) GetCatalogImage(ctx context.Context, id string) ([]byte, strin
 		return nil, "", fmt.Errorf("id is required")
 	}
 	const q = `
-select image_data, coalesce(image_mime_type, '')
+select coalesce(image_key, ''), image_data, coalesce(image_mime_type, '')
 from exercise_catalog
 where id = $1`
 	var (
+		key      string
 		data     []byte
 		mimeType string
 	)
-	if err := s.db.QueryRowxContext(ctx, q, trimmed).Scan(&data, &mimeType); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, trimmed).Scan(&key, &data, &mimeType); err != nil {
 		return nil, "", err
 	}
+	if key != "" {
+		blobData, blobType, err := s.blobs.Get(ctx, key)
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if mimeType == "" {
+			mimeType = blobType
+		}
+		return blobData, mimeType, nil
+	}
+	if len(data) > 0 {
+		s.migrateLegacyImage(ctx, trimmed, data, mimeType)
+	}
 	return data, mimeType, nil
 }
 
+// GetCatalogThumbnail returns the PNG thumbnail for an entry. Thumbnails are
+// written on upload; entries uploaded before thumbnails existed get one
+// generated from the full image on first request.
+func (s *Catalog) GetCatalogThumbnail(ctx context.Context, id string) ([]byte, string, error) {
+	trimmed := strings.TrimSpace(id)
+	if trimmed == "" {
+		return nil, "", fmt.Errorf("id is required")
+	}
+	data, mimeType, err := s.blobs.Get(ctx, blob.CatalogThumbnailKey(trimmed))
+	if err == nil {
+		return data, mimeType, nil
+	}
+	if !errors.Is(err, blob.ErrNotFound) {
+		return nil, "", err
+	}
+	full, _, err := s.GetCatalogImage(ctx, trimmed)
+	if err != nil || len(full) == 0 {
+		return nil, "", err
+	}
+	thumb := putThumbnail(ctx, s.blobs, trimmed, full)
+	if thumb == nil {
+		// Not decodable as PNG; fall back to the original.
+		return s.GetCatalogImage(ctx, trimmed)
+	}
+	return thumb, "image/png", nil
+}
+
+// putThumbnail renders and saves the thumbnail for an image. Failures are
+// logged only; the full image remains the source of truth.
+func putThumbnail(ctx context.Context, blobs blob.Store, id string, data []byte) []byte {
+	thumb, err := imaging.Thumbnail(data, imaging.ThumbnailSize)
+	if err != nil {
+		log.Printf("catalog thumbnail error id=%s: %v", id, err)
+		return nil
+	}
+	if err := blobs.Put(ctx, blob.CatalogThumbnailKey(id), thumb, "image/png"); err != nil {
+		log.Printf("catalog thumbnail put error id=%s: %v", id, err)
+	}
+	return thumb
+}
+
+// migrateLegacyImage moves an image still stored inline in exercise_catalog
+// into the blob store. Failures are logged and retried on the next read.
+func (s *Catalog) migrateLegacyImage(ctx context.Context, id string, data []byte, mimeType string) {
+	key := blob.CatalogImageKey(id)
+	if err := s.blobs.Put(ctx, key, data, mimeType); err != nil {
+		log.Printf("catalog image migrate put error id=%s: %v", id, err)
+		return
+	}
+	if _, err := conn(ctx, s.db).ExecContext(ctx, `
+		update exercise_catalog set image_key = $2, image_data = null
+		where id = $1 and image_key is null`, id, key); err != nil {
+		log.Printf("catalog image migrate update error id=%s: %v", id, err)
+	}
+}
+
 func (s *Catalog) DeleteCatalogEntry(ctx context.Context, id string) error {
 	trimmed := strings.TrimSpace(id)
 	if trimmed == "" {
 		return fmt.Errorf("id is required")
 	}
 	const q = `DELETE FROM exercise_catalog WHERE id = $1`
-	result, err := s.db.ExecContext(ctx, q, trimmed)
+	result, err := conn(ctx, s.db).ExecContext(ctx, q, trimmed)
 	if err != nil {
 		return err
 	}
@@ -509,11 +844,12 @@ func (s *Catalog) DeleteCatalogEntry(ctx context.Context, id string) error {
 	if rowsAffected == 0 {
 		return sql.ErrNoRows
 	}
+	AfterCommit(ctx, s.cache.invalidate)
 	return nil
 }
 
 func (s *Catalog) CreateCatalogEntryWithImage(ctx context.Context, entry CatalogEntry, imageData []byte, imageMimeType string) (*CatalogRecord, error) {
-	tx, err := s.db.BeginTxx(ctx, nil)
+	tx, err := beginTx(ctx, s.db)
 	if err != nil {
 		return nil, err
 	}
@@ -522,18 +858,19 @@ func (s *Catalog) CreateCatalogEntryWithImage(ctx context.Context, entry Catalog
 			_ = tx.Rollback()
 		}
 	}()
-	if err = createCatalogEntryWithImage(ctx, tx, entry, imageData, imageMimeType); err != nil {
+	if err = createCatalogEntryWithImage(ctx, tx.Tx, s.blobs, entry, imageData, imageMimeType); err != nil {
 		return nil, err
 	}
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
+	AfterCommit(ctx, s.cache.invalidate)
 	// Get the created entry by slug to return the full record
 	slug := slugify(entry.Name)
 	return s.GetCatalogEntryBySlug(ctx, slug)
 }
 
-func createCatalogEntryWithImage(ctx context.Context, tx *sqlx.Tx, entry CatalogEntry, imageData []byte, imageMimeType string) error {
+func createCatalogEntryWithImage(ctx context.Context, tx *sqlx.Tx, blobs blob.Store, entry CatalogEntry, imageData []byte, imageMimeType string) error {
 	name := strings.TrimSpace(entry.Name)
 	if name == "" {
 		return fmt.Errorf("catalog name is required")
@@ -576,6 +913,12 @@ func createCatalogEntryWithImage(ctx context.Context, tx *sqlx.Tx, entry Catalog
 		baseWeight = sql.NullFloat64{Float64: *entry.BaseWeightKg, Valid: true}
 	}
 	secondaries := sanitizeList(entry.SecondaryMuscles)
+	if primaryMuscles, err = canonicalMuscles(ctx, tx, primaryMuscles); err != nil {
+		return err
+	}
+	if secondaries, err = canonicalMuscles(ctx, tx, secondaries); err != nil {
+		return err
+	}
 
 	for _, ref := range []struct {
 		value string
@@ -605,8 +948,9 @@ func createCatalogEntryWithImage(ctx context.Context, tx *sqlx.Tx, entry Catalog
 		links = []string{}
 	}
 	const q = `
-insert into exercise_catalog (name, slug, description, type, body_part, equipment, level, multiplier, base_weight_kg, links, image_data, image_mime_type)
-values ($1, $2, $3, $4, $5, $6, $7, coalesce($8, 1), coalesce($9, 0), $10, $11, $12)
+insert into exercise_catalog (name, slug, description, type, body_part, equipment, level, multiplier, base_weight_kg, links,
+                              instructions, cues, common_mistakes)
+values ($1, $2, $3, $4, $5, $6, $7, coalesce($8, 1), coalesce($9, 0), $10, $11, $12, $13)
 on conflict (slug) do update
 set name = excluded.name,
     description = excluded.description,
@@ -617,18 +961,28 @@ set name = excluded.name,
     multiplier = case when $8 is null then exercise_catalog.multiplier else excluded.multiplier end,
     base_weight_kg = case when $9 is null then exercise_catalog.base_weight_kg else excluded.base_weight_kg end,
     links = excluded.links,
-    image_data = case when octet_length($11::bytea) > 0 then $11 else exercise_catalog.image_data end,
-    image_mime_type = case when octet_length($11::bytea) > 0 then nullif($12, '') else exercise_catalog.image_mime_type end
+    instructions = excluded.instructions,
+    cues = excluded.cues,
+    common_mistakes = excluded.common_mistakes
 returning id
 `
 	var catalogID string
-	mimeTypeStr := strings.TrimSpace(imageMimeType)
-	if len(imageData) == 0 {
-		mimeTypeStr = ""
-	}
-	if err := tx.QueryRowxContext(ctx, q, name, slug, description, typeVal, bodyPart, equipment, level, multiplier, baseWeight, links, imageData, mimeTypeStr).Scan(&catalogID); err != nil {
+	if err := tx.QueryRowxContext(ctx, q, name, slug, description, typeVal, bodyPart, equipment, level, multiplier, baseWeight, links,
+		sanitizeSteps(entry.Instructions), sanitizeSteps(entry.Cues), sanitizeSteps(entry.CommonMistakes)).Scan(&catalogID); err != nil {
 		return err
 	}
+	if len(imageData) > 0 {
+		key := blob.CatalogImageKey(catalogID)
+		if err := blobs.Put(ctx, key, imageData, strings.TrimSpace(imageMimeType)); err != nil {
+			return fmt.Errorf("store image: %w", err)
+		}
+		putThumbnail(ctx, blobs, catalogID, imageData)
+		if _, err := tx.ExecContext(ctx, `
+			update exercise_catalog set image_key = $2, image_data = null, image_mime_type = nullif($3, '')
+			where id = $1`, catalogID, key, strings.TrimSpace(imageMimeType)); err != nil {
+			return err
+		}
+	}
 	if _, err := tx.ExecContext(ctx, `delete from exercise_catalog_primary_muscles where catalog_id = $1`, catalogID); err != nil {
 		return err
 	}
@@ -659,111 +1013,34 @@ func (s *Catalog) GetCatalogEntryBySlug(ctx context.Context, slug string) (*Cata
 	if trimmed == "" {
 		return nil, fmt.Errorf("slug is required")
 	}
-	const q = `
-select
-  ec.id,
-  ec.name,
-  ec.slug,
-  ec.description,
-  ec.type,
-  ec.body_part,
-  ec.equipment,
-  ec.level,
-  coalesce((
-    select array_to_json(array_agg(pm.muscle order by pm.muscle))
-    from exercise_catalog_primary_muscles pm
-    where pm.catalog_id = ec.id
-  ), '[]'::json) as primary_json,
-  ec.multiplier,
-  ec.base_weight_kg,
-  coalesce(array_to_json(ec.links), '[]'::json) as links_json,
-  coalesce((
-    select array_to_json(array_agg(sm.muscle order by sm.muscle))
-    from exercise_catalog_secondary_muscles sm
-    where sm.catalog_id = ec.id
-  ), '[]'::json) as secondary_json,
-  case when ec.image_data is not null then true else false end as has_image,
-  ec.created_at,
-  ec.updated_at
-from exercise_catalog ec
-where ec.slug = $1
-`
-	var (
-		record        CatalogRecord
-		description   sql.NullString
-		multiplier    sql.NullFloat64
-		baseWeight    sql.NullFloat64
-		primaryJSON   []byte
-		linksJSON     []byte
-		secondaryJSON []byte
-	)
-	if err := s.db.QueryRowxContext(ctx, q, trimmed).Scan(
-		&record.ID,
-		&record.Name,
-		&record.Slug,
-		&description,
-		&record.Type,
-		&record.BodyPart,
-		&record.Equipment,
-		&record.Level,
-		&primaryJSON,
-		&multiplier,
-		&baseWeight,
-		&linksJSON,
-		&secondaryJSON,
-		&record.HasImage,
-		&record.CreatedAt,
-		&record.UpdatedAt,
-	); err != nil {
-		return nil, err
-	}
-	if description.Valid {
-		record.Description = &description.String
-	}
-	if multiplier.Valid {
-		record.Multiplier = &multiplier.Float64
-	}
-	if baseWeight.Valid {
-		record.BaseWeightKg = &baseWeight.Float64
-	}
-	if err := json.Unmarshal(primaryJSON, &record.PrimaryMuscles); err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(linksJSON, &record.Links); err != nil {
-		return nil, err
-	}
-	if record.Links == nil {
-		record.Links = []string{}
-	}
-	if err := json.Unmarshal(secondaryJSON, &record.SecondaryMuscles); err != nil {
-		return nil, err
-	}
-	if record.PrimaryMuscles == nil {
-		record.PrimaryMuscles = []string{}
-	}
-	if record.SecondaryMuscles == nil {
-		record.SecondaryMuscles = []string{}
-	}
-	return &record, nil
+	row := conn(ctx, s.db).QueryRowxContext(ctx, catalogRecordSelect+"where ec.slug = $1", trimmed)
+	return scanCatalogRecord(row)
 }
 
 type ExerciseStats struct {
 	HighestWeightKg float64              `json:"highestWeightKg"`
 	History         []ExerciseHistoryItem `json:"history"`
+	// Best estimated 1RM across all logged working sets, not just this page.
+	Formula      progression.Formula `json:"formula"`
+	BestE1RMKg   float64             `json:"bestE1rmKg"`
+	BestE1RMDate *string             `json:"bestE1rmDate,omitempty"`
 }
 
 type ExerciseHistoryItem struct {
 	WorkoutDate string       `json:"workoutDate"`
 	Sets        []SetHistory `json:"sets"`
+	// Highest estimated 1RM among the session's working sets.
+	E1RMKg float64 `json:"e1rmKg"`
 }
 
 type SetHistory struct {
-	Reps     int     `json:"reps"`
-	WeightKg float64 `json:"weightKg"`
-	IsWarmup bool    `json:"isWarmup"`
+	Reps         int     `json:"reps"`
+	AssistedReps int     `json:"assistedReps"`
+	WeightKg     float64 `json:"weightKg"`
+	IsWarmup     bool    `json:"isWarmup"`
 }
 
-func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID string, limit, offset int) (*ExerciseStats, bool, error) {
+func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID string, limit, offset int, formula progression.Formula) (*ExerciseStats, bool, error) {
 	trimmed := strings.TrimSpace(catalogID)
 	if trimmed == "" {
 		return nil, false, fmt.Errorf("catalog id is required")
@@ -772,15 +1049,18 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 		return nil, false, fmt.Errorf("user id is required")
 	}
 
-	// Get highest weight
+	// Get highest weight from the per-exercise daily rollup
 	const highestWeightQ = `
-	select max(s.weight_kg) as highest_weight
-	from sets s
-	join exercises e on e.id = s.exercise_id
-	where e.catalog_id = $1 and s.user_id = $2 and s.is_warmup = false
+	select max(top_weight_kg) as highest_weight
+	from stats_exercise_daily
+	where catalog_id = $1 and user_id = $2
 	`
 	var highestWeight sql.NullFloat64
-	if err := s.db.QueryRowxContext(ctx, highestWeightQ, trimmed, userID).Scan(&highestWeight); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, highestWeightQ, trimmed, userID).Scan(&highestWeight); err != nil {
+		return nil, false, err
+	}
+	bestE1RM, bestE1RMDate, err := s.bestOneRepMax(ctx, trimmed, userID, formula)
+	if err != nil {
 		return nil, false, err
 	}
 
@@ -794,7 +1074,7 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 	order by d.workout_date desc
 	limit $3 offset $4
 	`
-	dateRows, err := s.db.QueryxContext(ctx, datesQ, trimmed, userID, limit, offset)
+	dateRows, err := conn(ctx, s.db).QueryxContext(ctx, datesQ, trimmed, userID, limit, offset)
 	if err != nil {
 		return nil, false, err
 	}
@@ -820,6 +1100,9 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 		stats := &ExerciseStats{
 			HighestWeightKg: 0,
 			History:         []ExerciseHistoryItem{},
+			Formula:         formula,
+			BestE1RMKg:      bestE1RM,
+			BestE1RMDate:    bestE1RMDate,
 		}
 		if highestWeight.Valid {
 			stats.HighestWeightKg = highestWeight.Float64
@@ -841,6 +1124,7 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 	select 
 		d.workout_date,
 		s.reps,
+		s.assisted_reps,
 		s.weight_kg,
 		s.is_warmup
 	from sets s
@@ -850,7 +1134,7 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 	order by d.workout_date desc, s.position asc
 	`, strings.Join(datePlaceholders, ","))
 
-	rows, err := s.db.QueryxContext(ctx, historyQ, args...)
+	rows, err := conn(ctx, s.db).QueryxContext(ctx, historyQ, args...)
 	if err != nil {
 		return nil, false, err
 	}
@@ -859,17 +1143,18 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 	historyMap := make(map[string][]SetHistory)
 	for rows.Next() {
 		var workoutDate time.Time
-		var reps int
+		var reps, assistedReps int
 		var weightKg float64
 		var isWarmup bool
-		if err := rows.Scan(&workoutDate, &reps, &weightKg, &isWarmup); err != nil {
+		if err := rows.Scan(&workoutDate, &reps, &assistedReps, &weightKg, &isWarmup); err != nil {
 			return nil, false, err
 		}
 		dateStr := workoutDate.Format("2006-01-02")
 		historyMap[dateStr] = append(historyMap[dateStr], SetHistory{
-			Reps:     reps,
-			WeightKg: weightKg,
-			IsWarmup: isWarmup,
+			Reps:         reps,
+			AssistedReps: assistedReps,
+			WeightKg:     weightKg,
+			IsWarmup:     isWarmup,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -881,16 +1166,25 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 	for _, date := range dates {
 		dateStr := date.Format("2006-01-02")
 		if sets, ok := historyMap[dateStr]; ok {
-			history = append(history, ExerciseHistoryItem{
+			item := ExerciseHistoryItem{
 				WorkoutDate: dateStr,
 				Sets:        sets,
-			})
+			}
+			for _, set := range sets {
+				if !set.IsWarmup {
+					item.E1RMKg = max(item.E1RMKg, progression.EstimateOneRepMax(formula, set.WeightKg, set.Reps-set.AssistedReps))
+				}
+			}
+			history = append(history, item)
 		}
 	}
 
 	stats := &ExerciseStats{
 		HighestWeightKg: 0,
 		History:         history,
+		Formula:         formula,
+		BestE1RMKg:      bestE1RM,
+		BestE1RMDate:    bestE1RMDate,
 	}
 	if highestWeight.Valid {
 		stats.HighestWeightKg = highestWeight.Float64
@@ -898,3 +1192,40 @@ func (s *Catalog) GetExerciseStats(ctx context.Context, catalogID string, userID
 
 	return stats, hasMore, nil
 }
+
+// bestOneRepMax scans every working set for the catalog entry and returns the
+// highest estimated 1RM and the date it was achieved. Assisted reps don't
+// count towards the estimate.
+func (s *Catalog) bestOneRepMax(ctx context.Context, catalogID, userID string, formula progression.Formula) (float64, *string, error) {
+	const q = `
+	select s.reps - s.assisted_reps, s.weight_kg, s.workout_date
+	from sets s
+	join exercises e on e.id = s.exercise_id
+	where e.catalog_id = $1 and s.user_id = $2 and s.is_warmup = false and s.set_type = 'strength'
+	`
+	rows, err := conn(ctx, s.db).QueryxContext(ctx, q, catalogID, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	var (
+		best     float64
+		bestDate *string
+	)
+	for rows.Next() {
+		var (
+			reps     int
+			weightKg float64
+			date     time.Time
+		)
+		if err := rows.Scan(&reps, &weightKg, &date); err != nil {
+			return 0, nil, err
+		}
+		if v := progression.EstimateOneRepMax(formula, weightKg, reps); v > best {
+			best = v
+			d := date.Format("2006-01-02")
+			bestDate = &d
+		}
+	}
+	return best, bestDate, rows.Err()
+}
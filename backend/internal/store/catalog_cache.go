@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// catalogCache memoises catalog reads in process. Entries expire after ttl
+// and the whole cache is dropped whenever this instance writes to the
+// catalog; writes from other instances become visible once the ttl lapses.
+// A nil *catalogCache is a valid, disabled cache.
+type catalogCache struct {
+	ttl time.Duration
+
+	mu    sync.RWMutex
+	items map[string]cacheItem
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type cacheItem struct {
+	value   any
+	expires time.Time
+}
+
+type CatalogCacheStats struct {
+	Enabled    bool    `json:"enabled"`
+	TTLSeconds float64 `json:"ttlSeconds"`
+	Entries    int     `json:"entries"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	HitRate    float64 `json:"hitRate"`
+}
+
+// EnableCache turns on the in-memory cache for Search, Facets and
+// GetCatalogEntry. A ttl <= 0 leaves caching disabled.
+func (s *Catalog) EnableCache(ttl time.Duration) {
+	if ttl <= 0 {
+		s.cache = nil
+		return
+	}
+	s.cache = &catalogCache{ttl: ttl, items: make(map[string]cacheItem)}
+}
+
+func (s *Catalog) CacheStats() CatalogCacheStats {
+	c := s.cache
+	if c == nil {
+		return CatalogCacheStats{}
+	}
+	c.mu.RLock()
+	n := len(c.items)
+	c.mu.RUnlock()
+	st := CatalogCacheStats{
+		Enabled:    true,
+		TTLSeconds: c.ttl.Seconds(),
+		Entries:    n,
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+	}
+	if total := st.Hits + st.Misses; total > 0 {
+		st.HitRate = roundTo(float64(st.Hits)/float64(total), 4)
+	}
+	return st
+}
+
+func (c *catalogCache) get(key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	it, ok := c.items[key]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(it.expires) {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return it.value, true
+}
+
+func (c *catalogCache) set(key string, v any) {
+	if c == nil {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Opportunistically drop expired entries so distinct search keys don't
+	// accumulate forever.
+	for k, it := range c.items {
+		if now.After(it.expires) {
+			delete(c.items, k)
+		}
+	}
+	c.items[key] = cacheItem{value: v, expires: now.Add(c.ttl)}
+}
+
+func (c *catalogCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.items = make(map[string]cacheItem)
+	c.mu.Unlock()
+}
+
+func (s *Catalog) Facets(ctx context.Context) (CatalogFacets, error) {
+	const key = "facets"
+	if v, ok := s.cache.get(key); ok {
+		return v.(CatalogFacets), nil
+	}
+	f, err := s.facets(ctx)
+	if err != nil {
+		return f, err
+	}
+	s.cache.set(key, f)
+	return f, nil
+}
+
+func (s *Catalog) Search(ctx context.Context, p CatalogSearchParams) (CatalogSearchResult, error) {
+	raw, _ := json.Marshal(p)
+	key := "search:" + string(raw)
+	if v, ok := s.cache.get(key); ok {
+		return v.(CatalogSearchResult), nil
+	}
+	res, err := s.search(ctx, p)
+	if err != nil {
+		return res, err
+	}
+	s.cache.set(key, res)
+	return res, nil
+}
+
+// FacetCounts returns per-value counts for every facet, each scoped to the
+// other filters in p (paging and sort are ignored).
+func (s *Catalog) FacetCounts(ctx context.Context, p CatalogSearchParams) (*CatalogFacetCounts, error) {
+	p.Page, p.PageSize, p.Sort, p.WithFacets = 0, 0, "", false
+	raw, _ := json.Marshal(p)
+	key := "facetCounts:" + string(raw)
+	if v, ok := s.cache.get(key); ok {
+		return v.(*CatalogFacetCounts), nil
+	}
+	counts, err := s.facetCounts(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.set(key, counts)
+	return counts, nil
+}
+
+// GetCatalogEntry returns sql.ErrNoRows when the entry does not exist. The
+// returned record is a copy and safe to modify.
+func (s *Catalog) GetCatalogEntry(ctx context.Context, id string) (*CatalogRecord, error) {
+	key := "entry:" + id
+	if v, ok := s.cache.get(key); ok {
+		rec := *v.(*CatalogRecord)
+		return &rec, nil
+	}
+	rec, err := s.getCatalogEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cached := *rec
+	s.cache.set(key, &cached)
+	return rec, nil
+}
@@ -0,0 +1,23 @@
+package store
+
+import "context"
+
+// ExportCatalog streams every approved catalog entry, ordered by name, to fn.
+// Rows are read from a cursor so the full catalog is never held in memory.
+func (s *Catalog) ExportCatalog(ctx context.Context, fn func(*CatalogRecord) error) error {
+	rows, err := conn(ctx, s.db).QueryxContext(ctx, catalogRecordSelect+"where ec.status = 'approved' order by ec.name")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rec, err := scanCatalogRecord(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"exercise-tracker/internal/blob"
+)
+
+// CatalogIDsWithoutImage returns, keyed by name, the ids of the catalog
+// entries named in names that have no image. Names are matched by slug.
+func (s *Catalog) CatalogIDsWithoutImage(ctx context.Context, names []string) (map[string]string, error) {
+	bySlug := make(map[string][]string, len(names))
+	slugs := make([]string, 0, len(names))
+	for _, name := range names {
+		slug := slugify(strings.TrimSpace(name))
+		if slug == "" {
+			continue
+		}
+		if _, ok := bySlug[slug]; !ok {
+			slugs = append(slugs, slug)
+		}
+		bySlug[slug] = append(bySlug[slug], name)
+	}
+	out := make(map[string]string)
+	if len(slugs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		ID   string `db:"id"`
+		Slug string `db:"slug"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select id, slug from exercise_catalog
+		where slug = any($1::text[]) and image_key is null and image_data is null`, slugs); err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		for _, name := range bySlug[r.Slug] {
+			out[name] = r.ID
+		}
+	}
+	return out, nil
+}
+
+// PutCatalogImage stores data as the image of catalog entry id, with a
+// thumbnail, replacing any image it had.
+func (s *Catalog) PutCatalogImage(ctx context.Context, id string, data []byte, mimeType string) error {
+	key := blob.CatalogImageKey(id)
+	if err := s.blobs.Put(ctx, key, data, strings.TrimSpace(mimeType)); err != nil {
+		return err
+	}
+	putThumbnail(ctx, s.blobs, id, data)
+	if _, err := conn(ctx, s.db).ExecContext(ctx, `
+		update exercise_catalog set image_key = $2, image_data = null, image_mime_type = nullif($3, '')
+		where id = $1`, id, key, strings.TrimSpace(mimeType)); err != nil {
+		return err
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return nil
+}
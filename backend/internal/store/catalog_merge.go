@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrCatalogMergeSelf is returned when an entry is merged into itself.
+var ErrCatalogMergeSelf = errors.New("source and target must differ")
+
+type CatalogMergeResult struct {
+	TargetID         string `json:"targetId"`
+	Exercises        int64  `json:"exercises"`
+	ProgramExercises int64  `json:"programExercises"`
+	Links            int64  `json:"links"`
+}
+
+// MergeCatalogEntries folds sourceID into targetID in one transaction:
+// logged and programmed exercises are re-pointed (and renamed) to the target,
+// muscles and links are unioned into it, and the source is deleted. Returns
+// sql.ErrNoRows when either entry does not exist.
+func (s *Catalog) MergeCatalogEntries(ctx context.Context, sourceID, targetID string) (res *CatalogMergeResult, err error) {
+	if sourceID == targetID {
+		return nil, ErrCatalogMergeSelf
+	}
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// Lock both rows so a concurrent edit or merge can't interleave.
+	var found int
+	if err = tx.GetContext(ctx, &found, `
+		select count(*) from (
+		  select id from exercise_catalog where id = any($1::uuid[]) for update
+		) locked`, []string{sourceID, targetID}); err != nil {
+		return nil, err
+	}
+	if found != 2 {
+		err = sql.ErrNoRows
+		return nil, err
+	}
+
+	res = &CatalogMergeResult{TargetID: targetID}
+	var moved []string
+	if err = tx.SelectContext(ctx, &moved, `
+		update exercises e set catalog_id = $2, name = c.name
+		from exercise_catalog c
+		where e.catalog_id = $1 and c.id = $2
+		returning e.id`, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	res.Exercises = int64(len(moved))
+	if len(moved) > 0 {
+		// The target may differ in multiplier or equipment.
+		if _, err = tx.ExecContext(ctx, `
+			update sets set effective_load_kg = bodyweight_load(exercise_id, user_id, workout_date, weight_kg)
+			where exercise_id = any($1::uuid[])`, moved); err != nil {
+			return nil, err
+		}
+	}
+	r, err := tx.ExecContext(ctx, `update program_exercises set catalog_id = $2 where catalog_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	res.ProgramExercises, _ = r.RowsAffected()
+	if r, err = tx.ExecContext(ctx, `update exercise_links set catalog_id = $2 where catalog_id = $1`, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	res.Links, _ = r.RowsAffected()
+
+	for _, q := range []string{
+		`insert into exercise_catalog_primary_muscles (catalog_id, muscle)
+		 select $2, muscle from exercise_catalog_primary_muscles where catalog_id = $1
+		 on conflict do nothing`,
+		// A muscle primary on either side stays primary only.
+		`insert into exercise_catalog_secondary_muscles (catalog_id, muscle)
+		 select $2, sm.muscle from exercise_catalog_secondary_muscles sm
+		 where sm.catalog_id = $1
+		   and not exists (select 1 from exercise_catalog_primary_muscles pm where pm.catalog_id = $2 and pm.muscle = sm.muscle)
+		 on conflict do nothing`,
+		`delete from exercise_catalog_secondary_muscles sm
+		 using exercise_catalog_primary_muscles pm
+		 where sm.catalog_id = $2 and pm.catalog_id = $2 and pm.muscle = sm.muscle`,
+		`update exercise_catalog t
+		 set links = array(select distinct unnest(t.links || s.links)),
+		     description = coalesce(t.description, s.description),
+		     updated_at = now()
+		 from exercise_catalog s
+		 where t.id = $2 and s.id = $1`,
+		`update goals set catalog_id = $2 where catalog_id = $1`,
+		`delete from exercise_catalog where id = $1`,
+	} {
+		if _, err = tx.ExecContext(ctx, q, sourceID, targetID); err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return res, nil
+}
+
+type CatalogDuplicate struct {
+	ID         string  `db:"id" json:"id"`
+	Name       string  `db:"name" json:"name"`
+	Equipment  string  `db:"equipment" json:"equipment"`
+	Uses       int     `db:"uses" json:"uses"`
+	OtherID    string  `db:"other_id" json:"otherId"`
+	OtherName  string  `db:"other_name" json:"otherName"`
+	OtherEquip string  `db:"other_equipment" json:"otherEquipment"`
+	OtherUses  int     `db:"other_uses" json:"otherUses"`
+	Similarity float64 `db:"similarity" json:"similarity"`
+}
+
+// DuplicateCandidates lists pairs of entries whose names have a trigram
+// similarity of at least minSimilarity, most similar first. Uses counts the
+// logged exercises pointing at each side, to help pick the merge target.
+func (s *Catalog) DuplicateCandidates(ctx context.Context, minSimilarity float64, limit int) ([]CatalogDuplicate, error) {
+	const q = `
+		with pairs as (
+		  select a.id, a.name, a.equipment, b.id as other_id, b.name as other_name, b.equipment as other_equipment,
+		         similarity(a.name, b.name)::float8 as similarity
+		  from exercise_catalog a
+		  join exercise_catalog b on a.id < b.id and a.name % b.name
+		  where similarity(a.name, b.name) >= $1
+		  order by similarity desc, a.name
+		  limit $2
+		)
+		select p.*,
+		       (select count(*) from exercises e where e.catalog_id = p.id) as uses,
+		       (select count(*) from exercises e where e.catalog_id = p.other_id) as other_uses
+		from pairs p
+		order by p.similarity desc, p.name
+	`
+	out := []CatalogDuplicate{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, q, minSimilarity, limit); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrMuscleMergeSelf is returned when a muscle is merged into itself.
+var ErrMuscleMergeSelf = errors.New("source and target must differ")
+
+// CanonicalMuscle is a muscle_types name with the spellings that resolve to
+// it and how many catalog entries use it.
+type CanonicalMuscle struct {
+	Name          string   `db:"name" json:"name"`
+	Aliases       []string `db:"-" json:"aliases"`
+	PrimaryUses   int      `db:"primary_uses" json:"primaryUses"`
+	SecondaryUses int      `db:"secondary_uses" json:"secondaryUses"`
+}
+
+type MuscleMergeResult struct {
+	Target string `json:"target"`
+	// Catalog entries re-pointed from the source, per role.
+	Primary   int64    `json:"primary"`
+	Secondary int64    `json:"secondary"`
+	Aliases   []string `json:"aliases"`
+}
+
+// canonicalMuscles maps names through canonical_muscle(), keeping their
+// order and dropping the duplicates that resolve to the same muscle.
+func canonicalMuscles(ctx context.Context, q sqlx.QueryerContext, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return names, nil
+	}
+	var out []string
+	if err := sqlx.SelectContext(ctx, q, &out, `
+		select canonical_muscle(u.name)
+		from unnest($1::text[]) with ordinality as u(name, ord)
+		order by u.ord`, names); err != nil {
+		return nil, err
+	}
+	return sanitizeList(out), nil
+}
+
+// Muscles lists the canonical muscles by name, with their aliases.
+func (s *Catalog) Muscles(ctx context.Context) ([]CanonicalMuscle, error) {
+	var muscles []CanonicalMuscle
+	if err := conn(ctx, s.db).SelectContext(ctx, &muscles, `
+		select mt.name,
+		       (select count(*) from exercise_catalog_primary_muscles pm where pm.muscle = mt.name) as primary_uses,
+		       (select count(*) from exercise_catalog_secondary_muscles sm where sm.muscle = mt.name) as secondary_uses
+		from muscle_types mt
+		order by mt.name`); err != nil {
+		return nil, err
+	}
+	var aliases []struct {
+		Alias  string `db:"alias"`
+		Muscle string `db:"muscle"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &aliases, `select alias, muscle from muscle_aliases order by alias`); err != nil {
+		return nil, err
+	}
+	byName := make(map[string][]string, len(aliases))
+	for _, a := range aliases {
+		byName[a.Muscle] = append(byName[a.Muscle], a.Alias)
+	}
+	for i := range muscles {
+		muscles[i].Aliases = byName[muscles[i].Name]
+		if muscles[i].Aliases == nil {
+			muscles[i].Aliases = []string{}
+		}
+	}
+	if muscles == nil {
+		muscles = []CanonicalMuscle{}
+	}
+	return muscles, nil
+}
+
+// MergeMuscles folds the muscle source into target in one transaction:
+// catalog entries using source are re-pointed to target (a muscle primary on
+// either side stays primary only), source's aliases move to target, source
+// itself becomes an alias and is deleted. Later imports spelling it that way
+// resolve to target. Returns sql.ErrNoRows when either muscle does not exist.
+func (s *Catalog) MergeMuscles(ctx context.Context, source, target string) (res *MuscleMergeResult, err error) {
+	source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+	if source == target {
+		return nil, ErrMuscleMergeSelf
+	}
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var found int
+	if err = tx.GetContext(ctx, &found, `
+		select count(*) from (
+		  select name from muscle_types where name = any($1::text[]) for update
+		) locked`, []string{source, target}); err != nil {
+		return nil, err
+	}
+	if found != 2 {
+		err = sql.ErrNoRows
+		return nil, err
+	}
+
+	res = &MuscleMergeResult{Target: target}
+	for _, step := range []struct {
+		q     string
+		moved *int64
+	}{
+		{`insert into exercise_catalog_primary_muscles (catalog_id, muscle)
+		  select catalog_id, $2 from exercise_catalog_primary_muscles where muscle = $1
+		  on conflict do nothing`, nil},
+		{`delete from exercise_catalog_primary_muscles where muscle = $1`, &res.Primary},
+		{`insert into exercise_catalog_secondary_muscles (catalog_id, muscle)
+		  select sm.catalog_id, $2 from exercise_catalog_secondary_muscles sm
+		  where sm.muscle = $1
+		    and not exists (select 1 from exercise_catalog_primary_muscles pm where pm.catalog_id = sm.catalog_id and pm.muscle = $2)
+		  on conflict do nothing`, nil},
+		{`delete from exercise_catalog_secondary_muscles where muscle = $1`, &res.Secondary},
+		{`delete from exercise_catalog_secondary_muscles sm
+		  using exercise_catalog_primary_muscles pm
+		  where sm.muscle = $2 and pm.muscle = $2 and pm.catalog_id = sm.catalog_id`, nil},
+		{`update muscle_aliases set muscle = $2 where muscle = $1`, nil},
+		{`insert into muscle_aliases (alias, muscle) values (lower(btrim($1)), $2)
+		  on conflict (alias) do update set muscle = excluded.muscle`, nil},
+		{`delete from muscle_types where name = $1`, nil},
+	} {
+		r, err := tx.ExecContext(ctx, step.q, source, target)
+		if err != nil {
+			return nil, err
+		}
+		if step.moved != nil {
+			*step.moved, _ = r.RowsAffected()
+		}
+	}
+	if err = tx.SelectContext(ctx, &res.Aliases, `select alias from muscle_aliases where muscle = $1 order by alias`, target); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return res, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"exercise-tracker/internal/progression"
+)
+
+// Progress metrics for an exercise's chart series.
+const (
+	ProgressE1RM   = "e1rm"   // best estimated 1RM of the day
+	ProgressTopSet = "topset" // heaviest working set of the day
+	ProgressVolume = "volume" // working-set tonnage of the day
+)
+
+type ProgressPoint struct {
+	Date  string  `db:"date" json:"date"`
+	Value float64 `db:"value" json:"value"`
+}
+
+// e1rmExpr mirrors progression.EstimateOneRepMax in SQL over sets alias s,
+// using the effective load so bodyweight exercises count the body. Assisted
+// reps don't count, and a set with no unassisted reps has no estimate.
+func e1rmExpr(f progression.Formula) string {
+	const (
+		w = `coalesce(s.effective_load_kg, s.weight_kg)`
+		r = `(s.reps - s.assisted_reps)`
+	)
+	switch f {
+	case progression.FormulaBrzycki:
+		return `case when ` + r + ` = 1 then ` + w + ` when ` + r + ` between 2 and 36 then ` + w + ` * 36 / (37 - ` + r + `) end`
+	case progression.FormulaLombardi:
+		return `case when ` + r + ` = 1 then ` + w + ` when ` + r + ` > 1 then ` + w + ` * power(` + r + `, 0.10) end`
+	default:
+		return `case when ` + r + ` = 1 then ` + w + ` when ` + r + ` > 1 then ` + w + ` * (1 + ` + r + ` / 30.0) end`
+	}
+}
+
+// ExerciseProgress returns one value per training day since `since` for the
+// user's sets of a catalog exercise. When there are more than maxPoints days,
+// they are split into maxPoints consecutive buckets and each bucket keeps its
+// highest point, so peaks survive downsampling.
+func (s *Catalog) ExerciseProgress(ctx context.Context, catalogID, userID, metric string, formula progression.Formula, since *time.Time, maxPoints int) ([]ProgressPoint, error) {
+	var value string
+	switch metric {
+	case ProgressE1RM:
+		value = "max(" + e1rmExpr(formula) + ")"
+	case ProgressTopSet:
+		value = "max(coalesce(s.effective_load_kg, s.weight_kg))"
+	case ProgressVolume:
+		value = "sum(s.volume_kg)"
+	default:
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+	q := `
+		with daily as (
+		  select s.workout_date as d, ` + value + `::float8 as v
+		  from sets s
+		  join exercises e on e.id = s.exercise_id
+		  where e.catalog_id = $1 and s.user_id = $2 and s.is_warmup = false and s.set_type = 'strength'
+		    and ($3::date is null or s.workout_date >= $3::date)
+		    and s.reps > 0 and coalesce(s.effective_load_kg, s.weight_kg) > 0
+		  group by s.workout_date
+		), bucketed as (
+		  select d, v, ntile($4) over (order by d) as bucket
+		  from daily
+		  where v is not null
+		), ranked as (
+		  select d, v, row_number() over (partition by bucket order by v desc, d desc) as rn
+		  from bucketed
+		)
+		select to_char(d, 'YYYY-MM-DD') as date, round(v::numeric, 2)::float8 as value
+		from ranked
+		where rn = 1
+		order by d
+	`
+	var sinceArg any
+	if since != nil {
+		sinceArg = since.Format("2006-01-02")
+	}
+	out := []ProgressPoint{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, q, catalogID, userID, sinceArg, maxPoints); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
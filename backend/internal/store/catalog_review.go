@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Catalog review states.
+const (
+	CatalogPending  = "pending"
+	CatalogApproved = "approved"
+	CatalogRejected = "rejected"
+)
+
+var (
+	// ErrCatalogNameTaken means another entry already uses the name (or its
+	// slug).
+	ErrCatalogNameTaken = errors.New("a catalog entry with this name already exists")
+	// ErrCatalogNotPending is returned when reviewing an entry that isn't
+	// waiting for review.
+	ErrCatalogNotPending = errors.New("catalog entry is not pending review")
+)
+
+// SubmitCatalogEntry adds a user's entry to the review queue. Resubmitting
+// the name of the user's own rejected entry replaces it and puts it back in
+// the queue.
+func (s *Catalog) SubmitCatalogEntry(ctx context.Context, userID string, entry CatalogEntry, imageData []byte, imageMimeType string) (rec *CatalogRecord, err error) {
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	var existing struct {
+		Status      string  `db:"status"`
+		SubmittedBy *string `db:"submitted_by"`
+	}
+	err = tx.GetContext(ctx, &existing, `
+		select status, submitted_by::text as submitted_by from exercise_catalog
+		where slug = $1 or lower(name) = lower($2)
+		limit 1
+		for update`, slugify(entry.Name), entry.Name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = nil
+	case err != nil:
+		return nil, err
+	case existing.Status != CatalogRejected || existing.SubmittedBy == nil || *existing.SubmittedBy != userID:
+		err = ErrCatalogNameTaken
+		return nil, err
+	}
+	if err = createCatalogEntryWithImage(ctx, tx.Tx, s.blobs, entry, imageData, imageMimeType); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `
+		update exercise_catalog
+		set status = 'pending', submitted_by = $2, review_feedback = null, reviewed_by = null, reviewed_at = null, updated_at = now()
+		where slug = $1`, slugify(entry.Name), userID); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return s.GetCatalogEntryBySlug(ctx, slugify(entry.Name))
+}
+
+// Submissions lists entries submitted by userID, newest first. An empty
+// userID lists every submission; status filters when non-empty.
+func (s *Catalog) Submissions(ctx context.Context, userID, status string) ([]CatalogRecord, error) {
+	rows, err := conn(ctx, s.db).QueryxContext(ctx, catalogRecordSelect+`
+		where ec.submitted_by is not null
+		  and ($1 = '' or ec.submitted_by = nullif($1, '')::uuid)
+		  and ($2 = '' or ec.status = $2)
+		order by ec.created_at desc`, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []CatalogRecord{}
+	for rows.Next() {
+		rec, err := scanCatalogRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rec)
+	}
+	return out, rows.Err()
+}
+
+// ReviewCatalogEntry approves or rejects a pending entry. Returns
+// sql.ErrNoRows when it doesn't exist and ErrCatalogNotPending when it was
+// already reviewed.
+func (s *Catalog) ReviewCatalogEntry(ctx context.Context, id, reviewerID, status string, feedback *string) (*CatalogRecord, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update exercise_catalog
+		set status = $2, review_feedback = $3, reviewed_by = $4, reviewed_at = now(), updated_at = now()
+		where id = $1 and status = 'pending'`, id, status, feedback, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := s.getCatalogEntry(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrCatalogNotPending
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return s.getCatalogEntry(ctx, id)
+}
+
+// VisibleTo reports whether userID may see rec: approved entries are public,
+// anything else only to its author.
+func (rec *CatalogRecord) VisibleTo(userID string) bool {
+	return rec.Status == CatalogApproved || (rec.SubmittedBy != nil && *rec.SubmittedBy == userID)
+}
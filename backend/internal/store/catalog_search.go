@@ -7,16 +7,29 @@ import (
 	"strings"
 )
 
+// CatalogSearchParams filters the catalog. Values within one facet are ORed
+// (IN), facets are ANDed together.
 type CatalogSearchParams struct {
-	Q         string
-	Type      string
-	BodyPart  string
-	Equipment string
-	Level     string
-	Muscle    string
-	Page      int
-	PageSize  int
-	Sort      string
+	Q          string
+	Types      []string
+	BodyParts  []string
+	Equipment  []string
+	Levels     []string
+	Muscles    []string
+	Page       int
+	PageSize   int
+	Sort       string
+	WithFacets bool
+
+	// AvailableEquipment, when non-nil, drops entries needing equipment
+	// outside the list (e.g. a gym profile's). Bodyweight entries always pass.
+	AvailableEquipment []string
+	// ViewerID also surfaces the viewer's own pending submissions; everyone
+	// else only sees approved entries.
+	ViewerID string
+	// Locales, most preferred first, pick a translated name; q also matches
+	// names in these locales.
+	Locales []string
 }
 
 type CatalogFacets struct {
@@ -27,21 +40,21 @@ type CatalogFacets struct {
 	Muscles   []string `json:"muscles"`
 }
 
-func (c *Catalog) Facets(ctx context.Context) (CatalogFacets, error) {
+func (c *Catalog) facets(ctx context.Context) (CatalogFacets, error) {
 	var f CatalogFacets
-	if err := c.db.SelectContext(ctx, &f.Types, `select name from exercise_types order by name`); err != nil {
+	if err := c.reader(ctx).SelectContext(ctx, &f.Types, `select name from exercise_types order by name`); err != nil {
 		return f, err
 	}
-	if err := c.db.SelectContext(ctx, &f.BodyParts, `select name from body_parts order by name`); err != nil {
+	if err := c.reader(ctx).SelectContext(ctx, &f.BodyParts, `select name from body_parts order by name`); err != nil {
 		return f, err
 	}
-	if err := c.db.SelectContext(ctx, &f.Equipment, `select name from equipment_types order by name`); err != nil {
+	if err := c.reader(ctx).SelectContext(ctx, &f.Equipment, `select name from equipment_types order by name`); err != nil {
 		return f, err
 	}
-	if err := c.db.SelectContext(ctx, &f.Levels, `select name from levels order by name`); err != nil {
+	if err := c.reader(ctx).SelectContext(ctx, &f.Levels, `select name from levels order by name`); err != nil {
 		return f, err
 	}
-	if err := c.db.SelectContext(ctx, &f.Muscles, `select name from muscle_types order by name`); err != nil {
+	if err := c.reader(ctx).SelectContext(ctx, &f.Muscles, `select name from muscle_types order by name`); err != nil {
 		return f, err
 	}
 	return f, nil
@@ -59,17 +72,20 @@ type CatalogItem struct {
 	BaseWeightKg     float64  `db:"base_weight_kg" json:"baseWeightKg"`
 	SecondaryMuscles []string `json:"secondaryMuscles,omitempty"`
 	HasImage         bool     `db:"has_image" json:"hasImage"`
+	Status           string   `db:"status" json:"status"`
+	Locale           *string  `db:"locale" json:"locale,omitempty"`
 }
 
 type CatalogSearchResult struct {
-	Items    []CatalogItem `json:"items"`
-	Page     int           `json:"page"`
-	PageSize int           `json:"pageSize"`
-	Total    int           `json:"total"`
-	HasMore  bool          `json:"hasMore"`
+	Items    []CatalogItem       `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
+	Total    int                 `json:"total"`
+	HasMore  bool                `json:"hasMore"`
+	Facets   *CatalogFacetCounts `json:"facets,omitempty"`
 }
 
-func (c *Catalog) Search(ctx context.Context, p CatalogSearchParams) (CatalogSearchResult, error) {
+func (c *Catalog) search(ctx context.Context, p CatalogSearchParams) (CatalogSearchResult, error) {
 	if p.Page <= 0 {
 		p.Page = 1
 	}
@@ -80,52 +96,29 @@ func (c *Catalog) Search(ctx context.Context, p CatalogSearchParams) (CatalogSea
 	if strings.EqualFold(p.Sort, "name_desc") {
 		sort = "name desc"
 	}
-	where := []string{}
 	args := []any{}
 	arg := func(v any) string {
 		args = append(args, v)
 		return fmt.Sprintf("$%d", len(args))
 	}
-	if p.Q != "" {
-		q := "%" + p.Q + "%"
-		where = append(where, fmt.Sprintf("(name ILIKE %s OR COALESCE(description,'') ILIKE %s)", arg(q), arg(q)))
-	}
-	if p.Type != "" {
-		where = append(where, fmt.Sprintf("type = %s", arg(p.Type)))
-	}
-	if p.BodyPart != "" {
-		where = append(where, fmt.Sprintf("body_part = %s", arg(p.BodyPart)))
-	}
-	if p.Equipment != "" {
-		where = append(where, fmt.Sprintf("equipment = %s", arg(p.Equipment)))
-	}
-	if p.Level != "" {
-		where = append(where, fmt.Sprintf("level = %s", arg(p.Level)))
-	}
-	if p.Muscle != "" {
-		where = append(where, fmt.Sprintf(`(exists (
-  select 1 from exercise_catalog_primary_muscles pm
-  where pm.catalog_id = exercise_catalog.id and pm.muscle = %s
-) OR exists (
-  select 1 from exercise_catalog_secondary_muscles sm
-  where sm.catalog_id = exercise_catalog.id and sm.muscle = %s))`, arg(p.Muscle), arg(p.Muscle)))
-	}
+	where := catalogFilters(p, "", arg)
 	cond := ""
 	if len(where) > 0 {
 		cond = "WHERE " + strings.Join(where, " AND ")
 	}
 	// total
 	var total int
-	if err := c.db.QueryRowxContext(ctx, "SELECT count(*) FROM exercise_catalog "+cond, args...).Scan(&total); err != nil {
+	if err := c.reader(ctx).QueryRowxContext(ctx, "SELECT count(*) FROM exercise_catalog "+cond, args...).Scan(&total); err != nil {
 		return CatalogSearchResult{}, err
 	}
 	// items
 	argsItems := append([]any{}, args...)
-	argsItems = append(argsItems, p.PageSize, (p.Page-1)*p.PageSize)
+	argsItems = append(argsItems, p.Locales, p.PageSize, (p.Page-1)*p.PageSize)
+	n := len(args)
 	query := `
 SELECT
   id,
-  name,
+  COALESCE(tr.name, exercise_catalog.name) AS name,
   type,
   body_part,
   equipment,
@@ -142,12 +135,20 @@ SELECT
     FROM exercise_catalog_secondary_muscles sm
     WHERE sm.catalog_id = exercise_catalog.id
   ), '[]'::json) AS secondary_muscles,
-  CASE WHEN image_data IS NOT NULL THEN TRUE ELSE FALSE END AS has_image
+  CASE WHEN image_key IS NOT NULL OR image_data IS NOT NULL THEN TRUE ELSE FALSE END AS has_image,
+  status,
+  tr.locale
 FROM exercise_catalog
+LEFT JOIN LATERAL (
+  SELECT t.name, t.locale FROM catalog_translations t
+  WHERE t.catalog_id = exercise_catalog.id AND t.locale = ANY($` + fmt.Sprint(n+1) + `::text[])
+  ORDER BY array_position($` + fmt.Sprint(n+1) + `::text[], t.locale)
+  LIMIT 1
+) tr ON true
 ` + cond + `
 ORDER BY ` + sort + `
-LIMIT $` + fmt.Sprint(len(args)+1) + ` OFFSET $` + fmt.Sprint(len(args)+2)
-	rows, err := c.db.QueryxContext(ctx, query, argsItems...)
+LIMIT $` + fmt.Sprint(n+2) + ` OFFSET $` + fmt.Sprint(n+3)
+	rows, err := c.reader(ctx).QueryxContext(ctx, query, argsItems...)
 	if err != nil {
 		return CatalogSearchResult{}, err
 	}
@@ -171,6 +172,8 @@ LIMIT $` + fmt.Sprint(len(args)+1) + ` OFFSET $` + fmt.Sprint(len(args)+2)
 			&it.BaseWeightKg,
 			&secondaryJSON,
 			&it.HasImage,
+			&it.Status,
+			&it.Locale,
 		); err != nil {
 			return CatalogSearchResult{}, err
 		}
@@ -188,11 +191,187 @@ LIMIT $` + fmt.Sprint(len(args)+1) + ` OFFSET $` + fmt.Sprint(len(args)+2)
 		}
 		items = append(items, it)
 	}
-	return CatalogSearchResult{
+	res := CatalogSearchResult{
 		Items:    items,
 		Page:     p.Page,
 		PageSize: p.PageSize,
 		Total:    total,
 		HasMore:  p.Page*p.PageSize < total,
-	}, nil
+	}
+	if p.WithFacets {
+		counts, err := c.facetCounts(ctx, p)
+		if err != nil {
+			return CatalogSearchResult{}, err
+		}
+		res.Facets = counts
+	}
+	return res, nil
+}
+
+// Facet names used by catalogFilters to leave one dimension out.
+const (
+	facetType      = "type"
+	facetBodyPart  = "bodyPart"
+	facetEquipment = "equipment"
+	facetLevel     = "level"
+	facetMuscle    = "muscle"
+)
+
+// catalogFilters builds the WHERE conditions for p against exercise_catalog,
+// skipping the facet named by except (used for facet counts, where a
+// dimension's own selection must not narrow its choices).
+func catalogFilters(p CatalogSearchParams, except string, arg func(any) string) []string {
+	where := []string{fmt.Sprintf(
+		"(exercise_catalog.status = 'approved' OR (exercise_catalog.status = 'pending' AND exercise_catalog.submitted_by = nullif(%s, '')::uuid))",
+		arg(p.ViewerID))}
+	if p.Q != "" {
+		q := "%" + p.Q + "%"
+		cond := fmt.Sprintf("exercise_catalog.name ILIKE %s OR COALESCE(exercise_catalog.description,'') ILIKE %s", arg(q), arg(q))
+		if len(p.Locales) > 0 {
+			cond += fmt.Sprintf(` OR exists (
+  select 1 from catalog_translations t
+  where t.catalog_id = exercise_catalog.id and t.locale = any(%s::text[]) and t.name ILIKE %s
+)`, arg(p.Locales), arg(q))
+		}
+		where = append(where, "("+cond+")")
+	}
+	in := func(column string, values []string) string {
+		ph := make([]string, len(values))
+		for i, v := range values {
+			ph[i] = arg(v)
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(ph, ", "))
+	}
+	if len(p.Types) > 0 && except != facetType {
+		where = append(where, in("exercise_catalog.type", p.Types))
+	}
+	if len(p.BodyParts) > 0 && except != facetBodyPart {
+		where = append(where, in("exercise_catalog.body_part", p.BodyParts))
+	}
+	if len(p.Equipment) > 0 && except != facetEquipment {
+		where = append(where, in("exercise_catalog.equipment", p.Equipment))
+	}
+	if p.AvailableEquipment != nil {
+		where = append(where, fmt.Sprintf("(exercise_catalog.equipment = any(%s::text[]) OR exercise_catalog.equipment IN ('Body Only', 'Bodyweight'))", arg(p.AvailableEquipment)))
+	}
+	if len(p.Levels) > 0 && except != facetLevel {
+		where = append(where, in("exercise_catalog.level", p.Levels))
+	}
+	if len(p.Muscles) > 0 && except != facetMuscle {
+		where = append(where, fmt.Sprintf(`(exists (
+  select 1 from exercise_catalog_primary_muscles pm
+  where pm.catalog_id = exercise_catalog.id and %s
+) OR exists (
+  select 1 from exercise_catalog_secondary_muscles sm
+  where sm.catalog_id = exercise_catalog.id and %s))`, in("pm.muscle", p.Muscles), in("sm.muscle", p.Muscles)))
+	}
+	return where
+}
+
+type FacetCount struct {
+	Value string `db:"value" json:"value"`
+	Count int    `db:"count" json:"count"`
+}
+
+type CatalogFacetCounts struct {
+	Types     []FacetCount `json:"types"`
+	BodyParts []FacetCount `json:"bodyParts"`
+	Equipment []FacetCount `json:"equipment"`
+	Levels    []FacetCount `json:"levels"`
+	Muscles   []FacetCount `json:"muscles"`
+}
+
+// facetCounts returns, for every facet, how many entries each value would
+// match given the other active filters. All facets are computed in a single
+// round trip: one grouped select per facet, unioned together.
+func (c *Catalog) facetCounts(ctx context.Context, p CatalogSearchParams) (*CatalogFacetCounts, error) {
+	dims := []struct {
+		facet string
+		expr  string
+		from  string
+	}{
+		{facetType, "exercise_catalog.type", "exercise_catalog"},
+		{facetBodyPart, "exercise_catalog.body_part", "exercise_catalog"},
+		{facetEquipment, "exercise_catalog.equipment", "exercise_catalog"},
+		{facetLevel, "exercise_catalog.level", "exercise_catalog"},
+		{facetMuscle, "m.muscle", `exercise_catalog
+join (
+  select catalog_id, muscle from exercise_catalog_primary_muscles
+  union
+  select catalog_id, muscle from exercise_catalog_secondary_muscles
+) m on m.catalog_id = exercise_catalog.id`},
+	}
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	parts := make([]string, 0, len(dims))
+	for _, d := range dims {
+		where := append(catalogFilters(p, d.facet, arg), d.expr+" is not null")
+		parts = append(parts, fmt.Sprintf(`(select '%s' as facet, %s as value, count(distinct exercise_catalog.id) as count
+from %s
+where %s
+group by 2)`, d.facet, d.expr, d.from, strings.Join(where, " and ")))
+	}
+	q := strings.Join(parts, "\nunion all\n") + "\norder by facet, value"
+
+	var rows []struct {
+		Facet string `db:"facet"`
+		FacetCount
+	}
+	if err := c.reader(ctx).SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	out := &CatalogFacetCounts{
+		Types:     []FacetCount{},
+		BodyParts: []FacetCount{},
+		Equipment: []FacetCount{},
+		Levels:    []FacetCount{},
+		Muscles:   []FacetCount{},
+	}
+	for _, r := range rows {
+		switch r.Facet {
+		case facetType:
+			out.Types = append(out.Types, r.FacetCount)
+		case facetBodyPart:
+			out.BodyParts = append(out.BodyParts, r.FacetCount)
+		case facetEquipment:
+			out.Equipment = append(out.Equipment, r.FacetCount)
+		case facetLevel:
+			out.Levels = append(out.Levels, r.FacetCount)
+		case facetMuscle:
+			out.Muscles = append(out.Muscles, r.FacetCount)
+		}
+	}
+	return out, nil
+}
+
+// CatalogMatch is a catalog entry matched to a free-text exercise name.
+type CatalogMatch struct {
+	ID    string  `db:"id" json:"id"`
+	Name  string  `db:"name" json:"name"`
+	Score float64 `db:"score" json:"score"`
+}
+
+// MatchExercise finds up to limit catalog entries the user can log whose
+// names contain name's words, most similar first. Ties go to the entry the
+// user logs most, then the one logged most overall, so "squat" picks the
+// user's usual squat.
+func (c *Catalog) MatchExercise(ctx context.Context, userID, name string, limit int) ([]CatalogMatch, error) {
+	out := []CatalogMatch{}
+	err := c.reader(ctx).SelectContext(ctx, &out, `
+		select ec.id, ec.name, word_similarity($2, lower(ec.name))::float8 as score
+		from exercise_catalog ec
+		where (ec.status = 'approved' or (ec.status = 'pending' and ec.submitted_by = $1::uuid))
+		  and $2 <% lower(ec.name)
+		order by score desc,
+		         (select count(*) from exercises e join workout_days d on d.id = e.day_id
+		          where e.catalog_id = ec.id and d.user_id = $1::uuid) desc,
+		         (select count(*) from exercises e where e.catalog_id = ec.id) desc,
+		         similarity($2, lower(ec.name)) desc,
+		         ec.name
+		limit $3
+	`, userID, strings.ToLower(name), limit)
+	return out, err
 }
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+func benchmarkCatalogEntries(n int) []CatalogEntry {
+	entries := make([]CatalogEntry, n)
+	for i := range entries {
+		desc := fmt.Sprintf("Benchmark exercise %d", i)
+		entries[i] = CatalogEntry{
+			Name:             fmt.Sprintf("Bench Exercise %05d", i),
+			Description:      &desc,
+			Type:             "Strength",
+			BodyPart:         []string{"Chest", "Back", "Legs", "Shoulders"}[i%4],
+			Equipment:        []string{"Barbell", "Dumbbell", "Cable"}[i%3],
+			Level:            "Intermediate",
+			PrimaryMuscles:   []string{[]string{"Chest", "Lats", "Quadriceps", "Shoulders"}[i%4]},
+			SecondaryMuscles: []string{"Triceps", "Biceps"},
+			Links:            []string{fmt.Sprintf("https://example.com/ex/%d", i)},
+		}
+	}
+	return entries
+}
+
+func TestNewCatalogBatchLastDuplicateWins(t *testing.T) {
+	mult := 0.5
+	entries := []CatalogEntry{
+		{Name: "Bench Press", Type: "Strength", BodyPart: "Chest", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Chest"}},
+		{Name: "Squat", Type: "Strength", BodyPart: "Legs", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Quadriceps"}},
+		{Name: "bench  press", Type: "Strength", BodyPart: "Chest", Equipment: "Barbell", Level: "Expert", PrimaryMuscles: []string{"Chest", "Triceps"}, Multiplier: &mult},
+	}
+	b, err := newCatalogBatch(entries)
+	if err != nil {
+		t.Fatalf("newCatalogBatch: %v", err)
+	}
+	if len(b.slugs) != 2 {
+		t.Fatalf("expected 2 unique slugs, got %v", b.slugs)
+	}
+	if b.slugs[0] != "bench-press" || b.levels[0] != "Expert" || b.multipliers[0] == nil || *b.multipliers[0] != 0.5 {
+		t.Fatalf("expected later duplicate to win, got level=%s multiplier=%v", b.levels[0], b.multipliers[0])
+	}
+	if len(b.primaryMuscles) != 3 {
+		t.Fatalf("expected 3 primary muscle links, got %v", b.primaryMuscles)
+	}
+	if b.links[0] != "[]" {
+		t.Fatalf("expected empty links JSON, got %s", b.links[0])
+	}
+}
+
+func TestNewCatalogBatchRejectsMissingFields(t *testing.T) {
+	_, err := newCatalogBatch([]CatalogEntry{{Name: "No Muscles", Type: "Strength", BodyPart: "Chest", Equipment: "Barbell", Level: "Beginner"}})
+	if err == nil {
+		t.Fatal("expected error for missing primary muscles")
+	}
+}
+
+func BenchmarkNewCatalogBatch(b *testing.B) {
+	entries := benchmarkCatalogEntries(3000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := newCatalogBatch(entries); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCatalogUpsert imports 3,000 entries against a real database. Set
+// TEST_DATABASE_URL to a disposable, migrated database to run it; the
+// benchmark rows are removed afterwards.
+func BenchmarkCatalogUpsert(b *testing.B) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		b.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sqlx.Open("pgx", url)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	catalog := NewCatalog(db, nil)
+	entries := benchmarkCatalogEntries(3000)
+	b.Cleanup(func() {
+		_, _ = db.ExecContext(ctx, `delete from exercise_catalog where slug like 'bench-exercise-%'`)
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := catalog.Upsert(ctx, entries); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
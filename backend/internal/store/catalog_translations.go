@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type CatalogTranslation struct {
+	CatalogID   string    `db:"catalog_id" json:"catalogId"`
+	Locale      string    `db:"locale" json:"locale"`
+	Name        string    `db:"name" json:"name"`
+	Description *string   `db:"description" json:"description,omitempty"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+const catalogTranslationColumns = `catalog_id, locale, name, description, updated_at`
+
+func (s *Catalog) Translations(ctx context.Context, catalogID string) ([]CatalogTranslation, error) {
+	out := []CatalogTranslation{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select `+catalogTranslationColumns+`
+		from catalog_translations
+		where catalog_id = $1
+		order by locale`, catalogID)
+	return out, err
+}
+
+// PutTranslation creates or replaces the entry's translation for locale.
+// Returns sql.ErrNoRows when the entry does not exist.
+func (s *Catalog) PutTranslation(ctx context.Context, t CatalogTranslation) (*CatalogTranslation, error) {
+	var out CatalogTranslation
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into catalog_translations (catalog_id, locale, name, description)
+		select id, $2, $3, $4 from exercise_catalog where id = $1
+		on conflict (catalog_id, locale) do update
+		set name = excluded.name, description = excluded.description, updated_at = now()
+		returning `+catalogTranslationColumns, t.CatalogID, t.Locale, t.Name, t.Description).StructScan(&out)
+	if err != nil {
+		return nil, err
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return &out, nil
+}
+
+func (s *Catalog) DeleteTranslation(ctx context.Context, catalogID, locale string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from catalog_translations where catalog_id = $1 and locale = $2`, catalogID, locale)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	if n > 0 {
+		AfterCommit(ctx, s.cache.invalidate)
+	}
+	return n > 0, nil
+}
+
+// Localize replaces rec's name and description with the first translation
+// found in locales (most preferred first) and sets rec.Locale. rec is left
+// as is when there is none; a translation without a description keeps the
+// English one.
+func (s *Catalog) Localize(ctx context.Context, rec *CatalogRecord, locales []string) error {
+	if len(locales) == 0 {
+		return nil
+	}
+	var t CatalogTranslation
+	err := conn(ctx, s.db).GetContext(ctx, &t, `
+		select `+catalogTranslationColumns+`
+		from catalog_translations
+		where catalog_id = $1 and locale = any($2::text[])
+		order by array_position($2::text[], locale)
+		limit 1`, rec.ID, locales)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rec.Name = t.Name
+	if t.Description != nil {
+		rec.Description = t.Description
+	}
+	rec.Locale = &t.Locale
+	return nil
+}
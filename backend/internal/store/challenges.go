@@ -0,0 +1,254 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Challenge metrics.
+const (
+	ChallengeVolume = "volume" // working-set tonnage
+	ChallengeSets   = "sets"   // working sets
+	ChallengeDays   = "days"   // days with working sets
+	ChallengeStreak = "streak" // longest run of consecutive training days
+)
+
+// Challenge list filters, relative to a given day.
+const (
+	ChallengesActive   = "active"
+	ChallengesUpcoming = "upcoming"
+	ChallengesPast     = "past"
+	ChallengesAll      = "all"
+)
+
+var ErrChallengeEnded = errors.New("challenge has ended")
+
+type Challenges struct {
+	db *sqlx.DB
+}
+
+func NewChallenges(db *sqlx.DB) *Challenges { return &Challenges{db: db} }
+
+// Challenge is a challenge as seen by one user.
+type Challenge struct {
+	ID          string    `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Metric      string    `db:"metric" json:"metric"`
+	CatalogID   *string   `db:"catalog_id" json:"catalogId"`
+	CatalogName *string   `db:"catalog_name" json:"catalogName"`
+	StartsOn    string    `db:"starts_on" json:"startsOn"`
+	EndsOn      string    `db:"ends_on" json:"endsOn"`
+	Public      bool      `db:"is_public" json:"public"`
+	Members     int       `db:"members" json:"members"`
+	Joined      bool      `db:"joined" json:"joined"`
+	IsCreator   bool      `db:"is_creator" json:"isCreator"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+}
+
+// challengeSelect renders challenges for the user $1.
+const challengeSelect = `
+	select c.id, c.name, c.metric, c.catalog_id, ec.name as catalog_name,
+	       to_char(c.starts_on, 'YYYY-MM-DD') as starts_on, to_char(c.ends_on, 'YYYY-MM-DD') as ends_on,
+	       c.is_public,
+	       (select count(*) from challenge_members m where m.challenge_id = c.id) as members,
+	       exists (select 1 from challenge_members m where m.challenge_id = c.id and m.user_id = $1) as joined,
+	       c.creator_id = $1 as is_creator,
+	       c.created_at
+	from challenges c
+	left join exercise_catalog ec on ec.id = c.catalog_id
+`
+
+type ChallengeParams struct {
+	Name      string
+	Metric    string
+	CatalogID *string
+	StartsOn  time.Time
+	EndsOn    time.Time
+	Public    bool
+}
+
+// Create adds a challenge and joins its creator to it. It returns
+// ErrUnknownCatalogEntry for a catalog id that doesn't exist.
+func (s *Challenges) Create(ctx context.Context, userID string, p ChallengeParams) (_ *Challenge, err error) {
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if p.CatalogID != nil {
+		var exists bool
+		if err = tx.GetContext(ctx, &exists, `select exists (select 1 from exercise_catalog where id = $1)`, *p.CatalogID); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrUnknownCatalogEntry
+		}
+	}
+	var id string
+	if err = tx.GetContext(ctx, &id, `
+		insert into challenges (creator_id, name, metric, catalog_id, starts_on, ends_on, is_public)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		returning id
+	`, userID, p.Name, p.Metric, p.CatalogID, p.StartsOn.Format("2006-01-02"), p.EndsOn.Format("2006-01-02"), p.Public); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `insert into challenge_members (challenge_id, user_id) values ($1, $2)`, id, userID); err != nil {
+		return nil, err
+	}
+	var c Challenge
+	if err = tx.QueryRowxContext(ctx, challengeSelect+` where c.id = $2`, userID, id).StructScan(&c); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// List returns the public challenges and the ones the user has joined,
+// filtered by status relative to today: the soonest to end first, or the
+// latest ended first for past ones.
+func (s *Challenges) List(ctx context.Context, userID, status string, today time.Time) ([]Challenge, error) {
+	var filter, order string
+	switch status {
+	case ChallengesActive:
+		filter, order = `c.starts_on <= $2::date and c.ends_on >= $2::date`, `c.ends_on, c.created_at`
+	case ChallengesUpcoming:
+		filter, order = `c.starts_on > $2::date`, `c.starts_on, c.created_at`
+	case ChallengesPast:
+		filter, order = `c.ends_on < $2::date`, `c.ends_on desc, c.created_at desc`
+	case ChallengesAll:
+		filter, order = `$2::date is not null`, `c.ends_on desc, c.created_at desc`
+	default:
+		return nil, fmt.Errorf("unknown status %q", status)
+	}
+	out := []Challenge{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, challengeSelect+`
+		where (c.is_public or exists (select 1 from challenge_members m where m.challenge_id = c.id and m.user_id = $1))
+		  and `+filter+`
+		order by `+order+`
+		limit 200`, userID, today.Format("2006-01-02"))
+	return out, err
+}
+
+// Get returns a challenge by id, or nil, nil if there is none. Private
+// challenges are returned too: their id is the invitation.
+func (s *Challenges) Get(ctx context.Context, userID, id string) (*Challenge, error) {
+	var c Challenge
+	err := conn(ctx, s.db).QueryRowxContext(ctx, challengeSelect+` where c.id = $2`, userID, id).StructScan(&c)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Join adds the user to a challenge that hasn't ended by today; joining
+// twice is a no-op. Returns nil, nil when there is no such challenge.
+func (s *Challenges) Join(ctx context.Context, userID, id string, today time.Time) (*Challenge, error) {
+	c, err := s.Get(ctx, userID, id)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	if c.EndsOn < today.Format("2006-01-02") {
+		return nil, ErrChallengeEnded
+	}
+	if _, err := conn(ctx, s.db).ExecContext(ctx, `
+		insert into challenge_members (challenge_id, user_id) values ($1, $2)
+		on conflict do nothing`, id, userID); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// Leave removes the user from a challenge.
+func (s *Challenges) Leave(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from challenge_members where challenge_id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Delete removes a challenge; only its creator may.
+func (s *Challenges) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from challenges where id = $1 and creator_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ChallengeStanding is one member's progress. Names follow the member's
+// leaderboard profile: nil unless they chose to show a display name.
+type ChallengeStanding struct {
+	Rank  int     `db:"rank" json:"rank"`
+	Name  *string `db:"name" json:"name"`
+	You   bool    `db:"you" json:"you"`
+	Value float64 `db:"value" json:"value"`
+	// LastDate is the member's latest training day in the challenge.
+	LastDate *string `db:"last_date" json:"lastDate"`
+}
+
+// Standings ranks a challenge's members by its metric over its dates, from
+// the stat rollups. Members without training in range rank with 0.
+func (s *Challenges) Standings(ctx context.Context, c *Challenge, viewerID string) ([]ChallengeStanding, error) {
+	const q = `
+		with members as (
+		  select user_id from challenge_members where challenge_id = $1
+		), daily as (
+		  select d.user_id, d.workout_date, d.working_sets, d.tonnage_kg
+		  from stats_daily d
+		  join members m on m.user_id = d.user_id
+		  where $2::uuid is null and d.workout_date between $3::date and $4::date and d.working_sets > 0
+		  union all
+		  select x.user_id, x.workout_date, x.working_sets, x.tonnage_kg
+		  from stats_exercise_daily x
+		  join members m on m.user_id = x.user_id
+		  where x.catalog_id = $2::uuid and x.workout_date between $3::date and $4::date and x.working_sets > 0
+		), runs as (
+		  select user_id, count(*) as length
+		  from (
+		    select user_id, workout_date - (row_number() over (partition by user_id order by workout_date))::int as run
+		    from daily
+		  ) r
+		  group by user_id, run
+		), totals as (
+		  select m.user_id,
+		         case $5
+		           when 'volume' then coalesce(sum(d.tonnage_kg), 0)
+		           when 'sets' then coalesce(sum(d.working_sets), 0)
+		           when 'days' then count(d.workout_date)
+		           else coalesce((select max(length) from runs where runs.user_id = m.user_id), 0)
+		         end::float8 as value,
+		         max(d.workout_date) as last_date
+		  from members m
+		  left join daily d on d.user_id = m.user_id
+		  group by m.user_id
+		)
+		select rank() over (order by t.value desc) as rank,
+		       case when lp.hide_identity then null else lp.display_name end as name,
+		       t.user_id = $6 as you,
+		       round(t.value::numeric, 2)::float8 as value,
+		       to_char(t.last_date, 'YYYY-MM-DD') as last_date
+		from totals t
+		left join leaderboard_profiles lp on lp.user_id = t.user_id
+		order by rank, t.last_date nulls last
+	`
+	out := []ChallengeStanding{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, q, c.ID, c.CatalogID, c.StartsOn, c.EndsOn, c.Metric, viewerID)
+	return out, err
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Checkins struct {
+	db *sqlx.DB
+}
+
+func NewCheckins(db *sqlx.DB) *Checkins { return &Checkins{db: db} }
+
+type Checkin struct {
+	Date       string    `db:"checkin_date" json:"date"`
+	SleepHours *float64  `db:"sleep_hours" json:"sleepHours"`
+	Soreness   *int      `db:"soreness" json:"soreness"`
+	Motivation *int      `db:"motivation" json:"motivation"`
+	Note       *string   `db:"note" json:"note,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type CheckinParams struct {
+	SleepHours *float64
+	Soreness   *int
+	Motivation *int
+	Note       *string
+}
+
+const checkinColumns = `to_char(checkin_date, 'YYYY-MM-DD') as checkin_date, sleep_hours::float8 as sleep_hours,
+	soreness, motivation, note, created_at, updated_at`
+
+// Put records the check-in for a date, replacing any earlier one.
+func (s *Checkins) Put(ctx context.Context, userID string, date time.Time, p CheckinParams) (*Checkin, error) {
+	q := `
+		insert into daily_checkins (user_id, checkin_date, sleep_hours, soreness, motivation, note)
+		values ($1, $2, $3, $4, $5, $6)
+		on conflict (user_id, checkin_date) do update
+		set sleep_hours = excluded.sleep_hours, soreness = excluded.soreness,
+		    motivation = excluded.motivation, note = excluded.note, updated_at = now()
+		returning ` + checkinColumns
+	var out Checkin
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, date.Format("2006-01-02"),
+		p.SleepHours, p.Soreness, p.Motivation, p.Note).StructScan(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns check-ins between from and to (inclusive, either may be nil),
+// newest first.
+func (s *Checkins) List(ctx context.Context, userID string, from, to *time.Time) ([]Checkin, error) {
+	out := []Checkin{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select `+checkinColumns+`
+		from daily_checkins
+		where user_id = $1
+		  and ($2::date is null or checkin_date >= $2::date)
+		  and ($3::date is null or checkin_date <= $3::date)
+		order by checkin_date desc`, userID, dateArg(from), dateArg(to))
+	return out, err
+}
+
+func (s *Checkins) Delete(ctx context.Context, userID string, date time.Time) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from daily_checkins where user_id = $1 and checkin_date = $2`,
+		userID, date.Format("2006-01-02"))
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	CoachLinkPending = "pending"
+	CoachLinkActive  = "active"
+)
+
+var ErrSelfCoach = errors.New("cannot coach yourself")
+
+type Coaching struct {
+	db *sqlx.DB
+}
+
+func NewCoaching(db *sqlx.DB) *Coaching { return &Coaching{db: db} }
+
+type CoachLink struct {
+	ID          string     `db:"id" json:"id"`
+	ClientID    string     `db:"client_id" json:"clientId"`
+	ClientEmail string     `db:"client_email" json:"clientEmail"`
+	CoachEmail  string     `db:"coach_email" json:"coachEmail"`
+	CoachID     *string    `db:"coach_id" json:"coachId,omitempty"`
+	CanWrite    bool       `db:"can_write" json:"canWrite"`
+	Status      string     `db:"status" json:"status"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+	AcceptedAt  *time.Time `db:"accepted_at" json:"acceptedAt,omitempty"`
+}
+
+const coachLinkSelect = `
+	select l.id, l.client_id, u.email as client_email, l.coach_email, l.coach_id,
+	       l.can_write, l.status, l.created_at, l.accepted_at
+	from coach_links l
+	join users u on u.id = l.client_id
+`
+
+// Invite creates (or updates the access level of) a coach invitation from
+// clientID to coachEmail. Re-inviting an active coach keeps the link active.
+func (s *Coaching) Invite(ctx context.Context, clientID, coachEmail string, canWrite bool) (*CoachLink, error) {
+	coachEmail = strings.ToLower(strings.TrimSpace(coachEmail))
+	var clientEmail string
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `select email from users where id = $1`, clientID).Scan(&clientEmail); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(clientEmail, coachEmail) {
+		return nil, ErrSelfCoach
+	}
+	var id string
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into coach_links (client_id, coach_email, can_write)
+		values ($1, $2, $3)
+		on conflict (client_id, coach_email) do update set can_write = excluded.can_write
+		returning id
+	`, clientID, coachEmail, canWrite).Scan(&id); err != nil {
+		return nil, err
+	}
+	return s.byID(ctx, id)
+}
+
+func (s *Coaching) byID(ctx context.Context, id string) (*CoachLink, error) {
+	var l CoachLink
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, coachLinkSelect+` where l.id = $1`, id).StructScan(&l); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Coaches lists the links a client has created, pending or active.
+func (s *Coaching) Coaches(ctx context.Context, clientID string) ([]CoachLink, error) {
+	out := []CoachLink{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, coachLinkSelect+` where l.client_id = $1 order by l.created_at`, clientID)
+	return out, err
+}
+
+// Clients lists the links addressed to a coach: active ones by coach id and
+// pending invitations by email.
+func (s *Coaching) Clients(ctx context.Context, coachID, coachEmail string) ([]CoachLink, error) {
+	out := []CoachLink{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, coachLinkSelect+`
+		where l.coach_id = $1 or (l.status = 'pending' and l.coach_email = $2)
+		order by l.status, u.email`, coachID, coachEmail)
+	return out, err
+}
+
+// Accept activates a pending invitation addressed to coachEmail. Returns nil,
+// nil when there is no such invitation.
+func (s *Coaching) Accept(ctx context.Context, linkID, coachID, coachEmail string) (*CoachLink, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update coach_links
+		set coach_id = $2, status = 'active', accepted_at = now()
+		where id = $1 and coach_email = $3 and status = 'pending'
+	`, linkID, coachID, coachEmail)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return s.byID(ctx, linkID)
+}
+
+// Remove deletes a link; either the client or the coach may end it.
+func (s *Coaching) Remove(ctx context.Context, linkID, userID, userEmail string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		delete from coach_links
+		where id = $1 and (client_id = $2 or coach_id = $2 or coach_email = $3)
+	`, linkID, userID, userEmail)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Access reports whether coachID has an active link to clientID and whether
+// it allows writes.
+func (s *Coaching) Access(ctx context.Context, coachID, clientID string) (canWrite bool, ok bool, err error) {
+	err = conn(ctx, s.db).QueryRowxContext(ctx, `
+		select can_write from coach_links
+		where coach_id = $1 and client_id = $2 and status = 'active'
+	`, coachID, clientID).Scan(&canWrite)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return canWrite, true, nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/models"
+)
+
+var ErrCommentTarget = errors.New("exercise does not belong to this day")
+
+type Comments struct {
+	db *sqlx.DB
+}
+
+func NewComments(db *sqlx.DB) *Comments { return &Comments{db: db} }
+
+const commentSelect = `
+	select c.id, c.day_id, c.exercise_id, c.author_id, u.email as author_email, c.body, c.created_at, c.read_at
+	from workout_comments c
+	join users u on u.id = c.author_id
+`
+
+// ListByDay returns a day's comments oldest first, or nil, nil when the day
+// doesn't belong to ownerID.
+func (s *Comments) ListByDay(ctx context.Context, ownerID, dayID string) ([]models.WorkoutComment, error) {
+	if ok, err := s.ownsDay(ctx, ownerID, dayID); err != nil || !ok {
+		return nil, err
+	}
+	out := []models.WorkoutComment{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, commentSelect+` where c.day_id = $1 order by c.created_at`, dayID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create adds a comment by authorID to ownerID's day. Returns nil, nil when
+// the day doesn't belong to ownerID.
+func (s *Comments) Create(ctx context.Context, ownerID, authorID, dayID string, exerciseID *string, body string) (*models.WorkoutComment, error) {
+	if ok, err := s.ownsDay(ctx, ownerID, dayID); err != nil || !ok {
+		return nil, err
+	}
+	if exerciseID != nil {
+		var ok bool
+		if err := conn(ctx, s.db).QueryRowxContext(ctx, `select exists (select 1 from exercises where id = $1 and day_id = $2)`, *exerciseID, dayID).Scan(&ok); err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrCommentTarget
+		}
+	}
+	// The owner's own comments never count as unread.
+	var id string
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into workout_comments (day_id, exercise_id, author_id, body, read_at)
+		values ($1, $2, $3, $4, case when $3 = $5 then now() end)
+		returning id
+	`, dayID, exerciseID, authorID, body, ownerID).Scan(&id); err != nil {
+		return nil, err
+	}
+	var c models.WorkoutComment
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, commentSelect+` where c.id = $1`, id).StructScan(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Delete removes a comment written by authorID.
+func (s *Comments) Delete(ctx context.Context, authorID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from workout_comments where id = $1 and author_id = $2`, id, authorID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MarkRead marks every unread comment on ownerID's day as read.
+func (s *Comments) MarkRead(ctx context.Context, ownerID, dayID string) (int64, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update workout_comments c set read_at = now()
+		from workout_days d
+		where c.day_id = d.id and d.id = $1 and d.user_id = $2 and c.read_at is null
+	`, dayID, ownerID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+type UnreadCommentCount struct {
+	DayID       string `db:"day_id" json:"dayId"`
+	WorkoutDate string `db:"workout_date" json:"workoutDate"`
+	Count       int    `db:"count" json:"count"`
+}
+
+// UnreadCounts returns per-day unread comment counts on ownerID's days.
+func (s *Comments) UnreadCounts(ctx context.Context, ownerID string) ([]UnreadCommentCount, error) {
+	out := []UnreadCommentCount{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select d.id as day_id, to_char(d.workout_date, 'YYYY-MM-DD') as workout_date, count(*) as count
+		from workout_comments c
+		join workout_days d on d.id = c.day_id
+		where d.user_id = $1 and c.read_at is null
+		group by d.id, d.workout_date
+		order by d.workout_date desc
+	`, ownerID)
+	return out, err
+}
+
+func (s *Comments) ownsDay(ctx context.Context, ownerID, dayID string) (bool, error) {
+	var ok bool
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `select exists (select 1 from workout_days where id = $1 and user_id = $2)`, dayID, ownerID).Scan(&ok)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return ok, err
+}
@@ -6,13 +6,16 @@ import (
 	"errors"
 	"time"
 
-	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 
 	"exercise-tracker/internal/models"
 )
 
-var ErrRestDayHasExercises = errors.New("workout day still has exercises")
+var (
+	ErrRestDayHasExercises = errors.New("workout day still has exercises")
+	ErrSessionOrder        = errors.New("session cannot finish before it starts")
+)
 
 type Days struct {
 	db *sqlx.DB
@@ -35,12 +38,12 @@ func (s *Days) GetOrCreate(ctx context.Context, userID string, date time.Time) (
 
 func (s *Days) GetByUserAndDate(ctx context.Context, userID string, date time.Time) (*models.WorkoutDay, error) {
 	const q = `
-		select id, user_id, workout_date, timezone, notes, is_rest_day, created_at, updated_at
+		select id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
 		from workout_days
 		where user_id = $1 and workout_date = $2
 	`
 	d := new(models.WorkoutDay)
-	if err := s.db.QueryRowxContext(ctx, q, userID, date).StructScan(d); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, date).StructScan(d); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -54,10 +57,28 @@ func (s *Days) Create(ctx context.Context, userID string, date time.Time) (*mode
 		insert into workout_days (user_id, workout_date)
 		values ($1, $2)
 		on conflict (user_id, workout_date) do update set workout_date = excluded.workout_date
-		returning id, user_id, workout_date, timezone, notes, is_rest_day, created_at, updated_at
+		returning id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
 	`
 	d := new(models.WorkoutDay)
-	if err := s.db.QueryRowxContext(ctx, q, userID, date).StructScan(d); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, date).StructScan(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+const daySelect = `
+	select id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
+	from workout_days
+`
+
+// GetForUpdate returns one of the user's days and locks it until the
+// request transaction ends, or nil, nil if it isn't theirs.
+func (s *Days) GetForUpdate(ctx context.Context, userID, dayID string) (*models.WorkoutDay, error) {
+	d := new(models.WorkoutDay)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, daySelect+` where id = $1 and user_id = $2 for update`, dayID, userID).StructScan(d); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return d, nil
@@ -65,48 +86,218 @@ func (s *Days) Create(ctx context.Context, userID string, date time.Time) (*mode
 
 func (s *Days) GetWithDetails(ctx context.Context, userID, dayID string) (*models.DayWithDetails, error) {
 	day := new(models.WorkoutDay)
-	if err := s.db.QueryRowxContext(ctx,
-		`select id, user_id, workout_date, timezone, notes, is_rest_day, created_at, updated_at
-		 from workout_days where id = $1 and user_id = $2`, dayID, userID).StructScan(day); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, daySelect+` where id = $1 and user_id = $2`, dayID, userID).StructScan(day); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-
-	// Load exercises
-	rows, err := s.db.QueryxContext(ctx, `
-		select id, day_id, catalog_id, name, position, comment, created_at, updated_at
-		from exercises
-		where day_id = $1
-		order by position, created_at`, dayID)
+	out, err := s.withDetails(ctx, []models.WorkoutDay{*day})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return &out[0], nil
+}
+
+// ListWithDetails returns the user's days matching any of dayIDs or dates,
+// oldest first. Unknown ids and dates without a day are skipped.
+func (s *Days) ListWithDetails(ctx context.Context, userID string, dayIDs []string, dates []time.Time) ([]models.DayWithDetails, error) {
+	dateStrs := make([]string, len(dates))
+	for i, d := range dates {
+		dateStrs[i] = d.Format("2006-01-02")
+	}
+	var days []models.WorkoutDay
+	if err := conn(ctx, s.db).SelectContext(ctx, &days, daySelect+`
+		where user_id = $1 and (id::text = any($2::text[]) or workout_date = any($3::date[]))
+		order by workout_date`, userID, dayIDs, dateStrs); err != nil {
+		return nil, err
+	}
+	return s.withDetails(ctx, days)
+}
+
+// withDetails loads exercises, sets, rests and comments for days with one
+// query each and groups them by day and exercise.
+func (s *Days) withDetails(ctx context.Context, days []models.WorkoutDay) ([]models.DayWithDetails, error) {
+	out := make([]models.DayWithDetails, len(days))
+	if len(days) == 0 {
+		return out, nil
+	}
+	ids := make([]string, len(days))
+	for i, d := range days {
+		ids[i] = d.ID
+	}
 
 	var exercises []models.Exercise
-	for rows.Next() {
-		var ex models.Exercise
-		if err := rows.StructScan(&ex); err != nil {
-			return nil, err
+	if err := conn(ctx, s.db).SelectContext(ctx, &exercises, `
+		select id, day_id, catalog_id, name, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg, created_at, updated_at
+		from exercises
+		where day_id = any($1::uuid[])
+		order by position, created_at`, ids); err != nil {
+		return nil, err
+	}
+	var sets []models.Set
+	if err := conn(ctx, s.db).SelectContext(ctx, &sets, `
+		select s.id, s.exercise_id, s.user_id, s.workout_date, s.position, s.reps, s.weight_kg, s.rpe, s.rir,
+		       s.is_warmup, s.rest_seconds, s.tempo, s.performed_at, s.set_type, s.duration_seconds, s.distance_m, s.side, s.to_failure, s.assisted_reps, s.partial_reps,
+		       s.volume_kg, coalesce(s.effective_load_kg, s.weight_kg) as effective_load_kg, s.created_at, s.updated_at
+		from sets s
+		join exercises e on e.id = s.exercise_id
+		where e.day_id = any($1::uuid[])
+		order by s.position, s.created_at
+	`, ids); err != nil {
+		return nil, err
+	}
+	var rests []models.RestPeriod
+	if err := conn(ctx, s.db).SelectContext(ctx, &rests, `
+		select rp.id, rp.exercise_id, rp.position, rp.duration_seconds, rp.created_at, rp.updated_at
+		from rest_periods rp
+		join exercises e on e.id = rp.exercise_id
+		where e.day_id = any($1::uuid[])
+		order by rp.position, rp.created_at
+	`, ids); err != nil {
+		return nil, err
+	}
+	var comments []models.WorkoutComment
+	if err := conn(ctx, s.db).SelectContext(ctx, &comments, commentSelect+` where c.day_id = any($1::uuid[]) order by c.created_at`, ids); err != nil {
+		return nil, err
+	}
+
+	setsByExercise := make(map[string][]models.Set)
+	for _, st := range sets {
+		setsByExercise[st.ExerciseID] = append(setsByExercise[st.ExerciseID], st)
+	}
+	restsByExercise := make(map[string][]models.RestPeriod)
+	for _, rp := range rests {
+		restsByExercise[rp.ExerciseID] = append(restsByExercise[rp.ExerciseID], rp)
+	}
+	exercisesByDay := make(map[string][]models.Exercise)
+	for _, ex := range exercises {
+		exSets := setsByExercise[ex.ID]
+		exRests := restsByExercise[ex.ID]
+		ex.Sets = exSets
+		ex.Rests = exRests
+		ex.Timeline = buildExerciseTimeline(exSets, exRests)
+		exercisesByDay[ex.DayID] = append(exercisesByDay[ex.DayID], ex)
+	}
+	commentsByDay := make(map[string][]models.WorkoutComment)
+	for _, c := range comments {
+		commentsByDay[c.DayID] = append(commentsByDay[c.DayID], c)
+	}
+	for i, d := range days {
+		dayComments := commentsByDay[d.ID]
+		unread := 0
+		for _, c := range dayComments {
+			if c.ReadAt == nil {
+				unread++
+			}
 		}
-		exercises = append(exercises, ex)
+		out[i] = models.DayWithDetails{WorkoutDay: d, Exercises: exercisesByDay[d.ID], Comments: dayComments, UnreadComments: unread}
 	}
-	// Load sets per exercise (simple N+1 for clarity)
-	for i := range exercises {
-		sets, err := s.ListSetsByExercise(ctx, exercises[i].ID)
-		if err != nil {
-			return nil, err
+	return out, nil
+}
+
+type ExerciseSummary struct {
+	Name     string  `db:"name" json:"name"`
+	Sets     int     `db:"sets" json:"sets"`
+	VolumeKg float64 `db:"volume_kg" json:"volumeKg"`
+}
+
+type DaySummary struct {
+	Date      string            `json:"date"`
+	DayID     *string           `json:"dayId"`
+	IsRestDay bool              `json:"isRestDay"`
+	Exercises []ExerciseSummary `json:"exercises"`
+	Sets      int               `json:"sets"`
+	VolumeKg  float64           `json:"volumeKg"`
+	// HeartRate is set when heart-rate samples were uploaded for the day.
+	HeartRate *HeartRateSummary `json:"heartRate,omitempty"`
+}
+
+// WeekSummary returns one summary per day for the seven days from start,
+// counting working sets and volume per exercise without loading the sets.
+// Dates without a workout day get a summary with a nil DayID.
+func (s *Days) WeekSummary(ctx context.Context, userID string, start time.Time) ([]DaySummary, error) {
+	const q = `
+		select to_char(d.workout_date, 'YYYY-MM-DD') as workout_date, d.id as day_id, d.is_rest_day,
+		       e.name, count(st.id) as sets, coalesce(sum(st.volume_kg), 0)::float8 as volume_kg,
+		       hr.avg_bpm::float8 as avg_bpm, hr.max_bpm, hr.calories_kcal::float8 as calories_kcal
+		from workout_days d
+		left join exercises e on e.day_id = d.id
+		left join sets st on st.exercise_id = e.id and st.is_warmup = false
+		left join day_heart_rate hr on hr.day_id = d.id
+		where d.user_id = $1 and d.workout_date >= $2::date and d.workout_date < $2::date + 7
+		group by d.id, e.id, hr.day_id
+		order by d.workout_date, e.position, e.created_at
+	`
+	var rows []struct {
+		WorkoutDate string          `db:"workout_date"`
+		DayID       string          `db:"day_id"`
+		IsRestDay   bool            `db:"is_rest_day"`
+		Name        sql.NullString  `db:"name"`
+		Sets        int             `db:"sets"`
+		VolumeKg    float64         `db:"volume_kg"`
+		AvgBPM      sql.NullFloat64 `db:"avg_bpm"`
+		MaxBPM      sql.NullInt64   `db:"max_bpm"`
+		Calories    sql.NullFloat64 `db:"calories_kcal"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, q, userID, start.Format("2006-01-02")); err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]*DaySummary)
+	for _, r := range rows {
+		sum, ok := byDate[r.WorkoutDate]
+		if !ok {
+			id := r.DayID
+			sum = &DaySummary{Date: r.WorkoutDate, DayID: &id, IsRestDay: r.IsRestDay, Exercises: []ExerciseSummary{}}
+			if r.AvgBPM.Valid {
+				sum.HeartRate = &HeartRateSummary{AvgBPM: r.AvgBPM.Float64, MaxBPM: int(r.MaxBPM.Int64), CaloriesKcal: r.Calories.Float64}
+			}
+			byDate[r.WorkoutDate] = sum
 		}
-		rests, err := s.listRestPeriodsByExercise(ctx, exercises[i].ID)
-		if err != nil {
-			return nil, err
+		if !r.Name.Valid {
+			continue
 		}
-		exercises[i].Sets = sets
-		exercises[i].Timeline = buildExerciseTimeline(sets, rests)
+		sum.Exercises = append(sum.Exercises, ExerciseSummary{Name: r.Name.String, Sets: r.Sets, VolumeKg: r.VolumeKg})
+		sum.Sets += r.Sets
+		sum.VolumeKg += r.VolumeKg
+	}
+	out := make([]DaySummary, 0, 7)
+	for i := 0; i < 7; i++ {
+		key := start.AddDate(0, 0, i).Format("2006-01-02")
+		if sum, ok := byDate[key]; ok {
+			sum.VolumeKg = roundTo(sum.VolumeKg, 2)
+			out = append(out, *sum)
+			continue
+		}
+		out = append(out, DaySummary{Date: key, Exercises: []ExerciseSummary{}})
+	}
+	return out, nil
+}
+
+// ExerciseTimeline returns an exercise's sets and rests in workout order, or
+// nil, nil if the exercise doesn't belong to the user.
+func (s *Days) ExerciseTimeline(ctx context.Context, userID, exerciseID string) ([]models.ExerciseEntry, error) {
+	var exists bool
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		select exists (
+		  select 1 from exercises e
+		  join workout_days d on d.id = e.day_id
+		  where e.id = $1 and d.user_id = $2
+		)`, exerciseID, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	sets, err := s.ListSetsByExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
 	}
-	return &models.DayWithDetails{WorkoutDay: *day, Exercises: exercises}, nil
+	rests, err := s.listRestPeriodsByExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	return buildExerciseTimeline(sets, rests), nil
 }
 
 func (s *Days) SetRestDay(ctx context.Context, userID, dayID string, rest bool) (*models.WorkoutDay, error) {
@@ -114,10 +305,10 @@ func (s *Days) SetRestDay(ctx context.Context, userID, dayID string, rest bool)
 		update workout_days
 		set is_rest_day = $3
 		where id = $1 and user_id = $2
-		returning id, user_id, workout_date, timezone, notes, is_rest_day, created_at, updated_at
+		returning id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
 	`
 	d := new(models.WorkoutDay)
-	if err := s.db.QueryRowxContext(ctx, q, dayID, userID, rest).StructScan(d); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, dayID, userID, rest).StructScan(d); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.ConstraintName == "rest_day_requires_no_exercises" {
 			return nil, ErrRestDayHasExercises
@@ -130,11 +321,91 @@ func (s *Days) SetRestDay(ctx context.Context, userID, dayID string, rest bool)
 	return d, nil
 }
 
+// SetNotes replaces a day's notes; blank notes clear them.
+func (s *Days) SetNotes(ctx context.Context, userID, dayID, notes string) (*models.WorkoutDay, error) {
+	const q = `
+		update workout_days
+		set notes = nullif(btrim($3), '')
+		where id = $1 and user_id = $2
+		returning id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
+	`
+	d := new(models.WorkoutDay)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, dayID, userID, notes).StructScan(d); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetVisibility sets who can see a day in the activity feed: private,
+// friends, or "" for the owner's default.
+func (s *Days) SetVisibility(ctx context.Context, userID, dayID, visibility string) (*models.WorkoutDay, error) {
+	const q = `
+		update workout_days
+		set visibility = nullif($3, '')
+		where id = $1 and user_id = $2
+		returning id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
+	`
+	d := new(models.WorkoutDay)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, dayID, userID, visibility).StructScan(d); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// StartSession records when the day's workout started, clearing any
+// earlier finish time.
+func (s *Days) StartSession(ctx context.Context, userID, dayID string, at time.Time) (*models.WorkoutDay, error) {
+	const q = `
+		update workout_days
+		set started_at = $3, finished_at = null
+		where id = $1 and user_id = $2
+		returning id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
+	`
+	return s.updateSession(ctx, q, dayID, userID, at)
+}
+
+// FinishSession records when the day's workout finished. A session that was
+// never started is treated as starting and finishing at the same time.
+func (s *Days) FinishSession(ctx context.Context, userID, dayID string, at time.Time) (*models.WorkoutDay, error) {
+	const q = `
+		update workout_days
+		set finished_at = $3, started_at = coalesce(started_at, $3)
+		where id = $1 and user_id = $2
+		returning id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
+	`
+	return s.updateSession(ctx, q, dayID, userID, at)
+}
+
+func (s *Days) updateSession(ctx context.Context, q, dayID, userID string, at time.Time) (*models.WorkoutDay, error) {
+	d := new(models.WorkoutDay)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, dayID, userID, at).StructScan(d); err != nil {
+		if isSessionOrderViolation(err) {
+			return nil, ErrSessionOrder
+		}
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func isSessionOrderViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "workout_days_session_order"
+}
+
 func (s *Days) ListSetsByExercise(ctx context.Context, exerciseID string) ([]models.Set, error) {
-	rows, err := s.db.QueryxContext(ctx, `
-		select id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe,
-		       is_warmup, rest_seconds, tempo, performed_at,
-		       volume_kg, created_at, updated_at
+	rows, err := conn(ctx, s.db).QueryxContext(ctx, `
+		select id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir,
+		       is_warmup, rest_seconds, tempo, performed_at, set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps,
+		       volume_kg, coalesce(effective_load_kg, weight_kg) as effective_load_kg, created_at, updated_at
 		from sets
 		where exercise_id = $1
 		order by position, created_at
@@ -155,7 +426,7 @@ func (s *Days) ListSetsByExercise(ctx context.Context, exerciseID string) ([]mod
 }
 
 func (s *Days) listRestPeriodsByExercise(ctx context.Context, exerciseID string) ([]models.RestPeriod, error) {
-	rows, err := s.db.QueryxContext(ctx, `
+	rows, err := conn(ctx, s.db).QueryxContext(ctx, `
 		select id, exercise_id, position, duration_seconds, created_at, updated_at
 		from rest_periods
 		where exercise_id = $1
@@ -178,7 +449,7 @@ func (s *Days) listRestPeriodsByExercise(ctx context.Context, exerciseID string)
 
 func buildExerciseTimeline(sets []models.Set, rests []models.RestPeriod) []models.ExerciseEntry {
 	if len(sets) == 0 && len(rests) == 0 {
-		return nil
+		return []models.ExerciseEntry{}
 	}
 	buckets := make(map[int][]models.RestPeriod)
 	for _, rp := range rests {
@@ -187,7 +458,7 @@ func buildExerciseTimeline(sets []models.Set, rests []models.RestPeriod) []model
 		}
 		buckets[rp.Position] = append(buckets[rp.Position], rp)
 	}
-	var timeline []models.ExerciseEntry
+	timeline := make([]models.ExerciseEntry, 0, len(sets)+len(rests))
 	// Rest periods positioned before the first set use position 0
 	if head := buckets[0]; len(head) > 0 {
 		for _, rp := range head {
@@ -1,9 +1,13 @@
 package store
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+
 	"exercise-tracker/internal/models"
 )
 
@@ -66,3 +70,80 @@ func TestBuildExerciseTimelineOrdersEntries(t *testing.T) {
 }
 
 
+
+func TestBuildExerciseTimelineEmpty(t *testing.T) {
+	timeline := buildExerciseTimeline(nil, nil)
+	if timeline == nil || len(timeline) != 0 {
+		t.Fatalf("expected empty non-nil timeline, got %#v", timeline)
+	}
+}
+
+func TestBuildSessionTimeline(t *testing.T) {
+	base := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	at := func(min int) *time.Time { v := base.Add(time.Duration(min) * time.Minute); return &v }
+	sets := []TimelineSet{
+		{SetID: "a", PerformedAt: at(5), VolumeKg: 100},
+		{SetID: "b", PerformedAt: at(2), VolumeKg: 100},
+		{SetID: "c"},
+		{SetID: "d", PerformedAt: at(12), VolumeKg: 100},
+	}
+	tl := buildSessionTimeline(sets, at(0))
+
+	if len(tl.Items) != 3 || len(tl.Unstamped) != 1 || tl.Unstamped[0].SetID != "c" {
+		t.Fatalf("unexpected split: %#v", tl)
+	}
+	for i, want := range []string{"b", "a", "d"} {
+		if tl.Items[i].SetID != want {
+			t.Fatalf("item %d = %s, want %s", i, tl.Items[i].SetID, want)
+		}
+	}
+	if tl.Items[0].GapSeconds != nil || *tl.Items[0].OffsetSeconds != 120 {
+		t.Fatalf("first item offsets: %#v", tl.Items[0])
+	}
+	if *tl.Items[2].OffsetSeconds != 720 || *tl.Items[2].GapSeconds != 420 {
+		t.Fatalf("last item offsets: %#v", tl.Items[2])
+	}
+	st := tl.Stats
+	if st.StampedSets != 3 || st.ActiveSeconds != 600 {
+		t.Fatalf("unexpected stats: %#v", st)
+	}
+	if *st.SetsPerHour != 18 || *st.VolumePerMinute != 30 || *st.AvgGapSeconds != 300 || *st.MedianGapSeconds != 300 {
+		t.Fatalf("unexpected rates: %+v", st)
+	}
+}
+
+func TestBuildSessionTimelineEmpty(t *testing.T) {
+	tl := buildSessionTimeline(nil, nil)
+	if tl.Items == nil || tl.Unstamped == nil || tl.Stats.SetsPerHour != nil {
+		t.Fatalf("expected empty timeline, got %#v", tl)
+	}
+}
+
+func TestBuildSessionTimelineTempo(t *testing.T) {
+	tempo, bad := "3-1-X-0", "slow"
+	sets := []TimelineSet{
+		{SetID: "warmup", Reps: 10, IsWarmup: true, Tempo: &tempo},
+		{SetID: "a", Reps: 8, Tempo: &tempo},
+		{SetID: "b", Reps: 6, Tempo: &bad},
+		{SetID: "c", Reps: 5, Tempo: &tempo},
+	}
+	tl := buildSessionTimeline(sets, nil)
+	if got := tl.Unstamped[1].TimeUnderTensionSeconds; got == nil || *got != 40 {
+		t.Fatalf("set a tut = %v, want 40", got)
+	}
+	if tl.Unstamped[2].TimeUnderTensionSeconds != nil {
+		t.Fatal("invalid tempo should have no tut")
+	}
+	if st := tl.Stats; st.TimeUnderTensionSeconds != 65 || st.TempoSets != 2 {
+		t.Fatalf("unexpected tut stats: %+v", st)
+	}
+}
+
+func TestIsSessionOrderViolation(t *testing.T) {
+	if !isSessionOrderViolation(fmt.Errorf("finish: %w", &pgconn.PgError{Code: "23514", ConstraintName: "workout_days_session_order"})) {
+		t.Fatal("session order violation not recognised")
+	}
+	if isSessionOrderViolation(&pgconn.PgError{Code: "23514", ConstraintName: "quota_sets"}) || isSessionOrderViolation(errors.New("boom")) {
+		t.Fatal("other errors are not session order violations")
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 
-	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 
 	"exercise-tracker/internal/models"
@@ -28,10 +28,10 @@ func (s *Exercises) Create(ctx context.Context, userID, dayID, catalogID string,
 			$3,
 			$4
 		where exists(select 1 from workout_days where id = $1 and user_id = $5)
-		returning id, day_id, catalog_id, name, position, comment, created_at, updated_at
+		returning id, day_id, catalog_id, name, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg, created_at, updated_at
 	`
 	var ex models.Exercise
-	if err := s.db.QueryRowxContext(ctx, q, dayID, catalogID, position, comment, userID).StructScan(&ex); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, dayID, catalogID, position, comment, userID).StructScan(&ex); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.ConstraintName == "exercises_require_training_day" {
 			return nil, ErrExerciseOnRestDay
@@ -41,6 +41,26 @@ func (s *Exercises) Create(ctx context.Context, userID, dayID, catalogID string,
 	return &ex, nil
 }
 
+// GetForUpdate returns one of the user's exercises and locks it until the
+// request transaction ends, or nil, nil if it isn't theirs.
+func (s *Exercises) GetForUpdate(ctx context.Context, userID, id string) (*models.Exercise, error) {
+	const q = `
+		select e.id, e.day_id, e.catalog_id, e.name, e.position, e.comment, e.planned_sets, e.planned_reps, e.planned_reps_max, e.planned_amrap, e.planned_weight_kg, e.created_at, e.updated_at
+		from exercises e
+		join workout_days d on d.id = e.day_id
+		where e.id = $1 and d.user_id = $2
+		for update of e
+	`
+	var ex models.Exercise
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, id, userID).StructScan(&ex); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ex, nil
+}
+
 func (s *Exercises) Update(ctx context.Context, userID, id string, position *int, comment *string) (*models.Exercise, error) {
 	const q = `
 		update exercises e
@@ -48,10 +68,10 @@ func (s *Exercises) Update(ctx context.Context, userID, id string, position *int
 		    comment = coalesce($4, e.comment)
 		where e.id = $1
 		  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
-		returning id, day_id, catalog_id, name, position, comment, created_at, updated_at
+		returning id, day_id, catalog_id, name, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg, created_at, updated_at
 	`
 	var ex models.Exercise
-	if err := s.db.QueryRowxContext(ctx, q, id, userID, position, comment).StructScan(&ex); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, id, userID, position, comment).StructScan(&ex); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -66,10 +86,86 @@ func (s *Exercises) Delete(ctx context.Context, userID, id string) (bool, error)
 		where e.id = $1
 		  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
 	`
-	res, err := s.db.ExecContext(ctx, q, id, userID)
+	res, err := conn(ctx, s.db).ExecContext(ctx, q, id, userID)
 	if err != nil {
 		return false, err
 	}
 	n, _ := res.RowsAffected()
 	return n > 0, nil
 }
+
+// Move reassigns an exercise, with its sets and rests, to another of the
+// user's workout days. Set and rest positions are kept; the exercise keeps its
+// position unless one is given. Returns nil, nil when the exercise or the
+// target day doesn't belong to the user.
+func (s *Exercises) Move(ctx context.Context, userID, id, dayID string, position *int) (*models.Exercise, error) {
+	var ex *models.Exercise
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		var err error
+		ex, err = moveExercise(ctx, tx, userID, id, dayID, position)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ex, nil
+}
+
+func moveExercise(ctx context.Context, tx *sqlx.Tx, userID, id, dayID string, position *int) (*models.Exercise, error) {
+	const q = `
+		update exercises e
+		set day_id = $3,
+		    position = coalesce($4, e.position)
+		where e.id = $1
+		  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
+		  and exists (select 1 from workout_days d where d.id = $3 and d.user_id = $2)
+		returning id, day_id, catalog_id, name, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg, created_at, updated_at
+	`
+	var ex models.Exercise
+	if err := tx.QueryRowxContext(ctx, q, id, userID, dayID, position).StructScan(&ex); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "exercises_require_training_day" {
+			return nil, ErrExerciseOnRestDay
+		}
+		return nil, err
+	}
+	// sets.workout_date is denormalized from the day and only synced when
+	// exercise_id changes.
+	if _, err := tx.ExecContext(ctx, `
+		update sets s set workout_date = d.workout_date
+		from workout_days d
+		where d.id = $2 and s.exercise_id = $1
+	`, ex.ID, ex.DayID); err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
+
+// ExerciseEquipment is the catalog loading info of a logged exercise.
+type ExerciseEquipment struct {
+	Equipment    string  `db:"equipment"`
+	BaseWeightKg float64 `db:"base_weight_kg"`
+}
+
+// Equipment returns the catalog equipment and base weight behind one of the
+// user's exercises, or nil, nil if the exercise isn't theirs.
+func (s *Exercises) Equipment(ctx context.Context, userID, id string) (*ExerciseEquipment, error) {
+	const q = `
+		select c.equipment, c.base_weight_kg
+		from exercises e
+		join workout_days d on d.id = e.day_id
+		join exercise_catalog c on c.id = e.catalog_id
+		where e.id = $1 and d.user_id = $2
+	`
+	var out ExerciseEquipment
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, id, userID).StructScan(&out); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Day visibility. A day without its own uses the owner's default from
+// settings.
+const (
+	VisibilityPrivate = "private"
+	VisibilityFriends = "friends"
+)
+
+// Feed serves friends' workout summaries. The summaries are kept in
+// feed_items and brought up to date from the change journal on read.
+type Feed struct {
+	db *sqlx.DB
+}
+
+func NewFeed(db *sqlx.DB) *Feed { return &Feed{db: db} }
+
+// FeedPR is an exercise whose top working set beat every earlier day.
+type FeedPR struct {
+	CatalogID      string  `json:"catalogId"`
+	Name           string  `json:"name"`
+	WeightKg       float64 `json:"weightKg"`
+	PreviousBestKg float64 `json:"previousBestKg"`
+}
+
+type FeedItem struct {
+	DayID       string    `db:"day_id" json:"dayId"`
+	UserID      string    `db:"user_id" json:"userId"`
+	Email       string    `db:"email" json:"email"`
+	WorkoutDate string    `db:"workout_date" json:"workoutDate"`
+	Exercises   int       `db:"exercises" json:"exercises"`
+	WorkingSets int       `db:"working_sets" json:"workingSets"`
+	TonnageKg   float64   `db:"tonnage_kg" json:"tonnageKg"`
+	PRsJSON     []byte    `db:"prs" json:"-"`
+	PRs         []FeedPR  `db:"-" json:"prs"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Refresh re-summarizes the days touched by journal entries since the last
+// refresh, along with the owners' later days, whose PRs compare against
+// them. Only transactions below the snapshot xmin are consumed, since later
+// ones may still commit. When another refresh holds the cursor it returns
+// right away; that one covers the same entries.
+func (s *Feed) Refresh(ctx context.Context) (err error) {
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	var from int64
+	err = tx.GetContext(ctx, &from, `select xmin_horizon from feed_cursor where id for update skip locked`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+	var to int64
+	if err = tx.GetContext(ctx, &to, `select txid_snapshot_xmin(txid_current_snapshot())`); err != nil {
+		return err
+	}
+	if to <= from {
+		return tx.Commit()
+	}
+	if _, err = tx.ExecContext(ctx, `
+		with touched as (
+		  select j.day_id, j.user_id, d.workout_date
+		  from change_journal j
+		  left join workout_days d on d.id = j.day_id
+		  where j.tx_id >= $1 and j.tx_id < $2 and j.day_id is not null
+		), later as (
+		  select f.day_id
+		  from feed_items f
+		  join (select user_id, min(workout_date) as since from touched group by user_id) t on t.user_id = f.user_id
+		  where f.workout_date > t.since
+		)
+		select refresh_feed_items(array(select day_id from touched union select day_id from later))
+	`, from, to); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `update feed_cursor set xmin_horizon = $1 where id`, to); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns the shared workout days of userID's friends, newest first,
+// dated before `before` when it is set.
+func (s *Feed) List(ctx context.Context, userID string, before *time.Time, limit int) ([]FeedItem, error) {
+	const q = `
+		with friends as (
+		  select case when f.requester_id = $1 then f.addressee_id else f.requester_id end as id
+		  from friendships f
+		  where f.status = 'accepted' and (f.requester_id = $1 or f.addressee_id = $1)
+		)
+		select f.day_id, f.user_id, u.email, to_char(f.workout_date, 'YYYY-MM-DD') as workout_date,
+		       f.exercises, f.working_sets, f.tonnage_kg::float8 as tonnage_kg, f.prs, f.updated_at
+		from feed_items f
+		join friends fr on fr.id = f.user_id
+		join workout_days d on d.id = f.day_id
+		join users u on u.id = f.user_id and u.deleted_at is null
+		left join user_settings us on us.user_id = f.user_id
+		where coalesce(d.visibility, us.day_visibility, 'private') = 'friends'
+		  and ($2::date is null or f.workout_date < $2::date)
+		order by f.workout_date desc, f.updated_at desc
+		limit $3
+	`
+	out := []FeedItem{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, q, userID, dateArg(before), limit); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].PRs = []FeedPR{}
+		if err := json.Unmarshal(out[i].PRsJSON, &out[i].PRs); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
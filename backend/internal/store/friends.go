@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+)
+
+var ErrSelfFriend = errors.New("cannot befriend yourself")
+
+type Friends struct {
+	db *sqlx.DB
+}
+
+func NewFriends(db *sqlx.DB) *Friends { return &Friends{db: db} }
+
+// Friendship is a friend request or friendship as seen by one of its users:
+// Incoming is true when the other user sent it, and Email and UserID are the
+// other user's. UserID is nil for requests to an email with no account yet.
+type Friendship struct {
+	ID         string     `db:"id" json:"id"`
+	Status     string     `db:"status" json:"status"`
+	Incoming   bool       `db:"incoming" json:"incoming"`
+	UserID     *string    `db:"user_id" json:"userId,omitempty"`
+	Email      string     `db:"email" json:"email"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"acceptedAt,omitempty"`
+}
+
+// friendshipSelect renders friendships for the user $1 with email $2.
+const friendshipSelect = `
+	select f.id, f.status,
+	       f.requester_id <> $1 as incoming,
+	       case when f.requester_id = $1 then f.addressee_id else f.requester_id end as user_id,
+	       case when f.requester_id = $1 then f.addressee_email::text else u.email::text end as email,
+	       f.created_at, f.accepted_at
+	from friendships f
+	join users u on u.id = f.requester_id
+	where (f.requester_id = $1 or f.addressee_id = $1 or f.addressee_email = $2)
+`
+
+// Request sends a friend request from userID to email. When that user has
+// already asked userID, their request is accepted instead; an existing
+// request or friendship between the two is returned unchanged.
+func (s *Friends) Request(ctx context.Context, userID, userEmail, email string) (*Friendship, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if strings.EqualFold(userEmail, email) {
+		return nil, ErrSelfFriend
+	}
+	var reverse string
+	err := conn(ctx, s.db).GetContext(ctx, &reverse, `
+		select f.id from friendships f
+		join users u on u.id = f.requester_id
+		where u.email = $1 and (f.addressee_id = $2 or f.addressee_email = $3)
+	`, email, userID, userEmail)
+	if err == nil {
+		if f, err := s.Accept(ctx, reverse, userID, userEmail); f != nil || err != nil {
+			return f, err
+		}
+		return s.byID(ctx, reverse, userID, userEmail)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	var id string
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into friendships (requester_id, addressee_email)
+		values ($1, $2)
+		on conflict (requester_id, addressee_email) do update set addressee_email = excluded.addressee_email
+		returning id
+	`, userID, email).Scan(&id); err != nil {
+		return nil, err
+	}
+	return s.byID(ctx, id, userID, userEmail)
+}
+
+func (s *Friends) byID(ctx context.Context, id, userID, userEmail string) (*Friendship, error) {
+	var f Friendship
+	err := conn(ctx, s.db).QueryRowxContext(ctx, friendshipSelect+` and f.id = $3`, userID, userEmail, id).StructScan(&f)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// List returns the user's friends and pending requests both ways, friends
+// first.
+func (s *Friends) List(ctx context.Context, userID, userEmail string) ([]Friendship, error) {
+	out := []Friendship{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `select * from (`+friendshipSelect+`) x order by x.status, x.email`, userID, userEmail)
+	return out, err
+}
+
+// Accept accepts a pending request addressed to userEmail. Returns nil, nil
+// when there is no such request.
+func (s *Friends) Accept(ctx context.Context, id, userID, userEmail string) (*Friendship, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update friendships
+		set addressee_id = $2, status = 'accepted', accepted_at = now()
+		where id = $1 and addressee_email = $3 and status = 'pending'
+	`, id, userID, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return s.byID(ctx, id, userID, userEmail)
+}
+
+// Remove ends a friendship, or cancels or declines a request; either user
+// may do it.
+func (s *Friends) Remove(ctx context.Context, id, userID, userEmail string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		delete from friendships
+		where id = $1 and (requester_id = $2 or addressee_id = $2 or addressee_email = $3)
+	`, id, userID, userEmail)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,298 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/progression"
+)
+
+// Goal metrics.
+const (
+	GoalE1RM   = "e1rm"   // best estimated 1RM, kg
+	GoalWeight = "weight" // heaviest working set, kg
+	GoalReps   = "reps"   // most unassisted reps in a working set at or above AtWeightKg
+)
+
+const (
+	// goalTrendWeeks is the window the trend line is fitted over.
+	goalTrendWeeks = 8
+	// goalHorizon is how far ahead a projection may land.
+	goalHorizon = 5 * 365 * 24 * time.Hour
+)
+
+var ErrUnknownGoalMetric = errors.New("unknown goal metric")
+
+type Goals struct {
+	db *sqlx.DB
+}
+
+func NewGoals(db *sqlx.DB) *Goals { return &Goals{db: db} }
+
+type Goal struct {
+	ID           string              `db:"id" json:"id"`
+	CatalogID    string              `db:"catalog_id" json:"catalogId"`
+	ExerciseName string              `db:"exercise_name" json:"exerciseName"`
+	Metric       string              `db:"metric" json:"metric"`
+	Target       float64             `db:"target_value" json:"target"`
+	AtWeightKg   *float64            `db:"at_weight_kg" json:"atWeightKg,omitempty"`
+	Formula      progression.Formula `db:"formula" json:"formula,omitempty"`
+	TargetDate   *string             `db:"target_date" json:"targetDate"`
+	Note         *string             `db:"note" json:"note,omitempty"`
+	CreatedAt    time.Time           `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time           `db:"updated_at" json:"updatedAt"`
+	Progress     GoalProgress        `db:"-" json:"progress"`
+}
+
+// GoalProgress is computed from the logged sets when a goal is read.
+type GoalProgress struct {
+	// Current is the best value logged so far, nil without any sets.
+	Current     *float64 `json:"current"`
+	CurrentDate *string  `json:"currentDate"`
+	// Baseline is the best value before the goal was set, 0 without any.
+	Baseline float64 `json:"baseline"`
+	// Percent is how far Current has come from Baseline to the target.
+	Percent    float64 `json:"percent"`
+	AchievedOn *string `json:"achievedOn"`
+	// TrendPerWeek is the least-squares slope over the last eight weeks of
+	// training days; nil with fewer than three days spanning a week.
+	TrendPerWeek *float64 `json:"trendPerWeek"`
+	// ProjectedDate is when the trend reaches the target; nil when achieved,
+	// not improving or more than five years out.
+	ProjectedDate *string `json:"projectedDate"`
+	// OnTrack reports, for goals with a target date, whether the goal was
+	// achieved or is projected to be by then.
+	OnTrack *bool `json:"onTrack"`
+}
+
+type GoalParams struct {
+	CatalogID  string
+	Metric     string
+	Target     float64
+	AtWeightKg *float64
+	Formula    progression.Formula
+	TargetDate *time.Time
+	Note       *string
+}
+
+const goalColumns = `g.id, g.catalog_id, c.name as exercise_name, g.metric, g.target_value::float8 as target_value,
+	g.at_weight_kg::float8 as at_weight_kg, g.formula, to_char(g.target_date, 'YYYY-MM-DD') as target_date,
+	g.note, g.created_at, g.updated_at`
+
+// List returns the user's goals, oldest first, with their progress.
+func (s *Goals) List(ctx context.Context, userID string) ([]Goal, error) {
+	out := []Goal{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select `+goalColumns+`
+		from goals g
+		join exercise_catalog c on c.id = g.catalog_id
+		where g.user_id = $1
+		order by g.created_at`, userID); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for i := range out {
+		if err := s.fillProgress(ctx, userID, &out[i], now); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Get returns a goal with its progress, or nil, nil if it isn't the user's.
+func (s *Goals) Get(ctx context.Context, userID, id string) (*Goal, error) {
+	var g Goal
+	err := conn(ctx, s.db).GetContext(ctx, &g, `
+		select `+goalColumns+`
+		from goals g
+		join exercise_catalog c on c.id = g.catalog_id
+		where g.id = $1 and g.user_id = $2`, id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillProgress(ctx, userID, &g, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Create adds a goal and returns it with its progress, or nil, nil when the
+// catalog exercise doesn't exist.
+func (s *Goals) Create(ctx context.Context, userID string, p GoalParams) (*Goal, error) {
+	var id string
+	err := conn(ctx, s.db).GetContext(ctx, &id, `
+		insert into goals (user_id, catalog_id, metric, target_value, at_weight_kg, formula, target_date, note)
+		select $1, c.id, $3, $4, $5, $6, $7, $8
+		from exercise_catalog c
+		where c.id = $2
+		returning id`, userID, p.CatalogID, p.Metric, p.Target, p.AtWeightKg, p.Formula, dateArg(p.TargetDate), p.Note)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// Update replaces a goal's definition, or returns nil, nil if it isn't the
+// user's or the catalog exercise doesn't exist. The baseline stays the best
+// value before the goal was first created.
+func (s *Goals) Update(ctx context.Context, userID, id string, p GoalParams) (*Goal, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update goals
+		set catalog_id = $3, metric = $4, target_value = $5, at_weight_kg = $6, formula = $7,
+		    target_date = $8, note = $9, updated_at = now()
+		where id = $1 and user_id = $2 and exists (select 1 from exercise_catalog where id = $3)`,
+		id, userID, p.CatalogID, p.Metric, p.Target, p.AtWeightKg, p.Formula, dateArg(p.TargetDate), p.Note)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *Goals) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from goals where id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (s *Goals) fillProgress(ctx context.Context, userID string, g *Goal, now time.Time) error {
+	const w = `coalesce(s.effective_load_kg, s.weight_kg)`
+	var value, filter string
+	args := []any{g.CatalogID, userID}
+	switch g.Metric {
+	case GoalE1RM:
+		value, filter = e1rmExpr(g.Formula), w+` > 0`
+	case GoalWeight:
+		value, filter = w, w+` > 0`
+	case GoalReps:
+		at := 0.0
+		if g.AtWeightKg != nil {
+			at = *g.AtWeightKg
+		}
+		value, filter = `s.reps - s.assisted_reps`, w+` >= $3`
+		args = append(args, at)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownGoalMetric, g.Metric)
+	}
+	var series []ProgressPoint
+	if err := conn(ctx, s.db).SelectContext(ctx, &series, `
+		select to_char(s.workout_date, 'YYYY-MM-DD') as date, round(max(`+value+`)::numeric, 2)::float8 as value
+		from sets s
+		join exercises e on e.id = s.exercise_id
+		where e.catalog_id = $1 and s.user_id = $2 and s.is_warmup = false and s.set_type = 'strength'
+		  and s.reps > 0 and `+filter+`
+		group by s.workout_date
+		having max(`+value+`) is not null
+		order by s.workout_date`, args...); err != nil {
+		return err
+	}
+	g.Progress = goalProgress(series, g.Target, g.CreatedAt, g.TargetDate, now)
+	return nil
+}
+
+// goalProgress computes progress toward target from one value per training
+// day, oldest first.
+func goalProgress(series []ProgressPoint, target float64, created time.Time, targetDate *string, now time.Time) GoalProgress {
+	var p GoalProgress
+	createdOn := created.UTC().Format("2006-01-02")
+	for i := range series {
+		pt := series[i]
+		if pt.Date < createdOn {
+			p.Baseline = max(p.Baseline, pt.Value)
+		}
+		if p.Current == nil || pt.Value > *p.Current {
+			p.Current, p.CurrentDate = &series[i].Value, &series[i].Date
+		}
+		if p.AchievedOn == nil && pt.Value >= target {
+			p.AchievedOn = &series[i].Date
+		}
+	}
+	switch {
+	case p.AchievedOn != nil:
+		p.Percent = 100
+	case p.Current != nil && target > p.Baseline:
+		p.Percent = roundTo(math.Min(math.Max((*p.Current-p.Baseline)/(target-p.Baseline)*100, 0), 100), 1)
+	}
+
+	// Fit value against days since the window start.
+	since := now.AddDate(0, 0, -7*goalTrendWeeks).Format("2006-01-02")
+	var xs, ys []float64
+	var first, last time.Time
+	for _, pt := range series {
+		if pt.Date < since {
+			continue
+		}
+		d, _ := time.Parse("2006-01-02", pt.Date)
+		if first.IsZero() {
+			first = d
+		}
+		last = d
+		xs = append(xs, d.Sub(first).Hours()/24)
+		ys = append(ys, pt.Value)
+	}
+	if len(xs) >= 3 && last.Sub(first) >= 7*24*time.Hour {
+		slope, intercept := leastSquares(xs, ys)
+		perWeek := roundTo(slope*7, 2)
+		p.TrendPerWeek = &perWeek
+		if p.AchievedOn == nil && slope > 0 {
+			// Days from the window's first day until the line meets the target.
+			days := (target - intercept) / slope
+			at := first.Add(time.Duration(math.Ceil(days)) * 24 * time.Hour)
+			today := now.UTC().Truncate(24 * time.Hour)
+			if at.Before(today) {
+				at = today
+			}
+			if at.Sub(today) <= goalHorizon {
+				s := at.Format("2006-01-02")
+				p.ProjectedDate = &s
+			}
+		}
+	}
+
+	if targetDate != nil {
+		var onTrack bool
+		switch {
+		case p.AchievedOn != nil:
+			onTrack = *p.AchievedOn <= *targetDate
+		case p.ProjectedDate != nil:
+			onTrack = *p.ProjectedDate <= *targetDate
+		}
+		p.OnTrack = &onTrack
+	}
+	return p
+}
+
+// leastSquares fits y = intercept + slope*x.
+func leastSquares(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	var sx, sy, sxy, sxx float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxy += xs[i] * ys[i]
+		sxx += xs[i] * xs[i]
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return 0, sy / n
+	}
+	slope = (n*sxy - sx*sy) / den
+	return slope, (sy - slope*sx) / n
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGoalProgress(t *testing.T) {
+	now := time.Date(2026, 3, 30, 12, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	series := []ProgressPoint{
+		{Date: "2025-12-01", Value: 110}, // before the trend window
+		{Date: "2026-02-23", Value: 100},
+		{Date: "2026-03-02", Value: 102.5},
+		{Date: "2026-03-09", Value: 105},
+		{Date: "2026-03-16", Value: 107.5},
+	}
+	targetDate := "2026-06-01"
+	p := goalProgress(series, 120, created, &targetDate, now)
+	if *p.Current != 110 || *p.CurrentDate != "2025-12-01" || p.Baseline != 110 || p.Percent != 0 || p.AchievedOn != nil {
+		t.Fatalf("progress = %+v", p)
+	}
+	// 2.5 kg a week from 100 on 2026-02-23 reaches 120 eight weeks later.
+	if *p.TrendPerWeek != 2.5 || *p.ProjectedDate != "2026-04-20" || !*p.OnTrack {
+		t.Fatalf("trend = %v, projected = %v, on track = %v", *p.TrendPerWeek, p.ProjectedDate, *p.OnTrack)
+	}
+
+	p = goalProgress(series, 105, created, &targetDate, now)
+	if *p.AchievedOn != "2025-12-01" || p.Percent != 100 || p.ProjectedDate != nil || !*p.OnTrack {
+		t.Fatalf("achieved = %+v", p)
+	}
+
+	p = goalProgress(series[1:3], 120, created, nil, now)
+	if p.Percent != 12.5 || p.TrendPerWeek != nil || p.OnTrack != nil {
+		t.Fatalf("short history = %+v", p)
+	}
+}
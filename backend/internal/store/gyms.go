@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// Gym profile kinds.
+const (
+	GymHome       = "home"
+	GymCommercial = "commercial"
+)
+
+var (
+	ErrGymNameTaken     = errors.New("a gym profile with this name already exists")
+	ErrUnknownEquipment = errors.New("unknown equipment")
+)
+
+type Gyms struct {
+	db *sqlx.DB
+}
+
+func NewGyms(db *sqlx.DB) *Gyms { return &Gyms{db: db} }
+
+type GymProfile struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Equipment []string  `json:"equipment"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type gymProfileRow struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	Kind      string    `db:"kind"`
+	Equipment []byte    `db:"equipment"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r gymProfileRow) profile() GymProfile {
+	out := GymProfile{ID: r.ID, Name: r.Name, Kind: r.Kind, CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt}
+	_ = json.Unmarshal(r.Equipment, &out.Equipment)
+	if out.Equipment == nil {
+		out.Equipment = []string{}
+	}
+	return out
+}
+
+const gymProfileColumns = `id, name, kind, array_to_json(equipment) as equipment, created_at, updated_at`
+
+type GymProfileParams struct {
+	Name      string
+	Kind      string
+	Equipment []string
+}
+
+func (s *Gyms) List(ctx context.Context, userID string) ([]GymProfile, error) {
+	var rows []gymProfileRow
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select `+gymProfileColumns+`
+		from gym_profiles
+		where user_id = $1
+		order by name`, userID); err != nil {
+		return nil, err
+	}
+	out := make([]GymProfile, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.profile())
+	}
+	return out, nil
+}
+
+func (s *Gyms) Get(ctx context.Context, userID, id string) (*GymProfile, error) {
+	var row gymProfileRow
+	err := conn(ctx, s.db).GetContext(ctx, &row, `select `+gymProfileColumns+` from gym_profiles where id = $1 and user_id = $2`, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := row.profile()
+	return &p, nil
+}
+
+// Create returns ErrUnknownEquipment when an item isn't in the catalog's
+// equipment list and ErrGymNameTaken when the user already has the name.
+func (s *Gyms) Create(ctx context.Context, userID string, p GymProfileParams) (*GymProfile, error) {
+	if err := s.checkEquipment(ctx, p.Equipment); err != nil {
+		return nil, err
+	}
+	var row gymProfileRow
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into gym_profiles (user_id, name, kind, equipment)
+		values ($1, $2, $3, $4::text[])
+		returning `+gymProfileColumns, userID, p.Name, p.Kind, dedupe(p.Equipment)).StructScan(&row)
+	if err != nil {
+		if isGymNameViolation(err) {
+			return nil, ErrGymNameTaken
+		}
+		return nil, err
+	}
+	out := row.profile()
+	return &out, nil
+}
+
+// Update replaces the profile; nil when it doesn't exist.
+func (s *Gyms) Update(ctx context.Context, userID, id string, p GymProfileParams) (*GymProfile, error) {
+	if err := s.checkEquipment(ctx, p.Equipment); err != nil {
+		return nil, err
+	}
+	var row gymProfileRow
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		update gym_profiles
+		set name = $3, kind = $4, equipment = $5::text[], updated_at = now()
+		where id = $1 and user_id = $2
+		returning `+gymProfileColumns, id, userID, p.Name, p.Kind, dedupe(p.Equipment)).StructScan(&row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		if isGymNameViolation(err) {
+			return nil, ErrGymNameTaken
+		}
+		return nil, err
+	}
+	out := row.profile()
+	return &out, nil
+}
+
+func (s *Gyms) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from gym_profiles where id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (s *Gyms) checkEquipment(ctx context.Context, equipment []string) error {
+	if len(equipment) == 0 {
+		return nil
+	}
+	var unknown []string
+	if err := conn(ctx, s.db).SelectContext(ctx, &unknown, `
+		select u.name from unnest($1::text[]) as u(name)
+		where not exists (select 1 from equipment_types et where et.name = u.name)`, equipment); err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownEquipment, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func isGymNameViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "gym_profiles_user_id_name_key"
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
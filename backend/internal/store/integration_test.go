@@ -0,0 +1,494 @@
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/store"
+	"exercise-tracker/internal/testutil"
+)
+
+func TestMain(m *testing.M) { os.Exit(testutil.Main(m)) }
+
+// seedUser creates a user and today's workout day in ctx's transaction.
+func seedUser(t *testing.T, ctx context.Context, email string) (userID, dayID string) {
+	t.Helper()
+	database := testutil.DB(t)
+	user, err := store.NewUsers(database.DB).Create(ctx, email, "x")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	day, err := store.NewDays(database.DB).GetOrCreate(ctx, user.ID, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		t.Fatalf("create day: %v", err)
+	}
+	return user.ID, day.ID
+}
+
+func catalogID(t *testing.T, ctx context.Context, tx *store.RequestTx, slug string) string {
+	t.Helper()
+	var id string
+	if err := tx.Tx().GetContext(ctx, &id, `select id from exercise_catalog where slug = $1`, slug); err != nil {
+		t.Fatalf("catalog %s: %v", slug, err)
+	}
+	return id
+}
+
+func ops(t *testing.T, v ...any) []json.RawMessage {
+	t.Helper()
+	out := make([]json.RawMessage, len(v))
+	for i, op := range v {
+		b, err := json.Marshal(op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = b
+	}
+	return out
+}
+
+func TestCatalogUpsertIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	catalog := store.NewCatalog(testutil.DB(t).DB, nil)
+
+	entries := []store.CatalogEntry{
+		{Name: "IT Bench Press", Type: "Strength", BodyPart: "Chest", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Chest"}, SecondaryMuscles: []string{"Triceps"}},
+		{Name: "IT Squat", Type: "Strength", BodyPart: "Legs", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Quadriceps"}},
+	}
+	if n, err := catalog.Upsert(ctx, entries); err != nil || n != 2 {
+		t.Fatalf("first upsert = %d, %v", n, err)
+	}
+
+	// Same slug again: the row is updated in place and its muscles replaced.
+	entries[0].Level = "Expert"
+	entries[0].SecondaryMuscles = nil
+	if _, err := catalog.Upsert(ctx, entries[:1]); err != nil {
+		t.Fatalf("second upsert: %v", err)
+	}
+	id := catalogID(t, ctx, tx, "it-bench-press")
+	rec, err := catalog.GetCatalogEntry(ctx, id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if rec.Level != "Expert" {
+		t.Errorf("level = %q, want Expert", rec.Level)
+	}
+	if len(rec.SecondaryMuscles) != 0 {
+		t.Errorf("secondary muscles = %v, want none", rec.SecondaryMuscles)
+	}
+	var count int
+	if err := tx.Tx().GetContext(ctx, &count, `select count(*) from exercise_catalog where slug in ('it-bench-press', 'it-squat')`); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("catalog rows = %d, want 2", count)
+	}
+}
+
+func TestSaveProcessBatchOrderingIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, dayID := seedUser(t, ctx, "save-order@example.com")
+
+	catalog := store.NewCatalog(database.DB, nil)
+	if _, err := catalog.Upsert(ctx, []store.CatalogEntry{
+		{Name: "IT Row", Type: "Strength", BodyPart: "Back", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Lats"}},
+		{Name: "IT Press", Type: "Strength", BodyPart: "Shoulders", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Shoulders"}},
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	row := catalogID(t, ctx, tx, "it-row")
+	press := catalogID(t, ctx, tx, "it-press")
+
+	// Later ops refer to rows created earlier in the same batch by local id,
+	// and the reorders run after the creates they depend on.
+	batch := ops(t,
+		map[string]any{"type": "createExercise", "localId": "ex-a", "dayId": dayID, "catalogId": row, "position": 0},
+		map[string]any{"type": "createExercise", "localId": "ex-b", "dayId": dayID, "catalogId": press, "position": 1},
+		map[string]any{"type": "createSet", "localId": "s1", "exerciseId": "ex-a", "position": 0, "reps": 5, "weightKg": 60},
+		map[string]any{"type": "createSet", "localId": "s2", "exerciseId": "ex-a", "position": 1, "reps": 5, "weightKg": 70},
+		map[string]any{"type": "createSet", "localId": "s3", "exerciseId": "ex-a", "position": 2, "reps": 5, "weightKg": 80},
+		map[string]any{"type": "reorderSets", "exerciseId": "ex-a", "orderedIds": []string{"s3", "s1", "s2"}},
+		map[string]any{"type": "reorderExercises", "dayId": dayID, "orderedIds": []string{"ex-b", "ex-a"}},
+	)
+	mapping, _, err := store.NewSave(database.DB).ProcessBatch(ctx, userID, batch, "it-order")
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(mapping.Exercises) != 2 || len(mapping.Sets) != 3 {
+		t.Fatalf("mapping = %+v", mapping)
+	}
+
+	day, err := store.NewDays(database.DB).GetWithDetails(ctx, userID, dayID)
+	if err != nil || day == nil {
+		t.Fatalf("GetWithDetails = %v, %v", day, err)
+	}
+	if got := exerciseCatalogIDs(day.Exercises); fmt.Sprint(got) != fmt.Sprint([]string{press, row}) {
+		t.Errorf("exercise order = %v, want [press row]", got)
+	}
+	var weights []float64
+	for _, ex := range day.Exercises {
+		if ex.CatalogID != nil && *ex.CatalogID == row {
+			for _, s := range ex.Sets {
+				weights = append(weights, s.WeightKg)
+			}
+		}
+	}
+	if fmt.Sprint(weights) != "[80 60 70]" {
+		t.Errorf("set order by weight = %v, want [80 60 70]", weights)
+	}
+
+	// A failing op rolls back the ops before it.
+	bad := ops(t,
+		map[string]any{"type": "createSet", "localId": "s4", "exerciseId": mapping.Exercises[0].ID, "position": 3, "reps": 5, "weightKg": 90},
+		map[string]any{"type": "reorderSets", "exerciseId": "missing", "orderedIds": []string{"nope"}},
+	)
+	if _, _, err := store.NewSave(database.DB).ProcessBatch(ctx, userID, bad, "it-rollback"); err == nil {
+		t.Fatal("expected the batch to fail")
+	}
+	var sets int
+	if err := tx.Tx().GetContext(ctx, &sets, `select count(*) from sets where user_id = $1`, userID); err != nil {
+		t.Fatal(err)
+	}
+	if sets != 3 {
+		t.Errorf("sets after failed batch = %d, want 3", sets)
+	}
+}
+
+func exerciseCatalogIDs(exs []models.Exercise) []string {
+	ids := make([]string, 0, len(exs))
+	for _, ex := range exs {
+		if ex.CatalogID != nil {
+			ids = append(ids, *ex.CatalogID)
+		}
+	}
+	return ids
+}
+
+func TestReorderIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, dayID := seedUser(t, ctx, "reorder@example.com")
+	if _, err := store.NewCatalog(database.DB, nil).Upsert(ctx, []store.CatalogEntry{
+		{Name: "IT Row", Type: "Strength", BodyPart: "Back", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Lats"}},
+		{Name: "IT Press", Type: "Strength", BodyPart: "Shoulders", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Shoulders"}},
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	row := catalogID(t, ctx, tx, "it-row")
+	press := catalogID(t, ctx, tx, "it-press")
+
+	mapping, _, err := store.NewSave(database.DB).ProcessBatch(ctx, userID, ops(t,
+		map[string]any{"type": "createExercise", "localId": "ex-a", "dayId": dayID, "catalogId": row, "position": 0},
+		map[string]any{"type": "createExercise", "localId": "ex-b", "dayId": dayID, "catalogId": press, "position": 1},
+	), "it-reorder")
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	a, b := mapping.Exercises[0].ID, mapping.Exercises[1].ID
+
+	exercises := store.NewExercises(database.DB)
+	if _, err := exercises.Reorder(ctx, userID, dayID, []string{b}); !errors.Is(err, store.ErrReorderIDs) {
+		t.Fatalf("partial reorder err = %v, want ErrReorderIDs", err)
+	}
+	if _, err := exercises.Reorder(ctx, userID, dayID, []string{b, b}); !errors.Is(err, store.ErrReorderIDs) {
+		t.Fatalf("duplicate reorder err = %v, want ErrReorderIDs", err)
+	}
+	if found, err := exercises.Reorder(ctx, userID, dayID, []string{b, a}); err != nil || !found {
+		t.Fatalf("Reorder = %v, %v", found, err)
+	}
+	other, _ := seedUser(t, ctx, "reorder-other@example.com")
+	if found, err := exercises.Reorder(ctx, other, dayID, []string{b, a}); err != nil || found {
+		t.Fatalf("Reorder by another user = %v, %v", found, err)
+	}
+
+	day, err := store.NewDays(database.DB).GetWithDetails(ctx, userID, dayID)
+	if err != nil || day == nil {
+		t.Fatalf("GetWithDetails = %v, %v", day, err)
+	}
+	if got := exerciseCatalogIDs(day.Exercises); fmt.Sprint(got) != fmt.Sprint([]string{press, row}) {
+		t.Errorf("exercise order = %v, want [press row]", got)
+	}
+}
+
+func TestSaveAutoRestIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, dayID := seedUser(t, ctx, "auto-rest@example.com")
+
+	if _, err := store.NewCatalog(database.DB, nil).Upsert(ctx, []store.CatalogEntry{
+		{Name: "IT Deadlift", Type: "Strength", BodyPart: "Back", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Hamstrings"}},
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	deadlift := catalogID(t, ctx, tx, "it-deadlift")
+	settings := store.NewSettings(database.DB)
+	def := 90
+	if _, err := settings.Update(ctx, userID, store.UpdateSettingsParams{DefaultRestSeconds: &def}); err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	if _, err := settings.SetExerciseRest(ctx, userID, deadlift, 180); err != nil {
+		t.Fatalf("exercise rest: %v", err)
+	}
+
+	batch := ops(t,
+		map[string]any{"type": "createExercise", "localId": "ex", "dayId": dayID, "catalogId": deadlift, "position": 0},
+		map[string]any{"type": "createSet", "localId": "s1", "exerciseId": "ex", "position": 0, "reps": 5, "weightKg": 100},
+		map[string]any{"type": "createSet", "localId": "s2", "exerciseId": "ex", "position": 1, "reps": 5, "weightKg": 110},
+		map[string]any{"type": "createSet", "localId": "s3", "exerciseId": "ex", "position": 2, "reps": 5, "weightKg": 120},
+	)
+	mapping, _, err := store.NewSave(database.DB).ProcessBatch(store.WithAutoRest(ctx), userID, batch, "it-auto-rest")
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(mapping.AutoRests) != 2 {
+		t.Fatalf("auto rests = %+v, want 2", mapping.AutoRests)
+	}
+	for i, r := range mapping.AutoRests {
+		if r.DurationSeconds != 180 {
+			t.Errorf("rest %d duration = %d, want the per-exercise 180", i, r.DurationSeconds)
+		}
+	}
+	var positions []string
+	if err := tx.Tx().SelectContext(ctx, &positions, `
+		select kind || position from (
+		  select 's' as kind, position from sets where exercise_id = $1
+		  union all
+		  select 'r', position from rest_periods where exercise_id = $1
+		) x order by position`, mapping.Exercises[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(positions) != "[s0 r1 s2 r3 s4]" {
+		t.Errorf("items = %v, want [s0 r1 s2 r3 s4]", positions)
+	}
+}
+
+func TestMuscleAliasesIntegration(t *testing.T) {
+	ctx, _ := testutil.Tx(t)
+	catalog := store.NewCatalog(testutil.DB(t).DB, nil)
+	entry := func(name string, muscles ...string) store.CatalogEntry {
+		return store.CatalogEntry{Name: name, Type: "Strength", BodyPart: "Back", Equipment: "Cable", Level: "Beginner", PrimaryMuscles: muscles}
+	}
+	if _, err := catalog.Upsert(ctx, []store.CatalogEntry{
+		entry("IT Pulldown", "ITLats"),
+		entry("IT Pullover", "IT Latissimus", "itlats"),
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	// "itlats" matched the canonical spelling case-insensitively.
+	rec, err := catalog.GetCatalogEntryBySlug(ctx, "it-pullover")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(rec.PrimaryMuscles) != 2 {
+		t.Fatalf("primary = %v, want IT Latissimus and ITLats", rec.PrimaryMuscles)
+	}
+
+	if _, err := catalog.MergeMuscles(ctx, "ITLats", "ITLats"); !errors.Is(err, store.ErrMuscleMergeSelf) {
+		t.Fatalf("self merge err = %v", err)
+	}
+	res, err := catalog.MergeMuscles(ctx, "IT Latissimus", "ITLats")
+	if err != nil {
+		t.Fatalf("MergeMuscles: %v", err)
+	}
+	if res.Primary != 1 || len(res.Aliases) != 1 || res.Aliases[0] != "it latissimus" {
+		t.Fatalf("merge result = %+v", res)
+	}
+	if _, err := catalog.Upsert(ctx, []store.CatalogEntry{entry("IT Straight Arm Pulldown", " it LATISSIMUS ")}); err != nil {
+		t.Fatalf("upsert after merge: %v", err)
+	}
+	for _, slug := range []string{"it-pullover", "it-straight-arm-pulldown"} {
+		rec, err := catalog.GetCatalogEntryBySlug(ctx, slug)
+		if err != nil {
+			t.Fatalf("get %s: %v", slug, err)
+		}
+		if len(rec.PrimaryMuscles) != 1 || rec.PrimaryMuscles[0] != "ITLats" {
+			t.Fatalf("%s primary = %v, want [ITLats]", slug, rec.PrimaryMuscles)
+		}
+	}
+}
+
+func TestStatRollupsIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, dayID := seedUser(t, ctx, "rollups@example.com")
+	if _, err := store.NewCatalog(database.DB, nil).Upsert(ctx, []store.CatalogEntry{
+		{Name: "IT Curl", Type: "Strength", BodyPart: "Arms", Equipment: "Dumbbell", Level: "Beginner", PrimaryMuscles: []string{"Biceps"}},
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	curl := catalogID(t, ctx, tx, "it-curl")
+	mapping, _, err := store.NewSave(database.DB).ProcessBatch(ctx, userID, ops(t,
+		map[string]any{"type": "createExercise", "localId": "ex", "dayId": dayID, "catalogId": curl, "position": 0},
+		map[string]any{"type": "createSet", "localId": "w", "exerciseId": "ex", "position": 0, "reps": 10, "weightKg": 5, "isWarmup": true},
+		map[string]any{"type": "createSet", "localId": "s1", "exerciseId": "ex", "position": 1, "reps": 10, "weightKg": 12},
+		map[string]any{"type": "createSet", "localId": "s2", "exerciseId": "ex", "position": 2, "reps": 8, "weightKg": 14},
+	), "it-rollups")
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	analytics := store.NewAnalytics(database.DB)
+	load := func() store.WeeklyLoad {
+		t.Helper()
+		weeks, err := analytics.TrainingLoad(ctx, userID, 1, time.Now())
+		if err != nil || len(weeks) != 1 {
+			t.Fatalf("TrainingLoad = %v, %v", weeks, err)
+		}
+		return weeks[0]
+	}
+	if w := load(); w.Sets != 2 || w.TonnageKg != 232 {
+		t.Fatalf("week = %+v, want 2 working sets and 232 kg", w)
+	}
+
+	if _, err := tx.Tx().ExecContext(ctx, `delete from sets where id = $1`, mapping.Sets[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	if w := load(); w.Sets != 1 || w.TonnageKg != 120 {
+		t.Fatalf("after delete week = %+v, want 1 working set and 120 kg", w)
+	}
+
+	// A stale row is repaired by a recompute.
+	if _, err := tx.Tx().ExecContext(ctx, `update stats_daily set tonnage_kg = 0 where user_id = $1`, userID); err != nil {
+		t.Fatal(err)
+	}
+	if days, err := analytics.RecomputeStats(ctx, userID); err != nil || days != 1 {
+		t.Fatalf("RecomputeStats = %d, %v", days, err)
+	}
+	if w := load(); w.TonnageKg != 120 {
+		t.Fatalf("after recompute week = %+v", w)
+	}
+}
+
+func TestFriendsFeedIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	database := testutil.DB(t)
+	alice, aliceDay := seedUser(t, ctx, "feed-alice@example.com")
+	bob, _ := seedUser(t, ctx, "feed-bob@example.com")
+
+	friends := store.NewFriends(database.DB)
+	req, err := friends.Request(ctx, alice, "feed-alice@example.com", " Feed-Bob@example.com ")
+	if err != nil || req.Status != store.FriendshipPending || req.Incoming {
+		t.Fatalf("Request = %+v, %v", req, err)
+	}
+	// Bob asking Alice back accepts her request.
+	back, err := friends.Request(ctx, bob, "feed-bob@example.com", "feed-alice@example.com")
+	if err != nil || back.ID != req.ID || back.Status != store.FriendshipAccepted || !back.Incoming {
+		t.Fatalf("reverse Request = %+v, %v", back, err)
+	}
+
+	if _, err := store.NewCatalog(database.DB, nil).Upsert(ctx, []store.CatalogEntry{
+		{Name: "IT Squat", Type: "Strength", BodyPart: "Legs", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Quadriceps"}},
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	squat := catalogID(t, ctx, tx, "it-squat")
+	if _, _, err := store.NewSave(database.DB).ProcessBatch(ctx, alice, ops(t,
+		map[string]any{"type": "createExercise", "localId": "ex", "dayId": aliceDay, "catalogId": squat, "position": 0},
+		map[string]any{"type": "createSet", "localId": "s1", "exerciseId": "ex", "position": 0, "reps": 5, "weightKg": 100},
+	), "it-feed"); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	// The journal entries of this transaction are above any snapshot xmin,
+	// so summarize the day directly.
+	if _, err := tx.Tx().ExecContext(ctx, `select refresh_feed_items(array[$1::uuid])`, aliceDay); err != nil {
+		t.Fatal(err)
+	}
+
+	feed := store.NewFeed(database.DB)
+	items, err := feed.List(ctx, bob, nil, 20)
+	if err != nil || len(items) != 0 {
+		t.Fatalf("feed of a private day = %+v, %v", items, err)
+	}
+	if _, err := store.NewDays(database.DB).SetVisibility(ctx, alice, aliceDay, store.VisibilityFriends); err != nil {
+		t.Fatal(err)
+	}
+	items, err = feed.List(ctx, bob, nil, 20)
+	if err != nil || len(items) != 1 || items[0].WorkingSets != 1 || items[0].TonnageKg != 500 {
+		t.Fatalf("feed = %+v, %v", items, err)
+	}
+	if items, err := feed.List(ctx, alice, nil, 20); err != nil || len(items) != 0 {
+		t.Fatalf("own feed = %+v, %v", items, err)
+	}
+
+	if ok, err := friends.Remove(ctx, req.ID, bob, "feed-bob@example.com"); err != nil || !ok {
+		t.Fatalf("Remove = %v, %v", ok, err)
+	}
+	if items, err := feed.List(ctx, bob, nil, 20); err != nil || len(items) != 0 {
+		t.Fatalf("feed after unfriending = %+v, %v", items, err)
+	}
+}
+
+func TestUsersPurgeClearsJournalIntegration(t *testing.T) {
+	ctx, tx := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, dayID := seedUser(t, ctx, "purge-journal@example.com")
+
+	catalog := store.NewCatalog(database.DB, nil)
+	if _, err := catalog.Upsert(ctx, []store.CatalogEntry{
+		{Name: "IT Purge Squat", Type: "Strength", BodyPart: "Legs", Equipment: "Barbell", Level: "Beginner", PrimaryMuscles: []string{"Quadriceps"}},
+	}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	batch := ops(t,
+		map[string]any{"type": "createExercise", "localId": "ex", "dayId": dayID, "catalogId": catalogID(t, ctx, tx, "it-purge-squat"), "position": 0},
+		map[string]any{"type": "createSet", "localId": "s1", "exerciseId": "ex", "position": 0, "reps": 5, "weightKg": 100},
+	)
+	if _, _, err := store.NewSave(database.DB).ProcessBatch(ctx, userID, batch, "it-purge"); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if err := store.NewUsers(database.DB).Purge(ctx, userID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	var journal int
+	if err := tx.Tx().GetContext(ctx, &journal, `select count(*) from change_journal where user_id = $1`, userID); err != nil {
+		t.Fatal(err)
+	}
+	if journal != 0 {
+		t.Errorf("journal rows after purge = %d, want 0", journal)
+	}
+}
+
+func TestFinishSessionOrderIntegration(t *testing.T) {
+	ctx, _ := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, dayID := seedUser(t, ctx, "session-order@example.com")
+
+	days := store.NewDays(database.DB)
+	start := time.Now().UTC()
+	if _, err := days.StartSession(ctx, userID, dayID, start); err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	if _, err := days.FinishSession(ctx, userID, dayID, start.Add(-time.Hour)); !errors.Is(err, store.ErrSessionOrder) {
+		t.Fatalf("FinishSession before start = %v, want ErrSessionOrder", err)
+	}
+}
+
+func TestScheduleDeletionRevokesSessionsIntegration(t *testing.T) {
+	ctx, _ := testutil.Tx(t)
+	database := testutil.DB(t)
+	userID, _ := seedUser(t, ctx, "schedule-deletion@example.com")
+
+	sessions := store.NewSessions(database.DB)
+	for _, agent := range []string{"phone", "laptop"} {
+		if _, err := sessions.Create(ctx, userID, agent, "127.0.0.1", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+	}
+	if err := store.NewUsers(database.DB).ScheduleDeletion(ctx, userID, time.Now().Add(24*time.Hour)); err != nil {
+		t.Fatalf("ScheduleDeletion: %v", err)
+	}
+	active, err := sessions.List(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 0 {
+		t.Errorf("active sessions after scheduling deletion = %d, want 0", len(active))
+	}
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// Journal sources. Triggers journal every write to days, exercises, sets and
+// rests (migration 031); the source tells where it came from.
+const (
+	JournalSourceREST      = "rest"
+	JournalSourceSaveBatch = "save-batch"
+	JournalSourceUndo      = "undo"
+)
+
+// ErrNothingToUndo means the user has no change left to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrUndoConflict means reverting the change would break a constraint, for
+// example restoring an exercise on a day since marked as a rest day.
+var ErrUndoConflict = errors.New("change can no longer be undone")
+
+type Journal struct {
+	db *sqlx.DB
+}
+
+func NewJournal(db *sqlx.DB) *Journal { return &Journal{db: db} }
+
+// JournalEntry is one journaled row change. Before and After hold the whole
+// row for deletes and inserts, and only the changed fields for updates.
+type JournalEntry struct {
+	ID        int64           `db:"id" json:"id"`
+	DayID     *string         `db:"day_id" json:"dayId,omitempty"`
+	TxID      int64           `db:"tx_id" json:"-"`
+	Entity    string          `db:"entity" json:"entity"`
+	EntityID  string          `db:"entity_id" json:"entityId"`
+	Action    string          `db:"action" json:"action"`
+	Before    json.RawMessage `db:"before" json:"before,omitempty"`
+	After     json.RawMessage `db:"after" json:"after,omitempty"`
+	Source    string          `db:"source" json:"source"`
+	OpIndex   *int            `db:"op_index" json:"opIndex,omitempty"`
+	CreatedAt time.Time       `db:"created_at" json:"createdAt"`
+	UndoneAt  *time.Time      `db:"undone_at" json:"undoneAt,omitempty"`
+}
+
+const journalColumns = `id, day_id, tx_id, entity, entity_id, action, before, after, source, op_index, created_at, undone_at`
+
+// setJournalSource tags the journal entries the rest of tx writes. opIndex
+// is the save op being applied, or -1 for none.
+func setJournalSource(ctx context.Context, tx sqlx.ExecerContext, source string, opIndex int) error {
+	op := ""
+	if opIndex >= 0 {
+		op = strconv.Itoa(opIndex)
+	}
+	_, err := tx.ExecContext(ctx, `select set_config('fitlog.journal_source', $1, true), set_config('fitlog.journal_op', $2, true)`, source, op)
+	return err
+}
+
+// DayHistory returns the latest changes to one of the user's days and
+// everything on it, newest first.
+func (s *Journal) DayHistory(ctx context.Context, userID, dayID string, limit int) ([]JournalEntry, error) {
+	out := []JournalEntry{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select `+journalColumns+`
+		from change_journal
+		where user_id = $1 and day_id = $2
+		order by id desc
+		limit $3
+	`, userID, dayID, limit)
+	return out, err
+}
+
+// Undo reverts the user's most recent change that isn't an undo itself: all
+// journal entries of the transaction that wrote it. It returns the reverted
+// entries, or ErrNothingToUndo.
+func (s *Journal) Undo(ctx context.Context, userID string) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		var txID int64
+		if err := tx.GetContext(ctx, &txID, `
+			select tx_id from change_journal
+			where user_id = $1 and undone_at is null and source <> $2
+			order by id desc
+			limit 1
+			for update
+		`, userID, JournalSourceUndo); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNothingToUndo
+			}
+			return err
+		}
+		if err := tx.SelectContext(ctx, &entries, `
+			update change_journal set undone_at = now()
+			where user_id = $1 and tx_id = $2 and undone_at is null and source <> $3
+			returning `+journalColumns, userID, txID, JournalSourceUndo); err != nil {
+			return err
+		}
+		if err := setJournalSource(ctx, tx, JournalSourceUndo, -1); err != nil {
+			return err
+		}
+		for _, e := range undoOrder(entries) {
+			if _, err := tx.ExecContext(ctx, `select journal_revert($1)`, e.ID); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && len(pgErr.Code) == 5 && pgErr.Code[:2] == "23" {
+					return ErrUndoConflict
+				}
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// undoOrder sorts one transaction's entries for reverting: inserted rows are
+// deleted newest first, then deleted rows restored oldest first (parents were
+// journaled before the children their delete cascaded to), then updates
+// rolled back newest first. A row both inserted and deleted is left alone.
+func undoOrder(entries []JournalEntry) []JournalEntry {
+	inserted := map[string]bool{}
+	for _, e := range entries {
+		if e.Action == "insert" {
+			inserted[e.EntityID] = true
+		}
+	}
+	var inserts, deletes, updates []JournalEntry
+	for _, e := range entries {
+		switch e.Action {
+		case "insert":
+			inserts = append(inserts, e)
+		case "delete":
+			if !inserted[e.EntityID] {
+				deletes = append(deletes, e)
+			}
+		case "update":
+			updates = append(updates, e)
+		}
+	}
+	byID := func(list []JournalEntry, desc bool) {
+		sort.SliceStable(list, func(i, j int) bool {
+			if desc {
+				return list[i].ID > list[j].ID
+			}
+			return list[i].ID < list[j].ID
+		})
+	}
+	byID(inserts, true)
+	byID(deletes, false)
+	byID(updates, true)
+	out := make([]JournalEntry, 0, len(entries))
+	out = append(out, inserts...)
+	out = append(out, deletes...)
+	return append(out, updates...)
+}
+
+// Prune drops journal entries older than before.
+func (s *Journal) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from change_journal where created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
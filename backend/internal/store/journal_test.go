@@ -0,0 +1,25 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUndoOrder(t *testing.T) {
+	entries := []JournalEntry{
+		{ID: 1, Action: "update", EntityID: "day"},
+		{ID: 2, Action: "delete", EntityID: "ex"},
+		{ID: 3, Action: "delete", EntityID: "set-1"},
+		{ID: 4, Action: "insert", EntityID: "set-2"},
+		{ID: 5, Action: "update", EntityID: "set-2"},
+		{ID: 6, Action: "insert", EntityID: "tmp"},
+		{ID: 7, Action: "delete", EntityID: "tmp"},
+	}
+	var got []int64
+	for _, e := range undoOrder(entries) {
+		got = append(got, e.ID)
+	}
+	if want := "[6 4 2 3 5 1]"; fmt.Sprint(got) != want {
+		t.Fatalf("undo order = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/progression"
+)
+
+// LeaderboardMaxReps is the most reps a set may have to rank: estimated 1RMs
+// drift too far from tested maxes beyond it.
+const LeaderboardMaxReps = 12
+
+// ErrLeaderboardNameRequired is returned when a profile would show the
+// user's identity without a display name.
+var ErrLeaderboardNameRequired = errors.New("displayName is required to show your identity")
+
+type Leaderboards struct {
+	db *sqlx.DB
+}
+
+func NewLeaderboards(db *sqlx.DB) *Leaderboards { return &Leaderboards{db: db} }
+
+// LeaderboardProfile is a user's leaderboard consent and privacy choice. The
+// zero value, opted out with identity hidden, is what users without a row
+// get.
+type LeaderboardProfile struct {
+	OptedIn      bool       `db:"opted_in" json:"optedIn"`
+	HideIdentity bool       `db:"hide_identity" json:"hideIdentity"`
+	DisplayName  *string    `db:"display_name" json:"displayName"`
+	UpdatedAt    *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
+}
+
+const leaderboardProfileColumns = `opted_in, hide_identity, display_name, updated_at`
+
+func (s *Leaderboards) Profile(ctx context.Context, userID string) (LeaderboardProfile, error) {
+	var out LeaderboardProfile
+	err := conn(ctx, s.db).GetContext(ctx, &out, `select `+leaderboardProfileColumns+` from leaderboard_profiles where user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return LeaderboardProfile{HideIdentity: true}, nil
+	}
+	return out, err
+}
+
+type UpdateLeaderboardProfileParams struct {
+	OptedIn      *bool
+	HideIdentity *bool
+	// DisplayName sets the name shown when identity isn't hidden; "" clears
+	// it.
+	DisplayName *string
+}
+
+// UpdateProfile changes the given fields, creating the row from defaults
+// first.
+func (s *Leaderboards) UpdateProfile(ctx context.Context, userID string, p UpdateLeaderboardProfileParams) (LeaderboardProfile, error) {
+	q := `
+		insert into leaderboard_profiles (user_id, opted_in, hide_identity, display_name)
+		values ($1, coalesce($2, false), coalesce($3, true), nullif($4, ''))
+		on conflict (user_id) do update
+		set opted_in = coalesce($2, leaderboard_profiles.opted_in),
+		    hide_identity = coalesce($3, leaderboard_profiles.hide_identity),
+		    display_name = case when $4::text is null then leaderboard_profiles.display_name else nullif($4, '') end,
+		    updated_at = now()
+		returning ` + leaderboardProfileColumns
+	var out LeaderboardProfile
+	err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, p.OptedIn, p.HideIdentity, p.DisplayName).StructScan(&out)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "leaderboard_profiles_display_name_required" {
+		return LeaderboardProfile{}, ErrLeaderboardNameRequired
+	}
+	return out, err
+}
+
+// LeaderboardEntry is one user's best relative-strength day: the best
+// estimated 1RM of the day over the bodyweight measured closest to it.
+type LeaderboardEntry struct {
+	Rank int `db:"rank" json:"rank"`
+	// Name is nil for users who hide their identity.
+	Name         *string `db:"name" json:"name"`
+	You          bool    `db:"you" json:"you"`
+	E1RMKg       float64 `db:"e1rm_kg" json:"e1rmKg"`
+	BodyweightKg float64 `db:"bodyweight_kg" json:"bodyweightKg"`
+	Ratio        float64 `db:"ratio" json:"ratio"`
+	Date         string  `db:"date" json:"date"`
+}
+
+type Leaderboard struct {
+	CatalogID string              `json:"catalogId"`
+	Formula   progression.Formula `json:"formula"`
+	Entries   []LeaderboardEntry  `json:"entries"`
+	// You is the viewer's entry, also when it ranks below the top entries;
+	// nil unless the viewer has opted in and has a ranked set.
+	You *LeaderboardEntry `json:"you"`
+}
+
+// Leaderboard ranks opted-in users by relative strength on a catalog
+// exercise and returns the top `limit` ranks. Only working strength sets of
+// at most LeaderboardMaxReps reps count, and users without a bodyweight
+// entry don't rank. Guests and accounts pending deletion are left out. It
+// returns sql.ErrNoRows when the catalog entry doesn't exist.
+func (s *Leaderboards) Leaderboard(ctx context.Context, catalogID, viewerID string, formula progression.Formula, limit int) (*Leaderboard, error) {
+	var exists bool
+	if err := conn(ctx, s.db).GetContext(ctx, &exists, `select exists (select 1 from exercise_catalog where id = $1)`, catalogID); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	q := `
+		with daily as (
+		  select s.user_id, s.workout_date, max(` + e1rmExpr(formula) + `)::float8 as e1rm
+		  from sets s
+		  join exercises e on e.id = s.exercise_id
+		  join leaderboard_profiles lp on lp.user_id = s.user_id and lp.opted_in
+		  join users u on u.id = s.user_id and u.deleted_at is null and not u.is_guest
+		  where e.catalog_id = $1 and s.is_warmup = false and s.set_type = 'strength'
+		    and s.reps between 1 and $3 and coalesce(s.effective_load_kg, s.weight_kg) > 0
+		  group by s.user_id, s.workout_date
+		), scored as (
+		  select d.user_id, d.workout_date, d.e1rm, bw.weight_kg::float8 as bodyweight_kg, d.e1rm / bw.weight_kg::float8 as ratio
+		  from daily d
+		  cross join lateral (
+		    select b.weight_kg
+		    from bodyweight_entries b
+		    where b.user_id = d.user_id
+		    order by (b.measured_on > d.workout_date), abs(b.measured_on - d.workout_date)
+		    limit 1
+		  ) bw
+		), best as (
+		  select distinct on (user_id) *
+		  from scored
+		  order by user_id, ratio desc, workout_date
+		), ranked as (
+		  select b.*, rank() over (order by round(b.ratio::numeric, 3) desc) as rank
+		  from best b
+		)
+		select r.rank,
+		       case when lp.hide_identity then null else lp.display_name end as name,
+		       r.user_id = $2 as you,
+		       round(r.e1rm::numeric, 2)::float8 as e1rm_kg,
+		       round(r.bodyweight_kg::numeric, 2)::float8 as bodyweight_kg,
+		       round(r.ratio::numeric, 3)::float8 as ratio,
+		       to_char(r.workout_date, 'YYYY-MM-DD') as date
+		from ranked r
+		join leaderboard_profiles lp on lp.user_id = r.user_id
+		where r.rank <= $4 or r.user_id = $2
+		order by r.rank, r.workout_date
+	`
+	var rows []LeaderboardEntry
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, q, catalogID, viewerID, LeaderboardMaxReps, limit); err != nil {
+		return nil, err
+	}
+	out := &Leaderboard{CatalogID: catalogID, Formula: formula, Entries: []LeaderboardEntry{}}
+	for _, r := range rows {
+		if r.You {
+			you := r
+			out.You = &you
+		}
+		if r.Rank <= limit {
+			out.Entries = append(out.Entries, r)
+		}
+	}
+	return out, nil
+}
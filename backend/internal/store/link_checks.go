@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLinkFailures is how many checks in a row must find a link gone before
+// it is reported dead, so a brief outage doesn't flag it.
+const DeadLinkFailures = 2
+
+// LinkCheck is the outcome of checking one catalog link. StatusCode is nil
+// when the request failed before a response.
+type LinkCheck struct {
+	URL        string
+	Dead       bool
+	StatusCode *int
+	Error      *string
+}
+
+// DeadLink is a dead URL and one catalog entry that still links to it.
+type DeadLink struct {
+	URL          string    `db:"url" json:"url"`
+	CatalogID    string    `db:"catalog_id" json:"catalogId"`
+	Name         string    `db:"name" json:"name"`
+	StatusCode   *int      `db:"status_code" json:"statusCode,omitempty"`
+	Error        *string   `db:"error" json:"error,omitempty"`
+	Failures     int       `db:"failures" json:"failures"`
+	FailingSince time.Time `db:"failing_since" json:"failingSince"`
+	CheckedAt    time.Time `db:"checked_at" json:"checkedAt"`
+}
+
+// DueLinkChecks returns catalog links never checked or last checked before
+// checkedBefore, least recently checked first.
+func (s *Catalog) DueLinkChecks(ctx context.Context, checkedBefore time.Time, limit int) ([]string, error) {
+	var out []string
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select u.url
+		from (select distinct unnest(links) as url from exercise_catalog) u
+		left join link_checks lc on lc.url = u.url
+		where lc.url is null or lc.checked_at < $1
+		order by lc.checked_at nulls first, u.url
+		limit $2`, checkedBefore, limit)
+	return out, err
+}
+
+// SaveLinkCheck records c, counting consecutive dead results.
+func (s *Catalog) SaveLinkCheck(ctx context.Context, c LinkCheck) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `
+		insert into link_checks (url, status_code, error, failures, failing_since, checked_at)
+		values ($1, $2, $3, case when $4 then 1 else 0 end, case when $4 then now() end, now())
+		on conflict (url) do update
+		set status_code = excluded.status_code,
+		    error = excluded.error,
+		    failures = case when $4 then link_checks.failures + 1 else 0 end,
+		    failing_since = case when $4 then coalesce(link_checks.failing_since, now()) end,
+		    checked_at = now()`,
+		c.URL, c.StatusCode, c.Error, c.Dead)
+	return err
+}
+
+// DeadLinks lists links that failed at least DeadLinkFailures checks in a
+// row, with each catalog entry that still uses them, longest dead first.
+func (s *Catalog) DeadLinks(ctx context.Context, limit int) ([]DeadLink, error) {
+	out := []DeadLink{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select lc.url, ec.id as catalog_id, ec.name, lc.status_code, lc.error, lc.failures, lc.failing_since, lc.checked_at
+		from link_checks lc
+		join exercise_catalog ec on lc.url = any(ec.links)
+		where lc.failures >= $1
+		order by lc.failing_since, lc.url, ec.name
+		limit $2`, DeadLinkFailures, limit)
+	return out, err
+}
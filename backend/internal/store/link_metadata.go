@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Link metadata fetch outcomes.
+const (
+	LinkMetadataOK     = "ok"
+	LinkMetadataFailed = "failed"
+)
+
+type LinkMetadata struct {
+	URL          string    `db:"url" json:"url"`
+	Status       string    `db:"status" json:"status"`
+	Provider     *string   `db:"provider" json:"provider,omitempty"`
+	Title        *string   `db:"title" json:"title,omitempty"`
+	ThumbnailURL *string   `db:"thumbnail_url" json:"thumbnailUrl,omitempty"`
+	Error        *string   `db:"error" json:"-"`
+	FetchedAt    time.Time `db:"fetched_at" json:"fetchedAt"`
+}
+
+// LinkPreview is a catalog link with whatever metadata has been fetched for
+// it so far.
+type LinkPreview struct {
+	URL          string  `json:"url"`
+	Provider     *string `json:"provider,omitempty"`
+	Title        *string `json:"title,omitempty"`
+	ThumbnailURL *string `json:"thumbnailUrl,omitempty"`
+}
+
+// SaveLinkMetadata stores the result of fetching m.URL.
+func (s *Catalog) SaveLinkMetadata(ctx context.Context, m LinkMetadata) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `
+		insert into link_metadata (url, status, provider, title, thumbnail_url, error, fetched_at)
+		values ($1, $2, $3, $4, $5, $6, now())
+		on conflict (url) do update
+		set status = excluded.status, provider = excluded.provider, title = excluded.title,
+		    thumbnail_url = excluded.thumbnail_url, error = excluded.error, fetched_at = now()`,
+		m.URL, m.Status, m.Provider, m.Title, m.ThumbnailURL, m.Error)
+	if err != nil {
+		return err
+	}
+	AfterCommit(ctx, s.cache.invalidate)
+	return nil
+}
+
+// PendingLinks returns catalog links that have no metadata yet, or whose
+// last fetch failed before retryFailedBefore.
+func (s *Catalog) PendingLinks(ctx context.Context, retryFailedBefore time.Time, limit int) ([]string, error) {
+	var out []string
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select distinct u.url
+		from exercise_catalog ec
+		cross join lateral unnest(ec.links) as u(url)
+		left join link_metadata lm on lm.url = u.url
+		where lm.url is null or (lm.status = 'failed' and lm.fetched_at < $1)
+		order by u.url
+		limit $2`, retryFailedBefore, limit)
+	return out, err
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Nutrition struct {
+	db *sqlx.DB
+}
+
+func NewNutrition(db *sqlx.DB) *Nutrition { return &Nutrition{db: db} }
+
+type NutritionEntry struct {
+	ID        string    `db:"id" json:"id"`
+	EatenOn   string    `db:"eaten_on" json:"date"`
+	Calories  int       `db:"calories" json:"calories"`
+	ProteinG  float64   `db:"protein_g" json:"proteinG"`
+	CarbsG    *float64  `db:"carbs_g" json:"carbsG,omitempty"`
+	FatG      *float64  `db:"fat_g" json:"fatG,omitempty"`
+	Note      *string   `db:"note" json:"note,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type NutritionParams struct {
+	Date     time.Time
+	Calories int
+	ProteinG float64
+	CarbsG   *float64
+	FatG     *float64
+	Note     *string
+}
+
+const nutritionColumns = `id, to_char(eaten_on, 'YYYY-MM-DD') as eaten_on, calories, protein_g::float8 as protein_g,
+	carbs_g::float8 as carbs_g, fat_g::float8 as fat_g, note, created_at, updated_at`
+
+// Log adds an entry; a day's entries are summed.
+func (s *Nutrition) Log(ctx context.Context, userID string, p NutritionParams) (*NutritionEntry, error) {
+	q := `
+		insert into nutrition_entries (user_id, eaten_on, calories, protein_g, carbs_g, fat_g, note)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		returning ` + nutritionColumns
+	var out NutritionEntry
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, p.Date.Format("2006-01-02"),
+		p.Calories, p.ProteinG, p.CarbsG, p.FatG, p.Note).StructScan(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns entries between from and to (inclusive, either may be nil),
+// newest first.
+func (s *Nutrition) List(ctx context.Context, userID string, from, to *time.Time) ([]NutritionEntry, error) {
+	out := []NutritionEntry{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select `+nutritionColumns+`
+		from nutrition_entries
+		where user_id = $1
+		  and ($2::date is null or eaten_on >= $2::date)
+		  and ($3::date is null or eaten_on <= $3::date)
+		order by eaten_on desc, created_at desc`, userID, dateArg(from), dateArg(to))
+	return out, err
+}
+
+func (s *Nutrition) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from nutrition_entries where id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// NutritionDay is one date of the dashboard: the day's summed intake next to
+// its training from the stat rollups.
+type NutritionDay struct {
+	Date     string   `db:"date" json:"date"`
+	Entries  int      `db:"entries" json:"entries"`
+	Calories int      `db:"calories" json:"calories"`
+	ProteinG float64  `db:"protein_g" json:"proteinG"`
+	CarbsG   *float64 `db:"carbs_g" json:"carbsG"`
+	FatG     *float64 `db:"fat_g" json:"fatG"`
+	// WorkingSets and TonnageKg are zero on days without training.
+	WorkingSets int     `db:"working_sets" json:"workingSets"`
+	TonnageKg   float64 `db:"tonnage_kg" json:"tonnageKg"`
+	IsRestDay   bool    `db:"is_rest_day" json:"isRestDay"`
+}
+
+// IntakeAverage averages the intake of the logged days in a group.
+type IntakeAverage struct {
+	Days     int     `json:"days"`
+	Calories float64 `json:"calories"`
+	ProteinG float64 `json:"proteinG"`
+}
+
+type NutritionDashboard struct {
+	From string         `json:"from"`
+	To   string         `json:"to"`
+	Days []NutritionDay `json:"days"`
+	// Logged averages every day with an entry; Training and Rest split them
+	// by whether the day had working sets.
+	Logged   IntakeAverage `json:"logged"`
+	Training IntakeAverage `json:"training"`
+	Rest     IntakeAverage `json:"rest"`
+	// CaloriesVolumeCorrelation is Pearson's r between calories and tonnage
+	// over logged training days; nil with fewer than three or no variation.
+	CaloriesVolumeCorrelation *float64 `json:"caloriesVolumeCorrelation"`
+}
+
+// Dashboard returns every date from from to to, oldest first, with the
+// averages and correlation over them.
+func (s *Nutrition) Dashboard(ctx context.Context, userID string, from, to time.Time) (*NutritionDashboard, error) {
+	days := []NutritionDay{}
+	err := conn(ctx, s.db).SelectContext(ctx, &days, `
+		select to_char(g.date, 'YYYY-MM-DD') as date,
+		       coalesce(n.entries, 0) as entries, coalesce(n.calories, 0) as calories,
+		       coalesce(n.protein_g, 0)::float8 as protein_g, n.carbs_g::float8 as carbs_g, n.fat_g::float8 as fat_g,
+		       coalesce(sd.working_sets, 0) as working_sets, coalesce(sd.tonnage_kg, 0)::float8 as tonnage_kg,
+		       coalesce(d.is_rest_day, false) as is_rest_day
+		from generate_series($2::date, $3::date, interval '1 day') as g(date)
+		left join (
+		  select eaten_on, count(*) as entries, sum(calories) as calories, sum(protein_g) as protein_g,
+		         sum(carbs_g) as carbs_g, sum(fat_g) as fat_g
+		  from nutrition_entries
+		  where user_id = $1 and eaten_on between $2::date and $3::date
+		  group by eaten_on
+		) n on n.eaten_on = g.date
+		left join stats_daily sd on sd.user_id = $1 and sd.workout_date = g.date
+		left join workout_days d on d.user_id = $1 and d.workout_date = g.date
+		order by g.date`, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	out := summarizeNutrition(days)
+	out.From, out.To = from.Format("2006-01-02"), to.Format("2006-01-02")
+	return out, nil
+}
+
+func summarizeNutrition(days []NutritionDay) *NutritionDashboard {
+	out := &NutritionDashboard{Days: days}
+	var cals, tonnage []float64
+	add := func(a *IntakeAverage, d NutritionDay) {
+		a.Days++
+		a.Calories += float64(d.Calories)
+		a.ProteinG += d.ProteinG
+	}
+	for _, d := range days {
+		if d.Entries == 0 {
+			continue
+		}
+		add(&out.Logged, d)
+		if d.WorkingSets > 0 {
+			add(&out.Training, d)
+			cals = append(cals, float64(d.Calories))
+			tonnage = append(tonnage, d.TonnageKg)
+		} else {
+			add(&out.Rest, d)
+		}
+	}
+	for _, a := range []*IntakeAverage{&out.Logged, &out.Training, &out.Rest} {
+		if a.Days > 0 {
+			a.Calories = roundTo(a.Calories/float64(a.Days), 0)
+			a.ProteinG = roundTo(a.ProteinG/float64(a.Days), 1)
+		}
+	}
+	out.CaloriesVolumeCorrelation = correlation(cals, tonnage)
+	return out
+}
@@ -0,0 +1,30 @@
+package store
+
+import "testing"
+
+func TestSummarizeNutrition(t *testing.T) {
+	out := summarizeNutrition([]NutritionDay{
+		{Date: "2026-03-01", Entries: 2, Calories: 2000, ProteinG: 150, WorkingSets: 10, TonnageKg: 4000},
+		{Date: "2026-03-02", Entries: 1, Calories: 1800, ProteinG: 120},
+		{Date: "2026-03-03"},
+		{Date: "2026-03-04", Entries: 3, Calories: 2600, ProteinG: 170, WorkingSets: 20, TonnageKg: 9000},
+		{Date: "2026-03-05", Entries: 1, Calories: 2300, ProteinG: 160, WorkingSets: 14, TonnageKg: 6500},
+		{Date: "2026-03-06", WorkingSets: 12, TonnageKg: 5000},
+	})
+	if out.Logged != (IntakeAverage{Days: 4, Calories: 2175, ProteinG: 150}) {
+		t.Errorf("logged = %+v", out.Logged)
+	}
+	if out.Training != (IntakeAverage{Days: 3, Calories: 2300, ProteinG: 160}) {
+		t.Errorf("training = %+v", out.Training)
+	}
+	if out.Rest != (IntakeAverage{Days: 1, Calories: 1800, ProteinG: 120}) {
+		t.Errorf("rest = %+v", out.Rest)
+	}
+	if r := out.CaloriesVolumeCorrelation; r == nil || *r != 1 {
+		t.Errorf("correlation = %v", r)
+	}
+
+	if out := summarizeNutrition(out.Days[:2]); out.CaloriesVolumeCorrelation != nil {
+		t.Errorf("correlation from one training day = %v", *out.CaloriesVolumeCorrelation)
+	}
+}
@@ -0,0 +1,361 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/models"
+)
+
+type Programs struct {
+	db *sqlx.DB
+}
+
+func NewPrograms(db *sqlx.DB) *Programs { return &Programs{db: db} }
+
+type ProgramInput struct {
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	Weeks       []ProgramWeekInput `json:"weeks"`
+}
+
+type ProgramWeekInput struct {
+	WeekNumber int               `json:"weekNumber"`
+	Name       *string           `json:"name"`
+	Days       []ProgramDayInput `json:"days"`
+}
+
+type ProgramDayInput struct {
+	DayNumber int                    `json:"dayNumber"`
+	Name      *string                `json:"name"`
+	IsRestDay bool                   `json:"isRestDay"`
+	Exercises []ProgramExerciseInput `json:"exercises"`
+}
+
+// ProgramExerciseInput plans an exercise. TargetReps is the bottom of a rep
+// range when TargetRepsMax is set; AMRAP makes the last set as many reps as
+// possible, at least TargetReps.
+type ProgramExerciseInput struct {
+	CatalogID      string   `json:"catalogId"`
+	TargetSets     int      `json:"targetSets"`
+	TargetReps     int      `json:"targetReps"`
+	TargetRepsMax  *int     `json:"targetRepsMax"`
+	AMRAP          bool     `json:"amrap"`
+	TargetWeightKg *float64 `json:"targetWeightKg"`
+	Notes          *string  `json:"notes"`
+}
+
+// Validate checks the structural rules the schema would otherwise reject with
+// an opaque constraint error.
+func (p ProgramInput) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	weeks := make(map[int]struct{})
+	for _, w := range p.Weeks {
+		if w.WeekNumber <= 0 {
+			return errors.New("weekNumber must be > 0")
+		}
+		if _, dup := weeks[w.WeekNumber]; dup {
+			return fmt.Errorf("duplicate weekNumber %d", w.WeekNumber)
+		}
+		weeks[w.WeekNumber] = struct{}{}
+		days := make(map[int]struct{})
+		for _, d := range w.Days {
+			if d.DayNumber < 1 || d.DayNumber > 7 {
+				return errors.New("dayNumber must be between 1 and 7")
+			}
+			if _, dup := days[d.DayNumber]; dup {
+				return fmt.Errorf("duplicate dayNumber %d in week %d", d.DayNumber, w.WeekNumber)
+			}
+			days[d.DayNumber] = struct{}{}
+			if d.IsRestDay && len(d.Exercises) > 0 {
+				return fmt.Errorf("rest day %d in week %d cannot have exercises", d.DayNumber, w.WeekNumber)
+			}
+			for _, ex := range d.Exercises {
+				if strings.TrimSpace(ex.CatalogID) == "" {
+					return errors.New("exercise catalogId is required")
+				}
+				if ex.TargetSets <= 0 || ex.TargetReps <= 0 {
+					return errors.New("targetSets and targetReps must be > 0")
+				}
+				if ex.TargetRepsMax != nil && *ex.TargetRepsMax < ex.TargetReps {
+					return errors.New("targetRepsMax must be >= targetReps")
+				}
+				if ex.TargetWeightKg != nil && *ex.TargetWeightKg < 0 {
+					return errors.New("targetWeightKg must be >= 0")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Programs) List(ctx context.Context, userID string) ([]models.Program, error) {
+	out := []models.Program{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select id, user_id, name, description, created_at, updated_at
+		from programs
+		where user_id = $1
+		order by created_at desc`, userID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Programs) Get(ctx context.Context, userID, id string) (*models.Program, error) {
+	p := new(models.Program)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		select id, user_id, name, description, created_at, updated_at
+		from programs
+		where id = $1 and user_id = $2`, id, userID).StructScan(p); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var weeks []models.ProgramWeek
+	if err := conn(ctx, s.db).SelectContext(ctx, &weeks, `
+		select id, program_id, week_number, name
+		from program_weeks
+		where program_id = $1
+		order by week_number`, id); err != nil {
+		return nil, err
+	}
+	var days []models.ProgramDay
+	if err := conn(ctx, s.db).SelectContext(ctx, &days, `
+		select pd.id, pd.week_id, pd.day_number, pd.name, pd.is_rest_day
+		from program_days pd
+		join program_weeks pw on pw.id = pd.week_id
+		where pw.program_id = $1
+		order by pd.day_number`, id); err != nil {
+		return nil, err
+	}
+	var exercises []models.ProgramExercise
+	if err := conn(ctx, s.db).SelectContext(ctx, &exercises, `
+		select pe.id, pe.program_day_id, pe.catalog_id, ec.name, pe.position,
+		       pe.target_sets, pe.target_reps, pe.target_reps_max, pe.amrap, pe.target_weight_kg, pe.notes
+		from program_exercises pe
+		join program_days pd on pd.id = pe.program_day_id
+		join program_weeks pw on pw.id = pd.week_id
+		join exercise_catalog ec on ec.id = pe.catalog_id
+		where pw.program_id = $1
+		order by pe.position`, id); err != nil {
+		return nil, err
+	}
+	exByDay := make(map[string][]models.ProgramExercise)
+	for _, ex := range exercises {
+		exByDay[ex.ProgramDayID] = append(exByDay[ex.ProgramDayID], ex)
+	}
+	daysByWeek := make(map[string][]models.ProgramDay)
+	for _, d := range days {
+		d.Exercises = exByDay[d.ID]
+		if d.Exercises == nil {
+			d.Exercises = []models.ProgramExercise{}
+		}
+		daysByWeek[d.WeekID] = append(daysByWeek[d.WeekID], d)
+	}
+	p.Weeks = make([]models.ProgramWeek, 0, len(weeks))
+	for _, w := range weeks {
+		w.Days = daysByWeek[w.ID]
+		if w.Days == nil {
+			w.Days = []models.ProgramDay{}
+		}
+		p.Weeks = append(p.Weeks, w)
+	}
+	return p, nil
+}
+
+func (s *Programs) Create(ctx context.Context, userID string, in ProgramInput) (*models.Program, error) {
+	var id string
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowxContext(ctx, `
+			insert into programs (user_id, name, description)
+			values ($1, $2, $3)
+			returning id`, userID, strings.TrimSpace(in.Name), trimPtr(in.Description)).Scan(&id); err != nil {
+			return err
+		}
+		return insertProgramWeeks(ctx, tx, id, in.Weeks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// Replace overwrites the program's metadata and its full week/day/exercise tree.
+func (s *Programs) Replace(ctx context.Context, userID, id string, in ProgramInput) (*models.Program, error) {
+	found := false
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			update programs set name = $3, description = $4
+			where id = $1 and user_id = $2`, id, userID, strings.TrimSpace(in.Name), trimPtr(in.Description))
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+		found = true
+		if _, err := tx.ExecContext(ctx, `delete from program_weeks where program_id = $1`, id); err != nil {
+			return err
+		}
+		return insertProgramWeeks(ctx, tx, id, in.Weeks)
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *Programs) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from programs where id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// CatalogIDs maps catalog slugs to the ids of the entries the user can use:
+// approved ones and their own submissions. Unknown slugs are left out.
+func (s *Programs) CatalogIDs(ctx context.Context, userID string, slugs []string) (map[string]string, error) {
+	lower := make([]string, len(slugs))
+	for i, slug := range slugs {
+		lower[i] = strings.ToLower(slug)
+	}
+	return catalogIDsBySlug(ctx, conn(ctx, s.db), userID, lower)
+}
+
+func insertProgramWeeks(ctx context.Context, tx *sqlx.Tx, programID string, weeks []ProgramWeekInput) error {
+	for _, w := range weeks {
+		var weekID string
+		if err := tx.QueryRowxContext(ctx, `
+			insert into program_weeks (program_id, week_number, name)
+			values ($1, $2, $3)
+			returning id`, programID, w.WeekNumber, trimPtr(w.Name)).Scan(&weekID); err != nil {
+			return err
+		}
+		for _, d := range w.Days {
+			var dayID string
+			if err := tx.QueryRowxContext(ctx, `
+				insert into program_days (week_id, day_number, name, is_rest_day)
+				values ($1, $2, $3, $4)
+				returning id`, weekID, d.DayNumber, trimPtr(d.Name), d.IsRestDay).Scan(&dayID); err != nil {
+				return err
+			}
+			for pos, ex := range d.Exercises {
+				if _, err := tx.ExecContext(ctx, `
+					insert into program_exercises (program_day_id, catalog_id, position, target_sets, target_reps, target_reps_max, amrap, target_weight_kg, notes)
+					values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+					dayID, strings.TrimSpace(ex.CatalogID), pos, ex.TargetSets, ex.TargetReps, ex.TargetRepsMax, ex.AMRAP, ex.TargetWeightKg, trimPtr(ex.Notes)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type ScheduledDay struct {
+	Date   string `json:"date"`
+	DayID  string `json:"dayId"`
+	Week   int    `json:"week"`
+	Day    int    `json:"day"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ScheduleResult struct {
+	Scheduled []ScheduledDay `json:"scheduled"`
+	Skipped   []ScheduledDay `json:"skipped"`
+}
+
+// Schedule materializes the program as workout_days starting at start (day 1
+// of week 1). Dates that already hold exercises or another program's plan are
+// skipped rather than overwritten.
+func (s *Programs) Schedule(ctx context.Context, userID, id string, start time.Time) (*ScheduleResult, error) {
+	prog, err := s.Get(ctx, userID, id)
+	if err != nil || prog == nil {
+		return nil, err
+	}
+	res := &ScheduleResult{Scheduled: []ScheduledDay{}, Skipped: []ScheduledDay{}}
+	err = inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		for _, w := range prog.Weeks {
+			days := append([]models.ProgramDay(nil), w.Days...)
+			sort.Slice(days, func(i, j int) bool { return days[i].DayNumber < days[j].DayNumber })
+			for _, d := range days {
+				date := start.AddDate(0, 0, (w.WeekNumber-1)*7+d.DayNumber-1)
+				entry := ScheduledDay{Date: date.Format("2006-01-02"), Week: w.WeekNumber, Day: d.DayNumber}
+
+				var (
+					existingID   string
+					hasExercises bool
+					existingProg sql.NullString
+				)
+				err := tx.QueryRowxContext(ctx, `
+					select wd.id, wd.program_id, exists(select 1 from exercises e where e.day_id = wd.id)
+					from workout_days wd
+					where wd.user_id = $1 and wd.workout_date = $2
+					for update`, userID, date).Scan(&existingID, &existingProg, &hasExercises)
+				switch {
+				case err == sql.ErrNoRows:
+					if err := tx.QueryRowxContext(ctx, `
+						insert into workout_days (user_id, workout_date, is_rest_day, program_id)
+						values ($1, $2, $3, $4)
+						returning id`, userID, date, d.IsRestDay, id).Scan(&entry.DayID); err != nil {
+						return err
+					}
+				case err != nil:
+					return err
+				case hasExercises:
+					entry.DayID, entry.Reason = existingID, "day already has exercises"
+					res.Skipped = append(res.Skipped, entry)
+					continue
+				case existingProg.Valid && existingProg.String != id:
+					entry.DayID, entry.Reason = existingID, "day already planned by another program"
+					res.Skipped = append(res.Skipped, entry)
+					continue
+				default:
+					entry.DayID = existingID
+					if _, err := tx.ExecContext(ctx, `
+						update workout_days set is_rest_day = $2, program_id = $3
+						where id = $1`, existingID, d.IsRestDay, id); err != nil {
+						return err
+					}
+				}
+				for _, ex := range d.Exercises {
+					if _, err := tx.ExecContext(ctx, `
+						insert into exercises (day_id, catalog_id, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg)
+						values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+						entry.DayID, ex.CatalogID, ex.Position, ex.Notes, ex.TargetSets, ex.TargetReps, ex.TargetRepsMax, ex.AMRAP, ex.TargetWeightKg); err != nil {
+						return err
+					}
+				}
+				res.Scheduled = append(res.Scheduled, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func trimPtr(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*v)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// recapListSize caps the top exercises and PRs in a recap.
+const recapListSize = 5
+
+type Recaps struct {
+	db *sqlx.DB
+}
+
+func NewRecaps(db *sqlx.DB) *Recaps { return &Recaps{db: db} }
+
+// YearRecap summarizes a user's training year from working sets.
+type YearRecap struct {
+	Year          int             `json:"year"`
+	TrainingDays  int             `json:"trainingDays"`
+	WorkingSets   int             `json:"workingSets"`
+	TotalVolumeKg float64         `json:"totalVolumeKg"`
+	BusiestMonth  *RecapMonth     `json:"busiestMonth"`
+	TopExercises  []RecapExercise `json:"topExercises"`
+	BiggestPRs    []RecapRecord   `json:"biggestPRs"`
+	GeneratedAt   time.Time       `json:"generatedAt"`
+}
+
+// RecapMonth is the month with the most training days, by volume on ties.
+type RecapMonth struct {
+	Month        string  `db:"month" json:"month"` // YYYY-MM
+	TrainingDays int     `db:"training_days" json:"trainingDays"`
+	VolumeKg     float64 `db:"volume_kg" json:"volumeKg"`
+}
+
+// RecapExercise is a catalog exercise ranked by working sets in the year.
+type RecapExercise struct {
+	CatalogID   string  `db:"catalog_id" json:"catalogId"`
+	Name        string  `db:"name" json:"name"`
+	WorkingSets int     `db:"working_sets" json:"workingSets"`
+	VolumeKg    float64 `db:"volume_kg" json:"volumeKg"`
+}
+
+// RecapRecord is the year's heaviest set of an exercise. GainKg is how much
+// it beat the best before the year, or the year's first top set for an
+// exercise first trained that year (PreviousKg nil).
+type RecapRecord struct {
+	CatalogID  string   `db:"catalog_id" json:"catalogId"`
+	Name       string   `db:"name" json:"name"`
+	WeightKg   float64  `db:"weight_kg" json:"weightKg"`
+	Date       string   `db:"date" json:"date"`
+	PreviousKg *float64 `db:"previous_kg" json:"previousKg"`
+	GainKg     float64  `db:"gain_kg" json:"gainKg"`
+}
+
+func yearBounds(year int) (string, string) {
+	return fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year)
+}
+
+// Get returns the cached recap, or nil, nil when there is none or the year's
+// rollups changed after it was generated.
+func (s *Recaps) Get(ctx context.Context, userID string, year int) (*YearRecap, error) {
+	from, to := yearBounds(year)
+	var data []byte
+	err := conn(ctx, s.db).GetContext(ctx, &data, `
+		select r.data
+		from year_recaps r
+		where r.user_id = $1 and r.year = $2
+		  and r.training_days = (
+		    select count(*) from stats_daily d
+		    where d.user_id = $1 and d.workout_date between $3::date and $4::date
+		  )
+		  and not exists (
+		    select 1 from stats_daily d
+		    where d.user_id = $1 and d.workout_date between $3::date and $4::date
+		      and d.refreshed_at > r.generated_at
+		  )
+	`, userID, year, from, to)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var recap YearRecap
+	if err := json.Unmarshal(data, &recap); err != nil {
+		return nil, err
+	}
+	return &recap, nil
+}
+
+// Generate builds the user's recap of year from the stat rollups and caches
+// it, replacing any earlier one.
+func (s *Recaps) Generate(ctx context.Context, userID string, year int) (*YearRecap, error) {
+	from, to := yearBounds(year)
+	recap := YearRecap{Year: year, TopExercises: []RecapExercise{}, BiggestPRs: []RecapRecord{}}
+	// Stamped with the database clock before reading, so a refresh racing
+	// the reads leaves the recap stale rather than silently missed.
+	if err := conn(ctx, s.db).GetContext(ctx, &recap.GeneratedAt, `select now()`); err != nil {
+		return nil, err
+	}
+	var totals struct {
+		Days     int     `db:"training_days"`
+		Sets     int     `db:"working_sets"`
+		VolumeKg float64 `db:"volume_kg"`
+	}
+	if err := conn(ctx, s.db).GetContext(ctx, &totals, `
+		select count(*) as training_days,
+		       coalesce(sum(working_sets), 0) as working_sets,
+		       coalesce(sum(tonnage_kg), 0)::float8 as volume_kg
+		from stats_daily
+		where user_id = $1 and workout_date between $2::date and $3::date
+	`, userID, from, to); err != nil {
+		return nil, err
+	}
+	recap.TrainingDays, recap.WorkingSets, recap.TotalVolumeKg = totals.Days, totals.Sets, totals.VolumeKg
+
+	var month RecapMonth
+	err := conn(ctx, s.db).GetContext(ctx, &month, `
+		select to_char(date_trunc('month', workout_date), 'YYYY-MM') as month,
+		       count(*) as training_days,
+		       sum(tonnage_kg)::float8 as volume_kg
+		from stats_daily
+		where user_id = $1 and workout_date between $2::date and $3::date
+		group by 1
+		order by training_days desc, volume_kg desc, month
+		limit 1
+	`, userID, from, to)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		recap.BusiestMonth = &month
+	}
+
+	if err := conn(ctx, s.db).SelectContext(ctx, &recap.TopExercises, `
+		select x.catalog_id, ec.name,
+		       sum(x.working_sets) as working_sets,
+		       sum(x.tonnage_kg)::float8 as volume_kg
+		from stats_exercise_daily x
+		join exercise_catalog ec on ec.id = x.catalog_id
+		where x.user_id = $1 and x.workout_date between $2::date and $3::date
+		group by x.catalog_id, ec.name
+		order by working_sets desc, volume_kg desc, ec.name
+		limit $4
+	`, userID, from, to, recapListSize); err != nil {
+		return nil, err
+	}
+
+	if err := conn(ctx, s.db).SelectContext(ctx, &recap.BiggestPRs, `
+		with yr as (
+		  select catalog_id, workout_date, top_weight_kg
+		  from stats_exercise_daily
+		  where user_id = $1 and workout_date between $2::date and $3::date and top_weight_kg is not null
+		), best as (
+		  select distinct on (catalog_id) catalog_id, top_weight_kg, workout_date
+		  from yr
+		  order by catalog_id, top_weight_kg desc, workout_date
+		), first as (
+		  select distinct on (catalog_id) catalog_id, top_weight_kg
+		  from yr
+		  order by catalog_id, workout_date
+		), prior as (
+		  select catalog_id, max(top_weight_kg) as top_weight_kg
+		  from stats_exercise_daily
+		  where user_id = $1 and workout_date < $2::date and top_weight_kg is not null
+		  group by catalog_id
+		)
+		select b.catalog_id, ec.name,
+		       b.top_weight_kg::float8 as weight_kg,
+		       to_char(b.workout_date, 'YYYY-MM-DD') as date,
+		       p.top_weight_kg::float8 as previous_kg,
+		       (b.top_weight_kg - coalesce(p.top_weight_kg, f.top_weight_kg))::float8 as gain_kg
+		from best b
+		join first f on f.catalog_id = b.catalog_id
+		left join prior p on p.catalog_id = b.catalog_id
+		join exercise_catalog ec on ec.id = b.catalog_id
+		where b.top_weight_kg > coalesce(p.top_weight_kg, f.top_weight_kg)
+		order by gain_kg desc, b.workout_date
+		limit $4
+	`, userID, from, to, recapListSize); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(recap)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn(ctx, s.db).ExecContext(ctx, `
+		insert into year_recaps (user_id, year, data, training_days, generated_at)
+		values ($1, $2, $3, $4, $5)
+		on conflict (user_id, year) do update
+		set data = excluded.data, training_days = excluded.training_days, generated_at = excluded.generated_at
+	`, userID, year, data, recap.TrainingDays, recap.GeneratedAt); err != nil {
+		return nil, err
+	}
+	return &recap, nil
+}
+
+// Pending returns, in id order after the given one, up to limit users who
+// trained in year and have no recap of it yet.
+func (s *Recaps) Pending(ctx context.Context, year int, after string, limit int) ([]string, error) {
+	from, to := yearBounds(year)
+	out := []string{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select u.id
+		from users u
+		where u.id > coalesce(nullif($4, '')::uuid, '00000000-0000-0000-0000-000000000000'::uuid)
+		  and u.deleted_at is null
+		  and exists (
+		    select 1 from stats_daily d
+		    where d.user_id = u.id and d.workout_date between $2::date and $3::date
+		  )
+		  and not exists (select 1 from year_recaps r where r.user_id = u.id and r.year = $1)
+		order by u.id
+		limit $5
+	`, year, from, to, after, limit)
+	return out, err
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+)
+
+// PersonalRecord describes a working set that beats the user's previous best
+// weight for the same catalog exercise.
+type PersonalRecord struct {
+	SetID        string  `db:"set_id" json:"setId"`
+	ExerciseID   string  `db:"exercise_id" json:"exerciseId"`
+	CatalogID    string  `db:"catalog_id" json:"catalogId"`
+	Name         string  `db:"name" json:"name"`
+	WeightKg     float64 `db:"weight_kg" json:"weightKg"`
+	Reps         int     `db:"reps" json:"reps"`
+	PreviousBest float64 `db:"previous_best" json:"previousBestKg"`
+}
+
+// DetectPersonalRecords checks the given sets against everything else the user
+// has logged and returns the ones that set a new top weight. A set needs at
+// least one unassisted rep to count, on either side of the comparison.
+func (s *Sets) DetectPersonalRecords(ctx context.Context, userID string, setIDs []string) ([]PersonalRecord, error) {
+	if len(setIDs) == 0 {
+		return nil, nil
+	}
+	const q = `
+		select s.id as set_id, s.exercise_id, e.catalog_id, e.name, s.weight_kg, s.reps,
+		       coalesce((
+		         select max(o.weight_kg)
+		         from sets o
+		         join exercises oe on oe.id = o.exercise_id
+		         where o.user_id = s.user_id
+		           and oe.catalog_id = e.catalog_id
+		           and o.is_warmup = false and o.set_type = 'strength'
+		           and o.reps > o.assisted_reps
+		           and o.id <> all($2::uuid[])
+		       ), 0) as previous_best
+		from sets s
+		join exercises e on e.id = s.exercise_id
+		where s.user_id = $1 and s.id = any($2::uuid[]) and s.is_warmup = false and s.set_type = 'strength'
+		  and s.reps > s.assisted_reps
+		order by s.weight_kg desc
+	`
+	var rows []PersonalRecord
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, q, userID, setIDs); err != nil {
+		return nil, err
+	}
+	// Only the heaviest new set per catalog entry counts as the record.
+	seen := make(map[string]struct{})
+	var out []PersonalRecord
+	for _, r := range rows {
+		if r.WeightKg <= 0 || r.WeightKg <= r.PreviousBest {
+			continue
+		}
+		if _, ok := seen[r.CatalogID]; ok {
+			continue
+		}
+		seen[r.CatalogID] = struct{}{}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Reminder delivery channels.
+const (
+	ReminderEmail = "email"
+	ReminderPush  = "push"
+)
+
+type Reminders struct {
+	db *sqlx.DB
+}
+
+func NewReminders(db *sqlx.DB) *Reminders { return &Reminders{db: db} }
+
+type Reminder struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	DaysOfWeek []int      `json:"daysOfWeek"`
+	TimeOfDay  string     `json:"time"` // HH:MM
+	Timezone   string     `json:"timezone"`
+	Channels   []string   `json:"channels"`
+	Enabled    bool       `json:"enabled"`
+	NextRunAt  *time.Time `json:"nextRunAt,omitempty"`
+	LastSentAt *time.Time `json:"lastSentAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+type reminderRow struct {
+	ID         string     `db:"id"`
+	UserID     string     `db:"user_id"`
+	DaysOfWeek []byte     `db:"days_of_week"`
+	TimeOfDay  string     `db:"time_of_day"`
+	Timezone   string     `db:"timezone"`
+	Channels   []byte     `db:"channels"`
+	Enabled    bool       `db:"enabled"`
+	NextRunAt  *time.Time `db:"next_run_at"`
+	LastSentAt *time.Time `db:"last_sent_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+func (r reminderRow) reminder() Reminder {
+	out := Reminder{
+		ID: r.ID, UserID: r.UserID, TimeOfDay: r.TimeOfDay, Timezone: r.Timezone, Enabled: r.Enabled,
+		NextRunAt: r.NextRunAt, LastSentAt: r.LastSentAt, CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt,
+	}
+	_ = json.Unmarshal(r.DaysOfWeek, &out.DaysOfWeek)
+	_ = json.Unmarshal(r.Channels, &out.Channels)
+	return out
+}
+
+const reminderColumns = `id, user_id, array_to_json(days_of_week) as days_of_week, to_char(time_of_day, 'HH24:MI') as time_of_day,
+	timezone, array_to_json(channels) as channels, enabled, next_run_at, last_sent_at, created_at, updated_at`
+
+// ReminderParams is a full reminder definition. NextRunAt is computed by the
+// caller from the schedule; nil for disabled reminders.
+type ReminderParams struct {
+	DaysOfWeek []int
+	TimeOfDay  string
+	Timezone   string
+	Channels   []string
+	Enabled    bool
+	NextRunAt  *time.Time
+}
+
+func (s *Reminders) List(ctx context.Context, userID string) ([]Reminder, error) {
+	var rows []reminderRow
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select `+reminderColumns+`
+		from reminders
+		where user_id = $1
+		order by time_of_day, created_at`, userID); err != nil {
+		return nil, err
+	}
+	out := make([]Reminder, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.reminder())
+	}
+	return out, nil
+}
+
+func (s *Reminders) Get(ctx context.Context, userID, id string) (*Reminder, error) {
+	var row reminderRow
+	err := conn(ctx, s.db).GetContext(ctx, &row, `select `+reminderColumns+` from reminders where id = $1 and user_id = $2`, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := row.reminder()
+	return &r, nil
+}
+
+func (s *Reminders) Create(ctx context.Context, userID string, p ReminderParams) (*Reminder, error) {
+	var row reminderRow
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into reminders (user_id, days_of_week, time_of_day, timezone, channels, enabled, next_run_at)
+		values ($1, $2::int[], $3::time, $4, $5::text[], $6, $7)
+		returning `+reminderColumns,
+		userID, intArray(p.DaysOfWeek), p.TimeOfDay, p.Timezone, dedupe(p.Channels), p.Enabled, p.NextRunAt).StructScan(&row)
+	if err != nil {
+		return nil, err
+	}
+	out := row.reminder()
+	return &out, nil
+}
+
+// Update replaces the reminder; nil when it doesn't exist.
+func (s *Reminders) Update(ctx context.Context, userID, id string, p ReminderParams) (*Reminder, error) {
+	var row reminderRow
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		update reminders
+		set days_of_week = $3::int[], time_of_day = $4::time, timezone = $5, channels = $6::text[],
+		    enabled = $7, next_run_at = $8, updated_at = now()
+		where id = $1 and user_id = $2
+		returning `+reminderColumns,
+		id, userID, intArray(p.DaysOfWeek), p.TimeOfDay, p.Timezone, dedupe(p.Channels), p.Enabled, p.NextRunAt).StructScan(&row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := row.reminder()
+	return &out, nil
+}
+
+func (s *Reminders) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from reminders where id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Due returns enabled reminders whose next run is at or before now. Accounts
+// scheduled for deletion are skipped.
+func (s *Reminders) Due(ctx context.Context, now time.Time, limit int) ([]Reminder, error) {
+	var rows []reminderRow
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select `+reminderColumns+`
+		from reminders r
+		where r.enabled and r.next_run_at <= $1
+		  and exists (select 1 from users u where u.id = r.user_id and u.deleted_at is null)
+		order by r.next_run_at
+		limit $2`, now, limit); err != nil {
+		return nil, err
+	}
+	out := make([]Reminder, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.reminder())
+	}
+	return out, nil
+}
+
+// Advance moves a reminder past the run it was due for. The previous value
+// guards against a concurrent edit that already rescheduled it.
+func (s *Reminders) Advance(ctx context.Context, id string, prev, next time.Time) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `
+		update reminders set next_run_at = $3
+		where id = $1 and next_run_at = $2`, id, prev, next)
+	return err
+}
+
+func (s *Reminders) MarkSent(ctx context.Context, id string, at time.Time) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `update reminders set last_sent_at = $2 where id = $1`, id, at)
+	return err
+}
+
+type PushSubscription struct {
+	ID        string    `db:"id" json:"id"`
+	Endpoint  string    `db:"endpoint" json:"endpoint"`
+	P256dh    string    `db:"p256dh" json:"p256dh"`
+	Auth      string    `db:"auth" json:"auth"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// SavePushSubscription registers a browser endpoint for the user. Endpoints
+// are unique, so re-subscribing (or a shared device changing hands) moves it.
+func (s *Reminders) SavePushSubscription(ctx context.Context, userID string, sub PushSubscription) (*PushSubscription, error) {
+	out := new(PushSubscription)
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into push_subscriptions (user_id, endpoint, p256dh, auth)
+		values ($1, $2, $3, $4)
+		on conflict (endpoint) do update
+		set user_id = excluded.user_id, p256dh = excluded.p256dh, auth = excluded.auth
+		returning id, endpoint, p256dh, auth, created_at`,
+		userID, sub.Endpoint, sub.P256dh, sub.Auth).StructScan(out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Reminders) PushSubscriptions(ctx context.Context, userID string) ([]PushSubscription, error) {
+	out := []PushSubscription{}
+	if err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select id, endpoint, p256dh, auth, created_at
+		from push_subscriptions
+		where user_id = $1
+		order by created_at`, userID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Reminders) DeletePushSubscription(ctx context.Context, userID, endpoint string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from push_subscriptions where user_id = $1 and endpoint = $2`, userID, endpoint)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// DropPushEndpoint removes an endpoint the push service reported as gone.
+func (s *Reminders) DropPushEndpoint(ctx context.Context, endpoint string) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `delete from push_subscriptions where endpoint = $1`, endpoint)
+	return err
+}
+
+func intArray(values []int) []int64 {
+	out := make([]int64, 0, len(values))
+	seen := make(map[int]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, int64(v))
+		}
+	}
+	return out
+}
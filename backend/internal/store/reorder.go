@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrReorderIDs means an ordered id list doesn't name each of the parent's
+// children exactly once.
+var ErrReorderIDs = errors.New("orderedIds must list every child exactly once")
+
+// reorderExercises sets each exercise's position to its index in ids with a
+// single statement. Ids that aren't exercises of the user's day dayID are
+// skipped; the number of exercises updated is returned.
+func reorderExercises(ctx context.Context, tx sqlx.ExecerContext, userID, dayID string, ids []string) (int64, error) {
+	res, err := tx.ExecContext(ctx, `
+		update exercises e set position = u.ord - 1
+		from unnest($1::uuid[]) with ordinality as u(id, ord)
+		where e.id = u.id
+		  and e.day_id = $3
+		  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
+	`, ids, userID, dayID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// reorderSets is reorderExercises for the sets of one exercise.
+func reorderSets(ctx context.Context, tx sqlx.ExecerContext, userID, exerciseID string, ids []string) (int64, error) {
+	res, err := tx.ExecContext(ctx, `
+		update sets s set position = u.ord - 1
+		from unnest($1::uuid[]) with ordinality as u(id, ord)
+		where s.id = u.id and s.user_id = $2 and s.exercise_id = $3
+	`, ids, userID, exerciseID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Reorder sets the order of a day's exercises. ids must hold every exercise
+// of the day once, or ErrReorderIDs is returned and nothing changes. Returns
+// false when the day isn't the user's.
+func (s *Exercises) Reorder(ctx context.Context, userID, dayID string, ids []string) (bool, error) {
+	found := false
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		var total int
+		if err := tx.GetContext(ctx, &total, `
+			select count(e.id)
+			from workout_days d
+			left join exercises e on e.day_id = d.id
+			where d.id = $1 and d.user_id = $2
+			group by d.id
+		`, dayID, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		found = true
+		if total != len(ids) {
+			return ErrReorderIDs
+		}
+		n, err := reorderExercises(ctx, tx, userID, dayID, ids)
+		if err != nil {
+			return err
+		}
+		if n != int64(len(ids)) {
+			return ErrReorderIDs
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Reorder sets the order of an exercise's sets, like Exercises.Reorder.
+// Returns false when the exercise isn't the user's.
+func (s *Sets) Reorder(ctx context.Context, userID, exerciseID string, ids []string) (bool, error) {
+	found := false
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		var total int
+		if err := tx.GetContext(ctx, &total, `
+			select count(s.id)
+			from exercises e
+			join workout_days d on d.id = e.day_id
+			left join sets s on s.exercise_id = e.id
+			where e.id = $1 and d.user_id = $2
+			group by e.id
+		`, exerciseID, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		found = true
+		if total != len(ids) {
+			return ErrReorderIDs
+		}
+		n, err := reorderSets(ctx, tx, userID, exerciseID, ids)
+		if err != nil {
+			return err
+		}
+		if n != int64(len(ids)) {
+			return ErrReorderIDs
+		}
+		return nil
+	})
+	return found, err
+}
@@ -1,47 +1,62 @@
 package store
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/logging"
+	"exercise-tracker/internal/models"
 )
 
 type Save struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	strict bool
 }
 
 func NewSave(db *sqlx.DB) *Save { return &Save{db: db} }
 
+// SetStrict makes ops carrying a field their type doesn't define fail instead
+// of the field being ignored. It is off by default so that older clients
+// sending extra fields keep working.
+func (s *Save) SetStrict(strict bool) { s.strict = strict }
+
 // Operation envelopes (decoded per type)
 type opType string
 
 const (
-	opCreateExercise   opType = "createExercise"
-	opCreateSet        opType = "createSet"
-	opUpdateExercise   opType = "updateExercise"
-	opUpdateSet        opType = "updateSet"
-	opReorderExercises opType = "reorderExercises"
-	opReorderSets      opType = "reorderSets"
-	opDeleteExercise   opType = "deleteExercise"
-	opDeleteSet        opType = "deleteSet"
-	opCreateRest       opType = "createRest"
-	opUpdateRest       opType = "updateRest"
-	opDeleteRest       opType = "deleteRest"
-	opUpdateDay        opType = "updateDay"
-	opCreateDay        opType = "createDay"
+	opCreateExercise    opType = "createExercise"
+	opCreateSet         opType = "createSet"
+	opUpdateExercise    opType = "updateExercise"
+	opUpdateSet         opType = "updateSet"
+	opReorderExercises  opType = "reorderExercises"
+	opReorderSets       opType = "reorderSets"
+	opDeleteExercise    opType = "deleteExercise"
+	opDeleteSet         opType = "deleteSet"
+	opCreateRest        opType = "createRest"
+	opUpdateRest        opType = "updateRest"
+	opDeleteRest        opType = "deleteRest"
+	opUpdateDay         opType = "updateDay"
+	opCreateDay         opType = "createDay"
+	opMoveExercise      opType = "moveExercise"
+	opDuplicateExercise opType = "duplicateExercise"
+	opDuplicateSet      opType = "duplicateSet"
+	opUpdateDayNotes    opType = "updateDayNotes"
+	opSetDayTiming      opType = "setDayTiming"
 )
 
 type opEnvelope struct {
 	Type opType `json:"type"`
 	// Keep raw for secondary decode
-	raw json.RawMessage
+	raw    json.RawMessage
+	strict bool
 }
 
 func (o *opEnvelope) UnmarshalJSON(data []byte) error {
@@ -57,6 +72,80 @@ func (o *opEnvelope) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// decode unmarshals the op into v, rejecting unknown fields in strict mode.
+func (o opEnvelope) decode(v any) error {
+	if !o.strict {
+		return json.Unmarshal(o.raw, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(o.raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// decodeOp decodes one raw op's envelope and checks that its type is known.
+// In strict mode the op's fields are decoded too, so a malformed op fails
+// before a transaction is opened.
+func decodeOp(raw json.RawMessage, strict bool) (opEnvelope, error) {
+	var e opEnvelope
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return e, err
+	}
+	e.strict = strict
+	op := newOp(e.Type)
+	if op == nil {
+		return e, fmt.Errorf("unknown op type: %s", string(e.Type))
+	}
+	if strict {
+		if err := e.decode(op); err != nil {
+			return e, fmt.Errorf("%s: %w", e.Type, err)
+		}
+	}
+	return e, nil
+}
+
+// newOp returns a pointer to a zero op of type t, or nil for an unknown type.
+func newOp(t opType) any {
+	switch t {
+	case opCreateExercise:
+		return &createExerciseOp{}
+	case opCreateSet:
+		return &createSetOp{}
+	case opUpdateExercise:
+		return &updateExerciseOp{}
+	case opUpdateSet:
+		return &updateSetOp{}
+	case opReorderExercises:
+		return &reorderExercisesOp{}
+	case opReorderSets:
+		return &reorderSetsOp{}
+	case opDeleteExercise:
+		return &deleteExerciseOp{}
+	case opDeleteSet:
+		return &deleteSetOp{}
+	case opCreateRest:
+		return &createRestOp{}
+	case opUpdateRest:
+		return &updateRestOp{}
+	case opDeleteRest:
+		return &deleteRestOp{}
+	case opUpdateDay:
+		return &updateDayOp{}
+	case opCreateDay:
+		return &createDayOp{}
+	case opMoveExercise:
+		return &moveExerciseOp{}
+	case opDuplicateExercise:
+		return &duplicateExerciseOp{}
+	case opDuplicateSet:
+		return &duplicateSetOp{}
+	case opUpdateDayNotes:
+		return &updateDayNotesOp{}
+	case opSetDayTiming:
+		return &setDayTimingOp{}
+	}
+	return nil
+}
+
 // Create/update types
 type createExerciseOp struct {
 	Type      opType  `json:"type"`
@@ -75,6 +164,21 @@ type createSetOp struct {
 	Reps       int     `json:"reps"`
 	WeightKg   float64 `json:"weightKg"`
 	IsWarmup   bool    `json:"isWarmup"`
+	// RPE and RIR (reps in reserve) are optional and must roughly agree;
+	// see models.CheckEffort.
+	RPE *float64 `json:"rpe"`
+	RIR *int     `json:"rir"`
+	// SetType defaults to strength; see models.SetType*.
+	SetType         string   `json:"setType"`
+	DurationSeconds *int     `json:"durationSeconds"`
+	DistanceM       *float64 `json:"distanceM"`
+	Side            *string  `json:"side"`
+	ToFailure       bool     `json:"toFailure"`
+	AssistedReps    int      `json:"assistedReps"`
+	PartialReps     int      `json:"partialReps"`
+	// PerformedAt defaults to the insert time when the user has
+	// stampSets on; see migration 030.
+	PerformedAt *time.Time `json:"performedAt"`
 }
 
 type updateExerciseOp struct {
@@ -90,10 +194,19 @@ type updateSetOp struct {
 	Type  opType `json:"type"`
 	SetID string `json:"setId"`
 	Patch struct {
-		Position  *int     `json:"position"`
-		Reps      *int     `json:"reps"`
-		WeightKg  *float64 `json:"weightKg"`
-		IsWarmup  *bool    `json:"isWarmup"`
+		Position        *int     `json:"position"`
+		Reps            *int     `json:"reps"`
+		WeightKg        *float64 `json:"weightKg"`
+		IsWarmup        *bool    `json:"isWarmup"`
+		RPE             *float64 `json:"rpe"`
+		RIR             *int     `json:"rir"`
+		SetType         *string  `json:"setType"`
+		DurationSeconds *int     `json:"durationSeconds"`
+		DistanceM       *float64 `json:"distanceM"`
+		Side            *string  `json:"side"`
+		ToFailure       *bool    `json:"toFailure"`
+		AssistedReps    *int     `json:"assistedReps"`
+		PartialReps     *int     `json:"partialReps"`
 	} `json:"patch"`
 }
 
@@ -141,12 +254,53 @@ type deleteRestOp struct {
 	RestID string `json:"restId"`
 }
 
+type moveExerciseOp struct {
+	Type       opType `json:"type"`
+	ExerciseID string `json:"exerciseId"` // may be "temp:<id>"
+	DayID      string `json:"dayId"`      // may be a createDay localId
+	Position   *int   `json:"position,omitempty"`
+}
+
+// duplicateExerciseOp clones an exercise with all its sets and rests into the
+// same day. The clones are mapped from SetLocalIDs/RestLocalIDs (source
+// position order) when given, otherwise from "<localId>:set:<n>" and
+// "<localId>:rest:<n>".
+type duplicateExerciseOp struct {
+	Type         opType   `json:"type"`
+	LocalID      string   `json:"localId"`
+	ExerciseID   string   `json:"exerciseId"` // may be "temp:<id>"
+	Position     *int     `json:"position,omitempty"`
+	SetLocalIDs  []string `json:"setLocalIds,omitempty"`
+	RestLocalIDs []string `json:"restLocalIds,omitempty"`
+}
+
+type duplicateSetOp struct {
+	Type     opType `json:"type"`
+	LocalID  string `json:"localId"`
+	SetID    string `json:"setId"` // may be "temp:<id>"
+	Position *int   `json:"position,omitempty"`
+}
+
 type updateDayOp struct {
 	Type      opType `json:"type"`
 	DayID     string `json:"dayId"`
 	IsRestDay bool   `json:"isRestDay"`
 }
 
+type updateDayNotesOp struct {
+	Type  opType `json:"type"`
+	DayID string `json:"dayId"` // may be a createDay localId
+	Notes string `json:"notes"` // blank clears
+}
+
+// setDayTimingOp sets either end of a day's session; omitted fields are kept.
+type setDayTimingOp struct {
+	Type       opType     `json:"type"`
+	DayID      string     `json:"dayId"` // may be a createDay localId
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
 type createDayOp struct {
 	Type        opType `json:"type"`
 	LocalID     string `json:"localId"`
@@ -159,6 +313,35 @@ type SaveMapping struct {
 	Exercises []LocalIdMap `json:"exercises"`
 	Sets      []LocalIdMap `json:"sets"`
 	Rests     []LocalIdMap `json:"rests"`
+	// AutoRests lists the rests inserted for an autoRest batch.
+	AutoRests []AutoRest `json:"autoRests,omitempty"`
+}
+
+// AutoRest is a rest period the server inserted before a created set. The
+// set is moved to SetPosition, right after the rest.
+type AutoRest struct {
+	ID              string `json:"id"`
+	ExerciseID      string `json:"exerciseId"`
+	Position        int    `json:"position"`
+	DurationSeconds int    `json:"durationSeconds"`
+	SetLocalID      string `json:"setLocalId"`
+	SetPosition     int    `json:"setPosition"`
+}
+
+type autoRestKey struct{}
+
+// WithAutoRest makes ProcessBatch and ProcessBatchPartial insert a rest
+// before each created set that follows another set, using the exercise's
+// default rest (see Settings.ExerciseRests and
+// UserSettings.DefaultRestSeconds). Those sets are appended to the exercise
+// rather than placed at their requested position.
+func WithAutoRest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, autoRestKey{}, true)
+}
+
+func autoRest(ctx context.Context) bool {
+	v, _ := ctx.Value(autoRestKey{}).(bool)
+	return v
 }
 
 type LocalIdMap struct {
@@ -166,24 +349,56 @@ type LocalIdMap struct {
 	ID      string `json:"id"`
 }
 
+// SaveOpResult reports the outcome of one op when a batch is processed with
+// continueOnError.
+type SaveOpResult struct {
+	Index  int    `json:"index"`
+	Type   string `json:"type,omitempty"`
+	Status string `json:"status"` // applied | failed
+	Reason string `json:"reason,omitempty"`
+}
+
+const (
+	SaveOpApplied = "applied"
+	SaveOpFailed  = "failed"
+)
+
+// batchState carries temp -> real id maps between the ops of one batch.
+type batchState struct {
+	days      map[string]string
+	exercises map[string]string
+	sets      map[string]string
+	rests     map[string]string
+	mapping   SaveMapping
+}
+
+func newBatchState() *batchState {
+	return &batchState{
+		days:      make(map[string]string),
+		exercises: make(map[string]string),
+		sets:      make(map[string]string),
+		rests:     make(map[string]string),
+	}
+}
+
 // ProcessBatch applies the ops within a single transaction using the prescribed ordering.
 func (s *Save) ProcessBatch(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (SaveMapping, time.Time, error) {
 	if len(rawOps) == 0 {
 		return SaveMapping{}, time.Now().UTC(), nil
 	}
-	log.Printf("save batch start key=%s user=%s ops=%d", safeStr(idKey), userID, len(rawOps))
+	logging.Infof("save batch start key=%s user=%s ops=%d", logging.Redact(idKey), userID, len(rawOps))
 	// Decode envelopes
 	var envs []opEnvelope
 	envs = make([]opEnvelope, 0, len(rawOps))
 	for _, r := range rawOps {
-		var e opEnvelope
-		if err := json.Unmarshal(r, &e); err != nil {
+		e, err := decodeOp(r, s.strict)
+		if err != nil {
 			return SaveMapping{}, time.Time{}, fmt.Errorf("invalid op: %w", err)
 		}
 		envs = append(envs, e)
 	}
 
-	tx, err := s.db.BeginTxx(ctx, nil)
+	tx, err := beginTx(ctx, s.db)
 	if err != nil {
 		return SaveMapping{}, time.Time{}, err
 	}
@@ -193,319 +408,634 @@ func (s *Save) ProcessBatch(ctx context.Context, userID string, rawOps []json.Ra
 		}
 	}()
 
-	tempToRealExercise := make(map[string]string)
-	tempToRealDay := make(map[string]string)
-	tempToRealSet := make(map[string]string)
-	tempToRealRest := make(map[string]string)
-	mapping := SaveMapping{}
-
+	st := newBatchState()
 	// Execute operations sequentially in the exact order received
-	for _, e := range envs {
-		switch e.Type {
-		case opCreateDay:
-			var op createDayOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid createDay: %w", err)
-			}
-			if strings.TrimSpace(op.LocalID) == "" || strings.TrimSpace(op.WorkoutDate) == "" {
-				return SaveMapping{}, time.Time{}, errors.New("createDay missing localId or workoutDate")
-			}
-			const qCreateDay = `
-				insert into workout_days (user_id, workout_date, timezone, is_rest_day)
-				values ($1, $2, $3, false)
-				returning id
-			`
-			var realDayID string
-			if err = tx.QueryRowxContext(ctx, qCreateDay, userID, op.WorkoutDate, op.Timezone).Scan(&realDayID); err != nil {
-				// Handle potential conflict, maybe day already exists. For now, we error.
-				return SaveMapping{}, time.Time{}, fmt.Errorf("could not create day, it may already exist: %w", err)
-			}
-			tempToRealDay[op.LocalID] = realDayID
-			// Note: We dont add Day mappings to the response as client creates them interactively.
+	for i, e := range envs {
+		if err = setJournalSource(ctx, tx, JournalSourceSaveBatch, i); err != nil {
+			return SaveMapping{}, time.Time{}, err
+		}
+		if err = applyOp(ctx, tx.Tx, userID, idKey, e, st); err != nil {
+			return SaveMapping{}, time.Time{}, err
+		}
+	}
 
-			log.Printf("save op createDay key=%s user=%s localId=%s id=%s date=%s", safeStr(idKey), userID, op.LocalID, realDayID, op.WorkoutDate)
+	if err = tx.Commit(); err != nil {
+		return SaveMapping{}, time.Time{}, err
+	}
+	mapping := st.mapping
+	logging.Infof("save batch commit key=%s user=%s createdExercises=%d createdSets=%d createdRests=%d", logging.Redact(idKey), userID, len(mapping.Exercises), len(mapping.Sets), len(mapping.Rests))
+	return mapping, time.Now().UTC(), nil
+}
 
-		case opUpdateDay:
-			var op updateDayOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateDay: %w", err)
-			}
-			if strings.TrimSpace(op.DayID) == "" {
-				return SaveMapping{}, time.Time{}, errors.New("updateDay missing dayId")
-			}
-			if _, err = tx.ExecContext(ctx, `
-				update workout_days set is_rest_day = $3, updated_at = now()
-				where id = $1 and user_id = $2
-			`, op.DayID, userID, op.IsRestDay); err != nil {
-				return SaveMapping{}, time.Time{}, err
-			}
-			log.Printf("save op updateDay key=%s user=%s dayId=%s isRestDay=%t", safeStr(idKey), userID, op.DayID, op.IsRestDay)
-		case opDeleteSet:
-			var op deleteSetOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid deleteSet: %w", err)
-			}
-			id := resolveId(op.SetID, tempToRealSet)
-			if id == "" && strings.HasPrefix(op.SetID, "temp:") { // Changed op.ID to op.SetID
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid deleteSet id: %s", op.SetID) // Changed op.ID to op.SetID
-			}
-			if id == "" {
-				id = op.SetID // Changed op.ID to op.SetID
-			}
-			if _, err = tx.ExecContext(ctx, `delete from sets where id = $1 and user_id = $2`, id, userID); err != nil {
-				return SaveMapping{}, time.Time{}, err
-			}
-			log.Printf("save op deleteSet key=%s user=%s id=%s", safeStr(idKey), userID, op.SetID) // Changed op.ID to op.SetID
-		case opDeleteRest:
-			var op deleteRestOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid deleteRest: %w", err)
-			}
-			rid := resolveId(op.RestID, tempToRealRest)
-			if rid == "" && strings.HasPrefix(op.RestID, "temp:") {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid deleteRest id: %s", op.RestID)
-			}
-			if rid == "" {
-				rid = op.RestID
-			}
-			if _, err = tx.ExecContext(ctx, `
-				delete from rest_periods rp
-				using exercises e
-				join workout_days d on d.id = e.day_id
-				where rp.id = $1
-				  and rp.exercise_id = e.id
-				  and d.user_id = $2
-			`, rid, userID); err != nil {
-				return SaveMapping{}, time.Time{}, err
-			}
-			log.Printf("save op deleteRest key=%s user=%s id=%s", safeStr(idKey), userID, op.RestID)
-		case opCreateExercise:
-			var op createExerciseOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid createExercise: %w", err)
-			}
-			if strings.TrimSpace(op.LocalID) == "" || strings.TrimSpace(op.DayID) == "" || strings.TrimSpace(op.CatalogID) == "" {
-				return SaveMapping{}, time.Time{}, errors.New("createExercise missing localId/dayId/catalogId")
-			}
+// ProcessBatchPartial is ProcessBatch in continueOnError mode: each op runs
+// in its own savepoint, so a failing op is rolled back on its own and the
+// rest of the batch still commits. Ops that depend on a failed op's temp id
+// fail in turn. The error is only non-nil when the transaction itself fails.
+func (s *Save) ProcessBatchPartial(ctx context.Context, userID string, rawOps []json.RawMessage, idKey string) (SaveMapping, []SaveOpResult, time.Time, error) {
+	results := make([]SaveOpResult, 0, len(rawOps))
+	if len(rawOps) == 0 {
+		return SaveMapping{}, results, time.Now().UTC(), nil
+	}
+	logging.Infof("save batch start key=%s user=%s ops=%d continueOnError=true", logging.Redact(idKey), userID, len(rawOps))
 
-			dayID := resolveId(op.DayID, tempToRealDay)
-			if dayID == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid or out-of-order reference for createExercise.dayId: %s", op.DayID)
-			}
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return SaveMapping{}, nil, time.Time{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
 
-			const qCreateEx = `
-				insert into exercises (day_id, catalog_id, position, comment)
-				select $1, $2, $3, $4
-				where exists (select 1 from workout_days where id = $1 and user_id = $5)
-				returning id
-			`
-			var realExID string
-			if err = tx.QueryRowxContext(ctx, qCreateEx, dayID, op.CatalogID, op.Position, op.Comment, userID).Scan(&realExID); err != nil {
-				return SaveMapping{}, time.Time{}, err
-			}
-			tempToRealExercise[op.LocalID] = realExID
-			mapping.Exercises = append(mapping.Exercises, LocalIdMap{LocalID: op.LocalID, ID: realExID})
-			log.Printf("save op createExercise key=%s user=%s localId=%s id=%s dayId=%s catalogId=%s position=%d",
-				safeStr(idKey), userID, op.LocalID, realExID, op.DayID, op.CatalogID, op.Position)
-		case opCreateSet:
-			var op createSetOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid createSet: %w", err)
-			}
-			exID := resolveId(op.ExerciseID, tempToRealExercise)
-			if exID == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid or out-of-order reference for createSet.exerciseId: %s", op.ExerciseID)
-			}
-			const qCreateSet = `
-				insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, is_warmup)
-				select $1, d.user_id, d.workout_date, $3, $4, $5, $6
-				from exercises e
-				join workout_days d on d.id = e.day_id
-				where e.id = $1 and d.user_id = $2
-				returning id
-			`
-			var realSetID string
-			if err = tx.QueryRowxContext(ctx, qCreateSet, exID, userID, op.Position, op.Reps, op.WeightKg, op.IsWarmup).Scan(&realSetID); err != nil {
-				return SaveMapping{}, time.Time{}, err
-			}
-			tempToRealSet[op.LocalID] = realSetID
-			mapping.Sets = append(mapping.Sets, LocalIdMap{LocalID: op.LocalID, ID: realSetID})
-			log.Printf("save op createSet key=%s user=%s localId=%s id=%s exerciseId=%s position=%d reps=%d weightKg=%.2f warmup=%t",
-				safeStr(idKey), userID, op.LocalID, realSetID, exID, op.Position, op.Reps, op.WeightKg, op.IsWarmup)
-		case opCreateRest:
-			var op createRestOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid createRest: %w", err)
-			}
-			exID := resolveId(op.ExerciseID, tempToRealExercise)
-			if exID == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid or out-of-order reference for createRest.exerciseId: %s", op.ExerciseID)
+	st := newBatchState()
+	failed := 0
+	for i, r := range rawOps {
+		e, uerr := decodeOp(r, s.strict)
+		if uerr != nil {
+			results = append(results, SaveOpResult{Index: i, Type: string(e.Type), Status: SaveOpFailed, Reason: fmt.Sprintf("invalid op: %v", uerr)})
+			failed++
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, `savepoint save_op`); err != nil {
+			return SaveMapping{}, nil, time.Time{}, err
+		}
+		if err = setJournalSource(ctx, tx, JournalSourceSaveBatch, i); err != nil {
+			return SaveMapping{}, nil, time.Time{}, err
+		}
+		if opErr := applyOp(ctx, tx.Tx, userID, idKey, e, st); opErr != nil {
+			if _, err = tx.ExecContext(ctx, `rollback to savepoint save_op`); err != nil {
+				return SaveMapping{}, nil, time.Time{}, err
 			}
-			const qCreateRest = `
-				with allowed as (
-				  select e.id as exercise_id
-				  from exercises e
-				  join workout_days d on d.id = e.day_id
-				  where e.id = $1 and d.user_id = $2
-				)
-				insert into rest_periods (exercise_id, position, duration_seconds)
-				select (select exercise_id from allowed), $3, $4
-				on conflict (exercise_id, position)
-				do update set duration_seconds = excluded.duration_seconds, updated_at = now()
-				returning id
-			`
-			var realRestID string
-			if err = tx.QueryRowxContext(ctx, qCreateRest, exID, userID, op.Position, op.Duration).Scan(&realRestID); err != nil {
-				return SaveMapping{}, time.Time{}, err
+			logging.Debugf("save op failed key=%s user=%s index=%d type=%s: %v", logging.Redact(idKey), userID, i, e.Type, opErr)
+			reason := opErr.Error()
+			if qe, ok := AsQuotaError(opErr); ok {
+				reason = qe.Error()
 			}
-			tempToRealRest[op.LocalID] = realRestID
-			mapping.Rests = append(mapping.Rests, LocalIdMap{LocalID: op.LocalID, ID: realRestID})
-			log.Printf("save op createRest key=%s user=%s localId=%s id=%s exerciseId=%s position=%d duration=%d",
-				safeStr(idKey), userID, op.LocalID, realRestID, exID, op.Position, op.Duration)
-		case opUpdateExercise:
-			var op updateExerciseOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateExercise: %w", err)
+			results = append(results, SaveOpResult{Index: i, Type: string(e.Type), Status: SaveOpFailed, Reason: reason})
+			failed++
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, `release savepoint save_op`); err != nil {
+			return SaveMapping{}, nil, time.Time{}, err
+		}
+		results = append(results, SaveOpResult{Index: i, Type: string(e.Type), Status: SaveOpApplied})
+	}
+
+	if err = tx.Commit(); err != nil {
+		return SaveMapping{}, nil, time.Time{}, err
+	}
+	mapping := st.mapping
+	logging.Infof("save batch commit key=%s user=%s createdExercises=%d createdSets=%d createdRests=%d failedOps=%d", logging.Redact(idKey), userID, len(mapping.Exercises), len(mapping.Sets), len(mapping.Rests), failed)
+	return mapping, results, time.Now().UTC(), nil
+}
+
+// applyOp executes a single decoded op inside tx, recording any temp -> real
+// id mappings in st.
+func applyOp(ctx context.Context, tx *sqlx.Tx, userID, idKey string, e opEnvelope, st *batchState) error {
+	var err error
+	switch e.Type {
+	case opCreateDay:
+		var op createDayOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid createDay: %w", err)
+		}
+		if strings.TrimSpace(op.LocalID) == "" || strings.TrimSpace(op.WorkoutDate) == "" {
+			return errors.New("createDay missing localId or workoutDate")
+		}
+		const qCreateDay = `
+			insert into workout_days (user_id, workout_date, timezone, is_rest_day)
+			values ($1, $2, $3, false)
+			returning id
+		`
+		var realDayID string
+		if err = tx.QueryRowxContext(ctx, qCreateDay, userID, op.WorkoutDate, op.Timezone).Scan(&realDayID); err != nil {
+			// Handle potential conflict, maybe day already exists. For now, we error.
+			return fmt.Errorf("could not create day, it may already exist: %w", err)
+		}
+		st.days[op.LocalID] = realDayID
+		// Note: We dont add Day mappings to the response as client creates them interactively.
+
+		logging.Debugf("save op createDay key=%s user=%s localId=%s id=%s date=%s", logging.Redact(idKey), userID, op.LocalID, realDayID, op.WorkoutDate)
+
+	case opUpdateDay:
+		var op updateDayOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid updateDay: %w", err)
+		}
+		if strings.TrimSpace(op.DayID) == "" {
+			return errors.New("updateDay missing dayId")
+		}
+		if _, err = tx.ExecContext(ctx, `
+			update workout_days set is_rest_day = $3, updated_at = now()
+			where id = $1 and user_id = $2
+		`, op.DayID, userID, op.IsRestDay); err != nil {
+			return err
+		}
+		logging.Debugf("save op updateDay key=%s user=%s dayId=%s isRestDay=%t", logging.Redact(idKey), userID, op.DayID, op.IsRestDay)
+	case opUpdateDayNotes:
+		var op updateDayNotesOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid updateDayNotes: %w", err)
+		}
+		if strings.TrimSpace(op.DayID) == "" {
+			return errors.New("updateDayNotes missing dayId")
+		}
+		dayID := resolveId(op.DayID, st.days)
+		if _, err = tx.ExecContext(ctx, `
+			update workout_days set notes = nullif(btrim($3), ''), updated_at = now()
+			where id = $1 and user_id = $2
+		`, dayID, userID, op.Notes); err != nil {
+			return err
+		}
+		logging.Debugf("save op updateDayNotes key=%s user=%s dayId=%s len=%d", logging.Redact(idKey), userID, dayID, len(op.Notes))
+	case opSetDayTiming:
+		var op setDayTimingOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid setDayTiming: %w", err)
+		}
+		if strings.TrimSpace(op.DayID) == "" {
+			return errors.New("setDayTiming missing dayId")
+		}
+		dayID := resolveId(op.DayID, st.days)
+		if _, err = tx.ExecContext(ctx, `
+			update workout_days
+			set started_at = coalesce($3, started_at),
+			    finished_at = coalesce($4, finished_at),
+			    updated_at = now()
+			where id = $1 and user_id = $2
+		`, dayID, userID, op.StartedAt, op.FinishedAt); err != nil {
+			if isSessionOrderViolation(err) {
+				return ErrSessionOrder
 			}
-			id := resolveId(op.ExerciseID, tempToRealExercise)
-			if id == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateExercise id: %s", op.ExerciseID)
+			return err
+		}
+		logging.Debugf("save op setDayTiming key=%s user=%s dayId=%s start_set=%t finish_set=%t",
+			logging.Redact(idKey), userID, dayID, op.StartedAt != nil, op.FinishedAt != nil)
+	case opDeleteSet:
+		var op deleteSetOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid deleteSet: %w", err)
+		}
+		id := resolveId(op.SetID, st.sets)
+		if id == "" && strings.HasPrefix(op.SetID, "temp:") { // Changed op.ID to op.SetID
+			return fmt.Errorf("invalid deleteSet id: %s", op.SetID) // Changed op.ID to op.SetID
+		}
+		if id == "" {
+			id = op.SetID // Changed op.ID to op.SetID
+		}
+		if _, err = tx.ExecContext(ctx, `delete from sets where id = $1 and user_id = $2`, id, userID); err != nil {
+			return err
+		}
+		logging.Debugf("save op deleteSet key=%s user=%s id=%s", logging.Redact(idKey), userID, op.SetID) // Changed op.ID to op.SetID
+	case opDeleteRest:
+		var op deleteRestOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid deleteRest: %w", err)
+		}
+		rid := resolveId(op.RestID, st.rests)
+		if rid == "" && strings.HasPrefix(op.RestID, "temp:") {
+			return fmt.Errorf("invalid deleteRest id: %s", op.RestID)
+		}
+		if rid == "" {
+			rid = op.RestID
+		}
+		if _, err = tx.ExecContext(ctx, `
+			delete from rest_periods rp
+			using exercises e
+			join workout_days d on d.id = e.day_id
+			where rp.id = $1
+			  and rp.exercise_id = e.id
+			  and d.user_id = $2
+		`, rid, userID); err != nil {
+			return err
+		}
+		logging.Debugf("save op deleteRest key=%s user=%s id=%s", logging.Redact(idKey), userID, op.RestID)
+	case opCreateExercise:
+		var op createExerciseOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid createExercise: %w", err)
+		}
+		if strings.TrimSpace(op.LocalID) == "" || strings.TrimSpace(op.DayID) == "" || strings.TrimSpace(op.CatalogID) == "" {
+			return errors.New("createExercise missing localId/dayId/catalogId")
+		}
+
+		dayID := resolveId(op.DayID, st.days)
+		if dayID == "" {
+			return fmt.Errorf("invalid or out-of-order reference for createExercise.dayId: %s", op.DayID)
+		}
+
+		const qCreateEx = `
+			insert into exercises (day_id, catalog_id, position, comment)
+			select $1, $2, $3, $4
+			where exists (select 1 from workout_days where id = $1 and user_id = $5)
+			returning id
+		`
+		var realExID string
+		if err = tx.QueryRowxContext(ctx, qCreateEx, dayID, op.CatalogID, op.Position, op.Comment, userID).Scan(&realExID); err != nil {
+			return err
+		}
+		st.exercises[op.LocalID] = realExID
+		st.mapping.Exercises = append(st.mapping.Exercises, LocalIdMap{LocalID: op.LocalID, ID: realExID})
+		logging.Debugf("save op createExercise key=%s user=%s localId=%s id=%s dayId=%s catalogId=%s position=%d",
+			logging.Redact(idKey), userID, op.LocalID, realExID, op.DayID, op.CatalogID, op.Position)
+	case opCreateSet:
+		var op createSetOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid createSet: %w", err)
+		}
+		exID := resolveId(op.ExerciseID, st.exercises)
+		if exID == "" {
+			return fmt.Errorf("invalid or out-of-order reference for createSet.exerciseId: %s", op.ExerciseID)
+		}
+		if op.SetType != "" && !models.ValidSetType(op.SetType) {
+			return fmt.Errorf("invalid createSet.setType: %s", op.SetType)
+		}
+		if op.Side != nil && !models.ValidSide(*op.Side) {
+			return fmt.Errorf("invalid createSet.side: %s", *op.Side)
+		}
+		if op.AssistedReps < 0 || op.AssistedReps > op.Reps {
+			return fmt.Errorf("invalid createSet.assistedReps: %d", op.AssistedReps)
+		}
+		if op.PartialReps < 0 {
+			return fmt.Errorf("invalid createSet.partialReps: %d", op.PartialReps)
+		}
+		if err = models.CheckEffort(op.RPE, op.RIR); err != nil {
+			return fmt.Errorf("invalid createSet: %w", err)
+		}
+		const qCreateSet = `
+			insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, is_warmup, set_type, duration_seconds, distance_m, side, performed_at,
+			                  to_failure, assisted_reps, partial_reps, rpe, rir)
+			select $1, d.user_id, d.workout_date, $3, $4, $5, $6, coalesce(nullif($7, ''), 'strength'), $8, $9, $10, $11, $12, $13, $14, $15, $16
+			from exercises e
+			join workout_days d on d.id = e.day_id
+			where e.id = $1 and d.user_id = $2
+			returning id
+		`
+		if autoRest(ctx) {
+			var rest *AutoRest
+			if rest, err = insertAutoRest(ctx, tx, userID, exID); err != nil {
+				return err
 			}
-			const qUpdEx = `
-				update exercises e
-				set position = coalesce($3, e.position),
-				    comment = coalesce($4, e.comment)
-				where e.id = $1
-				  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
-			`
-			if _, err = tx.ExecContext(ctx, qUpdEx, id, userID, op.Patch.Position, op.Patch.Comment); err != nil {
-				return SaveMapping{}, time.Time{}, err
+			if rest != nil {
+				op.Position = rest.SetPosition
+				rest.SetLocalID = op.LocalID
+				st.mapping.AutoRests = append(st.mapping.AutoRests, *rest)
 			}
-			log.Printf("save op updateExercise key=%s user=%s id=%s pos_set=%t comment_set=%t",
-				safeStr(idKey), userID, op.ExerciseID, op.Patch.Position != nil, op.Patch.Comment != nil)
-		case opUpdateSet:
-			var op updateSetOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateSet: %w", err)
+		}
+		var realSetID string
+		if err = tx.QueryRowxContext(ctx, qCreateSet, exID, userID, op.Position, op.Reps, op.WeightKg, op.IsWarmup,
+			op.SetType, op.DurationSeconds, op.DistanceM, op.Side, op.PerformedAt, op.ToFailure, op.AssistedReps, op.PartialReps,
+			op.RPE, op.RIR).Scan(&realSetID); err != nil {
+			return err
+		}
+		st.sets[op.LocalID] = realSetID
+		st.mapping.Sets = append(st.mapping.Sets, LocalIdMap{LocalID: op.LocalID, ID: realSetID})
+		logging.Debugf("save op createSet key=%s user=%s localId=%s id=%s exerciseId=%s position=%d reps=%d weightKg=%.2f warmup=%t",
+			logging.Redact(idKey), userID, op.LocalID, realSetID, exID, op.Position, op.Reps, op.WeightKg, op.IsWarmup)
+	case opCreateRest:
+		var op createRestOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid createRest: %w", err)
+		}
+		exID := resolveId(op.ExerciseID, st.exercises)
+		if exID == "" {
+			return fmt.Errorf("invalid or out-of-order reference for createRest.exerciseId: %s", op.ExerciseID)
+		}
+		const qCreateRest = `
+			with allowed as (
+			  select e.id as exercise_id
+			  from exercises e
+			  join workout_days d on d.id = e.day_id
+			  where e.id = $1 and d.user_id = $2
+			)
+			insert into rest_periods (exercise_id, position, duration_seconds)
+			select (select exercise_id from allowed), $3, $4
+			on conflict (exercise_id, position)
+			do update set duration_seconds = excluded.duration_seconds, updated_at = now()
+			returning id
+		`
+		var realRestID string
+		if err = tx.QueryRowxContext(ctx, qCreateRest, exID, userID, op.Position, op.Duration).Scan(&realRestID); err != nil {
+			return err
+		}
+		st.rests[op.LocalID] = realRestID
+		st.mapping.Rests = append(st.mapping.Rests, LocalIdMap{LocalID: op.LocalID, ID: realRestID})
+		logging.Debugf("save op createRest key=%s user=%s localId=%s id=%s exerciseId=%s position=%d duration=%d",
+			logging.Redact(idKey), userID, op.LocalID, realRestID, exID, op.Position, op.Duration)
+	case opUpdateExercise:
+		var op updateExerciseOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid updateExercise: %w", err)
+		}
+		id := resolveId(op.ExerciseID, st.exercises)
+		if id == "" {
+			return fmt.Errorf("invalid updateExercise id: %s", op.ExerciseID)
+		}
+		const qUpdEx = `
+			update exercises e
+			set position = coalesce($3, e.position),
+			    comment = coalesce($4, e.comment)
+			where e.id = $1
+			  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
+		`
+		if _, err = tx.ExecContext(ctx, qUpdEx, id, userID, op.Patch.Position, op.Patch.Comment); err != nil {
+			return err
+		}
+		logging.Debugf("save op updateExercise key=%s user=%s id=%s pos_set=%t comment_set=%t",
+			logging.Redact(idKey), userID, op.ExerciseID, op.Patch.Position != nil, op.Patch.Comment != nil)
+	case opUpdateSet:
+		var op updateSetOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid updateSet: %w", err)
+		}
+		id := resolveId(op.SetID, st.sets)
+		if id == "" {
+			return fmt.Errorf("invalid updateSet id: %s", op.SetID)
+		}
+		if op.Patch.SetType != nil && !models.ValidSetType(*op.Patch.SetType) {
+			return fmt.Errorf("invalid updateSet.setType: %s", *op.Patch.SetType)
+		}
+		if op.Patch.Side != nil && !models.ValidSide(*op.Patch.Side) {
+			return fmt.Errorf("invalid updateSet.side: %s", *op.Patch.Side)
+		}
+		if op.Patch.PartialReps != nil && *op.Patch.PartialReps < 0 {
+			return fmt.Errorf("invalid updateSet.partialReps: %d", *op.Patch.PartialReps)
+		}
+		if err = models.CheckEffort(op.Patch.RPE, op.Patch.RIR); err != nil {
+			return fmt.Errorf("invalid updateSet: %w", err)
+		}
+		const qUpdSet = `
+			update sets s set
+			  position = coalesce($3, s.position),
+			  reps = coalesce($4, s.reps),
+			  weight_kg = coalesce($5, s.weight_kg),
+			  is_warmup = coalesce($6, s.is_warmup),
+			  set_type = coalesce($7, s.set_type),
+			  duration_seconds = coalesce($8, s.duration_seconds),
+			  distance_m = coalesce($9, s.distance_m),
+			  side = coalesce($10, s.side),
+			  to_failure = coalesce($11, s.to_failure),
+			  assisted_reps = coalesce($12, s.assisted_reps),
+			  partial_reps = coalesce($13, s.partial_reps),
+			  rpe = coalesce($14, s.rpe),
+			  rir = coalesce($15, s.rir)
+			where s.id = $1 and s.user_id = $2
+		`
+		if _, err = tx.ExecContext(ctx, qUpdSet, id, userID, op.Patch.Position, op.Patch.Reps, op.Patch.WeightKg, op.Patch.IsWarmup,
+			op.Patch.SetType, op.Patch.DurationSeconds, op.Patch.DistanceM, op.Patch.Side,
+			op.Patch.ToFailure, op.Patch.AssistedReps, op.Patch.PartialReps, op.Patch.RPE, op.Patch.RIR); err != nil {
+			if isEffortViolation(err) {
+				return fmt.Errorf("invalid updateSet: %w", models.ErrEffortMismatch)
 			}
-			id := resolveId(op.SetID, tempToRealSet)
+			return err
+		}
+		logging.Debugf("save op updateSet key=%s user=%s id=%s pos_set=%t reps_set=%t weight_set=%t warmup_set=%t",
+			logging.Redact(idKey), userID, op.SetID,
+			op.Patch.Position != nil, op.Patch.Reps != nil, op.Patch.WeightKg != nil, op.Patch.IsWarmup != nil)
+	case opUpdateRest:
+		var op updateRestOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid updateRest: %w", err)
+		}
+		id := resolveId(op.RestID, st.rests)
+		if id == "" {
+			return fmt.Errorf("invalid updateRest id: %s", op.RestID)
+		}
+		const qUpdRest = `
+			update rest_periods rp set
+			  position = coalesce($3, rp.position),
+			  duration_seconds = coalesce($4, rp.duration_seconds),
+			  updated_at = now()
+			from exercises e
+			join workout_days d on d.id = e.day_id
+			where rp.id = $1
+			  and rp.exercise_id = e.id
+			  and d.user_id = $2
+		`
+		if _, err = tx.ExecContext(ctx, qUpdRest, id, userID, op.Patch.Position, op.Patch.Duration); err != nil {
+			return err
+		}
+		logging.Debugf("save op updateRest key=%s user=%s id=%s pos_set=%t duration_set=%t",
+			logging.Redact(idKey), userID, op.RestID, op.Patch.Position != nil, op.Patch.Duration != nil)
+	case opReorderExercises:
+		var op reorderExercisesOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid reorderExercises: %w", err)
+		}
+		dayID := resolveId(op.DayID, st.days)
+		if dayID == "" {
+			return fmt.Errorf("invalid reorderExercises.dayId: %s", op.DayID)
+		}
+		ids := make([]string, 0, len(op.OrderedIDs))
+		for idx, id := range op.OrderedIDs {
+			id = resolveId(id, st.exercises)
 			if id == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateSet id: %s", op.SetID)
-			}
-			const qUpdSet = `
-				update sets s set
-				  position = coalesce($3, s.position),
-				  reps = coalesce($4, s.reps),
-				  weight_kg = coalesce($5, s.weight_kg),
-				  is_warmup = coalesce($6, s.is_warmup)
-				where s.id = $1 and s.user_id = $2
-			`
-			if _, err = tx.ExecContext(ctx, qUpdSet, id, userID, op.Patch.Position, op.Patch.Reps, op.Patch.WeightKg, op.Patch.IsWarmup); err != nil {
-				return SaveMapping{}, time.Time{}, err
+				return fmt.Errorf("invalid exercise id in reorder: %s", op.OrderedIDs[idx])
 			}
-			log.Printf("save op updateSet key=%s user=%s id=%s pos_set=%t reps_set=%t weight_set=%t warmup_set=%t",
-				safeStr(idKey), userID, op.SetID,
-				op.Patch.Position != nil, op.Patch.Reps != nil, op.Patch.WeightKg != nil, op.Patch.IsWarmup != nil)
-		case opUpdateRest:
-			var op updateRestOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateRest: %w", err)
-			}
-			id := resolveId(op.RestID, tempToRealRest)
+			ids = append(ids, id)
+		}
+		if _, err = reorderExercises(ctx, tx, userID, dayID, ids); err != nil {
+			return err
+		}
+		count := len(ids)
+		logging.Debugf("save op reorderExercises key=%s user=%s dayId=%s count=%d", logging.Redact(idKey), userID, op.DayID, count)
+	case opReorderSets:
+		var op reorderSetsOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid reorderSets: %w", err)
+		}
+		exID := resolveId(op.ExerciseID, st.exercises)
+		if exID == "" {
+			return fmt.Errorf("invalid reorderSets.exerciseId: %s", op.ExerciseID)
+		}
+		ids := make([]string, 0, len(op.OrderedIDs))
+		for idx, id := range op.OrderedIDs {
+			id = resolveId(id, st.sets)
 			if id == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid updateRest id: %s", op.RestID)
-			}
-			const qUpdRest = `
-				update rest_periods rp set
-				  position = coalesce($3, rp.position),
-				  duration_seconds = coalesce($4, rp.duration_seconds),
-				  updated_at = now()
-				from exercises e
-				join workout_days d on d.id = e.day_id
-				where rp.id = $1
-				  and rp.exercise_id = e.id
-				  and d.user_id = $2
-			`
-			if _, err = tx.ExecContext(ctx, qUpdRest, id, userID, op.Patch.Position, op.Patch.Duration); err != nil {
-				return SaveMapping{}, time.Time{}, err
-			}
-			log.Printf("save op updateRest key=%s user=%s id=%s pos_set=%t duration_set=%t",
-				safeStr(idKey), userID, op.RestID, op.Patch.Position != nil, op.Patch.Duration != nil)
-		case opReorderExercises:
-			var op reorderExercisesOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid reorderExercises: %w", err)
-			}
-			count := 0
-			for idx, id := range op.OrderedIDs {
-				id = resolveId(id, tempToRealExercise)
-				if id == "" {
-					return SaveMapping{}, time.Time{}, fmt.Errorf("invalid exercise id in reorder: %s", op.OrderedIDs[idx])
-				}
-				if _, err = tx.ExecContext(ctx, `
-					update exercises e set position = $3
-					where e.id = $1
-					  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
-				`, id, userID, idx); err != nil {
-					return SaveMapping{}, time.Time{}, err
-				}
-				count++
-			}
-			log.Printf("save op reorderExercises key=%s user=%s dayId=%s count=%d", safeStr(idKey), userID, op.DayID, count)
-		case opReorderSets:
-			var op reorderSetsOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid reorderSets: %w", err)
+				return fmt.Errorf("invalid set id in reorder: %s", op.OrderedIDs[idx])
 			}
-			exID := resolveId(op.ExerciseID, tempToRealExercise)
-			if exID == "" {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid reorderSets.exerciseId: %s", op.ExerciseID)
-			}
-			count := 0
-			for idx, id := range op.OrderedIDs {
-				id = resolveId(id, tempToRealSet)
-				if id == "" {
-					return SaveMapping{}, time.Time{}, fmt.Errorf("invalid set id in reorder: %s", op.OrderedIDs[idx])
-				}
-				if _, err = tx.ExecContext(ctx, `
-					update sets s set position = $3
-					where s.id = $1 and s.user_id = $2
-				`, id, userID, idx); err != nil {
-					return SaveMapping{}, time.Time{}, err
-				}
-				count++
-			}
-			log.Printf("save op reorderSets key=%s user=%s exerciseId=%s count=%d", safeStr(idKey), userID, exID, count)
-		case opDeleteExercise:
-			var op deleteExerciseOp
-			if err = json.Unmarshal(e.raw, &op); err != nil {
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid deleteExercise: %w", err)
-			}
-			eid := resolveId(op.ExerciseID, tempToRealExercise)
-			if eid == "" && strings.HasPrefix(op.ExerciseID, "temp:") { // Changed op.ID to op.ExerciseID
-				return SaveMapping{}, time.Time{}, fmt.Errorf("invalid deleteExercise id: %s", op.ExerciseID) // Changed op.ID to op.ExerciseID
-			}
-			if eid == "" {
-				eid = op.ExerciseID // Changed op.ID to op.ExerciseID
-			}
-			if _, err = tx.ExecContext(ctx, `
-				delete from exercises e
-				where e.id = $1
-				  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
-			`, eid, userID); err != nil {
-				return SaveMapping{}, time.Time{}, err
+			ids = append(ids, id)
+		}
+		if _, err = reorderSets(ctx, tx, userID, exID, ids); err != nil {
+			return err
+		}
+		count := len(ids)
+		logging.Debugf("save op reorderSets key=%s user=%s exerciseId=%s count=%d", logging.Redact(idKey), userID, exID, count)
+	case opDeleteExercise:
+		var op deleteExerciseOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid deleteExercise: %w", err)
+		}
+		eid := resolveId(op.ExerciseID, st.exercises)
+		if eid == "" && strings.HasPrefix(op.ExerciseID, "temp:") { // Changed op.ID to op.ExerciseID
+			return fmt.Errorf("invalid deleteExercise id: %s", op.ExerciseID) // Changed op.ID to op.ExerciseID
+		}
+		if eid == "" {
+			eid = op.ExerciseID // Changed op.ID to op.ExerciseID
+		}
+		if _, err = tx.ExecContext(ctx, `
+			delete from exercises e
+			where e.id = $1
+			  and exists (select 1 from workout_days d where d.id = e.day_id and d.user_id = $2)
+		`, eid, userID); err != nil {
+			return err
+		}
+		logging.Debugf("save op deleteExercise key=%s user=%s id=%s", logging.Redact(idKey), userID, op.ExerciseID) // Changed op.ID to op.ExerciseID
+	case opMoveExercise:
+		var op moveExerciseOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid moveExercise: %w", err)
+		}
+		if strings.TrimSpace(op.ExerciseID) == "" || strings.TrimSpace(op.DayID) == "" {
+			return errors.New("moveExercise missing exerciseId or dayId")
+		}
+		exID := resolveId(op.ExerciseID, st.exercises)
+		dayID := resolveId(op.DayID, st.days)
+		ex, err := moveExercise(ctx, tx, userID, exID, dayID, op.Position)
+		if err != nil {
+			return err
+		}
+		if ex == nil {
+			return fmt.Errorf("moveExercise: exercise %s or day %s not found", op.ExerciseID, op.DayID)
+		}
+		logging.Debugf("save op moveExercise key=%s user=%s id=%s dayId=%s pos_set=%t",
+			logging.Redact(idKey), userID, exID, dayID, op.Position != nil)
+	case opDuplicateExercise:
+		var op duplicateExerciseOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid duplicateExercise: %w", err)
+		}
+		if strings.TrimSpace(op.LocalID) == "" || strings.TrimSpace(op.ExerciseID) == "" {
+			return errors.New("duplicateExercise missing localId or exerciseId")
+		}
+		srcID := resolveId(op.ExerciseID, st.exercises)
+		newID, setIDs, restIDs, err := duplicateExercise(ctx, tx, userID, srcID, op.Position)
+		if err != nil {
+			return err
+		}
+		if newID == "" {
+			return fmt.Errorf("duplicateExercise: exercise %s not found", op.ExerciseID)
+		}
+		st.exercises[op.LocalID] = newID
+		st.mapping.Exercises = append(st.mapping.Exercises, LocalIdMap{LocalID: op.LocalID, ID: newID})
+		for i, id := range setIDs {
+			local := cloneLocalID(op.SetLocalIDs, i, op.LocalID, "set")
+			st.sets[local] = id
+			st.mapping.Sets = append(st.mapping.Sets, LocalIdMap{LocalID: local, ID: id})
+		}
+		for i, id := range restIDs {
+			local := cloneLocalID(op.RestLocalIDs, i, op.LocalID, "rest")
+			st.rests[local] = id
+			st.mapping.Rests = append(st.mapping.Rests, LocalIdMap{LocalID: local, ID: id})
+		}
+		logging.Debugf("save op duplicateExercise key=%s user=%s localId=%s id=%s sourceId=%s sets=%d rests=%d",
+			logging.Redact(idKey), userID, op.LocalID, newID, srcID, len(setIDs), len(restIDs))
+	case opDuplicateSet:
+		var op duplicateSetOp
+		if err = e.decode(&op); err != nil {
+			return fmt.Errorf("invalid duplicateSet: %w", err)
+		}
+		if strings.TrimSpace(op.LocalID) == "" || strings.TrimSpace(op.SetID) == "" {
+			return errors.New("duplicateSet missing localId or setId")
+		}
+		srcID := resolveId(op.SetID, st.sets)
+		const qDupSet = `
+			insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir, is_warmup, rest_seconds, tempo,
+			                  set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps)
+			select s.exercise_id, s.user_id, s.workout_date,
+			       coalesce($3, (select coalesce(max(position) + 1, 0) from sets where exercise_id = s.exercise_id)),
+			       s.reps, s.weight_kg, s.rpe, s.rir, s.is_warmup, s.rest_seconds, s.tempo,
+			       s.set_type, s.duration_seconds, s.distance_m, s.side, s.to_failure, s.assisted_reps, s.partial_reps
+			from sets s
+			where s.id = $1 and s.user_id = $2
+			returning id
+		`
+		var newID string
+		if err = tx.QueryRowxContext(ctx, qDupSet, srcID, userID, op.Position).Scan(&newID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("duplicateSet: set %s not found", op.SetID)
 			}
-			log.Printf("save op deleteExercise key=%s user=%s id=%s", safeStr(idKey), userID, op.ExerciseID) // Changed op.ID to op.ExerciseID
-		default:
-			return SaveMapping{}, time.Time{}, fmt.Errorf("unknown op type: %s", string(e.Type))
+			return err
 		}
+		st.sets[op.LocalID] = newID
+		st.mapping.Sets = append(st.mapping.Sets, LocalIdMap{LocalID: op.LocalID, ID: newID})
+		logging.Debugf("save op duplicateSet key=%s user=%s localId=%s id=%s sourceId=%s",
+			logging.Redact(idKey), userID, op.LocalID, newID, srcID)
+	default:
+		return fmt.Errorf("unknown op type: %s", string(e.Type))
 	}
 
-	if err = tx.Commit(); err != nil {
-		return SaveMapping{}, time.Time{}, err
+	return nil
+}
+
+// duplicateExercise clones an exercise, its sets and its rests within the
+// same day, appending it after the day's last exercise unless position is
+// given. The new set and rest ids are returned in the source's position
+// order; newID is empty when the exercise doesn't belong to the user.
+func duplicateExercise(ctx context.Context, tx *sqlx.Tx, userID, srcID string, position *int) (newID string, setIDs, restIDs []string, err error) {
+	const qEx = `
+		insert into exercises (day_id, catalog_id, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg)
+		select e.day_id, e.catalog_id,
+		       coalesce($3, (select coalesce(max(position) + 1, 0) from exercises where day_id = e.day_id)),
+		       e.comment, e.planned_sets, e.planned_reps, e.planned_reps_max, e.planned_amrap, e.planned_weight_kg
+		from exercises e
+		join workout_days d on d.id = e.day_id
+		where e.id = $1 and d.user_id = $2
+		returning id
+	`
+	if err = tx.QueryRowxContext(ctx, qEx, srcID, userID, position).Scan(&newID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, nil, nil
+		}
+		return "", nil, nil, err
 	}
-	log.Printf("save batch commit key=%s user=%s createdExercises=%d createdSets=%d createdRests=%d", safeStr(idKey), userID, len(mapping.Exercises), len(mapping.Sets), len(mapping.Rests))
-	return mapping, time.Now().UTC(), nil
+
+	var srcSets []string
+	if err = tx.SelectContext(ctx, &srcSets, `select id from sets where exercise_id = $1 order by position, created_at`, srcID); err != nil {
+		return "", nil, nil, err
+	}
+	for _, id := range srcSets {
+		var sid string
+		if err = tx.QueryRowxContext(ctx, `
+			insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir, is_warmup, rest_seconds, tempo,
+			                  set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps)
+			select $2, user_id, workout_date, position, reps, weight_kg, rpe, rir, is_warmup, rest_seconds, tempo,
+			       set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps
+			from sets where id = $1
+			returning id
+		`, id, newID).Scan(&sid); err != nil {
+			return "", nil, nil, err
+		}
+		setIDs = append(setIDs, sid)
+	}
+
+	var srcRests []string
+	if err = tx.SelectContext(ctx, &srcRests, `select id from rest_periods where exercise_id = $1 order by position, created_at`, srcID); err != nil {
+		return "", nil, nil, err
+	}
+	for _, id := range srcRests {
+		var rid string
+		if err = tx.QueryRowxContext(ctx, `
+			insert into rest_periods (exercise_id, position, duration_seconds)
+			select $2, position, duration_seconds
+			from rest_periods where id = $1
+			returning id
+		`, id, newID).Scan(&rid); err != nil {
+			return "", nil, nil, err
+		}
+		restIDs = append(restIDs, rid)
+	}
+	return newID, setIDs, restIDs, nil
+}
+
+// cloneLocalID picks the client's local id for the i-th cloned child, falling
+// back to a derived one when the client didn't supply enough.
+func cloneLocalID(given []string, i int, parent, kind string) string {
+	if i < len(given) && strings.TrimSpace(given[i]) != "" {
+		return given[i]
+	}
+	return fmt.Sprintf("%s:%s:%d", parent, kind, i)
 }
 
 func resolveId(id string, tempMap map[string]string) string {
@@ -515,17 +1045,10 @@ func resolveId(id string, tempMap map[string]string) string {
 	return id
 }
 
-func safeStr(s string) string {
-	if strings.TrimSpace(s) == "" {
-		return "-"
-	}
-	return s
-}
-
 // CurrentEpoch returns the stored epoch for a user, or 0 on error/missing.
 func (s *Save) CurrentEpoch(ctx context.Context, userID string) int64 {
 	var epoch sql.NullInt64
-	if err := s.db.QueryRowxContext(ctx, `select save_epoch from users where id = $1`, userID).Scan(&epoch); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, `select save_epoch from users where id = $1`, userID).Scan(&epoch); err != nil {
 		return 0
 	}
 	if epoch.Valid {
@@ -536,8 +1059,54 @@ func (s *Save) CurrentEpoch(ctx context.Context, userID string) int64 {
 
 // SetEpoch updates the user's epoch to the provided value.
 func (s *Save) SetEpoch(ctx context.Context, userID string, epoch int64) error {
-	_, err := s.db.ExecContext(ctx, `update users set save_epoch = $2 where id = $1`, userID, epoch)
+	_, err := conn(ctx, s.db).ExecContext(ctx, `update users set save_epoch = $2 where id = $1`, userID, epoch)
 	return err
 }
 
-
+// insertAutoRest appends a rest to the exercise when its last item is a set
+// and a default rest applies, returning the rest with the position for the
+// set that follows it. It returns nil when no rest is needed.
+func insertAutoRest(ctx context.Context, tx *sqlx.Tx, userID, exerciseID string) (*AutoRest, error) {
+	var row struct {
+		Seconds   *int  `db:"rest_seconds"`
+		LastPos   *int  `db:"last_position"`
+		LastIsSet *bool `db:"last_is_set"`
+	}
+	if err := tx.GetContext(ctx, &row, `
+		with items as (
+		  select s.position, true as is_set from sets s where s.exercise_id = $1
+		  union all
+		  select rp.position, false from rest_periods rp where rp.exercise_id = $1
+		)
+		select coalesce(uer.rest_seconds, us.default_rest_seconds) as rest_seconds,
+		       (select max(position) from items) as last_position,
+		       (select is_set from items order by position desc, is_set limit 1) as last_is_set
+		from exercises e
+		join workout_days d on d.id = e.day_id
+		left join user_exercise_rest uer on uer.user_id = d.user_id and uer.catalog_id = e.catalog_id
+		left join user_settings us on us.user_id = d.user_id
+		where e.id = $1 and d.user_id = $2
+	`, exerciseID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if row.Seconds == nil || row.LastPos == nil || row.LastIsSet == nil || !*row.LastIsSet {
+		return nil, nil
+	}
+	rest := &AutoRest{
+		ExerciseID:      exerciseID,
+		Position:        *row.LastPos + 1,
+		DurationSeconds: *row.Seconds,
+		SetPosition:     *row.LastPos + 2,
+	}
+	if err := tx.QueryRowxContext(ctx, `
+		insert into rest_periods (exercise_id, position, duration_seconds)
+		values ($1, $2, $3)
+		returning id
+	`, exerciseID, rest.Position, rest.DurationSeconds).Scan(&rest.ID); err != nil {
+		return nil, err
+	}
+	return rest, nil
+}
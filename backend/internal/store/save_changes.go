@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"exercise-tracker/internal/models"
+)
+
+// maxSyncChanges caps each entity list in a SyncChanges. A client that is
+// further behind than this gets Truncated and should refetch instead.
+const maxSyncChanges = 500
+
+type DeletedEntity struct {
+	Type      string    `db:"entity_type" json:"type"` // exercise | set | rest
+	ID        string    `db:"entity_id" json:"id"`
+	DeletedAt time.Time `db:"deleted_at" json:"deletedAt"`
+}
+
+// SyncChanges lists a user's days, exercises, sets and rests that were
+// created, updated or deleted after Since.
+type SyncChanges struct {
+	Since     time.Time           `json:"since"`
+	Days      []models.WorkoutDay `json:"days"`
+	Exercises []models.Exercise   `json:"exercises"`
+	Sets      []models.Set        `json:"sets"`
+	Rests     []models.RestPeriod `json:"rests"`
+	Deleted   []DeletedEntity     `json:"deleted"`
+	Truncated bool                `json:"truncated,omitempty"`
+}
+
+// ChangesSince returns what changed server-side after the client's save
+// epoch (unix milliseconds), so a client rejected with stale_epoch can merge.
+func (s *Save) ChangesSince(ctx context.Context, userID string, epoch int64) (*SyncChanges, error) {
+	since := time.UnixMilli(epoch).UTC()
+	c := &SyncChanges{
+		Since:     since,
+		Days:      []models.WorkoutDay{},
+		Exercises: []models.Exercise{},
+		Sets:      []models.Set{},
+		Rests:     []models.RestPeriod{},
+		Deleted:   []DeletedEntity{},
+	}
+	const limit = maxSyncChanges + 1
+	if err := conn(ctx, s.db).SelectContext(ctx, &c.Days, `
+		select id, user_id, workout_date, timezone, notes, is_rest_day, visibility, program_id, started_at, finished_at, duration_seconds, created_at, updated_at
+		from workout_days
+		where user_id = $1 and updated_at > $2
+		order by updated_at
+		limit $3`, userID, since, limit); err != nil {
+		return nil, err
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &c.Exercises, `
+		select e.id, e.day_id, e.catalog_id, e.name, e.position, e.comment, e.planned_sets, e.planned_reps, e.planned_reps_max, e.planned_amrap, e.planned_weight_kg, e.created_at, e.updated_at
+		from exercises e
+		join workout_days d on d.id = e.day_id
+		where d.user_id = $1 and e.updated_at > $2
+		order by e.updated_at
+		limit $3`, userID, since, limit); err != nil {
+		return nil, err
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &c.Sets, `
+		select id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir,
+		       is_warmup, rest_seconds, tempo, performed_at, set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps,
+		       volume_kg, coalesce(effective_load_kg, weight_kg) as effective_load_kg, created_at, updated_at
+		from sets
+		where user_id = $1 and updated_at > $2
+		order by updated_at
+		limit $3`, userID, since, limit); err != nil {
+		return nil, err
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &c.Rests, `
+		select rp.id, rp.exercise_id, rp.position, rp.duration_seconds, rp.created_at, rp.updated_at
+		from rest_periods rp
+		join exercises e on e.id = rp.exercise_id
+		join workout_days d on d.id = e.day_id
+		where d.user_id = $1 and rp.updated_at > $2
+		order by rp.updated_at
+		limit $3`, userID, since, limit); err != nil {
+		return nil, err
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &c.Deleted, `
+		select entity_type, entity_id, deleted_at
+		from deleted_entities
+		where user_id = $1 and deleted_at > $2
+		order by deleted_at
+		limit $3`, userID, since, limit); err != nil {
+		return nil, err
+	}
+	if len(c.Days) > maxSyncChanges {
+		c.Days, c.Truncated = c.Days[:maxSyncChanges], true
+	}
+	if len(c.Exercises) > maxSyncChanges {
+		c.Exercises, c.Truncated = c.Exercises[:maxSyncChanges], true
+	}
+	if len(c.Sets) > maxSyncChanges {
+		c.Sets, c.Truncated = c.Sets[:maxSyncChanges], true
+	}
+	if len(c.Rests) > maxSyncChanges {
+		c.Rests, c.Truncated = c.Rests[:maxSyncChanges], true
+	}
+	if len(c.Deleted) > maxSyncChanges {
+		c.Deleted, c.Truncated = c.Deleted[:maxSyncChanges], true
+	}
+	return c, nil
+}
+
+// PruneTombstones drops deletion records older than before; clients that far
+// behind refetch instead of merging.
+func (s *Save) PruneTombstones(ctx context.Context, before time.Time) (int64, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from deleted_entities where deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+var saveOpSeeds = []string{
+	`{"type":"createDay","localId":"d1","workoutDate":"2024-05-01","timezone":"UTC"}`,
+	`{"type":"createExercise","localId":"e1","dayId":"d1","catalogId":"c1","position":0,"comment":"slow"}`,
+	`{"type":"createSet","localId":"s1","exerciseId":"e1","position":0,"reps":5,"weightKg":100,"setType":"strength"}`,
+	`{"type":"updateSet","setId":"s1","patch":{"reps":6,"side":"left"}}`,
+	`{"type":"updateExercise","exerciseId":"e1","patch":{"position":2}}`,
+	`{"type":"reorderSets","exerciseId":"e1","orderedIds":["s2","s1"]}`,
+	`{"type":"setDayTiming","dayId":"d1","startedAt":"2024-05-01T10:00:00Z"}`,
+	`{"type":"duplicateExercise","localId":"e2","exerciseId":"e1","setLocalIds":["x"]}`,
+	`{"type":"deleteRest","restId":"r1"}`,
+	`{"type":"nope"}`,
+	`{"type":"createSet","reps":"five"}`,
+	`{"type":"updateSet","setId":"s1","patch":{"reps":6,"extra":true}}`,
+	`null`,
+	`[]`,
+	`{"type":1}`,
+}
+
+func FuzzDecodeOp(f *testing.F) {
+	for _, s := range saveOpSeeds {
+		f.Add([]byte(s), false)
+		f.Add([]byte(s), true)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, strict bool) {
+		e, err := decodeOp(data, strict)
+		if err != nil {
+			return
+		}
+		op := newOp(e.Type)
+		if op == nil {
+			t.Fatalf("accepted unknown op type %q", e.Type)
+		}
+		if err := e.decode(op); err != nil && strict {
+			t.Fatalf("strict mode accepted an op that doesn't decode: %v", err)
+		}
+		if strict {
+			if _, err := decodeOp(data, false); err != nil {
+				t.Fatalf("accepted in strict mode but not in lenient mode: %v", err)
+			}
+		}
+	})
+}
+
+func TestDecodeOpStrict(t *testing.T) {
+	tests := []struct {
+		op      string
+		lenient bool
+		strict  bool
+	}{
+		{op: saveOpSeeds[2], lenient: true, strict: true},
+		{op: `{"type":"deleteSet","setId":"s1","force":true}`, lenient: true, strict: false},
+		{op: saveOpSeeds[11], lenient: true, strict: false},
+		{op: saveOpSeeds[9], lenient: false, strict: false},
+		{op: `null`, lenient: false, strict: false},
+		{op: `{"type":"createSet","reps":"five"}`, lenient: true, strict: false},
+	}
+	for _, tc := range tests {
+		for _, strict := range []bool{false, true} {
+			_, err := decodeOp([]byte(tc.op), strict)
+			want := tc.lenient
+			if strict {
+				want = tc.strict
+			}
+			if (err == nil) != want {
+				t.Errorf("decodeOp(%s, strict=%v) error = %v, want ok=%v", strings.TrimSpace(tc.op), strict, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SaveLimits bounds a single save batch so one request can't hold the
+// transaction open indefinitely. Zero disables a limit. MaxBodyBytes is left
+// to the transport, which sees the request before it is decoded.
+type SaveLimits struct {
+	MaxOps       int
+	MaxBodyBytes int64
+	MaxStringLen int
+}
+
+// Codes of a SaveLimitError.
+const (
+	SaveLimitTooManyOps    = "too_many_ops"
+	SaveLimitStringTooLong = "string_too_long"
+	SaveLimitInvalidJSON   = "invalid_json"
+)
+
+// SaveLimitError is a batch rejected by SaveLimits.Check before any op ran.
+type SaveLimitError struct {
+	Code    string
+	Message string
+}
+
+func (e *SaveLimitError) Error() string { return e.Message }
+
+// Check returns a *SaveLimitError when the batch exceeds l.
+func (l SaveLimits) Check(idempotencyKey string, ops []json.RawMessage) error {
+	if l.MaxOps > 0 && len(ops) > l.MaxOps {
+		return &SaveLimitError{Code: SaveLimitTooManyOps,
+			Message: fmt.Sprintf("Batch has %d ops; the limit is %d. Split it into smaller batches.", len(ops), l.MaxOps)}
+	}
+	limit := l.MaxStringLen
+	if limit <= 0 {
+		return nil
+	}
+	if len(idempotencyKey) > limit {
+		return &SaveLimitError{Code: SaveLimitStringTooLong,
+			Message: fmt.Sprintf("idempotencyKey exceeds %d characters.", limit)}
+	}
+	for i, raw := range ops {
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return &SaveLimitError{Code: SaveLimitInvalidJSON, Message: "invalid json"}
+		}
+		if longestString(v) > limit {
+			return &SaveLimitError{Code: SaveLimitStringTooLong,
+				Message: fmt.Sprintf("Op %d has a string value longer than %d characters.", i, limit)}
+		}
+	}
+	return nil
+}
+
+// longestString returns the length in characters of the longest string
+// anywhere in a decoded JSON value.
+func longestString(v any) int {
+	longest := 0
+	switch t := v.(type) {
+	case string:
+		longest = utf8.RuneCountInString(t)
+	case []any:
+		for _, e := range t {
+			longest = max(longest, longestString(e))
+		}
+	case map[string]any:
+		for k, e := range t {
+			longest = max(longest, utf8.RuneCountInString(k), longestString(e))
+		}
+	}
+	return longest
+}
+
+// IsSaveInputError reports whether a ProcessBatch error was caused by the ops
+// themselves (a malformed op, a bad reference or a violated constraint)
+// rather than by the database failing. The former is the client's to fix
+// and safe to show; the latter should be logged and reported generically.
+func IsSaveInputError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 22 is data exceptions and 23 integrity constraint
+		// violations, which the triggers raise too.
+		return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, sql.ErrConnDone), errors.Is(err, sql.ErrTxDone), errors.Is(err, driver.ErrBadConn),
+		errors.As(err, &connectErr), errors.As(err, &netErr):
+		return false
+	}
+	return true
+}
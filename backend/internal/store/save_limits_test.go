@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestSaveLimitsCheck(t *testing.T) {
+	ops := []json.RawMessage{
+		json.RawMessage(`{"type":"createDay","localId":"d1","workoutDate":"2024-01-01"}`),
+		json.RawMessage(`{"type":"updateDayNotes","dayId":"d1","notes":"` + strings.Repeat("x", 20) + `"}`),
+	}
+	for _, tc := range []struct {
+		limits SaveLimits
+		key    string
+		code   string
+	}{
+		{SaveLimits{}, "", ""},
+		{SaveLimits{MaxOps: 1}, "", SaveLimitTooManyOps},
+		{SaveLimits{MaxStringLen: 10}, "", SaveLimitStringTooLong},
+		{SaveLimits{MaxStringLen: 30}, strings.Repeat("k", 31), SaveLimitStringTooLong},
+		{SaveLimits{MaxOps: 2, MaxStringLen: 30}, "key", ""},
+	} {
+		err := tc.limits.Check(tc.key, ops)
+		var le *SaveLimitError
+		if tc.code == "" && err != nil || tc.code != "" && (!errors.As(err, &le) || le.Code != tc.code) {
+			t.Errorf("%+v key %d chars: got %v, want %q", tc.limits, len(tc.key), err, tc.code)
+		}
+	}
+}
+
+func TestIsSaveInputError(t *testing.T) {
+	for _, err := range []error{
+		errors.New("updateDay missing dayId"),
+		fmt.Errorf("invalid createSet: %w", &pgconn.PgError{Code: "23514", ConstraintName: "sets_reps_check"}),
+		&pgconn.PgError{Code: "22P02"},
+	} {
+		if !IsSaveInputError(err) {
+			t.Errorf("%v should be an input error", err)
+		}
+	}
+	for _, err := range []error{
+		nil,
+		&pgconn.PgError{Code: "40001"},
+		fmt.Errorf("could not create day: %w", &pgconn.PgError{Code: "57P01"}),
+		context.DeadlineExceeded,
+	} {
+		if IsSaveInputError(err) {
+			t.Errorf("%v should not be an input error", err)
+		}
+	}
+}
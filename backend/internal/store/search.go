@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Search hit kinds.
+const (
+	SearchHitNote     = "note"
+	SearchHitExercise = "exercise"
+	SearchHitComment  = "comment"
+)
+
+const (
+	maxSearchRows    = 200
+	searchSnippetLen = 160
+)
+
+// Highlight marks a match in a snippet, in runes.
+type Highlight struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+type SearchHit struct {
+	Kind       string      `json:"kind"`
+	ExerciseID *string     `json:"exerciseId,omitempty"`
+	Snippet    string      `json:"snippet"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+type DaySearchResult struct {
+	DayID       string      `json:"dayId"`
+	WorkoutDate string      `json:"workoutDate"`
+	Hits        []SearchHit `json:"hits"`
+}
+
+// SearchHistory finds q in the user's day notes, exercise names and exercise
+// comments (substring, case-insensitive, served by trigram indexes) and
+// groups the hits by day, newest first.
+func (s *Days) SearchHistory(ctx context.Context, userID, q string) ([]DaySearchResult, error) {
+	const query = `
+		select d.id as day_id, d.workout_date, 'note' as kind, null::uuid as exercise_id, d.notes as text
+		from workout_days d
+		where d.user_id = $1 and d.notes ilike $2 escape '\'
+		union all
+		select d.id, d.workout_date, 'exercise', e.id, e.name
+		from exercises e join workout_days d on d.id = e.day_id
+		where d.user_id = $1 and e.name ilike $2 escape '\'
+		union all
+		select d.id, d.workout_date, 'comment', e.id, e.comment
+		from exercises e join workout_days d on d.id = e.day_id
+		where d.user_id = $1 and e.comment ilike $2 escape '\'
+		order by workout_date desc, day_id, kind
+		limit $3
+	`
+	var rows []struct {
+		DayID       string    `db:"day_id"`
+		WorkoutDate time.Time `db:"workout_date"`
+		Kind        string    `db:"kind"`
+		ExerciseID  *string   `db:"exercise_id"`
+		Text        string    `db:"text"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, query, userID, "%"+escapeLike(q)+"%", maxSearchRows); err != nil {
+		return nil, err
+	}
+	out := []DaySearchResult{}
+	for _, r := range rows {
+		if len(out) == 0 || out[len(out)-1].DayID != r.DayID {
+			out = append(out, DaySearchResult{DayID: r.DayID, WorkoutDate: r.WorkoutDate.Format("2006-01-02")})
+		}
+		snippet, highlights := highlightMatches(r.Text, q, searchSnippetLen)
+		day := &out[len(out)-1]
+		day.Hits = append(day.Hits, SearchHit{Kind: r.Kind, ExerciseID: r.ExerciseID, Snippet: snippet, Highlights: highlights})
+	}
+	return out, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// highlightMatches trims text to at most maxLen runes around the first match
+// of q and returns the case-insensitive match positions within the snippet.
+func highlightMatches(text, q string, maxLen int) (string, []Highlight) {
+	runes := []rune(text)
+	lower := foldRunes(runes)
+	needle := foldRunes([]rune(q))
+	first := indexRunes(lower, needle, 0)
+	start := 0
+	if len(runes) > maxLen && first > maxLen/4 {
+		start = first - maxLen/4
+		if start > len(runes)-maxLen {
+			start = len(runes) - maxLen
+		}
+	}
+	end := len(runes)
+	if end-start > maxLen {
+		end = start + maxLen
+	}
+	highlights := []Highlight{}
+	if len(needle) > 0 {
+		for i := indexRunes(lower, needle, start); i >= 0 && i+len(needle) <= end; i = indexRunes(lower, needle, i+len(needle)) {
+			highlights = append(highlights, Highlight{Start: i - start, Length: len(needle)})
+		}
+	}
+	return string(runes[start:end]), highlights
+}
+
+func foldRunes(rs []rune) []rune {
+	out := make([]rune, len(rs))
+	for i, r := range rs {
+		out[i] = unicode.ToLower(r)
+	}
+	return out
+}
+
+func indexRunes(haystack, needle []rune, from int) int {
+	if len(needle) == 0 {
+		return -1
+	}
+	for i := from; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
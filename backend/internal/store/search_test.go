@@ -0,0 +1,33 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightMatches(t *testing.T) {
+	snippet, hl := highlightMatches("Felt strong on Bench; bench PR next week", "bench", 160)
+	if snippet != "Felt strong on Bench; bench PR next week" {
+		t.Fatalf("snippet = %q", snippet)
+	}
+	if len(hl) != 2 || hl[0] != (Highlight{Start: 15, Length: 5}) || hl[1] != (Highlight{Start: 22, Length: 5}) {
+		t.Fatalf("highlights = %+v", hl)
+	}
+}
+
+func TestHighlightMatchesTrimsAroundFirstMatch(t *testing.T) {
+	text := strings.Repeat("a", 300) + "squat" + strings.Repeat("b", 300)
+	snippet, hl := highlightMatches(text, "SQUAT", 100)
+	if n := len([]rune(snippet)); n != 100 {
+		t.Fatalf("snippet length = %d", n)
+	}
+	if len(hl) != 1 || snippet[hl[0].Start:hl[0].Start+hl[0].Length] != "squat" {
+		t.Fatalf("highlights = %+v in %q", hl, snippet)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := escapeLike(`50%_off\`); got != `50\%\_off\\` {
+		t.Fatalf("escapeLike = %q", got)
+	}
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"exercise-tracker/internal/models"
+)
+
+// SessionTimeline reconstructs when each set of a day happened, for pacing
+// and density views.
+type SessionTimeline struct {
+	DayID      string     `json:"dayId"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Items are the sets with a performedAt, oldest first.
+	Items []TimelineSet `json:"items"`
+	// Unstamped are the sets without a performedAt, in workout order.
+	Unstamped []TimelineSet `json:"unstamped"`
+	Stats     TimelineStats `json:"stats"`
+}
+
+type TimelineSet struct {
+	SetID        string     `db:"set_id" json:"setId"`
+	ExerciseID   string     `db:"exercise_id" json:"exerciseId"`
+	ExerciseName string     `db:"exercise_name" json:"exerciseName"`
+	Position     int        `db:"position" json:"position"`
+	Reps         int        `db:"reps" json:"reps"`
+	WeightKg     float64    `db:"weight_kg" json:"weightKg"`
+	VolumeKg     float64    `db:"volume_kg" json:"volumeKg"`
+	IsWarmup     bool       `db:"is_warmup" json:"isWarmup"`
+	PerformedAt  *time.Time `db:"performed_at" json:"performedAt,omitempty"`
+	Tempo        *string    `db:"tempo" json:"tempo,omitempty"`
+	RPE          *float64   `db:"rpe" json:"rpe,omitempty"`
+	RIR          *int       `db:"rir" json:"rir,omitempty"`
+	// TimeUnderTensionSeconds is reps × tempo, nil without a valid tempo.
+	TimeUnderTensionSeconds *int `db:"-" json:"timeUnderTensionSeconds,omitempty"`
+	// OffsetSeconds is the time since the session started (startedAt, or
+	// the first set without one); GapSeconds is the time since the
+	// previous set.
+	OffsetSeconds *int `db:"-" json:"offsetSeconds,omitempty"`
+	GapSeconds    *int `db:"-" json:"gapSeconds,omitempty"`
+}
+
+// TimelineStats summarizes the stamped sets. Rates are nil until there are
+// two stamped sets at different times.
+type TimelineStats struct {
+	StampedSets      int      `json:"stampedSets"`
+	ActiveSeconds    int      `json:"activeSeconds"`
+	SetsPerHour      *float64 `json:"setsPerHour,omitempty"`
+	VolumePerMinute  *float64 `json:"volumePerMinute,omitempty"`
+	AvgGapSeconds    *float64 `json:"avgGapSeconds,omitempty"`
+	MedianGapSeconds *float64 `json:"medianGapSeconds,omitempty"`
+	// TimeUnderTensionSeconds totals the estimate over every working set
+	// with a tempo, stamped or not; TempoSets counts them.
+	TimeUnderTensionSeconds int `json:"timeUnderTensionSeconds"`
+	TempoSets               int `json:"tempoSets"`
+	// AvgRPE averages the effort of working sets that logged an RPE or RIR,
+	// counting RIR as 10 - RIR; EffortSets counts them.
+	AvgRPE     *float64 `json:"avgRpe,omitempty"`
+	EffortSets int      `json:"effortSets"`
+}
+
+// SessionTimeline returns the day's timeline, or nil, nil if the day isn't
+// the user's.
+func (s *Days) SessionTimeline(ctx context.Context, userID, dayID string) (*SessionTimeline, error) {
+	var day struct {
+		StartedAt  *time.Time `db:"started_at"`
+		FinishedAt *time.Time `db:"finished_at"`
+	}
+	err := conn(ctx, s.db).GetContext(ctx, &day, `select started_at, finished_at from workout_days where id = $1 and user_id = $2`, dayID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sets []TimelineSet
+	if err := conn(ctx, s.db).SelectContext(ctx, &sets, `
+		select s.id as set_id, s.exercise_id, e.name as exercise_name, s.position, s.reps, s.weight_kg,
+		       s.volume_kg, s.is_warmup, s.performed_at, s.tempo, s.rpe, s.rir
+		from sets s
+		join exercises e on e.id = s.exercise_id
+		where e.day_id = $1 and s.user_id = $2
+		order by e.position, s.position
+	`, dayID, userID); err != nil {
+		return nil, err
+	}
+	t := buildSessionTimeline(sets, day.StartedAt)
+	t.DayID, t.StartedAt, t.FinishedAt = dayID, day.StartedAt, day.FinishedAt
+	return t, nil
+}
+
+// buildSessionTimeline splits sets (in workout order) into stamped and
+// unstamped, orders the stamped ones by time and fills offsets, gaps and
+// stats.
+func buildSessionTimeline(sets []TimelineSet, startedAt *time.Time) *SessionTimeline {
+	t := &SessionTimeline{Items: []TimelineSet{}, Unstamped: []TimelineSet{}}
+	effort := 0.0
+	for _, s := range sets {
+		s.TimeUnderTensionSeconds = models.TimeUnderTension(s.Tempo, s.Reps)
+		if s.TimeUnderTensionSeconds != nil && !s.IsWarmup {
+			t.Stats.TimeUnderTensionSeconds += *s.TimeUnderTensionSeconds
+			t.Stats.TempoSets++
+		}
+		if rpe := models.EffectiveRPE(s.RPE, s.RIR); rpe != nil && !s.IsWarmup {
+			effort += *rpe
+			t.Stats.EffortSets++
+		}
+		if s.PerformedAt == nil {
+			t.Unstamped = append(t.Unstamped, s)
+		} else {
+			t.Items = append(t.Items, s)
+		}
+	}
+	if t.Stats.EffortSets > 0 {
+		avg := roundTo(effort/float64(t.Stats.EffortSets), 1)
+		t.Stats.AvgRPE = &avg
+	}
+	sort.SliceStable(t.Items, func(i, j int) bool { return t.Items[i].PerformedAt.Before(*t.Items[j].PerformedAt) })
+	if len(t.Items) == 0 {
+		return t
+	}
+
+	start := *t.Items[0].PerformedAt
+	if startedAt != nil && startedAt.Before(start) {
+		start = *startedAt
+	}
+	var gaps []float64
+	volume := 0.0
+	for i := range t.Items {
+		it := &t.Items[i]
+		off := int(it.PerformedAt.Sub(start).Seconds())
+		it.OffsetSeconds = &off
+		if i > 0 {
+			gap := int(it.PerformedAt.Sub(*t.Items[i-1].PerformedAt).Seconds())
+			it.GapSeconds = &gap
+			gaps = append(gaps, float64(gap))
+		}
+		volume += it.VolumeKg
+	}
+
+	st := &t.Stats
+	st.StampedSets = len(t.Items)
+	first, last := *t.Items[0].PerformedAt, *t.Items[len(t.Items)-1].PerformedAt
+	st.ActiveSeconds = int(last.Sub(first).Seconds())
+	if st.ActiveSeconds > 0 {
+		perHour := float64(len(t.Items)) / (float64(st.ActiveSeconds) / 3600)
+		perMinute := volume / (float64(st.ActiveSeconds) / 60)
+		st.SetsPerHour, st.VolumePerMinute = &perHour, &perMinute
+	}
+	if len(gaps) > 0 {
+		sum := 0.0
+		for _, g := range gaps {
+			sum += g
+		}
+		avg := sum / float64(len(gaps))
+		sort.Float64s(gaps)
+		median := gaps[len(gaps)/2]
+		if len(gaps)%2 == 0 {
+			median = (gaps[len(gaps)/2-1] + gaps[len(gaps)/2]) / 2
+		}
+		st.AvgGapSeconds, st.MedianGapSeconds = &avg, &median
+	}
+	return t
+}
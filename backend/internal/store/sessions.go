@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Sessions struct {
+	db *sqlx.DB
+}
+
+func NewSessions(db *sqlx.DB) *Sessions { return &Sessions{db: db} }
+
+// Session is one signed-in device.
+type Session struct {
+	ID         string    `db:"id" json:"id"`
+	UserAgent  string    `db:"user_agent" json:"userAgent"`
+	IP         string    `db:"ip" json:"ip"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	LastSeenAt time.Time `db:"last_seen_at" json:"lastSeenAt"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expiresAt"`
+	// Current marks the session making the request; set by the handler.
+	Current bool `db:"-" json:"current"`
+}
+
+func (s *Sessions) Create(ctx context.Context, userID, userAgent, ip string, expiresAt time.Time) (*Session, error) {
+	const q = `
+		insert into sessions (user_id, user_agent, ip, expires_at)
+		values ($1, $2, $3, $4)
+		returning id, user_agent, ip, created_at, last_seen_at, expires_at
+	`
+	var sess Session
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, userAgent, ip, expiresAt).StructScan(&sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// List returns the user's active sessions, most recently seen first.
+func (s *Sessions) List(ctx context.Context, userID string) ([]Session, error) {
+	out := []Session{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select id, user_agent, ip, created_at, last_seen_at, expires_at
+		from sessions
+		where user_id = $1 and revoked_at is null and expires_at > now()
+		order by last_seen_at desc
+	`, userID)
+	return out, err
+}
+
+func (s *Sessions) Revoke(ctx context.Context, userID, id string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update sessions set revoked_at = now()
+		where id = $1 and user_id = $2 and revoked_at is null
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Touch reports whether the session is active for userID, stamping
+// last_seen_at at most once a minute.
+func (s *Sessions) Touch(ctx context.Context, userID, id string) (bool, error) {
+	var got string
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		update sessions
+		set last_seen_at = case
+		  when last_seen_at < now() - interval '1 minute' then now()
+		  else last_seen_at end
+		where id = $1 and user_id = $2 and revoked_at is null and expires_at > now()
+		returning id
+	`, id, userID).Scan(&got)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -3,13 +3,40 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 
 	"exercise-tracker/internal/models"
+	"exercise-tracker/internal/progression"
 )
 
+// ErrSetMeasurement means a set lacks what its type is measured by: reps for
+// strength, a duration and/or distance for the other types.
+var ErrSetMeasurement = errors.New("set is missing the measurement for its type")
+
+func isSetMeasurementViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.ConstraintName == "sets_reps_check" || pgErr.ConstraintName == "sets_type_measurement")
+}
+
+// ErrAssistedReps means a set would have more assisted reps than reps.
+var ErrAssistedReps = errors.New("assistedReps must be between 0 and reps")
+
+func isAssistedRepsViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "sets_assisted_reps_check"
+}
+
+// isEffortViolation reports a set whose RPE and RIR disagree, which a
+// partial update can cause against the stored value.
+func isEffortViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "sets_rir_rpe_check"
+}
+
 type Sets struct {
 	db *sqlx.DB
 }
@@ -23,42 +50,95 @@ type CreateSetParams struct {
 	Reps        int
 	WeightKg    float64
 	RPE         *float64
+	RIR         *int
 	IsWarmup    bool
 	RestSeconds *int
 	Tempo       *string
 	PerformedAt *time.Time
+	// SetType defaults to strength.
+	SetType         string
+	DurationSeconds *int
+	DistanceM       *float64
+	Side            *string
+	ToFailure       bool
+	AssistedReps    int
+	PartialReps     int
 }
 
 func (s *Sets) Create(ctx context.Context, p CreateSetParams) (*models.Set, error) {
 	const q = `
-		insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, is_warmup, rest_seconds, tempo, performed_at)
-		select $1, d.user_id, d.workout_date, $3, $4, $5, $6, $7, $8, $9, $10
+		insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, is_warmup, rest_seconds, tempo, performed_at,
+		                  set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps, rir)
+		select $1, d.user_id, d.workout_date, $3, $4, $5, $6, $7, $8, $9, $10, coalesce(nullif($11, ''), 'strength'), $12, $13, $14,
+		       $15, $16, $17, $18
 		from exercises e join workout_days d on d.id = e.day_id
 		where e.id = $1 and d.user_id = $2
-		returning id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe,
-		          is_warmup, rest_seconds, tempo, performed_at,
-				  volume_kg, created_at, updated_at
+		returning id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir,
+		          is_warmup, rest_seconds, tempo, performed_at, set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps,
+				  volume_kg, coalesce(effective_load_kg, weight_kg) as effective_load_kg, created_at, updated_at
 	`
 	var out models.Set
-	if err := s.db.QueryRowxContext(ctx, q,
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q,
 		p.ExerciseID, p.UserID, p.Position, p.Reps, p.WeightKg, p.RPE, p.IsWarmup, p.RestSeconds, p.Tempo, p.PerformedAt,
+		p.SetType, p.DurationSeconds, p.DistanceM, p.Side, p.ToFailure, p.AssistedReps, p.PartialReps, p.RIR,
 	).StructScan(&out); err != nil {
+		if isSetMeasurementViolation(err) {
+			return nil, ErrSetMeasurement
+		}
+		if isAssistedRepsViolation(err) {
+			return nil, ErrAssistedReps
+		}
+		if isEffortViolation(err) {
+			return nil, models.ErrEffortMismatch
+		}
 		return nil, err
 	}
 	return &out, nil
 }
 
+// UpdateSetParams leaves nil fields unchanged; an empty Tempo clears the
+// set's tempo.
 type UpdateSetParams struct {
-	ID          string
-	UserID      string
-	Position    *int
-	Reps        *int
-	WeightKg    *float64
-	RPE         *float64
-	IsWarmup    *bool
-	RestSeconds *int
-	Tempo       *string
-	PerformedAt *time.Time
+	ID              string
+	UserID          string
+	Position        *int
+	Reps            *int
+	WeightKg        *float64
+	RPE             *float64
+	RIR             *int
+	IsWarmup        *bool
+	RestSeconds     *int
+	Tempo           *string
+	PerformedAt     *time.Time
+	SetType         *string
+	DurationSeconds *int
+	DistanceM       *float64
+	Side            *string
+	ToFailure       *bool
+	AssistedReps    *int
+	PartialReps     *int
+}
+
+// GetForUpdate returns one of the user's sets and locks it until the
+// request transaction ends, or nil, nil if it isn't theirs. Handlers use it
+// to check a client's precondition before updating.
+func (s *Sets) GetForUpdate(ctx context.Context, userID, id string) (*models.Set, error) {
+	const q = `
+		select id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir,
+		       is_warmup, rest_seconds, tempo, performed_at, set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps,
+		       volume_kg, coalesce(effective_load_kg, weight_kg) as effective_load_kg, created_at, updated_at
+		from sets
+		where id = $1 and user_id = $2
+		for update
+	`
+	var out models.Set
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, id, userID).StructScan(&out); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
 }
 
 func (s *Sets) Update(ctx context.Context, p UpdateSetParams) (*models.Set, error) {
@@ -70,27 +150,45 @@ func (s *Sets) Update(ctx context.Context, p UpdateSetParams) (*models.Set, erro
 		  rpe = coalesce($6, s.rpe),
 		  is_warmup = coalesce($7, s.is_warmup),
 		  rest_seconds = coalesce($8, s.rest_seconds),
-		  tempo = coalesce($9, s.tempo),
-		  performed_at = coalesce($10, s.performed_at)
+		  tempo = case when $9::text is null then s.tempo else nullif($9::text, '') end,
+		  performed_at = coalesce($10, s.performed_at),
+		  set_type = coalesce($11, s.set_type),
+		  duration_seconds = coalesce($12, s.duration_seconds),
+		  distance_m = coalesce($13, s.distance_m),
+		  side = coalesce($14, s.side),
+		  to_failure = coalesce($15, s.to_failure),
+		  assisted_reps = coalesce($16, s.assisted_reps),
+		  partial_reps = coalesce($17, s.partial_reps),
+		  rir = coalesce($18, s.rir)
 		where s.id = $1 and s.user_id = $2
-		returning id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe,
-		          is_warmup, rest_seconds, tempo, performed_at,
-				  volume_kg, created_at, updated_at
+		returning id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir,
+		          is_warmup, rest_seconds, tempo, performed_at, set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps,
+				  volume_kg, coalesce(effective_load_kg, weight_kg) as effective_load_kg, created_at, updated_at
 	`
 	var out models.Set
-	if err := s.db.QueryRowxContext(ctx, q,
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q,
 		p.ID, p.UserID, p.Position, p.Reps, p.WeightKg, p.RPE, p.IsWarmup, p.RestSeconds, p.Tempo, p.PerformedAt,
+		p.SetType, p.DurationSeconds, p.DistanceM, p.Side, p.ToFailure, p.AssistedReps, p.PartialReps, p.RIR,
 	).StructScan(&out); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
+		if isSetMeasurementViolation(err) {
+			return nil, ErrSetMeasurement
+		}
+		if isAssistedRepsViolation(err) {
+			return nil, ErrAssistedReps
+		}
+		if isEffortViolation(err) {
+			return nil, models.ErrEffortMismatch
+		}
 		return nil, err
 	}
 	return &out, nil
 }
 
 func (s *Sets) Delete(ctx context.Context, id, userID string) (bool, error) {
-	res, err := s.db.ExecContext(ctx, `delete from sets where id = $1 and user_id = $2`, id, userID)
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from sets where id = $1 and user_id = $2`, id, userID)
 	if err != nil {
 		return false, err
 	}
@@ -115,7 +213,7 @@ func (s *Sets) CreateRest(ctx context.Context, p CreateRestParams) (*models.Rest
 		returning id, exercise_id, position, duration_seconds, created_at, updated_at
 	`
 	var out models.RestPeriod
-	if err := s.db.QueryRowxContext(ctx, q,
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q,
 		p.ExerciseID, p.UserID, p.Position, p.DurationSeconds,
 	).StructScan(&out); err != nil {
 		if err == sql.ErrNoRows {
@@ -147,7 +245,7 @@ func (s *Sets) UpdateRest(ctx context.Context, p UpdateRestParams) (*models.Rest
 		returning rp.id, rp.exercise_id, rp.position, rp.duration_seconds, rp.created_at, rp.updated_at
 	`
 	var out models.RestPeriod
-	if err := s.db.QueryRowxContext(ctx, q,
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q,
 		p.ID, p.UserID, p.Position, p.DurationSeconds,
 	).StructScan(&out); err != nil {
 		if err == sql.ErrNoRows {
@@ -167,10 +265,58 @@ func (s *Sets) DeleteRest(ctx context.Context, restID, userID string) (bool, err
 		  and rp.exercise_id = e.id
 		  and d.user_id = $2
 	`
-	res, err := s.db.ExecContext(ctx, q, restID, userID)
+	res, err := conn(ctx, s.db).ExecContext(ctx, q, restID, userID)
 	if err != nil {
 		return false, err
 	}
 	n, _ := res.RowsAffected()
 	return n > 0, nil
 }
+
+// InsertWarmups adds warmup sets at the start of an exercise, shifting its
+// existing sets and rests back. Returns nil, nil if the exercise isn't the
+// user's.
+func (s *Sets) InsertWarmups(ctx context.Context, userID, exerciseID string, warmups []progression.WarmupSet) ([]models.Set, error) {
+	var out []models.Set
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		var owned bool
+		if err := tx.QueryRowxContext(ctx, `
+			select exists (
+			  select 1 from exercises e join workout_days d on d.id = e.day_id
+			  where e.id = $1 and d.user_id = $2
+			)`, exerciseID, userID).Scan(&owned); err != nil {
+			return err
+		}
+		if !owned {
+			return nil
+		}
+		n := len(warmups)
+		if _, err := tx.ExecContext(ctx, `update sets set position = position + $2 where exercise_id = $1`, exerciseID, n); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `update rest_periods set position = position + $2 where exercise_id = $1`, exerciseID, n); err != nil {
+			return err
+		}
+		out = make([]models.Set, 0, n)
+		for i, w := range warmups {
+			var st models.Set
+			if err := tx.QueryRowxContext(ctx, `
+				insert into sets (exercise_id, user_id, workout_date, position, reps, weight_kg, is_warmup)
+				select $1, d.user_id, d.workout_date, $2, $3, $4, true
+				from exercises e join workout_days d on d.id = e.day_id
+				where e.id = $1
+				returning id, exercise_id, user_id, workout_date, position, reps, weight_kg, rpe, rir,
+				          is_warmup, rest_seconds, tempo, performed_at, set_type, duration_seconds, distance_m, side, to_failure, assisted_reps, partial_reps,
+				          volume_kg, coalesce(effective_load_kg, weight_kg) as effective_load_kg, created_at, updated_at`,
+				exerciseID, i, w.Reps, w.WeightKg).StructScan(&st); err != nil {
+				return err
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsSetMeasurementViolation(t *testing.T) {
+	for _, name := range []string{"sets_reps_check", "sets_type_measurement"} {
+		if !isSetMeasurementViolation(fmt.Errorf("insert set: %w", &pgconn.PgError{Code: "23514", ConstraintName: name})) {
+			t.Errorf("%s not recognised", name)
+		}
+	}
+	if isSetMeasurementViolation(&pgconn.PgError{Code: "23514", ConstraintName: "quota_sets"}) || isSetMeasurementViolation(errors.New("boom")) {
+		t.Fatal("other errors are not measurement violations")
+	}
+}
+
+func TestIsAssistedRepsViolation(t *testing.T) {
+	if !isAssistedRepsViolation(fmt.Errorf("update set: %w", &pgconn.PgError{Code: "23514", ConstraintName: "sets_assisted_reps_check"})) {
+		t.Fatal("assisted reps violation not recognised")
+	}
+	if isAssistedRepsViolation(&pgconn.PgError{Code: "23514", ConstraintName: "sets_reps_check"}) {
+		t.Fatal("other constraints are not assisted reps violations")
+	}
+}
+
+func TestIsEffortViolation(t *testing.T) {
+	if !isEffortViolation(fmt.Errorf("update set: %w", &pgconn.PgError{Code: "23514", ConstraintName: "sets_rir_rpe_check"})) {
+		t.Fatal("rpe/rir mismatch not recognised")
+	}
+	if isEffortViolation(&pgconn.PgError{Code: "23514", ConstraintName: "sets_assisted_reps_check"}) || isEffortViolation(errors.New("boom")) {
+		t.Fatal("other errors are not effort violations")
+	}
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"exercise-tracker/internal/plates"
+)
+
+// Defaults for users without a settings row; keep in sync with the column
+// defaults of user_settings.
+const (
+	DefaultBarWeightKg      = 20
+	DefaultPlateIncrementKg = 1.25
+)
+
+type Settings struct {
+	db *sqlx.DB
+}
+
+func NewSettings(db *sqlx.DB) *Settings { return &Settings{db: db} }
+
+type UserSettings struct {
+	BarWeightKg float64 `json:"barWeightKg"`
+	// PlateIncrementKg is the smallest plate available, per side.
+	PlateIncrementKg float64 `json:"plateIncrementKg"`
+	// SmallestIncrement is the smallest total jump the user can load, in
+	// Units; nil means two of the smallest plate.
+	SmallestIncrement *float64 `json:"smallestIncrement"`
+	// Units is kg or lb; Plates, BarWeights and SmallestIncrement are in
+	// this unit.
+	Units      string         `json:"units"`
+	Plates     []plates.Plate `json:"plates"`
+	BarWeights []float64      `json:"barWeights"`
+	// DefaultRestSeconds is the rest auto-inserted between sets of
+	// exercises without their own ExerciseRest; nil means none.
+	DefaultRestSeconds *int `json:"defaultRestSeconds"`
+	// StampSets stamps sets created without performedAt with the server
+	// time.
+	StampSets bool `json:"stampSets"`
+	// DayVisibility is who sees days without their own visibility in the
+	// activity feed: private or friends.
+	DayVisibility string     `json:"dayVisibility"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+}
+
+type settingsRow struct {
+	BarWeightKg      float64    `db:"bar_weight_kg"`
+	PlateIncrementKg float64    `db:"plate_increment_kg"`
+	SmallestInc      *float64   `db:"smallest_increment"`
+	Units            string     `db:"units"`
+	Plates           []byte     `db:"plates"`
+	BarWeights       []byte     `db:"bar_weights"`
+	DefaultRest      *int       `db:"default_rest_seconds"`
+	StampSets        bool       `db:"stamp_sets"`
+	DayVisibility    string     `db:"day_visibility"`
+	UpdatedAt        *time.Time `db:"updated_at"`
+}
+
+func DefaultUserSettings() UserSettings {
+	return settingsRow{BarWeightKg: DefaultBarWeightKg, PlateIncrementKg: DefaultPlateIncrementKg, Units: plates.UnitKg, DayVisibility: VisibilityPrivate}.settings()
+}
+
+// settings fills unset equipment with the defaults for the row's unit.
+func (r settingsRow) settings() UserSettings {
+	out := UserSettings{
+		BarWeightKg:        r.BarWeightKg,
+		PlateIncrementKg:   r.PlateIncrementKg,
+		SmallestIncrement:  r.SmallestInc,
+		Units:              r.Units,
+		DefaultRestSeconds: r.DefaultRest,
+		StampSets:          r.StampSets,
+		DayVisibility:      r.DayVisibility,
+		UpdatedAt:          r.UpdatedAt,
+	}
+	if len(r.Plates) > 0 {
+		_ = json.Unmarshal(r.Plates, &out.Plates)
+	}
+	if len(r.BarWeights) > 0 {
+		_ = json.Unmarshal(r.BarWeights, &out.BarWeights)
+	}
+	if out.Plates == nil {
+		out.Plates = plates.DefaultKg
+		if out.Units == plates.UnitLb {
+			out.Plates = plates.DefaultLb
+		}
+	}
+	if out.BarWeights == nil {
+		out.BarWeights = plates.DefaultBarsKg
+		if out.Units == plates.UnitLb {
+			out.BarWeights = plates.DefaultBarsLb
+		}
+	}
+	return out
+}
+
+// StepKg is the smallest jump the user can load, in kg, for rounding
+// suggested weights.
+func (u UserSettings) StepKg() float64 {
+	if u.SmallestIncrement != nil {
+		return plates.ToKg(*u.SmallestIncrement, u.Units)
+	}
+	return 2 * u.PlateIncrementKg
+}
+
+const settingsColumns = `bar_weight_kg, plate_increment_kg, smallest_increment, units, plates, bar_weights, default_rest_seconds, stamp_sets, day_visibility, updated_at`
+
+// Get returns the user's settings, or the defaults if none were saved.
+func (s *Settings) Get(ctx context.Context, userID string) (UserSettings, error) {
+	var row settingsRow
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `select `+settingsColumns+` from user_settings where user_id = $1`, userID).StructScan(&row)
+	if err == sql.ErrNoRows {
+		return DefaultUserSettings(), nil
+	}
+	if err != nil {
+		return UserSettings{}, err
+	}
+	return row.settings(), nil
+}
+
+type UpdateSettingsParams struct {
+	BarWeightKg      *float64
+	PlateIncrementKg *float64
+	Units            *string
+	Plates           []plates.Plate
+	BarWeights       []float64
+	// ResetEquipment clears plates, bar weights and the smallest increment
+	// back to the defaults.
+	ResetEquipment bool
+	// DefaultRestSeconds sets the default rest; 0 clears it.
+	DefaultRestSeconds *int
+	StampSets          *bool
+	DayVisibility      *string
+	// SmallestIncrement sets the smallest jump, in the user's unit; 0
+	// clears it.
+	SmallestIncrement *float64
+}
+
+// Update changes the given fields, creating the row from defaults first.
+func (s *Settings) Update(ctx context.Context, userID string, p UpdateSettingsParams) (UserSettings, error) {
+	var platesJSON, barsJSON []byte
+	if p.Plates != nil {
+		platesJSON, _ = json.Marshal(p.Plates)
+	}
+	if p.BarWeights != nil {
+		barsJSON, _ = json.Marshal(p.BarWeights)
+	}
+	q := `
+		insert into user_settings (user_id, bar_weight_kg, plate_increment_kg, units, plates, bar_weights, default_rest_seconds, stamp_sets, day_visibility, smallest_increment)
+		values ($1, coalesce($2, $8), coalesce($3, $9), coalesce($4, 'kg'), $5::jsonb, $6::jsonb, nullif($10::int, 0), coalesce($11, false), coalesce($12, 'private'), nullif($13::numeric, 0))
+		on conflict (user_id) do update
+		set bar_weight_kg = coalesce($2, user_settings.bar_weight_kg),
+		    plate_increment_kg = coalesce($3, user_settings.plate_increment_kg),
+		    units = coalesce($4, user_settings.units),
+		    plates = case when $7 then null else coalesce($5::jsonb, user_settings.plates) end,
+		    bar_weights = case when $7 then null else coalesce($6::jsonb, user_settings.bar_weights) end,
+		    default_rest_seconds = case when $10::int is null then user_settings.default_rest_seconds else nullif($10::int, 0) end,
+		    stamp_sets = coalesce($11, user_settings.stamp_sets),
+		    day_visibility = coalesce($12, user_settings.day_visibility),
+		    smallest_increment = case when $13::numeric is not null then nullif($13::numeric, 0) when $7 then null else user_settings.smallest_increment end,
+		    updated_at = now()
+		returning ` + settingsColumns
+	var row settingsRow
+	err := conn(ctx, s.db).QueryRowxContext(ctx, q, userID, p.BarWeightKg, p.PlateIncrementKg, p.Units,
+		nullableJSON(platesJSON), nullableJSON(barsJSON), p.ResetEquipment,
+		float64(DefaultBarWeightKg), DefaultPlateIncrementKg, p.DefaultRestSeconds, p.StampSets, p.DayVisibility, p.SmallestIncrement).StructScan(&row)
+	if err != nil {
+		return UserSettings{}, err
+	}
+	return row.settings(), nil
+}
+
+func nullableJSON(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
+
+// ExerciseRest is a user's default rest for one catalog exercise.
+type ExerciseRest struct {
+	CatalogID   string    `db:"catalog_id" json:"catalogId"`
+	RestSeconds int       `db:"rest_seconds" json:"restSeconds"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// ErrUnknownCatalogEntry is returned when a per-exercise setting names a
+// catalog entry that doesn't exist.
+var ErrUnknownCatalogEntry = errors.New("unknown catalog entry")
+
+func (s *Settings) ExerciseRests(ctx context.Context, userID string) ([]ExerciseRest, error) {
+	out := []ExerciseRest{}
+	err := conn(ctx, s.db).SelectContext(ctx, &out, `
+		select catalog_id, rest_seconds, updated_at
+		from user_exercise_rest
+		where user_id = $1
+		order by updated_at desc
+	`, userID)
+	return out, err
+}
+
+func (s *Settings) SetExerciseRest(ctx context.Context, userID, catalogID string, seconds int) (*ExerciseRest, error) {
+	var r ExerciseRest
+	err := conn(ctx, s.db).QueryRowxContext(ctx, `
+		insert into user_exercise_rest (user_id, catalog_id, rest_seconds)
+		select $1, ec.id, $3 from exercise_catalog ec where ec.id = $2
+		on conflict (user_id, catalog_id) do update
+		set rest_seconds = excluded.rest_seconds, updated_at = now()
+		returning catalog_id, rest_seconds, updated_at
+	`, userID, catalogID, seconds).StructScan(&r)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUnknownCatalogEntry
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *Settings) DeleteExerciseRest(ctx context.Context, userID, catalogID string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from user_exercise_rest where user_id = $1 and catalog_id = $2`, userID, catalogID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,464 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// Template share kinds.
+const (
+	TemplateKindDay     = "day"
+	TemplateKindProgram = "program"
+)
+
+// Templates publishes workout days and programs under share codes and copies
+// them into other accounts.
+type Templates struct {
+	db *sqlx.DB
+}
+
+func NewTemplates(db *sqlx.DB) *Templates { return &Templates{db: db} }
+
+// TemplateSnapshot is what a share code hands out: a single day for a day
+// share, the weeks of a program otherwise. Exercises are referenced by
+// catalog slug so they can be matched in the importer's catalog.
+type TemplateSnapshot struct {
+	Name        string         `json:"name"`
+	Description *string        `json:"description,omitempty"`
+	Day         *TemplateDay   `json:"day,omitempty"`
+	Weeks       []TemplateWeek `json:"weeks,omitempty"`
+}
+
+type TemplateWeek struct {
+	WeekNumber int           `json:"weekNumber"`
+	Name       *string       `json:"name,omitempty"`
+	Days       []TemplateDay `json:"days"`
+}
+
+type TemplateDay struct {
+	DayNumber int                `json:"dayNumber,omitempty"`
+	Name      *string            `json:"name,omitempty"`
+	IsRestDay bool               `json:"isRestDay,omitempty"`
+	Exercises []TemplateExercise `json:"exercises"`
+}
+
+type TemplateExercise struct {
+	Slug           string   `json:"slug"`
+	Name           string   `json:"name"`
+	TargetSets     int      `json:"targetSets"`
+	TargetReps     int      `json:"targetReps"`
+	TargetRepsMax  *int     `json:"targetRepsMax,omitempty"`
+	AMRAP          bool     `json:"amrap,omitempty"`
+	TargetWeightKg *float64 `json:"targetWeightKg,omitempty"`
+	Notes          *string  `json:"notes,omitempty"`
+}
+
+type TemplateShare struct {
+	Code      string           `db:"code" json:"code"`
+	Kind      string           `db:"kind" json:"kind"`
+	SourceID  string           `db:"source_id" json:"sourceId,omitempty"`
+	Name      string           `db:"name" json:"name"`
+	Snapshot  TemplateSnapshot `db:"-" json:"snapshot"`
+	Imports   int              `db:"imports" json:"imports"`
+	CreatedAt time.Time        `db:"created_at" json:"createdAt"`
+}
+
+// TemplateImport reports what an import created. Skipped lists exercises
+// whose slug isn't in the importer's catalog.
+type TemplateImport struct {
+	Kind      string   `json:"kind"`
+	DayID     string   `json:"dayId,omitempty"`
+	ProgramID string   `json:"programId,omitempty"`
+	Imported  int      `json:"imported"`
+	Skipped   []string `json:"skipped"`
+}
+
+// Publish snapshots one of the user's days or programs under a new share
+// code. Returns nil, nil if the source isn't theirs.
+func (s *Templates) Publish(ctx context.Context, userID, kind, sourceID string) (*TemplateShare, error) {
+	var snap *TemplateSnapshot
+	var err error
+	switch kind {
+	case TemplateKindDay:
+		snap, err = s.daySnapshot(ctx, userID, sourceID)
+	case TemplateKindProgram:
+		snap, err = s.programSnapshot(ctx, userID, sourceID)
+	default:
+		return nil, fmt.Errorf("unknown template kind %q", kind)
+	}
+	if err != nil || snap == nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		code, err := newShareCode()
+		if err != nil {
+			return nil, err
+		}
+		share := TemplateShare{Snapshot: *snap}
+		err = conn(ctx, s.db).QueryRowxContext(ctx, `
+			insert into template_shares (code, user_id, kind, source_id, name, snapshot)
+			values ($1, $2, $3, $4, $5, $6)
+			returning code, kind, source_id, name, imports, created_at
+		`, code, userID, kind, sourceID, snap.Name, raw).StructScan(&share)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && attempt < 3 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &share, nil
+	}
+}
+
+func (s *Templates) daySnapshot(ctx context.Context, userID, dayID string) (*TemplateSnapshot, error) {
+	var day struct {
+		Date  time.Time `db:"workout_date"`
+		Notes *string   `db:"notes"`
+	}
+	err := conn(ctx, s.db).GetContext(ctx, &day, `select workout_date, notes from workout_days where id = $1 and user_id = $2`, dayID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ExerciseID      string   `db:"id"`
+		Slug            string   `db:"slug"`
+		Name            string   `db:"name"`
+		Comment         *string  `db:"comment"`
+		PlannedSets     *int     `db:"planned_sets"`
+		PlannedReps     *int     `db:"planned_reps"`
+		PlannedRepsMax  *int     `db:"planned_reps_max"`
+		PlannedAMRAP    bool     `db:"planned_amrap"`
+		PlannedWeightKg *float64 `db:"planned_weight_kg"`
+		WorkingSets     int      `db:"working_sets"`
+		TopReps         *int     `db:"top_reps"`
+		TopWeightKg     *float64 `db:"top_weight_kg"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select e.id, c.slug, e.name, e.comment, e.planned_sets, e.planned_reps, e.planned_reps_max, e.planned_amrap, e.planned_weight_kg,
+		       count(st.id) as working_sets, max(st.reps) as top_reps, max(st.weight_kg) as top_weight_kg
+		from exercises e
+		join exercise_catalog c on c.id = e.catalog_id
+		left join sets st on st.exercise_id = e.id and not st.is_warmup and st.set_type = 'strength'
+		where e.day_id = $1
+		group by e.id, c.slug
+		order by e.position, e.created_at
+	`, dayID); err != nil {
+		return nil, err
+	}
+	td := TemplateDay{Exercises: []TemplateExercise{}}
+	for _, r := range rows {
+		ex := TemplateExercise{Slug: r.Slug, Name: r.Name, Notes: r.Comment, TargetWeightKg: r.PlannedWeightKg, AMRAP: r.PlannedAMRAP}
+		// Logged work wins over the plan; fall back to the plan, then one set.
+		switch {
+		case r.WorkingSets > 0:
+			ex.TargetSets = r.WorkingSets
+			ex.TargetWeightKg = r.TopWeightKg
+			if r.TopReps != nil {
+				ex.TargetReps = *r.TopReps
+			}
+		case r.PlannedSets != nil:
+			ex.TargetSets = *r.PlannedSets
+		}
+		if ex.TargetReps == 0 && r.PlannedReps != nil {
+			ex.TargetReps, ex.TargetRepsMax = *r.PlannedReps, r.PlannedRepsMax
+		}
+		ex.TargetSets = max(ex.TargetSets, 1)
+		ex.TargetReps = max(ex.TargetReps, 1)
+		td.Exercises = append(td.Exercises, ex)
+	}
+	name := "Workout " + day.Date.Format("2006-01-02")
+	return &TemplateSnapshot{Name: name, Description: day.Notes, Day: &td}, nil
+}
+
+func (s *Templates) programSnapshot(ctx context.Context, userID, programID string) (*TemplateSnapshot, error) {
+	prog, err := NewPrograms(s.db).Get(ctx, userID, programID)
+	if err != nil || prog == nil {
+		return nil, err
+	}
+	var ids []string
+	for _, w := range prog.Weeks {
+		for _, d := range w.Days {
+			for _, ex := range d.Exercises {
+				ids = append(ids, ex.CatalogID)
+			}
+		}
+	}
+	var slugs []struct {
+		ID   string `db:"id"`
+		Slug string `db:"slug"`
+	}
+	if err := conn(ctx, s.db).SelectContext(ctx, &slugs, `select id, slug from exercise_catalog where id = any($1::uuid[])`, ids); err != nil {
+		return nil, err
+	}
+	slugByID := make(map[string]string, len(slugs))
+	for _, c := range slugs {
+		slugByID[c.ID] = c.Slug
+	}
+	snap := &TemplateSnapshot{Name: prog.Name, Description: prog.Description, Weeks: []TemplateWeek{}}
+	for _, w := range prog.Weeks {
+		tw := TemplateWeek{WeekNumber: w.WeekNumber, Name: w.Name, Days: []TemplateDay{}}
+		for _, d := range w.Days {
+			td := TemplateDay{DayNumber: d.DayNumber, Name: d.Name, IsRestDay: d.IsRestDay, Exercises: []TemplateExercise{}}
+			for _, ex := range d.Exercises {
+				td.Exercises = append(td.Exercises, TemplateExercise{
+					Slug:           slugByID[ex.CatalogID],
+					Name:           ex.Name,
+					TargetSets:     ex.TargetSets,
+					TargetReps:     ex.TargetReps,
+					TargetRepsMax:  ex.TargetRepsMax,
+					AMRAP:          ex.AMRAP,
+					TargetWeightKg: ex.TargetWeightKg,
+					Notes:          ex.Notes,
+				})
+			}
+			tw.Days = append(tw.Days, td)
+		}
+		snap.Weeks = append(snap.Weeks, tw)
+	}
+	return snap, nil
+}
+
+const templateShareColumns = `code, kind, source_id, name, snapshot, imports, created_at`
+
+type templateShareRow struct {
+	TemplateShare
+	Raw json.RawMessage `db:"snapshot"`
+}
+
+func (r templateShareRow) share() (TemplateShare, error) {
+	out := r.TemplateShare
+	return out, json.Unmarshal(r.Raw, &out.Snapshot)
+}
+
+// Get returns the share behind code, or nil, nil if there is none.
+func (s *Templates) Get(ctx context.Context, code string) (*TemplateShare, error) {
+	var row templateShareRow
+	err := conn(ctx, s.db).GetContext(ctx, &row, `select `+templateShareColumns+` from template_shares where code = $1`, normalizeShareCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	share, err := row.share()
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// List returns the user's published shares, newest first.
+func (s *Templates) List(ctx context.Context, userID string) ([]TemplateShare, error) {
+	var rows []templateShareRow
+	if err := conn(ctx, s.db).SelectContext(ctx, &rows, `
+		select `+templateShareColumns+` from template_shares
+		where user_id = $1
+		order by created_at desc
+	`, userID); err != nil {
+		return nil, err
+	}
+	out := make([]TemplateShare, 0, len(rows))
+	for _, r := range rows {
+		share, err := r.share()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, share)
+	}
+	return out, nil
+}
+
+// Delete withdraws one of the user's share codes.
+func (s *Templates) Delete(ctx context.Context, userID, code string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from template_shares where code = $1 and user_id = $2`, normalizeShareCode(code), userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Import copies the template behind code into the user's account: a day
+// share appends its exercises, with their targets as the plan, to the user's
+// day on date; a program share becomes a new program. Exercises are matched
+// by slug against the catalog entries the user can see. Returns nil, nil for
+// an unknown code and ErrExerciseOnRestDay when date is a rest day.
+func (s *Templates) Import(ctx context.Context, userID, code string, date time.Time) (*TemplateImport, error) {
+	share, err := s.Get(ctx, code)
+	if err != nil || share == nil {
+		return nil, err
+	}
+	res := &TemplateImport{Kind: share.Kind, Skipped: []string{}}
+	err = inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		catalog, err := matchTemplateSlugs(ctx, tx, userID, share.Snapshot)
+		if err != nil {
+			return err
+		}
+		if share.Kind == TemplateKindDay {
+			return importTemplateDay(ctx, tx, userID, date, share.Snapshot.Day, catalog, res)
+		}
+		return importTemplateProgram(ctx, tx, userID, share.Snapshot, catalog, res)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "exercises_require_training_day" {
+			return nil, ErrExerciseOnRestDay
+		}
+		return nil, err
+	}
+	if _, err := conn(ctx, s.db).ExecContext(ctx, `update template_shares set imports = imports + 1 where code = $1`, share.Code); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// matchTemplateSlugs maps the snapshot's slugs to catalog ids the user can
+// use: approved entries and their own submissions.
+func matchTemplateSlugs(ctx context.Context, tx *sqlx.Tx, userID string, snap TemplateSnapshot) (map[string]string, error) {
+	var slugs []string
+	add := func(d *TemplateDay) {
+		for _, ex := range d.Exercises {
+			if ex.Slug != "" {
+				slugs = append(slugs, strings.ToLower(ex.Slug))
+			}
+		}
+	}
+	if snap.Day != nil {
+		add(snap.Day)
+	}
+	for _, w := range snap.Weeks {
+		for i := range w.Days {
+			add(&w.Days[i])
+		}
+	}
+	return catalogIDsBySlug(ctx, tx, userID, slugs)
+}
+
+// catalogIDsBySlug maps lowercase slugs to the ids of the catalog entries the
+// user can use; unknown slugs are left out.
+func catalogIDsBySlug(ctx context.Context, q queryer, userID string, slugs []string) (map[string]string, error) {
+	var rows []struct {
+		ID   string `db:"id"`
+		Slug string `db:"slug"`
+	}
+	if err := q.SelectContext(ctx, &rows, `
+		select id, lower(slug::text) as slug from exercise_catalog
+		where lower(slug::text) = any($1::text[]) and (status = 'approved' or submitted_by = $2)
+	`, slugs, userID); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(rows))
+	for _, r := range rows {
+		out[r.Slug] = r.ID
+	}
+	return out, nil
+}
+
+func importTemplateDay(ctx context.Context, tx *sqlx.Tx, userID string, date time.Time, day *TemplateDay, catalog map[string]string, res *TemplateImport) error {
+	if err := tx.GetContext(ctx, &res.DayID, `
+		insert into workout_days (user_id, workout_date)
+		values ($1, $2)
+		on conflict (user_id, workout_date) do update set workout_date = excluded.workout_date
+		returning id
+	`, userID, date); err != nil {
+		return err
+	}
+	var next int
+	if err := tx.GetContext(ctx, &next, `select coalesce(max(position) + 1, 0) from exercises where day_id = $1`, res.DayID); err != nil {
+		return err
+	}
+	if day == nil {
+		return nil
+	}
+	for _, ex := range day.Exercises {
+		catalogID, ok := catalog[strings.ToLower(ex.Slug)]
+		if !ok {
+			res.Skipped = append(res.Skipped, ex.Name)
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			insert into exercises (day_id, catalog_id, position, comment, planned_sets, planned_reps, planned_reps_max, planned_amrap, planned_weight_kg)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			res.DayID, catalogID, next, ex.Notes, ex.TargetSets, ex.TargetReps, ex.TargetRepsMax, ex.AMRAP, ex.TargetWeightKg); err != nil {
+			return err
+		}
+		next++
+		res.Imported++
+	}
+	return nil
+}
+
+func importTemplateProgram(ctx context.Context, tx *sqlx.Tx, userID string, snap TemplateSnapshot, catalog map[string]string, res *TemplateImport) error {
+	in := ProgramInput{Name: snap.Name, Description: snap.Description}
+	for _, w := range snap.Weeks {
+		wi := ProgramWeekInput{WeekNumber: w.WeekNumber, Name: w.Name}
+		for _, d := range w.Days {
+			di := ProgramDayInput{DayNumber: d.DayNumber, Name: d.Name, IsRestDay: d.IsRestDay}
+			for _, ex := range d.Exercises {
+				catalogID, ok := catalog[strings.ToLower(ex.Slug)]
+				if !ok {
+					res.Skipped = append(res.Skipped, ex.Name)
+					continue
+				}
+				di.Exercises = append(di.Exercises, ProgramExerciseInput{
+					CatalogID:      catalogID,
+					TargetSets:     ex.TargetSets,
+					TargetReps:     ex.TargetReps,
+					TargetRepsMax:  ex.TargetRepsMax,
+					AMRAP:          ex.AMRAP,
+					TargetWeightKg: ex.TargetWeightKg,
+					Notes:          ex.Notes,
+				})
+				res.Imported++
+			}
+			wi.Days = append(wi.Days, di)
+		}
+		in.Weeks = append(in.Weeks, wi)
+	}
+	if err := in.Validate(); err != nil {
+		return err
+	}
+	if err := tx.GetContext(ctx, &res.ProgramID, `
+		insert into programs (user_id, name, description)
+		values ($1, $2, $3)
+		returning id`, userID, strings.TrimSpace(in.Name), trimPtr(in.Description)); err != nil {
+		return err
+	}
+	return insertProgramWeeks(ctx, tx, res.ProgramID, in.Weeks)
+}
+
+// shareCodeAlphabet leaves out characters that are easy to misread.
+const shareCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+func newShareCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = shareCodeAlphabet[int(b[i])%len(shareCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// normalizeShareCode accepts codes typed in lower case or with separators.
+func normalizeShareCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
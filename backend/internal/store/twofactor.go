@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TwoFactor struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactor(db *sqlx.DB) *TwoFactor { return &TwoFactor{db: db} }
+
+// TOTP is a user's authenticator secret. EnabledAt is nil while enrollment
+// awaits its first code.
+type TOTP struct {
+	Secret    string     `db:"secret"`
+	EnabledAt *time.Time `db:"enabled_at"`
+	LastStep  int64      `db:"last_step"`
+}
+
+func (s *TwoFactor) Get(ctx context.Context, userID string) (*TOTP, error) {
+	var t TOTP
+	err := conn(ctx, s.db).GetContext(ctx, &t, `select secret, enabled_at, last_step from user_totp where user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Enroll stores a new pending secret, replacing an earlier unfinished
+// enrollment. It reports false when 2FA is already enabled.
+func (s *TwoFactor) Enroll(ctx context.Context, userID, secret string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		insert into user_totp (user_id, secret) values ($1, $2)
+		on conflict (user_id) do update
+		set secret = excluded.secret, last_step = 0, created_at = now()
+		where user_totp.enabled_at is null
+	`, userID, secret)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Enable turns on a pending enrollment confirmed with the code at step and
+// stores the recovery code hashes. It reports false when there is no
+// pending enrollment.
+func (s *TwoFactor) Enable(ctx context.Context, userID string, step int64, recoveryHashes []string) (bool, error) {
+	enabled := false
+	err := inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			update user_totp set enabled_at = now(), last_step = $2
+			where user_id = $1 and enabled_at is null and last_step < $2
+		`, userID, step)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+		enabled = true
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryHashes)
+	})
+	return enabled, err
+}
+
+// UseStep records a verified code's time step, reporting false when that
+// step or a later one was already used.
+func (s *TwoFactor) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update user_totp set last_step = $2
+		where user_id = $1 and enabled_at is not null and last_step < $2
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// UseRecoveryCode spends an unused recovery code.
+func (s *TwoFactor) UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `
+		update user_recovery_codes set used_at = now()
+		where user_id = $1 and code_hash = $2 and used_at is null
+	`, userID, hash)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecoveryCodesLeft counts the user's unused recovery codes.
+func (s *TwoFactor) RecoveryCodesLeft(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := conn(ctx, s.db).GetContext(ctx, &n, `select count(*) from user_recovery_codes where user_id = $1 and used_at is null`, userID)
+	return n, err
+}
+
+// ReplaceRecoveryCodes invalidates the user's recovery codes in favour of a
+// new set.
+func (s *TwoFactor) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	return inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, hashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID string, hashes []string) error {
+	if _, err := tx.ExecContext(ctx, `delete from user_recovery_codes where user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.ExecContext(ctx, `insert into user_recovery_codes (user_id, code_hash) values ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Disable removes the secret and recovery codes.
+func (s *TwoFactor) Disable(ctx context.Context, userID string) error {
+	return inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `delete from user_recovery_codes where user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `delete from user_totp where user_id = $1`, userID)
+		return err
+	})
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// queryer is what store methods run statements against: the pool, or the
+// request's transaction when one is open.
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+type requestTxKey struct{}
+
+// RequestTx is a transaction shared by every store call made with the
+// context it was begun with. Stores that need their own transaction nest
+// inside it with a savepoint, so their rollbacks stay local.
+type RequestTx struct {
+	tx *sqlx.Tx
+
+	mu          sync.Mutex
+	savepoints  int
+	afterCommit []func()
+}
+
+// BeginRequestTx opens a transaction on db and returns a context that routes
+// store calls through it. The caller must Commit or Rollback.
+func BeginRequestTx(ctx context.Context, db *sqlx.DB) (*RequestTx, context.Context, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, ctx, err
+	}
+	t := &RequestTx{tx: tx}
+	return t, context.WithValue(ctx, requestTxKey{}, t), nil
+}
+
+// Tx returns the underlying transaction, for statements issued outside the
+// store package.
+func (t *RequestTx) Tx() *sqlx.Tx { return t.tx }
+
+// Commit commits the transaction and then runs the AfterCommit callbacks.
+func (t *RequestTx) Commit() error {
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	fns := t.afterCommit
+	t.afterCommit = nil
+	t.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+	return nil
+}
+
+// Rollback discards the transaction and its AfterCommit callbacks. Rolling
+// back a committed transaction is a no-op.
+func (t *RequestTx) Rollback() error {
+	t.mu.Lock()
+	t.afterCommit = nil
+	t.mu.Unlock()
+	if err := t.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
+
+// InRequestTx reports whether ctx carries a request transaction.
+func InRequestTx(ctx context.Context) bool { return requestTx(ctx) != nil }
+
+func requestTx(ctx context.Context) *RequestTx {
+	t, _ := ctx.Value(requestTxKey{}).(*RequestTx)
+	return t
+}
+
+// conn returns the request transaction carried by ctx, or db.
+func conn(ctx context.Context, db *sqlx.DB) queryer {
+	if t := requestTx(ctx); t != nil {
+		return t.tx
+	}
+	return db
+}
+
+// Conn is conn for packages that write alongside the stores, such as the
+// job queue, so their rows commit or roll back with the request.
+func Conn(ctx context.Context, db *sqlx.DB) sqlx.ExtContext { return conn(ctx, db) }
+
+// AfterCommit runs fn once the request transaction commits, or right away
+// when ctx carries none. Use it for side effects such as cache invalidation
+// that must not be observed before the data is.
+func AfterCommit(ctx context.Context, fn func()) {
+	t := requestTx(ctx)
+	if t == nil {
+		fn()
+		return
+	}
+	t.mu.Lock()
+	t.afterCommit = append(t.afterCommit, fn)
+	t.mu.Unlock()
+}
+
+// scopedTx is a transaction begun by a store method. Inside a request
+// transaction it is a savepoint on the shared *sqlx.Tx; Commit releases it
+// and Rollback rolls back to it.
+type scopedTx struct {
+	*sqlx.Tx
+	savepoint string
+}
+
+// beginTx starts a transaction on db, or a savepoint when ctx carries a
+// request transaction.
+func beginTx(ctx context.Context, db *sqlx.DB) (*scopedTx, error) {
+	t := requestTx(ctx)
+	if t == nil {
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &scopedTx{Tx: tx}, nil
+	}
+	t.mu.Lock()
+	t.savepoints++
+	name := fmt.Sprintf("sp_%d", t.savepoints)
+	t.mu.Unlock()
+	if _, err := t.tx.ExecContext(ctx, "savepoint "+name); err != nil {
+		return nil, err
+	}
+	return &scopedTx{Tx: t.tx, savepoint: name}, nil
+}
+
+// inTx runs fn in a transaction on db (a savepoint inside a request
+// transaction), rolling back on error or panic.
+func inTx(ctx context.Context, db *sqlx.DB, fn func(*sqlx.Tx) error) (err error) {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if err = fn(tx.Tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *scopedTx) Commit() error {
+	if s.savepoint == "" {
+		return s.Tx.Commit()
+	}
+	_, err := s.Tx.Exec("release savepoint " + s.savepoint)
+	return err
+}
+
+func (s *scopedTx) Rollback() error {
+	if s.savepoint == "" {
+		return s.Tx.Rollback()
+	}
+	_, err := s.Tx.Exec("rollback to savepoint " + s.savepoint)
+	return err
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// Usage reports how much a user stores and manages the storage quotas that
+// triggers enforce on workout days and sets.
+type Usage struct {
+	db *sqlx.DB
+}
+
+func NewUsage(db *sqlx.DB) *Usage { return &Usage{db: db} }
+
+// Quota limits, nil meaning unlimited (or, for a user's override, the
+// default).
+type Quota struct {
+	MaxWorkoutDays *int `db:"max_workout_days" json:"maxWorkoutDays"`
+	MaxSets        *int `db:"max_sets" json:"maxSets"`
+}
+
+// UserUsage is what a user stores. Bytes is an estimate of the on-disk row
+// size of their workout data, excluding indexes.
+type UserUsage struct {
+	WorkoutDays int   `db:"workout_days" json:"workoutDays"`
+	Exercises   int   `db:"exercises" json:"exercises"`
+	Sets        int   `db:"sets" json:"sets"`
+	Rests       int   `db:"rests" json:"rests"`
+	Bytes       int64 `db:"bytes" json:"bytes"`
+	// Quota is the effective limit: the user's override where set,
+	// otherwise the default.
+	Quota Quota `db:"quota" json:"quota"`
+}
+
+// QuotaError reports a write that would take a user over a quota.
+type QuotaError struct {
+	Resource string // "workout days" or "sets"
+	Limit    int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("storage quota exceeded: at most %d %s", e.Limit, e.Resource)
+}
+
+// AsQuotaError returns the quota behind err when err was raised by one of the
+// quota triggers. Any store write that creates workout days or sets can fail
+// this way.
+func AsQuotaError(err error) (*QuotaError, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil, false
+	}
+	var resource string
+	switch pgErr.ConstraintName {
+	case "quota_workout_days":
+		resource = "workout days"
+	case "quota_sets":
+		resource = "sets"
+	default:
+		return nil, false
+	}
+	limit, _ := strconv.Atoi(pgErr.Detail)
+	return &QuotaError{Resource: resource, Limit: limit}, true
+}
+
+// Get returns the user's current usage and effective quota.
+func (s *Usage) Get(ctx context.Context, userID string) (*UserUsage, error) {
+	const q = `
+		with days as (
+			select d.id, pg_column_size(d.*) as size from workout_days d where d.user_id = $1
+		), exs as (
+			select e.id, pg_column_size(e.*) as size from exercises e join days on days.id = e.day_id
+		), rests as (
+			select pg_column_size(r.*) as size from rest_periods r join exs on exs.id = r.exercise_id
+		), user_sets as (
+			select pg_column_size(s.*) as size from sets s where s.user_id = $1
+		)
+		select (select count(*) from days) as workout_days,
+		       (select count(*) from exs) as exercises,
+		       (select count(*) from user_sets) as sets,
+		       (select count(*) from rests) as rests,
+		       coalesce((select sum(size) from days), 0)
+		         + coalesce((select sum(size) from exs), 0)
+		         + coalesce((select sum(size) from user_sets), 0)
+		         + coalesce((select sum(size) from rests), 0) as bytes,
+		       coalesce(q.max_workout_days, d.max_workout_days) as "quota.max_workout_days",
+		       coalesce(q.max_sets, d.max_sets) as "quota.max_sets"
+		from quota_defaults d
+		left join user_quotas q on q.user_id = $1
+	`
+	var out UserUsage
+	if err := conn(ctx, s.db).GetContext(ctx, &out, q, userID); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Defaults returns the quota that applies to users without an override.
+func (s *Usage) Defaults(ctx context.Context) (Quota, error) {
+	var out Quota
+	err := conn(ctx, s.db).GetContext(ctx, &out, `select max_workout_days, max_sets from quota_defaults`)
+	return out, err
+}
+
+// SetDefaults replaces the default quota. Existing data over a lowered limit
+// stays; only further writes fail.
+func (s *Usage) SetDefaults(ctx context.Context, q Quota) (Quota, error) {
+	var out Quota
+	err := conn(ctx, s.db).GetContext(ctx, &out, `
+		update quota_defaults
+		set max_workout_days = $1, max_sets = $2, updated_at = now()
+		returning max_workout_days, max_sets`, q.MaxWorkoutDays, q.MaxSets)
+	return out, err
+}
+
+// UserQuota returns the user's override, or nil when the defaults apply.
+func (s *Usage) UserQuota(ctx context.Context, userID string) (*Quota, error) {
+	var out Quota
+	err := conn(ctx, s.db).GetContext(ctx, &out, `select max_workout_days, max_sets from user_quotas where user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetUserQuota sets the user's override; a nil field falls back to the
+// default for that resource.
+func (s *Usage) SetUserQuota(ctx context.Context, userID string, q Quota) (*Quota, error) {
+	var out Quota
+	err := conn(ctx, s.db).GetContext(ctx, &out, `
+		insert into user_quotas (user_id, max_workout_days, max_sets)
+		values ($1, $2, $3)
+		on conflict (user_id) do update
+		set max_workout_days = excluded.max_workout_days, max_sets = excluded.max_sets, updated_at = now()
+		returning max_workout_days, max_sets`, userID, q.MaxWorkoutDays, q.MaxSets)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteUserQuota removes the user's override.
+func (s *Usage) DeleteUserQuota(ctx context.Context, userID string) (bool, error) {
+	res, err := conn(ctx, s.db).ExecContext(ctx, `delete from user_quotas where user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestAsQuotaError(t *testing.T) {
+	err := fmt.Errorf("insert set: %w", &pgconn.PgError{Code: "23514", ConstraintName: "quota_sets", Detail: "5000"})
+	qe, ok := AsQuotaError(err)
+	if !ok {
+		t.Fatal("quota error not recognised")
+	}
+	if qe.Resource != "sets" || qe.Limit != 5000 {
+		t.Fatalf("got %+v", qe)
+	}
+	if qe.Error() != "storage quota exceeded: at most 5000 sets" {
+		t.Fatalf("message = %q", qe.Error())
+	}
+
+	if _, ok := AsQuotaError(&pgconn.PgError{Code: "23514", ConstraintName: "exercises_require_training_day"}); ok {
+		t.Fatal("other check violations are not quota errors")
+	}
+	if _, ok := AsQuotaError(errors.New("boom")); ok {
+		t.Fatal("plain errors are not quota errors")
+	}
+}
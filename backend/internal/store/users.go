@@ -2,10 +2,14 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 
 	"exercise-tracker/internal/models"
@@ -23,19 +27,19 @@ func (s *Users) Create(ctx context.Context, email, passwordHash string) (*models
 	const q = `
 		insert into users (email, password_hash)
 		values ($1, $2)
-		returning id, email, password_hash, created_at, updated_at
+		returning id, email, password_hash, deleted_at, purge_after, is_guest, created_at, updated_at
 	`
 	u := new(models.User)
-	if err := s.db.QueryRowxContext(ctx, q, strings.ToLower(email), passwordHash).StructScan(u); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, strings.ToLower(email), passwordHash).StructScan(u); err != nil {
 		return nil, err
 	}
 	return u, nil
 }
 
 func (s *Users) ByEmail(ctx context.Context, email string) (*models.User, error) {
-	const q = `select id, email, password_hash, created_at, updated_at from users where email = $1`
+	const q = `select id, email, password_hash, deleted_at, purge_after, is_guest, created_at, updated_at from users where email = $1`
 	u := new(models.User)
-	if err := s.db.QueryRowxContext(ctx, q, strings.ToLower(email)).StructScan(u); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, strings.ToLower(email)).StructScan(u); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -45,9 +49,9 @@ func (s *Users) ByEmail(ctx context.Context, email string) (*models.User, error)
 }
 
 func (s *Users) ByID(ctx context.Context, id string) (*models.User, error) {
-	const q = `select id, email, password_hash, created_at, updated_at from users where id = $1`
+	const q = `select id, email, password_hash, deleted_at, purge_after, is_guest, created_at, updated_at from users where id = $1`
 	u := new(models.User)
-	if err := s.db.QueryRowxContext(ctx, q, id).StructScan(u); err != nil {
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, id).StructScan(u); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -57,3 +61,145 @@ func (s *Users) ByID(ctx context.Context, id string) (*models.User, error) {
 }
 
 
+
+// ErrEmailTaken is returned when claiming a guest account with an email
+// another account already uses.
+var ErrEmailTaken = errors.New("email already in use")
+
+// CreateGuest creates an account without credentials.
+func (s *Users) CreateGuest(ctx context.Context) (*models.User, error) {
+	const q = `
+		insert into users (email, password_hash, is_guest)
+		values ('guest-' || gen_random_uuid() || '@guest.invalid', '', true)
+		returning id, email, password_hash, deleted_at, purge_after, is_guest, created_at, updated_at
+	`
+	u := new(models.User)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q).StructScan(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Claim turns a guest account into a full one, keeping its id and so all its
+// data. It returns nil when id is not a guest.
+func (s *Users) Claim(ctx context.Context, id, email, passwordHash string) (*models.User, error) {
+	const q = `
+		update users set email = $2, password_hash = $3, is_guest = false, updated_at = now()
+		where id = $1 and is_guest
+		returning id, email, password_hash, deleted_at, purge_after, is_guest, created_at, updated_at
+	`
+	u := new(models.User)
+	if err := conn(ctx, s.db).QueryRowxContext(ctx, q, id, strings.ToLower(email), passwordHash).StructScan(u); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "users_email_key" {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// UpdatePasswordHash replaces the stored hash, e.g. after upgrading its
+// parameters on login.
+func (s *Users) UpdatePasswordHash(ctx context.Context, id, passwordHash string) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `update users set password_hash = $2, updated_at = now() where id = $1`, id, passwordHash)
+	return err
+}
+
+// ScheduleDeletion marks the account deleted and revokes its sessions; its
+// data is purged once purgeAfter has passed unless the user signs in again
+// before then.
+func (s *Users) ScheduleDeletion(ctx context.Context, id string, purgeAfter time.Time) error {
+	return inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			update users set deleted_at = now(), purge_after = $2, updated_at = now()
+			where id = $1`, id, purgeAfter); err != nil {
+			return err
+		}
+		// Signing in again restores the account; no existing session should.
+		_, err := tx.ExecContext(ctx, `
+			update sessions set revoked_at = now()
+			where user_id = $1 and revoked_at is null`, id)
+		return err
+	})
+}
+
+// CancelDeletion restores an account that is still inside its grace period.
+func (s *Users) CancelDeletion(ctx context.Context, id string) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `
+		update users set deleted_at = null, purge_after = null, updated_at = now()
+		where id = $1 and deleted_at is not null`, id)
+	return err
+}
+
+// Purge permanently removes the user and everything they own. Most tables
+// cascade from users; sets carry a denormalized user_id without a foreign key
+// and are deleted explicitly. Audit rows the user authored lose their email.
+func (s *Users) Purge(ctx context.Context, id string) error {
+	return inTx(ctx, s.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `update audit_log set actor_email = null where actor_id = $1`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `delete from sets where user_id = $1`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `delete from users where id = $1`, id); err != nil {
+			return err
+		}
+		// Journal rows and tombstones written by the deletes above; neither
+		// references users, so the cascades don't reach them.
+		if _, err := tx.ExecContext(ctx, `delete from change_journal where user_id = $1`, id); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `delete from deleted_entities where user_id = $1`, id)
+		return err
+	})
+}
+
+// PurgeIdleGuests purges the unclaimed guest accounts created before
+// idleSince that haven't been seen on any session since, and returns their
+// ids.
+func (s *Users) PurgeIdleGuests(ctx context.Context, idleSince time.Time) ([]string, error) {
+	var ids []string
+	if err := conn(ctx, s.db).SelectContext(ctx, &ids, `
+		select u.id from users u
+		where u.is_guest and u.created_at < $1
+		  and not exists (select 1 from sessions s where s.user_id = u.id and s.last_seen_at >= $1)`, idleSince); err != nil {
+		return nil, err
+	}
+	purged := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if err := s.Purge(ctx, id); err != nil {
+			return purged, err
+		}
+		purged = append(purged, id)
+	}
+	return purged, nil
+}
+
+// PurgeDue purges every account whose grace period ended before now and
+// returns their ids.
+func (s *Users) PurgeDue(ctx context.Context, now time.Time) ([]string, error) {
+	var ids []string
+	if err := conn(ctx, s.db).SelectContext(ctx, &ids, `select id from users where purge_after is not null and purge_after <= $1`, now); err != nil {
+		return nil, err
+	}
+	purged := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if err := s.Purge(ctx, id); err != nil {
+			return purged, err
+		}
+		purged = append(purged, id)
+	}
+	return purged, nil
+}
+
+// AnonymizedUserID is a stable, non-reversible reference to a user for audit
+// rows that must outlive the account.
+func AnonymizedUserID(id string) string {
+	sum := sha256.Sum256([]byte("user:" + id))
+	return "anon-" + hex.EncodeToString(sum[:8])
+}
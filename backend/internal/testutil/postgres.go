@@ -0,0 +1,216 @@
+// Package testutil provides a migrated Postgres database for integration
+// tests.
+//
+// The database comes from TEST_DATABASE_URL when it is set (it must be
+// disposable), otherwise from a throwaway container started with the docker
+// CLI. Tests are skipped when neither is available or with -short. Every
+// test gets its own transaction through Tx, so tests can share the database
+// and run in any order.
+//
+// The docker CLI is used rather than testcontainers-go to keep the Docker SDK
+// out of the module graph. Containers are started with --rm and labelled
+// with an expiry; Main removes its own, an interrupt removes it too, and the
+// next run reaps any whose expiry passed, so a crashed run leaves nothing
+// behind for long.
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"exercise-tracker/internal/db"
+	"exercise-tracker/internal/store"
+)
+
+// defaultImage is the Postgres image started when TEST_POSTGRES_IMAGE is
+// unset; it matches docker-compose.yml.
+const defaultImage = "postgres:17-alpine"
+
+const (
+	// containerLabel marks containers started here; its value is the unix
+	// time after which another run may remove the container.
+	containerLabel = "fitlog.testutil.expires"
+	// containerTTL outlasts any reasonable test run.
+	containerTTL = time.Hour
+)
+
+var (
+	once      sync.Once
+	shared    *db.DB
+	container string
+	setupErr  error
+	skipMsg   string
+)
+
+// Main runs the package's tests and removes the Postgres container, if one
+// was started. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(testutil.Main(m)) }
+func Main(m *testing.M) int {
+	code := m.Run()
+	if shared != nil {
+		_ = shared.Close()
+	}
+	removeContainer()
+	return code
+}
+
+// removeContainer force-removes the container this process started, if any.
+func removeContainer() {
+	if container != "" {
+		_ = exec.Command("docker", "rm", "-f", container).Run()
+	}
+}
+
+// DB returns the shared, fully migrated database, starting it on first use.
+// It skips t when no database is available.
+func DB(t testing.TB) *db.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration test skipped in -short mode")
+	}
+	once.Do(setup)
+	if skipMsg != "" {
+		t.Skip(skipMsg)
+	}
+	if setupErr != nil {
+		t.Fatalf("testutil: %v", setupErr)
+	}
+	return shared
+}
+
+// Tx begins a request transaction on the shared database and returns a
+// context that routes every store call through it. The transaction is
+// rolled back when t finishes, so nothing a test writes is seen by others.
+func Tx(t testing.TB) (context.Context, *store.RequestTx) {
+	t.Helper()
+	database := DB(t)
+	tx, ctx, err := store.BeginRequestTx(context.Background(), database.DB)
+	if err != nil {
+		t.Fatalf("testutil: begin: %v", err)
+	}
+	t.Cleanup(func() { _ = tx.Rollback() })
+	return ctx, tx
+}
+
+func setup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		if _, err := exec.LookPath("docker"); err != nil {
+			skipMsg = "TEST_DATABASE_URL not set and docker not found"
+			return
+		}
+		url, setupErr = startContainer(ctx)
+		if setupErr != nil {
+			return
+		}
+	}
+	shared, setupErr = connect(ctx, url)
+	if setupErr != nil {
+		return
+	}
+	if err := shared.Migrate(ctx); err != nil {
+		setupErr = fmt.Errorf("migrate: %w", err)
+	}
+}
+
+func startContainer(ctx context.Context) (string, error) {
+	image := os.Getenv("TEST_POSTGRES_IMAGE")
+	if image == "" {
+		image = defaultImage
+	}
+	reap(ctx)
+	expires := time.Now().Add(containerTTL).Unix()
+	out, err := docker(ctx, "run", "-d", "--rm",
+		"--label", fmt.Sprintf("%s=%d", containerLabel, expires),
+		"-e", "POSTGRES_USER=fitlog",
+		"-e", "POSTGRES_PASSWORD=fitlog",
+		"-e", "POSTGRES_DB=fitlog",
+		"-p", "127.0.0.1::5432",
+		image)
+	if err != nil {
+		return "", fmt.Errorf("start postgres: %w", err)
+	}
+	container = out
+	removeOnInterrupt()
+	addr, err := docker(ctx, "port", container, "5432/tcp")
+	if err != nil {
+		return "", fmt.Errorf("postgres port: %w", err)
+	}
+	// docker port prints one line per address family; the first is enough.
+	addr, _, _ = strings.Cut(addr, "\n")
+	return fmt.Sprintf("postgres://fitlog:fitlog@%s/fitlog?sslmode=disable", addr), nil
+}
+
+// reap removes containers left behind by earlier runs whose expiry passed.
+// Failures are ignored; at worst a stale container lingers.
+func reap(ctx context.Context) {
+	out, err := docker(ctx, "ps", "-a", "--filter", "label="+containerLabel,
+		"--format", fmt.Sprintf(`{{.ID}} {{.Label %q}}`, containerLabel))
+	if err != nil || out == "" {
+		return
+	}
+	now := time.Now().Unix()
+	for _, line := range strings.Split(out, "\n") {
+		id, exp, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if expires, err := strconv.ParseInt(exp, 10, 64); err == nil && expires < now {
+			_, _ = docker(ctx, "rm", "-f", id)
+		}
+	}
+}
+
+// removeOnInterrupt removes the container when the test binary is
+// interrupted, since Main doesn't get to run then.
+func removeOnInterrupt() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		s := <-sig
+		removeContainer()
+		signal.Reset()
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			_ = p.Signal(s)
+		}
+	}()
+}
+
+// connect retries until the server accepts connections; a fresh container
+// takes a few seconds to initialise.
+func connect(ctx context.Context, url string) (*db.DB, error) {
+	var lastErr error
+	for {
+		database, err := db.Connect(ctx, url)
+		if err == nil {
+			return database, nil
+		}
+		lastErr = err
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("postgres not ready: %w", lastErr)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}